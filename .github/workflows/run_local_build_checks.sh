@@ -90,6 +90,7 @@ echo "running staticcheck"
 	staticcheck ./ingesters/massFile/...
 	staticcheck ./ingesters/MSGraphIngester/...
 	staticcheck ./ingesters/multiFile/...
+	staticcheck ./ingesters/archiveFile/...
 	staticcheck ./ingesters/netflow/...
 	CGO_ENABLED=1 staticcheck ./ingesters/networkLog/...
 	staticcheck ./ingesters/O365Ingester/...
@@ -148,6 +149,7 @@ echo "running govulncheck on everything"
         govulncheck -test ./ingesters/snmp
         govulncheck -test ./ingesters/xlsxIngester
         govulncheck -test ./ingesters/multiFile
+        govulncheck -test ./ingesters/archiveFile
         GOOS=linux govulncheck -test ./ingesters/Shodan
         govulncheck -test ./ingesters/reimport
         govulncheck -test ./ingesters/SimpleRelay
@@ -187,6 +189,7 @@ echo "Running build tests"
         go build -o /dev/null ./ingesters/reddit_ingester
         go build -o /dev/null ./ingesters/hackernews_ingester
         go build -o /dev/null ./ingesters/multiFile
+        go build -o /dev/null ./ingesters/archiveFile
         go build -o /dev/null ./ingesters/session
         go build -o /dev/null ./ingesters/regexFile
         GOOS=linux go build -o /dev/null ./ingesters/Shodan
@@ -0,0 +1,227 @@
+/*************************************************************************
+ * Copyright 2026 Gravwell, Inc. All rights reserved.
+ * Contact: <legal@gravwell.io>
+ *
+ * This software may be modified and distributed under the terms of the
+ * BSD 2-clause license. See the LICENSE file for details.
+ **************************************************************************/
+
+package kits
+
+import (
+	"archive/tar"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	PublicKeyExt  = `.pub` // trusted key directories are scanned for files with this extension
+	PrivateKeyExt = `.key`
+
+	pemPublicKeyType  = `PUBLIC KEY`
+	pemPrivateKeyType = `PRIVATE KEY`
+)
+
+var (
+	ErrUntrustedSignature = errors.New("Manifest signature does not match any trusted key")
+	ErrNoTrustedKeys      = errors.New("No trusted keys provided")
+	ErrInvalidKey         = errors.New("Invalid ed25519 key")
+)
+
+// GenerateSigningKey creates a new ed25519 key pair suitable for signing kit manifests.
+func GenerateSigningKey() (ed25519.PublicKey, ed25519.PrivateKey, error) {
+	return ed25519.GenerateKey(rand.Reader)
+}
+
+// EncodePublicKey PEM encodes an ed25519 public key using the PKIX format.
+func EncodePublicKey(key ed25519.PublicKey) ([]byte, error) {
+	der, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: pemPublicKeyType, Bytes: der}), nil
+}
+
+// EncodePrivateKey PEM encodes an ed25519 private key using the PKCS8 format.
+func EncodePrivateKey(key ed25519.PrivateKey) ([]byte, error) {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: pemPrivateKeyType, Bytes: der}), nil
+}
+
+// ParsePublicKey decodes a PEM encoded ed25519 public key.
+func ParsePublicKey(b []byte) (ed25519.PublicKey, error) {
+	blk, _ := pem.Decode(b)
+	if blk == nil || blk.Type != pemPublicKeyType {
+		return nil, ErrInvalidKey
+	}
+	key, err := x509.ParsePKIXPublicKey(blk.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	pub, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return nil, ErrInvalidKey
+	}
+	return pub, nil
+}
+
+// ParsePrivateKey decodes a PEM encoded ed25519 private key.
+func ParsePrivateKey(b []byte) (ed25519.PrivateKey, error) {
+	blk, _ := pem.Decode(b)
+	if blk == nil || blk.Type != pemPrivateKeyType {
+		return nil, ErrInvalidKey
+	}
+	key, err := x509.ParsePKCS8PrivateKey(blk.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	priv, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, ErrInvalidKey
+	}
+	return priv, nil
+}
+
+// LoadPrivateKey reads a PEM encoded ed25519 private key from a file.
+func LoadPrivateKey(pth string) (ed25519.PrivateKey, error) {
+	b, err := os.ReadFile(pth)
+	if err != nil {
+		return nil, err
+	}
+	key, err := ParsePrivateKey(b)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", pth, err)
+	}
+	return key, nil
+}
+
+// LoadPublicKey reads a PEM encoded ed25519 public key from a file.
+func LoadPublicKey(pth string) (ed25519.PublicKey, error) {
+	b, err := os.ReadFile(pth)
+	if err != nil {
+		return nil, err
+	}
+	key, err := ParsePublicKey(b)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", pth, err)
+	}
+	return key, nil
+}
+
+// LoadTrustedKeys reads every public key file (files ending in PublicKeyExt) in the given
+// directory. Any key file which cannot be parsed is an error, an empty directory is not.
+func LoadTrustedKeys(dir string) (keys []ed25519.PublicKey, err error) {
+	var ents []os.DirEntry
+	if ents, err = os.ReadDir(dir); err != nil {
+		return
+	}
+	for _, ent := range ents {
+		if ent.IsDir() || !strings.HasSuffix(ent.Name(), PublicKeyExt) {
+			continue
+		}
+		var key ed25519.PublicKey
+		if key, err = LoadPublicKey(filepath.Join(dir, ent.Name())); err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return
+}
+
+// SignManifest generates an ed25519 signature over the encoded manifest.
+func SignManifest(key ed25519.PrivateKey, manifest []byte) ([]byte, error) {
+	if len(key) != ed25519.PrivateKeySize {
+		return nil, ErrInvalidKey
+	}
+	return ed25519.Sign(key, manifest), nil
+}
+
+// NewEd25519Verifier returns a SigVerificationFunc which accepts a manifest signed by
+// any one of the provided keys. Unsigned kits fail with ErrMissingSignature.
+func NewEd25519Verifier(keys []ed25519.PublicKey) SigVerificationFunc {
+	return func(manifest []byte, sig []byte) error {
+		if len(keys) == 0 {
+			return ErrNoTrustedKeys
+		} else if len(sig) == 0 {
+			return ErrMissingSignature
+		}
+		for _, k := range keys {
+			if len(k) == ed25519.PublicKeySize && ed25519.Verify(k, manifest, sig) {
+				return nil
+			}
+		}
+		return ErrUntrustedSignature
+	}
+}
+
+// SignKit copies the kit archive in rdr to wtr, replacing any existing manifest
+// signature with one generated by key. The kit is not validated; callers should
+// Verify the kit before signing it.
+func SignKit(rdr io.Reader, wtr io.Writer, key ed25519.PrivateKey) (err error) {
+	var hdr *tar.Header
+	var manifest []byte
+	tr := tar.NewReader(rdr)
+	tw := tar.NewWriter(wtr)
+	for {
+		if hdr, err = tr.Next(); err != nil {
+			if err == io.EOF {
+				err = nil
+				break
+			}
+			return
+		}
+		switch filepath.Base(hdr.Name) {
+		case ManifestSigName:
+			continue //drop the old signature
+		case ManifestName:
+			//hold the manifest so the signature lands ahead of it, just like the Builder
+			lr := io.LimitedReader{R: tr, N: maxManifestSize}
+			if manifest, err = io.ReadAll(&lr); err != nil {
+				return
+			}
+			continue
+		}
+		if err = tw.WriteHeader(hdr); err != nil {
+			return
+		} else if _, err = io.Copy(tw, tr); err != nil {
+			return
+		}
+	}
+	if manifest == nil {
+		return ErrMissingManifest
+	}
+	var sig []byte
+	if sig, err = SignManifest(key, manifest); err != nil {
+		return
+	}
+	hdr = &tar.Header{
+		Typeflag: tar.TypeReg,
+		Mode:     0660,
+		Name:     ManifestSigName,
+		Size:     int64(len(sig)),
+	}
+	if err = tw.WriteHeader(hdr); err != nil {
+		return
+	} else if err = writeAll(tw, sig); err != nil {
+		return
+	}
+	hdr.Name = ManifestName
+	hdr.Size = int64(len(manifest))
+	if err = tw.WriteHeader(hdr); err != nil {
+		return
+	} else if err = writeAll(tw, manifest); err != nil {
+		return
+	}
+	return tw.Close()
+}
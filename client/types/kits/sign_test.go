@@ -0,0 +1,147 @@
+/*************************************************************************
+ * Copyright 2026 Gravwell, Inc. All rights reserved.
+ * Contact: <legal@gravwell.io>
+ *
+ * This software may be modified and distributed under the terms of the
+ * BSD 2-clause license. See the LICENSE file for details.
+ **************************************************************************/
+
+package kits
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type nopCloser struct {
+	*bytes.Buffer
+}
+
+func (nopCloser) Close() error { return nil }
+
+func buildTestKit(t *testing.T, key ed25519.PrivateKey) []byte {
+	bb := nopCloser{bytes.NewBuffer(nil)}
+	pb, err := NewBuilder(defCfg, bb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := genRandomBuff()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = pb.Add(`test1`, Resource, b); err != nil {
+		t.Fatal(err)
+	}
+	var sig []byte
+	if key != nil {
+		mf := pb.Manifest()
+		mb, err := mf.Marshal()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if sig, err = SignManifest(key, mb); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = pb.WriteManifest(sig); err != nil {
+		t.Fatal(err)
+	} else if err = pb.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return bb.Bytes()
+}
+
+func TestKeyEncoding(t *testing.T) {
+	pub, priv, err := GenerateSigningKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pb, err := EncodePublicKey(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	vb, err := EncodePrivateKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p, err := ParsePublicKey(pb); err != nil || !p.Equal(pub) {
+		t.Fatalf("public key did not round trip: %v", err)
+	}
+	if p, err := ParsePrivateKey(vb); err != nil || !p.Equal(priv) {
+		t.Fatalf("private key did not round trip: %v", err)
+	}
+	if _, err = ParsePublicKey(vb); err == nil {
+		t.Fatal("parsed a private key as a public key")
+	}
+
+	dir := t.TempDir()
+	if err = os.WriteFile(filepath.Join(dir, `a`+PublicKeyExt), pb, 0644); err != nil {
+		t.Fatal(err)
+	} else if err = os.WriteFile(filepath.Join(dir, `a`+PrivateKeyExt), vb, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if keys, err := LoadTrustedKeys(dir); err != nil {
+		t.Fatal(err)
+	} else if len(keys) != 1 || !keys[0].Equal(pub) {
+		t.Fatalf("bad trusted keys %v", keys)
+	}
+	if err = os.WriteFile(filepath.Join(dir, `b`+PublicKeyExt), []byte(`garbage`), 0644); err != nil {
+		t.Fatal(err)
+	} else if _, err = LoadTrustedKeys(dir); err == nil {
+		t.Fatal("failed to catch bad key in trusted directory")
+	}
+}
+
+func TestSignedKitVerify(t *testing.T) {
+	pub, priv, err := GenerateSigningKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherPub, otherPriv, err := GenerateSigningKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	verify := func(kit []byte, keys ...ed25519.PublicKey) (bool, error) {
+		signed, _, sigerr, err := Verify(bytes.NewReader(kit), NewEd25519Verifier(keys))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return signed, sigerr
+	}
+
+	signed := buildTestKit(t, priv)
+	if ok, err := verify(signed, otherPub, pub); !ok || err != nil {
+		t.Fatalf("signed kit did not verify: %v", err)
+	}
+	if ok, err := verify(signed, otherPub); ok || !errors.Is(err, ErrUntrustedSignature) {
+		t.Fatalf("untrusted kit verified: %v", err)
+	}
+
+	unsigned := buildTestKit(t, nil)
+	if ok, err := verify(unsigned, pub); ok || !errors.Is(err, ErrMissingSignature) {
+		t.Fatalf("unsigned kit verified: %v", err)
+	}
+
+	//sign the unsigned kit after the fact, then re-sign the signed one with another key
+	bb := bytes.NewBuffer(nil)
+	if err = SignKit(bytes.NewReader(unsigned), bb, priv); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := verify(bb.Bytes(), pub); !ok || err != nil {
+		t.Fatalf("post signed kit did not verify: %v", err)
+	}
+	bb.Reset()
+	if err = SignKit(bytes.NewReader(signed), bb, otherPriv); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := verify(bb.Bytes(), otherPub); !ok || err != nil {
+		t.Fatalf("re-signed kit did not verify: %v", err)
+	}
+	if ok, _ := verify(bb.Bytes(), pub); ok {
+		t.Fatal("old signature survived re-signing")
+	}
+}
@@ -46,6 +46,7 @@ type cfgType struct {
 	OktaConf     map[string]*OktaConf
 	AsanaConf    map[string]*asanaConf
 	ShodanConf   map[string]*ShodanConf
+	Rest         map[string]*restConf
 }
 
 func (c cfgType) Verify() error {
@@ -95,6 +96,9 @@ func GetConfig(path, overlayPath string) (*cfgType, error) {
 	if err := c.ShodanVerify(); err != nil {
 		return nil, err
 	}
+	if err := c.RestVerify(); err != nil {
+		return nil, err
+	}
 
 	//initialize the state store location if its empty
 	if c.Global.State_Store_Location == `` {
@@ -165,6 +169,15 @@ func (c *cfgType) Tags() ([]string, error) {
 			tagMp[v.Tag_Name] = true
 		}
 	}
+	for _, v := range c.Rest {
+		if len(v.Tag_Name) == 0 {
+			continue
+		}
+		if _, ok := tagMp[v.Tag_Name]; !ok {
+			tags = append(tags, v.Tag_Name)
+			tagMp[v.Tag_Name] = true
+		}
+	}
 	if len(tags) == 0 {
 		return nil, errors.New("No tags specified")
 	}
@@ -68,6 +68,7 @@ func main() {
 	buildThinkstHandlerConfig(cfg, src, fetcherTracker, lg, igst, ib, ctx, &wg)
 	buildOktaHandlerConfig(cfg, src, fetcherTracker, lg, igst, ib, ctx, &wg)
 	buildShodanHandlerConfig(cfg, src, fetcherTracker, lg, igst, ib, ctx, &wg)
+	buildRestHandlerConfig(cfg, src, fetcherTracker, lg, igst, ib, ctx, &wg)
 
 	// listen for signals so we can close gracefully
 	utils.WaitForQuit()
@@ -0,0 +1,644 @@
+/*************************************************************************
+ * Copyright 2026 Gravwell, Inc. All rights reserved.
+ * Contact: <legal@gravwell.io>
+ *
+ * This software may be modified and distributed under the terms of the
+ * BSD 2-clause license. See the LICENSE file for details.
+ **************************************************************************/
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/gravwell/gravwell/v3/ingest"
+	"github.com/gravwell/gravwell/v3/ingest/entry"
+	"github.com/gravwell/gravwell/v3/ingest/log"
+	"github.com/gravwell/gravwell/v3/ingest/processors"
+	"github.com/gravwell/gravwell/v3/ingesters/base"
+	"github.com/gravwell/gravwell/v3/timegrinder"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+	"golang.org/x/time/rate"
+)
+
+const (
+	restMaxBodySize   = 64 * 1024 * 1024
+	restMaxBackoff    = time.Minute
+	restMaxRetryAfter = 10 * time.Minute
+)
+
+var (
+	errRestNotArray  = errors.New("records path does not point at an array")
+	errRestPageLimit = errors.New("hit the page limit before the window was complete")
+
+	// restTemplateFuncs are available in URL and Body templates, e.g. {{.Start | rfc3339}}
+	restTemplateFuncs = template.FuncMap{
+		"rfc3339": func(t time.Time) string { return t.UTC().Format(time.RFC3339) },
+		"unix":    func(t time.Time) int64 { return t.Unix() },
+		"unixms":  func(t time.Time) int64 { return t.UnixMilli() },
+		"format":  func(layout string, t time.Time) string { return t.UTC().Format(layout) },
+		"query":   url.QueryEscape,
+	}
+)
+
+// restWindow is handed to the URL and Body templates
+type restWindow struct {
+	Start time.Time
+	End   time.Time
+}
+
+// restCheckpoint is stored in the Key of the tracked object state. It holds the IDs of
+// records that could show up again in the next window so that they are not ingested twice.
+// A window that stopped at the page limit is resumed from the page it stopped at, so the
+// checkpoint also holds that page and the end of the window it belongs to.
+type restCheckpoint struct {
+	Seen   map[string]int64 // record ID to record timestamp in unix nanoseconds
+	Next   string           `json:",omitempty"` // URL of the next page of an incomplete window
+	Offset int              `json:",omitempty"` // record offset of the next page for offset pagination
+	End    time.Time        // end of the incomplete window
+}
+
+type restHandlerConfig struct {
+	name string
+	conf *restConf
+	p    restParams
+	tag  entry.EntryTag
+	src  net.IP
+	wg   *sync.WaitGroup
+	proc *processors.ProcessorSet
+	ctx  context.Context
+	ot   *objectTracker
+	cli  *http.Client
+	rl   *rate.Limiter
+	tg   *timegrinder.TimeGrinder
+}
+
+func buildRestHandlerConfig(cfg *cfgType, src net.IP, ot *objectTracker, lg *log.Logger, igst *ingest.IngestMuxer, ib base.IngesterBase, ctx context.Context, wg *sync.WaitGroup) {
+	if src == nil {
+		src = net.ParseIP("127.0.0.1")
+	}
+	for k, v := range cfg.Rest {
+		tag, err := igst.GetTag(v.Tag_Name)
+		if err != nil {
+			lg.Fatal("failed to resolve tag", log.KV("rest", k), log.KV("tag", v.Tag_Name), log.KVErr(err))
+		}
+		p, err := v.params()
+		if err != nil {
+			lg.Fatal("invalid configuration", log.KV("rest", k), log.KVErr(err))
+		}
+		tg, err := timegrinder.New(timegrinder.Config{})
+		if err != nil {
+			lg.Fatal("failed to create timegrinder", log.KV("rest", k), log.KVErr(err))
+		}
+		h := &restHandlerConfig{
+			name: k,
+			conf: v,
+			p:    p,
+			tag:  tag,
+			src:  src,
+			wg:   wg,
+			ctx:  ctx,
+			ot:   ot,
+			tg:   tg,
+			rl:   rate.NewLimiter(rate.Every(time.Minute/time.Duration(v.RateLimit)), 1),
+		}
+		h.cli = h.newClient()
+		if h.proc, err = cfg.Preprocessor.ProcessorSet(igst, v.Preprocessor); err != nil {
+			lg.FatalCode(0, "preprocessor construction error", log.KVErr(err))
+		}
+
+		// seed the state tracker so that we start at the configured time
+		if _, ok := ot.Get(restTrackerGroup, k); !ok {
+			start := v.StartTime
+			if start.IsZero() {
+				start = time.Now()
+			}
+			if err = h.saveCheckpoint(start, restCheckpoint{}); err != nil {
+				lg.Fatal("failed to set state tracker", log.KV("rest", k), log.KVErr(err))
+			}
+		}
+
+		wg.Add(1)
+		go h.run()
+	}
+}
+
+func (h *restHandlerConfig) newClient() *http.Client {
+	cli := &http.Client{Timeout: h.p.timeout}
+	if h.conf.Auth_Type != restAuthOAuth2 {
+		return cli
+	}
+	cc := clientcredentials.Config{
+		ClientID:       h.conf.Client_ID,
+		ClientSecret:   h.conf.Client_Secret,
+		TokenURL:       h.conf.Token_URL,
+		Scopes:         h.conf.Scope,
+		EndpointParams: url.Values(h.p.tokenParam),
+	}
+	if h.conf.Token_Auth_Body {
+		cc.AuthStyle = oauth2.AuthStyleInParams
+	}
+	// the token source caches the token and refreshes it when it expires
+	occ := cc.Client(context.WithValue(h.ctx, oauth2.HTTPClient, cli))
+	occ.Timeout = h.p.timeout
+	return occ
+}
+
+func (h *restHandlerConfig) loadCheckpoint() (start time.Time, cp restCheckpoint) {
+	state, ok := h.ot.Get(restTrackerGroup, h.name)
+	if !ok {
+		lg.Fatal("failed to get state tracker", log.KV("rest", h.name))
+	}
+	start = state.LatestTime
+	if state.Key != `` {
+		if err := json.Unmarshal([]byte(state.Key), &cp); err != nil {
+			lg.Warn("discarding corrupt checkpoint", log.KV("rest", h.name), log.KVErr(err))
+		}
+	}
+	if cp.Seen == nil {
+		cp.Seen = map[string]int64{}
+	}
+	return
+}
+
+func (h *restHandlerConfig) saveCheckpoint(start time.Time, cp restCheckpoint) error {
+	key, err := json.Marshal(cp)
+	if err != nil {
+		return err
+	}
+	state := trackedObjectState{
+		Updated:    time.Now(),
+		LatestTime: start,
+		Key:        string(key),
+	}
+	if err = h.ot.Set(restTrackerGroup, h.name, state, false); err != nil {
+		return err
+	}
+	return h.ot.Flush()
+}
+
+func (h *restHandlerConfig) run() {
+	defer h.wg.Done()
+	for {
+		start, cp := h.loadCheckpoint()
+		now := time.Now()
+		end := now.Add(-h.p.lag)
+		if h.p.window > 0 && end.Sub(start) > h.p.window {
+			end = start.Add(h.p.window)
+		}
+		if cp.Next != `` {
+			end = cp.End // finish the window we stopped part way through
+		}
+		if end.After(start) {
+			err := h.fetchWindow(restWindow{Start: start, End: end}, &cp)
+			if errors.Is(err, errRestPageLimit) {
+				lg.Warn("hit the page limit before the window was complete, resuming from the next page",
+					log.KV("rest", h.name), log.KV("max-pages", h.conf.Max_Pages))
+			} else if err != nil {
+				// hold the window start but keep the IDs we already sent so the retry does not duplicate them
+				lg.Error("failed to fetch window", log.KV("rest", h.name), log.KV("start", start), log.KV("end", end), log.KVErr(err))
+			} else {
+				// anything before the new start can never be fetched again
+				for id, ts := range cp.Seen {
+					if ts < end.UnixNano() {
+						delete(cp.Seen, id)
+					}
+				}
+				start = end
+				cp.Next, cp.Offset, cp.End = ``, 0, time.Time{}
+			}
+			if err := h.saveCheckpoint(start, cp); err != nil {
+				lg.Fatal("failed to set state tracker", log.KV("rest", h.name), log.KVErr(err))
+			}
+			// keep going if we are catching up on a backlog or the rest of a window
+			if (err == nil && now.Add(-h.p.lag).Sub(end) > h.p.interval) || errors.Is(err, errRestPageLimit) {
+				if h.ctx.Err() != nil {
+					break
+				}
+				continue
+			}
+		}
+		if quitableSleep(h.ctx, h.p.interval) {
+			break
+		}
+	}
+	lg.Info("Exiting", log.KV("rest", h.name))
+}
+
+func (h *restHandlerConfig) render(t *template.Template, w restWindow) (string, error) {
+	if t == nil {
+		return ``, nil
+	}
+	bb := bytes.NewBuffer(nil)
+	if err := t.Execute(bb, w); err != nil {
+		return ``, err
+	}
+	return bb.String(), nil
+}
+
+// fetchWindow pulls every page for the window, records are sent as each page arrives
+// and their IDs are added to the checkpoint. The window starts from the checkpoint page if
+// one is set; if the page limit is hit the page we stopped at is stored in the checkpoint
+// and errRestPageLimit is returned.
+func (h *restHandlerConfig) fetchWindow(w restWindow, cp *restCheckpoint) error {
+	base, err := h.render(h.p.urlTmpl, w)
+	if err != nil {
+		return fmt.Errorf("failed to render URL: %w", err)
+	}
+	body, err := h.render(h.p.bodyTmpl, w)
+	if err != nil {
+		return fmt.Errorf("failed to render body: %w", err)
+	}
+	baseURL, err := url.Parse(base)
+	if err != nil {
+		return fmt.Errorf("invalid URL %q: %w", base, err)
+	}
+	cur := baseURL
+	if h.conf.Pagination == restPageOffset {
+		cur = withParams(baseURL, h.conf.Limit_Param, strconv.Itoa(h.conf.Page_Size), h.conf.Offset_Param, "0")
+	}
+
+	var offset, total int
+	var lastCursor string
+	if cp.Next != `` {
+		if cur, err = url.Parse(cp.Next); err != nil {
+			return fmt.Errorf("invalid checkpoint page URL %q: %w", cp.Next, err)
+		}
+		offset = cp.Offset
+	}
+	for page := 0; page < h.conf.Max_Pages; page++ {
+		doc, hdr, err := h.do(cur, body)
+		if err != nil {
+			return err
+		}
+		recs, err := restRecords(doc, h.conf.Records_Path)
+		if err != nil {
+			return err
+		}
+		total += h.handleRecords(recs, w, cp.Seen)
+
+		var next *url.URL
+		switch h.conf.Pagination {
+		case restPageCursor:
+			if c, ok := jsonPathString(doc, h.conf.Cursor_Field); ok && c != `` && c != lastCursor {
+				lastCursor = c
+				next = withParams(baseURL, h.conf.Cursor_Param, c)
+			}
+		case restPageLink:
+			if l := linkNext(hdr.Values("Link")); l != `` {
+				next, err = cur.Parse(l)
+			}
+		case restPageOffset:
+			if len(recs) >= h.conf.Page_Size {
+				offset += len(recs)
+				next = withParams(baseURL, h.conf.Limit_Param, strconv.Itoa(h.conf.Page_Size), h.conf.Offset_Param, strconv.Itoa(offset))
+			}
+		case restPageNextURL:
+			if l, ok := jsonPathString(doc, h.conf.Next_URL_Field); ok && l != `` {
+				next, err = cur.Parse(l)
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("invalid next page URL: %w", err)
+		} else if next == nil || len(recs) == 0 {
+			lg.Info("window complete", log.KV("rest", h.name), log.KV("records", total), log.KV("pages", page+1))
+			return nil
+		}
+		cur = next
+	}
+	cp.Next, cp.Offset, cp.End = cur.String(), offset, w.End
+	return errRestPageLimit
+}
+
+// handleRecords extracts timestamps and IDs, drops anything we have already seen, and sends the rest
+func (h *restHandlerConfig) handleRecords(recs []json.RawMessage, w restWindow, seen map[string]int64) (n int) {
+	now := time.Now()
+	for _, rec := range recs {
+		ts, ok := h.recordTime(rec)
+		if !ok {
+			ts = now
+		} else if ts.Before(w.Start) {
+			continue // covered by an earlier window
+		}
+		id := h.recordID(rec)
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		ent := &entry.Entry{
+			Tag:  h.tag,
+			TS:   entry.FromStandard(ts),
+			SRC:  h.src,
+			Data: []byte(rec),
+		}
+		if err := h.proc.ProcessContext(ent, h.ctx); err != nil {
+			lg.Error("failed to send entry", log.KV("rest", h.name), log.KVErr(err))
+			continue
+		}
+		seen[id] = ts.UnixNano()
+		n++
+	}
+	return
+}
+
+func (h *restHandlerConfig) recordID(rec json.RawMessage) string {
+	if h.conf.ID_Field != `` {
+		if v, ok := jsonPathString(rec, h.conf.ID_Field); ok && v != `` {
+			return v
+		}
+	}
+	sum := sha256.Sum256(rec)
+	return hex.EncodeToString(sum[:])
+}
+
+func (h *restHandlerConfig) recordTime(rec json.RawMessage) (t time.Time, ok bool) {
+	if h.conf.Timestamp_Field == `` {
+		return
+	}
+	raw, ok := jsonPath(rec, h.conf.Timestamp_Field)
+	if !ok {
+		return
+	}
+	return parseRestTime(raw, h.conf.Timestamp_Format, h.tg)
+}
+
+// parseRestTime handles numeric epochs and strings; auto detection treats very large
+// epoch values as milliseconds and hands strings to timegrinder.
+func parseRestTime(raw json.RawMessage, format string, tg *timegrinder.TimeGrinder) (t time.Time, ok bool) {
+	var v interface{}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	if err := dec.Decode(&v); err != nil {
+		return
+	}
+	var s string
+	switch x := v.(type) {
+	case json.Number:
+		s = x.String()
+	case string:
+		s = x
+	default:
+		return
+	}
+	switch format {
+	case restTSUnix, restTSUnixMs, restTSAuto:
+		if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+			if format == restTSUnixMs || (format == restTSAuto && i > 1e11) {
+				return time.UnixMilli(i).UTC(), true
+			}
+			return time.Unix(i, 0).UTC(), true
+		} else if f, err := strconv.ParseFloat(s, 64); err == nil {
+			if format == restTSUnixMs || (format == restTSAuto && f > 1e11) {
+				f /= 1000
+			}
+			sec := int64(f)
+			return time.Unix(sec, int64((f-float64(sec))*1e9)).UTC(), true
+		} else if format != restTSAuto {
+			return
+		}
+		if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+			return t, true
+		}
+		if tg != nil {
+			if t, ok, _ = tg.Extract([]byte(s)); ok {
+				return
+			}
+		}
+		return time.Time{}, false
+	}
+	if t, err := time.Parse(format, s); err == nil {
+		return t, true
+	}
+	return
+}
+
+// do performs the request, retrying throttled requests, server errors, and network failures
+func (h *restHandlerConfig) do(u *url.URL, body string) (doc json.RawMessage, hdr http.Header, err error) {
+	for attempt := 0; ; attempt++ {
+		if err = h.rl.Wait(h.ctx); err != nil {
+			return
+		}
+		var retry bool
+		var delay time.Duration
+		if doc, hdr, retry, delay, err = h.doOnce(u, body); err == nil || !retry {
+			return
+		} else if attempt >= h.conf.Max_Retries {
+			err = fmt.Errorf("giving up after %d retries: %w", attempt, err)
+			return
+		}
+		if delay <= 0 {
+			delay = restBackoff(attempt)
+		}
+		lg.Warn("request failed, retrying", log.KV("rest", h.name), log.KV("delay", delay), log.KVErr(err))
+		if quitableSleep(h.ctx, delay) {
+			err = h.ctx.Err()
+			return
+		}
+	}
+}
+
+func (h *restHandlerConfig) doOnce(u *url.URL, body string) (doc json.RawMessage, hdr http.Header, retry bool, delay time.Duration, err error) {
+	var rdr io.Reader
+	if body != `` {
+		rdr = strings.NewReader(body)
+	}
+	var req *http.Request
+	if req, err = http.NewRequestWithContext(h.ctx, h.conf.Method, u.String(), rdr); err != nil {
+		return
+	}
+	req.Header.Set(`Accept`, `application/json`)
+	if body != `` {
+		req.Header.Set(`Content-Type`, `application/json`)
+	}
+	for k, v := range h.p.headers {
+		req.Header[k] = v
+	}
+	switch h.conf.Auth_Type {
+	case restAuthBearer:
+		req.Header.Set(`Authorization`, `Bearer `+h.conf.Token)
+	case restAuthBasic:
+		req.SetBasicAuth(h.conf.Username, h.conf.Password)
+	}
+
+	var resp *http.Response
+	if resp, err = h.cli.Do(req); err != nil {
+		retry = h.ctx.Err() == nil
+		return
+	}
+	defer resp.Body.Close()
+	hdr = resp.Header
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		err = fmt.Errorf("%s: %s", resp.Status, bytes.TrimSpace(msg))
+		retry = resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+		delay = retryAfter(resp.Header.Get(`Retry-After`), time.Now())
+		return
+	}
+	var b []byte
+	if b, err = io.ReadAll(io.LimitReader(resp.Body, restMaxBodySize+1)); err != nil {
+		retry = true
+		return
+	} else if len(b) > restMaxBodySize {
+		err = fmt.Errorf("response larger than %d bytes", restMaxBodySize)
+		return
+	} else if !json.Valid(b) {
+		err = errors.New("response is not valid JSON")
+		return
+	}
+	doc = json.RawMessage(b)
+	return
+}
+
+// retryAfter parses a Retry-After header, which is either a number of seconds or an HTTP date
+func retryAfter(v string, now time.Time) (d time.Duration) {
+	if v = strings.TrimSpace(v); v == `` {
+		return
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		d = time.Duration(secs) * time.Second
+	} else if t, err := http.ParseTime(v); err == nil {
+		d = t.Sub(now)
+	}
+	if d < 0 {
+		d = 0
+	} else if d > restMaxRetryAfter {
+		d = restMaxRetryAfter
+	}
+	return
+}
+
+func restBackoff(attempt int) time.Duration {
+	if attempt > 6 {
+		return restMaxBackoff
+	}
+	if d := time.Second << uint(attempt); d < restMaxBackoff {
+		return d
+	}
+	return restMaxBackoff
+}
+
+// withParams returns a copy of u with the query parameters set, given as name/value pairs
+func withParams(u *url.URL, kv ...string) *url.URL {
+	r := *u
+	q := r.Query()
+	for i := 0; i+1 < len(kv); i += 2 {
+		q.Set(kv[i], kv[i+1])
+	}
+	r.RawQuery = q.Encode()
+	return &r
+}
+
+// linkNext pulls the rel="next" target out of RFC5988 Link headers
+func linkNext(hdrs []string) string {
+	for _, hdr := range hdrs {
+		for _, link := range strings.Split(hdr, ",") {
+			parts := strings.Split(link, ";")
+			target := strings.TrimSpace(parts[0])
+			if !strings.HasPrefix(target, "<") || !strings.HasSuffix(target, ">") {
+				continue
+			}
+			for _, p := range parts[1:] {
+				k, v, ok := strings.Cut(strings.TrimSpace(p), "=")
+				if !ok || !strings.EqualFold(strings.TrimSpace(k), "rel") {
+					continue
+				}
+				for _, rel := range strings.Fields(strings.Trim(strings.TrimSpace(v), `"`)) {
+					if strings.EqualFold(rel, "next") {
+						return target[1 : len(target)-1]
+					}
+				}
+			}
+		}
+	}
+	return ``
+}
+
+// jsonPath walks a dot separated path of object keys and array indexes without
+// re-encoding anything, so records are ingested exactly as the API sent them.
+func jsonPath(doc json.RawMessage, path string) (json.RawMessage, bool) {
+	if path == `` {
+		return doc, true
+	}
+	cur := doc
+	for _, p := range strings.Split(path, ".") {
+		trimmed := bytes.TrimSpace(cur)
+		if len(trimmed) == 0 {
+			return nil, false
+		}
+		switch trimmed[0] {
+		case '{':
+			var obj map[string]json.RawMessage
+			if err := json.Unmarshal(trimmed, &obj); err != nil {
+				return nil, false
+			}
+			var ok bool
+			if cur, ok = obj[p]; !ok {
+				return nil, false
+			}
+		case '[':
+			idx, err := strconv.Atoi(p)
+			if err != nil {
+				return nil, false
+			}
+			var arr []json.RawMessage
+			if err = json.Unmarshal(trimmed, &arr); err != nil || idx < 0 || idx >= len(arr) {
+				return nil, false
+			}
+			cur = arr[idx]
+		default:
+			return nil, false
+		}
+	}
+	return cur, true
+}
+
+// jsonPathString returns the value at path as a string, numbers are returned verbatim
+func jsonPathString(doc json.RawMessage, path string) (string, bool) {
+	raw, ok := jsonPath(doc, path)
+	if !ok {
+		return ``, false
+	}
+	raw = bytes.TrimSpace(raw)
+	if bytes.Equal(raw, []byte(`null`)) {
+		return ``, false
+	}
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s, true
+	}
+	var n json.Number
+	if err := json.Unmarshal(raw, &n); err == nil {
+		return n.String(), true
+	}
+	return ``, false
+}
+
+func restRecords(doc json.RawMessage, path string) (recs []json.RawMessage, err error) {
+	raw, ok := jsonPath(doc, path)
+	if !ok {
+		// an absent records field usually means an empty page
+		return nil, nil
+	}
+	if raw = bytes.TrimSpace(raw); bytes.Equal(raw, []byte(`null`)) {
+		return nil, nil
+	} else if len(raw) == 0 || raw[0] != '[' {
+		return nil, errRestNotArray
+	}
+	err = json.Unmarshal(raw, &recs)
+	return
+}
@@ -0,0 +1,274 @@
+/*************************************************************************
+ * Copyright 2026 Gravwell, Inc. All rights reserved.
+ * Contact: <legal@gravwell.io>
+ *
+ * This software may be modified and distributed under the terms of the
+ * BSD 2-clause license. See the LICENSE file for details.
+ **************************************************************************/
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"text/template"
+	"time"
+)
+
+const (
+	restTrackerGroup = "rest"
+
+	restDefaultInterval   = time.Minute
+	restDefaultPageSize   = 100
+	restDefaultMaxPages   = 1000
+	restDefaultMaxRetries = 5
+	restDefaultRateLimit  = 60
+	restDefaultTimeout    = 30 * time.Second
+
+	restAuthNone   = "none"
+	restAuthBearer = "bearer"
+	restAuthBasic  = "basic"
+	restAuthOAuth2 = "oauth2"
+
+	restPageNone    = "none"
+	restPageCursor  = "cursor"
+	restPageLink    = "link"
+	restPageOffset  = "offset"
+	restPageNextURL = "next-url"
+
+	restTSAuto   = "auto"
+	restTSUnix   = "unix"
+	restTSUnixMs = "unixms"
+)
+
+/*
+restConf is a config only fetcher for JSON REST APIs. The URL is a Go template which is
+rendered for every polling window using the restWindow type.
+*/
+type restConf struct {
+	StartTime    time.Time
+	Tag_Name     string
+	Preprocessor []string
+	RateLimit    int // requests per minute
+
+	URL      string
+	Method   string
+	Body     string   // optional request body template
+	Header   []string // additional headers in "Name: value" form
+	Interval string   // how often to poll
+	Window   string   // maximum size of a single polling window, empty means now
+	Lag      string   // how far behind now the window ends, lets slow APIs settle
+	Timeout  string
+
+	Auth_Type       string
+	Token           string
+	Username        string
+	Password        string
+	Token_URL       string
+	Client_ID       string
+	Client_Secret   string
+	Scope           []string
+	Token_Param     []string // extra parameters sent to the OAuth2 token endpoint, "name=value"
+	Token_Auth_Body bool     // send OAuth2 client credentials in the body rather than basic auth
+
+	Records_Path     string // dot separated path to the record array, empty means the document is the array
+	Timestamp_Field  string // dot separated path to the timestamp within a record
+	Timestamp_Format string // auto, unix, unixms, or a Go time layout
+	ID_Field         string // dot separated path to a unique ID within a record, defaults to a hash of the record
+
+	Pagination     string
+	Cursor_Field   string // response path holding the next cursor
+	Cursor_Param   string // query parameter the cursor is sent in
+	Next_URL_Field string // response path holding the next page URL
+	Offset_Param   string
+	Limit_Param    string
+	Page_Size      int
+	Max_Pages      int
+
+	Max_Retries int
+}
+
+type restParams struct {
+	interval   time.Duration
+	window     time.Duration
+	lag        time.Duration
+	timeout    time.Duration
+	urlTmpl    *template.Template
+	bodyTmpl   *template.Template
+	headers    http.Header
+	tokenParam map[string][]string
+}
+
+func parseRestDuration(name, v string, def time.Duration) (time.Duration, error) {
+	if v = strings.TrimSpace(v); v == `` {
+		return def, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s %q: %w", name, v, err)
+	} else if d < 0 {
+		return 0, fmt.Errorf("invalid %s %q: must not be negative", name, v)
+	}
+	return d, nil
+}
+
+// normalize fills in defaults and lowercases the enumerations
+func (rc *restConf) normalize() {
+	if rc.Method = strings.ToUpper(strings.TrimSpace(rc.Method)); rc.Method == `` {
+		rc.Method = http.MethodGet
+	}
+	if rc.Auth_Type = strings.ToLower(strings.TrimSpace(rc.Auth_Type)); rc.Auth_Type == `` {
+		rc.Auth_Type = restAuthNone
+	}
+	if rc.Pagination = strings.ToLower(strings.TrimSpace(rc.Pagination)); rc.Pagination == `` {
+		rc.Pagination = restPageNone
+	}
+	if rc.Timestamp_Format = strings.TrimSpace(rc.Timestamp_Format); rc.Timestamp_Format == `` {
+		rc.Timestamp_Format = restTSAuto
+	}
+	if rc.Page_Size <= 0 {
+		rc.Page_Size = restDefaultPageSize
+	}
+	if rc.Max_Pages <= 0 {
+		rc.Max_Pages = restDefaultMaxPages
+	}
+	if rc.Max_Retries <= 0 {
+		rc.Max_Retries = restDefaultMaxRetries
+	}
+	if rc.RateLimit <= 0 {
+		rc.RateLimit = restDefaultRateLimit
+	}
+	if rc.Cursor_Param == `` {
+		rc.Cursor_Param = "cursor"
+	}
+	if rc.Offset_Param == `` {
+		rc.Offset_Param = "offset"
+	}
+	if rc.Limit_Param == `` {
+		rc.Limit_Param = "limit"
+	}
+}
+
+// params validates the configuration and builds the parsed form used by the handler
+func (rc *restConf) params() (p restParams, err error) {
+	rc.normalize()
+	if rc.Tag_Name == `` {
+		err = errors.New("Tag-Name not specified")
+		return
+	} else if rc.URL == `` {
+		err = errors.New("URL not specified")
+		return
+	}
+	if p.urlTmpl, err = template.New("url").Funcs(restTemplateFuncs).Option("missingkey=error").Parse(rc.URL); err != nil {
+		err = fmt.Errorf("invalid URL template: %w", err)
+		return
+	}
+	if rc.Body != `` {
+		if p.bodyTmpl, err = template.New("body").Funcs(restTemplateFuncs).Option("missingkey=error").Parse(rc.Body); err != nil {
+			err = fmt.Errorf("invalid Body template: %w", err)
+			return
+		}
+	}
+	if p.interval, err = parseRestDuration("Interval", rc.Interval, restDefaultInterval); err != nil {
+		return
+	} else if p.interval == 0 {
+		err = errors.New("Interval must be greater than zero")
+		return
+	}
+	if p.window, err = parseRestDuration("Window", rc.Window, 0); err != nil {
+		return
+	} else if p.lag, err = parseRestDuration("Lag", rc.Lag, 0); err != nil {
+		return
+	} else if p.timeout, err = parseRestDuration("Timeout", rc.Timeout, restDefaultTimeout); err != nil {
+		return
+	}
+
+	p.headers = http.Header{}
+	for _, h := range rc.Header {
+		name, val, ok := strings.Cut(h, ":")
+		if name = strings.TrimSpace(name); !ok || name == `` {
+			err = fmt.Errorf("invalid Header %q, must be in the form 'Name: value'", h)
+			return
+		}
+		p.headers.Add(name, strings.TrimSpace(val))
+	}
+
+	switch rc.Auth_Type {
+	case restAuthNone:
+	case restAuthBearer:
+		if rc.Token == `` {
+			err = errors.New("Token is required for bearer authentication")
+		}
+	case restAuthBasic:
+		if rc.Username == `` {
+			err = errors.New("Username is required for basic authentication")
+		}
+	case restAuthOAuth2:
+		if rc.Token_URL == `` || rc.Client_ID == `` || rc.Client_Secret == `` {
+			err = errors.New("Token-URL, Client-ID, and Client-Secret are required for oauth2 authentication")
+		}
+		p.tokenParam = map[string][]string{}
+		for _, tp := range rc.Token_Param {
+			name, val, ok := strings.Cut(tp, "=")
+			if !ok || name == `` {
+				err = fmt.Errorf("invalid Token-Param %q, must be in the form 'name=value'", tp)
+				break
+			}
+			p.tokenParam[name] = append(p.tokenParam[name], val)
+		}
+	default:
+		err = fmt.Errorf("invalid Auth-Type %q", rc.Auth_Type)
+	}
+	if err != nil {
+		return
+	}
+
+	switch rc.Pagination {
+	case restPageNone, restPageLink, restPageOffset:
+	case restPageCursor:
+		if rc.Cursor_Field == `` {
+			err = errors.New("Cursor-Field is required for cursor pagination")
+		}
+	case restPageNextURL:
+		if rc.Next_URL_Field == `` {
+			err = errors.New("Next-URL-Field is required for next-url pagination")
+		}
+	default:
+		err = fmt.Errorf("invalid Pagination %q", rc.Pagination)
+	}
+	if err != nil {
+		return
+	}
+
+	switch rc.Timestamp_Format {
+	case restTSAuto, restTSUnix, restTSUnixMs:
+	default:
+		// must be a Go layout, make sure it actually contains something that looks like a time
+		if ref := time.Unix(0, 0).UTC().Format(rc.Timestamp_Format); ref == rc.Timestamp_Format {
+			err = fmt.Errorf("invalid Timestamp-Format %q: no time elements, it must be a Go layout", rc.Timestamp_Format)
+		} else if _, lerr := time.Parse(rc.Timestamp_Format, ref); lerr != nil {
+			err = fmt.Errorf("invalid Timestamp-Format %q: %w", rc.Timestamp_Format, lerr)
+		}
+	}
+	return
+}
+
+/*
+Build the verify bits for the above conf.
+*/
+func (c cfgType) RestVerify() error {
+	for k, v := range c.Rest {
+		if err := c.Preprocessor.Validate(); err != nil {
+			return err
+		}
+		if err := c.Preprocessor.CheckProcessors(v.Preprocessor); err != nil {
+			return fmt.Errorf("Rest %s preprocessor invalid: %v", k, err)
+		}
+		if _, err := v.params(); err != nil {
+			return fmt.Errorf("Rest %s: %w", k, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,674 @@
+/*************************************************************************
+ * Copyright 2026 Gravwell, Inc. All rights reserved.
+ * Contact: <legal@gravwell.io>
+ *
+ * This software may be modified and distributed under the terms of the
+ * BSD 2-clause license. See the LICENSE file for details.
+ **************************************************************************/
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gravwell/gravwell/v3/ingest/entry"
+	"github.com/gravwell/gravwell/v3/ingest/log"
+	"github.com/gravwell/gravwell/v3/ingest/processors"
+	"github.com/gravwell/gravwell/v3/timegrinder"
+	"golang.org/x/time/rate"
+)
+
+type captureWriter struct {
+	sync.Mutex
+	ents []*entry.Entry
+}
+
+func (c *captureWriter) WriteEntry(ent *entry.Entry) error {
+	c.Lock()
+	c.ents = append(c.ents, ent)
+	c.Unlock()
+	return nil
+}
+
+func (c *captureWriter) WriteEntryContext(ctx context.Context, ent *entry.Entry) error {
+	return c.WriteEntry(ent)
+}
+
+func (c *captureWriter) WriteBatch(ents []*entry.Entry) error {
+	for _, ent := range ents {
+		c.WriteEntry(ent)
+	}
+	return nil
+}
+
+func (c *captureWriter) WriteBatchContext(ctx context.Context, ents []*entry.Entry) error {
+	return c.WriteBatch(ents)
+}
+
+func (c *captureWriter) data() (r []string) {
+	c.Lock()
+	defer c.Unlock()
+	for _, ent := range c.ents {
+		r = append(r, string(ent.Data))
+	}
+	return
+}
+
+// newTestRest builds a handler the way buildRestHandlerConfig does without an ingest muxer
+func newTestRest(t *testing.T, rc *restConf) (*restHandlerConfig, *captureWriter) {
+	t.Helper()
+	lg = log.NewDiscardLogger()
+	if rc.Tag_Name == `` {
+		rc.Tag_Name = `rest`
+	}
+	p, err := rc.params()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tg, err := timegrinder.New(timegrinder.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ot, err := NewObjectTracker(filepath.Join(t.TempDir(), `state`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	cw := &captureWriter{}
+	h := &restHandlerConfig{
+		name: `test`,
+		conf: rc,
+		p:    p,
+		tag:  1,
+		wg:   &sync.WaitGroup{},
+		proc: processors.NewProcessorSet(cw),
+		ctx:  ctx,
+		ot:   ot,
+		tg:   tg,
+		rl:   rate.NewLimiter(rate.Inf, 1),
+	}
+	h.cli = h.newClient()
+	return h, cw
+}
+
+func testWindow() restWindow {
+	return restWindow{Start: time.Unix(1700000000, 0).UTC(), End: time.Unix(1700003600, 0).UTC()}
+}
+
+func testRecords(n int) (recs []string) {
+	for i := 0; i < n; i++ {
+		recs = append(recs, fmt.Sprintf(`{"id":"r%d","ts":%d}`, i, 1700000000+i))
+	}
+	return
+}
+
+func page(recs []string, extra string) string {
+	return fmt.Sprintf(`{"data":[%s]%s}`, strings.Join(recs, `,`), extra)
+}
+
+func TestRestPagination(t *testing.T) {
+	recs := testRecords(5)
+	tests := []struct {
+		name string
+		conf restConf
+		// serve returns the body for a request and any Link header
+		serve func(r *http.Request, base string) (body, link string)
+	}{
+		{
+			name: restPageLink,
+			conf: restConf{Pagination: restPageLink},
+			serve: func(r *http.Request, base string) (string, string) {
+				p, _ := strconv.Atoi(r.URL.Query().Get(`page`))
+				end := min(2*p+2, len(recs))
+				var link string
+				if end < len(recs) {
+					link = fmt.Sprintf(`<%s/events?page=%d>; rel="next", <%s/events?page=0>; rel="first"`, base, p+1, base)
+				}
+				return page(recs[2*p:end], ``), link
+			},
+		},
+		{
+			name: restPageCursor,
+			conf: restConf{Pagination: restPageCursor, Cursor_Field: `meta.next`, Cursor_Param: `after`},
+			serve: func(r *http.Request, base string) (string, string) {
+				p, _ := strconv.Atoi(strings.TrimPrefix(r.URL.Query().Get(`after`), `c`))
+				end := min(2*p+2, len(recs))
+				next := ``
+				if end < len(recs) {
+					next = fmt.Sprintf(`c%d`, p+1)
+				}
+				return page(recs[2*p:end], fmt.Sprintf(`,"meta":{"next":%q}`, next)), ``
+			},
+		},
+		{
+			name: restPageOffset,
+			conf: restConf{Pagination: restPageOffset, Page_Size: 2},
+			serve: func(r *http.Request, base string) (string, string) {
+				off, _ := strconv.Atoi(r.URL.Query().Get(`offset`))
+				lim, _ := strconv.Atoi(r.URL.Query().Get(`limit`))
+				return page(recs[off:min(off+lim, len(recs))], ``), ``
+			},
+		},
+		{
+			name: restPageNextURL,
+			conf: restConf{Pagination: restPageNextURL, Next_URL_Field: `links.next`},
+			serve: func(r *http.Request, base string) (string, string) {
+				p, _ := strconv.Atoi(r.URL.Query().Get(`p`))
+				end := min(2*p+2, len(recs))
+				next := `null`
+				if end < len(recs) {
+					next = fmt.Sprintf(`"/events?p=%d"`, p+1) // relative to the current page
+				}
+				return page(recs[2*p:end], fmt.Sprintf(`,"links":{"next":%s}`, next)), ``
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var reqs int32
+			var srv *httptest.Server
+			srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if atomic.AddInt32(&reqs, 1) == 1 && r.URL.Query().Get(`since`) != `1700000000` {
+					t.Errorf("first request did not carry the window start: %v", r.URL)
+				}
+				body, link := tt.serve(r, srv.URL)
+				if link != `` {
+					w.Header().Set(`Link`, link)
+				}
+				w.Write([]byte(body))
+			}))
+			defer srv.Close()
+			rc := tt.conf
+			rc.URL = srv.URL + `/events?since={{.Start | unix}}`
+			rc.Records_Path = `data`
+			rc.ID_Field = `id`
+			rc.Timestamp_Field = `ts`
+			h, cw := newTestRest(t, &rc)
+			cp := restCheckpoint{Seen: map[string]int64{}}
+			if err := h.fetchWindow(testWindow(), &cp); err != nil {
+				t.Fatal(err)
+			}
+			if got := cw.data(); strings.Join(got, `,`) != strings.Join(recs, `,`) {
+				t.Fatalf("got %v", got)
+			} else if n := atomic.LoadInt32(&reqs); n != 3 {
+				t.Fatalf("made %d requests", n)
+			} else if len(cp.Seen) != len(recs) {
+				t.Fatalf("seen %v", cp.Seen)
+			}
+		})
+	}
+}
+
+func TestRestPageLimit(t *testing.T) {
+	recs := testRecords(5)
+	tests := []restConf{
+		{Pagination: restPageCursor, Cursor_Field: `next`, Cursor_Param: `after`},
+		{Pagination: restPageOffset, Page_Size: 1},
+	}
+	for _, rc := range tests {
+		t.Run(rc.Pagination, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				// one record per page, the cursor is the index of the next record
+				i, _ := strconv.Atoi(r.URL.Query().Get(`after`))
+				if off := r.URL.Query().Get(`offset`); off != `` {
+					i, _ = strconv.Atoi(off)
+				}
+				if i >= len(recs) {
+					w.Write([]byte(page(nil, ``)))
+					return
+				}
+				w.Write([]byte(page(recs[i:i+1], fmt.Sprintf(`,"next":"%d"`, i+1))))
+			}))
+			defer srv.Close()
+			rc.URL = srv.URL + `/events`
+			rc.Records_Path = `data`
+			rc.ID_Field = `id`
+			rc.Timestamp_Field = `ts`
+			rc.Max_Pages = 2
+			h, cw := newTestRest(t, &rc)
+			w := testWindow()
+			cp := restCheckpoint{Seen: map[string]int64{}}
+			// each call stops at the page limit and the next picks up where it stopped
+			for i := 1; i <= 2; i++ {
+				if err := h.fetchWindow(w, &cp); !errors.Is(err, errRestPageLimit) {
+					t.Fatalf("call %d did not hit the page limit: %v", i, err)
+				} else if got := cw.data(); len(got) != 2*i {
+					t.Fatalf("call %d got %v", i, got)
+				} else if cp.Next == `` || !cp.End.Equal(w.End) {
+					t.Fatalf("call %d did not store the next page: %+v", i, cp)
+				}
+			}
+			if err := h.fetchWindow(w, &cp); err != nil {
+				t.Fatal(err)
+			}
+			if got := cw.data(); strings.Join(got, `,`) != strings.Join(recs, `,`) {
+				t.Fatalf("got %v", got)
+			}
+		})
+	}
+}
+
+func TestRestRunPageLimit(t *testing.T) {
+	start := time.Now().Add(-2 * time.Hour).Truncate(time.Second)
+	var recs []string
+	for i := 0; i < 5; i++ {
+		recs = append(recs, fmt.Sprintf(`{"id":"r%d","ts":%d}`, i, start.Unix()+int64(i)))
+	}
+	var mtx sync.Mutex
+	var ends []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mtx.Lock()
+		ends = append(ends, r.URL.Query().Get(`end`))
+		mtx.Unlock()
+		i, _ := strconv.Atoi(r.URL.Query().Get(`after`))
+		end := min(i+2, len(recs))
+		next := ``
+		if end < len(recs) {
+			next = strconv.Itoa(end)
+		}
+		w.Write([]byte(page(recs[i:end], fmt.Sprintf(`,"next":%q`, next))))
+	}))
+	defer srv.Close()
+	h, cw := newTestRest(t, &restConf{
+		URL:             srv.URL + `?end={{.End | unix}}`,
+		Records_Path:    `data`,
+		ID_Field:        `id`,
+		Timestamp_Field: `ts`,
+		Pagination:      restPageCursor,
+		Cursor_Field:    `next`,
+		Cursor_Param:    `after`,
+		Max_Pages:       1,
+		Lag:             `30m`,
+		Interval:        `1h`,
+	})
+	if err := h.saveCheckpoint(start, restCheckpoint{}); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	h.ctx = ctx
+	h.wg.Add(1)
+	go h.run()
+	deadline := time.Now().Add(10 * time.Second)
+	for len(cw.data()) < len(recs) && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	cancel()
+	h.wg.Wait()
+
+	// the window is finished a page at a time without moving its end
+	if got := cw.data(); strings.Join(got, `,`) != strings.Join(recs, `,`) {
+		t.Fatalf("got %v", got)
+	}
+	mtx.Lock()
+	defer mtx.Unlock()
+	if len(ends) != 3 || ends[0] != ends[1] || ends[1] != ends[2] {
+		t.Fatalf("bad window ends %v", ends)
+	}
+	last, cp := h.loadCheckpoint()
+	if strconv.FormatInt(last.Unix(), 10) != ends[0] || cp.Next != `` {
+		t.Fatalf("bad checkpoint %v %+v", last, cp)
+	}
+}
+
+func TestRestRetryAfter(t *testing.T) {
+	var reqs int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch atomic.AddInt32(&reqs, 1) {
+		case 1:
+			w.Header().Set(`Retry-After`, `1`)
+			w.WriteHeader(http.StatusTooManyRequests)
+		default:
+			w.Write([]byte(`[{"id":"a"}]`))
+		}
+	}))
+	defer srv.Close()
+	h, cw := newTestRest(t, &restConf{URL: srv.URL})
+	start := time.Now()
+	if err := h.fetchWindow(testWindow(), &restCheckpoint{Seen: map[string]int64{}}); err != nil {
+		t.Fatal(err)
+	}
+	if d := time.Since(start); d < time.Second {
+		t.Fatalf("retried after %v, ignoring Retry-After", d)
+	} else if n := atomic.LoadInt32(&reqs); n != 2 {
+		t.Fatalf("made %d requests", n)
+	} else if got := cw.data(); len(got) != 1 {
+		t.Fatalf("got %v", got)
+	}
+}
+
+func TestRestNoRetry(t *testing.T) {
+	var reqs int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&reqs, 1)
+		http.Error(w, `bad filter`, http.StatusBadRequest)
+	}))
+	defer srv.Close()
+	h, _ := newTestRest(t, &restConf{URL: srv.URL})
+	if err := h.fetchWindow(testWindow(), &restCheckpoint{Seen: map[string]int64{}}); err == nil || !strings.Contains(err.Error(), `bad filter`) {
+		t.Fatalf("bad error %v", err)
+	} else if n := atomic.LoadInt32(&reqs); n != 1 {
+		t.Fatalf("client errors were retried %d times", n)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2025, time.March, 4, 5, 6, 7, 0, time.UTC)
+	tests := []struct {
+		v   string
+		exp time.Duration
+	}{
+		{``, 0},
+		{` 5 `, 5 * time.Second},
+		{`-3`, 0},
+		{`100000`, restMaxRetryAfter},
+		{now.Add(30 * time.Second).Format(http.TimeFormat), 30 * time.Second},
+		{now.Add(-time.Minute).Format(http.TimeFormat), 0},
+		{`soon`, 0},
+	}
+	for _, tt := range tests {
+		if got := retryAfter(tt.v, now); got != tt.exp {
+			t.Errorf("%q: got %v expected %v", tt.v, got, tt.exp)
+		}
+	}
+}
+
+func TestRestParams(t *testing.T) {
+	tests := []struct {
+		name string
+		conf restConf
+		err  string // empty means valid
+	}{
+		{`minimal`, restConf{}, ``},
+		{`no tag`, restConf{Tag_Name: `-`}, `Tag-Name`},
+		{`no url`, restConf{URL: `-`}, `URL not specified`},
+		{`bad url template`, restConf{URL: `http://x/{{.Start`}, `invalid URL template`},
+		{`bad body template`, restConf{Body: `{{.Nope | nofunc}}`}, `invalid Body template`},
+		{`zero interval`, restConf{Interval: `0s`}, `Interval must be greater than zero`},
+		{`negative lag`, restConf{Lag: `-1m`}, `must not be negative`},
+		{`bad window`, restConf{Window: `forever`}, `invalid Window`},
+		{`bad header`, restConf{Header: []string{`NoColon`}}, `invalid Header`},
+		{`header`, restConf{Header: []string{`X-Api-Key: abc`}}, ``},
+		{`bearer`, restConf{Auth_Type: `Bearer`}, `Token is required`},
+		{`basic`, restConf{Auth_Type: restAuthBasic}, `Username is required`},
+		{`oauth2`, restConf{Auth_Type: restAuthOAuth2, Token_URL: `http://x`}, `Client-Secret are required`},
+		{`oauth2 param`, restConf{Auth_Type: restAuthOAuth2, Token_URL: `http://x`, Client_ID: `a`, Client_Secret: `b`, Token_Param: []string{`audience`}}, `invalid Token-Param`},
+		{`unknown auth`, restConf{Auth_Type: `digest`}, `invalid Auth-Type`},
+		{`cursor`, restConf{Pagination: restPageCursor}, `Cursor-Field is required`},
+		{`next url`, restConf{Pagination: ` Next-URL `}, `Next-URL-Field is required`},
+		{`unknown pagination`, restConf{Pagination: `pages`}, `invalid Pagination`},
+		{`layout`, restConf{Timestamp_Format: `2006-01-02 15:04:05`}, ``},
+		{`bad layout`, restConf{Timestamp_Format: `yyyy-mm-dd`}, `invalid Timestamp-Format`},
+	}
+	for _, tt := range tests {
+		rc := tt.conf
+		if rc.Tag_Name == `-` {
+			rc.Tag_Name = ``
+		} else {
+			rc.Tag_Name = `rest`
+		}
+		if rc.URL == `-` {
+			rc.URL = ``
+		} else if rc.URL == `` {
+			rc.URL = `https://example.com/events?since={{.Start | rfc3339}}`
+		}
+		_, err := rc.params()
+		if tt.err == `` && err != nil {
+			t.Errorf("%s: unexpected error %v", tt.name, err)
+		} else if tt.err != `` && (err == nil || !strings.Contains(err.Error(), tt.err)) {
+			t.Errorf("%s: got error %v, expected %q", tt.name, err, tt.err)
+		}
+	}
+
+	// defaults are filled in and the enumerations are normalized
+	rc := restConf{Tag_Name: `rest`, URL: `https://example.com`, Method: `post`, Pagination: `Offset`, Header: []string{`X-A: 1`, `X-A: 2`}}
+	p, err := rc.params()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rc.Method != http.MethodPost || rc.Pagination != restPageOffset || rc.Page_Size != restDefaultPageSize ||
+		rc.Limit_Param != `limit` || rc.Offset_Param != `offset` || rc.Max_Retries != restDefaultMaxRetries {
+		t.Fatalf("bad defaults %+v", rc)
+	} else if p.interval != restDefaultInterval || p.timeout != restDefaultTimeout || len(p.headers.Values(`X-A`)) != 2 {
+		t.Fatalf("bad params %+v", p)
+	}
+}
+
+func TestJSONPath(t *testing.T) {
+	doc := json.RawMessage(`{"a":{"b":"x","n":12.50,"z":null},"list":[{"id":1},{"id":"two"}]}`)
+	tests := []struct {
+		path string
+		raw  string
+		ok   bool
+	}{
+		{``, string(doc), true},
+		{`a.b`, `"x"`, true},
+		{`list.1.id`, `"two"`, true},
+		{`list.0`, `{"id":1}`, true},
+		{`list.2`, ``, false},
+		{`list.x`, ``, false},
+		{`a.b.c`, ``, false},
+		{`missing`, ``, false},
+	}
+	for _, tt := range tests {
+		raw, ok := jsonPath(doc, tt.path)
+		if ok != tt.ok || (ok && string(raw) != tt.raw) {
+			t.Errorf("%q: got %s %v", tt.path, raw, ok)
+		}
+	}
+	// numbers are returned verbatim, null is treated as absent
+	if s, ok := jsonPathString(doc, `a.n`); !ok || s != `12.50` {
+		t.Fatalf("bad number %q", s)
+	} else if s, ok = jsonPathString(doc, `list.0.id`); !ok || s != `1` {
+		t.Fatalf("bad number %q", s)
+	} else if _, ok = jsonPathString(doc, `a.z`); ok {
+		t.Fatal("null was returned as a string")
+	} else if _, ok = jsonPathString(doc, `a`); ok {
+		t.Fatal("object was returned as a string")
+	}
+
+	if recs, err := restRecords(doc, `list`); err != nil || len(recs) != 2 || string(recs[1]) != `{"id":"two"}` {
+		t.Fatalf("bad records %s %v", recs, err)
+	} else if recs, err = restRecords(doc, `nope`); err != nil || recs != nil {
+		t.Fatalf("missing records path returned %s %v", recs, err)
+	} else if recs, err = restRecords(doc, `a.z`); err != nil || recs != nil {
+		t.Fatalf("null records returned %s %v", recs, err)
+	} else if _, err = restRecords(doc, `a`); err != errRestNotArray {
+		t.Fatalf("object records returned %v", err)
+	}
+}
+
+func TestParseRestTime(t *testing.T) {
+	tg, err := timegrinder.New(timegrinder.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sec := time.Unix(1700000000, 0).UTC()
+	tests := []struct {
+		raw    string
+		format string
+		exp    time.Time
+		ok     bool
+	}{
+		{`1700000000`, restTSAuto, sec, true},
+		{`1700000000123`, restTSAuto, sec.Add(123 * time.Millisecond), true},
+		{`"1700000000"`, restTSAuto, sec, true},
+		{`1700000000.5`, restTSAuto, sec.Add(500 * time.Millisecond), true},
+		{`1700000000123`, restTSUnixMs, sec.Add(123 * time.Millisecond), true},
+		{`1700000000`, restTSUnix, sec, true},
+		{`"2023-11-14T22:13:20.25Z"`, restTSAuto, sec.Add(250 * time.Millisecond), true},
+		{`"Nov 14 2023 22:13:20"`, restTSAuto, sec, true},
+		{`"2023-11-14T22:13:20Z"`, restTSUnix, time.Time{}, false},
+		{`"not a time"`, restTSAuto, time.Time{}, false},
+		{`"2023-11-14"`, `2006-01-02`, time.Date(2023, 11, 14, 0, 0, 0, 0, time.UTC), true},
+		{`"11/14/2023"`, `2006-01-02`, time.Time{}, false},
+		{`{"t":1}`, restTSAuto, time.Time{}, false},
+		{`true`, restTSAuto, time.Time{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseRestTime(json.RawMessage(tt.raw), tt.format, tg)
+		if ok != tt.ok || (ok && !got.Equal(tt.exp)) {
+			t.Errorf("%s as %s: got %v %v expected %v", tt.raw, tt.format, got, ok, tt.exp)
+		}
+	}
+}
+
+func TestRestOAuth2(t *testing.T) {
+	var tokens int32
+	var expires int32 = 3600
+	tokSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if r.PostForm.Get(`client_id`) != `cid` || r.PostForm.Get(`client_secret`) != `secret` ||
+			r.PostForm.Get(`audience`) != `api` || r.PostForm.Get(`grant_type`) != `client_credentials` {
+			t.Errorf("bad token request %v", r.PostForm)
+			http.Error(w, `bad request`, http.StatusBadRequest)
+			return
+		}
+		n := atomic.AddInt32(&tokens, 1)
+		w.Header().Set(`Content-Type`, `application/json`)
+		fmt.Fprintf(w, `{"access_token":"tok-%d","token_type":"Bearer","expires_in":%d}`, n, atomic.LoadInt32(&expires))
+	}))
+	defer tokSrv.Close()
+	var authMtx sync.Mutex
+	var auths []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authMtx.Lock()
+		auths = append(auths, r.Header.Get(`Authorization`))
+		authMtx.Unlock()
+		w.Write([]byte(`[]`))
+	}))
+	defer srv.Close()
+
+	h, _ := newTestRest(t, &restConf{
+		URL:             srv.URL,
+		Auth_Type:       restAuthOAuth2,
+		Token_URL:       tokSrv.URL,
+		Client_ID:       `cid`,
+		Client_Secret:   `secret`,
+		Token_Param:     []string{`audience=api`},
+		Token_Auth_Body: true,
+	})
+	// a valid token is reused
+	for i := 0; i < 2; i++ {
+		if err := h.fetchWindow(testWindow(), &restCheckpoint{Seen: map[string]int64{}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// tokens that are about to expire are refreshed before the next request
+	atomic.StoreInt32(&expires, 1)
+	h.cli = h.newClient()
+	for i := 0; i < 2; i++ {
+		if err := h.fetchWindow(testWindow(), &restCheckpoint{Seen: map[string]int64{}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	authMtx.Lock()
+	defer authMtx.Unlock()
+	if exp := []string{`Bearer tok-1`, `Bearer tok-1`, `Bearer tok-2`, `Bearer tok-3`}; strings.Join(auths, `,`) != strings.Join(exp, `,`) {
+		t.Fatalf("got authorization %v, expected %v", auths, exp)
+	}
+}
+
+func TestRestCheckpoint(t *testing.T) {
+	w := testWindow()
+	// the API returns records on both sides of the window start, some without IDs
+	body := fmt.Sprintf(`[{"id":"old","ts":%d},{"id":"a","ts":%d},{"id":"b","ts":%d},{"ts":%d,"v":1}]`,
+		w.Start.Unix()-10, w.Start.Unix(), w.Start.Unix()+10, w.Start.Unix()+20)
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.Write([]byte(body))
+	}))
+	defer srv.Close()
+	h, cw := newTestRest(t, &restConf{URL: srv.URL, ID_Field: `id`, Timestamp_Field: `ts`})
+
+	seen := map[string]int64{}
+	if err := h.fetchWindow(w, &restCheckpoint{Seen: seen}); err != nil {
+		t.Fatal(err)
+	} else if got := cw.data(); len(got) != 3 || len(seen) != 3 {
+		t.Fatalf("got %v seen %v", got, seen)
+	} else if seen[`a`] != w.Start.UnixNano() {
+		t.Fatalf("bad seen time %v", seen)
+	}
+
+	// the seen IDs survive a checkpoint round trip and suppress duplicates on the next fetch
+	if err := h.saveCheckpoint(w.Start, restCheckpoint{Seen: seen}); err != nil {
+		t.Fatal(err)
+	}
+	ot, err := NewObjectTracker(h.ot.statePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.ot = ot
+	start, cp := h.loadCheckpoint()
+	if !start.Equal(w.Start) || len(cp.Seen) != 3 {
+		t.Fatalf("bad checkpoint %v %v", start, cp)
+	}
+	if err = h.fetchWindow(w, &cp); err != nil {
+		t.Fatal(err)
+	} else if got := cw.data(); len(got) != 3 {
+		t.Fatalf("duplicates ingested: %v", got)
+	}
+
+	// a corrupt checkpoint only loses the seen IDs
+	if err = h.ot.Set(restTrackerGroup, h.name, trackedObjectState{LatestTime: w.Start, Key: `{`}, true); err != nil {
+		t.Fatal(err)
+	}
+	if start, cp = h.loadCheckpoint(); !start.Equal(w.Start) || cp.Seen == nil || len(cp.Seen) != 0 {
+		t.Fatalf("bad checkpoint after corruption %v %v", start, cp)
+	}
+}
+
+func TestRestRun(t *testing.T) {
+	start := time.Now().Add(-2 * time.Hour).Truncate(time.Second)
+	var reqs int32
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&reqs, 1)
+		fmt.Fprintf(rw, `[{"id":"x","ts":%q}]`, r.URL.Query().Get(`start`))
+	}))
+	defer srv.Close()
+	h, cw := newTestRest(t, &restConf{
+		URL:             srv.URL + `?start={{.Start | rfc3339}}&end={{.End | rfc3339}}`,
+		ID_Field:        `id`,
+		Timestamp_Field: `ts`,
+		Window:          `30m`,
+		Lag:             `30m`,
+		Interval:        `20m`,
+	})
+	if err := h.saveCheckpoint(start, restCheckpoint{}); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	h.ctx = ctx
+	h.wg.Add(1)
+	go h.run()
+	// the backlog is fetched a window at a time, the last window ends the lag before now
+	deadline := time.Now().Add(10 * time.Second)
+	for atomic.LoadInt32(&reqs) < 3 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	cancel()
+	h.wg.Wait()
+
+	if n := atomic.LoadInt32(&reqs); n != 3 {
+		t.Fatalf("made %d requests", n)
+	} else if got := cw.data(); len(got) != 3 {
+		t.Fatalf("got %v", got)
+	}
+	last, cp := h.loadCheckpoint()
+	if lag := time.Since(last); lag < 30*time.Minute || lag > 31*time.Minute {
+		t.Fatalf("checkpoint is %v behind now", lag)
+	} else if len(cp.Seen) != 0 {
+		t.Fatalf("records before the checkpoint were kept %v", cp.Seen)
+	}
+}
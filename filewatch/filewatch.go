@@ -56,6 +56,11 @@ type WatchManager struct {
 	// This tracks directories which have been deleted and we hope will come back
 	removed map[string][]WatchConfig
 
+	// journal directories we poll, they run until Close
+	journals      []*journalFollower
+	journalWg     *sync.WaitGroup
+	journalCancel context.CancelFunc
+
 	routineRet chan error
 	logger     ingest.IngestLogger
 	ctx        context.Context
@@ -64,6 +69,7 @@ type WatchManager struct {
 
 type WatchConfig struct {
 	FollowerEngineConfig
+	RotationConfig
 	ConfigName string
 	BaseDir    string
 	FileFilter string
@@ -83,14 +89,15 @@ func NewWatcher(stateFilePath string) (*WatchManager, error) {
 	ctx, cancel := context.WithCancel(context.Background())
 
 	return &WatchManager{
-		mtx:     &sync.Mutex{},
-		fman:    fman,
-		watcher: w,
-		watched: map[string][]WatchConfig{},
-		removed: map[string][]WatchConfig{},
-		logger:  ingest.NoLogger(),
-		ctx:     ctx,
-		cancel:  cancel,
+		mtx:       &sync.Mutex{},
+		fman:      fman,
+		watcher:   w,
+		watched:   map[string][]WatchConfig{},
+		removed:   map[string][]WatchConfig{},
+		journalWg: &sync.WaitGroup{},
+		logger:    ingest.NoLogger(),
+		ctx:       ctx,
+		cancel:    cancel,
 	}, nil
 }
 
@@ -145,12 +152,17 @@ func (wm *WatchManager) Close() error {
 			retCh = wm.routineRet
 		}
 	}
+	if wm.journalCancel != nil {
+		wm.journalCancel()
+	}
 	wm.mtx.Unlock() //we have to unlock and wait for the routine to exit
 	var err error
 	if retCh != nil {
 		err = <-retCh
 		close(retCh)
 	}
+	//journal followers write cursors into the filter manager, they must be done before it closes
+	wm.journalWg.Wait()
 
 	//we can lock for the duration of this call
 	wm.mtx.Lock()
@@ -172,6 +184,31 @@ func (wm *WatchManager) Add(c WatchConfig) error {
 	return wm.addNoLock(c)
 }
 
+// AddJournal adds a directory of systemd journal files to follow, journals are polled once the manager is started
+func (wm *WatchManager) AddJournal(c JournalConfig) error {
+	wm.mtx.Lock()
+	defer wm.mtx.Unlock()
+	if wm.fman == nil {
+		return ErrNotReady
+	} else if wm.routineRet != nil {
+		return ErrAlreadyStarted
+	} else if c.Name == `` {
+		return ErrJournalNoName
+	} else if c.Hnd == nil {
+		return ErrJournalNoHandler
+	}
+	for _, j := range wm.journals {
+		if j.Name == c.Name {
+			return fmt.Errorf("%w: %s", ErrDuplicateJournal, c.Name)
+		}
+	}
+	if c.Dir == `` {
+		c.Dir = DefaultJournalDirectory
+	}
+	wm.journals = append(wm.journals, newJournalFollower(c, wm.fman, wm.logger))
+	return nil
+}
+
 func (wm *WatchManager) addNoLock(c WatchConfig) error {
 	if wm.watcher == nil || wm.watched == nil || wm.removed == nil {
 		return ErrNotReady
@@ -192,6 +229,10 @@ func (wm *WatchManager) addNoLock(c WatchConfig) error {
 		return ErrLocationNotDir
 	}
 
+	if err = c.RotationConfig.Validate(); err != nil {
+		return err
+	}
+
 	//extract all the filters from the match
 	fltrs, err := ExtractFilters(c.FileFilter)
 	if err != nil {
@@ -220,7 +261,7 @@ func (wm *WatchManager) addNoLock(c WatchConfig) error {
 		wm.watched[c.BaseDir] = append(wm.watched[c.BaseDir], c)
 	}
 
-	if err := wm.fman.AddFilter(c.ConfigName, c.BaseDir, fltrs, c.Hnd, c.FollowerEngineConfig); err != nil {
+	if err := wm.fman.AddFilter(c.ConfigName, c.BaseDir, fltrs, c.Hnd, c.FollowerEngineConfig, c.RotationConfig); err != nil {
 		return err
 	}
 	// Now add the subdirectories
@@ -358,7 +399,7 @@ func (wm *WatchManager) Start() error {
 	if wm.fman == nil || wm.watcher == nil {
 		return ErrNotReady
 	}
-	if len(wm.watched) == 0 && len(wm.removed) == 0 {
+	if len(wm.watched) == 0 && len(wm.removed) == 0 && len(wm.journals) == 0 {
 		return ErrNoDirsWatched
 	}
 	if wm.routineRet != nil {
@@ -374,6 +415,13 @@ func (wm *WatchManager) Start() error {
 	wm.routineRet = make(chan error, 1)
 	go wm.routine(wm.routineRet)
 
+	var jctx context.Context
+	jctx, wm.journalCancel = context.WithCancel(wm.ctx)
+	for _, j := range wm.journals {
+		j.lgr = wm.logger
+		wm.journalWg.Add(1)
+		go j.routine(jctx, wm.journalWg)
+	}
 	return nil
 }
 
@@ -459,6 +507,8 @@ func (wm *WatchManager) routine(errch chan error) {
 	defer tckr.Stop()
 	mkdirTicker := time.NewTicker(newDirTickInterval)
 	defer mkdirTicker.Stop()
+	rotationTicker := time.NewTicker(rotationCheckInterval)
+	defer rotationTicker.Stop()
 
 watchRoutine:
 	for {
@@ -545,6 +595,10 @@ watchRoutine:
 			if err := wm.fman.FlushStates(); err != nil {
 				wm.logger.Error("failed to flush states", log.KVErr(err))
 			}
+		case <-rotationTicker.C:
+			if err := wm.fman.CheckRotations(); err != nil {
+				wm.logger.Error("failed to check rotated files", log.KVErr(err))
+			}
 		case <-mkdirTicker.C:
 			// Scan all the removed directories and see if they got recreated
 			if err := wm.scanRemoved(); err != nil {
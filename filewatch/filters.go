@@ -15,6 +15,7 @@ import (
 	"os"
 	"path/filepath"
 	"sync"
+	"time"
 
 	"github.com/gravwell/gravwell/v3/ingest"
 	"github.com/gravwell/gravwell/v3/ingest/log"
@@ -27,6 +28,7 @@ const (
 
 type filter struct {
 	FollowerEngineConfig
+	RotationConfig
 	bname string //name given to the config file
 	loc   string //location we are watching
 	mtchs []string
@@ -36,6 +38,8 @@ type filter struct {
 func (f *filter) Equal(x filter) bool {
 	if f.FollowerEngineConfig != x.FollowerEngineConfig {
 		return false
+	} else if f.RotationConfig != x.RotationConfig {
+		return false
 	} else if f.bname != x.bname {
 		return false
 	} else if f.loc != x.loc {
@@ -69,13 +73,24 @@ type FilterManager struct {
 	stateFout       *os.File
 	maxFilesWatched int
 	logger          ingest.IngestLogger
+
+	// rotation tracking for filters that use fingerprints or follow compressed rotations
+	rmtx     *sync.Mutex // guards rotated, followers report truncations without holding the manager lock
+	rotated  []rotatedFile
+	prints   map[FileName]fingerprint
+	pending  map[string]*pendingCompressed
+	deferred map[string]time.Time
 }
 
 func NewFilterManager(stateFile string) (*FilterManager, error) {
-	fout, states, err := initStateFile(stateFile)
+	fout, states, rs, err := initStateFile(stateFile)
 	if err != nil {
 		return nil, err
 	}
+	if rs.Prints == nil {
+		rs.Prints = map[FileName]fingerprint{}
+	}
+	retireMissing(states, &rs)
 	if err := cleanStates(states); err != nil {
 		fout.Close()
 		return nil, err
@@ -88,6 +103,11 @@ func NewFilterManager(stateFile string) (*FilterManager, error) {
 		states:    states,
 		followers: map[FileName]*follower{},
 		logger:    ingest.NoLogger(),
+		rmtx:      &sync.Mutex{},
+		rotated:   rs.Rotated,
+		prints:    rs.Prints,
+		pending:   map[string]*pendingCompressed{},
+		deferred:  map[string]time.Time{},
 	}, nil
 }
 
@@ -163,6 +183,7 @@ func (f *FilterManager) Close() (err error) {
 	defer f.mtx.Unlock()
 
 	//we have to actually close followers
+	f.nolockSyncPrints()
 	for _, v := range f.followers {
 		if lerr := v.Close(); lerr != nil {
 			err = appendErr(err, lerr)
@@ -223,18 +244,24 @@ func (f *FilterManager) nolockDumpStates() error {
 	if err := f.stateFout.Truncate(0); err != nil {
 		return err
 	}
-	if err := gob.NewEncoder(f.stateFout).Encode(f.states); err != nil {
+	enc := gob.NewEncoder(f.stateFout)
+	if err := enc.Encode(f.states); err != nil {
+		return err
+	}
+	//rotation tracking follows the states so older versions can still read the file
+	if err := enc.Encode(f.nolockRotationState()); err != nil {
 		return err
 	}
 	return nil
 }
 
-func (f *FilterManager) AddFilter(bname, loc string, mtchs []string, lh handler, ecfg FollowerEngineConfig) error {
+func (f *FilterManager) AddFilter(bname, loc string, mtchs []string, lh handler, ecfg FollowerEngineConfig, rcfg RotationConfig) error {
 	f.mtx.Lock()
 	defer f.mtx.Unlock()
 
 	fltr := filter{
 		FollowerEngineConfig: ecfg,
+		RotationConfig:       rcfg,
 		bname:                bname,
 		loc:                  filepath.Clean(loc),
 		mtchs:                mtchs,
@@ -265,6 +292,9 @@ func (f *FilterManager) nolockRemoveDirectory(path string, purgeState bool) (err
 			if err = v.Close(); err != nil {
 				return
 			}
+			if purgeState {
+				f.nolockRetire(v)
+			}
 		}
 	}
 	return
@@ -294,6 +324,9 @@ func (f *FilterManager) nolockRemoveFollower(fpath string, purgeState bool) (rem
 			if err = fl.Close(); err != nil {
 				return
 			}
+			if purgeState {
+				f.nolockRetire(fl)
+			}
 			removed = true
 		}
 	}
@@ -370,6 +403,7 @@ func (f *FilterManager) RenameFollower(fpath string) error {
 		p, ok, err := f.findFileId(v.loc, v.mtchs, id)
 		if err != nil {
 			flw.Close()
+			f.nolockRetire(flw)
 			delete(f.states, stid)
 			delete(f.followers, stid)
 			continue
@@ -393,14 +427,7 @@ func (f *FilterManager) RenameFollower(fpath string) error {
 				if err := flw.Close(); err != nil {
 					return err
 				}
-				fcfg := FollowerConfig{
-					BaseName:             v.bname,
-					FilePath:             p,
-					State:                st,
-					FilterID:             i,
-					Handler:              v.lh,
-					FollowerEngineConfig: v.FollowerEngineConfig,
-				}
+				fcfg := f.followerConfig(v, i, p, st)
 				if err := f.addFollower(fcfg); err != nil {
 					return err
 				}
@@ -488,6 +515,33 @@ func (f *FilterManager) addFollower(fcfg FollowerConfig) error {
 	return nil
 }
 
+func (f *FilterManager) followerConfig(v filter, filterID int, fpath string, si *int64) FollowerConfig {
+	fcfg := FollowerConfig{
+		FollowerEngineConfig: v.FollowerEngineConfig,
+		RotationConfig:       v.RotationConfig,
+		BaseName:             v.bname,
+		FilePath:             fpath,
+		State:                si,
+		FilterID:             filterID,
+		Handler:              v.lh,
+	}
+	if v.Identity == IdentityFingerprint {
+		fcfg.Rotated = f.addRotated
+	}
+	return fcfg
+}
+
+// nolockCompressedCandidate checks if a file is compressed, but only if a filter on its directory cares
+// caller MUST HOLD LOCK
+func (f *FilterManager) nolockCompressedCandidate(fdir, fpath string) bool {
+	for _, v := range f.filters {
+		if v.FollowCompressed && v.loc == fdir {
+			return hasCompressionExt(fpath) || isCompressedFile(fpath)
+		}
+	}
+	return false
+}
+
 // look for seek infor for the filename, caller MUST HOLD LOCK
 func (f *FilterManager) seekInfo(bname, fpath string) *int64 {
 	for k, v := range f.states {
@@ -528,10 +582,16 @@ func (f *FilterManager) launchFollowers(fpath string, deleteState bool) (ok bool
 	fname := filepath.Base(fpath)
 	fdir := filepath.Dir(fpath)
 	var si *int64
+	compressed := f.nolockCompressedCandidate(fdir, fpath)
 
 	//swing through all filters and launch a follower for each one that matches
 FILTER_LOOP:
 	for i, v := range f.filters {
+		if compressed && v.FollowCompressed && v.loc == fdir {
+			//compressed files are only read to finish a rotated file, never followed
+			f.nolockQueueCompressed(fpath, i)
+			continue
+		}
 		//check base directory and pattern match
 		if v.loc != fdir || !f.matchFile(v.mtchs, fname) {
 			continue
@@ -557,14 +617,11 @@ FILTER_LOOP:
 		if si == nil {
 			si = f.addSeekInfo(v.bname, fpath)
 		}
-		fcfg := FollowerConfig{
-			FollowerEngineConfig: v.FollowerEngineConfig,
-			BaseName:             v.bname,
-			FilePath:             fpath,
-			State:                si,
-			FilterID:             i,
-			Handler:              v.lh,
+		if v.Identity == IdentityFingerprint && !f.nolockCheckIdentity(v, fpath, si) {
+			f.nolockDeferFile(fpath)
+			continue
 		}
+		fcfg := f.followerConfig(v, i, fpath, si)
 		if err := f.addFollower(fcfg); err != nil {
 			return false, err
 		}
@@ -645,6 +702,7 @@ func (f *FilterManager) checkRename(fpath string, id FileId) (isRename bool, err
 				if err = v.Close(); err != nil {
 					return
 				}
+				f.nolockRetire(v)
 				delete(f.states, k)
 				delete(f.followers, k)
 			}
@@ -698,28 +756,32 @@ func (f *FilterManager) CatchupFile(wf watchedFile, qc chan os.Signal) (bool, er
 	fname := filepath.Base(wf.pth)
 	fdir := filepath.Dir(wf.pth)
 	var si *int64
+	compressed := f.nolockCompressedCandidate(fdir, wf.pth)
 
 	//swing through all filters and spin up a follower then synchronously process outstanding data
 	for i, v := range f.filters {
 		var hasWork bool
+		if compressed && v.FollowCompressed && v.loc == fdir {
+			f.nolockQueueCompressed(wf.pth, i)
+			continue
+		}
 		//check base directory and pattern match
 		if v.loc != fdir || !f.matchFile(v.mtchs, fname) {
 			continue
 		}
+		if v.Identity == IdentityFingerprint {
+			if si = f.seekInfo(v.bname, wf.pth); si == nil {
+				si = f.addSeekInfo(v.bname, wf.pth)
+			}
+			f.nolockCheckIdentity(v, wf.pth, si)
+		}
 		if si, hasWork, err = f.checkState(wf); err != nil {
 			return false, err
 		} else if !hasWork {
 			continue // no work to do
 		}
 		//this file needs to be caught up
-		fcfg := FollowerConfig{
-			FollowerEngineConfig: v.FollowerEngineConfig,
-			BaseName:             v.bname,
-			FilePath:             wf.pth,
-			State:                si,
-			FilterID:             i,
-			Handler:              v.lh,
-		}
+		fcfg := f.followerConfig(v, i, wf.pth, si)
 		if quit, err := f.catchupFollower(fcfg, qc); err != nil || quit {
 			return quit, err
 		}
@@ -812,7 +874,7 @@ func ReadStateFile(p string) (states map[string]int64, err error) {
 	return
 }
 
-func initStateFile(p string) (fout *os.File, states map[FileName]*int64, err error) {
+func initStateFile(p string) (fout *os.File, states map[FileName]*int64, rs rotationState, err error) {
 	var fi os.FileInfo
 	states = map[FileName]*int64{}
 	//attempt to open state file
@@ -848,7 +910,8 @@ func initStateFile(p string) (fout *os.File, states map[FileName]*int64, err err
 		return
 	}
 	if fi.Size() > 0 {
-		if err = gob.NewDecoder(fout).Decode(&states); err != nil {
+		dec := gob.NewDecoder(fout)
+		if err = dec.Decode(&states); err != nil {
 			// hold onto the decode error in case we can't get to a backup
 			serr := err
 			fout.Close()
@@ -868,7 +931,7 @@ func initStateFile(p string) (fout *os.File, states map[FileName]*int64, err err
 
 				if count == RENAME_COUNT_MAX {
 					// if we got here then we ran out of attempts
-					return nil, nil, fmt.Errorf("Failed to rename old state file")
+					return nil, nil, rs, fmt.Errorf("Failed to rename old state file")
 				}
 			}
 
@@ -880,12 +943,19 @@ func initStateFile(p string) (fout *os.File, states map[FileName]*int64, err err
 			// success!
 			return initStateFile(p)
 		}
+		//rotation tracking is optional, state files from older versions will not have it
+		if dec.Decode(&rs) != nil {
+			rs = rotationState{}
+		}
 	}
 	return
 }
 
 func cleanStates(states map[FileName]*int64) error {
 	for k, v := range states {
+		if isJournalState(k) {
+			continue //journal cursors are sequence numbers, not file offsets
+		}
 		fi, err := os.Stat(k.FilePath)
 		if err != nil {
 			if os.IsNotExist(err) {
@@ -55,11 +55,13 @@ type FollowerEngineConfig struct {
 
 type FollowerConfig struct {
 	FollowerEngineConfig
+	RotationConfig
 	BaseName string
 	FilePath string
 	State    *int64
 	FilterID int
 	Handler  handler
+	Rotated  func(rotatedFile) // called when the file is truncated out from under us
 }
 
 type follower struct {
@@ -76,6 +78,14 @@ type follower struct {
 	wg       *sync.WaitGroup
 	lh       handler
 	lastAct  time.Time
+
+	// fingerprint tracking for rotation aware filters
+	fin      *os.File
+	rcfg     RotationConfig
+	rotated  func(rotatedFile)
+	printMtx *sync.Mutex
+	print    fingerprint
+	printOff int64 // offset at the last fingerprint update, readable while the follower runs
 }
 
 func NewFollower(cfg FollowerConfig) (*follower, error) {
@@ -115,6 +125,15 @@ func NewFollower(cfg FollowerConfig) (*follower, error) {
 		return nil, err
 	}
 
+	var fp fingerprint
+	if cfg.RotationConfig.enabled() {
+		if fp, err = fingerprintFile(fin, cfg.RotationConfig.printSize()); err != nil {
+			wtchr.Close()
+			lnr.Close()
+			return nil, err
+		}
+	}
+
 	//open the file for reading and get
 	return &follower{
 		filterId: cfg.FilterID,
@@ -129,10 +148,48 @@ func NewFollower(cfg FollowerConfig) (*follower, error) {
 			FilePath: cfg.FilePath,
 			BaseName: cfg.BaseName,
 		},
-		lastAct: time.Now(),
+		lastAct:  time.Now(),
+		fin:      fin,
+		rcfg:     cfg.RotationConfig,
+		rotated:  cfg.Rotated,
+		printMtx: &sync.Mutex{},
+		print:    fp,
+		printOff: *cfg.State,
 	}, nil
 }
 
+// Fingerprint returns the fingerprint of the file, it is empty if the filter is not rotation aware
+func (f *follower) Fingerprint() fingerprint {
+	f.printMtx.Lock()
+	defer f.printMtx.Unlock()
+	return f.print
+}
+
+// printState returns the fingerprint along with how far into the file we were when it was taken
+func (f *follower) printState() (fingerprint, int64) {
+	f.printMtx.Lock()
+	defer f.printMtx.Unlock()
+	return f.print, f.printOff
+}
+
+// updateFingerprint extends the fingerprint while the file is shorter than the fingerprint size
+func (f *follower) updateFingerprint(reset bool) {
+	if !f.rcfg.enabled() {
+		return
+	}
+	f.printMtx.Lock()
+	defer f.printMtx.Unlock()
+	f.printOff = *f.state
+	if reset {
+		f.print = fingerprint{}
+	} else if f.print.Len >= f.rcfg.printSize() {
+		return
+	}
+	if p, err := fingerprintFile(f.fin, f.rcfg.printSize()); err == nil {
+		f.print = p
+	}
+}
+
 func (f *follower) FilterId() int {
 	return f.filterId
 }
@@ -297,6 +354,12 @@ func (f *follower) IdleDuration() time.Duration {
 // and make sure the file wasn't truncated
 func (f *follower) processLines(writeEvent, removing, allowPartial bool) error {
 	var hit bool
+	if writeEvent && f.contentReplaced() {
+		// truncated and rewritten past our offset before we noticed, the size alone can't tell us
+		if err := f.restart(); err != nil {
+			return err
+		}
+	}
 	for {
 		ln, ok, sawEOF, err := f.lnr.ReadEntry()
 		if err != nil {
@@ -310,8 +373,7 @@ func (f *follower) processLines(writeEvent, removing, allowPartial bool) error {
 			}
 			if sz < *f.state {
 				// the file must have been truncated
-				*f.state = 0
-				if err = f.lnr.SeekFile(0); err != nil {
+				if err = f.restart(); err != nil {
 					return err
 				}
 			}
@@ -330,6 +392,7 @@ func (f *follower) processLines(writeEvent, removing, allowPartial bool) error {
 					if err = f.lh.HandleLog(ln, time.Now(), f.FilePath); err == nil {
 						hit = true
 						*f.state = f.lnr.Index()
+						f.updateFingerprint(false)
 					}
 				}
 				return err
@@ -345,10 +408,35 @@ func (f *follower) processLines(writeEvent, removing, allowPartial bool) error {
 	}
 	if hit {
 		f.lastAct = time.Now()
+		f.updateFingerprint(false)
 	}
 	return nil
 }
 
+// restart goes back to the beginning of a file that was truncated
+func (f *follower) restart() error {
+	if f.rotated != nil {
+		// remember what we read in case the old content shows up as a copy
+		f.rotated(rotatedFile{FileName: f.FileName, Print: f.Fingerprint(), Offset: *f.state, When: time.Now()})
+	}
+	*f.state = 0
+	f.updateFingerprint(true)
+	return f.lnr.SeekFile(0)
+}
+
+// contentReplaced checks if the start of the file no longer matches its fingerprint
+func (f *follower) contentReplaced() bool {
+	if f.rcfg.Identity != IdentityFingerprint || *f.state == 0 {
+		return false
+	}
+	p := f.Fingerprint()
+	if p.Len == 0 {
+		return false
+	}
+	cur, err := fingerprintFile(f.fin, p.Len)
+	return err == nil && cur != p
+}
+
 func (f *follower) routine() {
 	defer f.wg.Done()
 	defer func(r *int32) {
@@ -0,0 +1,466 @@
+/*************************************************************************
+ * Copyright 2026 Gravwell, Inc. All rights reserved.
+ * Contact: <legal@gravwell.io>
+ *
+ * This software may be modified and distributed under the terms of the
+ * BSD 2-clause license. See the LICENSE file for details.
+ **************************************************************************/
+
+// Package journal reads systemd journal files natively, without libsystemd.
+//
+// Entries are walked through the entry array chain, which is how journald links
+// completed entries, so files that are still being written can be followed safely.
+// Regular and compact files are supported, as are XZ, LZ4, and ZSTD compressed data objects.
+package journal
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/pierrec/lz4/v4"
+	"github.com/ulikunitz/xz"
+)
+
+const (
+	Signature = "LPKSHHRH"
+
+	// incompatible header flags
+	IncompatCompressedXZ   = 1 << 0
+	IncompatCompressedLZ4  = 1 << 1
+	IncompatKeyedHash      = 1 << 2
+	IncompatCompressedZSTD = 1 << 3
+	IncompatCompact        = 1 << 4
+	incompatSupported      = IncompatCompressedXZ | IncompatCompressedLZ4 | IncompatKeyedHash | IncompatCompressedZSTD | IncompatCompact
+
+	// object types
+	ObjectUnused         = 0
+	ObjectData           = 1
+	ObjectField          = 2
+	ObjectEntry          = 3
+	ObjectDataHashTable  = 4
+	ObjectFieldHashTable = 5
+	ObjectEntryArray     = 6
+	ObjectTag            = 7
+
+	// object flags
+	ObjectCompressedXZ   = 1 << 0
+	ObjectCompressedLZ4  = 1 << 1
+	ObjectCompressedZSTD = 1 << 2
+
+	// file states
+	StateOffline  = 0
+	StateOnline   = 1
+	StateArchived = 2
+
+	minHeaderSize         = 208 // everything through tail_entry_monotonic
+	objectHeaderSize      = 16
+	entryHeaderSize       = 64
+	entryArrayHeaderSize  = 24
+	dataHeaderSize        = 64
+	compactDataHeaderSize = 72
+
+	maxObjectSize = 256 * 1024 * 1024
+)
+
+var (
+	ErrBadSignature      = errors.New("not a journal file")
+	ErrUnsupportedFlags  = errors.New("journal file uses unsupported features")
+	ErrCorruptObject     = errors.New("corrupt journal object")
+	ErrUnexpectedObject  = errors.New("unexpected journal object type")
+	ErrObjectTooLarge    = errors.New("journal object too large")
+	ErrInvalidDataObject = errors.New("journal data object is not a FIELD=value pair")
+)
+
+// ID128 is a systemd 128 bit identifier
+type ID128 [16]byte
+
+func (id ID128) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+func (id ID128) IsZero() bool {
+	return id == ID128{}
+}
+
+// Header is the journal file header
+type Header struct {
+	CompatibleFlags   uint32
+	IncompatibleFlags uint32
+	State             uint8
+	FileID            ID128
+	MachineID         ID128
+	TailEntryBootID   ID128
+	SeqnumID          ID128
+	HeaderSize        uint64
+	ArenaSize         uint64
+	TailObjectOffset  uint64
+	NObjects          uint64
+	NEntries          uint64
+	TailEntrySeqnum   uint64
+	HeadEntrySeqnum   uint64
+	EntryArrayOffset  uint64
+	HeadEntryRealtime uint64
+	TailEntryRealtime uint64
+}
+
+// Compact indicates the file uses 32 bit offsets in entries and entry arrays
+func (h Header) Compact() bool {
+	return h.IncompatibleFlags&IncompatCompact != 0
+}
+
+func parseHeader(b []byte) (h Header, err error) {
+	if len(b) < minHeaderSize || string(b[:8]) != Signature {
+		err = ErrBadSignature
+		return
+	}
+	le := binary.LittleEndian
+	h.CompatibleFlags = le.Uint32(b[8:])
+	h.IncompatibleFlags = le.Uint32(b[12:])
+	h.State = b[16]
+	copy(h.FileID[:], b[24:40])
+	copy(h.MachineID[:], b[40:56])
+	copy(h.TailEntryBootID[:], b[56:72])
+	copy(h.SeqnumID[:], b[72:88])
+	h.HeaderSize = le.Uint64(b[88:])
+	h.ArenaSize = le.Uint64(b[96:])
+	h.TailObjectOffset = le.Uint64(b[136:])
+	h.NObjects = le.Uint64(b[144:])
+	h.NEntries = le.Uint64(b[152:])
+	h.TailEntrySeqnum = le.Uint64(b[160:])
+	h.HeadEntrySeqnum = le.Uint64(b[168:])
+	h.EntryArrayOffset = le.Uint64(b[176:])
+	h.HeadEntryRealtime = le.Uint64(b[184:])
+	h.TailEntryRealtime = le.Uint64(b[192:])
+	if h.IncompatibleFlags&^incompatSupported != 0 {
+		err = fmt.Errorf("%w: %#x", ErrUnsupportedFlags, h.IncompatibleFlags&^incompatSupported)
+	} else if h.HeaderSize < minHeaderSize {
+		err = ErrBadSignature
+	}
+	return
+}
+
+// Field is a single FIELD=value pair of an entry, values may be binary
+type Field struct {
+	Name  string
+	Value []byte
+}
+
+// Entry is a decoded journal entry
+type Entry struct {
+	Seqnum    uint64
+	Realtime  time.Time
+	Monotonic uint64
+	BootID    ID128
+	Fields    []Field
+}
+
+// Get returns the value of the first field with the given name
+func (e *Entry) Get(name string) ([]byte, bool) {
+	for _, f := range e.Fields {
+		if f.Name == name {
+			return f.Value, true
+		}
+	}
+	return nil, false
+}
+
+// File is an open journal file
+type File struct {
+	fin  *os.File
+	name string
+	hdr  Header
+	zdec *zstd.Decoder
+}
+
+// Open opens a journal file and reads its header
+func Open(pth string) (*File, error) {
+	fin, err := os.Open(pth)
+	if err != nil {
+		return nil, err
+	}
+	f := &File{
+		fin:  fin,
+		name: pth,
+	}
+	if err = f.Refresh(); err != nil {
+		fin.Close()
+		return nil, err
+	}
+	return f, nil
+}
+
+// Name returns the path the file was opened with
+func (f *File) Name() string {
+	return f.name
+}
+
+// Stat returns the FileInfo of the open file
+func (f *File) Stat() (os.FileInfo, error) {
+	return f.fin.Stat()
+}
+
+// Header returns the header as of the last Refresh
+func (f *File) Header() Header {
+	return f.hdr
+}
+
+// Refresh re-reads the header, files that are online change as journald writes to them
+func (f *File) Refresh() error {
+	buf := make([]byte, minHeaderSize)
+	if _, err := f.fin.ReadAt(buf, 0); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return ErrBadSignature
+		}
+		return err
+	}
+	hdr, err := parseHeader(buf)
+	if err != nil {
+		return err
+	}
+	f.hdr = hdr
+	return nil
+}
+
+func (f *File) Close() error {
+	if f.zdec != nil {
+		f.zdec.Close()
+	}
+	return f.fin.Close()
+}
+
+// readObject reads a whole object at off and checks its type
+func (f *File) readObject(off uint64, typ uint8) (flags uint8, b []byte, err error) {
+	if off == 0 || off%8 != 0 {
+		err = fmt.Errorf("%w: bad offset %d", ErrCorruptObject, off)
+		return
+	}
+	var hdr [objectHeaderSize]byte
+	if _, err = f.fin.ReadAt(hdr[:], int64(off)); err != nil {
+		return
+	}
+	sz := binary.LittleEndian.Uint64(hdr[8:])
+	if hdr[0] != typ {
+		err = fmt.Errorf("%w: %d at offset %d, expected %d", ErrUnexpectedObject, hdr[0], off, typ)
+		return
+	} else if sz < objectHeaderSize {
+		err = fmt.Errorf("%w: size %d at offset %d", ErrCorruptObject, sz, off)
+		return
+	} else if sz > maxObjectSize {
+		err = fmt.Errorf("%w: %d bytes at offset %d", ErrObjectTooLarge, sz, off)
+		return
+	}
+	b = make([]byte, sz)
+	if _, err = f.fin.ReadAt(b, int64(off)); err != nil {
+		return
+	}
+	flags = hdr[1]
+	return
+}
+
+func (f *File) itemSize() uint64 {
+	if f.hdr.Compact() {
+		return 4
+	}
+	return 8
+}
+
+func (f *File) item(b []byte) uint64 {
+	if f.hdr.Compact() {
+		return uint64(binary.LittleEndian.Uint32(b))
+	}
+	return binary.LittleEndian.Uint64(b)
+}
+
+// EntrySeqnum reads just the sequence number of the entry at off
+func (f *File) EntrySeqnum(off uint64) (uint64, error) {
+	var b [24]byte
+	if _, err := f.fin.ReadAt(b[:], int64(off)); err != nil {
+		return 0, err
+	} else if b[0] != ObjectEntry {
+		return 0, fmt.Errorf("%w: %d at offset %d, expected entry", ErrUnexpectedObject, b[0], off)
+	}
+	return binary.LittleEndian.Uint64(b[16:]), nil
+}
+
+// ReadEntry decodes the entry at off along with all of its data objects
+func (f *File) ReadEntry(off uint64) (ent Entry, err error) {
+	var b []byte
+	if _, b, err = f.readObject(off, ObjectEntry); err != nil {
+		return
+	} else if len(b) < entryHeaderSize {
+		err = fmt.Errorf("%w: short entry at offset %d", ErrCorruptObject, off)
+		return
+	}
+	le := binary.LittleEndian
+	ent.Seqnum = le.Uint64(b[16:])
+	ent.Realtime = time.UnixMicro(int64(le.Uint64(b[24:])))
+	ent.Monotonic = le.Uint64(b[32:])
+	copy(ent.BootID[:], b[40:56])
+
+	stride := uint64(16)
+	if f.hdr.Compact() {
+		stride = 4
+	}
+	items := b[entryHeaderSize:]
+	ent.Fields = make([]Field, 0, uint64(len(items))/stride)
+	for i := uint64(0); i+stride <= uint64(len(items)); i += stride {
+		var doff uint64
+		if f.hdr.Compact() {
+			doff = uint64(le.Uint32(items[i:]))
+		} else {
+			doff = le.Uint64(items[i:])
+		}
+		var fld Field
+		if fld, err = f.readData(doff); err != nil {
+			return
+		}
+		ent.Fields = append(ent.Fields, fld)
+	}
+	return
+}
+
+func (f *File) readData(off uint64) (fld Field, err error) {
+	flags, b, err := f.readObject(off, ObjectData)
+	if err != nil {
+		return
+	}
+	hsz := dataHeaderSize
+	if f.hdr.Compact() {
+		hsz = compactDataHeaderSize
+	}
+	if len(b) < hsz {
+		err = fmt.Errorf("%w: short data object at offset %d", ErrCorruptObject, off)
+		return
+	}
+	payload := b[hsz:]
+	if payload, err = f.decompress(flags, payload); err != nil {
+		return
+	}
+	idx := bytes.IndexByte(payload, '=')
+	if idx <= 0 {
+		err = fmt.Errorf("%w at offset %d", ErrInvalidDataObject, off)
+		return
+	}
+	fld.Name = string(payload[:idx])
+	fld.Value = payload[idx+1:]
+	return
+}
+
+func (f *File) decompress(flags uint8, b []byte) ([]byte, error) {
+	switch {
+	case flags&ObjectCompressedZSTD != 0:
+		if f.zdec == nil {
+			var err error
+			if f.zdec, err = zstd.NewReader(nil, zstd.WithDecoderConcurrency(1), zstd.WithDecoderMaxMemory(maxObjectSize)); err != nil {
+				return nil, err
+			}
+		}
+		return f.zdec.DecodeAll(b, nil)
+	case flags&ObjectCompressedXZ != 0:
+		rdr, err := xz.NewReader(bytes.NewReader(b))
+		if err != nil {
+			return nil, err
+		}
+		return io.ReadAll(io.LimitReader(rdr, maxObjectSize))
+	case flags&ObjectCompressedLZ4 != 0:
+		// journald prefixes LZ4 blocks with the little endian decompressed size
+		if len(b) < 8 {
+			return nil, fmt.Errorf("%w: short LZ4 payload", ErrCorruptObject)
+		}
+		sz := binary.LittleEndian.Uint64(b)
+		if sz > maxObjectSize {
+			return nil, ErrObjectTooLarge
+		}
+		out := make([]byte, sz)
+		n, err := lz4.UncompressBlock(b[8:], out)
+		if err != nil {
+			return nil, err
+		}
+		return out[:n], nil
+	}
+	return b, nil
+}
+
+// Iterator walks the entry offsets of a file in order
+// An iterator that reaches the end can be called again later to pick up entries
+// that were linked after it stopped.
+type Iterator struct {
+	f     *File
+	arr   uint64 // offset of the current entry array, zero before the first array is read
+	items []byte // items of the current entry array
+	idx   uint64 // next item index in the current array
+}
+
+// Entries returns an iterator positioned at the first entry of the file
+func (f *File) Entries() *Iterator {
+	return &Iterator{f: f}
+}
+
+// Next returns the offset of the next entry, ok is false when no more entries are currently linked
+func (it *Iterator) Next() (off uint64, ok bool, err error) {
+	isz := it.f.itemSize()
+	for {
+		if it.arr == 0 {
+			if it.f.hdr.EntryArrayOffset == 0 {
+				return
+			} else if err = it.load(it.f.hdr.EntryArrayOffset); err != nil {
+				return
+			}
+		}
+		if (it.idx+1)*isz <= uint64(len(it.items)) {
+			if off = it.f.item(it.items[it.idx*isz:]); off != 0 {
+				it.idx++
+				ok = true
+				return
+			}
+			// the slot was not filled when we read it, re-read the remainder of the array
+			pos := int64(it.arr) + entryArrayHeaderSize + int64(it.idx*isz)
+			if _, err = it.f.fin.ReadAt(it.items[it.idx*isz:], pos); err != nil {
+				return
+			}
+			if off = it.f.item(it.items[it.idx*isz:]); off != 0 {
+				it.idx++
+				ok = true
+			}
+			return
+		}
+		// the current array is exhausted, follow the link to the next one if there is one
+		var next uint64
+		if next, err = it.nextArray(); err != nil || next == 0 {
+			return
+		}
+		if err = it.load(next); err != nil {
+			return
+		}
+	}
+}
+
+func (it *Iterator) nextArray() (uint64, error) {
+	var b [8]byte
+	if _, err := it.f.fin.ReadAt(b[:], int64(it.arr)+objectHeaderSize); err != nil {
+		return 0, err
+	}
+	return binary.LittleEndian.Uint64(b[:]), nil
+}
+
+func (it *Iterator) load(off uint64) error {
+	_, b, err := it.f.readObject(off, ObjectEntryArray)
+	if err != nil {
+		return err
+	} else if len(b) < entryArrayHeaderSize {
+		return fmt.Errorf("%w: short entry array at offset %d", ErrCorruptObject, off)
+	}
+	if off != it.arr {
+		it.idx = 0
+	}
+	it.arr = off
+	it.items = b[entryArrayHeaderSize:]
+	return nil
+}
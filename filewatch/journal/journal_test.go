@@ -0,0 +1,168 @@
+/*************************************************************************
+ * Copyright 2026 Gravwell, Inc. All rights reserved.
+ * Contact: <legal@gravwell.io>
+ *
+ * This software may be modified and distributed under the terms of the
+ * BSD 2-clause license. See the LICENSE file for details.
+ **************************************************************************/
+
+package journal_test
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gravwell/gravwell/v3/filewatch/journal"
+	"github.com/gravwell/gravwell/v3/filewatch/journal/journaltest"
+)
+
+var (
+	testSeqnumID = journal.ID128{1, 2, 3, 4}
+	testBootID   = journal.ID128{9, 9, 9}
+	testStart    = time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+)
+
+// readAll walks an iterator to the current end of the file
+func readAll(t *testing.T, f *journal.File, it *journal.Iterator) (ents []journal.Entry) {
+	t.Helper()
+	for {
+		off, ok, err := it.Next()
+		if err != nil {
+			t.Fatal(err)
+		} else if !ok {
+			return
+		}
+		ent, err := f.ReadEntry(off)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ents = append(ents, ent)
+	}
+}
+
+func TestReadEntries(t *testing.T) {
+	for _, opts := range []journaltest.Options{
+		{},
+		{Compact: true},
+		{Compress: true},
+		{Compact: true, Compress: true},
+	} {
+		t.Run(fmt.Sprintf("compact=%v/compress=%v", opts.Compact, opts.Compress), func(t *testing.T) {
+			opts.SeqnumID, opts.BootID = testSeqnumID, testBootID
+			pth := filepath.Join(t.TempDir(), `system.journal`)
+			w, err := journaltest.Create(pth, opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer w.Close(false)
+			// enough entries to span several entry arrays
+			const count = 50
+			for i := 0; i < count; i++ {
+				err = w.Append(uint64(i+1), testStart.Add(time.Duration(i)*time.Second),
+					fmt.Sprintf("MESSAGE=message %d", i), `PRIORITY=6`, "_SYSTEMD_UNIT=test.service")
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			f, err := journal.Open(pth)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+			hdr := f.Header()
+			if hdr.SeqnumID != testSeqnumID || hdr.NEntries != count || hdr.HeadEntrySeqnum != 1 || hdr.TailEntrySeqnum != count {
+				t.Fatalf("bad header %+v", hdr)
+			} else if hdr.Compact() != opts.Compact {
+				t.Fatalf("compact flag mismatch")
+			}
+			ents := readAll(t, f, f.Entries())
+			if len(ents) != count {
+				t.Fatalf("got %d entries, expected %d", len(ents), count)
+			}
+			for i, ent := range ents {
+				if ent.Seqnum != uint64(i+1) || !ent.Realtime.Equal(testStart.Add(time.Duration(i)*time.Second)) || ent.BootID != testBootID {
+					t.Fatalf("bad entry %d %+v", i, ent)
+				}
+				if v, ok := ent.Get(`MESSAGE`); !ok || string(v) != fmt.Sprintf("message %d", i) {
+					t.Fatalf("bad message %q", v)
+				} else if v, ok = ent.Get(`_SYSTEMD_UNIT`); !ok || string(v) != `test.service` {
+					t.Fatalf("bad unit %q", v)
+				} else if _, ok = ent.Get(`MISSING`); ok {
+					t.Fatal("found missing field")
+				}
+			}
+		})
+	}
+}
+
+func TestFollow(t *testing.T) {
+	pth := filepath.Join(t.TempDir(), `system.journal`)
+	w, err := journaltest.Create(pth, journaltest.Options{SeqnumID: testSeqnumID, Compact: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close(false)
+	f, err := journal.Open(pth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	it := f.Entries()
+	if ents := readAll(t, f, it); len(ents) != 0 {
+		t.Fatalf("empty file returned %d entries", len(ents))
+	}
+
+	var seq uint64
+	for round := 1; round <= 5; round++ {
+		// append a few entries, crossing array boundaries, and make sure we pick up exactly the new ones
+		for i := 0; i < round*3; i++ {
+			seq++
+			if err = w.Append(seq, testStart, fmt.Sprintf("MESSAGE=%d", seq)); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err = f.Refresh(); err != nil {
+			t.Fatal(err)
+		}
+		ents := readAll(t, f, it)
+		if len(ents) != round*3 {
+			t.Fatalf("round %d: got %d entries", round, len(ents))
+		} else if ents[len(ents)-1].Seqnum != seq {
+			t.Fatalf("round %d: last seqnum %d, expected %d", round, ents[len(ents)-1].Seqnum, seq)
+		}
+	}
+}
+
+func TestBadFiles(t *testing.T) {
+	dir := t.TempDir()
+	pth := filepath.Join(dir, `bad.journal`)
+	if err := os.WriteFile(pth, []byte("this is not a journal file"), 0640); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := journal.Open(pth); !errors.Is(err, journal.ErrBadSignature) {
+		t.Fatalf("bad error %v", err)
+	}
+
+	pth = filepath.Join(dir, `flags.journal`)
+	w, err := journaltest.Create(pth, journaltest.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Close(true)
+	b, err := os.ReadFile(pth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b[12] |= 0x80 // an incompatible flag we do not know about
+	if err = os.WriteFile(pth, b, 0640); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = journal.Open(pth); !errors.Is(err, journal.ErrUnsupportedFlags) {
+		t.Fatalf("bad error %v", err)
+	}
+}
@@ -0,0 +1,293 @@
+/*************************************************************************
+ * Copyright 2026 Gravwell, Inc. All rights reserved.
+ * Contact: <legal@gravwell.io>
+ *
+ * This software may be modified and distributed under the terms of the
+ * BSD 2-clause license. See the LICENSE file for details.
+ **************************************************************************/
+
+// Package journaltest writes small systemd journal files for tests.
+// Files contain the header, data, entry, and entry array objects that readers walk,
+// hash tables are not written.
+package journaltest
+
+import (
+	"encoding/binary"
+	"errors"
+	"hash/fnv"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gravwell/gravwell/v3/filewatch/journal"
+	"github.com/klauspost/compress/zstd"
+)
+
+const (
+	headerSize       = 272
+	firstArrayItems  = 4
+	objectHeaderSize = 16
+)
+
+var (
+	ErrClosed       = errors.New("journal writer is closed")
+	ErrInvalidField = errors.New("fields must be in FIELD=value form")
+)
+
+// Options controls the layout of a written journal file
+type Options struct {
+	SeqnumID  journal.ID128
+	MachineID journal.ID128
+	BootID    journal.ID128
+	Compact   bool // use 32 bit offsets, as current journald does by default
+	Compress  bool // compress every data object with zstd
+}
+
+// Writer appends entries to a journal file, the header is updated after every entry
+// so a reader can follow the file while it is written.
+type Writer struct {
+	Options
+	f    *os.File
+	off  uint64
+	data map[string]uint64
+	zenc *zstd.Encoder
+
+	nObjects, nEntries, nData, nArrays uint64
+	headSeq, tailSeq                   uint64
+	headRT, tailRT, tailMono           uint64
+	firstArr, curArr, tailEntry        uint64
+	curCap, curCount                   uint64
+	state                              uint8
+}
+
+// Create creates a new online journal file
+func Create(pth string, opts Options) (*Writer, error) {
+	f, err := os.OpenFile(pth, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0640)
+	if err != nil {
+		return nil, err
+	}
+	w := &Writer{
+		Options: opts,
+		f:       f,
+		off:     headerSize,
+		data:    map[string]uint64{},
+		state:   journal.StateOnline,
+	}
+	if opts.Compress {
+		if w.zenc, err = zstd.NewWriter(nil); err != nil {
+			f.Close()
+			return nil, err
+		}
+	}
+	if err = w.writeHeader(); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return w, nil
+}
+
+func (w *Writer) itemSize() uint64 {
+	if w.Compact {
+		return 4
+	}
+	return 8
+}
+
+func (w *Writer) putItem(b []byte, v uint64) {
+	if w.Compact {
+		binary.LittleEndian.PutUint32(b, uint32(v))
+	} else {
+		binary.LittleEndian.PutUint64(b, v)
+	}
+}
+
+// alloc writes an object at the end of the file and returns its offset
+func (w *Writer) alloc(typ, flags uint8, body []byte) (uint64, error) {
+	off := w.off
+	obj := make([]byte, objectHeaderSize+len(body))
+	obj[0] = typ
+	obj[1] = flags
+	binary.LittleEndian.PutUint64(obj[8:], uint64(len(obj)))
+	copy(obj[objectHeaderSize:], body)
+	if _, err := w.f.WriteAt(obj, int64(off)); err != nil {
+		return 0, err
+	}
+	w.off = (off + uint64(len(obj)) + 7) &^ 7
+	w.nObjects++
+	return off, nil
+}
+
+func hash(b []byte) uint64 {
+	h := fnv.New64a()
+	h.Write(b)
+	return h.Sum64()
+}
+
+func (w *Writer) dataObject(fld string) (uint64, error) {
+	if off, ok := w.data[fld]; ok {
+		return off, nil
+	}
+	hdr := 48 // hash through n_entries
+	if w.Compact {
+		hdr += 8
+	}
+	payload := []byte(fld)
+	var flags uint8
+	if w.zenc != nil {
+		payload = w.zenc.EncodeAll(payload, nil)
+		flags = journal.ObjectCompressedZSTD
+	}
+	body := make([]byte, hdr+len(payload))
+	binary.LittleEndian.PutUint64(body, hash([]byte(fld)))
+	copy(body[hdr:], payload)
+	off, err := w.alloc(journal.ObjectData, flags, body)
+	if err != nil {
+		return 0, err
+	}
+	w.data[fld] = off
+	w.nData++
+	return off, nil
+}
+
+// Append writes an entry with the given FIELD=value pairs
+func (w *Writer) Append(seqnum uint64, realtime time.Time, fields ...string) error {
+	if w.f == nil {
+		return ErrClosed
+	}
+	stride := 16
+	if w.Compact {
+		stride = 4
+	}
+	body := make([]byte, 48+stride*len(fields))
+	binary.LittleEndian.PutUint64(body[0:], seqnum)
+	binary.LittleEndian.PutUint64(body[8:], uint64(realtime.UnixMicro()))
+	binary.LittleEndian.PutUint64(body[16:], seqnum*1000)
+	copy(body[24:40], w.BootID[:])
+	var xor uint64
+	for i, fld := range fields {
+		if !strings.Contains(fld, `=`) || strings.HasPrefix(fld, `=`) {
+			return ErrInvalidField
+		}
+		off, err := w.dataObject(fld)
+		if err != nil {
+			return err
+		}
+		h := hash([]byte(fld))
+		xor ^= h
+		item := body[48+stride*i:]
+		if w.Compact {
+			binary.LittleEndian.PutUint32(item, uint32(off))
+		} else {
+			binary.LittleEndian.PutUint64(item, off)
+			binary.LittleEndian.PutUint64(item[8:], h)
+		}
+	}
+	binary.LittleEndian.PutUint64(body[40:], xor)
+	off, err := w.alloc(journal.ObjectEntry, 0, body)
+	if err != nil {
+		return err
+	}
+	if err = w.link(off); err != nil {
+		return err
+	}
+	if w.nEntries == 0 {
+		w.headSeq = seqnum
+		w.headRT = uint64(realtime.UnixMicro())
+	}
+	w.nEntries++
+	w.tailSeq = seqnum
+	w.tailRT = uint64(realtime.UnixMicro())
+	w.tailMono = seqnum * 1000
+	w.tailEntry = off
+	return w.writeHeader()
+}
+
+// link adds an entry to the entry array chain, growing the chain when the current array is full
+func (w *Writer) link(entry uint64) error {
+	isz := w.itemSize()
+	if w.curArr == 0 || w.curCount == w.curCap {
+		capacity := uint64(firstArrayItems)
+		if w.curCap > 0 {
+			capacity = w.curCap * 2
+		}
+		arr, err := w.alloc(journal.ObjectEntryArray, 0, make([]byte, 8+capacity*isz))
+		if err != nil {
+			return err
+		}
+		w.nArrays++
+		if w.curArr == 0 {
+			w.firstArr = arr
+		} else {
+			var b [8]byte
+			binary.LittleEndian.PutUint64(b[:], arr)
+			if _, err = w.f.WriteAt(b[:], int64(w.curArr)+objectHeaderSize); err != nil {
+				return err
+			}
+		}
+		w.curArr, w.curCap, w.curCount = arr, capacity, 0
+	}
+	b := make([]byte, isz)
+	w.putItem(b, entry)
+	if _, err := w.f.WriteAt(b, int64(w.curArr+objectHeaderSize+8+w.curCount*isz)); err != nil {
+		return err
+	}
+	w.curCount++
+	return nil
+}
+
+func (w *Writer) writeHeader() error {
+	le := binary.LittleEndian
+	b := make([]byte, headerSize)
+	copy(b, journal.Signature)
+	var incompat uint32
+	if w.Compact {
+		incompat |= journal.IncompatCompact | journal.IncompatKeyedHash
+	}
+	if w.Compress {
+		incompat |= journal.IncompatCompressedZSTD
+	}
+	le.PutUint32(b[12:], incompat)
+	b[16] = w.state
+	fid := hash([]byte(w.f.Name()))
+	le.PutUint64(b[24:], fid)
+	copy(b[40:56], w.MachineID[:])
+	copy(b[56:72], w.BootID[:])
+	copy(b[72:88], w.SeqnumID[:])
+	le.PutUint64(b[88:], headerSize)
+	le.PutUint64(b[96:], w.off-headerSize)
+	le.PutUint64(b[144:], w.nObjects)
+	le.PutUint64(b[152:], w.nEntries)
+	le.PutUint64(b[160:], w.tailSeq)
+	le.PutUint64(b[168:], w.headSeq)
+	le.PutUint64(b[176:], w.firstArr)
+	le.PutUint64(b[184:], w.headRT)
+	le.PutUint64(b[192:], w.tailRT)
+	le.PutUint64(b[200:], w.tailMono)
+	le.PutUint64(b[208:], w.nData)
+	le.PutUint64(b[232:], w.nArrays)
+	le.PutUint32(b[256:], uint32(w.curArr))
+	le.PutUint32(b[260:], uint32(w.curCount))
+	le.PutUint64(b[264:], w.tailEntry)
+	_, err := w.f.WriteAt(b, 0)
+	return err
+}
+
+// Close marks the file offline, or archived if requested, and closes it
+func (w *Writer) Close(archive bool) error {
+	if w.f == nil {
+		return ErrClosed
+	}
+	w.state = journal.StateOffline
+	if archive {
+		w.state = journal.StateArchived
+	}
+	err := w.writeHeader()
+	if cerr := w.f.Close(); err == nil {
+		err = cerr
+	}
+	if w.zenc != nil {
+		w.zenc.Close()
+	}
+	w.f = nil
+	return err
+}
@@ -0,0 +1,464 @@
+/*************************************************************************
+ * Copyright 2026 Gravwell, Inc. All rights reserved.
+ * Contact: <legal@gravwell.io>
+ *
+ * This software may be modified and distributed under the terms of the
+ * BSD 2-clause license. See the LICENSE file for details.
+ **************************************************************************/
+
+package filewatch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gravwell/gravwell/v3/filewatch/journal"
+	"github.com/gravwell/gravwell/v3/ingest"
+	"github.com/gravwell/gravwell/v3/ingest/entry"
+	"github.com/gravwell/gravwell/v3/ingest/log"
+)
+
+const (
+	DefaultJournalDirectory = `/var/log/journal`
+	AllJournalFields        = `*`
+
+	// journal cursors live in the state file next to file offsets, keyed by the follower name and sequence number space
+	journalStatePrefix  = `journal:`
+	journalPollInterval = 500 * time.Millisecond
+	journalMessageField = `MESSAGE`
+)
+
+var (
+	ErrJournalNoName     = errors.New("journal follower name is empty")
+	ErrJournalNoHandler  = errors.New("journal follower handler is nil")
+	ErrInvalidPriority   = errors.New("invalid journal priority")
+	ErrDuplicateJournal  = errors.New("journal follower already exists")
+	DefaultJournalFields = []string{
+		`PRIORITY`, `SYSLOG_IDENTIFIER`, `SYSLOG_FACILITY`, `_SYSTEMD_UNIT`, `_PID`, `_UID`, `_GID`,
+		`_COMM`, `_EXE`, `_HOSTNAME`, `_TRANSPORT`, `_BOOT_ID`,
+	}
+
+	journalPriorities = []string{`emerg`, `alert`, `crit`, `err`, `warning`, `notice`, `info`, `debug`}
+	// fields that are always integers are attached as integer enumerated values
+	journalIntFields = map[string]bool{`PRIORITY`: true, `SYSLOG_FACILITY`: true, `_PID`: true, `_UID`: true, `_GID`: true}
+)
+
+type journalHandler interface {
+	HandleJournalEntry(*journal.Entry) error
+	Tag() string
+}
+
+// JournalConfig describes a directory of systemd journal files to follow
+type JournalConfig struct {
+	Name        string // unique name, used to key cursors in the state file
+	Dir         string // defaults to DefaultJournalDirectory
+	StartAtTail bool   // when no cursor exists only ingest entries written after startup
+	Hnd         journalHandler
+}
+
+// ParseJournalPriority converts a syslog priority name or number to its numeric level
+func ParseJournalPriority(v string) (int, error) {
+	v = strings.ToLower(strings.TrimSpace(v))
+	if n, err := strconv.Atoi(v); err == nil {
+		if n < 0 || n >= len(journalPriorities) {
+			return -1, fmt.Errorf("%w %q", ErrInvalidPriority, v)
+		}
+		return n, nil
+	}
+	switch v {
+	case `emergency`, `panic`:
+		return 0, nil
+	case `critical`:
+		return 2, nil
+	case `error`:
+		return 3, nil
+	case `warn`:
+		return 4, nil
+	case `informational`:
+		return 6, nil
+	}
+	for i, p := range journalPriorities {
+		if v == p {
+			return i, nil
+		}
+	}
+	return -1, fmt.Errorf("%w %q", ErrInvalidPriority, v)
+}
+
+type JournalHandlerConfig struct {
+	TagName     string
+	Tag         entry.EntryTag
+	Src         net.IP
+	IgnoreTS    bool
+	Units       []string // globs matched against _SYSTEMD_UNIT
+	Identifiers []string // globs matched against SYSLOG_IDENTIFIER
+	MaxPriority string   // drop entries less severe than this priority
+	Fields      []string // fields attached as enumerated values, nil uses DefaultJournalFields
+	Logger      logger
+	Debugger    debugOut
+	Ctx         context.Context
+}
+
+// JournalHandler filters journal entries and converts them into gravwell entries
+type JournalHandler struct {
+	JournalHandlerConfig
+	w         logWriter
+	maxPri    int
+	allFields bool
+	fields    map[string]bool
+}
+
+func NewJournalHandler(cfg JournalHandlerConfig, w logWriter) (*JournalHandler, error) {
+	if w == nil {
+		return nil, errors.New("output writer is nil")
+	}
+	if cfg.Logger == nil {
+		return nil, errors.New("Logger is nil")
+	}
+	for _, g := range append(append([]string{}, cfg.Units...), cfg.Identifiers...) {
+		if _, err := filepath.Match(g, ``); err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", g, err)
+		}
+	}
+	jh := &JournalHandler{
+		JournalHandlerConfig: cfg,
+		w:                    w,
+		maxPri:               -1,
+		fields:               map[string]bool{},
+	}
+	if cfg.MaxPriority != `` {
+		var err error
+		if jh.maxPri, err = ParseJournalPriority(cfg.MaxPriority); err != nil {
+			return nil, err
+		}
+	}
+	fields := cfg.Fields
+	if len(fields) == 0 {
+		fields = DefaultJournalFields
+	}
+	for _, f := range fields {
+		if f == AllJournalFields {
+			jh.allFields = true
+		} else if f != `` {
+			jh.fields[f] = true
+		}
+	}
+	return jh, nil
+}
+
+func (jh *JournalHandler) Tag() string {
+	return jh.TagName
+}
+
+func globMatch(globs []string, ent *journal.Entry, field string) bool {
+	if len(globs) == 0 {
+		return true
+	}
+	v, ok := ent.Get(field)
+	if !ok {
+		return false
+	}
+	for _, g := range globs {
+		if m, _ := filepath.Match(g, string(v)); m {
+			return true
+		}
+	}
+	return false
+}
+
+// filter returns true if the entry should be ingested
+func (jh *JournalHandler) filter(ent *journal.Entry) bool {
+	if !globMatch(jh.Units, ent, `_SYSTEMD_UNIT`) {
+		return false
+	} else if !globMatch(jh.Identifiers, ent, `SYSLOG_IDENTIFIER`) {
+		return false
+	}
+	if jh.maxPri >= 0 {
+		v, ok := ent.Get(`PRIORITY`)
+		if !ok {
+			return false
+		}
+		if p, err := strconv.Atoi(string(v)); err != nil || p > jh.maxPri {
+			return false
+		}
+	}
+	return true
+}
+
+// HandleJournalEntry attaches the selected journal fields and hands the message to the writer
+// entries without a MESSAGE field or that do not pass the filters are dropped.
+func (jh *JournalHandler) HandleJournalEntry(jent *journal.Entry) error {
+	msg, ok := jent.Get(journalMessageField)
+	if !ok || len(msg) == 0 || !jh.filter(jent) {
+		return nil
+	}
+	ts := jent.Realtime
+	if jh.IgnoreTS {
+		ts = time.Now()
+	}
+	if jh.Debugger != nil {
+		jh.Debugger("GOT %s %s\n", ts.Format(time.RFC3339), string(msg))
+	}
+	ent := &entry.Entry{
+		SRC:  jh.Src,
+		TS:   entry.FromStandard(ts),
+		Tag:  jh.JournalHandlerConfig.Tag,
+		Data: append([]byte(nil), msg...),
+	}
+	for _, f := range jent.Fields {
+		if f.Name == journalMessageField || (!jh.allFields && !jh.fields[f.Name]) {
+			continue
+		}
+		if !utf8.Valid(f.Value) {
+			continue // binary fields are not attached
+		}
+		ev := entry.EnumeratedValue{Name: f.Name}
+		if journalIntFields[f.Name] {
+			if n, err := strconv.Atoi(string(f.Value)); err == nil {
+				ev.Value = entry.IntEnumData(n)
+			}
+		}
+		if !ev.Value.Valid() {
+			ev.Value = entry.StringEnumDataTail(string(f.Value))
+		}
+		ent.AddEnumeratedValue(ev) // invalid names are skipped
+
+	}
+	return jh.w.ProcessContext(ent, jh.Ctx)
+}
+
+// journalState returns the cursor for a journal sequence number space, creating it if needed.
+// The cursor holds the last handled sequence number.
+func (f *FilterManager) journalState(name string, id journal.ID128) (st *int64, existed bool) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	stid := FileName{
+		BaseName: journalStatePrefix + name,
+		FilePath: id.String(),
+	}
+	if st, existed = f.states[stid]; !existed || st == nil {
+		st = new(int64)
+		f.states[stid] = st
+	}
+	return
+}
+
+// setJournalState updates a cursor under the lock so state dumps see a consistent value
+func (f *FilterManager) setJournalState(st *int64, seqnum uint64) {
+	f.mtx.Lock()
+	*st = int64(seqnum)
+	f.mtx.Unlock()
+}
+
+func isJournalState(k FileName) bool {
+	return strings.HasPrefix(k.BaseName, journalStatePrefix)
+}
+
+// journalFile is an open journal file along with where we are in it
+type journalFile struct {
+	*journal.File
+	fi      os.FileInfo
+	it      *journal.Iterator
+	pending bool // off and seqnum hold an entry that has not been handled
+	off     uint64
+	seqnum  uint64
+}
+
+// peek loads the next entry offset and sequence number if we do not already have one
+func (jf *journalFile) peek() (bool, error) {
+	if jf.pending {
+		return true, nil
+	}
+	off, ok, err := jf.it.Next()
+	if err != nil || !ok {
+		return false, err
+	}
+	if jf.seqnum, err = jf.EntrySeqnum(off); err != nil {
+		return false, err
+	}
+	jf.off, jf.pending = off, true
+	return true, nil
+}
+
+// journalFollower polls a journal directory and merges entries from all files by sequence number.
+// journald shares one sequence number space between the system and user journals and their
+// archives, so a single cursor per space lets us follow rotation without duplicating entries.
+type journalFollower struct {
+	JournalConfig
+	fman    *FilterManager
+	lgr     ingest.IngestLogger
+	files   map[string]*journalFile
+	started bool
+}
+
+func newJournalFollower(cfg JournalConfig, fman *FilterManager, lgr ingest.IngestLogger) *journalFollower {
+	return &journalFollower{
+		JournalConfig: cfg,
+		fman:          fman,
+		lgr:           lgr,
+		files:         map[string]*journalFile{},
+	}
+}
+
+func (j *journalFollower) routine(ctx context.Context, wg *sync.WaitGroup) {
+	defer wg.Done()
+	defer j.close()
+	tckr := time.NewTicker(journalPollInterval)
+	defer tckr.Stop()
+	for {
+		if err := j.poll(ctx); err != nil && ctx.Err() == nil {
+			j.lgr.Error("failed to read journal", log.KV("journal", j.Name), log.KV("path", j.Dir), log.KVErr(err))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-tckr.C:
+		}
+	}
+}
+
+func (j *journalFollower) close() {
+	for k, jf := range j.files {
+		jf.Close()
+		delete(j.files, k)
+	}
+}
+
+// discover finds the journal files in the directory and the per machine subdirectories beneath it
+func (j *journalFollower) discover() (pths []string, err error) {
+	for _, pattern := range []string{`*.journal`, filepath.Join(`*`, `*.journal`)} {
+		var matches []string
+		if matches, err = filepath.Glob(filepath.Join(j.Dir, pattern)); err != nil {
+			return
+		}
+		pths = append(pths, matches...)
+	}
+	return
+}
+
+// refresh syncs the set of open files with the directory, reopening files that were replaced
+func (j *journalFollower) refresh() error {
+	pths, err := j.discover()
+	if err != nil {
+		return err
+	}
+	seen := make(map[string]bool, len(pths))
+	for _, pth := range pths {
+		fi, err := os.Stat(pth)
+		if err != nil || !fi.Mode().IsRegular() {
+			continue
+		}
+		seen[pth] = true
+		if jf, ok := j.files[pth]; ok {
+			if os.SameFile(fi, jf.fi) {
+				if err = jf.Refresh(); err == nil {
+					continue
+				}
+				j.lgr.Warn("failed to refresh journal file", log.KV("path", pth), log.KVErr(err))
+			}
+			jf.Close()
+			delete(j.files, pth)
+		}
+		f, err := journal.Open(pth)
+		if err != nil {
+			// journald creates files before writing the header, we will try again on the next poll
+			if !errors.Is(err, journal.ErrBadSignature) {
+				j.lgr.Warn("failed to open journal file", log.KV("path", pth), log.KVErr(err))
+			}
+			continue
+		}
+		j.files[pth] = &journalFile{File: f, fi: fi, it: f.Entries()}
+	}
+	for pth, jf := range j.files {
+		if !seen[pth] {
+			jf.Close()
+			delete(j.files, pth)
+		}
+	}
+	return nil
+}
+
+func (j *journalFollower) poll(ctx context.Context) error {
+	if err := j.refresh(); err != nil {
+		return err
+	}
+	groups := map[journal.ID128][]*journalFile{}
+	for _, jf := range j.files {
+		id := jf.Header().SeqnumID
+		groups[id] = append(groups[id], jf)
+	}
+	first := !j.started
+	j.started = true
+	for id, files := range groups {
+		if err := j.pollGroup(ctx, id, files, first); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pollGroup handles all new entries in a sequence number space in order
+func (j *journalFollower) pollGroup(ctx context.Context, id journal.ID128, files []*journalFile, first bool) error {
+	// only merge entries that were linked when we read the headers, every file has them all by now
+	var limit uint64
+	for _, jf := range files {
+		if tail := jf.Header().TailEntrySeqnum; tail > limit {
+			limit = tail
+		}
+	}
+	cursor, existed := j.fman.journalState(j.Name, id)
+	if !existed && first && j.StartAtTail {
+		j.fman.setJournalState(cursor, limit)
+		return nil
+	}
+	last := uint64(*cursor)
+	if limit <= last {
+		return nil
+	}
+	sort.Slice(files, func(a, b int) bool { return files[a].Name() < files[b].Name() })
+	for ctx.Err() == nil {
+		var next *journalFile
+		for _, jf := range files {
+			if jf.Header().TailEntrySeqnum <= last && !jf.pending {
+				continue // nothing new in this file
+			}
+			ok, err := jf.peek()
+			if err != nil {
+				j.lgr.Warn("failed to walk journal file", log.KV("path", jf.Name()), log.KVErr(err))
+				continue
+			}
+			if ok && jf.seqnum <= limit && (next == nil || jf.seqnum < next.seqnum) {
+				next = jf
+			}
+		}
+		if next == nil {
+			break
+		}
+		next.pending = false
+		if next.seqnum <= last {
+			continue // already handled, either before a restart or from a file that was rotated
+		}
+		ent, err := next.ReadEntry(next.off)
+		if err != nil {
+			j.lgr.Warn("failed to read journal entry", log.KV("path", next.Name()), log.KV("offset", next.off), log.KVErr(err))
+			continue
+		}
+		if err = j.Hnd.HandleJournalEntry(&ent); err != nil {
+			next.pending = true // retry on the next poll
+			return err
+		}
+		last = ent.Seqnum
+		j.fman.setJournalState(cursor, last)
+	}
+	return ctx.Err()
+}
@@ -0,0 +1,295 @@
+/*************************************************************************
+ * Copyright 2026 Gravwell, Inc. All rights reserved.
+ * Contact: <legal@gravwell.io>
+ *
+ * This software may be modified and distributed under the terms of the
+ * BSD 2-clause license. See the LICENSE file for details.
+ **************************************************************************/
+
+package filewatch
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gravwell/gravwell/v3/filewatch/journal"
+	"github.com/gravwell/gravwell/v3/filewatch/journal/journaltest"
+	"github.com/gravwell/gravwell/v3/ingest/entry"
+	"github.com/gravwell/gravwell/v3/ingest/log"
+)
+
+var (
+	testJournalSeqnumID = journal.ID128{0xde, 0xad, 0xbe, 0xef}
+	testJournalStart    = time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+)
+
+type entryCollector struct {
+	sync.Mutex
+	ents []*entry.Entry
+}
+
+func (ec *entryCollector) ProcessContext(ent *entry.Entry, ctx context.Context) error {
+	ec.Lock()
+	ec.ents = append(ec.ents, ent)
+	ec.Unlock()
+	return nil
+}
+
+func (ec *entryCollector) wait(t *testing.T, n int) []*entry.Entry {
+	t.Helper()
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(20 * time.Millisecond) {
+		ec.Lock()
+		if l := len(ec.ents); l >= n {
+			ents := ec.ents
+			ec.ents = nil
+			ec.Unlock()
+			if l > n {
+				t.Fatalf("got %d entries, expected %d", l, n)
+			}
+			return ents
+		}
+		ec.Unlock()
+	}
+	t.Fatalf("timed out waiting for %d entries", n)
+	return nil
+}
+
+func newTestJournalHandler(t *testing.T, cfg JournalHandlerConfig, ec *entryCollector) *JournalHandler {
+	t.Helper()
+	cfg.Logger = log.NewDiscardLogger()
+	jh, err := NewJournalHandler(cfg, ec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return jh
+}
+
+func testJournalFields(seqnum uint64, unit string) []string {
+	return []string{
+		fmt.Sprintf("MESSAGE=entry %d", seqnum),
+		fmt.Sprintf("PRIORITY=%d", seqnum%8),
+		`SYSLOG_IDENTIFIER=` + unit,
+		`_SYSTEMD_UNIT=` + unit + `.service`,
+		`_PID=1234`,
+		`_CMDLINE=/usr/bin/thing --flag`,
+	}
+}
+
+func startJournalWatcher(t *testing.T, stateFile, dir string, jh *JournalHandler, tail bool) *WatchManager {
+	t.Helper()
+	wm, err := NewWatcher(stateFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = wm.AddJournal(JournalConfig{Name: `test`, Dir: dir, StartAtTail: tail, Hnd: jh}); err != nil {
+		t.Fatal(err)
+	} else if err = wm.AddJournal(JournalConfig{Name: `test`, Dir: dir, Hnd: jh}); err == nil {
+		t.Fatal("duplicate journal name accepted")
+	}
+	if err = wm.Start(); err != nil {
+		t.Fatal(err)
+	}
+	return wm
+}
+
+func checkSeqnums(t *testing.T, ents []*entry.Entry, first uint64) {
+	t.Helper()
+	for i, ent := range ents {
+		exp := fmt.Sprintf("entry %d", first+uint64(i))
+		if string(ent.Data) != exp {
+			t.Fatalf("entry %d: got %q expected %q", i, ent.Data, exp)
+		}
+	}
+}
+
+func TestJournalFollow(t *testing.T) {
+	dir := t.TempDir()
+	stateFile := filepath.Join(t.TempDir(), `state`)
+	mdir := filepath.Join(dir, `0123456789abcdef`)
+	if err := os.Mkdir(mdir, 0750); err != nil {
+		t.Fatal(err)
+	}
+	opts := journaltest.Options{SeqnumID: testJournalSeqnumID, Compact: true}
+	sys, err := journaltest.Create(filepath.Join(mdir, `system.journal`), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	user, err := journaltest.Create(filepath.Join(mdir, `user-1000.journal`), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// system and user journals share a sequence number space, interleave them
+	var seqnum uint64
+	appendN := func(n int) {
+		for i := 0; i < n; i++ {
+			seqnum++
+			w, unit := sys, `sshd`
+			if seqnum%3 == 0 {
+				w, unit = user, `user`
+			}
+			if err := w.Append(seqnum, testJournalStart.Add(time.Duration(seqnum)*time.Second), testJournalFields(seqnum, unit)...); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	appendN(20)
+
+	ec := &entryCollector{}
+	jh := newTestJournalHandler(t, JournalHandlerConfig{Tag: 7}, ec)
+	wm := startJournalWatcher(t, stateFile, dir, jh, false)
+	ents := ec.wait(t, 20)
+	checkSeqnums(t, ents, 1)
+	ent := ents[2] // seqnum 3 came from the user journal
+	if !ent.TS.StandardTime().Equal(testJournalStart.Add(3*time.Second)) || ent.Tag != 7 {
+		t.Fatalf("bad entry %+v", ent)
+	}
+	if v, ok := ent.GetEnumeratedValue(`_SYSTEMD_UNIT`); !ok || v != `user.service` {
+		t.Fatalf("bad unit %v", v)
+	} else if v, ok = ent.GetEnumeratedValue(`PRIORITY`); !ok || v != int64(3) {
+		t.Fatalf("bad priority %T %v", v, v)
+	} else if _, ok = ent.GetEnumeratedValue(`_CMDLINE`); ok {
+		t.Fatal("field outside the default set attached")
+	} else if _, ok = ent.GetEnumeratedValue(`MESSAGE`); ok {
+		t.Fatal("message attached as an enumerated value")
+	}
+
+	// live appends are picked up
+	appendN(10)
+	checkSeqnums(t, ec.wait(t, 10), 21)
+
+	// rotate the system journal, the archive keeps its contents and a new file continues the sequence
+	if err = sys.Close(true); err != nil {
+		t.Fatal(err)
+	} else if err = os.Rename(filepath.Join(mdir, `system.journal`), filepath.Join(mdir, `system@0001-0002.journal`)); err != nil {
+		t.Fatal(err)
+	}
+	if sys, err = journaltest.Create(filepath.Join(mdir, `system.journal`), opts); err != nil {
+		t.Fatal(err)
+	}
+	appendN(10)
+	checkSeqnums(t, ec.wait(t, 10), 31)
+	if err = wm.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// entries written while we were down are ingested after a restart, nothing is repeated
+	appendN(5)
+	wm = startJournalWatcher(t, stateFile, dir, jh, true)
+	checkSeqnums(t, ec.wait(t, 5), 41)
+	if err = wm.Close(); err != nil {
+		t.Fatal(err)
+	}
+	sys.Close(false)
+	user.Close(false)
+
+	states, err := ReadStateFile(stateFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := states[filepath.Join(testJournalSeqnumID.String(), journalStatePrefix+`test`)]; v != 45 {
+		t.Fatalf("bad cursor %d in %v", v, states)
+	}
+}
+
+func TestJournalStartAtTail(t *testing.T) {
+	dir := t.TempDir()
+	w, err := journaltest.Create(filepath.Join(dir, `system.journal`), journaltest.Options{SeqnumID: testJournalSeqnumID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close(false)
+	for i := uint64(1); i <= 5; i++ {
+		if err = w.Append(i, testJournalStart, testJournalFields(i, `cron`)...); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ec := &entryCollector{}
+	jh := newTestJournalHandler(t, JournalHandlerConfig{}, ec)
+	wm := startJournalWatcher(t, filepath.Join(t.TempDir(), `state`), dir, jh, true)
+	defer wm.Close()
+	time.Sleep(2 * journalPollInterval)
+	for i := uint64(6); i <= 8; i++ {
+		if err = w.Append(i, testJournalStart, testJournalFields(i, `cron`)...); err != nil {
+			t.Fatal(err)
+		}
+	}
+	checkSeqnums(t, ec.wait(t, 3), 6)
+}
+
+func TestJournalHandlerFilters(t *testing.T) {
+	ec := &entryCollector{}
+	jh := newTestJournalHandler(t, JournalHandlerConfig{
+		Units:       []string{`ssh*.service`, `cron.service`},
+		MaxPriority: `warning`,
+		Fields:      []string{AllJournalFields},
+	}, ec)
+	mk := func(fields ...string) *journal.Entry {
+		ent := &journal.Entry{Realtime: testJournalStart}
+		for _, f := range fields {
+			name, val, _ := strings.Cut(f, `=`)
+			ent.Fields = append(ent.Fields, journal.Field{Name: name, Value: []byte(val)})
+		}
+		return ent
+	}
+	tests := []struct {
+		ent  *journal.Entry
+		pass bool
+	}{
+		{mk(`MESSAGE=a`, `_SYSTEMD_UNIT=sshd.service`, `PRIORITY=3`), true},
+		{mk(`MESSAGE=b`, `_SYSTEMD_UNIT=cron.service`, `PRIORITY=4`), true},
+		{mk(`MESSAGE=c`, `_SYSTEMD_UNIT=sshd.service`, `PRIORITY=6`), false},
+		{mk(`MESSAGE=d`, `_SYSTEMD_UNIT=nginx.service`, `PRIORITY=0`), false},
+		{mk(`MESSAGE=e`, `PRIORITY=0`), false},
+		{mk(`_SYSTEMD_UNIT=sshd.service`, `PRIORITY=0`), false},
+		{mk(`MESSAGE=f`, `_SYSTEMD_UNIT=sshd.service`), false},
+	}
+	for i, tt := range tests {
+		if err := jh.HandleJournalEntry(tt.ent); err != nil {
+			t.Fatal(err)
+		}
+		ec.Lock()
+		got := len(ec.ents) == 1
+		ec.ents = nil
+		ec.Unlock()
+		if got != tt.pass {
+			t.Fatalf("test %d: got %v expected %v", i, got, tt.pass)
+		}
+	}
+
+	// all fields attaches everything except the message, binary values are dropped
+	jh.HandleJournalEntry(mk(`MESSAGE=x`, `_SYSTEMD_UNIT=cron.service`, `PRIORITY=1`, `CUSTOM=thing`, "BLOB=\xff\xfe"))
+	ent := ec.wait(t, 1)[0]
+	if v, ok := ent.GetEnumeratedValue(`CUSTOM`); !ok || v != `thing` {
+		t.Fatalf("bad custom field %v", v)
+	} else if _, ok = ent.GetEnumeratedValue(`BLOB`); ok {
+		t.Fatal("binary field attached")
+	} else if len(ent.EnumeratedValues()) != 3 {
+		t.Fatalf("got %d enumerated values", len(ent.EnumeratedValues()))
+	}
+
+	if _, err := NewJournalHandler(JournalHandlerConfig{Logger: log.NewDiscardLogger(), MaxPriority: `loud`}, ec); err == nil {
+		t.Fatal("bad priority accepted")
+	} else if _, err = NewJournalHandler(JournalHandlerConfig{Logger: log.NewDiscardLogger(), Units: []string{`[`}}, ec); err == nil {
+		t.Fatal("bad unit pattern accepted")
+	}
+}
+
+func TestParseJournalPriority(t *testing.T) {
+	tests := map[string]int{`0`: 0, `emerg`: 0, `ERR`: 3, `error`: 3, `warn`: 4, ` info `: 6, `7`: 7, `debug`: 7}
+	for v, exp := range tests {
+		if p, err := ParseJournalPriority(v); err != nil || p != exp {
+			t.Fatalf("%q: got %d %v expected %d", v, p, err, exp)
+		}
+	}
+	for _, v := range []string{``, `8`, `-1`, `verbose`} {
+		if _, err := ParseJournalPriority(v); err == nil {
+			t.Fatalf("%q accepted", v)
+		}
+	}
+}
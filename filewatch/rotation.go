@@ -0,0 +1,590 @@
+/*************************************************************************
+ * Copyright 2026 Gravwell, Inc. All rights reserved.
+ * Contact: <legal@gravwell.io>
+ *
+ * This software may be modified and distributed under the terms of the
+ * BSD 2-clause license. See the LICENSE file for details.
+ **************************************************************************/
+
+package filewatch
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gravwell/gravwell/v3/ingest/log"
+	"github.com/gravwell/gravwell/v3/ingesters/utils"
+)
+
+const (
+	IdentityInode       int = 0 // files are the same if they have the same device and inode
+	IdentityFingerprint int = 1 // files are the same if their first bytes are the same
+
+	DefaultFingerprintSize = 1024
+	MaxFingerprintSize     = 64 * 1024
+
+	// fingerprints shorter than this are too likely to collide, small files fall back to name lineage
+	minFingerprintMatch = 64
+
+	maxRotatedFiles       = 256
+	rotatedFileTTL        = 7 * 24 * time.Hour
+	rotationCheckInterval = 2 * time.Second
+	rotationSettle        = 2 * time.Second // files must sit unchanged this long before we decide what they are
+	compressedGiveUp      = time.Minute     // compressed files that match nothing are forgotten after this long
+)
+
+var (
+	ErrUnknownIdentity      = errors.New("unknown file identity mode")
+	ErrInvalidFingerprint   = errors.New("invalid fingerprint size")
+	ErrStreamEngine         = errors.New("engine cannot read compressed files")
+	compressionMagics       = [][]byte{{0x1f, 0x8b}, []byte("BZh"), {0xfd, '7', 'z', 'X', 'Z', 0x00}, {0x28, 0xb5, 0x2f, 0xfd}}
+	compressionExtensions   = []string{`.gz`, `.bz2`, `.xz`, `.zst`, `.zstd`}
+	errCompressedIncomplete = errors.New("compressed file is incomplete")
+)
+
+// RotationConfig controls how a filter recognizes its files after they are rotated
+type RotationConfig struct {
+	Identity         int   // IdentityInode or IdentityFingerprint
+	FingerprintSize  int64 // number of leading bytes that identify a file, zero uses DefaultFingerprintSize
+	FollowCompressed bool  // finish reading rotated files that were compressed before we were done with them
+}
+
+// ParseIdentity converts a configured identity name to an identity mode
+func ParseIdentity(v string) (int, error) {
+	switch strings.ToLower(strings.TrimSpace(v)) {
+	case ``, `inode`:
+		return IdentityInode, nil
+	case `fingerprint`:
+		return IdentityFingerprint, nil
+	}
+	return -1, fmt.Errorf("%w %q", ErrUnknownIdentity, v)
+}
+
+func (rc RotationConfig) Validate() error {
+	if rc.Identity != IdentityInode && rc.Identity != IdentityFingerprint {
+		return ErrUnknownIdentity
+	} else if rc.FingerprintSize < 0 || rc.FingerprintSize > MaxFingerprintSize {
+		return fmt.Errorf("%w %d, must be at most %d", ErrInvalidFingerprint, rc.FingerprintSize, MaxFingerprintSize)
+	}
+	return nil
+}
+
+// enabled indicates that followers need to keep fingerprints of their files
+func (rc RotationConfig) enabled() bool {
+	return rc.Identity == IdentityFingerprint || rc.FollowCompressed
+}
+
+func (rc RotationConfig) printSize() int64 {
+	if rc.FingerprintSize <= 0 {
+		return DefaultFingerprintSize
+	}
+	return rc.FingerprintSize
+}
+
+// fingerprint is a hash of the first Len bytes of a file
+type fingerprint struct {
+	Hash uint64
+	Len  int64
+}
+
+func hashPrefix(b []byte) fingerprint {
+	h := fnv.New64a()
+	h.Write(b)
+	return fingerprint{Hash: h.Sum64(), Len: int64(len(b))}
+}
+
+// fingerprintReader hashes up to n bytes from the reader, short readers produce short fingerprints
+func fingerprintReader(r io.Reader, n int64) (fingerprint, error) {
+	h := fnv.New64a()
+	m, err := io.CopyN(h, r, n)
+	if err != nil && err != io.EOF {
+		return fingerprint{}, err
+	}
+	return fingerprint{Hash: h.Sum64(), Len: m}, nil
+}
+
+// fingerprintFile hashes the first n bytes of an open file without moving its offset
+func fingerprintFile(fin io.ReaderAt, n int64) (fingerprint, error) {
+	return fingerprintReader(io.NewSectionReader(fin, 0, n), n)
+}
+
+// matches checks if the file starts with the fingerprinted bytes
+func (fp fingerprint) matches(fin io.ReaderAt) bool {
+	if fp.Len < minFingerprintMatch {
+		return false
+	}
+	v, err := fingerprintFile(fin, fp.Len)
+	return err == nil && v == fp
+}
+
+// rotatedFile records how far we read a file that rotated away, so its successor can pick up where we stopped
+type rotatedFile struct {
+	FileName // the filter base name and the last path the file had
+	Print    fingerprint
+	Offset   int64
+	When     time.Time
+}
+
+// rotationState is persisted in the state file after the offsets map, older readers ignore it
+type rotationState struct {
+	Prints  map[FileName]fingerprint
+	Rotated []rotatedFile
+}
+
+// pendingCompressed is a compressed file we are waiting on to finish being written
+type pendingCompressed struct {
+	filterID int
+	size     int64
+	mod      time.Time
+	changed  time.Time
+}
+
+func isCompressedFile(fpath string) bool {
+	fin, err := openDeletableFile(fpath)
+	if err != nil {
+		return false
+	}
+	defer fin.Close()
+	hdr := make([]byte, 6)
+	n, _ := io.ReadFull(fin, hdr)
+	for _, m := range compressionMagics {
+		if bytes.HasPrefix(hdr[:n], m) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasCompressionExt catches compressed files that are still empty and have no magic yet
+func hasCompressionExt(fpath string) bool {
+	return trimCompressionExt(fpath) != fpath
+}
+
+func trimCompressionExt(fpath string) string {
+	for _, ext := range compressionExtensions {
+		if strings.HasSuffix(fpath, ext) {
+			return strings.TrimSuffix(fpath, ext)
+		}
+	}
+	return fpath
+}
+
+// newStreamReader builds an entry reader over a stream, such as a decompressed file
+func newStreamReader(r io.Reader, ecfg FollowerEngineConfig) (Reader, error) {
+	br := baseReader{maxLine: defaultMaxLine}
+	switch ecfg.Engine {
+	case LineEngine:
+		return &LineReader{baseReader: br, brdr: bufio.NewReader(r)}, nil
+	case RegexEngine:
+		rx, err := regexp.Compile(ecfg.EngineArgs)
+		if err != nil {
+			return nil, err
+		}
+		return &RegexReader{
+			baseReader: br,
+			rx:         rx,
+			brdr:       bufio.NewReader(r),
+			lastRead:   time.Now(),
+		}, nil
+	}
+	return nil, ErrStreamEngine
+}
+
+// addRotated records a file that rotated away, followers call this when they see a truncation
+func (f *FilterManager) addRotated(rf rotatedFile) {
+	if rf.Offset <= 0 || rf.Print.Len == 0 {
+		return // nothing was read, the successor starts from the beginning anyway
+	}
+	f.rmtx.Lock()
+	defer f.rmtx.Unlock()
+	for i := range f.rotated {
+		if f.rotated[i].BaseName == rf.BaseName && f.rotated[i].Print == rf.Print {
+			f.rotated[i] = rf
+			return
+		}
+	}
+	f.rotated = append(f.rotated, rf)
+	if len(f.rotated) > maxRotatedFiles {
+		f.rotated = f.rotated[len(f.rotated)-maxRotatedFiles:]
+	}
+}
+
+// takeRotated removes and returns the first rotated file record that satisfies the match function
+func (f *FilterManager) takeRotated(bname string, match func(rotatedFile) bool) (rf rotatedFile, ok bool) {
+	f.rmtx.Lock()
+	defer f.rmtx.Unlock()
+	for i := range f.rotated {
+		if f.rotated[i].BaseName == bname && match(f.rotated[i]) {
+			rf, ok = f.rotated[i], true
+			f.rotated = append(f.rotated[:i], f.rotated[i+1:]...)
+			return
+		}
+	}
+	return
+}
+
+func (f *FilterManager) hasRotated(bname string) bool {
+	f.rmtx.Lock()
+	defer f.rmtx.Unlock()
+	for i := range f.rotated {
+		if f.rotated[i].BaseName == bname {
+			return true
+		}
+	}
+	return false
+}
+
+// nolockRetire records where a follower stopped when its file was removed or moved out of the filter.
+// The follower must already be closed so everything that could be read has been.
+// Caller MUST HOLD THE LOCK
+func (f *FilterManager) nolockRetire(fl *follower) {
+	if !fl.rcfg.enabled() {
+		return
+	}
+	f.addRotated(rotatedFile{
+		FileName: fl.FileName,
+		Print:    fl.Fingerprint(),
+		Offset:   *fl.state,
+		When:     time.Now(),
+	})
+}
+
+// nolockCheckIdentity makes sure a file state belongs to the content at the path when using fingerprint identity.
+// A state whose fingerprint no longer matches is retired so the rotated copy can pick it up, and a file
+// without a position inherits one from a rotated file or active follower with the same fingerprint.
+// Files that may still be in the middle of being copied are reported as not ready.
+// Caller MUST HOLD THE LOCK
+func (f *FilterManager) nolockCheckIdentity(v filter, fpath string, si *int64) (ready bool) {
+	ready = true
+	stid := FileName{BaseName: v.bname, FilePath: fpath}
+	if _, ok := f.followers[stid]; ok {
+		return // the active follower owns this state
+	}
+	fin, err := openDeletableFile(fpath)
+	if err != nil {
+		return
+	}
+	defer fin.Close()
+	fi, err := fin.Stat()
+	if err != nil {
+		return
+	}
+	if *si > 0 {
+		if p, ok := f.prints[stid]; ok && p.Len > 0 {
+			if cur, err := fingerprintFile(fin, p.Len); err == nil && cur == p {
+				return // same file, carry on
+			}
+			// a different file lives at this path now, the old content went somewhere else
+			f.addRotated(rotatedFile{FileName: stid, Print: p, Offset: *si, When: time.Now()})
+			f.logger.Info("file content changed, restarting from the beginning", log.KV("path", fpath), log.KV("follower", v.bname), log.KV("state", *si))
+			delete(f.prints, stid)
+			*si = 0
+		} else {
+			return
+		}
+	}
+	if rf, ok := f.takeRotated(v.bname, func(rf rotatedFile) bool {
+		return rf.Offset <= fi.Size() && rf.Print.matches(fin)
+	}); ok {
+		*si = rf.Offset
+		f.logger.Info("resuming rotated file", log.KV("path", fpath), log.KV("previous-path", rf.FilePath), log.KV("state", rf.Offset))
+		return
+	}
+	// copy-truncate rotation copies the file before truncating it, the copy starts where the original is
+	recent := time.Since(fi.ModTime()) < rotationSettle
+	for k, fl := range f.followers {
+		if k.BaseName != v.bname || k.FilePath == fpath {
+			continue
+		}
+		p, off := fl.printState()
+		if p.matches(fin) {
+			if *si = off; *si > fi.Size() {
+				*si = fi.Size()
+			}
+			f.logger.Info("file is a copy of a followed file", log.KV("path", fpath), log.KV("original", k.FilePath), log.KV("state", *si))
+			return
+		} else if recent && fi.Size() < p.Len {
+			ready = false // too short to tell yet, it could be a copy in progress
+		}
+	}
+	return
+}
+
+// nolockDeferFile notes a new file we cannot identify yet, it is retried once it stops changing
+// Caller MUST HOLD THE LOCK
+func (f *FilterManager) nolockDeferFile(fpath string) {
+	if _, ok := f.deferred[fpath]; !ok {
+		f.deferred[fpath] = time.Now()
+	}
+}
+
+// nolockRetryDeferred launches followers for deferred files that have settled
+// Caller MUST HOLD THE LOCK
+func (f *FilterManager) nolockRetryDeferred() {
+	for pth, when := range f.deferred {
+		fi, err := os.Stat(pth)
+		if err != nil {
+			delete(f.deferred, pth)
+			continue
+		} else if time.Since(fi.ModTime()) < rotationSettle || time.Since(when) < rotationSettle {
+			continue
+		}
+		delete(f.deferred, pth)
+		if _, err = f.launchFollowers(pth, false); err != nil {
+			f.logger.Warn("failed to follow file", log.KV("path", pth), log.KVErr(err))
+		}
+	}
+}
+
+// nolockQueueCompressed notes a compressed file that may hold the remainder of a rotated file
+// Caller MUST HOLD THE LOCK
+func (f *FilterManager) nolockQueueCompressed(fpath string, filterID int) {
+	if _, ok := f.pending[fpath]; !ok {
+		f.pending[fpath] = &pendingCompressed{filterID: filterID, changed: time.Now()}
+	}
+}
+
+// compressedCandidate is a settled compressed file that is checked without holding the manager lock
+type compressedCandidate struct {
+	pth     string
+	v       filter
+	changed time.Time
+}
+
+// CheckRotations reads the remainder of rotated files that were compressed once the compressed file is complete.
+// Decompression can take a while so candidates are collected under the lock and read without it.
+func (f *FilterManager) CheckRotations() error {
+	f.mtx.Lock()
+	f.nolockRetryDeferred()
+	now := time.Now()
+	var cands []compressedCandidate
+	for pth, pc := range f.pending {
+		fi, err := os.Stat(pth)
+		if err != nil || pc.filterID >= len(f.filters) {
+			delete(f.pending, pth)
+			continue
+		} else if fi.Size() != pc.size || !fi.ModTime().Equal(pc.mod) {
+			pc.size, pc.mod, pc.changed = fi.Size(), fi.ModTime(), now
+			continue
+		} else if now.Sub(pc.changed) < rotationSettle {
+			continue
+		}
+		cands = append(cands, compressedCandidate{pth: pth, v: f.filters[pc.filterID], changed: pc.changed})
+	}
+	f.mtx.Unlock()
+
+	for _, c := range cands {
+		rf, ok, err := f.matchCompressed(c.v, c.pth)
+		if err == errCompressedIncomplete {
+			continue // still being written
+		} else if err != nil {
+			f.logger.Warn("failed to check compressed file", log.KV("path", c.pth), log.KVErr(err))
+			f.dropPending(c.pth)
+			continue
+		} else if !ok {
+			if now.Sub(c.changed) > compressedGiveUp {
+				f.dropPending(c.pth)
+			}
+			continue
+		}
+		f.dropPending(c.pth)
+		if err = f.readCompressedRemainder(c.v, c.pth, rf); err != nil {
+			f.logger.Error("failed to read compressed rotated file", log.KV("path", c.pth), log.KVErr(err))
+		}
+	}
+	return nil
+}
+
+func (f *FilterManager) dropPending(pth string) {
+	f.mtx.Lock()
+	delete(f.pending, pth)
+	f.mtx.Unlock()
+}
+
+// matchCompressed finds the rotated file a compressed file came from, if any
+func (f *FilterManager) matchCompressed(v filter, pth string) (rf rotatedFile, ok bool, err error) {
+	if !f.hasRotated(v.bname) {
+		return
+	}
+	fin, err := openDeletableFile(pth)
+	if err != nil {
+		return
+	}
+	defer fin.Close()
+	rdr, err := utils.NewCompressedReader(fin)
+	if err != nil {
+		return
+	}
+	prefix := make([]byte, MaxFingerprintSize)
+	n, err := io.ReadFull(rdr, prefix)
+	utils.CloseCompressedReader(rdr)
+	if err == io.ErrUnexpectedEOF || err == io.EOF {
+		err = nil
+	} else if err != nil {
+		return
+	}
+	prefix = prefix[:n]
+	lineage := trimCompressionExt(pth)
+	match := func(rf rotatedFile) bool {
+		if rf.Print.Len >= minFingerprintMatch {
+			return rf.Print.Len <= int64(len(prefix)) && hashPrefix(prefix[:rf.Print.Len]) == rf.Print
+		}
+		return rf.FilePath == lineage // too small to fingerprint, go by name
+	}
+	// peek first so we do not consume the record for a file that is still being written
+	f.rmtx.Lock()
+	var hit bool
+	for _, r := range f.rotated {
+		if r.BaseName == v.bname && match(r) {
+			hit = true
+			break
+		}
+	}
+	f.rmtx.Unlock()
+	if !hit {
+		return
+	}
+	if _, err = fin.Seek(0, io.SeekStart); err != nil {
+		return
+	} else if rdr, err = utils.NewCompressedReader(fin); err != nil {
+		return
+	}
+	_, err = io.Copy(io.Discard, rdr)
+	utils.CloseCompressedReader(rdr)
+	if err != nil {
+		err = errCompressedIncomplete
+		return
+	}
+	rf, ok = f.takeRotated(v.bname, match)
+	return
+}
+
+// readCompressedRemainder hands everything past the rotated file offset to the filter handler
+func (f *FilterManager) readCompressedRemainder(v filter, pth string, rf rotatedFile) (err error) {
+	fin, err := openDeletableFile(pth)
+	if err != nil {
+		return
+	}
+	defer fin.Close()
+	rdr, err := utils.NewCompressedReader(fin)
+	if err != nil {
+		return
+	}
+	defer utils.CloseCompressedReader(rdr)
+	if _, err = io.CopyN(io.Discard, rdr, rf.Offset); err != nil {
+		if err == io.EOF {
+			err = nil // we already had all of it
+		}
+		return
+	}
+	sr, err := newStreamReader(rdr, v.FollowerEngineConfig)
+	if err != nil {
+		return
+	}
+	var cnt int
+	for {
+		ln, ok, eof, lerr := sr.ReadEntry()
+		if lerr != nil {
+			return lerr
+		} else if ok {
+			if err = v.lh.HandleLog(ln, time.Now(), pth); err != nil {
+				return
+			}
+			cnt++
+		} else if eof {
+			break
+		}
+	}
+	var ln []byte
+	if ln, err = sr.ReadRemaining(); err != nil {
+		return
+	} else if len(ln) > 0 {
+		if err = v.lh.HandleLog(ln, time.Now(), pth); err != nil {
+			return
+		}
+		cnt++
+	}
+	f.logger.Info("read the remainder of a compressed rotated file",
+		log.KV("path", pth), log.KV("previous-path", rf.FilePath), log.KV("follower", v.bname),
+		log.KV("state", rf.Offset), log.KV("entries", cnt))
+	return
+}
+
+// nolockRotationState collects the fingerprints of the followed files and the rotated files for the state file
+// Caller MUST HOLD THE LOCK
+func (f *FilterManager) nolockRotationState() rotationState {
+	f.nolockSyncPrints()
+	for k := range f.prints {
+		if _, ok := f.states[k]; !ok {
+			delete(f.prints, k)
+		}
+	}
+	f.rmtx.Lock()
+	defer f.rmtx.Unlock()
+	cutoff := time.Now().Add(-rotatedFileTTL)
+	rotated := f.rotated[:0]
+	for _, rf := range f.rotated {
+		if rf.When.After(cutoff) {
+			rotated = append(rotated, rf)
+		}
+	}
+	f.rotated = rotated
+	return rotationState{
+		Prints:  f.prints,
+		Rotated: append([]rotatedFile(nil), f.rotated...),
+	}
+}
+
+// nolockSyncPrints copies the fingerprints of active followers so they survive the followers
+// Caller MUST HOLD THE LOCK
+func (f *FilterManager) nolockSyncPrints() {
+	for k, fl := range f.followers {
+		if fl.rcfg.enabled() {
+			if p := fl.Fingerprint(); p.Len > 0 {
+				f.prints[k] = p
+			}
+		}
+	}
+}
+
+// retireMissing turns the state of fingerprinted files that vanished or were replaced while we were down into rotated records
+func retireMissing(states map[FileName]*int64, rs *rotationState) {
+	for k, v := range states {
+		p, ok := rs.Prints[k]
+		if !ok || v == nil || *v <= 0 {
+			continue
+		}
+		if !fileReplaced(k.FilePath, p) {
+			continue
+		}
+		rs.Rotated = append(rs.Rotated, rotatedFile{
+			FileName: FileName{BaseName: k.BaseName, FilePath: filepath.Clean(k.FilePath)},
+			Print:    p,
+			Offset:   *v,
+			When:     time.Now(),
+		})
+		delete(rs.Prints, k)
+		*v = 0 // whatever is at the path now is a new file
+	}
+}
+
+// fileReplaced checks if the file at a path is missing or no longer starts with the fingerprinted bytes.
+// This catches a new file that reused the inode of the old one.
+func fileReplaced(pth string, p fingerprint) bool {
+	fin, err := openDeletableFile(pth)
+	if err != nil {
+		return os.IsNotExist(err)
+	}
+	defer fin.Close()
+	cur, err := fingerprintFile(fin, p.Len)
+	return err == nil && cur != p
+}
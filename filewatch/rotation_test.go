@@ -0,0 +1,234 @@
+/*************************************************************************
+ * Copyright 2026 Gravwell, Inc. All rights reserved.
+ * Contact: <legal@gravwell.io>
+ *
+ * This software may be modified and distributed under the terms of the
+ * BSD 2-clause license. See the LICENSE file for details.
+ **************************************************************************/
+
+package filewatch
+
+import (
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// testLines tracks what was written so we can check that every line was handled exactly once
+type testLines struct {
+	cnt int
+	mp  map[string]bool
+}
+
+func (tl *testLines) write(t *testing.T, pth string) {
+	t.Helper()
+	cnt, mp, err := writeLines(pth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tl.mp == nil {
+		tl.mp = map[string]bool{}
+	}
+	tl.cnt += cnt
+	for k := range mp {
+		tl.mp[k] = true
+	}
+}
+
+func (tl *testLines) wait(t *testing.T, lh *safeTrackingLH, d time.Duration) {
+	t.Helper()
+	for deadline := time.Now().Add(d); time.Now().Before(deadline); time.Sleep(20 * time.Millisecond) {
+		lh.Lock()
+		cnt := lh.cnt
+		lh.Unlock()
+		if cnt >= tl.cnt {
+			break
+		}
+	}
+	lh.Lock()
+	defer lh.Unlock()
+	for k := range tl.mp {
+		if _, ok := lh.mp[k]; !ok {
+			t.Fatalf("missing line %q", k)
+		}
+	}
+	if lh.cnt != tl.cnt {
+		t.Fatalf("handled %d lines, wrote %d", lh.cnt, tl.cnt)
+	}
+}
+
+func startRotationWatcher(t *testing.T, stateFile, dir, filter string, rcfg RotationConfig, lh handler) *WatchManager {
+	t.Helper()
+	wm, err := NewWatcher(stateFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wm.SetMaxFilesWatched(64)
+	cfg := WatchConfig{
+		ConfigName:     bName,
+		BaseDir:        dir,
+		FileFilter:     filter,
+		Hnd:            lh,
+		RotationConfig: rcfg,
+	}
+	if err = wm.Add(cfg); err != nil {
+		t.Fatal(err)
+	} else if err = wm.Start(); err != nil {
+		t.Fatal(err)
+	}
+	return wm
+}
+
+func gzipFile(t *testing.T, src, dst string) {
+	t.Helper()
+	b, err := os.ReadFile(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fout, err := os.Create(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gz := gzip.NewWriter(fout)
+	if _, err = gz.Write(b); err != nil {
+		t.Fatal(err)
+	} else if err = gz.Close(); err != nil {
+		t.Fatal(err)
+	} else if err = fout.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRotationConfig(t *testing.T) {
+	for v, exp := range map[string]int{``: IdentityInode, `inode`: IdentityInode, ` Fingerprint `: IdentityFingerprint} {
+		if id, err := ParseIdentity(v); err != nil || id != exp {
+			t.Fatalf("%q: got %d %v", v, id, err)
+		}
+	}
+	if _, err := ParseIdentity(`checksum`); err == nil {
+		t.Fatal("bad identity accepted")
+	}
+	if err := (RotationConfig{Identity: 2}).Validate(); err == nil {
+		t.Fatal("bad identity accepted")
+	} else if err = (RotationConfig{FingerprintSize: MaxFingerprintSize + 1}).Validate(); err == nil {
+		t.Fatal("oversized fingerprint accepted")
+	} else if err = (RotationConfig{Identity: IdentityFingerprint, FingerprintSize: 256}).Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	b := bytes.Repeat([]byte("0123456789abcdef"), 32)
+	fp := hashPrefix(b[:256])
+	if !fp.matches(bytes.NewReader(b)) {
+		t.Fatal("prefix did not match")
+	} else if fp.matches(bytes.NewReader(b[:200])) {
+		t.Fatal("short file matched")
+	} else if hashPrefix(b[:32]).matches(bytes.NewReader(b)) {
+		t.Fatal("fingerprint below the minimum matched")
+	}
+	if fpr, err := fingerprintReader(bytes.NewReader(b[:100]), 256); err != nil || fpr != hashPrefix(b[:100]) {
+		t.Fatalf("bad short fingerprint %+v %v", fpr, err)
+	}
+	if trimCompressionExt(`/var/log/app.log.1.gz`) != `/var/log/app.log.1` || hasCompressionExt(`/var/log/app.log.1`) {
+		t.Fatal("bad compression extension handling")
+	}
+}
+
+func TestCopyTruncateFingerprint(t *testing.T) {
+	dir := t.TempDir()
+	lh := newSafeTrackingLH()
+	wm := startRotationWatcher(t, filepath.Join(t.TempDir(), `state`), dir, `app.log*`, RotationConfig{Identity: IdentityFingerprint}, lh)
+	defer wm.Close()
+
+	pth := filepath.Join(dir, `app.log`)
+	var lines testLines
+	lines.write(t, pth)
+	lines.wait(t, lh, 5*time.Second)
+
+	// copy the file the slow way, like a rotation tool would
+	b, err := os.ReadFile(pth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cp, err := os.Create(filepath.Join(dir, `app.log.1`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = cp.Write(b[:32]); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	if _, err = cp.Write(b[32:]); err != nil {
+		t.Fatal(err)
+	} else if err = cp.Close(); err != nil {
+		t.Fatal(err)
+	} else if err = os.Truncate(pth, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	lines.write(t, pth)
+	lines.wait(t, lh, 5*time.Second)
+	// give the copy time to settle and be picked up, it must not be read again
+	time.Sleep(rotationSettle + 2*rotationCheckInterval)
+	lines.wait(t, lh, 0)
+	if !wm.fman.IsWatched(filepath.Join(dir, `app.log.1`)) {
+		t.Fatal("copy is not followed")
+	}
+}
+
+func TestCompressedRotationRestart(t *testing.T) {
+	dir := t.TempDir()
+	stateFile := filepath.Join(t.TempDir(), `state`)
+	rcfg := RotationConfig{FollowCompressed: true}
+	lh := newSafeTrackingLH()
+	wm := startRotationWatcher(t, stateFile, dir, `app.log*`, rcfg, lh)
+
+	pth := filepath.Join(dir, `app.log`)
+	var lines testLines
+	lines.write(t, pth)
+	lines.wait(t, lh, 5*time.Second)
+	if err := wm.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// lines written while we were down then rotated and compressed before we came back
+	lines.write(t, pth)
+	gzipFile(t, pth, pth+`.1.gz`)
+	if err := os.Remove(pth); err != nil {
+		t.Fatal(err)
+	}
+	lines.write(t, pth)
+
+	wm = startRotationWatcher(t, stateFile, dir, `app.log*`, rcfg, lh)
+	defer wm.Close()
+	lines.wait(t, lh, rotationSettle+3*rotationCheckInterval+5*time.Second)
+	if wm.fman.IsWatched(pth + `.1.gz`) {
+		t.Fatal("compressed file is followed")
+	}
+}
+
+func TestCompressedRotationLive(t *testing.T) {
+	dir := t.TempDir()
+	lh := newSafeTrackingLH()
+	wm := startRotationWatcher(t, filepath.Join(t.TempDir(), `state`), dir, `app.log`, RotationConfig{FollowCompressed: true}, lh)
+	defer wm.Close()
+
+	pth := filepath.Join(dir, `app.log`)
+	var lines testLines
+	lines.write(t, pth)
+	lines.wait(t, lh, 5*time.Second)
+
+	// the writer keeps logging to the rotated file for a moment before it is compressed
+	if err := os.Rename(pth, pth+`.1`); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	lines.write(t, pth+`.1`)
+	gzipFile(t, pth+`.1`, pth+`.1.gz`)
+	if err := os.Remove(pth + `.1`); err != nil {
+		t.Fatal(err)
+	}
+	lines.wait(t, lh, rotationSettle+3*rotationCheckInterval+5*time.Second)
+}
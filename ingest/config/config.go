@@ -131,6 +131,7 @@ type IngestStreamConfig struct {
 
 type TimeFormat struct {
 	Format           string
+	Format_Style     string // go (default), strptime, java, or joda
 	Regex            string
 	Extraction_Regex string
 }
@@ -547,6 +548,7 @@ func (ctf CustomTimeFormat) Validate() (err error) {
 		cf := timegrinder.CustomFormat{
 			Name:             k,
 			Format:           v.Format,
+			Format_Style:     v.Format_Style,
 			Regex:            v.Regex,
 			Extraction_Regex: v.Extraction_Regex,
 		}
@@ -571,6 +573,7 @@ func (ctf CustomTimeFormat) LoadFormats(tg *timegrinder.TimeGrinder) (err error)
 		cf := timegrinder.CustomFormat{
 			Name:             k,
 			Format:           v.Format,
+			Format_Style:     v.Format_Style,
 			Regex:            v.Regex,
 			Extraction_Regex: v.Extraction_Regex,
 		}
@@ -0,0 +1,351 @@
+/*************************************************************************
+ * Copyright 2026 Gravwell, Inc. All rights reserved.
+ * Contact: <legal@gravwell.io>
+ *
+ * This software may be modified and distributed under the terms of the
+ * BSD 2-clause license. See the LICENSE file for details.
+ **************************************************************************/
+
+package processors
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+
+	"github.com/gobwas/glob"
+	"github.com/gravwell/gravwell/v3/ingest/config"
+	"github.com/gravwell/gravwell/v3/ingest/entry"
+)
+
+const (
+	BranchProcessor = `branch`
+
+	branchChainSep = `=>`
+)
+
+var (
+	ErrMissingBranchMatch = errors.New("Missing branch Match or Else specifications")
+	ErrBranchCycle        = errors.New("Branch preprocessors form a cycle")
+	ErrBranchNoConfig     = errors.New("Branch preprocessors must be built from a ProcessorConfig")
+)
+
+// BranchConfig routes entries into sub-chains of other preprocessors.
+// Match specifications are evaluated in order and take the form "condition => name, name, ...", where a condition is one of:
+//
+//	tag:<name or glob>
+//	src:<IP or CIDR>
+//	regex:<regular expression on the entry data>
+//	ev:<name>          (the enumerated value is present)
+//	ev:<name>=<value>  (the enumerated value renders as value)
+type BranchConfig struct {
+	Match []string // ordered condition and chain specifications
+	Else  string   // chain for entries that do not match any condition
+	Stop  bool     // entries that go through a chain skip the remaining preprocessors
+}
+
+type branchCond struct {
+	spec  string
+	chain []string
+	tag   glob.Glob
+	tags  map[entry.EntryTag]bool // cache of tag glob results
+	cidr  *net.IPNet
+	rxp   *regexp.Regexp
+	ev    string
+	evVal string
+	evAny bool
+}
+
+type Branch struct {
+	BranchConfig
+	tgr    Tagger
+	conds  []branchCond
+	chains [][]Processor // one chain per condition followed by the else chain
+}
+
+func BranchLoadConfig(vc *config.VariableConfig) (c BranchConfig, err error) {
+	if err = vc.MapTo(&c); err == nil {
+		_, err = c.validate()
+	}
+	return
+}
+
+func (c BranchConfig) validate() (conds []branchCond, err error) {
+	if len(c.Match) == 0 && strings.TrimSpace(c.Else) == `` {
+		return nil, ErrMissingBranchMatch
+	}
+	for _, m := range c.Match {
+		var cond branchCond
+		if cond, err = parseBranchCond(m); err != nil {
+			return
+		}
+		conds = append(conds, cond)
+	}
+	return
+}
+
+// chainNames returns every preprocessor name referenced by the branch
+func (c BranchConfig) chainNames() (r []string, err error) {
+	var conds []branchCond
+	if conds, err = c.validate(); err != nil {
+		return
+	}
+	for _, cond := range conds {
+		r = append(r, cond.chain...)
+	}
+	r = append(r, splitChain(c.Else)...)
+	return
+}
+
+func parseBranchCond(spec string) (bc branchCond, err error) {
+	bc.spec = spec
+	// split on the last separator so that regular expressions may contain it
+	idx := strings.LastIndex(spec, branchChainSep)
+	if idx < 0 {
+		err = fmt.Errorf("Branch match %q is missing a %q chain", spec, branchChainSep)
+		return
+	}
+	bc.chain = splitChain(spec[idx+len(branchChainSep):])
+	kind, val, ok := strings.Cut(strings.TrimSpace(spec[:idx]), `:`)
+	if !ok || val == `` {
+		err = fmt.Errorf("Branch match %q does not have a condition", spec)
+		return
+	}
+	switch strings.ToLower(strings.TrimSpace(kind)) {
+	case `tag`:
+		if bc.tag, err = glob.Compile(val); err != nil {
+			err = fmt.Errorf("Branch match %q has an invalid tag glob: %v", spec, err)
+		}
+		bc.tags = map[entry.EntryTag]bool{}
+	case `src`:
+		if !strings.Contains(val, `/`) {
+			if ip := net.ParseIP(val); ip == nil {
+				err = fmt.Errorf("Branch match %q has an invalid address", spec)
+				return
+			} else if ip.To4() != nil {
+				val += `/32`
+			} else {
+				val += `/128`
+			}
+		}
+		if _, bc.cidr, err = net.ParseCIDR(val); err != nil {
+			err = fmt.Errorf("Branch match %q has an invalid CIDR: %v", spec, err)
+		}
+	case `regex`:
+		if bc.rxp, err = regexp.Compile(val); err != nil {
+			err = fmt.Errorf("Branch match %q has an invalid regular expression: %v", spec, err)
+		}
+	case `ev`:
+		if bc.ev, bc.evVal, ok = strings.Cut(val, `=`); !ok {
+			bc.evAny = true
+		}
+		if bc.ev = strings.TrimSpace(bc.ev); bc.ev == `` {
+			err = fmt.Errorf("Branch match %q does not name an enumerated value", spec)
+		}
+	default:
+		err = fmt.Errorf("Branch match %q has unknown condition type %q", spec, kind)
+	}
+	return
+}
+
+func splitChain(v string) (r []string) {
+	for _, n := range strings.Split(v, `,`) {
+		if n = strings.TrimSpace(n); n != `` {
+			r = append(r, n)
+		}
+	}
+	return
+}
+
+func (bc *branchCond) match(ent *entry.Entry, tgr Tagger) bool {
+	switch {
+	case bc.tag != nil:
+		m, ok := bc.tags[ent.Tag]
+		if !ok {
+			// tags can be negotiated at any time, so resolve them as they are seen
+			if name, ok := tgr.LookupTag(ent.Tag); ok {
+				m = bc.tag.Match(name)
+				bc.tags[ent.Tag] = m
+			}
+		}
+		return m
+	case bc.cidr != nil:
+		return ent.SRC != nil && bc.cidr.Contains(ent.SRC)
+	case bc.rxp != nil:
+		return bc.rxp.Match(ent.Data)
+	case bc.ev != ``:
+		ev, ok := ent.GetEnumeratedValue(bc.ev)
+		if !ok {
+			return false
+		} else if bc.evAny {
+			return true
+		}
+		return fmt.Sprint(ev) == bc.evVal
+	}
+	return false
+}
+
+// checkBranches walks the chains referenced by a branch preprocessor looking for unknown names and cycles
+func (pc ProcessorConfig) checkBranches(name string, path []string) (err error) {
+	vc, ok := pc[name]
+	if !ok || vc == nil {
+		return fmt.Errorf("Preprocessor %v not defined", name)
+	} else if !isBranch(vc) {
+		return
+	}
+	for _, p := range path {
+		if p == name {
+			return fmt.Errorf("%w: %s", ErrBranchCycle, strings.Join(append(path, name), ` -> `))
+		}
+	}
+	var cfg BranchConfig
+	var names []string
+	if err = vc.MapTo(&cfg); err != nil {
+		return
+	} else if names, err = cfg.chainNames(); err != nil {
+		return
+	}
+	path = append(path, name)
+	for _, n := range names {
+		if _, ok := pc[n]; !ok {
+			return fmt.Errorf("Branch %s references undefined preprocessor %s", name, n)
+		} else if err = pc.checkBranches(n, path[:len(path):len(path)]); err != nil {
+			return
+		}
+	}
+	return
+}
+
+func isBranch(vc *config.VariableConfig) bool {
+	var pb preprocessorBase
+	if err := vc.MapTo(&pb); err != nil {
+		return false
+	}
+	return strings.TrimSpace(strings.ToLower(pb.Type)) == BranchProcessor
+}
+
+// newBranch builds a branch and a fresh instance of every preprocessor in its chains
+func (pc ProcessorConfig) newBranch(name string, tgr Tagger) (b *Branch, err error) {
+	var cfg BranchConfig
+	if err = pc.checkBranches(name, nil); err != nil {
+		return
+	} else if cfg, err = BranchLoadConfig(pc[name]); err != nil {
+		return
+	}
+	b = &Branch{
+		BranchConfig: cfg,
+		tgr:          tgr,
+	}
+	if b.conds, err = cfg.validate(); err != nil {
+		return nil, err
+	}
+	specs := make([][]string, 0, len(b.conds)+1)
+	for _, cond := range b.conds {
+		specs = append(specs, cond.chain)
+	}
+	specs = append(specs, splitChain(cfg.Else))
+	for _, names := range specs {
+		var chain []Processor
+		for _, n := range names {
+			var p Processor
+			if p, err = pc.getProcessor(n, tgr); err != nil {
+				err = fmt.Errorf("%s %v", n, err)
+				b.chains = append(b.chains, chain)
+				b.Close()
+				return nil, err
+			}
+			chain = append(chain, p)
+		}
+		b.chains = append(b.chains, chain)
+	}
+	return
+}
+
+// route returns the index of the chain an entry takes, -1 means the entry passes through untouched
+func (b *Branch) route(ent *entry.Entry) int {
+	for i := range b.conds {
+		if b.conds[i].match(ent, b.tgr) {
+			return i
+		}
+	}
+	if len(b.chains[len(b.conds)]) > 0 {
+		return len(b.conds)
+	}
+	return -1
+}
+
+func (b *Branch) Process(ents []*entry.Entry) (rset []*entry.Entry, err error) {
+	var stopped []*entry.Entry
+	if rset, stopped, err = b.processStop(ents); err == nil {
+		rset = append(rset, stopped...)
+	}
+	return
+}
+
+// processStop runs entries through their chains, entries that should skip the rest of the parent chain are returned in stopped
+func (b *Branch) processStop(ents []*entry.Entry) (rset, stopped []*entry.Entry, err error) {
+	if len(ents) == 0 {
+		return
+	}
+	routes := make([]int, len(ents))
+	for i, ent := range ents {
+		if ent == nil {
+			routes[i] = -2
+		} else {
+			routes[i] = b.route(ent)
+		}
+	}
+	// consecutive entries that take the same chain are handed over as one block which keeps entries in order
+	for start := 0; start < len(ents); {
+		end := start + 1
+		for end < len(ents) && routes[end] == routes[start] {
+			end++
+		}
+		blk := ents[start:end:end]
+		switch idx := routes[start]; idx {
+		case -2:
+		case -1:
+			rset = append(rset, blk...)
+		default:
+			var set, stp []*entry.Entry
+			if set, stp, err = runChain(b.chains[idx], blk); err != nil {
+				return
+			}
+			stopped = append(stopped, stp...)
+			if b.Stop {
+				stopped = append(stopped, set...)
+			} else {
+				rset = append(rset, set...)
+			}
+		}
+		start = end
+	}
+	return
+}
+
+func (b *Branch) Flush() (r []*entry.Entry) {
+	for _, chain := range b.chains {
+		for i, p := range chain {
+			if ents := p.Flush(); len(ents) > 0 {
+				if set, stopped, err := runChain(chain[i+1:], ents); err == nil {
+					r = append(r, set...)
+					r = append(r, stopped...)
+				}
+			}
+		}
+	}
+	return
+}
+
+func (b *Branch) Close() (err error) {
+	for _, chain := range b.chains {
+		for _, p := range chain {
+			if lerr := p.Close(); lerr != nil {
+				err = addError(lerr, err)
+			}
+		}
+	}
+	return
+}
@@ -0,0 +1,143 @@
+/*************************************************************************
+ * Copyright 2026 Gravwell, Inc. All rights reserved.
+ * Contact: <legal@gravwell.io>
+ *
+ * This software may be modified and distributed under the terms of the
+ * BSD 2-clause license. See the LICENSE file for details.
+ **************************************************************************/
+
+package processors
+
+import (
+	"errors"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/gravwell/gravwell/v3/ingest/config"
+	"github.com/gravwell/gravwell/v3/ingest/entry"
+)
+
+const branchTestConfig = `
+[preprocessor "br"]
+	type = branch
+	Match = "tag:app* => upA, tail"
+	Match = "src:10.0.0.0/8 => upB"
+	Match = "regex:^drop => dr"
+	Match = "ev:kind=special => upC"
+	Else = upE
+	Stop = %STOP%
+
+[preprocessor "upA"]
+	type = regexreplace
+	Regex = "x"
+	Replacement = "A"
+
+[preprocessor "upB"]
+	type = regexreplace
+	Regex = "x"
+	Replacement = "B"
+
+[preprocessor "upC"]
+	type = regexreplace
+	Regex = "x"
+	Replacement = "C"
+
+[preprocessor "upE"]
+	type = regexreplace
+	Regex = "x"
+	Replacement = "E"
+
+[preprocessor "dr"]
+	type = drop
+
+[preprocessor "tail"]
+	type = regexreplace
+	Regex = "^"
+	Replacement = "T-"
+`
+
+type branchTestWriter struct {
+	testWriter
+	testTagger
+}
+
+func branchTestEntries(tgr Tagger) []*entry.Entry {
+	app, _ := tgr.NegotiateTag(`apple`)
+	other, _ := tgr.NegotiateTag(`other`)
+	ents := []*entry.Entry{
+		{Tag: app, Data: []byte(`x`)},
+		{Tag: other, SRC: net.ParseIP(`10.1.2.3`), Data: []byte(`x`)},
+		{Tag: other, Data: []byte(`drop me`)},
+		{Tag: other, SRC: net.ParseIP(`192.168.1.1`), Data: []byte(`x`)},
+		{Tag: other, Data: []byte(`x`)},
+	}
+	ents[3].AddEnumeratedValueEx(`kind`, `special`)
+	return ents
+}
+
+func TestBranch(t *testing.T) {
+	tests := []struct {
+		stop string
+		exp  string
+	}{
+		{`false`, `T-T-A|T-B|T-C|T-E`},
+		{`true`, `T-A|B|C|E`},
+	}
+	for _, tt := range tests {
+		var tc testConfigStruct
+		b := []byte(strings.ReplaceAll(branchTestConfig, `%STOP%`, tt.stop))
+		if err := config.LoadConfigBytes(&tc, b); err != nil {
+			t.Fatal(err)
+		} else if err = tc.Preprocessor.Validate(); err != nil {
+			t.Fatal(err)
+		}
+		var tw branchTestWriter
+		ps, err := tc.Preprocessor.ProcessorSet(&tw, []string{`br`, `tail`})
+		if err != nil {
+			t.Fatal(err)
+		} else if err = ps.ProcessBatch(branchTestEntries(&tw)); err != nil {
+			t.Fatal(err)
+		} else if err = ps.Close(); err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, ent := range tw.ents {
+			got = append(got, string(ent.Data))
+		}
+		if strings.Join(got, `|`) != tt.exp {
+			t.Fatalf("stop %s: got %q expected %q", tt.stop, strings.Join(got, `|`), tt.exp)
+		}
+	}
+}
+
+func TestBranchConfig(t *testing.T) {
+	tests := []struct {
+		cfg   string
+		cycle bool
+		pass  bool
+	}{
+		{"[preprocessor \"a\"]\ntype=branch\nMatch=\"tag:foo => d\"\n[preprocessor \"d\"]\ntype=drop\n", false, true},
+		{"[preprocessor \"a\"]\ntype=branch\nElse=\"d, d\"\n[preprocessor \"d\"]\ntype=drop\n", false, true},
+		{"[preprocessor \"a\"]\ntype=branch\nMatch=\"tag:foo => missing\"\n", false, false},
+		{"[preprocessor \"a\"]\ntype=branch\nMatch=\"tag:foo => a\"\n", true, false},
+		{"[preprocessor \"a\"]\ntype=branch\nMatch=\"tag:foo => b\"\n[preprocessor \"b\"]\ntype=branch\nElse=a\n", true, false},
+		{"[preprocessor \"a\"]\ntype=branch\n", false, false},
+		{"[preprocessor \"a\"]\ntype=branch\nMatch=\"tag:foo\"\n", false, false},
+		{"[preprocessor \"a\"]\ntype=branch\nMatch=\"bogus:foo => d\"\n[preprocessor \"d\"]\ntype=drop\n", false, false},
+		{"[preprocessor \"a\"]\ntype=branch\nMatch=\"src:10.0.0.300 => d\"\n[preprocessor \"d\"]\ntype=drop\n", false, false},
+		{"[preprocessor \"a\"]\ntype=branch\nMatch=\"regex:[ => d\"\n[preprocessor \"d\"]\ntype=drop\n", false, false},
+	}
+	for i, tt := range tests {
+		var tc testConfigStruct
+		if err := config.LoadConfigBytes(&tc, []byte(tt.cfg)); err != nil {
+			t.Fatal(err)
+		}
+		err := tc.Preprocessor.CheckConfig(`a`)
+		if (err == nil) != tt.pass {
+			t.Fatalf("test %d: got %v, expected pass %v", i, err, tt.pass)
+		} else if errors.Is(err, ErrBranchCycle) != tt.cycle {
+			t.Fatalf("test %d: bad cycle detection %v", i, err)
+		}
+	}
+}
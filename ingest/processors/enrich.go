@@ -0,0 +1,526 @@
+/*************************************************************************
+ * Copyright 2026 Gravwell, Inc. All rights reserved.
+ * Contact: <legal@gravwell.io>
+ *
+ * This software may be modified and distributed under the terms of the
+ * BSD 2-clause license. See the LICENSE file for details.
+ **************************************************************************/
+
+package processors
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/asergeyev/nradix"
+	"github.com/gravwell/gravwell/v3/ingest/config"
+	"github.com/gravwell/gravwell/v3/ingest/entry"
+	"github.com/gravwell/jsonparser"
+	"github.com/oschwald/maxminddb-golang"
+)
+
+const (
+	EnrichProcessor = `enrich`
+
+	enrichFormatCSV  = `csv`
+	enrichFormatJSON = `json`
+	enrichFormatCIDR = `cidr`
+	enrichFormatMMDB = `mmdb`
+
+	enrichKeySrc   = `src`
+	enrichKeyRegex = `regex`
+	enrichKeyJSON  = `json`
+	enrichKeyEV    = `ev`
+
+	defaultEnrichReload  = 10 * time.Second
+	defaultEnrichJSONKey = `key`
+)
+
+var (
+	ErrMissingEnrichTable = errors.New("Missing enrich Table-Path")
+	ErrMissingEnrichField = errors.New("mmdb tables require at least one Field")
+)
+
+// EnrichConfig joins entries against a lookup table and attaches matching values as enumerated values.
+// Keys are taken from the entry using Key, which is one of:
+//
+//	src                 the entry source address (default)
+//	regex:<expression>  the first capture group, or the whole match if there are no groups
+//	json:<path>         a dotted path into the entry data
+//	ev:<name>           an enumerated value
+type EnrichConfig struct {
+	Table_Path      string   // path to the lookup table
+	Table_Format    string   // csv, json, cidr, or mmdb, guessed from the file extension when empty
+	Key             string   // where to find the key in each entry
+	Key_Column      string   // column or field holding the key, defaults to the first CSV column or "key"
+	Columns         []string // columns to attach, defaults to every column except the key
+	Field           []string // mmdb only, name=path into the record, e.g. country=country.iso_code
+	EV_Prefix       string   // prefix added to the name of every attached enumerated value
+	Reload_Interval string   // how often to check the table for changes, defaults to 10s
+	format          string
+	keyType         string
+	keyRxp          *regexp.Regexp
+	keyPath         []string
+	keyEV           string
+	fields          []mmdbField
+	reload          time.Duration
+}
+
+type mmdbField struct {
+	name string
+	path []string
+}
+
+func EnrichLoadConfig(vc *config.VariableConfig) (c EnrichConfig, err error) {
+	if err = vc.MapTo(&c); err == nil {
+		err = c.validate()
+	}
+	return
+}
+
+func (c *EnrichConfig) validate() (err error) {
+	if c.Table_Path == `` {
+		return ErrMissingEnrichTable
+	} else if _, err = os.Stat(c.Table_Path); err != nil {
+		return
+	}
+	if c.format = strings.ToLower(strings.TrimSpace(c.Table_Format)); c.format == `` {
+		c.format = strings.TrimPrefix(strings.ToLower(filepath.Ext(c.Table_Path)), `.`)
+	}
+	switch c.format {
+	case enrichFormatCSV, enrichFormatJSON, enrichFormatCIDR:
+	case enrichFormatMMDB:
+		if len(c.Field) == 0 {
+			return ErrMissingEnrichField
+		}
+	default:
+		return fmt.Errorf("Unknown enrich Table-Format %q", c.format)
+	}
+
+	c.fields = nil
+	for _, f := range c.Field {
+		name, pth, ok := strings.Cut(f, `=`)
+		if name, pth = strings.TrimSpace(name), strings.TrimSpace(pth); !ok || name == `` || pth == `` {
+			return fmt.Errorf("Invalid Field %q, expected name=path", f)
+		}
+		c.fields = append(c.fields, mmdbField{name: name, path: strings.Split(pth, `.`)})
+	}
+
+	kind, val, _ := strings.Cut(strings.TrimSpace(c.Key), `:`)
+	switch c.keyType = strings.ToLower(strings.TrimSpace(kind)); c.keyType {
+	case ``:
+		c.keyType = enrichKeySrc
+	case enrichKeySrc:
+	case enrichKeyRegex:
+		if c.keyRxp, err = regexp.Compile(val); err != nil {
+			return fmt.Errorf("Invalid Key regular expression: %v", err)
+		}
+	case enrichKeyJSON:
+		if c.keyPath = unquoteFields(splitRespectQuotes(val, dotSplitter)); len(c.keyPath) == 0 {
+			return fmt.Errorf("Key %q is missing a JSON path", c.Key)
+		}
+	case enrichKeyEV:
+		if c.keyEV = strings.TrimSpace(val); c.keyEV == `` {
+			return fmt.Errorf("Key %q is missing an enumerated value name", c.Key)
+		}
+	default:
+		return fmt.Errorf("Unknown Key type %q", kind)
+	}
+
+	c.reload = defaultEnrichReload
+	if c.Reload_Interval != `` {
+		if c.reload, err = time.ParseDuration(c.Reload_Interval); err != nil {
+			return fmt.Errorf("Invalid Reload-Interval %q: %v", c.Reload_Interval, err)
+		} else if c.reload <= 0 {
+			return fmt.Errorf("Invalid Reload-Interval %q", c.Reload_Interval)
+		}
+	}
+	return
+}
+
+// enrichTable returns the enumerated values for a key, ip is only set when the key is an address
+type enrichTable interface {
+	lookup(key []byte, ip net.IP) []entry.EnumeratedValue
+}
+
+type Enrich struct {
+	EnrichConfig
+	sync.RWMutex
+	tbl   enrichTable
+	mod   time.Time
+	size  int64
+	done  chan struct{}
+	wg    sync.WaitGroup
+	close sync.Once
+}
+
+func NewEnrichProcessor(cfg EnrichConfig) (*Enrich, error) {
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+	e := &Enrich{
+		EnrichConfig: cfg,
+		done:         make(chan struct{}),
+	}
+	if _, err := e.load(); err != nil {
+		return nil, err
+	}
+	e.wg.Add(1)
+	go e.watch()
+	return e, nil
+}
+
+func (e *Enrich) Close() error {
+	e.close.Do(func() {
+		close(e.done)
+	})
+	e.wg.Wait()
+	return nil
+}
+
+func (e *Enrich) Flush() []*entry.Entry {
+	return nil
+}
+
+// watch reloads the table whenever its modification time or size changes, a table that fails to load leaves the old one in place
+func (e *Enrich) watch() {
+	defer e.wg.Done()
+	tckr := time.NewTicker(e.reload)
+	defer tckr.Stop()
+	for {
+		select {
+		case <-e.done:
+			return
+		case <-tckr.C:
+			e.load()
+		}
+	}
+}
+
+// load reads the table if it has changed since the last load
+func (e *Enrich) load() (loaded bool, err error) {
+	var fi os.FileInfo
+	if fi, err = os.Stat(e.Table_Path); err != nil {
+		return
+	}
+	e.RLock()
+	same := e.tbl != nil && fi.ModTime().Equal(e.mod) && fi.Size() == e.size
+	e.RUnlock()
+	if same {
+		return
+	}
+	var tbl enrichTable
+	if tbl, err = e.loadTable(); err != nil {
+		return
+	}
+	e.Lock()
+	e.tbl, e.mod, e.size = tbl, fi.ModTime(), fi.Size()
+	e.Unlock()
+	loaded = true
+	return
+}
+
+func (e *Enrich) loadTable() (enrichTable, error) {
+	b, err := os.ReadFile(e.Table_Path)
+	if err != nil {
+		return nil, err
+	}
+	switch e.format {
+	case enrichFormatCSV:
+		return e.loadCSV(b, false)
+	case enrichFormatCIDR:
+		return e.loadCSV(b, true)
+	case enrichFormatJSON:
+		return e.loadJSON(b)
+	case enrichFormatMMDB:
+		var mt mmdbTable
+		if mt.rdr, err = maxminddb.FromBytes(b); err != nil {
+			return nil, err
+		}
+		mt.fields, mt.prefix = e.fields, e.EV_Prefix
+		return &mt, nil
+	}
+	return nil, fmt.Errorf("Unknown enrich Table-Format %q", e.format)
+}
+
+func (e *Enrich) Process(ents []*entry.Entry) ([]*entry.Entry, error) {
+	e.RLock()
+	tbl := e.tbl
+	e.RUnlock()
+	for _, ent := range ents {
+		if ent == nil {
+			continue
+		}
+		if key, ip, ok := e.key(ent); ok {
+			if evs := tbl.lookup(key, ip); len(evs) > 0 {
+				ent.AddEnumeratedValues(evs)
+			}
+		}
+	}
+	return ents, nil
+}
+
+func (e *Enrich) key(ent *entry.Entry) (key []byte, ip net.IP, ok bool) {
+	switch e.keyType {
+	case enrichKeySrc:
+		if ip = ent.SRC; ip != nil {
+			key, ok = []byte(ip.String()), true
+		}
+	case enrichKeyRegex:
+		if m := e.keyRxp.FindSubmatch(ent.Data); len(m) > 1 {
+			key, ok = m[1], true
+		} else if len(m) == 1 {
+			key, ok = m[0], true
+		}
+	case enrichKeyJSON:
+		var err error
+		if key, _, _, err = jsonparser.Get(ent.Data, e.keyPath...); err == nil {
+			ok = true
+		}
+	case enrichKeyEV:
+		var v interface{}
+		if v, ok = ent.GetEnumeratedValue(e.keyEV); ok {
+			if vip, isip := v.(net.IP); isip {
+				ip = vip
+			}
+			key = []byte(fmt.Sprint(v))
+		}
+	}
+	if ok && ip == nil && (e.format == enrichFormatCIDR || e.format == enrichFormatMMDB) {
+		// address tables need an address, keys pulled from data are parsed
+		if ip = net.ParseIP(string(bytes.TrimSpace(key))); ip == nil {
+			ok = false
+		}
+	}
+	return
+}
+
+// kvTable is an exact match table loaded from CSV or JSON
+type kvTable map[string][]entry.EnumeratedValue
+
+func (kt kvTable) lookup(key []byte, ip net.IP) []entry.EnumeratedValue {
+	return kt[string(key)]
+}
+
+// cidrTable is a longest prefix match table for IPv4 and IPv6 networks.
+// It uses nradix rather than ipexist because each network carries values and ipexist only answers IPv4 membership.
+type cidrTable struct {
+	tree *nradix.Tree
+}
+
+func (ct cidrTable) lookup(key []byte, ip net.IP) []entry.EnumeratedValue {
+	if ip == nil {
+		return nil
+	}
+	v, err := ct.tree.FindCIDR(ip.String())
+	if err != nil || v == nil {
+		return nil
+	}
+	evs, _ := v.([]entry.EnumeratedValue)
+	return evs
+}
+
+type mmdbTable struct {
+	rdr    *maxminddb.Reader
+	fields []mmdbField
+	prefix string
+}
+
+func (mt *mmdbTable) lookup(key []byte, ip net.IP) (evs []entry.EnumeratedValue) {
+	var rec map[string]interface{}
+	if ip == nil || mt.rdr.Lookup(ip, &rec) != nil || rec == nil {
+		return
+	}
+	for _, f := range mt.fields {
+		var v interface{} = rec
+		for _, k := range f.path {
+			mp, ok := v.(map[string]interface{})
+			if !ok {
+				v = nil
+				break
+			}
+			v = mp[k]
+		}
+		if v != nil {
+			evs = append(evs, enrichEV(mt.prefix+f.name, v))
+		}
+	}
+	return
+}
+
+// loadCSV loads a table with a header row, CIDR tables hold networks or addresses in the key column
+func (e *Enrich) loadCSV(b []byte, cidr bool) (enrichTable, error) {
+	rdr := csv.NewReader(bytes.NewReader(b))
+	rdr.TrimLeadingSpace = true
+	rdr.ReuseRecord = false
+	hdr, err := rdr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read table header: %v", err)
+	}
+	keyIdx, cols, err := e.columns(hdr)
+	if err != nil {
+		return nil, err
+	}
+	kt := kvTable{}
+	var ct cidrTable
+	if cidr {
+		ct.tree = nradix.NewTree(64)
+	}
+	for {
+		rec, err := rdr.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		var evs []entry.EnumeratedValue
+		for _, i := range cols {
+			if i < len(rec) {
+				evs = append(evs, enrichEV(e.EV_Prefix+hdr[i], rec[i]))
+			}
+		}
+		key := strings.TrimSpace(rec[keyIdx])
+		if !cidr {
+			kt[key] = evs
+			continue
+		}
+		if !strings.Contains(key, `/`) {
+			if ip := net.ParseIP(key); ip == nil {
+				return nil, fmt.Errorf("Invalid network %q", key)
+			} else if ip.To4() != nil {
+				key += `/32`
+			} else {
+				key += `/128`
+			}
+		}
+		// later rows win, matching how exact tables behave
+		if err = ct.tree.SetCIDR(key, evs); err != nil {
+			return nil, fmt.Errorf("Invalid network %q: %v", key, err)
+		}
+	}
+	if cidr {
+		return ct, nil
+	}
+	return kt, nil
+}
+
+// columns resolves the key column and the columns to attach
+func (e *Enrich) columns(hdr []string) (keyIdx int, cols []int, err error) {
+	idx := make(map[string]int, len(hdr))
+	for i, h := range hdr {
+		hdr[i] = strings.TrimSpace(h)
+		idx[hdr[i]] = i
+	}
+	if e.Key_Column != `` {
+		var ok bool
+		if keyIdx, ok = idx[e.Key_Column]; !ok {
+			err = fmt.Errorf("Key-Column %q is not in the table", e.Key_Column)
+			return
+		}
+	}
+	if len(e.Columns) == 0 {
+		for i := range hdr {
+			if i != keyIdx {
+				cols = append(cols, i)
+			}
+		}
+		return
+	}
+	for _, c := range e.Columns {
+		i, ok := idx[c]
+		if !ok {
+			err = fmt.Errorf("Column %q is not in the table", c)
+			return
+		}
+		cols = append(cols, i)
+	}
+	return
+}
+
+// loadJSON accepts an object of keys to records or an array of records that contain the key
+func (e *Enrich) loadJSON(b []byte) (enrichTable, error) {
+	var v interface{}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	keyName := e.Key_Column
+	if keyName == `` {
+		keyName = defaultEnrichJSONKey
+	}
+	kt := kvTable{}
+	add := func(key string, rec map[string]interface{}) {
+		var evs []entry.EnumeratedValue
+		if len(e.Columns) > 0 {
+			for _, c := range e.Columns {
+				if cv, ok := rec[c]; ok && cv != nil {
+					evs = append(evs, enrichEV(e.EV_Prefix+c, cv))
+				}
+			}
+		} else {
+			for k, cv := range rec {
+				if k != keyName && cv != nil {
+					evs = append(evs, enrichEV(e.EV_Prefix+k, cv))
+				}
+			}
+		}
+		kt[key] = evs
+	}
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for k, rv := range t {
+			if rec, ok := rv.(map[string]interface{}); ok {
+				add(k, rec)
+			}
+		}
+	case []interface{}:
+		for i, rv := range t {
+			rec, ok := rv.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("Table record %d is not an object", i)
+			} else if kv, ok := rec[keyName]; !ok || kv == nil {
+				return nil, fmt.Errorf("Table record %d is missing key %q", i, keyName)
+			} else {
+				add(fmt.Sprint(kv), rec)
+			}
+		}
+	default:
+		return nil, errors.New("JSON tables must be an object or an array of objects")
+	}
+	return kt, nil
+}
+
+// enrichEV converts a table value into an enumerated value, values without a native type are attached as JSON
+func enrichEV(name string, v interface{}) entry.EnumeratedValue {
+	switch t := v.(type) {
+	case json.Number:
+		if i, err := t.Int64(); err == nil {
+			v = i
+		} else if f, err := t.Float64(); err == nil {
+			v = f
+		} else {
+			v = t.String()
+		}
+	case map[string]interface{}, []interface{}:
+		if b, err := json.Marshal(t); err == nil {
+			v = string(b)
+		}
+	}
+	ed, err := entry.InferEnumeratedData(v)
+	if err != nil {
+		ed = entry.StringEnumData(fmt.Sprint(v))
+	}
+	return entry.EnumeratedValue{Name: name, Value: ed}
+}
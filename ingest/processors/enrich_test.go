@@ -0,0 +1,225 @@
+/*************************************************************************
+ * Copyright 2026 Gravwell, Inc. All rights reserved.
+ * Contact: <legal@gravwell.io>
+ *
+ * This software may be modified and distributed under the terms of the
+ * BSD 2-clause license. See the LICENSE file for details.
+ **************************************************************************/
+
+package processors
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gravwell/gravwell/v3/ingest/config"
+	"github.com/gravwell/gravwell/v3/ingest/entry"
+)
+
+func writeEnrichTable(t *testing.T, name, content string) string {
+	t.Helper()
+	pth := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(pth, []byte(content), 0640); err != nil {
+		t.Fatal(err)
+	}
+	return pth
+}
+
+func checkEV(t *testing.T, ent *entry.Entry, name string, exp interface{}) {
+	t.Helper()
+	if v, ok := ent.GetEnumeratedValue(name); exp == nil && ok {
+		t.Fatalf("unexpected EV %s = %v", name, v)
+	} else if exp != nil && (!ok || v != exp) {
+		t.Fatalf("bad EV %s: %v (%T) != %v", name, v, v, exp)
+	}
+}
+
+func TestEnrichConfig(t *testing.T) {
+	csvPth := writeEnrichTable(t, `t.csv`, "host,owner\na,b\n")
+	tests := []struct {
+		cfg  string
+		pass bool
+	}{
+		{`Table-Path=` + csvPth, true},
+		{`Table-Path=` + csvPth + "\nKey=\"regex:host=(\\\\S+)\"", true},
+		{`Table-Path=` + csvPth + "\nKey=json:a.b\nReload-Interval=1m", true},
+		{`Table-Path=` + csvPth + "\nKey=ev:host", true},
+		{``, false},
+		{`Table-Path=/no/such/table.csv`, false},
+		{`Table-Path=` + csvPth + "\nTable-Format=xml", false},
+		{`Table-Path=` + csvPth + "\nTable-Format=mmdb", false},
+		{`Table-Path=` + csvPth + "\nKey=\"regex:(\"", false},
+		{`Table-Path=` + csvPth + "\nKey=bogus", false},
+		{`Table-Path=` + csvPth + "\nReload-Interval=never", false},
+	}
+	for i, tt := range tests {
+		b := []byte("[preprocessor \"e\"]\n\ttype = enrich\n" + tt.cfg + "\n")
+		var tc testConfigStruct
+		if err := config.LoadConfigBytes(&tc, b); err != nil {
+			t.Fatal(err)
+		} else if err = tc.Preprocessor.CheckConfig(`e`); (err == nil) != tt.pass {
+			t.Fatalf("test %d: got %v, expected pass %v", i, err, tt.pass)
+		}
+	}
+}
+
+func TestEnrichCSV(t *testing.T) {
+	pth := writeEnrichTable(t, `users.csv`, "user,dept,owner\nalice,eng,bob\ncarol,ops,dave\n")
+	e, err := NewEnrichProcessor(EnrichConfig{
+		Table_Path:      pth,
+		Key:             `regex:user=(\S+)`,
+		Columns:         []string{`dept`},
+		EV_Prefix:       `user_`,
+		Reload_Interval: `10ms`,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer e.Close()
+	ents := []*entry.Entry{
+		{Data: []byte(`login user=alice`)},
+		{Data: []byte(`login user=mallory`)},
+		{Data: []byte(`no user here`)},
+	}
+	if _, err = e.Process(ents); err != nil {
+		t.Fatal(err)
+	}
+	checkEV(t, ents[0], `user_dept`, `eng`)
+	checkEV(t, ents[0], `user_owner`, nil)
+	checkEV(t, ents[1], `user_dept`, nil)
+	checkEV(t, ents[2], `user_dept`, nil)
+
+	// rewrite the table and wait for the reload
+	if err = os.WriteFile(pth, []byte("user,dept,owner\nalice,sales,bob\nmallory,evil,eve\n"), 0640); err != nil {
+		t.Fatal(err)
+	}
+	future := time.Now().Add(time.Hour)
+	os.Chtimes(pth, future, future)
+	for deadline := time.Now().Add(5 * time.Second); ; {
+		ents = []*entry.Entry{{Data: []byte(`user=mallory`)}}
+		if e.Process(ents); ents[0].EVB.Count() > 0 {
+			break
+		} else if time.Now().After(deadline) {
+			t.Fatal("table was not reloaded")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	checkEV(t, ents[0], `user_dept`, `evil`)
+}
+
+func TestEnrichJSON(t *testing.T) {
+	pth := writeEnrichTable(t, `hosts.json`, `[
+		{"key": "web01", "rack": 12, "weight": 1.5, "tags": ["a", "b"], "prod": true},
+		{"key": "db01", "rack": 3}
+	]`)
+	e, err := NewEnrichProcessor(EnrichConfig{Table_Path: pth, Key: `json:host.name`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer e.Close()
+	ents := []*entry.Entry{
+		{Data: []byte(`{"host": {"name": "web01"}}`)},
+		{Data: []byte(`{"host": {"name": "db01"}}`)},
+	}
+	if _, err = e.Process(ents); err != nil {
+		t.Fatal(err)
+	}
+	checkEV(t, ents[0], `rack`, int64(12))
+	checkEV(t, ents[0], `weight`, float64(1.5))
+	checkEV(t, ents[0], `tags`, `["a","b"]`)
+	checkEV(t, ents[0], `prod`, true)
+	checkEV(t, ents[1], `rack`, int64(3))
+	checkEV(t, ents[1], `prod`, nil)
+}
+
+func TestEnrichCIDR(t *testing.T) {
+	pth := writeEnrichTable(t, `nets.txt`, "network,zone\n10.0.0.0/8,internal\n10.1.0.0/16,lab\n2001:db8::/32,v6lab\n192.168.1.1,gateway\n")
+	e, err := NewEnrichProcessor(EnrichConfig{Table_Path: pth, Table_Format: `cidr`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer e.Close()
+	exp := map[string]interface{}{
+		`10.2.3.4`:    `internal`,
+		`10.1.3.4`:    `lab`,
+		`2001:db8::1`: `v6lab`,
+		`192.168.1.1`: `gateway`,
+		`192.168.1.2`: nil,
+		`2001:db9::1`: nil,
+	}
+	for src, zone := range exp {
+		ent := &entry.Entry{SRC: net.ParseIP(src)}
+		if _, err = e.Process([]*entry.Entry{ent}); err != nil {
+			t.Fatal(err)
+		}
+		checkEV(t, ent, `zone`, zone)
+	}
+}
+
+// buildTestMMDB encodes a minimal IPv4 MaxMind database with a single record for 10.0.0.0/8
+func buildTestMMDB() []byte {
+	const nodes = 8
+	var b []byte
+	// search tree with 24 bit records, one node per prefix bit, misses point at the node count
+	for i := 0; i < nodes; i++ {
+		bit := (10 >> (7 - i)) & 1
+		next := uint32(i + 1)
+		if i == nodes-1 {
+			next = nodes + 16 // pointer to the start of the data section
+		}
+		recs := [2]uint32{nodes, nodes}
+		recs[bit] = next
+		for _, r := range recs {
+			b = append(b, byte(r>>16), byte(r>>8), byte(r))
+		}
+	}
+	b = append(b, make([]byte, 16)...)
+	str := func(s string) []byte {
+		return append([]byte{0x40 | byte(len(s))}, s...)
+	}
+	// {"country": {"iso_code": "ZZ"}, "asn": uint32(64512)}
+	b = append(b, 0xe2)
+	b = append(b, str(`country`)...)
+	b = append(b, 0xe1)
+	b = append(b, str(`iso_code`)...)
+	b = append(b, str(`ZZ`)...)
+	b = append(b, str(`asn`)...)
+	b = append(b, 0xc2, 0xfc, 0x00)
+
+	b = append(b, "\xab\xcd\xefMaxMind.com"...)
+	b = append(b, 0xe7)
+	b = append(append(b, str(`node_count`)...), 0xc1, nodes)
+	b = append(append(b, str(`record_size`)...), 0xa1, 24)
+	b = append(append(b, str(`ip_version`)...), 0xa1, 4)
+	b = append(append(b, str(`binary_format_major_version`)...), 0xa1, 2)
+	b = append(append(b, str(`binary_format_minor_version`)...), 0xa0)
+	b = append(append(b, str(`database_type`)...), str(`Test`)...)
+	b = append(append(b, str(`build_epoch`)...), 0x01, 0x02, 0x01)
+	return b
+}
+
+func TestEnrichMMDB(t *testing.T) {
+	pth := writeEnrichTable(t, `geo.mmdb`, string(buildTestMMDB()))
+	e, err := NewEnrichProcessor(EnrichConfig{
+		Table_Path: pth,
+		Key:        `ev:addr`,
+		Field:      []string{`cc=country.iso_code`, `asn=asn`, `none=country.missing`},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer e.Close()
+	ents := []*entry.Entry{{}, {}}
+	ents[0].AddEnumeratedValueEx(`addr`, net.ParseIP(`10.9.8.7`).To4())
+	ents[1].AddEnumeratedValueEx(`addr`, `11.0.0.1`)
+	if _, err = e.Process(ents); err != nil {
+		t.Fatal(err)
+	}
+	checkEV(t, ents[0], `cc`, `ZZ`)
+	checkEV(t, ents[0], `asn`, uint64(64512))
+	checkEV(t, ents[0], `none`, nil)
+	checkEV(t, ents[1], `cc`, nil)
+}
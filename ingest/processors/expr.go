@@ -0,0 +1,270 @@
+/*************************************************************************
+ * Copyright 2026 Gravwell, Inc. All rights reserved.
+ * Contact: <legal@gravwell.io>
+ *
+ * This software may be modified and distributed under the terms of the
+ * BSD 2-clause license. See the LICENSE file for details.
+ **************************************************************************/
+
+package processors
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/expr-lang/expr"
+	"github.com/expr-lang/expr/ast"
+	"github.com/expr-lang/expr/vm"
+	"github.com/gravwell/gravwell/v3/ingest/config"
+	"github.com/gravwell/gravwell/v3/ingest/entry"
+)
+
+const (
+	ExprProcessor = `expr`
+
+	maxExprCIDRCache = 1024
+)
+
+var (
+	ErrMissingExprAction = errors.New("Missing expr action, at least one of Drop, Route, Set-EV, or Rewrite is required")
+)
+
+// ExprConfig applies expressions to every entry. Expressions can reference
+// TAG (tag name), SRC (source address string), TS (time.Time), DATA (string),
+// EV (enumerated values by name), and JSON (the data parsed as a JSON object).
+// Fields are only populated when an expression uses them, so JSON is only parsed when needed.
+// The cidr(addr, network) function reports whether an address is within a network.
+type ExprConfig struct {
+	Drop    string   // drop the entry if this evaluates to true
+	Match   string   // only apply Route, Set-EV, and Rewrite when this evaluates to true
+	Route   string   // evaluates to the name of the tag for the entry, empty strings leave the tag alone
+	Set_EV  []string // name=expression, attaches the result as an enumerated value
+	Rewrite string   // evaluates to the new entry data
+}
+
+type exprEnv struct {
+	TAG  string
+	SRC  string
+	TS   time.Time
+	DATA string
+	EV   map[string]interface{}
+	JSON map[string]interface{}
+}
+
+type exprSetEV struct {
+	name string
+	prog *vm.Program
+}
+
+// exprFields records which environment fields the compiled expressions reference
+type exprFields map[string]bool
+
+func (ef exprFields) Visit(node *ast.Node) {
+	if id, ok := (*node).(*ast.IdentifierNode); ok {
+		ef[id.Value] = true
+	}
+}
+
+type Expr struct {
+	nocloser
+	ExprConfig
+	tg      Tagger
+	fields  exprFields
+	drop    *vm.Program
+	match   *vm.Program
+	route   *vm.Program
+	rewrite *vm.Program
+	evs     []exprSetEV
+	tags    map[string]entry.EntryTag
+	cidrs   map[string]*net.IPNet
+	vm      vm.VM
+}
+
+func ExprLoadConfig(vc *config.VariableConfig) (c ExprConfig, err error) {
+	if err = vc.MapTo(&c); err == nil {
+		var e Expr
+		err = e.compile(c)
+	}
+	return
+}
+
+func NewExprProcessor(cfg ExprConfig, tg Tagger) (*Expr, error) {
+	e := &Expr{
+		tg:   tg,
+		tags: map[string]entry.EntryTag{},
+	}
+	if err := e.compile(cfg); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+func (e *Expr) compile(cfg ExprConfig) (err error) {
+	if cfg.Drop == `` && cfg.Route == `` && len(cfg.Set_EV) == 0 && cfg.Rewrite == `` {
+		return ErrMissingExprAction
+	}
+	e.ExprConfig = cfg
+	e.fields = exprFields{}
+	e.cidrs = map[string]*net.IPNet{}
+	if e.drop, err = e.compileOne(`Drop`, cfg.Drop, expr.AsBool()); err != nil {
+		return
+	} else if e.match, err = e.compileOne(`Match`, cfg.Match, expr.AsBool()); err != nil {
+		return
+	} else if e.route, err = e.compileOne(`Route`, cfg.Route); err != nil {
+		return
+	} else if e.rewrite, err = e.compileOne(`Rewrite`, cfg.Rewrite); err != nil {
+		return
+	}
+	e.evs = nil
+	for _, v := range cfg.Set_EV {
+		name, src, ok := strings.Cut(v, `=`)
+		if name = strings.TrimSpace(name); !ok || name == `` {
+			return fmt.Errorf("Invalid Set-EV %q, expected name=expression", v)
+		}
+		sev := exprSetEV{name: name}
+		if sev.prog, err = e.compileOne(`Set-EV `+name, src); err != nil {
+			return
+		} else if sev.prog == nil {
+			return fmt.Errorf("Set-EV %s is missing an expression", name)
+		}
+		e.evs = append(e.evs, sev)
+	}
+	return
+}
+
+func (e *Expr) compileOne(name, src string, opts ...expr.Option) (p *vm.Program, err error) {
+	if strings.TrimSpace(src) == `` {
+		return
+	}
+	opts = append(opts,
+		expr.Env(exprEnv{}),
+		expr.Patch(e.fields),
+		expr.Function(`cidr`, e.cidr, new(func(string, string) bool)),
+	)
+	if p, err = expr.Compile(src, opts...); err != nil {
+		err = fmt.Errorf("Invalid %s expression: %v", name, err)
+	}
+	return
+}
+
+// cidr reports whether addr falls within network, parsed networks are cached because they are almost always constants
+func (e *Expr) cidr(params ...interface{}) (interface{}, error) {
+	addr, _ := params[0].(string)
+	network, _ := params[1].(string)
+	n, ok := e.cidrs[network]
+	if !ok {
+		var err error
+		if _, n, err = net.ParseCIDR(network); err != nil {
+			return false, err
+		}
+		if len(e.cidrs) < maxExprCIDRCache {
+			e.cidrs[network] = n
+		}
+	}
+	ip := net.ParseIP(addr)
+	return ip != nil && n.Contains(ip), nil
+}
+
+func (e *Expr) Process(ents []*entry.Entry) (rset []*entry.Entry, err error) {
+	if len(ents) == 0 {
+		return
+	}
+	rset = ents[:0]
+	for _, ent := range ents {
+		if ent == nil {
+			continue
+		} else if ent = e.processItem(ent); ent != nil {
+			rset = append(rset, ent)
+		}
+	}
+	return
+}
+
+// processItem applies the actions to an entry, every expression sees the entry as it arrived.
+// Expressions that fail at runtime, such as comparing a missing JSON field, are treated as not matching.
+func (e *Expr) processItem(ent *entry.Entry) *entry.Entry {
+	env := e.env(ent)
+	if e.drop != nil {
+		if v, err := e.vm.Run(e.drop, env); err == nil && v == true {
+			return nil
+		}
+	}
+	if e.match != nil {
+		if v, err := e.vm.Run(e.match, env); err != nil || v != true {
+			return ent
+		}
+	}
+	for _, sev := range e.evs {
+		if v, err := e.vm.Run(sev.prog, env); err == nil && v != nil {
+			ed, err := entry.InferEnumeratedData(v)
+			if err != nil {
+				ed = entry.StringEnumData(fmt.Sprint(v))
+			}
+			ent.AddEnumeratedValue(entry.EnumeratedValue{Name: sev.name, Value: ed})
+		}
+	}
+	if e.route != nil {
+		if v, err := e.vm.Run(e.route, env); err == nil {
+			if name, ok := v.(string); ok && name != `` {
+				if tag, err := e.tag(name); err == nil {
+					ent.Tag = tag
+				}
+			}
+		}
+	}
+	if e.rewrite != nil {
+		if v, err := e.vm.Run(e.rewrite, env); err == nil {
+			switch d := v.(type) {
+			case string:
+				ent.Data = []byte(d)
+			case []byte:
+				ent.Data = d
+			case nil:
+			default:
+				ent.Data = []byte(fmt.Sprint(d))
+			}
+		}
+	}
+	return ent
+}
+
+func (e *Expr) tag(name string) (tag entry.EntryTag, err error) {
+	var ok bool
+	if tag, ok = e.tags[name]; !ok {
+		if tag, err = e.tg.NegotiateTag(name); err == nil {
+			e.tags[name] = tag
+		}
+	}
+	return
+}
+
+// env only populates the fields the expressions reference
+func (e *Expr) env(ent *entry.Entry) (env exprEnv) {
+	if e.fields[`TAG`] && e.tg != nil {
+		env.TAG, _ = e.tg.LookupTag(ent.Tag)
+	}
+	if e.fields[`SRC`] && ent.SRC != nil {
+		env.SRC = ent.SRC.String()
+	}
+	if e.fields[`TS`] {
+		env.TS = ent.TS.StandardTime()
+	}
+	if e.fields[`DATA`] {
+		env.DATA = string(ent.Data)
+	}
+	if e.fields[`EV`] && ent.EVB.Populated() {
+		env.EV = make(map[string]interface{}, ent.EVB.Count())
+		for _, ev := range ent.EVB.Values() {
+			env.EV[ev.Name] = ev.Value.Interface()
+		}
+	}
+	if e.fields[`JSON`] {
+		// data that is not a JSON object leaves JSON empty
+		json.Unmarshal(ent.Data, &env.JSON)
+	}
+	return
+}
@@ -0,0 +1,102 @@
+/*************************************************************************
+ * Copyright 2026 Gravwell, Inc. All rights reserved.
+ * Contact: <legal@gravwell.io>
+ *
+ * This software may be modified and distributed under the terms of the
+ * BSD 2-clause license. See the LICENSE file for details.
+ **************************************************************************/
+
+package processors
+
+import (
+	"net"
+	"testing"
+
+	"github.com/gravwell/gravwell/v3/ingest/config"
+	"github.com/gravwell/gravwell/v3/ingest/entry"
+)
+
+func TestExprConfig(t *testing.T) {
+	tests := []struct {
+		cfg  string
+		pass bool
+	}{
+		{`Drop = "JSON.severity < 4 && not cidr(SRC, '10.0.0.0/8')"`, true},
+		{`Route = "'fw-' + EV.vendor"`, true},
+		{`Set-EV = "len = len(DATA)"`, true},
+		{`Rewrite = "upper(DATA)"`, true},
+		{``, false},
+		{`Drop = "DATA +"`, false},
+		{`Drop = "len(DATA)"`, false},
+		{`Route = "NOPE"`, false},
+		{`Set-EV = "len(DATA)"`, false},
+		{`Set-EV = "len = "`, false},
+		{`Match = "TAG == 'foo'"`, false},
+	}
+	for i, tt := range tests {
+		b := []byte("[preprocessor \"e\"]\n\ttype = expr\n\t" + tt.cfg + "\n")
+		var tc testConfigStruct
+		if err := config.LoadConfigBytes(&tc, b); err != nil {
+			t.Fatal(err)
+		} else if err = tc.Preprocessor.CheckConfig(`e`); (err == nil) != tt.pass {
+			t.Fatalf("test %d: got %v, expected pass %v", i, err, tt.pass)
+		}
+	}
+}
+
+func TestExpr(t *testing.T) {
+	var tt testTagger
+	fw, _ := tt.NegotiateTag(`fw`)
+	cfg := ExprConfig{
+		Drop:    `JSON.severity < 4 && not cidr(SRC, "10.0.0.0/8")`,
+		Match:   `EV.vendor != nil`,
+		Route:   `TAG + "-" + EV.vendor`,
+		Set_EV:  []string{`sev = JSON.severity`, `late = TS.Year() > 2000`},
+		Rewrite: `upper(DATA)`,
+	}
+	e, err := NewExprProcessor(cfg, &tt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mk := func(src, data, vendor string) *entry.Entry {
+		ent := &entry.Entry{Tag: fw, TS: entry.Now(), SRC: net.ParseIP(src), Data: []byte(data)}
+		if vendor != `` {
+			ent.AddEnumeratedValueEx(`vendor`, vendor)
+		}
+		return ent
+	}
+	ents := []*entry.Entry{
+		mk(`192.168.1.1`, `{"severity": 2}`, `acme`), // dropped
+		mk(`10.1.1.1`, `{"severity": 2}`, `acme`),    // kept, internal
+		mk(`192.168.1.1`, `{"severity": 6}`, `acme`), // kept, severe enough
+		mk(`192.168.1.1`, `not json`, `acme`),        // kept, the drop expression fails
+		mk(`192.168.1.1`, `{"severity": 6}`, ``),     // kept, but does not match
+	}
+	set, err := e.Process(ents)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(set) != 4 {
+		t.Fatalf("bad count %d", len(set))
+	}
+	acme, ok := tt.mp[`fw-acme`]
+	if !ok {
+		t.Fatal("route tag was not negotiated")
+	}
+	for i, ent := range set[:3] {
+		if ent.Tag != acme {
+			t.Fatalf("%d not routed: %d", i, ent.Tag)
+		} else if v, ok := ent.GetEnumeratedValue(`late`); !ok || v != true {
+			t.Fatalf("%d bad late EV %v", i, v)
+		}
+	}
+	if string(set[1].Data) != `{"SEVERITY": 6}` {
+		t.Fatalf("bad rewrite %q", set[1].Data)
+	} else if v, ok := set[1].GetEnumeratedValue(`sev`); !ok || v != float64(6) {
+		t.Fatalf("bad sev EV %v", v)
+	} else if _, ok = set[2].GetEnumeratedValue(`sev`); ok {
+		t.Fatal("sev attached to non-JSON entry")
+	}
+	if last := set[3]; last.Tag != fw || string(last.Data) != `{"severity": 6}` || last.EVB.Count() != 0 {
+		t.Fatalf("unmatched entry was modified: %+v", last)
+	}
+}
@@ -0,0 +1,244 @@
+/*************************************************************************
+ * Copyright 2026 Gravwell, Inc. All rights reserved.
+ * Contact: <legal@gravwell.io>
+ *
+ * This software may be modified and distributed under the terms of the
+ * BSD 2-clause license. See the LICENSE file for details.
+ **************************************************************************/
+
+package processors
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"net"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/gravwell/gravwell/v3/ingest/entry"
+)
+
+const (
+	maxParallelLanes = 256
+)
+
+// lane is one worker's copy of the preprocessor chain.
+// Entries are assigned to lanes by source, so entries from a single source always go through
+// the same lane in the order they were handed to the ProcessorSet.
+type lane struct {
+	sync.Mutex
+	set []Processor
+}
+
+// serialProcessor is a stage shared by every lane for preprocessors that carry state between calls
+type serialProcessor struct {
+	sync.Mutex
+	p       Processor
+	flushed bool
+	closed  bool
+}
+
+func (sp *serialProcessor) Process(ents []*entry.Entry) ([]*entry.Entry, error) {
+	sp.Lock()
+	defer sp.Unlock()
+	return sp.p.Process(ents)
+}
+
+func (sp *serialProcessor) processStop(ents []*entry.Entry) (set, stopped []*entry.Entry, err error) {
+	sp.Lock()
+	defer sp.Unlock()
+	if s, ok := sp.p.(stopProcessor); ok {
+		return s.processStop(ents)
+	}
+	set, err = sp.p.Process(ents)
+	return
+}
+
+// Flush and Close are only passed on once even though every lane calls them
+func (sp *serialProcessor) Flush() []*entry.Entry {
+	sp.Lock()
+	defer sp.Unlock()
+	if sp.flushed {
+		return nil
+	}
+	sp.flushed = true
+	return sp.p.Flush()
+}
+
+func (sp *serialProcessor) Close() error {
+	sp.Lock()
+	defer sp.Unlock()
+	if sp.closed {
+		return nil
+	}
+	sp.closed = true
+	return sp.p.Close()
+}
+
+// ParallelProcessorSet builds a ProcessorSet that runs up to workers chains concurrently, zero uses the number of CPUs.
+// Preprocessors that do not carry state between calls are cloned for every worker while
+// stateful preprocessors such as gzip, vpc, and plugins are shared and handle one block at a time.
+// Entries are assigned to workers by source address, so ordering is preserved per source.
+func (pc ProcessorConfig) ParallelProcessorSet(t tagWriter, names []string, workers int) (pr *ProcessorSet, err error) {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	if workers > maxParallelLanes {
+		workers = maxParallelLanes
+	}
+	pr = NewProcessorSet(t)
+	pr.lanes = make([]*lane, workers)
+	for i := range pr.lanes {
+		pr.lanes[i] = &lane{}
+	}
+	for _, n := range names {
+		if err = pc.addParallel(pr.lanes, n, t); err != nil {
+			err = fmt.Errorf("%s %v", n, err)
+			pr.Close()
+			return nil, err
+		}
+	}
+	return
+}
+
+func (pc ProcessorConfig) addParallel(lanes []*lane, name string, tgr Tagger) (err error) {
+	var cloneable bool
+	if cloneable, err = pc.cloneable(name); err != nil {
+		return
+	} else if !cloneable {
+		var p Processor
+		if p, err = pc.getProcessor(name, tgr); err != nil {
+			return
+		}
+		sp := &serialProcessor{p: p}
+		for _, l := range lanes {
+			l.set = append(l.set, sp)
+		}
+		return
+	}
+	for _, l := range lanes {
+		var p Processor
+		if p, err = pc.getProcessor(name, tgr); err != nil {
+			return
+		}
+		l.set = append(l.set, p)
+	}
+	return
+}
+
+// cloneable reports whether independent copies of a preprocessor can run side by side.
+// A branch can only be cloned if everything in its chains can be cloned.
+func (pc ProcessorConfig) cloneable(name string) (ok bool, err error) {
+	if err = pc.checkBranches(name, nil); err != nil {
+		return
+	}
+	var pb preprocessorBase
+	if err = pc[name].MapTo(&pb); err != nil {
+		return
+	}
+	switch strings.TrimSpace(strings.ToLower(pb.Type)) {
+	case DropProcessor, JsonExtractProcessor, JsonArraySplitProcessor, JsonFilterProcessor,
+		JsonRouterProcessor, JsonTimestampProcessor, RegexTimestampProcessor, RegexExtractProcessor,
+		RegexRouterProcessor, CSVRouterProcessor, SrcRouterProcessor, SyslogRouterProcessor,
+		TagSrcRouterProcessor, RegexReplaceProcessor, RegexDropProcessor, CorelightProcessor,
+		AttachProcessor, ExprProcessor:
+		ok = true
+	case BranchProcessor:
+		var cfg BranchConfig
+		var names []string
+		if cfg, err = BranchLoadConfig(pc[name]); err != nil {
+			return
+		} else if names, err = cfg.chainNames(); err != nil {
+			return
+		}
+		ok = true
+		for _, n := range names {
+			if ok, err = pc.cloneable(n); err != nil || !ok {
+				return
+			}
+		}
+	}
+	return
+}
+
+// laneFor picks the lane for a source address, it returns nil if the set is not parallel
+func (pr *ProcessorSet) laneFor(src net.IP) *lane {
+	if len(pr.lanes) == 0 {
+		return nil
+	} else if len(pr.lanes) == 1 || src == nil {
+		return pr.lanes[0]
+	}
+	h := fnv.New32a()
+	h.Write(src.To16())
+	return pr.lanes[h.Sum32()%uint32(len(pr.lanes))]
+}
+
+// processParallel splits a block by lane, keeping the relative order of entries within each lane
+func (pr *ProcessorSet) processParallel(ents []*entry.Entry, ctx context.Context) (err error) {
+	if pr.wtr == nil {
+		return ErrNotReady
+	}
+	if len(pr.lanes) == 1 {
+		return pr.processLane(pr.lanes[0], ents, ctx)
+	}
+	var order []*lane
+	blocks := map[*lane][]*entry.Entry{}
+	for _, ent := range ents {
+		if ent == nil {
+			continue
+		}
+		l := pr.laneFor(ent.SRC)
+		if _, ok := blocks[l]; !ok {
+			order = append(order, l)
+		}
+		blocks[l] = append(blocks[l], ent)
+	}
+	for _, l := range order {
+		if err = pr.processLane(l, blocks[l], ctx); err != nil {
+			break
+		}
+	}
+	return
+}
+
+// processLane runs a block through a lane and writes it out while holding the lane so that output order matches input order
+func (pr *ProcessorSet) processLane(l *lane, ents []*entry.Entry, ctx context.Context) (err error) {
+	l.Lock()
+	defer l.Unlock()
+	var set, stopped []*entry.Entry
+	if set, stopped, err = runChain(l.set, ents); err != nil {
+		return
+	} else if set = append(set, stopped...); len(set) == 0 {
+		return
+	}
+	if ctx == nil {
+		err = pr.writeSet(set)
+	} else {
+		err = pr.writeSetContext(set, ctx)
+	}
+	return
+}
+
+func (pr *ProcessorSet) closeParallel() (err error) {
+	for _, l := range pr.lanes {
+		l.Lock()
+		for i, v := range l.set {
+			if ents := v.Flush(); len(ents) > 0 {
+				if ents, stopped, lerr := runChain(l.set[i+1:], ents); lerr != nil {
+					err = addError(lerr, err)
+				} else if ents = append(ents, stopped...); len(ents) > 0 && pr.wtr != nil {
+					if lerr := pr.writeSet(ents); lerr != nil {
+						err = addError(lerr, err)
+					}
+				}
+			}
+			if lerr := v.Close(); lerr != nil {
+				err = addError(lerr, err)
+			}
+		}
+		l.Unlock()
+	}
+	return
+}
@@ -43,8 +43,9 @@ var (
 
 type ProcessorSet struct {
 	sync.Mutex
-	wtr entWriter
-	set []Processor
+	wtr   entWriter
+	set   []Processor
+	lanes []*lane // only used by parallel sets
 }
 
 type ProcessorConfig map[string]*config.VariableConfig
@@ -56,6 +57,11 @@ type Processor interface {
 	Close() error //give the processor a chance to tidy up
 }
 
+// stopProcessor is implemented by processors that can divert entries around the rest of a chain
+type stopProcessor interface {
+	processStop([]*entry.Entry) (set, stopped []*entry.Entry, err error)
+}
+
 func CheckProcessor(id string) error {
 	id = strings.TrimSpace(strings.ToLower(id))
 	switch id {
@@ -71,6 +77,7 @@ func CheckProcessor(id string) error {
 	case JsonRouterProcessor:
 	case JsonTimestampProcessor:
 	case PluginProcessor:
+	case WasmProcessor:
 	case RegexExtractProcessor:
 	case RegexRouterProcessor:
 	case RegexTimestampProcessor:
@@ -82,6 +89,9 @@ func CheckProcessor(id string) error {
 	case RegexReplaceProcessor:
 	case RegexDropProcessor:
 	case AttachProcessor:
+	case BranchProcessor:
+	case ExprProcessor:
+	case EnrichProcessor:
 	default:
 		return checkProcessorOS(id)
 	}
@@ -145,6 +155,8 @@ func ProcessorLoadConfig(vc *config.VariableConfig) (cfg interface{}, err error)
 		cfg, err = SrcRouteLoadConfig(vc)
 	case PluginProcessor:
 		cfg, err = PluginLoadConfig(vc)
+	case WasmProcessor:
+		cfg, err = WasmLoadConfig(vc)
 	case CorelightProcessor:
 		cfg, err = CorelightLoadConfig(vc)
 	case SyslogRouterProcessor:
@@ -157,6 +169,12 @@ func ProcessorLoadConfig(vc *config.VariableConfig) (cfg interface{}, err error)
 		cfg, err = RegexDropLoadConfig(vc)
 	case AttachProcessor:
 		cfg, err = AttachLoadConfig(vc)
+	case BranchProcessor:
+		cfg, err = BranchLoadConfig(vc)
+	case ExprProcessor:
+		cfg, err = ExprLoadConfig(vc)
+	case EnrichProcessor:
+		cfg, err = EnrichLoadConfig(vc)
 	default:
 		cfg, err = processorLoadConfigOS(vc)
 	}
@@ -166,8 +184,8 @@ func ProcessorLoadConfig(vc *config.VariableConfig) (cfg interface{}, err error)
 func (pc ProcessorConfig) CheckConfig(name string) (err error) {
 	if vc, ok := pc[name]; !ok || vc == nil {
 		err = ErrNotFound
-	} else {
-		_, err = ProcessorLoadConfig(vc)
+	} else if _, err = ProcessorLoadConfig(vc); err == nil {
+		err = pc.checkBranches(name, nil)
 	}
 	return
 }
@@ -192,6 +210,9 @@ func (pc ProcessorConfig) MarshalJSON() ([]byte, error) {
 func (pc ProcessorConfig) getProcessor(name string, tgr Tagger) (p Processor, err error) {
 	if vc, ok := pc[name]; !ok || vc == nil {
 		err = ErrNotFound
+	} else if isBranch(vc) {
+		// branches build their sub-chains from the other preprocessors in the config
+		p, err = pc.newBranch(name, tgr)
 	} else {
 		p, err = newProcessor(vc, tgr)
 	}
@@ -308,6 +329,13 @@ func newProcessor(vc *config.VariableConfig, tgr Tagger) (p Processor, err error
 			p, err = NewPluginProcessor(cfg, tgr)
 		}
 		return
+	case WasmProcessor:
+		var cfg WasmConfig
+		// WasmLoadConfig keeps a handle on the variable config so the program can read its own parameters
+		if cfg, err = WasmLoadConfig(vc); err == nil {
+			p, err = NewWasmProcessor(cfg, tgr)
+		}
+		return
 	case CorelightProcessor:
 		var cfg CorelightConfig
 		if cfg, err = CorelightLoadConfig(vc); err != nil {
@@ -344,6 +372,20 @@ func newProcessor(vc *config.VariableConfig, tgr Tagger) (p Processor, err error
 			return
 		}
 		p, err = NewAttachProcessor(cfg)
+	case BranchProcessor:
+		err = ErrBranchNoConfig
+	case ExprProcessor:
+		var cfg ExprConfig
+		if cfg, err = ExprLoadConfig(vc); err != nil {
+			return
+		}
+		p, err = NewExprProcessor(cfg, tgr)
+	case EnrichProcessor:
+		var cfg EnrichConfig
+		if cfg, err = EnrichLoadConfig(vc); err != nil {
+			return
+		}
+		p, err = NewEnrichProcessor(cfg)
 	default:
 		p, err = newProcessorOS(vc, tgr)
 	}
@@ -357,26 +399,41 @@ func NewProcessorSet(wtr entWriter) *ProcessorSet {
 }
 
 func (pr *ProcessorSet) Enabled() bool {
-	pr.Lock()
-	defer pr.Unlock()
-	return len(pr.set) > 0 && pr.wtr != nil
+	return pr.Count() > 0 && pr.wtr != nil
 }
 
 func (pr *ProcessorSet) Count() int {
 	pr.Lock()
 	defer pr.Unlock()
+	if len(pr.lanes) > 0 {
+		pr.lanes[0].Lock()
+		defer pr.lanes[0].Unlock()
+		return len(pr.lanes[0].set)
+	}
 	return len(pr.set)
 }
 
+// AddProcessor appends a processor to the set, parallel sets share it between all workers one block at a time
 func (pr *ProcessorSet) AddProcessor(p Processor) {
 	pr.Lock()
 	defer pr.Unlock()
+	if len(pr.lanes) > 0 {
+		sp := &serialProcessor{p: p}
+		for _, l := range pr.lanes {
+			l.Lock()
+			l.set = append(l.set, sp)
+			l.Unlock()
+		}
+		return
+	}
 	pr.set = append(pr.set, p)
 }
 
 func (pr *ProcessorSet) Process(ent *entry.Entry) (err error) {
 	if ent == nil {
 		return ErrInvalidEntry
+	} else if len(pr.lanes) > 0 {
+		return pr.processParallel([]*entry.Entry{ent}, nil)
 	}
 	pr.Lock()
 	if pr.wtr == nil {
@@ -397,6 +454,8 @@ func (pr *ProcessorSet) Process(ent *entry.Entry) (err error) {
 func (pr *ProcessorSet) ProcessBatch(ents []*entry.Entry) (err error) {
 	if len(ents) == 0 {
 		return nil
+	} else if len(pr.lanes) > 0 {
+		return pr.processParallel(ents, nil)
 	}
 	pr.Lock()
 	if pr.wtr == nil {
@@ -417,6 +476,8 @@ func (pr *ProcessorSet) ProcessBatch(ents []*entry.Entry) (err error) {
 func (pr *ProcessorSet) ProcessContext(ent *entry.Entry, ctx context.Context) (err error) {
 	if ent == nil {
 		return ErrInvalidEntry
+	} else if len(pr.lanes) > 0 {
+		return pr.processParallel([]*entry.Entry{ent}, ctx)
 	}
 	pr.Lock()
 	if pr.wtr == nil {
@@ -437,6 +498,8 @@ func (pr *ProcessorSet) ProcessContext(ent *entry.Entry, ctx context.Context) (e
 func (pr *ProcessorSet) ProcessBatchContext(ents []*entry.Entry, ctx context.Context) (err error) {
 	if len(ents) == 0 {
 		return nil
+	} else if len(pr.lanes) > 0 {
+		return pr.processParallel(ents, ctx)
 	}
 	pr.Lock()
 	if pr.wtr == nil {
@@ -470,13 +533,26 @@ func (pr *ProcessorSet) writeSetContext(ents []*entry.Entry, ctx context.Context
 
 // processItem recurses into each processor generating entries and writing them out
 func (pr *ProcessorSet) processItems(ents []*entry.Entry) (set []*entry.Entry, err error) {
-	set = ents
-	if len(pr.set) == 0 {
-		return
+	var stopped []*entry.Entry
+	if set, stopped, err = runChain(pr.set, ents); err == nil && len(stopped) > 0 {
+		set = append(set, stopped...)
 	}
-	for i := 0; i < len(pr.set) && len(set) > 0; i++ {
+	return
+}
+
+// runChain pushes a block of entries through a list of processors.
+// Entries diverted by a stopping branch skip the rest of the list and are returned in stopped.
+func runChain(prs []Processor, ents []*entry.Entry) (set, stopped []*entry.Entry, err error) {
+	set = ents
+	for i := 0; i < len(prs) && len(set) > 0; i++ {
 		orig := set
-		if set, err = pr.set[i].Process(orig); err != nil {
+		var stp []*entry.Entry
+		if sp, ok := prs[i].(stopProcessor); ok {
+			set, stp, err = sp.processStop(orig)
+		} else {
+			set, err = prs[i].Process(orig)
+		}
+		if err != nil {
 			//TODO FIXME Issue #1225 - https://github.com/gravwell/gravwell/issues/1225
 			if _, ok := err.(*plugin.FaultError); ok {
 				// LOG THIS for issue #1225 and put in some logic
@@ -487,6 +563,7 @@ func (pr *ProcessorSet) processItems(ents []*entry.Entry) (set []*entry.Entry, e
 			}
 			break // something intentionally returned an error, break out
 		}
+		stopped = append(stopped, stp...)
 	}
 	return
 }
@@ -510,6 +587,9 @@ func (pr *ProcessorSet) processItemsOnFlush(prs []Processor, ents []*entry.Entry
 // This function DOES NOT close the ingest muxer handle.
 // It is ONLY for shutting down preprocessors
 func (pr *ProcessorSet) Close() (err error) {
+	if len(pr.lanes) > 0 {
+		return pr.closeParallel()
+	}
 	for i, v := range pr.set {
 		if v != nil {
 			if ents := v.Flush(); len(ents) > 0 {
@@ -565,6 +645,9 @@ func (pc ProcessorConfig) Validate() (err error) {
 		if _, err = ProcessorLoadConfig(v); err != nil {
 			err = fmt.Errorf("Preprocessor %s config invalid: %v", k, err)
 			return
+		} else if err = pc.checkBranches(k, nil); err != nil {
+			err = fmt.Errorf("Preprocessor %s config invalid: %v", k, err)
+			return
 		}
 	}
 	return
@@ -17,6 +17,7 @@ import (
 	"io"
 	"net"
 	"sync"
+	"sync/atomic"
 	"testing"
 
 	"github.com/gravwell/gravwell/v3/ingest/config"
@@ -474,3 +475,178 @@ func checkEntryEVs(ents []*entry.Entry) error {
 	}
 	return nil
 }
+
+const parallelTestConfig = `
+[preprocessor "rr"]
+	type = regexreplace
+	Regex = "(\\w+)@(\\w+)"
+	Replacement = "${2}.${1}"
+
+[preprocessor "rd"]
+	type = regexdrop
+	Regex = "^DEBUG.*(password|secret)=\\S+"
+
+[preprocessor "gz"]
+	type = gzip
+	Passthrough-Non-Gzip = true
+`
+
+type lockedTagWriter struct {
+	sync.Mutex
+	testWriter
+	testTagger
+}
+
+func (lw *lockedTagWriter) WriteEntry(ent *entry.Entry) error {
+	lw.Lock()
+	defer lw.Unlock()
+	return lw.testWriter.WriteEntry(ent)
+}
+
+func (lw *lockedTagWriter) WriteBatch(ents []*entry.Entry) error {
+	for _, ent := range ents {
+		if err := lw.WriteEntry(ent); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// countingProcessor is deliberately not thread safe so the race detector catches concurrent use
+type countingProcessor struct {
+	dummyProcessor
+	count int
+}
+
+func (cp *countingProcessor) Process(ents []*entry.Entry) ([]*entry.Entry, error) {
+	cp.count += len(ents)
+	return ents, nil
+}
+
+func TestParallelProcessorSet(t *testing.T) {
+	var tc testConfigStruct
+	if err := config.LoadConfigBytes(&tc, []byte(parallelTestConfig)); err != nil {
+		t.Fatal(err)
+	}
+	for _, n := range []string{`rr`, `rd`} {
+		if ok, err := tc.Preprocessor.cloneable(n); err != nil || !ok {
+			t.Fatalf("%s should be cloneable: %v", n, err)
+		}
+	}
+	if ok, err := tc.Preprocessor.cloneable(`gz`); err != nil || ok {
+		t.Fatalf("gzip should not be cloneable: %v", err)
+	}
+
+	var lw lockedTagWriter
+	ps, err := tc.Preprocessor.ParallelProcessorSet(&lw, []string{`rr`, `gz`, `rd`}, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var cp countingProcessor
+	ps.AddProcessor(&cp)
+	if ps.Count() != 4 || !ps.Enabled() {
+		t.Fatalf("bad count %d", ps.Count())
+	}
+
+	const sources, perSource = 16, 500
+	var wg sync.WaitGroup
+	for s := 0; s < sources; s++ {
+		wg.Add(1)
+		go func(src net.IP) {
+			defer wg.Done()
+			for i := 0; i < perSource; i += 2 {
+				ents := []*entry.Entry{
+					{SRC: src, Data: []byte(fmt.Sprintf("user@host %d", i))},
+					{SRC: src, Data: []byte(`DEBUG login password=hunter2`)},
+					{SRC: src, Data: []byte(fmt.Sprintf("user@host %d", i+1))},
+				}
+				var err error
+				if i%4 == 0 {
+					err = ps.ProcessBatch(ents)
+				} else {
+					for _, ent := range ents {
+						if err = ps.Process(ent); err != nil {
+							break
+						}
+					}
+				}
+				if err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}(net.IPv4(10, 0, 0, byte(s)))
+	}
+	wg.Wait()
+	if err = ps.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(lw.ents) != sources*perSource || cp.count != sources*perSource {
+		t.Fatalf("bad output count %d %d", len(lw.ents), cp.count)
+	}
+	// every source must come out in the order it went in
+	next := map[string]int{}
+	for _, ent := range lw.ents {
+		var n int
+		if _, err := fmt.Sscanf(string(ent.Data), "host.user %d", &n); err != nil {
+			t.Fatalf("bad entry %q", ent.Data)
+		} else if n != next[ent.SRC.String()] {
+			t.Fatalf("source %v out of order: got %d expected %d", ent.SRC, n, next[ent.SRC.String()])
+		}
+		next[ent.SRC.String()]++
+	}
+}
+
+type discardTagWriter struct {
+	discardWriter
+	testTagger
+}
+
+func benchmarkRegexStack(b *testing.B, workers int) {
+	var tc testConfigStruct
+	if err := config.LoadConfigBytes(&tc, []byte(parallelTestConfig)); err != nil {
+		b.Fatal(err)
+	}
+	var dw discardTagWriter
+	names := []string{`rr`, `rd`, `rr`, `rd`}
+	var ps *ProcessorSet
+	var err error
+	if workers == 0 {
+		ps, err = tc.Preprocessor.ProcessorSet(&dw, names)
+	} else {
+		ps, err = tc.Preprocessor.ParallelProcessorSet(&dw, names, workers)
+	}
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer ps.Close()
+	data := []byte(`Oct 10 12:34:56 web01 sshd[1234]: Accepted publickey for admin@example from 10.1.2.3 port 52144 ssh2`)
+	var srcs uint32
+	b.SetBytes(int64(len(data)))
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		n := atomic.AddUint32(&srcs, 1)
+		src := net.IPv4(10, 0, byte(n>>8), byte(n))
+		ent := &entry.Entry{SRC: src}
+		for pb.Next() {
+			ent.Data = data
+			if err := ps.Process(ent); err != nil {
+				b.Error(err)
+				return
+			}
+		}
+	})
+}
+
+func BenchmarkRegexStackSerial(b *testing.B) {
+	benchmarkRegexStack(b, 0)
+}
+
+func BenchmarkRegexStackParallel(b *testing.B) {
+	for _, w := range []int{1, 2, 4, 8} {
+		b.Run(fmt.Sprintf("workers-%d", w), func(b *testing.B) {
+			benchmarkRegexStack(b, w)
+		})
+	}
+}
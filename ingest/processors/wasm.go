@@ -0,0 +1,138 @@
+/*************************************************************************
+ * Copyright 2026 Gravwell, Inc. All rights reserved.
+ * Contact: <legal@gravwell.io>
+ *
+ * This software may be modified and distributed under the terms of the
+ * BSD 2-clause license. See the LICENSE file for details.
+ **************************************************************************/
+
+package processors
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/gravwell/gravwell/v3/ingest/config"
+	"github.com/gravwell/gravwell/v3/ingest/entry"
+	"github.com/gravwell/gravwell/v3/ingest/processors/wasm"
+)
+
+const (
+	WasmProcessor string = `wasm`
+
+	maxWasmFileSize int64 = 1024 * 1024 * 64
+)
+
+var (
+	ErrNoWasmPath = errors.New("No Wasm-Path provided")
+)
+
+type WasmConfig struct {
+	Wasm_Path    string // path to the compiled wasm module
+	Memory_Limit uint64 // maximum memory for the module in megabytes
+	Call_Timeout string // maximum duration of a single call into the module, e.g. 500ms
+	Debug        bool   // send program output to stdout
+	vc           *config.VariableConfig
+	code         []byte
+	lim          wasm.Limits
+	// all other config items are dynamic and passed to the underlying program
+}
+
+func WasmLoadConfig(vc *config.VariableConfig) (c WasmConfig, err error) {
+	if err = vc.MapTo(&c); err == nil {
+		if err = c.validate(); err == nil {
+			c.vc = vc
+		}
+	}
+	return
+}
+
+func (c *WasmConfig) validate() (err error) {
+	if c.Wasm_Path == `` {
+		return ErrNoWasmPath
+	}
+	if c.Call_Timeout != `` {
+		if c.lim.CallTimeout, err = time.ParseDuration(c.Call_Timeout); err != nil {
+			return fmt.Errorf("Invalid Call-Timeout %q: %v", c.Call_Timeout, err)
+		} else if c.lim.CallTimeout <= 0 {
+			return fmt.Errorf("Invalid Call-Timeout %q", c.Call_Timeout)
+		}
+	}
+	c.lim.Memory = c.Memory_Limit * 1024 * 1024
+	if c.code == nil {
+		c.code, err = loadWasmFile(c.Wasm_Path)
+	}
+	return
+}
+
+func loadWasmFile(pth string) (b []byte, err error) {
+	var fin *os.File
+	var fi os.FileInfo
+	if fin, err = os.Open(filepath.Clean(pth)); err != nil {
+		return
+	}
+	defer fin.Close()
+	if fi, err = fin.Stat(); err != nil {
+		return
+	} else if fi.Size() > maxWasmFileSize {
+		err = fmt.Errorf("Wasm file size is too large: %d > %d", fi.Size(), maxWasmFileSize)
+		return
+	}
+	return io.ReadAll(fin)
+}
+
+// Wasm is a preprocessor that runs a WebAssembly module in a sandbox
+type Wasm struct {
+	WasmConfig
+	prog *wasm.Program
+}
+
+func NewWasmProcessor(cfg WasmConfig, tg Tagger) (p *Wasm, err error) {
+	if err = cfg.validate(); err != nil {
+		return
+	}
+	var prog *wasm.Program
+	if prog, err = wasm.NewProgram(cfg.code, cfg.lim, cfg.Debug); err != nil {
+		return
+	}
+	var cm wasm.ConfigMap
+	if cfg.vc != nil {
+		cm = cfg.vc
+	}
+	if err = prog.Config(cm, tg); err != nil {
+		prog.Close()
+		return
+	}
+	p = &Wasm{
+		WasmConfig: cfg,
+		prog:       prog,
+	}
+	return
+}
+
+func (w *Wasm) Close() error {
+	if w == nil || w.prog == nil {
+		return ErrNotReady
+	}
+	return w.prog.Close()
+}
+
+func (w *Wasm) Flush() []*entry.Entry {
+	if w == nil || w.prog == nil {
+		return nil
+	}
+	// like plugins there is nowhere to send a flush error
+	set, _ := w.prog.Flush()
+	return set
+}
+
+func (w *Wasm) Process(ents []*entry.Entry) ([]*entry.Entry, error) {
+	if w == nil || w.prog == nil {
+		return nil, ErrNotReady
+	}
+	return w.prog.Process(ents)
+}
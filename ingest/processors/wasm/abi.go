@@ -0,0 +1,309 @@
+/*************************************************************************
+ * Copyright 2026 Gravwell, Inc. All rights reserved.
+ * Contact: <legal@gravwell.io>
+ *
+ * This software may be modified and distributed under the terms of the
+ * BSD 2-clause license. See the LICENSE file for details.
+ **************************************************************************/
+
+package wasm
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/gravwell/gravwell/v3/ingest/entry"
+	"github.com/tetratelabs/wazero/api"
+)
+
+// Status codes returned by host functions, non-negative values are successful results
+const (
+	CodeOK         int32 = 0
+	CodeBadIndex   int32 = -1 // the entry index is out of range
+	CodeNotFound   int32 = -2 // the requested value does not exist
+	CodeBadPointer int32 = -3 // a buffer is outside of linear memory
+	CodeInvalid    int32 = -4 // a value was rejected
+)
+
+// hostModule exports the functions programs import from the gravwell module.
+// Functions that return variable length data copy it into the caller's buffer only if it fits,
+// and always return the full length so the caller can grow its buffer and try again.
+func (p *Program) hostModule(ctx context.Context) error {
+	b := p.rt.NewHostModuleBuilder(HostModuleName)
+	export := func(name string, fn interface{}) {
+		b.NewFunctionBuilder().WithFunc(fn).Export(name)
+	}
+	export(`entry_count`, p.entryCount)
+	export(`entry_data`, p.entryData)
+	export(`entry_set_data`, p.entrySetData)
+	export(`entry_tag`, p.entryTag)
+	export(`entry_set_tag`, p.entrySetTag)
+	export(`entry_ts`, p.entryTS)
+	export(`entry_set_ts`, p.entrySetTS)
+	export(`entry_src`, p.entrySrc)
+	export(`entry_set_src`, p.entrySetSrc)
+	export(`entry_ev_get`, p.entryEVGet)
+	export(`entry_ev_set`, p.entryEVSet)
+	export(`entry_ev_set_int`, p.entryEVSetInt)
+	export(`entry_new`, p.entryNew)
+	export(`entry_emit`, p.entryEmit)
+	export(`tag_negotiate`, p.tagNegotiate)
+	export(`tag_lookup`, p.tagLookup)
+	export(`config_get`, p.configGet)
+	export(`set_error`, p.setError)
+	export(`log`, p.log)
+	_, err := b.Instantiate(ctx)
+	return err
+}
+
+// read returns a copy of a region of linear memory
+func read(m api.Module, ptr, l uint32) ([]byte, bool) {
+	b, ok := m.Memory().Read(ptr, l)
+	if !ok {
+		return nil, false
+	}
+	return bytes.Clone(b), true
+}
+
+// copyOut writes b into the caller's buffer if it fits and returns the full length of b
+func copyOut(m api.Module, buf, buflen uint32, b []byte) int32 {
+	if len(b) > math.MaxInt32 {
+		return CodeInvalid
+	} else if len(b) > 0 && uint32(len(b)) <= buflen {
+		if !m.Memory().Write(buf, b) {
+			return CodeBadPointer
+		}
+	}
+	return int32(len(b))
+}
+
+func (p *Program) entry(idx uint32) *entry.Entry {
+	if uint64(idx) >= uint64(len(p.ents)) {
+		return nil
+	}
+	return p.ents[idx]
+}
+
+func (p *Program) entryCount(ctx context.Context, m api.Module) int32 {
+	return int32(len(p.ents))
+}
+
+func (p *Program) entryData(ctx context.Context, m api.Module, idx, buf, buflen uint32) int32 {
+	ent := p.entry(idx)
+	if ent == nil {
+		return CodeBadIndex
+	}
+	return copyOut(m, buf, buflen, ent.Data)
+}
+
+func (p *Program) entrySetData(ctx context.Context, m api.Module, idx, ptr, l uint32) int32 {
+	ent := p.entry(idx)
+	if ent == nil {
+		return CodeBadIndex
+	}
+	b, ok := read(m, ptr, l)
+	if !ok {
+		return CodeBadPointer
+	}
+	ent.Data = b
+	return CodeOK
+}
+
+func (p *Program) entryTag(ctx context.Context, m api.Module, idx uint32) int32 {
+	ent := p.entry(idx)
+	if ent == nil {
+		return CodeBadIndex
+	}
+	return int32(ent.Tag)
+}
+
+func (p *Program) entrySetTag(ctx context.Context, m api.Module, idx, tag uint32) int32 {
+	ent := p.entry(idx)
+	if ent == nil {
+		return CodeBadIndex
+	} else if tag > math.MaxUint16 {
+		return CodeInvalid
+	}
+	ent.Tag = entry.EntryTag(tag)
+	return CodeOK
+}
+
+// entryTS returns the timestamp in nanoseconds since the epoch, invalid indexes return zero
+func (p *Program) entryTS(ctx context.Context, m api.Module, idx uint32) int64 {
+	ent := p.entry(idx)
+	if ent == nil {
+		return 0
+	}
+	return ent.TS.StandardTime().UnixNano()
+}
+
+func (p *Program) entrySetTS(ctx context.Context, m api.Module, idx uint32, ns int64) int32 {
+	ent := p.entry(idx)
+	if ent == nil {
+		return CodeBadIndex
+	}
+	ent.TS = entry.FromStandard(time.Unix(0, ns))
+	return CodeOK
+}
+
+// entrySrc returns the source address as 4 or 16 bytes, entries without a source return zero
+func (p *Program) entrySrc(ctx context.Context, m api.Module, idx, buf, buflen uint32) int32 {
+	ent := p.entry(idx)
+	if ent == nil {
+		return CodeBadIndex
+	}
+	src := ent.SRC
+	if v4 := src.To4(); v4 != nil {
+		src = v4
+	}
+	return copyOut(m, buf, buflen, src)
+}
+
+func (p *Program) entrySetSrc(ctx context.Context, m api.Module, idx, ptr, l uint32) int32 {
+	ent := p.entry(idx)
+	if ent == nil {
+		return CodeBadIndex
+	} else if l != 0 && l != net.IPv4len && l != net.IPv6len {
+		return CodeInvalid
+	}
+	b, ok := read(m, ptr, l)
+	if !ok {
+		return CodeBadPointer
+	}
+	ent.SRC = net.IP(b)
+	if l == 0 {
+		ent.SRC = nil
+	}
+	return CodeOK
+}
+
+// entryEVGet returns an enumerated value rendered as a string
+func (p *Program) entryEVGet(ctx context.Context, m api.Module, idx, name, namelen, buf, buflen uint32) int32 {
+	ent := p.entry(idx)
+	if ent == nil {
+		return CodeBadIndex
+	}
+	n, ok := read(m, name, namelen)
+	if !ok {
+		return CodeBadPointer
+	}
+	ev, ok := ent.EVB.Get(string(n))
+	if !ok {
+		return CodeNotFound
+	}
+	return copyOut(m, buf, buflen, []byte(ev.Value.String()))
+}
+
+func (p *Program) entryEVSet(ctx context.Context, m api.Module, idx, name, namelen, val, vallen uint32) int32 {
+	v, ok := read(m, val, vallen)
+	if !ok {
+		return CodeBadPointer
+	}
+	return p.setEV(m, idx, name, namelen, entry.StringEnumData(string(v)))
+}
+
+func (p *Program) entryEVSetInt(ctx context.Context, m api.Module, idx, name, namelen uint32, v int64) int32 {
+	return p.setEV(m, idx, name, namelen, entry.Int64EnumData(v))
+}
+
+func (p *Program) setEV(m api.Module, idx, name, namelen uint32, ed entry.EnumeratedData) int32 {
+	ent := p.entry(idx)
+	if ent == nil {
+		return CodeBadIndex
+	}
+	n, ok := read(m, name, namelen)
+	if !ok {
+		return CodeBadPointer
+	}
+	if err := ent.AddEnumeratedValue(entry.EnumeratedValue{Name: string(n), Value: ed}); err != nil {
+		return CodeInvalid
+	}
+	return CodeOK
+}
+
+// entryNew creates an entry with the given data and the current time, it returns the index of the new entry.
+// New entries are not emitted until the program emits them.
+func (p *Program) entryNew(ctx context.Context, m api.Module, ptr, l uint32) int32 {
+	if len(p.ents) >= math.MaxInt32 {
+		return CodeInvalid
+	}
+	b, ok := read(m, ptr, l)
+	if !ok {
+		return CodeBadPointer
+	}
+	p.ents = append(p.ents, &entry.Entry{TS: entry.Now(), Data: b})
+	return int32(len(p.ents) - 1)
+}
+
+// entryEmit adds an entry to the output of the current call, entries are output in the order they are emitted
+func (p *Program) entryEmit(ctx context.Context, m api.Module, idx uint32) int32 {
+	ent := p.entry(idx)
+	if ent == nil {
+		return CodeBadIndex
+	}
+	p.out = append(p.out, ent)
+	return CodeOK
+}
+
+func (p *Program) tagNegotiate(ctx context.Context, m api.Module, name, namelen uint32) int32 {
+	n, ok := read(m, name, namelen)
+	if !ok {
+		return CodeBadPointer
+	} else if p.tg == nil {
+		return CodeNotFound
+	}
+	tag, err := p.tg.NegotiateTag(string(n))
+	if err != nil {
+		return CodeInvalid
+	}
+	return int32(tag)
+}
+
+func (p *Program) tagLookup(ctx context.Context, m api.Module, tag, buf, buflen uint32) int32 {
+	if p.tg == nil || tag > math.MaxUint16 {
+		return CodeNotFound
+	}
+	name, ok := p.tg.LookupTag(entry.EntryTag(tag))
+	if !ok {
+		return CodeNotFound
+	}
+	return copyOut(m, buf, buflen, []byte(name))
+}
+
+// configGet returns a configuration value, index selects among values of parameters that are given more than once
+func (p *Program) configGet(ctx context.Context, m api.Module, name, namelen, index, buf, buflen uint32) int32 {
+	n, ok := read(m, name, namelen)
+	if !ok {
+		return CodeBadPointer
+	} else if p.cm == nil {
+		return CodeNotFound
+	}
+	vals, err := p.cm.GetStringSlice(string(n))
+	if err != nil || uint64(index) >= uint64(len(vals)) {
+		return CodeNotFound
+	}
+	return copyOut(m, buf, buflen, []byte(vals[index]))
+}
+
+// setError records an error message that is reported when the current call returns a non-zero code
+func (p *Program) setError(ctx context.Context, m api.Module, ptr, l uint32) {
+	if b, ok := read(m, ptr, l); ok {
+		p.err = string(b)
+	} else {
+		p.err = `set_error called with an invalid buffer at ` + strconv.FormatUint(uint64(ptr), 10)
+	}
+}
+
+func (p *Program) log(ctx context.Context, m api.Module, ptr, l uint32) {
+	if p.debug == nil {
+		return
+	}
+	if b, ok := m.Memory().Read(ptr, l); ok {
+		fmt.Fprintf(p.debug, "%s\n", b)
+	}
+}
@@ -0,0 +1,167 @@
+//go:build wasip1
+
+/*************************************************************************
+ * Copyright 2026 Gravwell, Inc. All rights reserved.
+ * Contact: <legal@gravwell.io>
+ *
+ * This software may be modified and distributed under the terms of the
+ * BSD 2-clause license. See the LICENSE file for details.
+ **************************************************************************/
+
+// guest is a test preprocessor built with GOOS=wasip1 GOARCH=wasm go build -buildmode=c-shared
+package main
+
+import (
+	"bytes"
+	"strconv"
+	"unsafe"
+)
+
+//go:wasmimport gravwell entry_data
+func entry_data(idx int32, buf unsafe.Pointer, buflen uint32) int32
+
+//go:wasmimport gravwell entry_set_data
+func entry_set_data(idx int32, buf unsafe.Pointer, l uint32) int32
+
+//go:wasmimport gravwell entry_set_tag
+func entry_set_tag(idx int32, tag int32) int32
+
+//go:wasmimport gravwell entry_ts
+func entry_ts(idx int32) int64
+
+//go:wasmimport gravwell entry_set_ts
+func entry_set_ts(idx int32, ns int64) int32
+
+//go:wasmimport gravwell entry_ev_get
+func entry_ev_get(idx int32, name unsafe.Pointer, namelen uint32, buf unsafe.Pointer, buflen uint32) int32
+
+//go:wasmimport gravwell entry_ev_set
+func entry_ev_set(idx int32, name unsafe.Pointer, namelen uint32, val unsafe.Pointer, vallen uint32) int32
+
+//go:wasmimport gravwell entry_ev_set_int
+func entry_ev_set_int(idx int32, name unsafe.Pointer, namelen uint32, v int64) int32
+
+//go:wasmimport gravwell entry_new
+func entry_new(buf unsafe.Pointer, l uint32) int32
+
+//go:wasmimport gravwell entry_emit
+func entry_emit(idx int32) int32
+
+//go:wasmimport gravwell tag_negotiate
+func tag_negotiate(name unsafe.Pointer, l uint32) int32
+
+//go:wasmimport gravwell config_get
+func config_get(name unsafe.Pointer, namelen uint32, index uint32, buf unsafe.Pointer, buflen uint32) int32
+
+//go:wasmimport gravwell set_error
+func set_error(buf unsafe.Pointer, l uint32)
+
+func ptr(b []byte) unsafe.Pointer {
+	return unsafe.Pointer(unsafe.SliceData(b))
+}
+
+// fetch calls a host function that copies into a buffer, growing the buffer until the value fits
+func fetch(fn func(buf unsafe.Pointer, buflen uint32) int32) ([]byte, bool) {
+	buf := make([]byte, 64)
+	for {
+		n := fn(ptr(buf), uint32(len(buf)))
+		if n < 0 {
+			return nil, false
+		} else if int(n) <= len(buf) {
+			return buf[:n], true
+		}
+		buf = make([]byte, n)
+	}
+}
+
+func configValue(name string, index uint32) (string, bool) {
+	n := []byte(name)
+	v, ok := fetch(func(buf unsafe.Pointer, buflen uint32) int32 {
+		return config_get(ptr(n), uint32(len(n)), index, buf, buflen)
+	})
+	return string(v), ok
+}
+
+func fail(msg string) int32 {
+	b := []byte(msg)
+	set_error(ptr(b), uint32(len(b)))
+	return 1
+}
+
+var (
+	prefix    []byte
+	tag       int32 = -1
+	processed int
+	hoard     [][]byte
+)
+
+//go:wasmexport gw_config
+func config() int32 {
+	if v, ok := configValue(`Prefix`, 0); ok {
+		prefix = []byte(v)
+	}
+	if v, ok := configValue(`Output-Tag`, 0); ok {
+		b := []byte(v)
+		if tag = tag_negotiate(ptr(b), uint32(len(b))); tag < 0 {
+			return fail(`bad tag ` + v)
+		}
+	}
+	return 0
+}
+
+//go:wasmexport gw_process
+func process(n int32) int32 {
+	for i := int32(0); i < n; i++ {
+		data, ok := fetch(func(buf unsafe.Pointer, buflen uint32) int32 { return entry_data(i, buf, buflen) })
+		if !ok {
+			return fail(`bad entry`)
+		}
+		processed++
+		switch {
+		case bytes.Equal(data, []byte(`drop`)):
+			continue
+		case bytes.Equal(data, []byte(`fail`)):
+			return fail(`asked to fail`)
+		case bytes.Equal(data, []byte(`spin`)):
+			for {
+			}
+		case bytes.Equal(data, []byte(`hoard`)):
+			for {
+				hoard = append(hoard, make([]byte, 1024*1024))
+			}
+		case bytes.HasPrefix(data, []byte(`split `)):
+			// new entries take the timestamp of the original
+			ts := entry_ts(i)
+			for _, w := range bytes.Fields(data[6:]) {
+				idx := entry_new(ptr(w), uint32(len(w)))
+				entry_set_ts(idx, ts)
+				entry_emit(idx)
+			}
+			continue
+		}
+		out := append(append([]byte{}, prefix...), bytes.ToUpper(data)...)
+		entry_set_data(i, ptr(out), uint32(len(out)))
+		if tag >= 0 {
+			entry_set_tag(i, tag)
+		}
+		name, lname := []byte(`len`), []byte(`label`)
+		entry_ev_set_int(i, ptr(name), uint32(len(name)), int64(len(data)))
+		if v, ok := fetch(func(buf unsafe.Pointer, buflen uint32) int32 {
+			return entry_ev_get(i, ptr(lname), uint32(len(lname)), buf, buflen)
+		}); ok {
+			v = bytes.ToUpper(v)
+			entry_ev_set(i, ptr(lname), uint32(len(lname)), ptr(v), uint32(len(v)))
+		}
+		entry_emit(i)
+	}
+	return 0
+}
+
+//go:wasmexport gw_flush
+func flush() int32 {
+	b := []byte(`processed ` + strconv.Itoa(processed))
+	entry_emit(entry_new(ptr(b), uint32(len(b))))
+	return 0
+}
+
+func main() {}
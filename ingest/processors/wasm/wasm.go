@@ -0,0 +1,302 @@
+/*************************************************************************
+ * Copyright 2026 Gravwell, Inc. All rights reserved.
+ * Contact: <legal@gravwell.io>
+ *
+ * This software may be modified and distributed under the terms of the
+ * BSD 2-clause license. See the LICENSE file for details.
+ **************************************************************************/
+
+// Package wasm runs preprocessors compiled to WebAssembly in a sandboxed, pure Go runtime.
+// Programs interact with the ingester only through the small host ABI described in README.md.
+package wasm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/gravwell/gravwell/v3/ingest/entry"
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
+	"github.com/tetratelabs/wazero/sys"
+)
+
+const (
+	HostModuleName string = `gravwell`
+
+	ConfigFuncName  string = `gw_config`
+	ProcessFuncName string = `gw_process`
+	FlushFuncName   string = `gw_flush`
+	CloseFuncName   string = `gw_close`
+	initFuncName    string = `_initialize`
+
+	pageSize           = 64 * 1024
+	DefaultMemoryLimit = 128 * 1024 * 1024 // 128MB
+	MaxMemoryLimit     = 4096 * 1024 * 1024
+	DefaultCallTimeout = time.Second
+)
+
+var (
+	ErrInvalidProgram = errors.New("invalid wasm program")
+	ErrNotReady       = errors.New("not ready")
+	ErrMissingProcess = errors.New("wasm program does not export " + ProcessFuncName)
+	ErrTimeout        = errors.New("wasm program exceeded its call timeout")
+	ErrMemoryLimit    = errors.New("invalid memory limit")
+)
+
+// ConfigMap is the set of configuration values handed to the program, it is satisfied by config.VariableConfig
+type ConfigMap interface {
+	GetStringSlice(string) ([]string, error)
+}
+
+// Tagger interface is a copy of the interface in processors, but we can't import processors due to import cycles
+type Tagger interface {
+	NegotiateTag(name string) (entry.EntryTag, error)
+	LookupTag(entry.EntryTag) (string, bool)
+	KnownTags() []string
+}
+
+// Limits bound the resources a program may use
+type Limits struct {
+	Memory      uint64        // maximum linear memory in bytes, zero uses DefaultMemoryLimit
+	CallTimeout time.Duration // maximum duration of a single call into the program, zero uses DefaultCallTimeout
+}
+
+func (l Limits) validate() (Limits, error) {
+	if l.Memory == 0 {
+		l.Memory = DefaultMemoryLimit
+	} else if l.Memory < pageSize || l.Memory > MaxMemoryLimit {
+		return l, fmt.Errorf("%w %d, must be between %d and %d bytes", ErrMemoryLimit, l.Memory, pageSize, uint64(MaxMemoryLimit))
+	}
+	if l.CallTimeout <= 0 {
+		l.CallTimeout = DefaultCallTimeout
+	}
+	return l, nil
+}
+
+// Program is an instantiated wasm preprocessor, calls are serialized.
+// A call that times out or traps leaves the module closed or in an unknown state, so the module
+// is instantiated again from the compiled code and reconfigured before the next call.
+type Program struct {
+	sync.Mutex
+	lim        Limits
+	rt         wazero.Runtime
+	compiled   wazero.CompiledModule
+	mod        api.Module
+	broken     bool // the last call failed inside the runtime and the module must be replaced
+	configured bool
+	process    api.Function
+	config     api.Function
+	flush      api.Function
+	closef     api.Function
+	debug      io.Writer
+
+	// per call state the host functions operate on
+	cm   ConfigMap
+	tg   Tagger
+	ents []*entry.Entry // entries visible to the program, inputs followed by any it created
+	out  []*entry.Entry
+	err  string
+}
+
+// NewProgram compiles and instantiates a wasm module, debug output from the program goes to stdout when debug is set
+func NewProgram(code []byte, lim Limits, debug bool) (p *Program, err error) {
+	if len(code) == 0 {
+		return nil, ErrInvalidProgram
+	}
+	if lim, err = lim.validate(); err != nil {
+		return
+	}
+	p = &Program{lim: lim}
+	if debug {
+		p.debug = os.Stdout
+	}
+	ctx := context.Background()
+	rc := wazero.NewRuntimeConfig().
+		WithMemoryLimitPages(uint32(lim.Memory / pageSize)).
+		WithCloseOnContextDone(true)
+	p.rt = wazero.NewRuntimeWithConfig(ctx, rc)
+	if err = p.init(ctx, code); err != nil {
+		p.rt.Close(ctx)
+		p = nil
+	}
+	return
+}
+
+func (p *Program) init(ctx context.Context, code []byte) (err error) {
+	// WASI is provided so TinyGo and Rust programs link, there is no filesystem, network, or environment
+	if _, err = wasi_snapshot_preview1.Instantiate(ctx, p.rt); err != nil {
+		return
+	} else if err = p.hostModule(ctx); err != nil {
+		return
+	}
+	if p.compiled, err = p.rt.CompileModule(ctx, code); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidProgram, err)
+	}
+	if err = p.instantiate(ctx); err != nil {
+		if errors.Is(err, ErrMissingProcess) {
+			return
+		}
+		return fmt.Errorf("%w: %v", ErrInvalidProgram, err)
+	}
+	if fn := p.mod.ExportedFunction(initFuncName); fn != nil {
+		err = p.call(fn, `initialize`)
+	}
+	return
+}
+
+// instantiate creates a fresh instance of the compiled module, closing any previous instance
+func (p *Program) instantiate(ctx context.Context) (err error) {
+	if p.mod != nil {
+		p.mod.Close(ctx)
+		p.mod = nil
+	}
+	mc := wazero.NewModuleConfig().
+		WithStartFunctions(). // programs are libraries, we call the reactor initializer ourselves
+		WithStdout(p.output()).
+		WithStderr(p.output())
+	if p.mod, err = p.rt.InstantiateModule(ctx, p.compiled, mc); err != nil {
+		return
+	}
+	if p.process = p.mod.ExportedFunction(ProcessFuncName); p.process == nil {
+		return ErrMissingProcess
+	}
+	p.config = p.mod.ExportedFunction(ConfigFuncName)
+	p.flush = p.mod.ExportedFunction(FlushFuncName)
+	p.closef = p.mod.ExportedFunction(CloseFuncName)
+	p.broken = false
+	return
+}
+
+// restart replaces a module that failed in the runtime, running the initializer and configuration again
+func (p *Program) restart() (err error) {
+	if err = p.instantiate(context.Background()); err != nil {
+		return
+	}
+	if fn := p.mod.ExportedFunction(initFuncName); fn != nil {
+		if err = p.call(fn, `initialize`); err != nil {
+			return
+		}
+	}
+	if p.configured && p.config != nil {
+		err = p.call(p.config, ConfigFuncName)
+	}
+	return
+}
+
+func (p *Program) output() io.Writer {
+	if p.debug == nil {
+		return io.Discard
+	}
+	return p.debug
+}
+
+// call invokes an exported function under the call timeout and converts failures into errors.
+// A module left broken by an earlier call is replaced first.
+func (p *Program) call(fn api.Function, name string, params ...uint64) error {
+	if p.rt == nil || p.mod == nil {
+		return ErrNotReady
+	} else if p.broken || p.mod.IsClosed() {
+		p.broken = true
+		if err := p.restart(); err != nil {
+			p.broken = true
+			return fmt.Errorf("failed to restart wasm program: %w", err)
+		}
+		// the function handle belongs to the old instance
+		if fn = p.mod.ExportedFunction(fn.Definition().ExportNames()[0]); fn == nil {
+			return ErrNotReady
+		}
+	}
+	p.err = ``
+	ctx, cancel := context.WithTimeout(context.Background(), p.lim.CallTimeout)
+	defer cancel()
+	res, err := fn.Call(ctx, params...)
+	if err != nil {
+		// timeouts close the module and traps leave its memory in an unknown state
+		p.broken = true
+		var ee *sys.ExitError
+		if errors.As(err, &ee) && ee.ExitCode() == sys.ExitCodeDeadlineExceeded {
+			return fmt.Errorf("%s: %w", name, ErrTimeout)
+		}
+		return fmt.Errorf("%s failed: %w", name, err)
+	} else if len(res) > 0 && int32(res[0]) != 0 {
+		if p.err != `` {
+			return fmt.Errorf("%s failed: %s", name, p.err)
+		}
+		return fmt.Errorf("%s failed with code %d", name, int32(res[0]))
+	}
+	return nil
+}
+
+// Config hands the configuration and tagger to the program, the program may hold onto either for its lifetime
+func (p *Program) Config(cm ConfigMap, tg Tagger) (err error) {
+	if p == nil {
+		return ErrNotReady
+	}
+	p.Lock()
+	defer p.Unlock()
+	p.cm, p.tg = cm, tg
+	p.configured = true
+	if p.config != nil {
+		err = p.call(p.config, ConfigFuncName)
+	}
+	return
+}
+
+// Process runs a block of entries through the program, the program decides which entries are emitted
+func (p *Program) Process(ents []*entry.Entry) (r []*entry.Entry, err error) {
+	if p == nil {
+		return nil, ErrNotReady
+	}
+	p.Lock()
+	defer p.Unlock()
+	p.ents, p.out = ents[:len(ents):len(ents)], nil // new entries must not land in the caller's backing array
+	if err = p.call(p.process, ProcessFuncName, uint64(len(ents))); err == nil {
+		r = p.out
+	}
+	p.ents, p.out = nil, nil
+	return
+}
+
+// Flush gives the program a chance to emit any entries it is holding
+func (p *Program) Flush() (r []*entry.Entry, err error) {
+	if p == nil {
+		return nil, ErrNotReady
+	}
+	p.Lock()
+	defer p.Unlock()
+	if p.flush == nil {
+		return
+	}
+	p.ents, p.out = nil, nil
+	if err = p.call(p.flush, FlushFuncName); err == nil {
+		r = p.out
+	}
+	p.ents, p.out = nil, nil
+	return
+}
+
+// Close tells the program to tidy up and releases the runtime
+func (p *Program) Close() (err error) {
+	if p == nil {
+		return ErrNotReady
+	}
+	p.Lock()
+	defer p.Unlock()
+	if p.rt == nil {
+		return ErrNotReady
+	}
+	if p.closef != nil && !p.broken && !p.mod.IsClosed() {
+		err = p.call(p.closef, CloseFuncName)
+	}
+	if lerr := p.rt.Close(context.Background()); lerr != nil && err == nil {
+		err = lerr
+	}
+	p.rt, p.mod = nil, nil
+	return
+}
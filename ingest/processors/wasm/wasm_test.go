@@ -0,0 +1,212 @@
+/*************************************************************************
+ * Copyright 2026 Gravwell, Inc. All rights reserved.
+ * Contact: <legal@gravwell.io>
+ *
+ * This software may be modified and distributed under the terms of the
+ * BSD 2-clause license. See the LICENSE file for details.
+ **************************************************************************/
+
+package wasm
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gravwell/gravwell/v3/ingest/entry"
+)
+
+var (
+	guestOnce sync.Once
+	guestCode []byte
+	guestErr  error
+)
+
+// guest builds the test program in testdata/guest with the local Go toolchain
+func guest(t *testing.T) []byte {
+	t.Helper()
+	guestOnce.Do(func() {
+		dir, err := os.MkdirTemp("", "wasmguest")
+		if err != nil {
+			guestErr = err
+			return
+		}
+		defer os.RemoveAll(dir)
+		out := filepath.Join(dir, `guest.wasm`)
+		cmd := exec.Command(`go`, `build`, `-buildmode=c-shared`, `-o`, out, `./testdata/guest`)
+		cmd.Env = append(os.Environ(), `GOOS=wasip1`, `GOARCH=wasm`)
+		if b, err := cmd.CombinedOutput(); err != nil {
+			guestErr = fmt.Errorf("%v: %s", err, b)
+			return
+		}
+		guestCode, guestErr = os.ReadFile(out)
+	})
+	if guestErr != nil {
+		t.Skip("cannot build the wasm test program:", guestErr)
+	}
+	return guestCode
+}
+
+type testConfig map[string][]string
+
+func (tc testConfig) GetStringSlice(name string) ([]string, error) {
+	return tc[name], nil
+}
+
+type testTagger map[string]entry.EntryTag
+
+func (tt testTagger) NegotiateTag(name string) (entry.EntryTag, error) {
+	if name == `` {
+		return 0, errors.New("empty tag")
+	}
+	if v, ok := tt[name]; ok {
+		return v, nil
+	}
+	tt[name] = entry.EntryTag(len(tt) + 1)
+	return tt[name], nil
+}
+
+func (tt testTagger) LookupTag(tag entry.EntryTag) (string, bool) {
+	for k, v := range tt {
+		if v == tag {
+			return k, true
+		}
+	}
+	return ``, false
+}
+
+func (tt testTagger) KnownTags() (r []string) {
+	for k := range tt {
+		r = append(r, k)
+	}
+	return
+}
+
+func newTestProgram(t *testing.T, lim Limits, cfg testConfig) *Program {
+	t.Helper()
+	p, err := NewProgram(guest(t), lim, false)
+	if err != nil {
+		t.Fatal(err)
+	} else if err = p.Config(cfg, testTagger{}); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func mkEntries(vals ...string) (ents []*entry.Entry) {
+	for i, v := range vals {
+		ents = append(ents, &entry.Entry{
+			TS:   entry.FromStandard(time.Unix(int64(1000+i), 0)),
+			SRC:  net.ParseIP(`10.0.0.1`),
+			Data: []byte(v),
+		})
+	}
+	return
+}
+
+func TestProcess(t *testing.T) {
+	p := newTestProgram(t, Limits{}, testConfig{`Prefix`: {`> `}, `Output-Tag`: {`wasm`}})
+	defer p.Close()
+
+	ents := mkEntries(`hello`, `drop`, `split a bb ccc`, `world`)
+	ents[3].AddEnumeratedValueEx(`label`, `thing`)
+	set, err := p.Process(ents)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, ent := range set {
+		got = append(got, string(ent.Data))
+	}
+	if exp := `> HELLO|a|bb|ccc|> WORLD`; strings.Join(got, `|`) != exp {
+		t.Fatalf("got %q expected %q", strings.Join(got, `|`), exp)
+	}
+	if set[0] != ents[0] || set[0].Tag != 1 || !set[0].SRC.Equal(net.ParseIP(`10.0.0.1`)) {
+		t.Fatalf("bad first entry %+v", set[0])
+	} else if v, ok := set[0].GetEnumeratedValue(`len`); !ok || v != int64(5) {
+		t.Fatalf("bad len ev %v", v)
+	} else if v, ok = set[4].GetEnumeratedValue(`label`); !ok || v != `THING` {
+		t.Fatalf("bad label ev %v", v)
+	} else if !set[2].TS.StandardTime().Equal(time.Unix(1002, 0)) {
+		t.Fatalf("new entry has bad timestamp %v", set[2].TS)
+	}
+
+	if set, err = p.Flush(); err != nil {
+		t.Fatal(err)
+	} else if len(set) != 1 || string(set[0].Data) != `processed 4` {
+		t.Fatalf("bad flush %v", set)
+	}
+
+	// errors reported by the program come back with their message and the program keeps working
+	if _, err = p.Process(mkEntries(`fail`)); err == nil || !strings.Contains(err.Error(), `asked to fail`) {
+		t.Fatalf("bad error %v", err)
+	} else if set, err = p.Process(mkEntries(`again`)); err != nil || len(set) != 1 {
+		t.Fatalf("program failed after an error: %v %v", set, err)
+	}
+	if err = p.Close(); err != nil {
+		t.Fatal(err)
+	} else if _, err = p.Process(mkEntries(`closed`)); err == nil {
+		t.Fatal("closed program processed entries")
+	}
+}
+
+func TestConfigError(t *testing.T) {
+	p, err := NewProgram(guest(t), Limits{}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+	if err = p.Config(testConfig{`Output-Tag`: {``}}, testTagger{}); err == nil || !strings.Contains(err.Error(), `bad tag`) {
+		t.Fatalf("bad error %v", err)
+	}
+}
+
+func TestLimits(t *testing.T) {
+	p := newTestProgram(t, Limits{CallTimeout: 200 * time.Millisecond}, testConfig{`Prefix`: {`> `}})
+	start := time.Now()
+	if _, err := p.Process(mkEntries(`spin`)); !errors.Is(err, ErrTimeout) {
+		t.Fatalf("bad error %v", err)
+	} else if d := time.Since(start); d > 5*time.Second {
+		t.Fatalf("timeout took %v", d)
+	}
+	// the timed out module is replaced and configured again
+	for i := 0; i < 2; i++ {
+		if set, err := p.Process(mkEntries(`hello`)); err != nil {
+			t.Fatalf("program failed after a timeout: %v", err)
+		} else if len(set) != 1 || string(set[0].Data) != `> HELLO` {
+			t.Fatalf("bad output after a timeout: %v", set)
+		}
+	}
+	if err := p.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	p = newTestProgram(t, Limits{Memory: 64 * 1024 * 1024}, nil)
+	defer p.Close()
+	if _, err := p.Process(mkEntries(`hoard`)); err == nil {
+		t.Fatal("program exceeded the memory limit")
+	}
+
+	if _, err := NewProgram(guest(t), Limits{Memory: 1024}, false); !errors.Is(err, ErrMemoryLimit) {
+		t.Fatalf("bad error %v", err)
+	}
+}
+
+func TestBadPrograms(t *testing.T) {
+	if _, err := NewProgram(nil, Limits{}, false); !errors.Is(err, ErrInvalidProgram) {
+		t.Fatalf("bad error %v", err)
+	} else if _, err = NewProgram([]byte("not a wasm module"), Limits{}, false); !errors.Is(err, ErrInvalidProgram) {
+		t.Fatalf("bad error %v", err)
+	}
+	// an empty module is valid wasm but does not export a process function
+	if _, err := NewProgram([]byte{0, 'a', 's', 'm', 1, 0, 0, 0}, Limits{}, false); !errors.Is(err, ErrMissingProcess) {
+		t.Fatalf("bad error %v", err)
+	}
+}
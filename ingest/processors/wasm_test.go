@@ -0,0 +1,101 @@
+/*************************************************************************
+ * Copyright 2026 Gravwell, Inc. All rights reserved.
+ * Contact: <legal@gravwell.io>
+ *
+ * This software may be modified and distributed under the terms of the
+ * BSD 2-clause license. See the LICENSE file for details.
+ **************************************************************************/
+
+package processors
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/gravwell/gravwell/v3/ingest/config"
+	"github.com/gravwell/gravwell/v3/ingest/entry"
+)
+
+func TestWasmConfig(t *testing.T) {
+	pth := filepath.Join(t.TempDir(), `prog.wasm`)
+	if err := os.WriteFile(pth, []byte{0, 'a', 's', 'm', 1, 0, 0, 0}, 0640); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		cfg  string
+		pass bool
+	}{
+		{`Wasm-Path="` + pth + `"`, true},
+		{`Wasm-Path="` + pth + `"` + "\nCall-Timeout=250ms\nMemory-Limit=32", true},
+		{`Call-Timeout=250ms`, false},
+		{`Wasm-Path="` + pth + `"` + "\nCall-Timeout=soon", false},
+		{`Wasm-Path="` + pth + `"` + "\nCall-Timeout=-1s", false},
+		{`Wasm-Path="/does/not/exist.wasm"`, false},
+	}
+	for i, tt := range tests {
+		b := []byte(fmt.Sprintf("[preprocessor \"w\"]\n\ttype = wasm\n%s\n", tt.cfg))
+		var tc struct {
+			Preprocessor ProcessorConfig
+		}
+		if err := config.LoadConfigBytes(&tc, b); err != nil {
+			t.Fatal(err)
+		}
+		if err := tc.Preprocessor.CheckConfig(`w`); (err == nil) != tt.pass {
+			t.Fatalf("test %d: got %v, expected pass %v", i, err, tt.pass)
+		}
+	}
+}
+
+func TestWasmProcess(t *testing.T) {
+	pth := filepath.Join(t.TempDir(), `guest.wasm`)
+	cmd := exec.Command(`go`, `build`, `-buildmode=c-shared`, `-o`, pth, `./wasm/testdata/guest`)
+	cmd.Env = append(os.Environ(), `GOOS=wasip1`, `GOARCH=wasm`)
+	if b, err := cmd.CombinedOutput(); err != nil {
+		t.Skipf("cannot build the wasm test program: %v %s", err, b)
+	}
+	b := []byte(`
+	[preprocessor "w"]
+		type = wasm
+		Wasm-Path = "` + pth + `"
+		Prefix = "wasm: "
+		Output-Tag = "upper"
+	`)
+	var tc struct {
+		Preprocessor ProcessorConfig
+	}
+	if err := config.LoadConfigBytes(&tc, b); err != nil {
+		t.Fatal(err)
+	}
+	var tt testTagger
+	p, err := tc.Preprocessor.getProcessor(`w`, &tt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tag, err := tt.NegotiateTag(`upper`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	set := make([]*entry.Entry, 64)
+	for i := range set {
+		set[i] = &entry.Entry{TS: entry.Now(), Data: []byte(fmt.Sprintf("test %d", i))}
+	}
+	rset, err := p.Process(set)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(rset) != len(set) {
+		t.Fatalf("return count mismatch: %d != %d", len(rset), len(set))
+	}
+	for i, ent := range rset {
+		if exp := fmt.Sprintf("wasm: TEST %d", i); ent.Tag != tag || string(ent.Data) != exp {
+			t.Fatalf("%d bad entry %q tag %d, expected %q tag %d", i, ent.Data, ent.Tag, exp, tag)
+		}
+	}
+	if ret := p.Flush(); len(ret) != 1 || string(ret[0].Data) != `processed 64` {
+		t.Fatalf("bad flush %v", ret)
+	} else if err = p.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,210 @@
+/*************************************************************************
+ * Copyright 2026 Gravwell, Inc. All rights reserved.
+ * Contact: <legal@gravwell.io>
+ *
+ * This software may be modified and distributed under the terms of the
+ * BSD 2-clause license. See the LICENSE file for details.
+ **************************************************************************/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+	"sync/atomic"
+
+	eventhubs "github.com/Azure/azure-event-hubs-go/v3"
+	"github.com/Azure/azure-event-hubs-go/v3/persist"
+	"github.com/gravwell/gravwell/v3/ingest/entry"
+	"github.com/gravwell/gravwell/v3/ingest/log"
+	"github.com/gravwell/gravwell/v3/timegrinder"
+)
+
+// partitionHub is the part of an event hub the ingester consumes
+type partitionHub interface {
+	partitions(ctx context.Context) ([]string, error)
+	receive(ctx context.Context, partitionID, consumerGroup, offset string, h eventhubs.Handler) (listener, error)
+}
+
+type listener interface {
+	Close(ctx context.Context) error
+}
+
+// azureHub receives from a real hub, the hub writes each partition checkpoint to its offset persister after the handler returns
+type azureHub struct {
+	*eventhubs.Hub
+}
+
+func (h azureHub) partitions(ctx context.Context) ([]string, error) {
+	info, err := h.GetRuntimeInformation(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return info.PartitionIDs, nil
+}
+
+func (h azureHub) receive(ctx context.Context, partitionID, consumerGroup, offset string, handler eventhubs.Handler) (listener, error) {
+	lh, err := h.Receive(ctx, partitionID, handler,
+		eventhubs.ReceiveWithStartingOffset(offset),
+		eventhubs.ReceiveWithConsumerGroup(consumerGroup),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return lh, nil
+}
+
+type entryProcessor interface {
+	ProcessContext(*entry.Entry, context.Context) error
+}
+
+// hubHandler turns events into entries.
+// Partitions are received concurrently, so the counters are atomic and the timegrinder is locked.
+type hubHandler struct {
+	tag   entry.EntryTag
+	src   net.IP
+	proc  entryProcessor
+	pctx  context.Context
+	count uint64
+	size  uint64
+
+	tgMtx     sync.Mutex
+	tg        *timegrinder.TimeGrinder
+	parseTime bool
+}
+
+func (hh *hubHandler) handle(ctx context.Context, msg *eventhubs.Event) error {
+	ent := &entry.Entry{
+		Data: msg.Data,
+		Tag:  hh.tag,
+		SRC:  hh.src,
+		TS:   hh.timestamp(msg),
+	}
+	atomic.AddUint64(&hh.size, uint64(len(msg.Data)))
+	if err := hh.proc.ProcessContext(ent, hh.pctx); err != nil {
+		lg.Error("failed to process entry", log.KVErr(err))
+	}
+	atomic.AddUint64(&hh.count, 1)
+	return nil
+}
+
+// timestamp extracts a timestamp from the event, falling back to the enqueued time.
+// A failed extraction disables time parsing for the rest of the hub.
+func (hh *hubHandler) timestamp(msg *eventhubs.Event) entry.Timestamp {
+	hh.tgMtx.Lock()
+	if hh.parseTime {
+		ts, ok, err := hh.tg.Extract(msg.Data)
+		if ok && err == nil {
+			hh.tgMtx.Unlock()
+			return entry.FromStandard(ts)
+		}
+		hh.parseTime = false
+	}
+	hh.tgMtx.Unlock()
+	if msg.SystemProperties != nil && msg.SystemProperties.EnqueuedTime != nil {
+		return entry.FromStandard(*msg.SystemProperties.EnqueuedTime)
+	}
+	return entry.Now()
+}
+
+type readerInfo struct {
+	namespace     string
+	hub           string
+	consumerGroup string
+	partitionID   string
+}
+
+func (r readerInfo) key() string {
+	return fmt.Sprintf("%s|%s|%s|%s", r.namespace, r.hub, r.consumerGroup, r.partitionID)
+}
+
+// checkpointSync copies partition checkpoints from the in-memory persister handed to the hubs
+// out to disk, only writing checkpoints that moved since the last sync
+type checkpointSync struct {
+	mtx     sync.Mutex
+	mem     persist.CheckpointPersister
+	disk    persist.CheckpointPersister
+	readers []readerInfo
+	last    map[string]persist.Checkpoint
+}
+
+func newCheckpointSync(mem, disk persist.CheckpointPersister) *checkpointSync {
+	return &checkpointSync{
+		mem:  mem,
+		disk: disk,
+		last: make(map[string]persist.Checkpoint),
+	}
+}
+
+func (cs *checkpointSync) add(r readerInfo) {
+	cs.mtx.Lock()
+	cs.readers = append(cs.readers, r)
+	cs.mtx.Unlock()
+}
+
+// startOffset returns where a partition should be read from, the stored checkpoint wins over the configured initial checkpoint
+func (cs *checkpointSync) startOffset(r readerInfo, initial string) string {
+	checkpoint, err := cs.disk.Read(r.namespace, r.hub, r.consumerGroup, r.partitionID)
+	if err != nil {
+		// set a default, we will check user setting next
+		checkpoint = persist.NewCheckpointFromStartOfStream()
+	}
+	if checkpoint.Offset == persist.StartOfStream && initial == "end" {
+		checkpoint = persist.NewCheckpointFromEndOfStream()
+	}
+	return checkpoint.Offset
+}
+
+// sync writes changed checkpoints to disk, force writes all of them
+func (cs *checkpointSync) sync(force bool) {
+	cs.mtx.Lock()
+	defer cs.mtx.Unlock()
+	for _, r := range cs.readers {
+		// read it from the memory persister
+		checkpoint, err := cs.mem.Read(r.namespace, r.hub, r.consumerGroup, r.partitionID)
+		if err != nil {
+			lg.Error("Failed to read checkpoint", log.KVErr(err))
+			continue
+		}
+		// See if it's any different
+		if prev, ok := cs.last[r.key()]; ok && !force && prev.Offset == checkpoint.Offset {
+			continue
+		}
+		cs.last[r.key()] = checkpoint
+		// and write it to disk
+		if err := cs.disk.Write(r.namespace, r.hub, r.consumerGroup, r.partitionID, checkpoint); err != nil {
+			lg.Error("Failed to write checkpoint to disk", log.KVErr(err))
+		}
+	}
+}
+
+// startReceivers launches a listener for each partition in the hub.
+// Calling Receive takes a while, but we can't really parallelize it because the first thing
+// Receive does is lock a mutex in the Hub -- one way or another, it's basically serial.
+func startReceivers(ctx context.Context, hub partitionHub, def eventHubConf, hh *hubHandler, cs *checkpointSync, lg *log.KVLogger) (ls []listener, err error) {
+	// get info about partitions in the hub
+	pids, err := hub.partitions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get runtime info: %w", err)
+	}
+	// config SHOULD have set this, but double check because it's cheap
+	cg := def.Consumer_Group
+	if cg == `` {
+		cg = eventhubs.DefaultConsumerGroup
+	}
+	for _, partitionID := range pids {
+		r := readerInfo{def.Event_Hubs_Namespace, def.Event_Hub, def.Consumer_Group, partitionID}
+		// ask where to start from
+		offset := cs.startOffset(r, def.Initial_Checkpoint)
+		l, err := hub.receive(ctx, partitionID, cg, offset, hh.handle)
+		if err != nil {
+			return ls, fmt.Errorf("failed to start event hub partition receiver: %w", err)
+		}
+		ls = append(ls, l)
+		cs.add(r)
+		lg.Info("started receiver for partition", log.KV("consumer-group", cg), log.KV("partition", partitionID))
+	}
+	return
+}
@@ -0,0 +1,254 @@
+/*************************************************************************
+ * Copyright 2026 Gravwell, Inc. All rights reserved.
+ * Contact: <legal@gravwell.io>
+ *
+ * This software may be modified and distributed under the terms of the
+ * BSD 2-clause license. See the LICENSE file for details.
+ **************************************************************************/
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	eventhubs "github.com/Azure/azure-event-hubs-go/v3"
+	"github.com/Azure/azure-event-hubs-go/v3/persist"
+	"github.com/gravwell/gravwell/v3/ingest/entry"
+	"github.com/gravwell/gravwell/v3/ingest/log"
+	"github.com/gravwell/gravwell/v3/timegrinder"
+)
+
+var baseTime = time.Date(2025, time.March, 4, 5, 6, 7, 0, time.UTC)
+
+// fakeHub delivers events the way the hub client does, checkpointing each one
+// in the offset persister once the handler returns
+type fakeHub struct {
+	sync.Mutex
+	mem       persist.CheckpointPersister
+	namespace string
+	name      string
+	events    map[string][]string // event bodies by partition, the index is the offset
+	started   map[string]string   // the offset each partition was started from
+}
+
+func (fh *fakeHub) partitions(ctx context.Context) (pids []string, err error) {
+	fh.Lock()
+	defer fh.Unlock()
+	for pid := range fh.events {
+		pids = append(pids, pid)
+	}
+	sort.Strings(pids)
+	return
+}
+
+func (fh *fakeHub) receive(ctx context.Context, pid, cg, offset string, h eventhubs.Handler) (listener, error) {
+	fh.Lock()
+	fh.started[pid] = offset
+	evs := fh.events[pid]
+	fh.Unlock()
+	start := 0
+	switch offset {
+	case persist.StartOfStream:
+	case persist.EndOfStream:
+		start = len(evs)
+	default:
+		n, err := strconv.Atoi(offset)
+		if err != nil {
+			return nil, err
+		}
+		start = n + 1
+	}
+	fl := &fakeListener{done: make(chan struct{})}
+	go func() {
+		defer close(fl.done)
+		for i := start; i < len(evs); i++ {
+			seq := int64(i)
+			enq := baseTime.Add(time.Duration(i) * time.Second)
+			ev := &eventhubs.Event{
+				Data:             []byte(evs[i]),
+				SystemProperties: &eventhubs.SystemProperties{SequenceNumber: &seq, EnqueuedTime: &enq, Offset: &seq},
+			}
+			if err := h(ctx, ev); err != nil {
+				return
+			}
+			fh.mem.Write(fh.namespace, fh.name, cg, pid, persist.NewCheckpoint(strconv.Itoa(i), seq, enq))
+		}
+	}()
+	return fl, nil
+}
+
+type fakeListener struct {
+	done chan struct{}
+}
+
+func (fl *fakeListener) Close(ctx context.Context) error {
+	select {
+	case <-fl.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return nil
+}
+
+type capture struct {
+	sync.Mutex
+	ents []*entry.Entry
+}
+
+func (c *capture) ProcessContext(ent *entry.Entry, ctx context.Context) error {
+	c.Lock()
+	c.ents = append(c.ents, ent)
+	c.Unlock()
+	return nil
+}
+
+func (c *capture) byData() map[string]time.Time {
+	c.Lock()
+	defer c.Unlock()
+	r := map[string]time.Time{}
+	for _, ent := range c.ents {
+		r[string(ent.Data)] = ent.TS.StandardTime().UTC()
+	}
+	return r
+}
+
+// consume runs every partition of the hub to completion and syncs the checkpoints to disk
+func consume(t *testing.T, fh *fakeHub, def eventHubConf, disk persist.CheckpointPersister, c *capture) {
+	t.Helper()
+	fh.mem = persist.NewMemoryPersister()
+	cs := newCheckpointSync(fh.mem, disk)
+	hh := &hubHandler{tag: 1, proc: c, pctx: context.Background()}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	ls, err := startReceivers(ctx, fh, def, hh, cs, log.NewLoggerWithKV(lg))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, l := range ls {
+		if err = l.Close(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cs.sync(true)
+}
+
+func readCheckpoint(t *testing.T, pth string) (cp persist.Checkpoint) {
+	t.Helper()
+	bts, err := os.ReadFile(pth)
+	if err != nil {
+		t.Fatal(err)
+	} else if err = json.Unmarshal(bts, &cp); err != nil {
+		t.Fatal(err)
+	}
+	return
+}
+
+func TestAzureEventHubs(t *testing.T) {
+	lg = log.NewDiscardLogger()
+	dir := t.TempDir()
+	disk, err := persist.NewFilePersister(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fh := &fakeHub{
+		namespace: `ns`,
+		name:      `hub`,
+		events: map[string][]string{
+			`0`: {`zero a`, `zero b`, `zero c`},
+			`1`: {`one a`},
+		},
+		started: map[string]string{},
+	}
+	def := eventHubConf{
+		Event_Hubs_Namespace: `ns`,
+		Event_Hub:            `hub`,
+		Consumer_Group:       `$Default`,
+		Initial_Checkpoint:   `start`,
+	}
+	var c capture
+	consume(t, fh, def, disk, &c)
+	got := c.byData()
+	exp := map[string]time.Time{
+		`zero a`: baseTime,
+		`zero b`: baseTime.Add(time.Second),
+		`zero c`: baseTime.Add(2 * time.Second),
+		`one a`:  baseTime,
+	}
+	if len(got) != len(exp) {
+		t.Fatalf("got %v, expected %v", got, exp)
+	}
+	for k, v := range exp {
+		if ts, ok := got[k]; !ok || !ts.Equal(v) {
+			t.Fatalf("%q: got %v expected %v", k, ts, v)
+		}
+	}
+
+	// the file persister names checkpoint files after the hub and partition, dropping the $
+	cp := readCheckpoint(t, filepath.Join(dir, `ns_hub_Default_0`))
+	if cp.Offset != `2` || cp.SequenceNumber != 2 || !cp.EnqueueTime.Equal(baseTime.Add(2*time.Second)) {
+		t.Fatalf("bad checkpoint for partition 0: %+v", cp)
+	}
+	if cp = readCheckpoint(t, filepath.Join(dir, `ns_hub_Default_1`)); cp.Offset != `0` || cp.SequenceNumber != 0 {
+		t.Fatalf("bad checkpoint for partition 1: %+v", cp)
+	}
+
+	// a restart resumes after the stored checkpoints
+	fh.events[`0`] = append(fh.events[`0`], `zero d`)
+	c = capture{}
+	consume(t, fh, def, disk, &c)
+	if fh.started[`0`] != `2` || fh.started[`1`] != `0` {
+		t.Fatalf("restart began at %v", fh.started)
+	}
+	if got = c.byData(); len(got) != 1 || !got[`zero d`].Equal(baseTime.Add(3*time.Second)) {
+		t.Fatalf("restart delivered %v", got)
+	}
+	if cp = readCheckpoint(t, filepath.Join(dir, `ns_hub_Default_0`)); cp.Offset != `3` || cp.SequenceNumber != 3 {
+		t.Fatalf("bad checkpoint after restart: %+v", cp)
+	}
+
+	// without a stored checkpoint Initial-Checkpoint=end skips the backlog
+	def.Consumer_Group = `other`
+	def.Initial_Checkpoint = `end`
+	c = capture{}
+	consume(t, fh, def, disk, &c)
+	if fh.started[`0`] != persist.EndOfStream || len(c.ents) != 0 {
+		t.Fatalf("end checkpoint began at %v and delivered %d entries", fh.started, len(c.ents))
+	}
+}
+
+func TestHubHandlerTimestamps(t *testing.T) {
+	lg = log.NewDiscardLogger()
+	tg, err := timegrinder.NewTimeGrinder(timegrinder.Config{EnableLeftMostSeed: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var c capture
+	hh := &hubHandler{tag: 1, proc: &c, pctx: context.Background(), tg: tg, parseTime: true}
+	enq := baseTime
+	for _, d := range []string{`2024-01-02T03:04:05Z parsed`, `no timestamp`, `2024-01-02T03:04:05Z after`} {
+		if err = hh.handle(context.Background(), &eventhubs.Event{
+			Data:             []byte(d),
+			SystemProperties: &eventhubs.SystemProperties{EnqueuedTime: &enq},
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// a failed extraction falls back to the enqueued time and stops parsing
+	got := c.byData()
+	if !got[`2024-01-02T03:04:05Z parsed`].Equal(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)) ||
+		!got[`no timestamp`].Equal(baseTime) || !got[`2024-01-02T03:04:05Z after`].Equal(baseTime) {
+		t.Fatalf("bad timestamps %v", got)
+	}
+	if atomic.LoadUint64(&hh.count) != 3 {
+		t.Fatalf("bad count %d", hh.count)
+	}
+}
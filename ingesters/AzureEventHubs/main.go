@@ -14,6 +14,7 @@ import (
 	"net"
 	"os"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	// Embed tzdata so that we don't rely on potentially broken timezone DBs on the host
@@ -23,7 +24,6 @@ import (
 	eventhubs "github.com/Azure/azure-event-hubs-go/v3"
 	"github.com/Azure/azure-event-hubs-go/v3/persist"
 	"github.com/gravwell/gravwell/v3/debug"
-	"github.com/gravwell/gravwell/v3/ingest/entry"
 	"github.com/gravwell/gravwell/v3/ingest/log"
 	"github.com/gravwell/gravwell/v3/ingesters/base"
 	"github.com/gravwell/gravwell/v3/ingesters/utils"
@@ -94,9 +94,10 @@ func main() {
 	memPersist := persist.NewMemoryPersister()
 
 	// These are the handlers listening to each individual partition
-	var listeners []*eventhubs.ListenerHandle
+	var listenerMtx sync.Mutex
+	var listeners []listener
 	// this is where we keep track of what we're receiving on
-	var readers []readerInfo
+	cs := newCheckpointSync(memPersist, diskPersist)
 
 	// This little goroutine tries to keep persistence updated in case of catastrophic
 	// failure, without totally smashing the disk like it would if we allowed an update
@@ -106,7 +107,6 @@ func main() {
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		last := make(map[string]persist.Checkpoint)
 		ticker := time.NewTicker(time.Second)
 		defer ticker.Stop()
 		for {
@@ -116,26 +116,7 @@ func main() {
 			case <-quitSig:
 				return
 			case <-ticker.C:
-				for _, r := range readers {
-					// read it from the memory persister
-					checkpoint, err := memPersist.Read(r.namespace, r.hub, r.consumerGroup, r.partitionID)
-					if err != nil {
-						lg.Error("Failed to read checkpoint", log.KVErr(err))
-						continue
-					}
-					// See if it's any different
-					if prev, ok := last[r.key()]; ok {
-						if prev.Offset == checkpoint.Offset {
-							// no change, skip
-							continue
-						}
-					}
-					last[r.key()] = checkpoint
-					// and write it to disk
-					if err := diskPersist.Write(r.namespace, r.hub, r.consumerGroup, r.partitionID, checkpoint); err != nil {
-						lg.Error("Failed to write checkpoint to disk", log.KVErr(err))
-					}
-				}
+				cs.sync(false)
 			}
 		}
 	}()
@@ -181,24 +162,6 @@ func main() {
 			}()
 			lg.Info("connected to event hub")
 
-			// stats stuff
-			var count, size uint64
-			var oldcount, oldsize uint64
-			if debugOn {
-				go func() {
-					for {
-						time.Sleep(1 * time.Second)
-						tmpcount := count
-						tmpsize := size
-						cdiff := tmpcount - oldcount
-						sdiff := tmpsize - oldsize
-						oldcount = tmpcount
-						oldsize = tmpsize
-						lg.Info("ingest stats", log.KV("eps", cdiff), log.KV("bps", sdiff), log.KV("bytes", oldsize))
-					}
-				}()
-			}
-
 			// configure time handling
 			var window timegrinder.TimestampWindow
 			window, err = cfg.Global.GlobalTimestampWindow()
@@ -234,80 +197,41 @@ func main() {
 				}
 			}
 
-			// This function gets called whenever an entry is received from an Events Hub partition.
+			// the handler gets called whenever an entry is received from an Events Hub partition.
 			// It packages the entry, extracts an appropriate timestamp, and sends it to the indexer.
-			callback := func(ctx context.Context, msg *eventhubs.Event) error {
-				ent := &entry.Entry{
-					Data: msg.Data,
-					Tag:  tagid,
-					SRC:  src,
-				}
-				size += uint64(len(msg.Data))
-				if !hubDef.Parse_Time {
-					if msg.SystemProperties != nil && msg.SystemProperties.EnqueuedTime != nil {
-						ent.TS = entry.FromStandard(*msg.SystemProperties.EnqueuedTime)
-					} else {
-						ent.TS = entry.Now()
-					}
-				} else {
-					ts, ok, err := tg.Extract(msg.Data)
-					if !ok || err != nil {
-						//  failed to extract, use the publishtime
-						hubDef.Parse_Time = false
-						if msg.SystemProperties != nil && msg.SystemProperties.EnqueuedTime != nil {
-							ent.TS = entry.FromStandard(*msg.SystemProperties.EnqueuedTime)
-						} else {
-							ent.TS = entry.Now()
-						}
-					} else {
-						ent.TS = entry.FromStandard(ts)
-					}
-				}
-				if err := procset.ProcessContext(ent, exitCtx); err != nil {
-					lg.Error("failed to process entry", log.KVErr(err))
-				}
-				count++
-				return nil
+			hh := &hubHandler{
+				tag:       tagid,
+				src:       src,
+				proc:      procset,
+				pctx:      exitCtx,
+				tg:        tg,
+				parseTime: hubDef.Parse_Time,
 			}
 
-			// get info about partitions in the hub
-			info, err := hub.GetRuntimeInformation(ctx)
-			if err != nil {
-				lg.Fatal("failed to get runtime info", log.KVErr(err))
+			// stats stuff
+			if debugOn {
+				go func() {
+					var oldcount, oldsize uint64
+					for {
+						time.Sleep(1 * time.Second)
+						tmpcount := atomic.LoadUint64(&hh.count)
+						tmpsize := atomic.LoadUint64(&hh.size)
+						cdiff := tmpcount - oldcount
+						sdiff := tmpsize - oldsize
+						oldcount = tmpcount
+						oldsize = tmpsize
+						lg.Info("ingest stats", log.KV("eps", cdiff), log.KV("bps", sdiff), log.KV("bytes", oldsize))
+					}
+				}()
 			}
 
-			// Launch a listener for each partition in the hub
-			// Calling Receive takes a while, but we can't really parallelize it because the first thing
-			// Receive does is lock a mutex in the Hub -- one way or another, it's basically serial.
-			for _, partitionID := range info.PartitionIDs {
-				// ask where to start from
-				checkpoint, err := diskPersist.Read(hubDef.Event_Hubs_Namespace, hubDef.Event_Hub, hubDef.Consumer_Group, partitionID)
-				if err != nil {
-					// set a default, we will check user setting next
-					checkpoint = persist.NewCheckpointFromStartOfStream()
-				}
-				if checkpoint.Offset == persist.StartOfStream && hubDef.Initial_Checkpoint == "end" {
-					checkpoint = persist.NewCheckpointFromEndOfStream()
-				}
-				// config SHOULD have set this, but double check because it's cheap
-				cg := hubDef.Consumer_Group
-				if cg == `` {
-					cg = eventhubs.DefaultConsumerGroup
-				}
-				handle, err := hub.Receive(
-					ctx,
-					partitionID,
-					callback,
-					eventhubs.ReceiveWithStartingOffset(checkpoint.Offset),
-					eventhubs.ReceiveWithConsumerGroup(cg),
-				)
-				if err != nil {
-					lg.Error("failed to start event hub partition receiver", log.KVErr(err))
-					return
-				}
-				listeners = append(listeners, handle)
-				readers = append(readers, readerInfo{hubDef.Event_Hubs_Namespace, hubDef.Event_Hub, hubDef.Consumer_Group, partitionID})
-				lg.Info("started receiver for partition", log.KV("consumer-group", cg), log.KV("partition", partitionID))
+			ls, err := startReceivers(ctx, azureHub{hub}, hubDef, hh, cs, lg)
+			listenerMtx.Lock()
+			listeners = append(listeners, ls...)
+			listenerMtx.Unlock()
+			if err != nil {
+				lg.Error("failed to start event hub", log.KVErr(err))
+				return
 			}
 			<-quitSig
 		}(k, *def)
@@ -320,11 +244,13 @@ func main() {
 	exitFn()
 
 	// Tell every event handler to close
+	listenerMtx.Lock()
 	for _, h := range listeners {
 		cctx, cf := context.WithTimeout(ctx, 2*time.Second)
 		h.Close(cctx)
 		cf()
 	}
+	listenerMtx.Unlock()
 
 	// Tell our goroutines to bail out
 	close(quitSig)
@@ -333,18 +259,7 @@ func main() {
 	lg.Info("all goroutines done")
 
 	// Write out persistence info one last time by hand.
-	for _, r := range readers {
-		// read it from the memory persister
-		checkpoint, err := memPersist.Read(r.namespace, r.hub, r.consumerGroup, r.partitionID)
-		if err != nil {
-			lg.Error("Failed to read checkpoint", log.KVErr(err))
-			continue
-		}
-		// and write it to disk
-		if err := diskPersist.Write(r.namespace, r.hub, r.consumerGroup, r.partitionID, checkpoint); err != nil {
-			lg.Error("Failed to write checkpoint to disk", log.KVErr(err))
-		}
-	}
+	cs.sync(true)
 	lg.Info("state saved, exiting")
 }
 
@@ -353,14 +268,3 @@ func debugout(format string, args ...interface{}) {
 		fmt.Printf(format, args...)
 	}
 }
-
-type readerInfo struct {
-	namespace     string
-	hub           string
-	consumerGroup string
-	partitionID   string
-}
-
-func (r readerInfo) key() string {
-	return fmt.Sprintf("%s|%s|%s|%s", r.namespace, r.hub, r.consumerGroup, r.partitionID)
-}
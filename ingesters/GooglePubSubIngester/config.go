@@ -17,6 +17,7 @@ import (
 	"github.com/gravwell/gravwell/v3/ingest/attach"
 	"github.com/gravwell/gravwell/v3/ingest/config"
 	"github.com/gravwell/gravwell/v3/ingest/processors"
+	"github.com/gravwell/gravwell/v3/ingesters/utils/deadletter"
 )
 
 const (
@@ -33,6 +34,7 @@ type global struct {
 }
 
 type pubsubconf struct {
+	deadletter.Config
 	Topic_Name            string
 	Subscription_Name     string
 	Tag_Name              string
@@ -40,6 +42,8 @@ type pubsubconf struct {
 	Timezone_Override     string
 	Parse_Time            bool
 	Preprocessor          []string
+
+	Dead_Letter_Topic string // topic that exhausted messages are published to
 }
 
 type cfgType struct {
@@ -94,6 +98,9 @@ func (c cfgType) Verify() error {
 		if err := c.Preprocessor.CheckProcessors(v.Preprocessor); err != nil {
 			return fmt.Errorf("pubsub stream %s preprocessor invalid: %v", k, err)
 		}
+		if err := v.Config.Verify(v.Dead_Letter_Topic != ``); err != nil {
+			return fmt.Errorf("pubsub stream %s: %w", k, err)
+		}
 	}
 	return nil
 }
@@ -106,12 +113,14 @@ func (c *cfgType) Tags() ([]string, error) {
 	var tags []string
 	tagMp := make(map[string]bool, 1)
 	for _, v := range c.PubSub {
-		if len(v.Tag_Name) == 0 {
-			continue
-		}
-		if _, ok := tagMp[v.Tag_Name]; !ok {
-			tags = append(tags, v.Tag_Name)
-			tagMp[v.Tag_Name] = true
+		for _, tag := range []string{v.Tag_Name, v.Dead_Letter_Tag} {
+			if len(tag) == 0 {
+				continue
+			}
+			if _, ok := tagMp[tag]; !ok {
+				tags = append(tags, tag)
+				tagMp[tag] = true
+			}
 		}
 	}
 	if len(tags) == 0 {
@@ -0,0 +1,92 @@
+/*************************************************************************
+ * Copyright 2026 Gravwell, Inc. All rights reserved.
+ * Contact: <legal@gravwell.io>
+ *
+ * This software may be modified and distributed under the terms of the
+ * BSD 2-clause license. See the LICENSE file for details.
+ **************************************************************************/
+
+package main
+
+import (
+	"context"
+	"strconv"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/gravwell/gravwell/v3/ingest/log"
+	"github.com/gravwell/gravwell/v3/ingesters/utils/deadletter"
+)
+
+// topicDeadLetter publishes dead lettered messages to a topic, the original attributes
+// are preserved and the failure context is added alongside them
+type topicDeadLetter struct {
+	topic *pubsub.Topic
+}
+
+func newTopicDeadLetter(client *pubsub.Client, name string) deadletter.Forwarder {
+	if name == `` {
+		return nil
+	}
+	return &topicDeadLetter{topic: client.Topic(name)}
+}
+
+func (t *topicDeadLetter) Forward(ctx context.Context, l deadletter.Letter) error {
+	attrs := make(map[string]string, len(l.Attributes)+3)
+	for k, v := range l.Attributes {
+		attrs[k] = v
+	}
+	attrs["gravwell-source"] = l.Source
+	attrs["gravwell-receive-count"] = strconv.Itoa(l.Receives)
+	if l.Error != `` {
+		attrs["gravwell-error"] = l.Error
+	}
+	_, err := t.topic.Publish(ctx, &pubsub.Message{
+		Data:       l.Body,
+		Attributes: attrs,
+	}).Get(ctx)
+	return err
+}
+
+func (t *topicDeadLetter) String() string {
+	return t.topic.String()
+}
+
+// subDeadLetter tracks failing deliveries on a subscription and dead letters them once exhausted
+type subDeadLetter struct {
+	*deadletter.DeadLetter
+	sub *pubsub.Subscription
+	// only consulted when the subscription has no dead letter policy, pubsub does not count deliveries without one
+	counter deadletter.Counter
+}
+
+// failed handles a message that failed processing, acking it if it was dead lettered and nacking it otherwise
+func (sd *subDeadLetter) failed(ctx context.Context, msg *pubsub.Message, perr error) {
+	rc := sd.receives(msg)
+	if ctx.Err() != nil || !sd.Exhausted(rc) {
+		msg.Nack()
+		return
+	}
+	l := deadletter.NewLetter(sd.sub.String(), msg.ID, rc, msg.Data, perr)
+	l.Attributes = msg.Attributes
+	if err := sd.Send(ctx, l); err != nil {
+		lg.Error("failed to dead letter message", log.KV("subscription", sd.sub.ID()), log.KV("receives", rc), log.KVErr(err))
+		msg.Nack()
+		return
+	}
+	lg.Warn("dead lettered message", log.KV("subscription", sd.sub.ID()), log.KV("receives", rc), log.KV("reason", perr))
+	sd.counter.Done(msg.ID)
+	msg.Ack()
+}
+
+// ok acknowledges a message that was processed
+func (sd *subDeadLetter) ok(msg *pubsub.Message) {
+	sd.counter.Done(msg.ID)
+	msg.Ack()
+}
+
+func (sd *subDeadLetter) receives(msg *pubsub.Message) int {
+	if msg.DeliveryAttempt != nil {
+		return *msg.DeliveryAttempt
+	}
+	return sd.counter.Receive(msg.ID)
+}
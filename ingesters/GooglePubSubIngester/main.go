@@ -13,6 +13,7 @@ import (
 	"fmt"
 	"net"
 	"os"
+	"sync/atomic"
 	"time"
 
 	// Embed tzdata so that we don't rely on potentially broken timezone DBs on the host
@@ -23,6 +24,7 @@ import (
 	"github.com/gravwell/gravwell/v3/ingest/log"
 	"github.com/gravwell/gravwell/v3/ingesters/base"
 	"github.com/gravwell/gravwell/v3/ingesters/utils"
+	"github.com/gravwell/gravwell/v3/ingesters/utils/deadletter"
 	"github.com/gravwell/gravwell/v3/timegrinder"
 
 	"cloud.google.com/go/pubsub"
@@ -87,8 +89,16 @@ func main() {
 		return
 	}
 
+	replay, err := parseReplayFlags()
+	if err != nil {
+		lg.Fatal("invalid replay request", log.KVErr(err))
+	}
+
+	lt := newLagTracker()
+	go lt.Report(exitCtx, igst)
+
 	var ok bool
-	for _, psv := range cfg.PubSub {
+	for name, psv := range cfg.PubSub {
 		tagid, err := igst.GetTag(psv.Tag_Name)
 		if err != nil {
 			lg.Fatal("failed to resolve tag", log.KV("tag", psv.Tag_Name), log.KVErr(err))
@@ -131,6 +141,26 @@ func main() {
 			}
 		}
 
+		// an operator requested replay rewinds the subscription, everything published after the start is redelivered
+		var rw *replayWindow
+		if replay != nil && replayRequested(name) {
+			w := *replay
+			w.SeekAt = time.Now()
+			if err = sub.SeekToTime(ctx, w.Start); err != nil {
+				lg.Error("failed to seek subscription for replay", log.KV("subscription", subname), log.KV("start", w.Start), log.KVErr(err))
+			} else {
+				lg.Info("replaying subscription", log.KV("subscription", subname), log.KV("start", w.Start), log.KV("end", w.End))
+				rw = &w
+			}
+		}
+
+		var sd *subDeadLetter
+		if dl, err := deadletter.New(psv.Config, igst, newTopicDeadLetter(client, psv.Dead_Letter_Topic)); err != nil {
+			lg.Fatal("dead letter failure", log.KV("subscription", subname), log.KVErr(err))
+		} else if dl != nil {
+			sd = &subDeadLetter{DeadLetter: dl, sub: sub}
+		}
+
 		var count, size uint64
 		var oldcount, oldsize uint64
 
@@ -139,8 +169,8 @@ func main() {
 			go func() {
 				for {
 					time.Sleep(1 * time.Second)
-					tmpcount := count
-					tmpsize := size
+					tmpcount := atomic.LoadUint64(&count)
+					tmpsize := atomic.LoadUint64(&size)
 					cdiff := tmpcount - oldcount
 					sdiff := tmpsize - oldsize
 					oldcount = tmpcount
@@ -150,14 +180,14 @@ func main() {
 			}()
 		}
 
-		go func(sub *pubsub.Subscription, tagid entry.EntryTag, ps *pubsubconf) {
+		go func(sub *pubsub.Subscription, tagid entry.EntryTag, ps *pubsubconf, rw *replayWindow, sd *subDeadLetter) {
 			eChan := make(chan *entry.Entry, 2048)
 			go func(c chan *entry.Entry) {
 				for e := range c {
 					if err := procset.ProcessContext(e, exitCtx); err != nil {
 						lg.Error("failed to process entry", log.KVErr(err))
 					}
-					count++
+					atomic.AddUint64(&count, 1)
 				}
 				if err := procset.Close(); err != nil {
 					lg.Error("failed to close processor", log.KVErr(err))
@@ -196,39 +226,29 @@ func main() {
 				}
 			}
 
+			sr := &subReceiver{
+				sub:   sub,
+				tag:   tagid,
+				src:   src,
+				ps:    ps,
+				tg:    tg,
+				rw:    rw,
+				lt:    lt,
+				eChan: eChan,
+				proc:  procset,
+				pctx:  exitCtx,
+				sd:    sd,
+				count: &count,
+				size:  &size,
+			}
 			cctx, cancel := context.WithCancel(ctx)
 			defer cancel()
 			for {
-				callback := func(ctx context.Context, msg *pubsub.Message) {
-					ent := &entry.Entry{
-						Data: msg.Data,
-						Tag:  tagid,
-						SRC:  src,
-					}
-					size += uint64(len(msg.Data))
-					if !ps.Parse_Time {
-						ent.TS = entry.FromStandard(msg.PublishTime)
-					} else {
-						ts, ok, err := tg.Extract(msg.Data)
-						if !ok || err != nil {
-							// failed to extract, use the publishtime
-							ps.Parse_Time = false
-							ent.TS = entry.FromStandard(msg.PublishTime)
-						} else {
-							ent.TS = entry.FromStandard(ts)
-						}
-					}
-					select {
-					case eChan <- ent:
-						msg.Ack()
-					case <-ctx.Done():
-					}
-				}
-				if err := sub.Receive(cctx, callback); err != nil {
+				if err := sub.Receive(cctx, sr.receive); err != nil {
 					lg.Error("receive failed", log.KVErr(err))
 				}
 			}
-		}(sub, tagid, psv)
+		}(sub, tagid, psv, rw, sd)
 	}
 
 	//register quit signals so we can die gracefully
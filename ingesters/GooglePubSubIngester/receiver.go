@@ -0,0 +1,87 @@
+/*************************************************************************
+ * Copyright 2026 Gravwell, Inc. All rights reserved.
+ * Contact: <legal@gravwell.io>
+ *
+ * This software may be modified and distributed under the terms of the
+ * BSD 2-clause license. See the LICENSE file for details.
+ **************************************************************************/
+
+package main
+
+import (
+	"context"
+	"net"
+	"sync"
+	"sync/atomic"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/gravwell/gravwell/v3/ingest/entry"
+	"github.com/gravwell/gravwell/v3/ingest/log"
+	"github.com/gravwell/gravwell/v3/timegrinder"
+)
+
+type entryProcessor interface {
+	ProcessContext(*entry.Entry, context.Context) error
+}
+
+// subReceiver turns messages delivered on a subscription into entries.
+// Receive runs the callback concurrently, so the counters are atomic and the timegrinder is locked.
+type subReceiver struct {
+	sub   *pubsub.Subscription
+	tag   entry.EntryTag
+	src   net.IP
+	ps    *pubsubconf
+	tgMtx sync.Mutex
+	tg    *timegrinder.TimeGrinder
+	rw    *replayWindow
+	lt    *lagTracker
+	eChan chan *entry.Entry
+
+	// messages are processed inline when dead lettering so failures can be retried
+	proc  entryProcessor
+	pctx  context.Context
+	sd    *subDeadLetter
+	count *uint64
+	size  *uint64
+}
+
+// receive is the subscription Receive callback
+func (sr *subReceiver) receive(ctx context.Context, msg *pubsub.Message) {
+	sr.lt.Update(sr.sub.ID(), msg.PublishTime, sr.rw.replaying(msg.PublishTime))
+	ent := &entry.Entry{
+		Data: msg.Data,
+		Tag:  sr.tag,
+		SRC:  sr.src,
+	}
+	atomic.AddUint64(sr.size, uint64(len(msg.Data)))
+	sr.tgMtx.Lock()
+	if !sr.ps.Parse_Time {
+		ent.TS = entry.FromStandard(msg.PublishTime)
+	} else {
+		ts, ok, err := sr.tg.Extract(msg.Data)
+		if !ok || err != nil {
+			// failed to extract, use the publishtime
+			sr.ps.Parse_Time = false
+			ent.TS = entry.FromStandard(msg.PublishTime)
+		} else {
+			ent.TS = entry.FromStandard(ts)
+		}
+	}
+	sr.tgMtx.Unlock()
+	if sr.sd != nil {
+		// process inline so a failure can be retried and eventually dead lettered
+		if err := sr.proc.ProcessContext(ent, sr.pctx); err != nil {
+			lg.Error("failed to process entry", log.KVErr(err))
+			sr.sd.failed(sr.pctx, msg, err)
+			return
+		}
+		atomic.AddUint64(sr.count, 1)
+		sr.sd.ok(msg)
+		return
+	}
+	select {
+	case sr.eChan <- ent:
+		msg.Ack()
+	case <-ctx.Done():
+	}
+}
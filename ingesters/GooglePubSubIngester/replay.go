@@ -0,0 +1,155 @@
+/*************************************************************************
+ * Copyright 2026 Gravwell, Inc. All rights reserved.
+ * Contact: <legal@gravwell.io>
+ *
+ * This software may be modified and distributed under the terms of the
+ * BSD 2-clause license. See the LICENSE file for details.
+ **************************************************************************/
+
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gravwell/gravwell/v3/ingest"
+	"github.com/gravwell/gravwell/v3/ingest/log"
+)
+
+const (
+	lagReportInterval = 10 * time.Second
+)
+
+var (
+	fReplayStart         = flag.String("replay-start", "", "Seek subscriptions back to this RFC3339 time and re-ingest everything published since")
+	fReplayEnd           = flag.String("replay-end", "", "End of the replay time range, messages published after it are still redelivered and ingested")
+	fReplaySubscriptions = flag.String("replay-subscriptions", "", "Comma separated PubSub config names to replay, defaults to all")
+)
+
+// replayWindow is an operator requested time range to re-ingest.  Seeking a subscription
+// marks everything published after Start as unacknowledged and Pub/Sub cannot seek to a range,
+// so messages published between End and the moment of the seek are redelivered as well.
+// Those may have been acknowledged before the seek or may still have been backlogged, there is
+// no way to tell which, so they are ingested again rather than risk dropping unseen data.
+type replayWindow struct {
+	Start  time.Time
+	End    time.Time // zero means the replay runs up to the seek
+	SeekAt time.Time
+}
+
+// replaying returns true if a message was published within the replay window
+func (rw *replayWindow) replaying(published time.Time) bool {
+	if rw == nil || published.Before(rw.Start) {
+		return false
+	} else if !rw.End.IsZero() {
+		return !published.After(rw.End)
+	}
+	return published.Before(rw.SeekAt)
+}
+
+// parseReplayFlags returns nil if no replay was requested
+func parseReplayFlags() (*replayWindow, error) {
+	if *fReplayStart == `` {
+		if *fReplayEnd != `` || *fReplaySubscriptions != `` {
+			return nil, errors.New("-replay-start is required when requesting a replay")
+		}
+		return nil, nil
+	}
+	var rw replayWindow
+	var err error
+	if rw.Start, err = time.Parse(time.RFC3339, *fReplayStart); err != nil {
+		return nil, fmt.Errorf("invalid -replay-start: %w", err)
+	}
+	if *fReplayEnd != `` {
+		if rw.End, err = time.Parse(time.RFC3339, *fReplayEnd); err != nil {
+			return nil, fmt.Errorf("invalid -replay-end: %w", err)
+		} else if !rw.End.After(rw.Start) {
+			return nil, errors.New("-replay-end must be after -replay-start")
+		}
+	}
+	if rw.Start.After(time.Now()) {
+		return nil, errors.New("-replay-start is in the future")
+	}
+	return &rw, nil
+}
+
+// replayRequested returns true if the named subscription config should be replayed
+func replayRequested(name string) bool {
+	if *fReplaySubscriptions == `` {
+		return true
+	}
+	for _, v := range strings.Split(*fReplaySubscriptions, ",") {
+		if strings.EqualFold(strings.TrimSpace(v), name) {
+			return true
+		}
+	}
+	return false
+}
+
+// subscriptionLag is the per subscription state reported in the ingester metadata
+type subscriptionLag struct {
+	LagMillis   int64 // time between publishing and receipt of the most recent message
+	Messages    uint64
+	LastPublish time.Time
+	Replaying   bool `json:",omitempty"`
+	Updated     time.Time
+}
+
+// pubsubMetadata is attached to the IngesterState so lag can be monitored from the indexer
+type pubsubMetadata struct {
+	Subscriptions map[string]subscriptionLag
+}
+
+type lagTracker struct {
+	sync.Mutex
+	md    pubsubMetadata
+	dirty bool
+}
+
+func newLagTracker() *lagTracker {
+	return &lagTracker{
+		md: pubsubMetadata{Subscriptions: map[string]subscriptionLag{}},
+	}
+}
+
+func (lt *lagTracker) Update(sub string, published time.Time, replaying bool) {
+	now := time.Now()
+	lt.Lock()
+	defer lt.Unlock()
+	sl := lt.md.Subscriptions[sub]
+	sl.Messages++
+	sl.Replaying = replaying
+	sl.Updated = now
+	if published.After(sl.LastPublish) || replaying {
+		sl.LastPublish = published
+		sl.LagMillis = now.Sub(published).Milliseconds()
+	}
+	lt.md.Subscriptions[sub] = sl
+	lt.dirty = true
+}
+
+// Report pushes the lag state into the ingester metadata until the context is canceled
+func (lt *lagTracker) Report(ctx context.Context, igst *ingest.IngestMuxer) {
+	tckr := time.NewTicker(lagReportInterval)
+	defer tckr.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-tckr.C:
+		}
+		lt.Lock()
+		if lt.dirty {
+			if err := igst.SetMetadata(lt.md); err != nil {
+				lg.Warn("failed to set ingester metadata", log.KVErr(err))
+			}
+			lt.dirty = false
+		}
+		lt.Unlock()
+	}
+}
@@ -0,0 +1,166 @@
+/*************************************************************************
+ * Copyright 2026 Gravwell, Inc. All rights reserved.
+ * Contact: <legal@gravwell.io>
+ *
+ * This software may be modified and distributed under the terms of the
+ * BSD 2-clause license. See the LICENSE file for details.
+ **************************************************************************/
+
+package main
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/pubsub"
+	"cloud.google.com/go/pubsub/apiv1/pubsubpb"
+	"cloud.google.com/go/pubsub/pstest"
+	"github.com/gravwell/gravwell/v3/ingest/entry"
+	"github.com/gravwell/gravwell/v3/ingest/log"
+)
+
+// seekReactor intercepts Seek on the fake, which drops message bodies when it redelivers,
+// so the test can redeliver acknowledged messages itself
+type seekReactor struct {
+	sync.Mutex
+	target time.Time
+}
+
+func (sr *seekReactor) React(req interface{}) (bool, interface{}, error) {
+	if r, ok := req.(*pubsubpb.SeekRequest); ok {
+		sr.Lock()
+		sr.target = r.GetTime().AsTime()
+		sr.Unlock()
+	}
+	return true, &pubsubpb.SeekResponse{}, nil
+}
+
+func TestReplayIngestsBacklogAfterEnd(t *testing.T) {
+	const topicName = `projects/test/topics/logs`
+	lg = log.NewDiscardLogger()
+	seeks := &seekReactor{}
+	srv := pstest.NewServer(pstest.ServerReactorOption{FuncName: "Seek", Reactor: seeks})
+	defer srv.Close()
+	t.Setenv("PUBSUB_EMULATOR_HOST", srv.Addr)
+
+	var clockMtx sync.Mutex
+	var clock time.Time
+	srv.SetTimeNowFunc(func() time.Time {
+		clockMtx.Lock()
+		defer clockMtx.Unlock()
+		if clock.IsZero() {
+			return time.Now()
+		}
+		return clock
+	})
+	publish := func(data string, at time.Time) string {
+		clockMtx.Lock()
+		clock = at
+		clockMtx.Unlock()
+		defer func() {
+			clockMtx.Lock()
+			clock = time.Time{}
+			clockMtx.Unlock()
+		}()
+		return srv.Publish(topicName, []byte(data), nil)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	client, err := pubsub.NewClient(ctx, `test`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	// the fake only retains messages for 10 minutes
+	t0 := time.Now().Add(-8 * time.Minute)
+	rw := &replayWindow{Start: t0, End: t0.Add(3 * time.Minute)}
+	publish(`init`, t0) // creates the topic
+	sub, err := client.CreateSubscription(ctx, `logs`, pubsub.SubscriptionConfig{Topic: client.Topic(`logs`)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := []string{
+		publish(`A`, t0.Add(time.Minute)),
+		publish(`B`, t0.Add(2*time.Minute)),
+		publish(`C`, t0.Add(4*time.Minute)),
+	}
+
+	// A and B are inside the window, C is after the end but was ingested before the replay
+	rctx, rcancel := context.WithCancel(ctx)
+	var acked int32
+	err = sub.Receive(rctx, func(_ context.Context, msg *pubsub.Message) {
+		msg.Ack()
+		if atomic.AddInt32(&acked, 1) == int32(len(ids)) {
+			rcancel()
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range ids {
+		for srv.Message(id).Acks == 0 {
+			if ctx.Err() != nil {
+				t.Fatal("messages were not acknowledged")
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+
+	// D is after the end and was still backlogged when the replay was requested
+	publish(`D`, t0.Add(5*time.Minute))
+
+	rw.SeekAt = time.Now()
+	if err = sub.SeekToTime(ctx, rw.Start); err != nil {
+		t.Fatal(err)
+	}
+	seeks.Lock()
+	target := seeks.target
+	seeks.Unlock()
+	if !target.Equal(rw.Start) {
+		t.Fatalf("bad seek target %v != %v", target, rw.Start)
+	}
+	// redeliver acknowledged messages published after the target the way Pub/Sub does
+	for _, m := range srv.Messages() {
+		if m.Acks > 0 && !m.PublishTime.Before(target) {
+			publish(string(m.Data), m.PublishTime)
+		}
+	}
+
+	var count, size uint64
+	sr := &subReceiver{
+		sub:   sub,
+		ps:    &pubsubconf{},
+		rw:    rw,
+		lt:    newLagTracker(),
+		eChan: make(chan *entry.Entry, 16),
+		count: &count,
+		size:  &size,
+	}
+	rctx, rcancel = context.WithCancel(ctx)
+	defer rcancel()
+	go sub.Receive(rctx, sr.receive)
+
+	got := map[string]bool{}
+	for len(got) < 4 {
+		select {
+		case ent := <-sr.eChan:
+			got[string(ent.Data)] = true
+		case <-ctx.Done():
+			t.Fatalf("missing redelivered messages, got %v", got)
+		}
+	}
+	for _, v := range []string{`A`, `B`, `C`, `D`} {
+		if !got[v] {
+			t.Fatalf("message %s was not ingested: %v", v, got)
+		}
+	}
+
+	if !rw.replaying(t0.Add(2*time.Minute)) || rw.replaying(t0.Add(5*time.Minute)) || rw.replaying(t0.Add(-time.Minute)) {
+		t.Fatal("bad replay window")
+	}
+}
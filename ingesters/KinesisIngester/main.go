@@ -21,15 +21,12 @@ import (
 	_ "time/tzdata"
 
 	"github.com/gravwell/gravwell/v3/debug"
-	"github.com/gravwell/gravwell/v3/ingest/entry"
 	"github.com/gravwell/gravwell/v3/ingest/log"
 	"github.com/gravwell/gravwell/v3/ingesters/base"
 	"github.com/gravwell/gravwell/v3/ingesters/utils"
 	"github.com/gravwell/gravwell/v3/sqs_common"
-	"github.com/gravwell/gravwell/v3/timegrinder"
 
 	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/kinesis"
 )
@@ -50,7 +47,6 @@ func main() {
 
 	var wg sync.WaitGroup
 	var cfg *cfgType
-	running := true
 
 	ibc := base.IngesterBaseConfig{
 		IngesterName:                 appName,
@@ -94,11 +90,19 @@ func main() {
 		lg.Fatal("obtaining credentials", log.KVErr(err))
 	}
 
-	dieChan := make(chan bool)
+	replay, err := parseReplayFlags()
+	if err != nil {
+		lg.Fatal("invalid replay request", log.KVErr(err))
+	}
 
+	// readCtx stops reading from kinesis, ctx stops processing; the gap lets in flight entries drain
+	readCtx, readCancel := context.WithCancel(context.Background())
 	ctx, cancel := context.WithCancel(context.Background())
 
-	for _, stream := range cfg.KinesisStream {
+	lt := newLagTracker()
+	go lt.Report(readCtx, igst)
+
+	for name, stream := range cfg.KinesisStream {
 		tagid, err := igst.GetTag(stream.Tag_Name)
 		if err != nil {
 			lg.Fatal("failed to resolve tag", log.KV("tag", stream.Tag_Name), log.KV("stream", stream.Stream_Name), log.KVErr(err))
@@ -117,51 +121,47 @@ func main() {
 		// get a handle on kinesis
 		svc := kinesis.New(sess, aws.NewConfig().WithRegion(stream.Region))
 
-		// Get the list of shards
-		shards := []*kinesis.Shard{}
-		dsi := &kinesis.DescribeStreamInput{}
-		dsi.SetStreamName(stream.Stream_Name)
-		count := 0
-		for {
-			streamdesc, err := svc.DescribeStream(dsi)
-			if err != nil {
-				count++
-				lg.Error("failed to get stream description", log.KV("stream", stream.Stream_Name), log.KVErr(err))
-				if count >= 5 {
-					// give up and LOUDLY quit
-					lg.Fatal("giving up fetch stream description for stream after 5 attempts, exiting.", log.KV("stream", stream.Stream_Name))
-				}
-				time.Sleep(1 * time.Second)
-				continue
+		var src net.IP
+		if cfg.Global.Source_Override != `` {
+			// global override
+			src = net.ParseIP(cfg.Global.Source_Override)
+			if src == nil {
+				lg.Fatal("Global Source-Override is invalid")
 			}
-			newshards := streamdesc.StreamDescription.Shards
-			shards = append(shards, newshards...)
-			if *streamdesc.StreamDescription.HasMoreShards {
-				dsi.SetExclusiveStartShardId(*(newshards[len(newshards)-1].ShardId))
+		}
+
+		sr := newStreamReader(*stream, svc, tagid, src, cfg, igst, stateMan, lt, readCtx, ctx, &wg)
+		if err = sr.Start(); err != nil {
+			lg.Fatal("giving up fetch stream description for stream, exiting.", log.KV("stream", stream.Stream_Name), log.KVErr(err))
+		}
+
+		// replays run alongside the normal readers and exit when they have covered the window
+		if replay != nil && replayRequested(name) {
+			rr := newStreamReader(*stream, svc, tagid, src, cfg, igst, stateMan, lt, readCtx, ctx, &wg)
+			rr.replay = replay
+			lg.Info("starting replay", log.KV("stream", stream.Stream_Name), log.KV("start", replay.Start), log.KV("end", replay.End))
+			if err = rr.Start(); err != nil {
+				lg.Error("failed to start replay", log.KV("stream", stream.Stream_Name), log.KVErr(err))
 			} else {
-				break
+				go func(rr *streamReader) {
+					select {
+					case <-rr.Done():
+						lg.Info("replay complete", log.KV("stream", rr.def.Stream_Name))
+					case <-readCtx.Done():
+					}
+				}(rr)
 			}
 		}
-		debugout("Read %d shards from stream %s\n", len(shards), stream.Stream_Name)
 
 		// Now start up the metrics reporter
-		var metricsTrackers []*shardMetrics
 		if stream.Metrics_Interval > 0 {
-			go func(stream streamDef) {
+			go func(stream streamDef, sr *streamReader) {
 				for {
 					select {
-					case <-dieChan:
+					case <-readCtx.Done():
 						return
 					case <-time.After(time.Duration(stream.Metrics_Interval) * time.Second):
-						report := metricsReport{StreamName: stream.Stream_Name, ShardCount: len(shards)}
-						for i := range metricsTrackers {
-							l, b, e, r := metricsTrackers[i].ReadAndReset()
-							report.AverageLag += l
-							report.CompressedDataSize += b
-							report.EntryDataSize += e
-							report.KinesisRequests += r
-						}
-						report.AverageLag = report.AverageLag / int64(len(metricsTrackers))
+						report := sr.metrics()
 						if stream.JSON_Metrics {
 							jr, err := json.Marshal(report)
 							if err == nil {
@@ -170,7 +170,7 @@ func main() {
 						} else {
 							lg.Info("stream stats",
 								log.KV("stream", stream.Stream_Name),
-								log.KV("shards", len(shards)),
+								log.KV("shards", report.ShardCount),
 								log.KV("delay", report.AverageLag),
 								log.KV("compressedsize", report.CompressedDataSize),
 								log.KV("requestcount", report.KinesisRequests),
@@ -178,185 +178,14 @@ func main() {
 						}
 					}
 				}
-			}(*stream)
-		}
-
-		for i, shard := range shards {
-			// Detect and skip closed shards
-			if shard.SequenceNumberRange != nil && shard.SequenceNumberRange.EndingSequenceNumber != nil {
-				lg.Info("shard appears closed, skipping", log.KV("shard", *shard.ShardId), log.KV("stream", stream.Stream_Name))
-				continue
-			}
-			//get timegrinder stood up
-			var window timegrinder.TimestampWindow
-			window, err = cfg.Global.GlobalTimestampWindow()
-			if err != nil {
-				return
-			}
-			tcfg := timegrinder.Config{
-				TSWindow:           window,
-				EnableLeftMostSeed: true,
-			}
-			tgr, err := timegrinder.NewTimeGrinder(tcfg)
-			if err != nil {
-				lg.FatalCode(0, "failed to create timegrinder", log.KVErr(err))
-				return
-			} else if err := cfg.TimeFormat.LoadFormats(tgr); err != nil {
-				lg.FatalCode(0, "failed to load custom time formats", log.KVErr(err))
-				return
-			}
-			if stream.Assume_Local_Timezone {
-				tgr.SetLocalTime()
-			}
-			if stream.Timezone_Override != `` {
-				if err = tgr.SetTimezone(stream.Timezone_Override); err != nil {
-					lg.FatalCode(0, "failed to set timezone", log.KV("timezone", stream.Timezone_Override), log.KVErr(err))
-					return
-				}
-			}
-
-			wg.Add(1)
-			go func(stream streamDef, shard kinesis.Shard, tagid entry.EntryTag, shardid int, tg *timegrinder.TimeGrinder) {
-				defer wg.Done()
-				// set up timegrinder and other long-lived stuff
-				var src net.IP
-				if cfg.Global.Source_Override != `` {
-					// global override
-					src = net.ParseIP(cfg.Global.Source_Override)
-					if src == nil {
-						lg.Fatal("Global Source-Override is invalid")
-					}
-				}
-
-				// one processor set per shard
-				procset, err := cfg.Preprocessor.ProcessorSet(igst, stream.Preprocessor)
-				if err != nil {
-					lg.Fatal("preprocessor construction error", log.KVErr(err))
-				}
-
-				// make the shardMetrics and add it to the array
-				tracker := shardMetrics{}
-				metricsTrackers = append(metricsTrackers, &tracker)
-				if stream.Metrics_Interval == 0 {
-					// disable it
-					tracker.Disabled = true
-				}
-
-			reconnectLoop:
-				for {
-					gsii := &kinesis.GetShardIteratorInput{}
-					gsii.SetShardId(*shard.ShardId)
-					gsii.SetStreamName(stream.Stream_Name)
-					seqnum := stateMan.GetSequenceNum(stream.Stream_Name, *shard.ShardId)
-					if seqnum == `` {
-						// we don't have a previous state
-						debugout("No previous sequence number for stream %v shard %v, defaulting to %v\n", stream.Stream_Name, *shard.ShardId, stream.Iterator_Type)
-						gsii.SetShardIteratorType(stream.Iterator_Type)
-					} else {
-						gsii.SetShardIteratorType(`AFTER_SEQUENCE_NUMBER`)
-						gsii.SetStartingSequenceNumber(seqnum)
-					}
-
-					output, err := svc.GetShardIterator(gsii)
-					if err != nil {
-						lg.Error("error on shard", log.KV("number", shardid), log.KV("stream", stream.Stream_Name), log.KV("shard", *shard.ShardId), log.KVErr(err))
-						time.Sleep(5 * time.Second)
-						continue
-					}
-					if output.ShardIterator == nil {
-						// this is weird, we are going to bail out
-						lg.Error("got nil initial shard iterator, sleeping and retrying")
-						time.Sleep(5 * time.Second)
-						continue
-					}
-					iter := *output.ShardIterator
-
-					var lastSeqNum string
-					for running {
-						gri := &kinesis.GetRecordsInput{}
-						gri.SetLimit(5000)
-						gri.SetShardIterator(iter)
-						var res *kinesis.GetRecordsOutput
-						var err error
-						for {
-							res, err = svc.GetRecords(gri)
-							if res != nil {
-								if res.NextShardIterator != nil {
-									iter = *res.NextShardIterator
-								}
-							}
-							if err != nil {
-								if awsErr, ok := err.(awserr.Error); ok {
-									// process SDK error
-									if awsErr.Code() == kinesis.ErrCodeProvisionedThroughputExceededException {
-										lg.Warn("throughput exceeded, trying again", log.KV("shard", *shard.ShardId), log.KV("stream", stream.Stream_Name))
-										time.Sleep(500 * time.Millisecond)
-									} else if awsErr.Code() == kinesis.ErrCodeExpiredIteratorException {
-										lg.Info("Iterator expired, re-initializing", log.KV("shard", *shard.ShardId), log.KV("stream", stream.Stream_Name))
-										time.Sleep(100 * time.Millisecond)
-										continue reconnectLoop
-									} else {
-										lg.Error("answer error", log.KV("code", awsErr.Code()), log.KV("message", awsErr.Message()), log.KV("shard", *shard.ShardId), log.KV("stream", stream.Stream_Name))
-										time.Sleep(500 * time.Millisecond)
-									}
-								} else {
-									lg.Error("unknown error", log.KVErr(err))
-								}
-							} else {
-								// if we got no records, chill for a sec before we hit it again
-								if len(res.Records) == 0 {
-									time.Sleep(100 * time.Millisecond)
-								}
-								break
-							}
-						}
-
-						var entrySize int
-						for _, r := range res.Records {
-							lastSeqNum = *r.SequenceNumber
-							ent := &entry.Entry{
-								Tag:  tagid,
-								SRC:  src,
-								Data: r.Data,
-							}
-							if !stream.Parse_Time {
-								ent.TS = entry.FromStandard(*r.ApproximateArrivalTimestamp)
-							} else {
-								ts, ok, err := tg.Extract(ent.Data)
-								if !ok || err != nil {
-									// something went wrong, switch to using kinesis timestamps
-									stream.Parse_Time = false
-									ent.TS = entry.FromStandard(*r.ApproximateArrivalTimestamp)
-								} else {
-									ent.TS = entry.FromStandard(ts)
-								}
-							}
-							if err = procset.ProcessContext(ent, ctx); err != nil {
-								lg.Error("Failed to handle entry", log.KVErr(err))
-							}
-							entrySize += int(ent.Size())
-						}
-						tracker.Update(res, entrySize)
-						// Now update the most recent sequence number
-						if lastSeqNum != `` {
-							stateMan.UpdateSequenceNum(stream.Stream_Name, *shard.ShardId, lastSeqNum)
-						}
-					}
-					if err = procset.Close(); err != nil {
-						lg.Error("Failed to close processor set", log.KVErr(err))
-					}
-					// if we get to this point, exit the for loop
-					break
-				}
-			}(*stream, *shard, tagid, i, tgr)
+			}(*stream, sr)
 		}
 	}
 
 	utils.WaitForQuit()
 	ib.AnnounceShutdown()
 
-	running = false
-	close(dieChan)
+	readCancel()
 
 	go func() {
 		time.Sleep(time.Second)
@@ -396,7 +225,9 @@ func (s *shardMetrics) Update(res *kinesis.GetRecordsOutput, entrySize int) {
 	if s.Disabled {
 		return
 	}
-	s.millisbehind = *res.MillisBehindLatest
+	if res.MillisBehindLatest != nil {
+		s.millisbehind = *res.MillisBehindLatest
+	}
 	s.requests++
 	var dsize int
 	for i := range res.Records {
@@ -0,0 +1,62 @@
+/*************************************************************************
+ * Copyright 2026 Gravwell, Inc. All rights reserved.
+ * Contact: <legal@gravwell.io>
+ *
+ * This software may be modified and distributed under the terms of the
+ * BSD 2-clause license. See the LICENSE file for details.
+ **************************************************************************/
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	fReplayStart   = flag.String("replay-start", "", "Re-ingest records that arrived at or after this RFC3339 time, normal ingest continues alongside the replay")
+	fReplayEnd     = flag.String("replay-end", "", "Stop the replay at this RFC3339 time, defaults to catching up to the present")
+	fReplayStreams = flag.String("replay-streams", "", "Comma separated KinesisStream config names to replay, defaults to all streams")
+)
+
+// parseReplayFlags returns nil if no replay was requested
+func parseReplayFlags() (*replayWindow, error) {
+	if *fReplayStart == `` {
+		if *fReplayEnd != `` || *fReplayStreams != `` {
+			return nil, errors.New("-replay-start is required when requesting a replay")
+		}
+		return nil, nil
+	}
+	var rw replayWindow
+	var err error
+	if rw.Start, err = time.Parse(time.RFC3339, *fReplayStart); err != nil {
+		return nil, fmt.Errorf("invalid -replay-start: %w", err)
+	}
+	if *fReplayEnd != `` {
+		if rw.End, err = time.Parse(time.RFC3339, *fReplayEnd); err != nil {
+			return nil, fmt.Errorf("invalid -replay-end: %w", err)
+		} else if !rw.End.After(rw.Start) {
+			return nil, errors.New("-replay-end must be after -replay-start")
+		}
+	}
+	if rw.Start.After(time.Now()) {
+		return nil, errors.New("-replay-start is in the future")
+	}
+	return &rw, nil
+}
+
+// replayRequested returns true if the named stream config should be replayed
+func replayRequested(name string) bool {
+	if *fReplayStreams == `` {
+		return true
+	}
+	for _, v := range strings.Split(*fReplayStreams, ",") {
+		if strings.EqualFold(strings.TrimSpace(v), name) {
+			return true
+		}
+	}
+	return false
+}
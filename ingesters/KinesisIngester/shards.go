@@ -0,0 +1,617 @@
+/*************************************************************************
+ * Copyright 2026 Gravwell, Inc. All rights reserved.
+ * Contact: <legal@gravwell.io>
+ *
+ * This software may be modified and distributed under the terms of the
+ * BSD 2-clause license. See the LICENSE file for details.
+ **************************************************************************/
+
+package main
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gravwell/gravwell/v3/ingest"
+	"github.com/gravwell/gravwell/v3/ingest/entry"
+	"github.com/gravwell/gravwell/v3/ingest/log"
+	"github.com/gravwell/gravwell/v3/timegrinder"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/kinesis"
+)
+
+const (
+	// shardEndMarker is stored in place of a sequence number once a closed shard has been completely read
+	shardEndMarker = `SHARD_END`
+
+	shardRefreshInterval = time.Minute
+	lagReportInterval    = 10 * time.Second
+	describeRetries      = 5
+)
+
+var errIteratorExpired = errors.New("shard iterator expired")
+
+// replayWindow is an operator requested time range to re-ingest, a zero End means "until caught up"
+type replayWindow struct {
+	Start time.Time
+	End   time.Time
+}
+
+/*
+streamReader manages the shards of a single stream.  Kinesis splits and merges shards by closing
+the parents and creating children; to preserve ordering a child is not read until all of its
+parents have been read to the end.  The stream is periodically re-described so that children
+created while we are running are picked up.
+
+A streamReader with a replay window reads every shard from the start of the window to the end
+and never touches the checkpointed sequence numbers.
+*/
+type streamReader struct {
+	def    streamDef
+	svc    *kinesis.Kinesis
+	tag    entry.EntryTag
+	src    net.IP
+	cfg    *cfgType
+	igst   *ingest.IngestMuxer
+	sm     *stateman
+	lag    *lagTracker
+	replay *replayWindow
+	ctx    context.Context // canceled when we should stop reading
+	pctx   context.Context // canceled when we should stop processing
+	wg     *sync.WaitGroup
+
+	mtx      sync.Mutex
+	shards   map[string]*kinesis.Shard
+	initial  map[string]bool // shards that existed when we started
+	active   map[string]bool
+	finished map[string]bool
+	skipped  map[string]bool // closed shards that were never read
+	trackers map[string]*shardMetrics
+	done     chan struct{}        // closed when a replay completes
+	run      func(*kinesis.Shard) // reads a shard, swapped out by tests
+}
+
+func newStreamReader(def streamDef, svc *kinesis.Kinesis, tag entry.EntryTag, src net.IP, cfg *cfgType, igst *ingest.IngestMuxer, sm *stateman, lt *lagTracker, ctx, pctx context.Context, wg *sync.WaitGroup) *streamReader {
+	sr := &streamReader{
+		def:      def,
+		svc:      svc,
+		tag:      tag,
+		src:      src,
+		cfg:      cfg,
+		igst:     igst,
+		sm:       sm,
+		lag:      lt,
+		ctx:      ctx,
+		pctx:     pctx,
+		wg:       wg,
+		shards:   map[string]*kinesis.Shard{},
+		initial:  map[string]bool{},
+		active:   map[string]bool{},
+		finished: map[string]bool{},
+		skipped:  map[string]bool{},
+		trackers: map[string]*shardMetrics{},
+		done:     make(chan struct{}),
+	}
+	sr.run = sr.shardRoutine
+	return sr
+}
+
+// listShards describes the stream, retrying a few times before giving up
+func (sr *streamReader) listShards() (shards []*kinesis.Shard, err error) {
+	dsi := &kinesis.DescribeStreamInput{}
+	dsi.SetStreamName(sr.def.Stream_Name)
+	var count int
+	for {
+		var streamdesc *kinesis.DescribeStreamOutput
+		if streamdesc, err = sr.svc.DescribeStreamWithContext(sr.ctx, dsi); err != nil {
+			if count++; count >= describeRetries || sr.ctx.Err() != nil {
+				return
+			}
+			lg.Error("failed to get stream description", log.KV("stream", sr.def.Stream_Name), log.KVErr(err))
+			time.Sleep(time.Second)
+			continue
+		}
+		newshards := streamdesc.StreamDescription.Shards
+		shards = append(shards, newshards...)
+		if streamdesc.StreamDescription.HasMoreShards == nil || !*streamdesc.StreamDescription.HasMoreShards || len(newshards) == 0 {
+			break
+		}
+		dsi.SetExclusiveStartShardId(*(newshards[len(newshards)-1].ShardId))
+	}
+	return
+}
+
+// Start performs the initial shard listing and kicks off readers for every shard that is ready
+func (sr *streamReader) Start() error {
+	shards, err := sr.listShards()
+	if err != nil {
+		return err
+	}
+	debugout("Read %d shards from stream %s\n", len(shards), sr.def.Stream_Name)
+	sr.mtx.Lock()
+	sr.addShardsLocked(shards, true)
+	sr.scheduleLocked()
+	sr.mtx.Unlock()
+
+	if sr.replay == nil {
+		sr.wg.Add(1)
+		go sr.refreshRoutine()
+	}
+	return nil
+}
+
+// Done returns a channel that is closed once a replay has read every shard
+func (sr *streamReader) Done() <-chan struct{} {
+	return sr.done
+}
+
+func (sr *streamReader) refreshRoutine() {
+	defer sr.wg.Done()
+	tckr := time.NewTicker(shardRefreshInterval)
+	defer tckr.Stop()
+	for {
+		select {
+		case <-sr.ctx.Done():
+			return
+		case <-tckr.C:
+		}
+		shards, err := sr.listShards()
+		if err != nil {
+			lg.Error("failed to refresh shard list", log.KV("stream", sr.def.Stream_Name), log.KVErr(err))
+			continue
+		}
+		sr.mtx.Lock()
+		sr.addShardsLocked(shards, false)
+		sr.scheduleLocked()
+		sr.mtx.Unlock()
+	}
+}
+
+// addShardsLocked records shards we have not seen before, initial shards are those listed at startup.
+// The caller must hold the lock.
+func (sr *streamReader) addShardsLocked(shards []*kinesis.Shard, initial bool) {
+	for _, s := range shards {
+		id := *s.ShardId
+		if _, ok := sr.shards[id]; ok {
+			continue
+		}
+		if !initial {
+			lg.Info("discovered new shard", log.KV("stream", sr.def.Stream_Name), log.KV("shard", id),
+				log.KV("parent", shardParents(s)))
+		}
+		sr.shards[id] = s
+		sr.initial[id] = initial
+		if sr.replay == nil && sr.sm.GetSequenceNum(sr.def.Stream_Name, id) == shardEndMarker {
+			sr.finished[id] = true
+		}
+	}
+}
+
+func shardParents(s *kinesis.Shard) (r []string) {
+	if s.ParentShardId != nil && *s.ParentShardId != `` {
+		r = append(r, *s.ParentShardId)
+	}
+	if s.AdjacentParentShardId != nil && *s.AdjacentParentShardId != `` {
+		r = append(r, *s.AdjacentParentShardId)
+	}
+	return
+}
+
+// parentsDoneLocked returns true if every parent we know about has been read to the end,
+// parents that have aged out of the stream are not waited on.
+func (sr *streamReader) parentsDoneLocked(s *kinesis.Shard) bool {
+	for _, p := range shardParents(s) {
+		if _, known := sr.shards[p]; known && !sr.finished[p] {
+			return false
+		}
+	}
+	return true
+}
+
+// parentReadLocked returns true if we read or checkpointed any parent of the shard, which makes it
+// a child whose records must be read from the start to continue where the parent left off.
+// Parents that were skipped because they were closed before we ever read them do not count.
+func (sr *streamReader) parentReadLocked(s *kinesis.Shard) bool {
+	for _, p := range shardParents(s) {
+		if _, known := sr.shards[p]; known && !sr.skipped[p] {
+			return true
+		} else if sr.sm.GetSequenceNum(sr.def.Stream_Name, p) != `` {
+			return true // aged out of the stream, but we had read it
+		}
+	}
+	return false
+}
+
+// scheduleLocked starts a reader for every shard that is not running, not finished, and whose parents are done.
+// The caller must hold the lock.
+func (sr *streamReader) scheduleLocked() {
+	ids := make([]string, 0, len(sr.shards))
+	for id := range sr.shards {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	// skipping a closed shard can make its children ready, so keep going until nothing changes
+	for changed := true; changed; {
+		changed = false
+		for _, id := range ids {
+			s := sr.shards[id]
+			if sr.active[id] || sr.finished[id] || !sr.parentsDoneLocked(s) {
+				continue
+			}
+			if sr.replay == nil && sr.initial[id] && shardClosed(s) && sr.def.Iterator_Type == kinesis.ShardIteratorTypeLatest &&
+				sr.sm.GetSequenceNum(sr.def.Stream_Name, id) == `` && !sr.parentReadLocked(s) {
+				// there is nothing "latest" in a closed shard we have never read
+				lg.Info("shard appears closed, skipping", log.KV("shard", id), log.KV("stream", sr.def.Stream_Name))
+				sr.finished[id] = true
+				sr.skipped[id] = true
+				changed = true
+				continue
+			}
+			sr.active[id] = true
+			sr.wg.Add(1)
+			go sr.run(s)
+		}
+	}
+	if sr.replay != nil && len(sr.active) == 0 {
+		select {
+		case <-sr.done:
+		default:
+			close(sr.done)
+		}
+	}
+}
+
+func shardClosed(s *kinesis.Shard) bool {
+	return s.SequenceNumberRange != nil && s.SequenceNumberRange.EndingSequenceNumber != nil
+}
+
+func (sr *streamReader) shardRoutine(s *kinesis.Shard) {
+	defer sr.wg.Done()
+	sr.shardDone(*s.ShardId, sr.readShard(s))
+}
+
+// shardDone clears a shard reader that exited, a finished shard is checkpointed and its children scheduled
+func (sr *streamReader) shardDone(id string, finished bool) {
+	sr.mtx.Lock()
+	defer sr.mtx.Unlock()
+	delete(sr.active, id)
+	delete(sr.trackers, id)
+	sr.lag.Remove(sr.def.Stream_Name, id, sr.replay != nil)
+	if !finished {
+		return
+	}
+	sr.finished[id] = true
+	if sr.replay == nil {
+		lg.Info("finished reading closed shard", log.KV("stream", sr.def.Stream_Name), log.KV("shard", id))
+		sr.sm.UpdateSequenceNum(sr.def.Stream_Name, id, shardEndMarker)
+	}
+	// children may now be ready
+	sr.scheduleLocked()
+}
+
+// iteratorInput determines where a shard reader should start.
+// Only root shards that existed when we started use the configured iterator type, children of
+// shards we read start at the beginning so nothing written between the split and now is lost.
+func (sr *streamReader) iteratorInput(s *kinesis.Shard, lastSeq string) *kinesis.GetShardIteratorInput {
+	id := *s.ShardId
+	sr.mtx.Lock()
+	root := sr.initial[id] && !sr.parentReadLocked(s)
+	sr.mtx.Unlock()
+	gsii := &kinesis.GetShardIteratorInput{}
+	gsii.SetShardId(id)
+	gsii.SetStreamName(sr.def.Stream_Name)
+	if lastSeq != `` {
+		gsii.SetShardIteratorType(kinesis.ShardIteratorTypeAfterSequenceNumber)
+		gsii.SetStartingSequenceNumber(lastSeq)
+	} else if sr.replay != nil {
+		gsii.SetShardIteratorType(kinesis.ShardIteratorTypeAtTimestamp)
+		gsii.SetTimestamp(sr.replay.Start)
+	} else if root {
+		// we don't have a previous state
+		debugout("No previous sequence number for stream %v shard %v, defaulting to %v\n", sr.def.Stream_Name, id, sr.def.Iterator_Type)
+		gsii.SetShardIteratorType(sr.def.Iterator_Type)
+	} else {
+		// children of shards we have read and shards created while we are running, start at the beginning
+		gsii.SetShardIteratorType(kinesis.ShardIteratorTypeTrimHorizon)
+	}
+	return gsii
+}
+
+func (sr *streamReader) newTimegrinder() (*timegrinder.TimeGrinder, error) {
+	window, err := sr.cfg.Global.GlobalTimestampWindow()
+	if err != nil {
+		return nil, err
+	}
+	tgr, err := timegrinder.NewTimeGrinder(timegrinder.Config{
+		TSWindow:           window,
+		EnableLeftMostSeed: true,
+	})
+	if err != nil {
+		return nil, err
+	} else if err = sr.cfg.TimeFormat.LoadFormats(tgr); err != nil {
+		return nil, err
+	}
+	if sr.def.Assume_Local_Timezone {
+		tgr.SetLocalTime()
+	}
+	if sr.def.Timezone_Override != `` {
+		if err = tgr.SetTimezone(sr.def.Timezone_Override); err != nil {
+			return nil, err
+		}
+	}
+	return tgr, nil
+}
+
+// sleep waits for the duration, returning true if we are shutting down
+func (sr *streamReader) sleep(d time.Duration) bool {
+	select {
+	case <-sr.ctx.Done():
+		return true
+	case <-time.After(d):
+	}
+	return false
+}
+
+// getRecords fetches the next batch, retrying throttling and transient errors
+func (sr *streamReader) getRecords(id, iter string) (*kinesis.GetRecordsOutput, error) {
+	gri := &kinesis.GetRecordsInput{}
+	gri.SetLimit(5000)
+	gri.SetShardIterator(iter)
+	for {
+		res, err := sr.svc.GetRecordsWithContext(sr.ctx, gri)
+		if err == nil {
+			return res, nil
+		} else if sr.ctx.Err() != nil {
+			return nil, sr.ctx.Err()
+		}
+		if awsErr, ok := err.(awserr.Error); ok {
+			// process SDK error
+			if awsErr.Code() == kinesis.ErrCodeProvisionedThroughputExceededException {
+				lg.Warn("throughput exceeded, trying again", log.KV("shard", id), log.KV("stream", sr.def.Stream_Name))
+			} else if awsErr.Code() == kinesis.ErrCodeExpiredIteratorException {
+				lg.Info("Iterator expired, re-initializing", log.KV("shard", id), log.KV("stream", sr.def.Stream_Name))
+				return nil, errIteratorExpired
+			} else {
+				lg.Error("answer error", log.KV("code", awsErr.Code()), log.KV("message", awsErr.Message()), log.KV("shard", id), log.KV("stream", sr.def.Stream_Name))
+			}
+		} else {
+			lg.Error("unknown error", log.KV("shard", id), log.KV("stream", sr.def.Stream_Name), log.KVErr(err))
+		}
+		if sr.sleep(500 * time.Millisecond) {
+			return nil, sr.ctx.Err()
+		}
+	}
+}
+
+// readShard reads a shard until it ends, the replay window is complete, or we are shutting down.
+// It returns true if the shard (or the replay of it) is complete.
+func (sr *streamReader) readShard(s *kinesis.Shard) (complete bool) {
+	id := *s.ShardId
+	name := sr.def.Stream_Name
+	tg, err := sr.newTimegrinder()
+	if err != nil {
+		lg.FatalCode(0, "failed to create timegrinder", log.KV("stream", name), log.KVErr(err))
+	}
+	parseTime := sr.def.Parse_Time
+
+	// one processor set per shard
+	procset, err := sr.cfg.Preprocessor.ProcessorSet(sr.igst, sr.def.Preprocessor)
+	if err != nil {
+		lg.Fatal("preprocessor construction error", log.KVErr(err))
+	}
+	defer func() {
+		if err := procset.Close(); err != nil {
+			lg.Error("Failed to close processor set", log.KVErr(err))
+		}
+	}()
+
+	tracker := &shardMetrics{Disabled: sr.def.Metrics_Interval == 0}
+	sr.mtx.Lock()
+	sr.trackers[id] = tracker
+	sr.mtx.Unlock()
+
+	var lastSeq string
+	if sr.replay == nil {
+		lastSeq = sr.sm.GetSequenceNum(name, id)
+	} else {
+		lg.Info("replaying shard", log.KV("stream", name), log.KV("shard", id), log.KV("start", sr.replay.Start), log.KV("end", sr.replay.End))
+	}
+
+reconnectLoop:
+	for {
+		output, err := sr.svc.GetShardIteratorWithContext(sr.ctx, sr.iteratorInput(s, lastSeq))
+		if err != nil {
+			lg.Error("error on shard", log.KV("stream", name), log.KV("shard", id), log.KVErr(err))
+			if sr.sleep(5 * time.Second) {
+				return false
+			}
+			continue
+		}
+		if output.ShardIterator == nil {
+			// this is weird, we are going to bail out
+			lg.Error("got nil initial shard iterator, sleeping and retrying", log.KV("stream", name), log.KV("shard", id))
+			if sr.sleep(5 * time.Second) {
+				return false
+			}
+			continue
+		}
+		iter := output.ShardIterator
+
+		// a nil next iterator means the shard has been closed and we have read all of it
+		for iter != nil {
+			if sr.ctx.Err() != nil {
+				return false
+			}
+			res, err := sr.getRecords(id, *iter)
+			if err == errIteratorExpired {
+				time.Sleep(100 * time.Millisecond)
+				continue reconnectLoop
+			} else if err != nil {
+				return false
+			}
+			iter = res.NextShardIterator
+
+			var entrySize int
+			var pastEnd bool
+			for _, r := range res.Records {
+				if sr.replay != nil && !sr.replay.End.IsZero() && r.ApproximateArrivalTimestamp != nil && r.ApproximateArrivalTimestamp.After(sr.replay.End) {
+					pastEnd = true
+					break
+				}
+				lastSeq = *r.SequenceNumber
+				ent := &entry.Entry{
+					Tag:  sr.tag,
+					SRC:  sr.src,
+					Data: r.Data,
+				}
+				if !parseTime {
+					ent.TS = entry.FromStandard(*r.ApproximateArrivalTimestamp)
+				} else {
+					ts, ok, err := tg.Extract(ent.Data)
+					if !ok || err != nil {
+						// something went wrong, switch to using kinesis timestamps
+						parseTime = false
+						ent.TS = entry.FromStandard(*r.ApproximateArrivalTimestamp)
+					} else {
+						ent.TS = entry.FromStandard(ts)
+					}
+				}
+				if err = procset.ProcessContext(ent, sr.pctx); err != nil {
+					lg.Error("Failed to handle entry", log.KVErr(err))
+				}
+				entrySize += int(ent.Size())
+			}
+			tracker.Update(res, entrySize)
+			sr.lag.Update(name, id, sr.replay != nil, res.MillisBehindLatest, lastSeq)
+			if sr.replay == nil {
+				// Now update the most recent sequence number
+				if lastSeq != `` {
+					sr.sm.UpdateSequenceNum(name, id, lastSeq)
+				}
+			} else if pastEnd {
+				return true
+			} else if sr.replay.End.IsZero() && len(res.Records) == 0 && res.MillisBehindLatest != nil && *res.MillisBehindLatest == 0 {
+				// open ended replays stop once they have caught up
+				return true
+			}
+			// if we got no records, chill for a sec before we hit it again
+			if len(res.Records) == 0 && iter != nil {
+				if sr.sleep(100 * time.Millisecond) {
+					return false
+				}
+			}
+		}
+		return true
+	}
+}
+
+// metrics reads and resets the metrics of every active shard
+func (sr *streamReader) metrics() (report metricsReport) {
+	sr.mtx.Lock()
+	defer sr.mtx.Unlock()
+	report.StreamName = sr.def.Stream_Name
+	report.ShardCount = len(sr.active)
+	for _, t := range sr.trackers {
+		l, b, e, r := t.ReadAndReset()
+		report.AverageLag += l
+		report.CompressedDataSize += b
+		report.EntryDataSize += e
+		report.KinesisRequests += r
+	}
+	if len(sr.trackers) > 0 {
+		report.AverageLag = report.AverageLag / int64(len(sr.trackers))
+	}
+	return
+}
+
+// shardLag is the per shard state reported in the ingester metadata
+type shardLag struct {
+	MillisBehindLatest int64
+	LastSequenceNumber string `json:",omitempty"`
+	Updated            time.Time
+}
+
+// kinesisMetadata is attached to the IngesterState so lag can be monitored from the indexer
+type kinesisMetadata struct {
+	Streams map[string]map[string]shardLag // stream name to shard ID
+	Replays map[string]map[string]shardLag `json:",omitempty"` // replays in progress
+}
+
+type lagTracker struct {
+	sync.Mutex
+	md    kinesisMetadata
+	dirty bool
+}
+
+func newLagTracker() *lagTracker {
+	return &lagTracker{
+		md: kinesisMetadata{
+			Streams: map[string]map[string]shardLag{},
+			Replays: map[string]map[string]shardLag{},
+		},
+	}
+}
+
+func (lt *lagTracker) set(replay bool) map[string]map[string]shardLag {
+	if replay {
+		return lt.md.Replays
+	}
+	return lt.md.Streams
+}
+
+func (lt *lagTracker) Update(stream, shard string, replay bool, millis *int64, seq string) {
+	if millis == nil {
+		return
+	}
+	lt.Lock()
+	defer lt.Unlock()
+	mp := lt.set(replay)
+	if _, ok := mp[stream]; !ok {
+		mp[stream] = map[string]shardLag{}
+	}
+	mp[stream][shard] = shardLag{
+		MillisBehindLatest: *millis,
+		LastSequenceNumber: seq,
+		Updated:            time.Now(),
+	}
+	lt.dirty = true
+}
+
+func (lt *lagTracker) Remove(stream, shard string, replay bool) {
+	lt.Lock()
+	defer lt.Unlock()
+	mp := lt.set(replay)
+	if sm, ok := mp[stream]; ok {
+		delete(sm, shard)
+		if len(sm) == 0 {
+			delete(mp, stream)
+		}
+		lt.dirty = true
+	}
+}
+
+// Report pushes the lag state into the ingester metadata until the context is canceled
+func (lt *lagTracker) Report(ctx context.Context, igst *ingest.IngestMuxer) {
+	tckr := time.NewTicker(lagReportInterval)
+	defer tckr.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-tckr.C:
+		}
+		lt.Lock()
+		if lt.dirty {
+			if err := igst.SetMetadata(lt.md); err != nil {
+				lg.Warn("failed to set ingester metadata", log.KVErr(err))
+			}
+			lt.dirty = false
+		}
+		lt.Unlock()
+	}
+}
@@ -0,0 +1,319 @@
+/*************************************************************************
+ * Copyright 2026 Gravwell, Inc. All rights reserved.
+ * Contact: <legal@gravwell.io>
+ *
+ * This software may be modified and distributed under the terms of the
+ * BSD 2-clause license. See the LICENSE file for details.
+ **************************************************************************/
+
+package main
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/kinesis"
+	"github.com/gravwell/gravwell/v3/ingest/log"
+)
+
+const testStream = `stream`
+
+// testShard builds a shard, closed shards have an ending sequence number
+func testShard(id string, closed bool, parents ...string) *kinesis.Shard {
+	s := &kinesis.Shard{
+		ShardId:             aws.String(id),
+		SequenceNumberRange: &kinesis.SequenceNumberRange{StartingSequenceNumber: aws.String(`1`)},
+	}
+	if closed {
+		s.SequenceNumberRange.EndingSequenceNumber = aws.String(`100`)
+	}
+	if len(parents) > 0 {
+		s.ParentShardId = aws.String(parents[0])
+	}
+	if len(parents) > 1 {
+		s.AdjacentParentShardId = aws.String(parents[1])
+	}
+	return s
+}
+
+// testReader is a stream reader whose shard readers only record that they were started
+type testReader struct {
+	*streamReader
+	startMtx sync.Mutex
+	started  []string
+}
+
+func newTestReader(iterType string, replay *replayWindow, checkpoints map[string]string) *testReader {
+	lg = log.NewDiscardLogger()
+	sm := &stateman{states: map[string]map[string]string{testStream: {}}}
+	for k, v := range checkpoints {
+		sm.states[testStream][k] = v
+	}
+	def := streamDef{Stream_Name: testStream, Iterator_Type: iterType}
+	tr := &testReader{
+		streamReader: newStreamReader(def, nil, 0, nil, nil, nil, sm, newLagTracker(), context.Background(), context.Background(), &sync.WaitGroup{}),
+	}
+	tr.replay = replay
+	tr.run = func(s *kinesis.Shard) {
+		defer tr.wg.Done()
+		tr.startMtx.Lock()
+		tr.started = append(tr.started, *s.ShardId)
+		tr.startMtx.Unlock()
+	}
+	return tr
+}
+
+// add registers shards and schedules readers the way Start and the refresh routine do
+func (tr *testReader) add(initial bool, shards ...*kinesis.Shard) {
+	tr.mtx.Lock()
+	tr.addShardsLocked(shards, initial)
+	tr.scheduleLocked()
+	tr.mtx.Unlock()
+}
+
+// starts returns the shards started since the last call
+func (tr *testReader) starts() []string {
+	tr.wg.Wait()
+	tr.startMtx.Lock()
+	defer tr.startMtx.Unlock()
+	r := tr.started
+	tr.started = nil
+	sort.Strings(r)
+	return r
+}
+
+func (tr *testReader) expectStarts(t *testing.T, exp ...string) {
+	t.Helper()
+	if got := tr.starts(); !reflect.DeepEqual(got, exp) && (len(got) != 0 || len(exp) != 0) {
+		t.Fatalf("started %v, expected %v", got, exp)
+	}
+}
+
+func TestScheduleSplit(t *testing.T) {
+	tr := newTestReader(kinesis.ShardIteratorTypeLatest, nil, map[string]string{`parent`: `50`})
+	tr.add(true, testShard(`parent`, true), testShard(`a`, false, `parent`), testShard(`b`, false, `parent`))
+	// the children wait for the parent
+	tr.expectStarts(t, `parent`)
+	tr.shardDone(`parent`, true)
+	tr.expectStarts(t, `a`, `b`)
+	if seq := tr.sm.GetSequenceNum(testStream, `parent`); seq != shardEndMarker {
+		t.Fatalf("parent checkpoint is %q", seq)
+	}
+	// a reader that exits early leaves the shard to be restarted and does not release children
+	tr.shardDone(`a`, false)
+	tr.expectStarts(t)
+	if tr.finished[`a`] || tr.active[`a`] {
+		t.Fatal("unfinished shard marked finished")
+	}
+}
+
+func TestScheduleMerge(t *testing.T) {
+	tr := newTestReader(kinesis.ShardIteratorTypeLatest, nil, map[string]string{`a`: `10`, `b`: `10`})
+	tr.add(true, testShard(`a`, true), testShard(`b`, true), testShard(`merged`, false, `a`, `b`))
+	tr.expectStarts(t, `a`, `b`)
+	tr.shardDone(`a`, true)
+	tr.expectStarts(t) // still waiting on the adjacent parent
+	tr.shardDone(`b`, true)
+	tr.expectStarts(t, `merged`)
+}
+
+func TestScheduleRestart(t *testing.T) {
+	// the parent was finished before the restart, the child picks up immediately
+	tr := newTestReader(kinesis.ShardIteratorTypeLatest, nil, map[string]string{`parent`: shardEndMarker})
+	tr.add(true, testShard(`parent`, true), testShard(`child`, false, `parent`))
+	tr.expectStarts(t, `child`)
+
+	// children created while running are scheduled when they are discovered
+	tr.add(false, testShard(`parent`, true), testShard(`child`, true, `parent`), testShard(`grandchild`, false, `child`))
+	tr.expectStarts(t)
+	tr.shardDone(`child`, true)
+	tr.expectStarts(t, `grandchild`)
+}
+
+func TestScheduleSkipClosed(t *testing.T) {
+	// nothing has been read, closed shards hold nothing "latest" and are skipped
+	tr := newTestReader(kinesis.ShardIteratorTypeLatest, nil, nil)
+	tr.add(true, testShard(`old`, true), testShard(`split`, true, `old`), testShard(`open`, false, `split`))
+	tr.expectStarts(t, `open`)
+	if !tr.finished[`old`] || !tr.finished[`split`] {
+		t.Fatal("closed shards not skipped")
+	}
+
+	// a closed child of a shard we read is not skipped, it holds what was written after the split
+	tr = newTestReader(kinesis.ShardIteratorTypeLatest, nil, map[string]string{`parent`: shardEndMarker})
+	tr.add(true, testShard(`parent`, true), testShard(`child`, true, `parent`), testShard(`grandchild`, false, `child`))
+	tr.expectStarts(t, `child`)
+
+	// TRIM_HORIZON reads closed shards
+	tr = newTestReader(kinesis.ShardIteratorTypeTrimHorizon, nil, nil)
+	tr.add(true, testShard(`old`, true), testShard(`open`, false, `old`))
+	tr.expectStarts(t, `old`)
+}
+
+func TestScheduleReplay(t *testing.T) {
+	// replays ignore the checkpoints and read every shard in order
+	tr := newTestReader(kinesis.ShardIteratorTypeLatest, &replayWindow{Start: time.Now()}, map[string]string{`parent`: shardEndMarker})
+	tr.add(true, testShard(`parent`, true), testShard(`child`, false, `parent`))
+	tr.expectStarts(t, `parent`)
+	tr.shardDone(`parent`, true)
+	tr.expectStarts(t, `child`)
+	select {
+	case <-tr.Done():
+		t.Fatal("replay done with a shard still active")
+	default:
+	}
+	tr.shardDone(`child`, true)
+	select {
+	case <-tr.Done():
+	default:
+		t.Fatal("replay not done")
+	}
+	if seq := tr.sm.GetSequenceNum(testStream, `child`); seq != `` {
+		t.Fatalf("replay touched the checkpoint: %q", seq)
+	}
+}
+
+func TestParentsDone(t *testing.T) {
+	tr := newTestReader(kinesis.ShardIteratorTypeLatest, nil, map[string]string{`done`: shardEndMarker})
+	tr.addShardsLocked([]*kinesis.Shard{testShard(`done`, true), testShard(`reading`, true)}, true)
+	tests := []struct {
+		name    string
+		parents []string
+		exp     bool
+	}{
+		{`root`, nil, true},
+		{`aged out parent`, []string{`gone`}, true},
+		{`finished parent`, []string{`done`}, true},
+		{`unfinished parent`, []string{`reading`}, false},
+		{`finished and aged out`, []string{`done`, `gone`}, true},
+		{`unfinished adjacent parent`, []string{`done`, `reading`}, false},
+	}
+	for _, tc := range tests {
+		if got := tr.parentsDoneLocked(testShard(`x`, false, tc.parents...)); got != tc.exp {
+			t.Errorf("%s: got %v", tc.name, got)
+		}
+	}
+}
+
+func TestIteratorInput(t *testing.T) {
+	replayStart := time.Date(2025, time.May, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		iter    string
+		replay  *replayWindow
+		cps     map[string]string
+		initial []*kinesis.Shard
+		later   []*kinesis.Shard
+		shard   string
+		lastSeq string
+		exp     string
+	}{
+		{
+			name:    `checkpoint`,
+			iter:    kinesis.ShardIteratorTypeLatest,
+			initial: []*kinesis.Shard{testShard(`s`, false)},
+			shard:   `s`, lastSeq: `42`,
+			exp: kinesis.ShardIteratorTypeAfterSequenceNumber,
+		},
+		{
+			name:    `replay`,
+			iter:    kinesis.ShardIteratorTypeLatest,
+			replay:  &replayWindow{Start: replayStart},
+			initial: []*kinesis.Shard{testShard(`s`, false)},
+			shard:   `s`,
+			exp:     kinesis.ShardIteratorTypeAtTimestamp,
+		},
+		{
+			name:    `root`,
+			iter:    kinesis.ShardIteratorTypeLatest,
+			initial: []*kinesis.Shard{testShard(`s`, false)},
+			shard:   `s`,
+			exp:     kinesis.ShardIteratorTypeLatest,
+		},
+		{
+			name:    `child of a shard finished before startup`,
+			iter:    kinesis.ShardIteratorTypeLatest,
+			cps:     map[string]string{`parent`: shardEndMarker},
+			initial: []*kinesis.Shard{testShard(`parent`, true), testShard(`child`, false, `parent`)},
+			shard:   `child`,
+			exp:     kinesis.ShardIteratorTypeTrimHorizon,
+		},
+		{
+			name:    `child of a shard read after startup`,
+			iter:    kinesis.ShardIteratorTypeLatest,
+			cps:     map[string]string{`parent`: `50`},
+			initial: []*kinesis.Shard{testShard(`parent`, true), testShard(`child`, false, `parent`)},
+			shard:   `child`,
+			exp:     kinesis.ShardIteratorTypeTrimHorizon,
+		},
+		{
+			name:    `merge with one parent read`,
+			iter:    kinesis.ShardIteratorTypeLatest,
+			cps:     map[string]string{`b`: shardEndMarker},
+			initial: []*kinesis.Shard{testShard(`b`, true), testShard(`merged`, false, `gone`, `b`)},
+			shard:   `merged`,
+			exp:     kinesis.ShardIteratorTypeTrimHorizon,
+		},
+		{
+			name:    `checkpointed parent aged out`,
+			iter:    kinesis.ShardIteratorTypeLatest,
+			cps:     map[string]string{`gone`: `50`},
+			initial: []*kinesis.Shard{testShard(`child`, false, `gone`)},
+			shard:   `child`,
+			exp:     kinesis.ShardIteratorTypeTrimHorizon,
+		},
+		{
+			name:    `unread parent aged out`,
+			iter:    kinesis.ShardIteratorTypeLatest,
+			initial: []*kinesis.Shard{testShard(`child`, false, `gone`)},
+			shard:   `child`,
+			exp:     kinesis.ShardIteratorTypeLatest,
+		},
+		{
+			name:    `child of a skipped shard`,
+			iter:    kinesis.ShardIteratorTypeLatest,
+			initial: []*kinesis.Shard{testShard(`old`, true), testShard(`child`, false, `old`)},
+			shard:   `child`,
+			exp:     kinesis.ShardIteratorTypeLatest,
+		},
+		{
+			name:    `discovered while running`,
+			iter:    kinesis.ShardIteratorTypeLatest,
+			initial: []*kinesis.Shard{testShard(`s`, false)},
+			later:   []*kinesis.Shard{testShard(`new`, false)},
+			shard:   `new`,
+			exp:     kinesis.ShardIteratorTypeTrimHorizon,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tr := newTestReader(tc.iter, tc.replay, tc.cps)
+			tr.add(true, tc.initial...)
+			if tc.later != nil {
+				tr.add(false, tc.later...)
+			}
+			tr.starts()
+			gsii := tr.iteratorInput(tr.shards[tc.shard], tc.lastSeq)
+			if *gsii.ShardIteratorType != tc.exp {
+				t.Fatalf("got %v, expected %v", *gsii.ShardIteratorType, tc.exp)
+			} else if *gsii.ShardId != tc.shard || *gsii.StreamName != testStream {
+				t.Fatalf("bad shard %v %v", *gsii.ShardId, *gsii.StreamName)
+			}
+			switch tc.exp {
+			case kinesis.ShardIteratorTypeAfterSequenceNumber:
+				if gsii.StartingSequenceNumber == nil || *gsii.StartingSequenceNumber != tc.lastSeq {
+					t.Fatalf("bad sequence number %v", gsii.StartingSequenceNumber)
+				}
+			case kinesis.ShardIteratorTypeAtTimestamp:
+				if gsii.Timestamp == nil || !gsii.Timestamp.Equal(replayStart) {
+					t.Fatalf("bad timestamp %v", gsii.Timestamp)
+				}
+			}
+		})
+	}
+}
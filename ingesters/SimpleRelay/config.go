@@ -29,10 +29,12 @@ const (
 	tcp6            bindType = iota
 	udp6            bindType = iota
 	TLS             bindType = iota
+	DTLS            bindType = iota
 
 	lineReader    readerType = iota
 	rfc5424Reader readerType = iota
 	rfc6587Reader readerType = iota
+	rfc3164Reader readerType = iota
 )
 
 var ()
@@ -42,9 +44,11 @@ type readerType int
 
 type listener struct {
 	baseConfig
-	Reader_Type   string
-	Drop_Priority bool // remove the <nnn> priority value at the start of the log message, useful for things like fortinet
-	Keep_Priority bool `json:"-"` //NOTE DEPRECATED AND UNUSED.  Left so that config parsing doesn't break
+	Reader_Type          string
+	Drop_Priority        bool   // remove the <nnn> priority value at the start of the log message, useful for things like fortinet
+	Source_From_Hostname bool   // RFC3164 only, resolve the syslog hostname and use it as the entry SRC
+	Client_CA_File       string // TLS and DTLS only, require client certificates signed by a CA in this bundle
+	Keep_Priority        bool   `json:"-"` //NOTE DEPRECATED AND UNUSED.  Left so that config parsing doesn't break
 }
 
 type baseConfig struct {
@@ -60,24 +64,32 @@ type baseConfig struct {
 	Preprocessor              []string
 }
 
+type global struct {
+	config.IngestConfig
+	Preprocessor_Workers int // run each listener's preprocessors across this many workers, zero runs them serially
+}
+
 type cfgReadType struct {
-	Global        config.IngestConfig
+	Global        global
 	Attach        attach.AttachConfig
 	Listener      map[string]*listener
 	JSONListener  map[string]*jsonListener
 	RegexListener map[string]*regexListener
+	GELFListener  map[string]*gelfListener
 	Preprocessor  processors.ProcessorConfig
 	TimeFormat    config.CustomTimeFormat
 }
 
 type cfgType struct {
 	config.IngestConfig
-	Attach        attach.AttachConfig
-	Listener      map[string]*listener
-	JSONListener  map[string]*jsonListener
-	RegexListener map[string]*regexListener
-	Preprocessor  processors.ProcessorConfig
-	TimeFormat    config.CustomTimeFormat
+	Preprocessor_Workers int
+	Attach               attach.AttachConfig
+	Listener             map[string]*listener
+	JSONListener         map[string]*jsonListener
+	RegexListener        map[string]*regexListener
+	GELFListener         map[string]*gelfListener
+	Preprocessor         processors.ProcessorConfig
+	TimeFormat           config.CustomTimeFormat
 }
 
 func GetConfig(path, overlayPath string) (*cfgType, error) {
@@ -89,13 +101,15 @@ func GetConfig(path, overlayPath string) (*cfgType, error) {
 		return nil, err
 	}
 	c := &cfgType{
-		IngestConfig:  cr.Global,
-		Attach:        cr.Attach,
-		Listener:      cr.Listener,
-		RegexListener: cr.RegexListener,
-		JSONListener:  cr.JSONListener,
-		Preprocessor:  cr.Preprocessor,
-		TimeFormat:    cr.TimeFormat,
+		IngestConfig:         cr.Global.IngestConfig,
+		Preprocessor_Workers: cr.Global.Preprocessor_Workers,
+		Attach:               cr.Attach,
+		Listener:             cr.Listener,
+		RegexListener:        cr.RegexListener,
+		JSONListener:         cr.JSONListener,
+		GELFListener:         cr.GELFListener,
+		Preprocessor:         cr.Preprocessor,
+		TimeFormat:           cr.TimeFormat,
 	}
 
 	if err := c.Verify(); err != nil {
@@ -111,13 +125,15 @@ func (c *cfgType) Verify() error {
 	} else if err = c.Attach.Verify(); err != nil {
 		return err
 	}
-	if len(c.Listener) == 0 && len(c.RegexListener) == 0 && len(c.JSONListener) == 0 {
+	if len(c.Listener) == 0 && len(c.RegexListener) == 0 && len(c.JSONListener) == 0 && len(c.GELFListener) == 0 {
 		return errors.New("No listeners specified")
 	}
 	if err := c.Preprocessor.Validate(); err != nil {
 		return err
 	} else if err = c.TimeFormat.Validate(); err != nil {
 		return err
+	} else if c.Preprocessor_Workers < 0 {
+		return fmt.Errorf("Invalid Preprocessor-Workers %d", c.Preprocessor_Workers)
 	}
 	bindMp := make(map[string]string, 1)
 	for k, v := range c.Listener {
@@ -153,6 +169,8 @@ func (c *cfgType) Verify() error {
 	for k, v := range c.RegexListener {
 		if err := v.Validate(); err != nil {
 			return fmt.Errorf("RegexListener %s configuration error: %v", k, err)
+		} else if err = checkStreamBind(v.Bind_String); err != nil {
+			return fmt.Errorf("RegexListener %s configuration error: %v", k, err)
 		}
 		if len(v.Tag_Name) == 0 {
 			v.Tag_Name = entry.DefaultTagName
@@ -180,6 +198,8 @@ func (c *cfgType) Verify() error {
 	for k, v := range c.JSONListener {
 		if err := v.Validate(); err != nil {
 			return fmt.Errorf("Listener %s configuration error: %v", k, err)
+		} else if err = checkStreamBind(v.Bind_String); err != nil {
+			return fmt.Errorf("Listener %s configuration error: %v", k, err)
 		}
 		if ingest.CheckTag(v.Default_Tag) != nil {
 			return errors.New("Invalid characters in the Default-Tag for " + k)
@@ -216,6 +236,30 @@ func (c *cfgType) Verify() error {
 	if err := checkJsonConfigs(c.JSONListener); err != nil {
 		return err
 	}
+	for k, v := range c.GELFListener {
+		if err := v.Validate(); err != nil {
+			return fmt.Errorf("GELFListener %s configuration error: %v", k, err)
+		}
+		tms, err := v.TagMatchers()
+		if err != nil {
+			return err
+		}
+		for _, t := range tms {
+			if len(t.Tag) == 0 || len(t.Value) == 0 {
+				return errors.New("Empty tag-match pair not allowed in GELF listener " + k)
+			}
+		}
+		if v.Timezone_Override != `` || v.Assume_Local_Timezone || v.Timestamp_Format_Override != `` {
+			return fmt.Errorf("GELFListener %s cannot specify timestamp parsing options, GELF timestamps are always epoch seconds", k)
+		}
+		if n, ok := bindMp[v.Bind_String]; ok {
+			return errors.New("Bind-String for " + k + " already in use by " + n)
+		}
+		bindMp[v.Bind_String] = k
+		if err := c.Preprocessor.CheckProcessors(v.Preprocessor); err != nil {
+			return fmt.Errorf("GELFListener %s preprocessor invalid: %v", k, err)
+		}
+	}
 	return nil
 }
 
@@ -243,7 +287,19 @@ func (c *cfgType) Tags() ([]string, error) {
 		}
 	}
 
-	//iterate over json listeners
+	//iterate over json and gelf listeners
+	for _, v := range c.GELFListener {
+		tgs, err := v.Tags()
+		if err != nil {
+			return nil, err
+		}
+		for _, tg := range tgs {
+			if _, ok := tagMp[tg]; !ok {
+				tags = append(tags, tg)
+				tagMp[tg] = true
+			}
+		}
+	}
 	for _, v := range c.JSONListener {
 		tgs, err := v.Tags()
 		if err != nil {
@@ -284,7 +340,7 @@ func checkListenerSettings(l *listener) (err error) {
 	if bt, _, err = translateBindType(l.Bind_String); err != nil {
 		return
 	}
-	if l.Drop_Priority && !(lt == rfc5424Reader || lt == rfc6587Reader) {
+	if l.Drop_Priority && !(lt == rfc5424Reader || lt == rfc6587Reader || lt == rfc3164Reader) {
 		err = fmt.Errorf("Drop-Priority is not compatible with reader type %s", lt)
 		return
 	}
@@ -292,9 +348,38 @@ func checkListenerSettings(l *listener) (err error) {
 		err = fmt.Errorf("RFC6587 reader type is not compatible with a UDP bind string")
 		return
 	}
+	if bt.TLS() || bt.DTLS() {
+		if l.Cert_File == `` || l.Key_File == `` {
+			err = fmt.Errorf("%s bind type requires Cert-File and Key-File", bt)
+			return
+		}
+	} else if l.Client_CA_File != `` {
+		err = fmt.Errorf("Client-CA-File is not compatible with bind type %s", bt)
+		return
+	}
+	if l.Source_From_Hostname {
+		if lt != rfc3164Reader {
+			err = fmt.Errorf("Source-From-Hostname is not compatible with reader type %s", lt)
+			return
+		} else if l.Source_Override != `` {
+			err = errors.New("Source-From-Hostname and Source-Override are mutually exclusive")
+			return
+		}
+	}
 	return
 }
 
+// checkStreamBind ensures that a bind string is one of the types supported by the regex and JSON listeners
+func checkStreamBind(bstr string) error {
+	bt, _, err := translateBindType(bstr)
+	if err != nil {
+		return err
+	} else if bt.DTLS() {
+		return errors.New("DTLS bind type is only supported by Listener")
+	}
+	return nil
+}
+
 func (l baseConfig) Validate() error {
 	if len(l.Bind_String) == 0 {
 		return errors.New("No Bind-String provided")
@@ -320,6 +405,8 @@ func translateBindType(bstr string) (bindType, string, error) {
 		return udp6, bits[1], nil
 	case "tls":
 		return TLS, bits[1], nil
+	case "dtls":
+		return DTLS, bits[1], nil
 	default:
 	}
 	return -1, "", errors.New("invalid bind protocol specifier of " + id)
@@ -343,6 +430,10 @@ func (bt bindType) TLS() bool {
 	return bt == TLS
 }
 
+func (bt bindType) DTLS() bool {
+	return bt == DTLS
+}
+
 func (bt bindType) String() string {
 	switch bt {
 	case tcp:
@@ -355,6 +446,8 @@ func (bt bindType) String() string {
 		return "udp6"
 	case TLS:
 		return "tls"
+	case DTLS:
+		return "dtls"
 	}
 	return "unknown"
 }
@@ -368,6 +461,8 @@ func translateReaderType(s string) (readerType, error) {
 		return rfc5424Reader, nil
 	case `rfc6587`:
 		return rfc6587Reader, nil
+	case `rfc3164`:
+		return rfc3164Reader, nil
 	case ``:
 		return lineReader, nil
 	}
@@ -382,6 +477,17 @@ func (rt readerType) String() string {
 		return `RFC5424`
 	case rfc6587Reader:
 		return `RFC6587`
+	case rfc3164Reader:
+		return `RFC3164`
 	}
 	return "UNKNOWN"
 }
+
+// processorSet builds the preprocessor chain for a listener, with Preprocessor-Workers set
+// the chain runs on that many workers and entries from a single source stay in order
+func (c *cfgType) processorSet(igst *ingest.IngestMuxer, names []string) (*processors.ProcessorSet, error) {
+	if c.Preprocessor_Workers > 0 && len(names) > 0 {
+		return c.Preprocessor.ParallelProcessorSet(igst, names, c.Preprocessor_Workers)
+	}
+	return c.Preprocessor.ProcessorSet(igst, names)
+}
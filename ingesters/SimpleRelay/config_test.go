@@ -12,6 +12,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"strings"
 	"testing"
 )
 
@@ -83,6 +84,39 @@ func TestBadConfig(t *testing.T) {
 	}
 }
 
+func TestPreprocessorWorkers(t *testing.T) {
+	wcfg := strings.Replace(testConfig, "[Global]\n", "[Global]\nPreprocessor-Workers=4\n", 1) + `
+[preprocessor "dropper"]
+	Type=drop
+`
+	cfgPath, err := dropConfig(wcfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := GetConfig(cfgPath, ``)
+	if err != nil {
+		t.Fatal(err)
+	} else if cfg.Preprocessor_Workers != 4 {
+		t.Fatalf("bad worker count %d", cfg.Preprocessor_Workers)
+	}
+	if ps, err := cfg.processorSet(nil, []string{`dropper`}); err != nil {
+		t.Fatal(err)
+	} else if ps.Count() != 1 {
+		t.Fatalf("bad preprocessor count %d", ps.Count())
+	}
+	if _, err = cfg.processorSet(nil, []string{`missing`}); err == nil {
+		t.Fatal("failed to catch a missing preprocessor")
+	}
+
+	cfgPath, err = dropConfig(strings.Replace(testConfig, "[Global]\n", "[Global]\nPreprocessor-Workers=-1\n", 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = GetConfig(cfgPath, ``); err == nil {
+		t.Fatal("failed to catch a negative worker count")
+	}
+}
+
 func dropConfig(cfg string) (pth string, err error) {
 	var fout *os.File
 	var n int
@@ -0,0 +1,528 @@
+/*************************************************************************
+ * Copyright 2026 Gravwell, Inc. All rights reserved.
+ * Contact: <legal@gravwell.io>
+ *
+ * This software may be modified and distributed under the terms of the
+ * BSD 2-clause license. See the LICENSE file for details.
+ **************************************************************************/
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"container/list"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gravwell/gravwell/v3/ingest"
+	"github.com/gravwell/gravwell/v3/ingest/entry"
+	"github.com/gravwell/gravwell/v3/ingest/log"
+	"github.com/gravwell/gravwell/v3/ingest/processors"
+	"github.com/gravwell/gravwell/v3/ingesters/utils"
+	"github.com/gravwell/jsonparser"
+)
+
+const (
+	gelfChunkHeaderSize = 12   // 2 byte magic, 8 byte message ID, sequence number, sequence count
+	gelfMaxChunks       = 128  // per the spec, anything larger is dropped
+	gelfMaxPending      = 1024 // incomplete messages held at once, the oldest is evicted to make room
+	gelfUDPBufferSize   = 64 * 1024
+)
+
+var (
+	gelfChunkMagic = []byte{0x1e, 0x0f}
+
+	errGELFInvalidChunk = errors.New("invalid GELF chunk")
+	errGELFNotObject    = errors.New("GELF message is not a JSON object")
+)
+
+type gelfHandlerConfig struct {
+	name             string
+	defTag           entry.EntryTag
+	tags             map[string]entry.EntryTag
+	ignoreTimestamps bool
+	src              net.IP
+	wg               *sync.WaitGroup
+	flds             []string
+	proc             *processors.ProcessorSet
+	ctx              context.Context
+	maxObjectSize    int64
+	chunkTimeout     time.Duration
+}
+
+func startGELFListeners(cfg *cfgType, igst *ingest.IngestMuxer, wg *sync.WaitGroup, f *flusher, ctx context.Context) (err error) {
+	//short circuit out on empty
+	if len(cfg.GELFListener) == 0 {
+		return nil
+	}
+	for k, v := range cfg.GELFListener {
+		if err = v.Validate(); err != nil {
+			return fmt.Errorf("GELFListener %s configuration is invalid: %w", k, err)
+		}
+		ghc := gelfHandlerConfig{
+			name:             k,
+			wg:               wg,
+			tags:             map[string]entry.EntryTag{},
+			ignoreTimestamps: v.Ignore_Timestamps,
+			ctx:              ctx,
+			maxObjectSize:    int64(v.Max_Object_Size),
+		}
+		if ghc.chunkTimeout, err = v.chunkTimeout(); err != nil {
+			return err
+		}
+		if ghc.proc, err = cfg.processorSet(igst, v.Preprocessor); err != nil {
+			lg.Fatal("preprocessor error", log.KVErr(err))
+		}
+		f.Add(ghc.proc)
+		if ghc.flds, err = v.GetJsonFields(); err != nil {
+			return err
+		}
+		if v.Source_Override != `` {
+			if ghc.src = net.ParseIP(v.Source_Override); ghc.src == nil {
+				return fmt.Errorf("GELFListener %v invalid source override \"%s\"", k, v.Source_Override)
+			}
+		} else if cfg.Source_Override != `` {
+			// global override
+			if ghc.src = net.ParseIP(cfg.Source_Override); ghc.src == nil {
+				return fmt.Errorf("global source override \"%s\" is invalid", cfg.Source_Override)
+			}
+		}
+		//resolve the default tag
+		if ghc.defTag, err = igst.GetTag(v.Default_Tag); err != nil {
+			return err
+		}
+		//resolve all the other tags
+		tms, err := v.TagMatchers()
+		if err != nil {
+			return err
+		}
+		for _, tm := range tms {
+			tg, err := igst.GetTag(tm.Tag)
+			if err != nil {
+				return err
+			}
+			ghc.tags[tm.Value] = tg
+		}
+
+		tp, str, err := translateBindType(v.Bind_String)
+		if err != nil {
+			lg.FatalCode(0, "invalid bind", log.KV("bindstring", v.Bind_String), log.KVErr(err))
+		}
+		if tp.TCP() {
+			addr, err := net.ResolveTCPAddr(tp.String(), str)
+			if err != nil {
+				return fmt.Errorf("%s Bind-String \"%s\" is invalid: %v\n", k, v.Bind_String, err)
+			}
+			l, err := net.ListenTCP(tp.String(), addr)
+			if err != nil {
+				return fmt.Errorf("%s Failed to listen on \"%s\": %v\n", k, addr, err)
+			}
+			connID := addConn(l)
+			wg.Add(1)
+			go gelfAcceptor(l, connID, ghc, tp)
+		} else if tp.TLS() {
+			config, err := newTLSConfig(&listener{baseConfig: v.baseConfig})
+			if err != nil {
+				lg.FatalCode(0, "failed to load certificate", log.KV("certfile", v.Cert_File), log.KV("keyfile", v.Key_File), log.KVErr(err))
+			}
+			addr, err := net.ResolveTCPAddr("tcp", str)
+			if err != nil {
+				lg.FatalCode(0, "invalid Bind-String", log.KV("bindstring", v.Bind_String), log.KV("gelflistener", k), log.KVErr(err))
+			}
+			l, err := tls.Listen("tcp", addr.String(), config)
+			if err != nil {
+				lg.FatalCode(0, "failed to listen via TLS", log.KV("address", addr), log.KV("gelflistener", k), log.KVErr(err))
+			}
+			connID := addConn(l)
+			wg.Add(1)
+			go gelfAcceptor(l, connID, ghc, tp)
+		} else if tp.UDP() {
+			addr, err := net.ResolveUDPAddr(tp.String(), str)
+			if err != nil {
+				lg.FatalCode(0, "invalid Bind-String", log.KV("bindstring", v.Bind_String), log.KV("gelflistener", k), log.KVErr(err))
+			}
+			l, err := net.ListenUDP(tp.String(), addr)
+			if err != nil {
+				lg.FatalCode(0, "failed to listen via udp", log.KV("address", addr), log.KV("gelflistener", k), log.KVErr(err))
+			}
+			connID := addConn(l)
+			wg.Add(1)
+			go gelfAcceptorUDP(l, connID, ghc)
+		}
+	}
+	debugout("Started %d GELF listeners\n", len(cfg.GELFListener))
+	return nil
+}
+
+func gelfAcceptor(lst net.Listener, id int, cfg gelfHandlerConfig, tp bindType) {
+	defer cfg.wg.Done()
+	defer delConn(id)
+	defer lst.Close()
+	var failCount int
+	for {
+		conn, err := lst.Accept()
+		if err != nil {
+			if strings.Contains(err.Error(), "closed") {
+				break
+			}
+			failCount++
+			lg.Warn("failed to accept connection", log.KV("readertype", `gelf`), log.KV("mode", tp), log.KVErr(err))
+			if failCount > 3 {
+				break
+			}
+			continue
+		}
+		debugout("Accepted %v connection from %s in gelf mode\n", tp.String(), conn.RemoteAddr())
+		lg.Info("accepted connection", log.KV("address", conn.RemoteAddr()), log.KV("readertype", `gelf`), log.KV("mode", tp), log.KV("listener", cfg.name))
+		failCount = 0
+		go gelfConnHandler(conn, cfg)
+	}
+}
+
+// gelfConnHandler handles GELF over TCP, messages are delimited by null bytes
+func gelfConnHandler(c net.Conn, cfg gelfHandlerConfig) {
+	cfg.wg.Add(1)
+	id := addConn(c)
+	defer cfg.wg.Done()
+	defer delConn(id)
+	defer c.Close()
+	rip := cfg.src
+	if rip == nil {
+		ipstr, _, err := net.SplitHostPort(c.RemoteAddr().String())
+		if err != nil {
+			lg.Error("failed to get host from remote addr", log.KV("remoteaddress", c.RemoteAddr().String()), log.KVErr(err))
+			return
+		} else if rip = net.ParseIP(ipstr); rip == nil {
+			lg.Error("failed to get remote address", log.KV("remoteaddress", ipstr))
+			return
+		}
+	}
+	ll := log.NewLoggerWithKV(lg, log.KV("gelf-listener", cfg.name))
+	if err := gelfStreamLoop(c, rip, cfg, ll); err != nil {
+		ll.Error("GELF stream handler error", log.KV("remoteaddress", rip), log.KVErr(err))
+	}
+}
+
+func gelfStreamLoop(rdr io.Reader, rip net.IP, cfg gelfHandlerConfig, ll *log.KVLogger) error {
+	s := bufio.NewScanner(rdr)
+	s.Buffer(make([]byte, 0, initDataSize), int(cfg.maxObjectSize)+1)
+	s.Split(nullSplitter)
+	for s.Scan() {
+		data := bytes.TrimSpace(s.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+		if err := cfg.handleMessage(bytes.Clone(data), rip); err != nil {
+			ll.Warn("invalid GELF message", log.KV("remoteaddress", rip), log.KVErr(err))
+		}
+	}
+	if err := s.Err(); err != nil {
+		if errors.Is(err, bufio.ErrTooLong) {
+			return utils.ErrOversizedObject
+		}
+		return err
+	}
+	return nil
+}
+
+func nullSplitter(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	if atEOF && len(data) == 0 {
+		return 0, nil, nil
+	}
+	if i := bytes.IndexByte(data, 0); i >= 0 {
+		return i + 1, data[0:i], nil
+	}
+	if atEOF {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
+
+func gelfAcceptorUDP(conn *net.UDPConn, id int, cfg gelfHandlerConfig) {
+	defer cfg.wg.Done()
+	defer delConn(id)
+	defer conn.Close()
+	ll := log.NewLoggerWithKV(lg, log.KV("gelf-listener", cfg.name))
+	buff := make([]byte, gelfUDPBufferSize)
+	ra := newGELFReassembler(cfg.chunkTimeout, cfg.maxObjectSize)
+	for {
+		n, raddr, err := conn.ReadFromUDP(buff)
+		if err != nil {
+			break
+		}
+		if n == 0 || raddr == nil || n > len(buff) {
+			continue
+		}
+		rip := cfg.src
+		if rip == nil {
+			rip = raddr.IP
+		}
+		if err = cfg.handleDatagram(ra, buff[:n], raddr.IP, rip, time.Now()); err != nil {
+			ll.Warn("invalid GELF message", log.KV("remoteaddress", raddr.IP), log.KVErr(err))
+		}
+	}
+}
+
+// handleDatagram processes a single UDP datagram, which may be a chunk of a larger message
+func (cfg gelfHandlerConfig) handleDatagram(ra *gelfReassembler, pkt []byte, sender, rip net.IP, now time.Time) error {
+	if !bytes.HasPrefix(pkt, gelfChunkMagic) {
+		//because we are using and reusing a local buffer, we have to copy the bytes when handing in
+		return cfg.handleMessage(bytes.Clone(pkt), rip)
+	}
+	msg, err := ra.Add(sender, pkt, now)
+	if err != nil || msg == nil {
+		return err
+	}
+	return cfg.handleMessage(msg, rip)
+}
+
+// handleMessage decompresses and decodes a complete GELF message and hands it to the preprocessors
+func (cfg gelfHandlerConfig) handleMessage(raw []byte, rip net.IP) error {
+	data, err := gelfDecompress(raw, cfg.maxObjectSize)
+	if err != nil {
+		return err
+	}
+	ent, err := cfg.newEntry(data, rip)
+	if err != nil {
+		return err
+	}
+	return cfg.proc.ProcessContext(ent, cfg.ctx)
+}
+
+func (cfg gelfHandlerConfig) newEntry(data []byte, rip net.IP) (ent *entry.Entry, err error) {
+	if data = compactObject(bytes.TrimSpace(data)); len(data) == 0 || data[0] != '{' {
+		return nil, errGELFNotObject
+	}
+	var ts time.Time
+	var evs []entry.EnumeratedValue
+	cb := func(key, value []byte, dt jsonparser.ValueType, _ int) error {
+		switch k := string(key); {
+		case k == `timestamp`:
+			if dt != jsonparser.Number {
+				return nil
+			}
+			if f, err := strconv.ParseFloat(string(value), 64); err == nil {
+				sec, frac := math.Modf(f)
+				ts = time.Unix(int64(sec), int64(frac*1e9)).UTC()
+				evs = append(evs, entry.EnumeratedValue{Name: `timestamp`, Value: entry.TSEnumData(entry.FromStandard(ts))})
+			}
+		case k == `host`:
+			if ev, ok := gelfEV(k, value, dt); ok {
+				evs = append(evs, ev)
+			}
+		case len(k) > 1 && k[0] == '_':
+			// additional fields are prefixed with an underscore, which we strip off
+			if ev, ok := gelfEV(k[1:], value, dt); ok {
+				evs = append(evs, ev)
+			}
+		}
+		return nil
+	}
+	if err = jsonparser.ObjectEach(data, cb); err != nil {
+		return nil, fmt.Errorf("%w: %v", errGELFNotObject, err)
+	}
+
+	tag := cfg.defTag
+	if len(cfg.flds) > 0 {
+		if s, err := jsonparser.GetString(data, cfg.flds...); err == nil {
+			if t, ok := cfg.tags[s]; ok {
+				tag = t
+			}
+		}
+	}
+	ent = &entry.Entry{
+		SRC:  rip,
+		TS:   entry.Now(),
+		Tag:  tag,
+		Data: data,
+	}
+	if !cfg.ignoreTimestamps && !ts.IsZero() {
+		ent.TS = entry.FromStandard(ts)
+	}
+	if len(evs) > 0 {
+		ent.AddEnumeratedValues(evs)
+	}
+	return
+}
+
+// gelfEV converts a JSON value to a natively typed enumerated value
+func gelfEV(name string, value []byte, dt jsonparser.ValueType) (ev entry.EnumeratedValue, ok bool) {
+	ev.Name = name
+	switch dt {
+	case jsonparser.String:
+		s, err := jsonparser.ParseString(value)
+		if err != nil {
+			s = string(value)
+		}
+		ev.Value = entry.StringEnumData(s)
+	case jsonparser.Number:
+		if v, err := strconv.ParseInt(string(value), 10, 64); err == nil {
+			ev.Value = entry.Int64EnumData(v)
+		} else if f, err := strconv.ParseFloat(string(value), 64); err == nil {
+			ev.Value = entry.Float64EnumData(f)
+		} else {
+			return
+		}
+	case jsonparser.Boolean:
+		b, err := jsonparser.ParseBoolean(value)
+		if err != nil {
+			return
+		}
+		ev.Value = entry.BoolEnumData(b)
+	case jsonparser.Object, jsonparser.Array:
+		// GELF does not allow nested values, but be lenient and keep them as strings
+		ev.Value = entry.StringEnumData(string(value))
+	default:
+		return
+	}
+	ok = true
+	return
+}
+
+// gelfDecompress detects and undoes gzip or zlib compression, uncompressed payloads are returned as is.
+// The decompressed size is capped to avoid compression bombs.
+func gelfDecompress(b []byte, max int64) ([]byte, error) {
+	var rdr io.ReadCloser
+	var err error
+	if len(b) >= 2 && b[0] == 0x1f && b[1] == 0x8b {
+		rdr, err = gzip.NewReader(bytes.NewReader(b))
+	} else if len(b) >= 2 && b[0]&0x0f == 0x08 && (uint16(b[0])<<8|uint16(b[1]))%31 == 0 {
+		rdr, err = zlib.NewReader(bytes.NewReader(b))
+	} else {
+		return b, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer rdr.Close()
+	out, err := io.ReadAll(io.LimitReader(rdr, max+1))
+	if err != nil {
+		return nil, err
+	} else if int64(len(out)) > max {
+		return nil, utils.ErrOversizedObject
+	}
+	return out, nil
+}
+
+type gelfChunkKey struct {
+	src string
+	id  [8]byte
+}
+
+type gelfChunkSet struct {
+	chunks   [][]byte
+	received int
+	size     int64
+	first    time.Time
+	elem     *list.Element // position in the reassembler age order
+}
+
+// gelfReassembler collects UDP chunks until a message is complete.
+// Incomplete messages are discarded once the chunk timeout expires or when
+// more than maxPending are held, in which case the oldest goes first.
+// The reassembler is NOT safe for concurrent use.
+type gelfReassembler struct {
+	timeout    time.Duration
+	maxSize    int64
+	maxPending int
+	sets       map[gelfChunkKey]*gelfChunkSet
+	order      *list.List // keys of incomplete messages, oldest first
+	lastSweep  time.Time
+}
+
+func newGELFReassembler(timeout time.Duration, maxSize int64) *gelfReassembler {
+	if timeout <= 0 {
+		timeout = defaultGELFChunkTimeout
+	}
+	return &gelfReassembler{
+		timeout:    timeout,
+		maxSize:    maxSize,
+		maxPending: gelfMaxPending,
+		sets:       make(map[gelfChunkKey]*gelfChunkSet),
+		order:      list.New(),
+	}
+}
+
+// Add adds a chunk, if the message is complete it is returned
+func (r *gelfReassembler) Add(sender net.IP, pkt []byte, now time.Time) (msg []byte, err error) {
+	r.sweep(now)
+	if len(pkt) <= gelfChunkHeaderSize || !bytes.HasPrefix(pkt, gelfChunkMagic) {
+		return nil, errGELFInvalidChunk
+	}
+	var key gelfChunkKey
+	key.src = sender.String()
+	copy(key.id[:], pkt[2:10])
+	seq, count := int(pkt[10]), int(pkt[11])
+	if count == 0 || count > gelfMaxChunks || seq >= count {
+		return nil, fmt.Errorf("%w: sequence %d of %d", errGELFInvalidChunk, seq, count)
+	}
+	cs, ok := r.sets[key]
+	if !ok {
+		for len(r.sets) >= r.maxPending {
+			r.drop(r.order.Front().Value.(gelfChunkKey))
+		}
+		cs = &gelfChunkSet{
+			chunks: make([][]byte, count),
+			first:  now,
+			elem:   r.order.PushBack(key),
+		}
+		r.sets[key] = cs
+	} else if len(cs.chunks) != count {
+		r.drop(key)
+		return nil, fmt.Errorf("%w: inconsistent sequence count", errGELFInvalidChunk)
+	}
+	if cs.chunks[seq] != nil {
+		return // duplicate
+	}
+	payload := pkt[gelfChunkHeaderSize:]
+	if cs.size += int64(len(payload)); cs.size > r.maxSize {
+		r.drop(key)
+		return nil, utils.ErrOversizedObject
+	}
+	cs.chunks[seq] = bytes.Clone(payload)
+	if cs.received++; cs.received < count {
+		return
+	}
+	r.drop(key)
+	msg = bytes.Join(cs.chunks, nil)
+	return
+}
+
+func (r *gelfReassembler) drop(key gelfChunkKey) {
+	if cs, ok := r.sets[key]; ok {
+		r.order.Remove(cs.elem)
+		delete(r.sets, key)
+	}
+}
+
+// sweep drops any incomplete messages that have timed out, at most once a second
+func (r *gelfReassembler) sweep(now time.Time) {
+	if now.Sub(r.lastSweep) < time.Second {
+		return
+	}
+	r.lastSweep = now
+	for e := r.order.Front(); e != nil; e = r.order.Front() {
+		key := e.Value.(gelfChunkKey)
+		if now.Sub(r.sets[key].first) <= r.timeout {
+			break // everything after this is newer
+		}
+		r.drop(key)
+	}
+}
+
+// pending returns the number of incomplete messages being held
+func (r *gelfReassembler) pending() int {
+	return len(r.sets)
+}
@@ -0,0 +1,87 @@
+/*************************************************************************
+ * Copyright 2026 Gravwell, Inc. All rights reserved.
+ * Contact: <legal@gravwell.io>
+ *
+ * This software may be modified and distributed under the terms of the
+ * BSD 2-clause license. See the LICENSE file for details.
+ **************************************************************************/
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	defaultGELFChunkTimeout = 5 * time.Second // the GELF spec says all chunks must arrive within 5 seconds
+)
+
+// gelfListener handles Graylog Extended Log Format messages, the Tag_Match and
+// Extractor parameters behave exactly the same as they do on the JSONListener.
+type gelfListener struct {
+	baseConfig
+	Max_Object_Size uint   // maximum size of a message after reassembly and decompression
+	Chunk_Timeout   string // how long to wait for all the chunks of a UDP message
+	Extractor       string
+	Default_Tag     string
+	Tag_Match       []string
+}
+
+// jsonListener gets a JSONListener view of the GELF listener so we can share the tag matching logic
+func (gl *gelfListener) jsonListener() *jsonListener {
+	return &jsonListener{
+		baseConfig:      gl.baseConfig,
+		Max_Object_Size: gl.Max_Object_Size,
+		Extractor:       gl.Extractor,
+		Default_Tag:     gl.Default_Tag,
+		Tag_Match:       gl.Tag_Match,
+	}
+}
+
+func (gl *gelfListener) Validate() error {
+	jl := gl.jsonListener()
+	if err := jl.Validate(); err != nil {
+		return err
+	}
+	//pull back any defaults that were resolved
+	gl.Default_Tag = jl.Default_Tag
+	gl.Max_Object_Size = jl.Max_Object_Size
+
+	bt, _, err := translateBindType(gl.Bind_String)
+	if err != nil {
+		return err
+	} else if bt.DTLS() {
+		return errors.New("DTLS bind type is not supported by GELFListener")
+	}
+	if _, err = gl.chunkTimeout(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (gl *gelfListener) chunkTimeout() (time.Duration, error) {
+	if gl.Chunk_Timeout == `` {
+		return defaultGELFChunkTimeout, nil
+	}
+	d, err := time.ParseDuration(gl.Chunk_Timeout)
+	if err != nil {
+		return 0, fmt.Errorf("Invalid Chunk-Timeout %q: %w", gl.Chunk_Timeout, err)
+	} else if d <= 0 {
+		return 0, fmt.Errorf("Invalid Chunk-Timeout %q: must be positive", gl.Chunk_Timeout)
+	}
+	return d, nil
+}
+
+func (gl *gelfListener) TagMatchers() ([]TagMatcher, error) {
+	return gl.jsonListener().TagMatchers()
+}
+
+func (gl *gelfListener) Tags() ([]string, error) {
+	return gl.jsonListener().Tags()
+}
+
+func (gl *gelfListener) GetJsonFields() ([]string, error) {
+	return gl.jsonListener().GetJsonFields()
+}
@@ -0,0 +1,312 @@
+/*************************************************************************
+ * Copyright 2026 Gravwell, Inc. All rights reserved.
+ * Contact: <legal@gravwell.io>
+ *
+ * This software may be modified and distributed under the terms of the
+ * BSD 2-clause license. See the LICENSE file for details.
+ **************************************************************************/
+
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"errors"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gravwell/gravwell/v3/ingest/entry"
+	"github.com/gravwell/gravwell/v3/ingest/log"
+	"github.com/gravwell/gravwell/v3/ingest/processors"
+	"github.com/gravwell/gravwell/v3/ingesters/utils"
+)
+
+const (
+	gelfTestMsg = `{"version":"1.1","host":"web01","short_message":"GET /","timestamp":1672531200.5,"level":6,` +
+		`"_container_name":"nginx","_status":200,"_latency":0.25,"_cached":true}`
+)
+
+func newGELFTestConfig() (gelfHandlerConfig, *tracker) {
+	lg = log.New(os.Stderr)
+	trk := &tracker{}
+	cfg := gelfHandlerConfig{
+		name:          `test`,
+		defTag:        entry.EntryTag(1),
+		tags:          map[string]entry.EntryTag{`nginx`: entry.EntryTag(2)},
+		flds:          []string{`_container_name`},
+		wg:            &sync.WaitGroup{},
+		ctx:           context.Background(),
+		proc:          processors.NewProcessorSet(&nilWriter{}),
+		maxObjectSize: int64(defaultMaxObjectSize),
+		chunkTimeout:  defaultGELFChunkTimeout,
+	}
+	cfg.proc.AddProcessor(trk)
+	return cfg, trk
+}
+
+func gelfChunks(id byte, msg []byte, size int) (r [][]byte) {
+	count := (len(msg) + size - 1) / size
+	for i := 0; i < count; i++ {
+		end := (i + 1) * size
+		if end > len(msg) {
+			end = len(msg)
+		}
+		hdr := []byte{0x1e, 0x0f, id, 1, 2, 3, 4, 5, 6, 7, byte(i), byte(count)}
+		r = append(r, append(hdr, msg[i*size:end]...))
+	}
+	return
+}
+
+func gzipBytes(t *testing.T, b []byte) []byte {
+	bb := bytes.NewBuffer(nil)
+	w := gzip.NewWriter(bb)
+	w.Write(b)
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return bb.Bytes()
+}
+
+func zlibBytes(t *testing.T, b []byte) []byte {
+	bb := bytes.NewBuffer(nil)
+	w := zlib.NewWriter(bb)
+	w.Write(b)
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return bb.Bytes()
+}
+
+func checkGELFEntry(t *testing.T, ent *entry.Entry) {
+	if ent.Tag != entry.EntryTag(2) {
+		t.Fatalf("bad tag %v", ent.Tag)
+	}
+	if ts := ent.TS.StandardTime(); !ts.Equal(time.Unix(1672531200, 5e8)) {
+		t.Fatalf("bad timestamp %v", ts)
+	}
+	checks := map[string]interface{}{
+		`host`:           `web01`,
+		`container_name`: `nginx`,
+		`status`:         int64(200),
+		`latency`:        float64(0.25),
+		`cached`:         true,
+	}
+	for k, v := range checks {
+		if ev, ok := ent.GetEnumeratedValue(k); !ok {
+			t.Fatalf("missing EV %s", k)
+		} else if ev != v {
+			t.Fatalf("bad EV %s: %v (%T) != %v (%T)", k, ev, ev, v, v)
+		}
+	}
+	if _, ok := ent.GetEnumeratedValue(`timestamp`); !ok {
+		t.Fatal("missing timestamp EV")
+	} else if _, ok := ent.GetEnumeratedValue(`short_message`); ok {
+		t.Fatal("short_message should not be attached")
+	}
+}
+
+func TestGELFChunked(t *testing.T) {
+	cfg, trk := newGELFTestConfig()
+	ra := newGELFReassembler(cfg.chunkTimeout, cfg.maxObjectSize)
+	sender := net.ParseIP(`10.0.0.1`)
+	now := time.Now()
+	payloads := [][]byte{
+		[]byte(gelfTestMsg),
+		gzipBytes(t, []byte(gelfTestMsg)),
+		zlibBytes(t, []byte(gelfTestMsg)),
+	}
+	for i, p := range payloads {
+		chunks := gelfChunks(byte(i), p, 16)
+		//deliver out of order with a duplicate
+		for j := len(chunks) - 1; j >= 0; j-- {
+			if err := cfg.handleDatagram(ra, chunks[j], sender, sender, now); err != nil {
+				t.Fatal(err)
+			}
+			if j == len(chunks)-1 {
+				cfg.handleDatagram(ra, chunks[j], sender, sender, now)
+			}
+		}
+		if len(trk.ents) != i+1 {
+			t.Fatalf("expected %d entries, got %d", i+1, len(trk.ents))
+		}
+		checkGELFEntry(t, trk.ents[i])
+	}
+	if ra.pending() != 0 {
+		t.Fatalf("reassembler still holding %d messages", ra.pending())
+	}
+
+	//unchunked compressed message
+	if err := cfg.handleDatagram(ra, gzipBytes(t, []byte(gelfTestMsg)), sender, sender, now); err != nil {
+		t.Fatal(err)
+	} else if len(trk.ents) != 4 {
+		t.Fatalf("expected 4 entries, got %d", len(trk.ents))
+	}
+	checkGELFEntry(t, trk.ents[3])
+}
+
+func TestGELFChunkTimeout(t *testing.T) {
+	cfg, trk := newGELFTestConfig()
+	ra := newGELFReassembler(cfg.chunkTimeout, cfg.maxObjectSize)
+	sender := net.ParseIP(`10.0.0.1`)
+	now := time.Now()
+	chunks := gelfChunks(1, []byte(gelfTestMsg), 32)
+	if err := cfg.handleDatagram(ra, chunks[0], sender, sender, now); err != nil {
+		t.Fatal(err)
+	} else if ra.pending() != 1 {
+		t.Fatal("chunk not pending")
+	}
+	//chunks from another sender with the same ID do not complete the message
+	for _, c := range chunks[1:] {
+		cfg.handleDatagram(ra, c, net.ParseIP(`10.0.0.2`), sender, now)
+	}
+	if len(trk.ents) != 0 {
+		t.Fatal("message assembled across senders")
+	}
+	//the rest arrive after the timeout
+	later := now.Add(cfg.chunkTimeout + time.Second)
+	for _, c := range chunks[1:] {
+		cfg.handleDatagram(ra, c, sender, sender, later)
+	}
+	if len(trk.ents) != 0 {
+		t.Fatal("message assembled after the timeout")
+	}
+
+	//bad sequence counts
+	bad := append([]byte{0x1e, 0x0f, 9, 9, 9, 9, 9, 9, 9, 9, 0, 129}, '{', '}')
+	if err := cfg.handleDatagram(ra, bad, sender, sender, later); !errors.Is(err, errGELFInvalidChunk) {
+		t.Fatalf("failed to catch oversized chunk count: %v", err)
+	}
+}
+
+func TestGELFPendingLimit(t *testing.T) {
+	cfg, trk := newGELFTestConfig()
+	ra := newGELFReassembler(cfg.chunkTimeout, cfg.maxObjectSize)
+	ra.maxPending = 4
+	sender := net.ParseIP(`10.0.0.1`)
+	now := time.Now()
+	//start more messages than the reassembler will hold
+	var sets [][][]byte
+	for i := 0; i < 6; i++ {
+		chunks := gelfChunks(byte(i), []byte(gelfTestMsg), 32)
+		if err := cfg.handleDatagram(ra, chunks[0], sender, sender, now.Add(time.Duration(i)*time.Millisecond)); err != nil {
+			t.Fatal(err)
+		}
+		sets = append(sets, chunks)
+	}
+	if ra.pending() != 4 {
+		t.Fatalf("reassembler holding %d messages", ra.pending())
+	}
+	//the newest complete
+	for _, chunks := range sets[2:] {
+		for _, c := range chunks[1:] {
+			if err := cfg.handleDatagram(ra, c, sender, sender, now); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if len(trk.ents) != 4 || ra.pending() != 0 {
+		t.Fatalf("got %d entries with %d pending", len(trk.ents), ra.pending())
+	}
+	//the two oldest were evicted and never complete
+	for _, chunks := range sets[:2] {
+		for _, c := range chunks[1:] {
+			cfg.handleDatagram(ra, c, sender, sender, now)
+		}
+	}
+	if len(trk.ents) != 4 {
+		t.Fatal("evicted message assembled")
+	}
+}
+
+func TestGELFOversized(t *testing.T) {
+	cfg, trk := newGELFTestConfig()
+	cfg.maxObjectSize = 64
+	ra := newGELFReassembler(cfg.chunkTimeout, cfg.maxObjectSize)
+	sender := net.ParseIP(`10.0.0.1`)
+	var err error
+	for _, c := range gelfChunks(1, []byte(gelfTestMsg), 32) {
+		if err = cfg.handleDatagram(ra, c, sender, sender, time.Now()); err != nil {
+			break
+		}
+	}
+	if !errors.Is(err, utils.ErrOversizedObject) {
+		t.Fatalf("failed to catch oversized message: %v", err)
+	}
+	//compression bombs are caught too
+	big := gzipBytes(t, []byte(`{"x":"`+strings.Repeat(`a`, 4096)+`"}`))
+	if err = cfg.handleDatagram(ra, big, sender, sender, time.Now()); !errors.Is(err, utils.ErrOversizedObject) {
+		t.Fatalf("failed to catch oversized decompression: %v", err)
+	}
+	if len(trk.ents) != 0 {
+		t.Fatal("oversized messages were ingested")
+	}
+}
+
+func TestGELFStream(t *testing.T) {
+	cfg, trk := newGELFTestConfig()
+	ll := log.NewLoggerWithKV(lg, log.KV("gelf-listener", cfg.name))
+	input := gelfTestMsg + "\x00" + `{"version":"1.1","host":"db01","short_message":"hi","_container_name":"pg"}` + "\x00\x00not json\x00"
+	if err := gelfStreamLoop(strings.NewReader(input), net.ParseIP(`10.0.0.1`), cfg, ll); err != nil {
+		t.Fatal(err)
+	}
+	if len(trk.ents) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(trk.ents))
+	}
+	checkGELFEntry(t, trk.ents[0])
+	if trk.ents[1].Tag != cfg.defTag {
+		t.Fatalf("unmatched message did not get the default tag: %v", trk.ents[1].Tag)
+	} else if v, ok := trk.ents[1].GetEnumeratedValue(`host`); !ok || v != `db01` {
+		t.Fatalf("bad host %v", v)
+	}
+}
+
+func TestGELFConfig(t *testing.T) {
+	cfgPath, err := dropConfig(gelfTestConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := GetConfig(cfgPath, ``)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gl, ok := cfg.GELFListener[`docker`]
+	if !ok {
+		t.Fatal("missing GELF listener")
+	}
+	if gl.Default_Tag != `docker` || gl.Max_Object_Size != defaultMaxObjectSize {
+		t.Fatalf("defaults not resolved: %q %d", gl.Default_Tag, gl.Max_Object_Size)
+	}
+	if d, err := gl.chunkTimeout(); err != nil || d != 3*time.Second {
+		t.Fatalf("bad chunk timeout %v %v", d, err)
+	}
+	tags, err := cfg.Tags()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(tags, ",") != `docker,nginx` {
+		t.Fatalf("bad tags %v", tags)
+	}
+	gl.Chunk_Timeout = `bad`
+	if err = gl.Validate(); err == nil {
+		t.Fatal("failed to catch bad chunk timeout")
+	}
+}
+
+const gelfTestConfig = `
+[Global]
+Ingest-Secret = IngestSecrets
+Pipe-Backend-Target=/tmp/pipe
+
+[GELFListener "docker"]
+	Bind-String="udp://0.0.0.0:12201"
+	Tag-Name=docker
+	Chunk-Timeout=3s
+	Extractor=_container_name
+	Tag-Match=nginx:nginx
+`
@@ -81,7 +81,7 @@ func startJSONListeners(cfg *cfgType, igst *ingest.IngestMuxer, wg *sync.WaitGro
 			disableCompact:   v.Disable_Compact,
 			tsWindow:         window,
 		}
-		if jhc.proc, err = cfg.Preprocessor.ProcessorSet(igst, v.Preprocessor); err != nil {
+		if jhc.proc, err = cfg.processorSet(igst, v.Preprocessor); err != nil {
 			lg.Fatal("preprocessor error", log.KVErr(err))
 		}
 		f.Add(jhc.proc)
@@ -82,7 +82,7 @@ func lineConnHandlerTCP(c net.Conn, cfg handlerConfig) {
 		if len(data) > 0 {
 			if ent, err := handleLog(data, rip, cfg.ignoreTimestamps, cfg.tag, tg); err != nil {
 				return
-			} else if err = cfg.proc.ProcessContext(ent, cfg.ctx); err != nil {
+			} else if err = cfg.proc.ProcessContext(cfg.withConnEVs(ent), cfg.ctx); err != nil {
 				return
 			}
 		}
@@ -105,6 +105,12 @@ func main() {
 		return
 	}
 
+	//fire off our gelf listeners
+	if err := startGELFListeners(cfg, igst, wg, &flshr, ctx); err != nil {
+		lg.FatalCode(0, "Failed to start GELF listeners", log.KV("ingesteruuid", id), log.KVErr(err))
+		return
+	}
+
 	lg.Info("Ingester running")
 
 	//listen for signals so we can close gracefully
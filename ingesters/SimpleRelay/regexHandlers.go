@@ -80,7 +80,7 @@ func startRegexListeners(cfg *cfgType, igst *ingest.IngestMuxer, wg *sync.WaitGr
 			maxBuffer:        v.Max_Buffer,
 			tsWindow:         window,
 		}
-		if rhc.proc, err = cfg.Preprocessor.ProcessorSet(igst, v.Preprocessor); err != nil {
+		if rhc.proc, err = cfg.processorSet(igst, v.Preprocessor); err != nil {
 			lg.Fatal("preprocessor error", log.KVErr(err))
 		}
 		f.Add(rhc.proc)
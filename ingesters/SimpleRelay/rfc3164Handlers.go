@@ -0,0 +1,527 @@
+/*************************************************************************
+ * Copyright 2026 Gravwell, Inc. All rights reserved.
+ * Contact: <legal@gravwell.io>
+ *
+ * This software may be modified and distributed under the terms of the
+ * BSD 2-clause license. See the LICENSE file for details.
+ **************************************************************************/
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gravwell/gravwell/v3/ingest/entry"
+	"github.com/gravwell/gravwell/v3/ingest/log"
+	"github.com/gravwell/gravwell/v3/timegrinder"
+)
+
+const (
+	maxRFC3164Pri    = 191 // facility 23, severity 7
+	maxRFC3164AppLen = 48  // RFC3164 says 32, but plenty of vendors blow past that
+	maxRFC3164SeqLen = 10
+
+	hostCacheTTL      = 5 * time.Minute
+	hostCacheMaxSize  = 64 * 1024
+	hostLookupTimeout = 2 * time.Second
+)
+
+// rfc3164Header is the set of fields cracked out of the front of a BSD syslog message.
+// Everything is optional, a message with none of these fields is still a valid message.
+type rfc3164Header struct {
+	hasPri   bool
+	pri      int
+	hasSeq   bool
+	seq      uint64
+	ts       time.Time
+	hostname string
+	app      string
+	pid      string
+	msg      []byte
+}
+
+func (h rfc3164Header) facility() int {
+	return h.pri >> 3
+}
+
+func (h rfc3164Header) severity() int {
+	return h.pri & 0x7
+}
+
+// evs generates the enumerated values that get attached to each entry
+func (h rfc3164Header) evs() (r []entry.EnumeratedValue) {
+	if h.hasPri {
+		r = append(r,
+			entry.EnumeratedValue{Name: `priority`, Value: entry.IntEnumData(h.pri)},
+			entry.EnumeratedValue{Name: `facility`, Value: entry.IntEnumData(h.facility())},
+			entry.EnumeratedValue{Name: `severity`, Value: entry.IntEnumData(h.severity())},
+		)
+	}
+	if h.hasSeq {
+		r = append(r, entry.EnumeratedValue{Name: `sequence`, Value: entry.Uint64EnumData(h.seq)})
+	}
+	if h.hostname != `` {
+		r = append(r, entry.EnumeratedValue{Name: `hostname`, Value: entry.StringEnumData(h.hostname)})
+	}
+	if h.app != `` {
+		r = append(r, entry.EnumeratedValue{Name: `appname`, Value: entry.StringEnumData(h.app)})
+	}
+	if h.pid != `` {
+		if v, err := strconv.ParseInt(h.pid, 10, 64); err == nil {
+			r = append(r, entry.EnumeratedValue{Name: `pid`, Value: entry.IntEnumData(int(v))})
+		} else {
+			r = append(r, entry.EnumeratedValue{Name: `pid`, Value: entry.StringEnumData(h.pid)})
+		}
+	}
+	return
+}
+
+// rfc3164Parser is a tolerant BSD syslog header parser.  RFC3164 is descriptive rather than
+// prescriptive so we accept missing priorities, missing hostnames, Cisco style sequence numbers
+// and clock sync markers, and any of the header timestamp formats that timegrinder understands.
+// A parser is NOT safe for concurrent use.
+type rfc3164Parser struct {
+	tg    *timegrinder.TimeGrinder
+	procs []timegrinder.Processor
+	loc   *time.Location
+}
+
+func newRFC3164Parser(tg *timegrinder.TimeGrinder, loc *time.Location, window timegrinder.TimestampWindow) *rfc3164Parser {
+	if loc == nil {
+		loc = time.UTC
+	}
+	// ordering matters here, formats that are a prefix of another format must come later
+	procs := []timegrinder.Processor{
+		timegrinder.NewAnsiCProcessor(),
+		timegrinder.NewUnixProcessor(),
+		timegrinder.NewSyslogVariant(),
+		timegrinder.NewSyslogProcessor(),
+		timegrinder.NewSyslogFileProcessor(),
+		timegrinder.NewSyslogFileProcessorTZ2(),
+		timegrinder.NewRFC3339NanoProcessor(),
+		timegrinder.NewRFC3339Processor(),
+		timegrinder.NewZonelessRFC3339(),
+		timegrinder.NewDPKGProcessor(),
+	}
+	for _, p := range procs {
+		p.SetWindow(window)
+	}
+	return &rfc3164Parser{
+		tg:    tg,
+		procs: procs,
+		loc:   loc,
+	}
+}
+
+// Parse cracks the header out of a message, ok is true if we found at least a priority or a timestamp
+func (p *rfc3164Parser) Parse(b []byte) (h rfc3164Header, ok bool) {
+	rest := bytes.TrimLeft(b, " \t\r\n\x00")
+	if pri, n, lok := parseRFC3164Pri(rest); lok {
+		h.hasPri, h.pri = true, pri
+		rest = bytes.TrimLeft(rest[n:], " ")
+	}
+
+	// Cisco sequence numbers, optionally followed by an origin hostname "<189>52: router1: *Mar  1 ..."
+	if seq, n, lok := parseRFC3164Sequence(rest); lok {
+		h.hasSeq, h.seq = true, seq
+		rest = bytes.TrimLeft(rest[n:], " ")
+		if tok, n := nextToken(rest); len(tok) > 1 && tok[len(tok)-1] == ':' {
+			if _, _, lok := p.parseTimestamp(bytes.TrimLeft(rest[n:], " ")); lok {
+				h.hostname = string(tok[:len(tok)-1])
+				rest = bytes.TrimLeft(rest[n:], " ")
+			}
+		}
+	}
+
+	if ts, n, lok := p.parseTimestamp(rest); lok {
+		h.ts = ts
+		rest = rest[n:]
+		if len(rest) > 0 && rest[0] == ':' {
+			rest = rest[1:]
+		}
+		rest = bytes.TrimLeft(rest, " ")
+		// the hostname is only trustworthy if it follows a timestamp, if the next token
+		// looks like an application tag or a Cisco mnemonic the sender left it off
+		if tok, n := nextToken(rest); len(tok) > 0 && !looksLikeTag(tok) && n < len(rest) {
+			h.hostname = string(tok)
+			rest = bytes.TrimLeft(rest[n:], " ")
+		}
+	}
+	ok = h.hasPri || !h.ts.IsZero()
+
+	if app, pid, n, lok := parseRFC3164Tag(rest); lok {
+		h.app, h.pid = app, pid
+		rest = bytes.TrimLeft(rest[n:], " ")
+	}
+	h.msg = rest
+	return
+}
+
+// parseTimestamp attempts to pull a timestamp from the very start of the buffer.
+// The returned offset is the end of the timestamp, including any trailing fractional
+// seconds or timezone abbreviation that Cisco devices like to tack on.
+func (p *rfc3164Parser) parseTimestamp(b []byte) (ts time.Time, n int, ok bool) {
+	// Cisco prefixes timestamps with '*' when the clock is not authoritative and '.' when NTP is lost
+	if len(b) > 0 && (b[0] == '*' || b[0] == '.') {
+		n = 1
+		b = b[1:]
+	}
+	var end int
+	if ts, end, ok = p.overrideTimestamp(b); !ok {
+		for _, proc := range p.procs {
+			if start, e, lok := proc.Match(b); lok && start == 0 {
+				if ts, lok, _ = proc.Extract(b[:e], p.loc); lok {
+					end, ok = e, true
+					break
+				}
+			}
+		}
+	}
+	if !ok {
+		return time.Time{}, 0, false
+	}
+	b = b[end:]
+	n += end
+
+	// fractional seconds not covered by the format
+	if len(b) > 1 && b[0] == '.' && isDigit(b[1]) {
+		var i int
+		for i = 1; i < len(b) && isDigit(b[i]); i++ {
+		}
+		if frac, err := strconv.ParseFloat(string(b[:i]), 64); err == nil {
+			ts = ts.Add(time.Duration(frac * float64(time.Second)))
+		}
+		b = b[i:]
+		n += i
+	}
+	// timezone abbreviations "Mar  1 18:46:11.123 UTC: ..."
+	if len(b) > 2 && b[0] == ' ' {
+		var i int
+		for i = 1; i < len(b) && b[i] >= 'A' && b[i] <= 'Z'; i++ {
+		}
+		if i > 2 && i < len(b) && b[i] == ':' {
+			n += i
+		}
+	}
+	return
+}
+
+// overrideTimestamp honors the listener format override if one was specified
+func (p *rfc3164Parser) overrideTimestamp(b []byte) (ts time.Time, end int, ok bool) {
+	if p.tg == nil {
+		return
+	}
+	proc, err := p.tg.OverrideProcessor()
+	if err != nil || proc == nil {
+		return
+	}
+	var start int
+	if start, end, ok = proc.Match(b); !ok || start != 0 {
+		return time.Time{}, 0, false
+	}
+	ts, ok, _ = proc.Extract(b[:end], p.loc)
+	return
+}
+
+func parseRFC3164Pri(b []byte) (pri, n int, ok bool) {
+	if len(b) < 3 || b[0] != '<' {
+		return
+	}
+	var i int
+	for i = 1; i < len(b) && i <= 4 && isDigit(b[i]); i++ {
+	}
+	if i == 1 || i > 4 || i >= len(b) || b[i] != '>' {
+		return
+	}
+	v, err := strconv.Atoi(string(b[1:i]))
+	if err != nil || v > maxRFC3164Pri {
+		return
+	}
+	return v, i + 1, true
+}
+
+// parseRFC3164Sequence looks for the Cisco "NNN: " sequence number
+func parseRFC3164Sequence(b []byte) (seq uint64, n int, ok bool) {
+	var i int
+	for i = 0; i < len(b) && isDigit(b[i]); i++ {
+	}
+	if i == 0 || i > maxRFC3164SeqLen || (i+1) >= len(b) || b[i] != ':' || b[i+1] != ' ' {
+		return
+	}
+	v, err := strconv.ParseUint(string(b[:i]), 10, 64)
+	if err != nil {
+		return
+	}
+	return v, i + 1, true
+}
+
+// parseRFC3164Tag extracts the "app[pid]:" tag, n is the offset of the message content
+func parseRFC3164Tag(b []byte) (app, pid string, n int, ok bool) {
+	tok, _ := nextToken(b)
+	if len(tok) < 2 {
+		return
+	}
+	var end int
+	if end = bytes.IndexAny(tok, "[:"); end <= 0 || end > maxRFC3164AppLen {
+		return
+	}
+	app = string(tok[:end])
+	if tok[end] == '[' {
+		cl := bytes.IndexByte(tok[end:], ']')
+		if cl == -1 {
+			return ``, ``, 0, false
+		}
+		pid = string(tok[end+1 : end+cl])
+		end += cl + 1
+		if end < len(tok) && tok[end] != ':' {
+			return ``, ``, 0, false
+		}
+	}
+	if end >= len(tok) || tok[end] != ':' {
+		// RFC3164 lets the colon be omitted after a pid
+		if pid == `` || end != len(tok) {
+			return ``, ``, 0, false
+		}
+		return app, pid, end, true
+	}
+	return app, pid, end + 1, true
+}
+
+// looksLikeTag checks if a token is an application tag or Cisco mnemonic rather than a hostname
+func looksLikeTag(tok []byte) bool {
+	if len(tok) == 0 {
+		return false
+	}
+	return tok[0] == '%' || tok[len(tok)-1] == ':' || bytes.IndexByte(tok, '[') != -1
+}
+
+func nextToken(b []byte) (tok []byte, n int) {
+	if n = bytes.IndexByte(b, ' '); n == -1 {
+		n = len(b)
+	}
+	tok = b[:n]
+	return
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// hostResolver is a small caching resolver used to map syslog hostnames to entry SRC values.
+// Failed lookups are cached too so that a dead DNS server does not stall ingest.
+type hostResolver struct {
+	sync.Mutex
+	ttl   time.Duration
+	cache map[string]hostCacheEntry
+	look  func(context.Context, string) ([]net.IPAddr, error)
+}
+
+type hostCacheEntry struct {
+	ip     net.IP
+	expire time.Time
+}
+
+func newHostResolver() *hostResolver {
+	return &hostResolver{
+		ttl:   hostCacheTTL,
+		cache: make(map[string]hostCacheEntry),
+		look:  net.DefaultResolver.LookupIPAddr,
+	}
+}
+
+// Resolve returns the address for a hostname, nil is returned if it cannot be resolved.
+func (hr *hostResolver) Resolve(ctx context.Context, host string) (ip net.IP) {
+	if host == `` || host == `-` {
+		return
+	} else if ip = net.ParseIP(host); ip != nil {
+		return
+	}
+	now := time.Now()
+	hr.Lock()
+	ce, ok := hr.cache[host]
+	hr.Unlock()
+	if ok && now.Before(ce.expire) {
+		return ce.ip
+	}
+
+	lctx, cf := context.WithTimeout(ctx, hostLookupTimeout)
+	addrs, err := hr.look(lctx, host)
+	cf()
+	if err == nil {
+		for _, a := range addrs {
+			if ip == nil || (ip.To4() == nil && a.IP.To4() != nil) {
+				ip = a.IP // prefer v4 addresses
+			}
+		}
+	}
+
+	hr.Lock()
+	if len(hr.cache) >= hostCacheMaxSize {
+		hr.cache = make(map[string]hostCacheEntry)
+	}
+	hr.cache[host] = hostCacheEntry{ip: ip, expire: now.Add(hr.ttl)}
+	hr.Unlock()
+	return
+}
+
+// handleRFC3164 generates an entry from a single message, the header values are attached as enumerated values
+func handleRFC3164(data []byte, rip net.IP, cfg handlerConfig, p *rfc3164Parser) (ent *entry.Entry) {
+	if len(data) == 0 {
+		return
+	}
+	h, _ := p.Parse(data)
+	if cfg.dropPriority {
+		data = dropPriority(data)
+	}
+	var ts entry.Timestamp
+	if cfg.ignoreTimestamps {
+		ts = entry.Now()
+	} else if !h.ts.IsZero() {
+		ts = entry.FromStandard(h.ts)
+	} else if t, ok, err := p.tg.Extract(data); err == nil && ok {
+		ts = entry.FromStandard(t)
+	} else {
+		ts = entry.Now()
+	}
+	if cfg.src == nil && cfg.resolver != nil {
+		if ip := cfg.resolver.Resolve(cfg.ctx, h.hostname); ip != nil {
+			rip = ip
+		}
+	}
+	ent = &entry.Entry{
+		SRC:  rip,
+		TS:   ts,
+		Tag:  cfg.tag,
+		Data: data,
+	}
+	if evs := h.evs(); len(evs) > 0 {
+		ent.AddEnumeratedValues(evs)
+	}
+	ent = cfg.withConnEVs(ent)
+	return
+}
+
+func newRFC3164HandlerParser(cfg handlerConfig) (p *rfc3164Parser, err error) {
+	var tg *timegrinder.TimeGrinder
+	tcfg := timegrinder.Config{
+		TSWindow:           cfg.tsWindow,
+		EnableLeftMostSeed: true,
+	}
+	if tg, err = timegrinder.NewTimeGrinder(tcfg); err != nil {
+		return
+	} else if err = cfg.timeFormats.LoadFormats(tg); err != nil {
+		return
+	}
+	loc := time.UTC
+	if cfg.setLocalTime {
+		tg.SetLocalTime()
+		loc = time.Local
+	}
+	if cfg.timezoneOverride != `` {
+		if err = tg.SetTimezone(cfg.timezoneOverride); err != nil {
+			return
+		} else if loc, err = time.LoadLocation(cfg.timezoneOverride); err != nil {
+			return
+		}
+	}
+	if cfg.formatOverride != `` {
+		if err = tg.SetFormatOverride(cfg.formatOverride); err != nil {
+			return
+		}
+	}
+	p = newRFC3164Parser(tg, loc, cfg.tsWindow)
+	return
+}
+
+func rfc3164ConnHandlerTCP(c net.Conn, cfg handlerConfig) {
+	cfg.wg.Add(1)
+	id := addConn(c)
+	defer cfg.wg.Done()
+	defer delConn(id)
+	defer c.Close()
+	var rip net.IP
+
+	if cfg.src == nil {
+		ipstr, _, err := net.SplitHostPort(c.RemoteAddr().String())
+		if err != nil {
+			lg.Error("failed to get host from remote address", log.KV("address", c.RemoteAddr()), log.KVErr(err))
+			return
+		}
+		if rip = net.ParseIP(ipstr); rip == nil {
+			lg.Error("failed to parse remote address", log.KV("address", ipstr))
+			return
+		}
+	} else {
+		rip = cfg.src
+	}
+
+	p, err := newRFC3164HandlerParser(cfg)
+	if err != nil {
+		lg.Error("failed to build RFC3164 parser", log.KV("listener", cfg.name), log.KVErr(err))
+		return
+	}
+	rfc3164Loop(bufio.NewReader(c), rip, cfg, p)
+}
+
+func rfc3164Loop(bio *bufio.Reader, rip net.IP, cfg handlerConfig, p *rfc3164Parser) {
+	for {
+		data, err := bio.ReadBytes('\n')
+		if data = bytes.Trim(data, "\n\r\t \x00"); len(data) > 0 {
+			if ent := handleRFC3164(data, rip, cfg, p); ent != nil {
+				if lerr := cfg.proc.ProcessContext(ent, cfg.ctx); lerr != nil {
+					return
+				}
+			}
+		}
+		if err != nil {
+			if err != io.EOF {
+				lg.Info("RFC3164 connection closed", log.KV("listener", cfg.name), log.KVErr(err))
+			}
+			return
+		}
+	}
+}
+
+func rfc3164ConnHandlerUDP(c *net.UDPConn, cfg handlerConfig) {
+	buff := make([]byte, 16*1024) //local buffer that should be big enough for even the largest UDP packets
+	p, err := newRFC3164HandlerParser(cfg)
+	if err != nil {
+		lg.Error("failed to build RFC3164 parser", log.KV("listener", cfg.name), log.KVErr(err))
+		return
+	}
+
+	var rip net.IP
+	for {
+		n, raddr, err := c.ReadFromUDP(buff)
+		if err != nil {
+			break
+		}
+		if n == 0 || raddr == nil || n > len(buff) {
+			continue
+		}
+		if cfg.src == nil {
+			rip = raddr.IP
+		} else {
+			rip = cfg.src
+		}
+		// some senders batch multiple messages into a single datagram
+		for _, ln := range bytes.Split(buff[:n], []byte("\n")) {
+			if ln = bytes.Trim(ln, "\n\r\t \x00"); len(ln) == 0 {
+				continue
+			}
+			//because we are using and reusing a local buffer, we have to copy the bytes when handing in
+			if ent := handleRFC3164(bytes.Clone(ln), rip, cfg, p); ent != nil {
+				if err = cfg.proc.ProcessContext(ent, cfg.ctx); err != nil {
+					return
+				}
+			}
+		}
+	}
+}
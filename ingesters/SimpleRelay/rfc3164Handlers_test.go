@@ -0,0 +1,190 @@
+/*************************************************************************
+ * Copyright 2026 Gravwell, Inc. All rights reserved.
+ * Contact: <legal@gravwell.io>
+ *
+ * This software may be modified and distributed under the terms of the
+ * BSD 2-clause license. See the LICENSE file for details.
+ **************************************************************************/
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"net"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gravwell/gravwell/v3/ingest/entry"
+	"github.com/gravwell/gravwell/v3/ingest/log"
+	"github.com/gravwell/gravwell/v3/ingest/processors"
+	"github.com/gravwell/gravwell/v3/timegrinder"
+)
+
+type rfc3164Test struct {
+	val      string
+	pri      int // -1 means no priority
+	seq      int // -1 means no sequence
+	ts       time.Time
+	hostname string
+	app      string
+	pid      string
+	msg      string
+}
+
+func TestRFC3164Parse(t *testing.T) {
+	year := time.Now().Year()
+	if time.Date(year, time.March, 1, 18, 46, 11, 0, time.UTC).After(time.Now().Add(24 * time.Hour)) {
+		year--
+	}
+	mar1 := time.Date(year, time.March, 1, 18, 46, 11, 0, time.UTC)
+	tsts := []rfc3164Test{
+		{
+			val: `<34>Mar  1 18:46:11 mymachine su: 'su root' failed for lonvick on /dev/pts/8`,
+			pri: 34, seq: -1, ts: mar1, hostname: `mymachine`, app: `su`,
+			msg: `'su root' failed for lonvick on /dev/pts/8`,
+		},
+		{
+			val: `<13>Mar  1 18:46:11 host1 sshd[1234]: Accepted publickey for root`,
+			pri: 13, seq: -1, ts: mar1, hostname: `host1`, app: `sshd`, pid: `1234`,
+			msg: `Accepted publickey for root`,
+		},
+		{
+			// missing hostname
+			val: `<13>Mar  1 18:46:11 sshd[1234]: Accepted publickey for root`,
+			pri: 13, seq: -1, ts: mar1, app: `sshd`, pid: `1234`,
+			msg: `Accepted publickey for root`,
+		},
+		{
+			// Cisco with sequence number, unsynchronized clock and milliseconds
+			val: `<189>52: *Mar  1 18:46:11.500: %SYS-5-CONFIG_I: Configured from console by vty0`,
+			pri: 189, seq: 52, ts: mar1.Add(500 * time.Millisecond), app: `%SYS-5-CONFIG_I`,
+			msg: `Configured from console by vty0`,
+		},
+		{
+			// Cisco with sequence number, origin hostname and a timezone
+			val: `<189>000123: router1: Mar  1 18:46:11 UTC: %LINK-3-UPDOWN: Interface Gi0/1, changed state to up`,
+			pri: 189, seq: 123, ts: mar1, hostname: `router1`, app: `%LINK-3-UPDOWN`,
+			msg: `Interface Gi0/1, changed state to up`,
+		},
+		{
+			val: `<14>2023-03-01T18:46:11Z fw01 kernel: dropped packet`,
+			pri: 14, seq: -1, ts: time.Date(2023, time.March, 1, 18, 46, 11, 0, time.UTC), hostname: `fw01`, app: `kernel`,
+			msg: `dropped packet`,
+		},
+		{
+			// no priority at all
+			val: `Mar  1 18:46:11 mymachine cron[99] (root) CMD (run-parts /etc/cron.hourly)`,
+			pri: -1, seq: -1, ts: mar1, hostname: `mymachine`, app: `cron`, pid: `99`,
+			msg: `(root) CMD (run-parts /etc/cron.hourly)`,
+		},
+		{
+			// nothing but a priority
+			val: `<30>just some text: with a colon`,
+			pri: 30, seq: -1, msg: `just some text: with a colon`,
+		},
+	}
+	p := newRFC3164Parser(nil, time.UTC, timegrinder.TimestampWindow{})
+	for _, tst := range tsts {
+		h, ok := p.Parse([]byte(tst.val))
+		if !ok {
+			t.Fatalf("failed to parse %q", tst.val)
+		}
+		if tst.pri >= 0 && (!h.hasPri || h.pri != tst.pri) {
+			t.Fatalf("bad priority on %q: %v %d", tst.val, h.hasPri, h.pri)
+		} else if tst.pri < 0 && h.hasPri {
+			t.Fatalf("unexpected priority on %q", tst.val)
+		}
+		if tst.seq >= 0 && (!h.hasSeq || h.seq != uint64(tst.seq)) {
+			t.Fatalf("bad sequence on %q: %v %d", tst.val, h.hasSeq, h.seq)
+		} else if tst.seq < 0 && h.hasSeq {
+			t.Fatalf("unexpected sequence on %q", tst.val)
+		}
+		if !h.ts.Equal(tst.ts) {
+			t.Fatalf("bad timestamp on %q: %v != %v", tst.val, h.ts, tst.ts)
+		}
+		if h.hostname != tst.hostname {
+			t.Fatalf("bad hostname on %q: %q != %q", tst.val, h.hostname, tst.hostname)
+		} else if h.app != tst.app {
+			t.Fatalf("bad app on %q: %q != %q", tst.val, h.app, tst.app)
+		} else if h.pid != tst.pid {
+			t.Fatalf("bad pid on %q: %q != %q", tst.val, h.pid, tst.pid)
+		} else if string(h.msg) != tst.msg {
+			t.Fatalf("bad message on %q: %q != %q", tst.val, h.msg, tst.msg)
+		}
+	}
+}
+
+func TestRFC3164Loop(t *testing.T) {
+	lg = log.New(os.Stderr)
+	input := "<34>Oct 11 22:14:15 mymachine su: 'su root' failed\n" +
+		"<13>Oct 11 22:14:16 10.1.2.3 sshd[42]: Accepted\n" +
+		"<13>Oct 11 22:14:17 unresolvable sshd[42]: Accepted\n"
+	trk := &tracker{}
+	cfg := handlerConfig{
+		wg:       &sync.WaitGroup{},
+		ctx:      context.Background(),
+		proc:     processors.NewProcessorSet(&nilWriter{}),
+		resolver: newHostResolver(),
+	}
+	cfg.proc.AddProcessor(trk)
+	cfg.resolver.look = func(_ context.Context, host string) ([]net.IPAddr, error) {
+		if host == `mymachine` {
+			return []net.IPAddr{{IP: net.ParseIP(`::1`)}, {IP: net.ParseIP(`192.168.1.1`)}}, nil
+		}
+		return nil, errors.New("no such host")
+	}
+	p, err := newRFC3164HandlerParser(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rip := net.ParseIP(`172.16.0.1`)
+	rfc3164Loop(bufio.NewReader(bytes.NewBufferString(input)), rip, cfg, p)
+	if len(trk.ents) != 3 {
+		t.Fatalf("Expected 3 entries, got %d", len(trk.ents))
+	}
+	expected := []string{`192.168.1.1`, `10.1.2.3`, `172.16.0.1`}
+	for i, ent := range trk.ents {
+		if ent.SRC.String() != expected[i] {
+			t.Fatalf("bad SRC on entry %d: %v != %v", i, ent.SRC, expected[i])
+		}
+		if ent.TS.StandardTime().Month() != time.October {
+			t.Fatalf("bad timestamp on entry %d: %v", i, ent.TS)
+		}
+		ev, ok := ent.GetEnumeratedValue(`appname`)
+		if !ok {
+			t.Fatalf("missing appname EV on entry %d", i)
+		} else if s, ok := ev.(string); !ok || (s != `su` && s != `sshd`) {
+			t.Fatalf("bad appname on entry %d: %v", i, ev)
+		}
+		if ev, ok = ent.GetEnumeratedValue(`severity`); !ok {
+			t.Fatalf("missing severity EV on entry %d", i)
+		}
+	}
+}
+
+func TestRFC3164HandleDropPriority(t *testing.T) {
+	cfg := handlerConfig{
+		tag:          entry.EntryTag(3),
+		dropPriority: true,
+		ctx:          context.Background(),
+	}
+	p, err := newRFC3164HandlerParser(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ent := handleRFC3164([]byte(`<13>Oct 11 22:14:17 host app: msg`), net.ParseIP(`1.2.3.4`), cfg, p)
+	if ent == nil {
+		t.Fatal("nil entry")
+	} else if string(ent.Data) != `Oct 11 22:14:17 host app: msg` {
+		t.Fatalf("priority not dropped: %q", ent.Data)
+	} else if ent.Tag != cfg.tag {
+		t.Fatalf("bad tag %v", ent.Tag)
+	} else if _, ok := ent.GetEnumeratedValue(`priority`); !ok {
+		t.Fatal("missing priority EV")
+	}
+}
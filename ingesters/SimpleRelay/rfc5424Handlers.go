@@ -151,7 +151,7 @@ func rfc5424ConnHandlerTCP(c net.Conn, cfg handlerConfig) {
 		data = bytes.Clone(data) // the scanner re-uses bytes, so we have to clone
 		if ent, err := handleLog(data, rip, cfg.ignoreTimestamps, cfg.tag, tg); err != nil {
 			return
-		} else if err = cfg.proc.ProcessContext(ent, cfg.ctx); err != nil {
+		} else if err = cfg.proc.ProcessContext(cfg.withConnEVs(ent), cfg.ctx); err != nil {
 			return
 		}
 	}
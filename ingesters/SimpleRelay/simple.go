@@ -50,6 +50,9 @@ type handlerConfig struct {
 	ctx              context.Context
 	timeFormats      config.CustomTimeFormat
 	tsWindow         timegrinder.TimestampWindow
+	resolver         *hostResolver // only set when the listener wants SRC derived from hostnames
+	clientAuth       bool          // TLS and DTLS listeners that require verified client certificates
+	connEVs          []entry.EnumeratedValue
 }
 
 func startSimpleListeners(cfg *cfgType, igst *ingest.IngestMuxer, wg *sync.WaitGroup, f *flusher, ctx context.Context) error {
@@ -105,8 +108,12 @@ func startSimpleListeners(cfg *cfgType, igst *ingest.IngestMuxer, wg *sync.WaitG
 			ctx:              ctx,
 			timeFormats:      cfg.TimeFormat,
 			tsWindow:         window,
+			clientAuth:       v.Client_CA_File != ``,
 		}
-		if hcfg.proc, err = cfg.Preprocessor.ProcessorSet(igst, v.Preprocessor); err != nil {
+		if v.Source_From_Hostname && src == nil {
+			hcfg.resolver = newHostResolver()
+		}
+		if hcfg.proc, err = cfg.processorSet(igst, v.Preprocessor); err != nil {
 			lg.Fatal("preprocessor error", log.KVErr(err))
 		}
 		f.Add(hcfg.proc)
@@ -125,14 +132,9 @@ func startSimpleListeners(cfg *cfgType, igst *ingest.IngestMuxer, wg *sync.WaitG
 			wg.Add(1)
 			go acceptor(l, connID, igst, hcfg, tp)
 		} else if tp.TLS() {
-			config := &tls.Config{
-				MinVersion: tls.VersionTLS12,
-			}
-
-			config.Certificates = make([]tls.Certificate, 1)
-			config.Certificates[0], err = tls.LoadX509KeyPair(v.Cert_File, v.Key_File)
+			config, err := newTLSConfig(v)
 			if err != nil {
-				lg.FatalCode(0, "failed to load certificate", log.KV("certfile", v.Cert_File), log.KV("keyfile", v.Key_File), log.KVErr(err))
+				lg.FatalCode(0, "failed to load TLS configuration", log.KV("certfile", v.Cert_File), log.KV("keyfile", v.Key_File), log.KV("clientcafile", v.Client_CA_File), log.KVErr(err))
 			}
 			//get the socket
 			addr, err := net.ResolveTCPAddr("tcp", str)
@@ -147,6 +149,10 @@ func startSimpleListeners(cfg *cfgType, igst *ingest.IngestMuxer, wg *sync.WaitG
 			//start the acceptor
 			wg.Add(1)
 			go acceptor(l, connID, igst, hcfg, tp)
+		} else if tp.DTLS() {
+			if err = startDTLSListener(v, str, hcfg); err != nil {
+				lg.FatalCode(0, "failed to listen via DTLS", log.KV("bindstring", v.Bind_String), log.KV("listener", k), log.KVErr(err))
+			}
 		} else if tp.UDP() {
 			addr, err := net.ResolveUDPAddr(tp.String(), str)
 			if err != nil {
@@ -187,17 +193,34 @@ func acceptor(lst net.Listener, id int, igst *ingest.IngestMuxer, cfg handlerCon
 		debugout("Accepted %v connection from %s in %v mode\n", conn.RemoteAddr(), cfg.lrt, tp.String())
 		lg.Info("accepted connection", log.KV("address", conn.RemoteAddr()), log.KV("readertype", cfg.lrt), log.KV("mode", tp), log.KV("listener", cfg.name))
 		failCount = 0
-		switch cfg.lrt {
-		case lineReader:
-			go lineConnHandlerTCP(conn, cfg)
-		case rfc5424Reader:
-			go rfc5424ConnHandlerTCP(conn, cfg)
-		case rfc6587Reader:
-			go rfc6587ConnHandlerTCP(conn, cfg)
-		default:
-			lg.Error("invalid reader type", log.KV("readertype", cfg.lrt))
+		go dispatchConn(conn, cfg)
+	}
+}
+
+// dispatchConn hands a connection off to the appropriate reader, TLS connections that
+// require client certificates are handshaked first so we can reject them early
+func dispatchConn(conn net.Conn, cfg handlerConfig) {
+	if tc, ok := conn.(*tls.Conn); ok && cfg.clientAuth {
+		evs, err := tlsHandshake(cfg.ctx, tc)
+		if err != nil {
+			lg.Warn("TLS client rejected", log.KV("address", conn.RemoteAddr()), log.KV("listener", cfg.name), log.KVErr(err))
+			conn.Close()
 			return
 		}
+		cfg.connEVs = evs
+	}
+	switch cfg.lrt {
+	case lineReader:
+		lineConnHandlerTCP(conn, cfg)
+	case rfc5424Reader:
+		rfc5424ConnHandlerTCP(conn, cfg)
+	case rfc6587Reader:
+		rfc6587ConnHandlerTCP(conn, cfg)
+	case rfc3164Reader:
+		rfc3164ConnHandlerTCP(conn, cfg)
+	default:
+		lg.Error("invalid reader type", log.KV("readertype", cfg.lrt))
+		conn.Close()
 	}
 }
 
@@ -211,6 +234,8 @@ func acceptorUDP(conn *net.UDPConn, id int, cfg handlerConfig, igst *ingest.Inge
 		lineConnHandlerUDP(conn, cfg)
 	case rfc5424Reader:
 		rfc5424ConnHandlerUDP(conn, cfg)
+	case rfc3164Reader:
+		rfc3164ConnHandlerUDP(conn, cfg)
 	default:
 		lg.Error("invalid reader type", log.KV("readertype", cfg.lrt))
 		return
@@ -245,6 +270,14 @@ func handleLog(b []byte, ip net.IP, ignoreTS bool, tag entry.EntryTag, tg *timeg
 	return
 }
 
+// withConnEVs attaches any connection level enumerated values, such as the verified client certificate subject
+func (hc handlerConfig) withConnEVs(ent *entry.Entry) *entry.Entry {
+	if ent != nil && len(hc.connEVs) > 0 {
+		ent.AddEnumeratedValues(hc.connEVs)
+	}
+	return ent
+}
+
 func addConn(c closer) int {
 	mtx.Lock()
 	connId++
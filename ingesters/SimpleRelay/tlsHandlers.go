@@ -0,0 +1,213 @@
+/*************************************************************************
+ * Copyright 2026 Gravwell, Inc. All rights reserved.
+ * Contact: <legal@gravwell.io>
+ *
+ * This software may be modified and distributed under the terms of the
+ * BSD 2-clause license. See the LICENSE file for details.
+ **************************************************************************/
+
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gravwell/gravwell/v3/ingest/entry"
+	"github.com/gravwell/gravwell/v3/ingest/log"
+	"github.com/pion/dtls/v2"
+	pudp "github.com/pion/transport/v2/udp"
+)
+
+const (
+	tlsHandshakeTimeout = 10 * time.Second
+	maxDTLSRecordSize   = 16 * 1024
+
+	clientSubjectEV = `client_subject`
+)
+
+var (
+	errNoClientCert = errors.New("client did not present a certificate")
+)
+
+// loadClientCAs reads a PEM encoded CA bundle used to verify client certificates
+func loadClientCAs(pth string) (*x509.CertPool, error) {
+	bts, err := os.ReadFile(pth)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(bts) {
+		return nil, fmt.Errorf("no certificates found in %q", pth)
+	}
+	return pool, nil
+}
+
+// newTLSConfig builds the TLS config for a listener, if a client CA bundle is provided
+// clients MUST present a certificate signed by one of the CAs in the bundle (RFC5425 section 5.3)
+func newTLSConfig(l *listener) (config *tls.Config, err error) {
+	config = &tls.Config{
+		MinVersion: tls.VersionTLS12,
+	}
+	config.Certificates = make([]tls.Certificate, 1)
+	if config.Certificates[0], err = tls.LoadX509KeyPair(l.Cert_File, l.Key_File); err != nil {
+		return
+	}
+	if l.Client_CA_File != `` {
+		if config.ClientCAs, err = loadClientCAs(l.Client_CA_File); err != nil {
+			return
+		}
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return
+}
+
+// newDTLSConfig builds the DTLS config for an RFC6012 listener
+func newDTLSConfig(l *listener) (config *dtls.Config, err error) {
+	var cert tls.Certificate
+	if cert, err = tls.LoadX509KeyPair(l.Cert_File, l.Key_File); err != nil {
+		return
+	}
+	config = &dtls.Config{
+		Certificates:         []tls.Certificate{cert},
+		ExtendedMasterSecret: dtls.RequireExtendedMasterSecret,
+	}
+	if l.Client_CA_File != `` {
+		if config.ClientCAs, err = loadClientCAs(l.Client_CA_File); err != nil {
+			return
+		}
+		config.ClientAuth = dtls.RequireAndVerifyClientCert
+	}
+	return
+}
+
+// clientCertEVs generates the enumerated values describing a verified peer
+func clientCertEVs(certs []*x509.Certificate) ([]entry.EnumeratedValue, error) {
+	if len(certs) == 0 || certs[0] == nil {
+		return nil, errNoClientCert
+	}
+	return []entry.EnumeratedValue{
+		entry.EnumeratedValue{Name: clientSubjectEV, Value: entry.StringEnumData(certs[0].Subject.String())},
+	}, nil
+}
+
+// tlsHandshake forces the handshake on a freshly accepted connection so that we can
+// reject unauthenticated clients before reading anything and grab the peer identity
+func tlsHandshake(ctx context.Context, c *tls.Conn) ([]entry.EnumeratedValue, error) {
+	hctx, cf := context.WithTimeout(ctx, tlsHandshakeTimeout)
+	defer cf()
+	if err := c.HandshakeContext(hctx); err != nil {
+		return nil, err
+	}
+	return clientCertEVs(c.ConnectionState().PeerCertificates)
+}
+
+// startDTLSListener starts an RFC6012 syslog over DTLS listener.
+// Handshakes are performed outside of the accept loop so that a misbehaving client cannot stall other clients.
+func startDTLSListener(l *listener, str string, hcfg handlerConfig) (err error) {
+	var addr *net.UDPAddr
+	var lst net.Listener
+	var dcfg *dtls.Config
+	if dcfg, err = newDTLSConfig(l); err != nil {
+		return
+	} else if addr, err = net.ResolveUDPAddr(`udp`, str); err != nil {
+		return
+	}
+	lc := pudp.ListenConfig{}
+	if lst, err = lc.Listen(`udp`, addr); err != nil {
+		return
+	}
+	connID := addConn(lst)
+	hcfg.wg.Add(1)
+	go dtlsAcceptor(lst, connID, dcfg, hcfg)
+	return
+}
+
+func dtlsAcceptor(lst net.Listener, id int, dcfg *dtls.Config, cfg handlerConfig) {
+	var failCount int
+	defer cfg.wg.Done()
+	defer delConn(id)
+	defer lst.Close()
+	for {
+		conn, err := lst.Accept()
+		if err != nil {
+			if strings.Contains(err.Error(), "closed") {
+				break
+			}
+			failCount++
+			lg.Warn("failed to accept DTLS association", log.KVErr(err))
+			if failCount > 3 {
+				break
+			}
+			continue
+		}
+		failCount = 0
+		go dtlsHandshake(conn, dcfg, cfg)
+	}
+}
+
+func dtlsHandshake(conn net.Conn, dcfg *dtls.Config, cfg handlerConfig) {
+	hctx, cf := context.WithTimeout(cfg.ctx, tlsHandshakeTimeout)
+	dc, err := dtls.ServerWithContext(hctx, conn, dcfg)
+	cf()
+	if err != nil {
+		lg.Warn("DTLS handshake failed", log.KV("address", conn.RemoteAddr()), log.KV("listener", cfg.name), log.KVErr(err))
+		conn.Close()
+		return
+	}
+	if cfg.clientAuth {
+		var certs []*x509.Certificate
+		for _, raw := range dc.ConnectionState().PeerCertificates {
+			if c, err := x509.ParseCertificate(raw); err == nil {
+				certs = append(certs, c)
+			}
+		}
+		if cfg.connEVs, err = clientCertEVs(certs); err != nil {
+			lg.Warn("DTLS client rejected", log.KV("address", conn.RemoteAddr()), log.KV("listener", cfg.name), log.KVErr(err))
+			dc.Close()
+			return
+		}
+	}
+	lg.Info("accepted connection", log.KV("address", dc.RemoteAddr()), log.KV("readertype", cfg.lrt), log.KV("mode", DTLS), log.KV("listener", cfg.name))
+	dispatchConn(newDatagramConn(dc), cfg)
+}
+
+// datagramConn adapts a DTLS association to a stream so that the TCP handlers can consume it.
+// DTLS reads are record oriented and will fail if the buffer cannot hold a full record,
+// so we always read into a record sized buffer and then hand it out as requested.
+// Each record is a complete message, so records that do not end in a newline get one
+// to keep the stream readers from joining them with the next record.
+type datagramConn struct {
+	net.Conn
+	buff    []byte
+	pending []byte
+}
+
+func newDatagramConn(c net.Conn) *datagramConn {
+	return &datagramConn{
+		Conn: c,
+		buff: make([]byte, maxDTLSRecordSize+1), //room for the delimiter
+	}
+}
+
+func (dc *datagramConn) Read(b []byte) (n int, err error) {
+	if len(dc.pending) == 0 {
+		if n, err = dc.Conn.Read(dc.buff[:maxDTLSRecordSize]); n <= 0 {
+			return
+		}
+		if dc.buff[n-1] != '\n' {
+			dc.buff[n] = '\n'
+			n++
+		}
+		dc.pending = dc.buff[:n]
+	}
+	n = copy(b, dc.pending)
+	dc.pending = dc.pending[n:]
+	return n, nil
+}
@@ -0,0 +1,391 @@
+/*************************************************************************
+ * Copyright 2026 Gravwell, Inc. All rights reserved.
+ * Contact: <legal@gravwell.io>
+ *
+ * This software may be modified and distributed under the terms of the
+ * BSD 2-clause license. See the LICENSE file for details.
+ **************************************************************************/
+
+package main
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gravwell/gravwell/v3/ingest/entry"
+	"github.com/gravwell/gravwell/v3/ingest/log"
+	"github.com/gravwell/gravwell/v3/ingest/processors"
+	"github.com/pion/dtls/v2"
+)
+
+type testPKI struct {
+	caPool     *x509.CertPool
+	caFile     string
+	serverCert string
+	serverKey  string
+	client     tls.Certificate
+}
+
+func newTestPKI(t *testing.T) (p testPKI) {
+	dir := t.TempDir()
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caTmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: `test CA`},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTmpl, caTmpl, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caCert, err := x509.ParseCertificate(caDER)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.caPool = x509.NewCertPool()
+	p.caPool.AddCert(caCert)
+	p.caFile = writeTestPEM(t, dir, `ca.pem`, `CERTIFICATE`, caDER)
+
+	issue := func(serial int64, cn string, usage x509.ExtKeyUsage) ([]byte, *ecdsa.PrivateKey) {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tmpl := &x509.Certificate{
+			SerialNumber: big.NewInt(serial),
+			Subject:      pkix.Name{CommonName: cn, Organization: []string{`Gravwell`}},
+			NotBefore:    time.Now().Add(-time.Hour),
+			NotAfter:     time.Now().Add(time.Hour),
+			KeyUsage:     x509.KeyUsageDigitalSignature,
+			ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+			IPAddresses:  []net.IP{net.ParseIP(`127.0.0.1`)},
+		}
+		der, err := x509.CreateCertificate(rand.Reader, tmpl, caCert, &key.PublicKey, caKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return der, key
+	}
+
+	srvDER, srvKey := issue(2, `server`, x509.ExtKeyUsageServerAuth)
+	p.serverCert = writeTestPEM(t, dir, `server.pem`, `CERTIFICATE`, srvDER)
+	kb, err := x509.MarshalECPrivateKey(srvKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.serverKey = writeTestPEM(t, dir, `server.key`, `EC PRIVATE KEY`, kb)
+
+	cliDER, cliKey := issue(3, `router1`, x509.ExtKeyUsageClientAuth)
+	p.client = tls.Certificate{
+		Certificate: [][]byte{cliDER},
+		PrivateKey:  cliKey,
+	}
+	return
+}
+
+func writeTestPEM(t *testing.T, dir, name, tp string, der []byte) string {
+	pth := filepath.Join(dir, name)
+	if err := os.WriteFile(pth, pem.EncodeToMemory(&pem.Block{Type: tp, Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return pth
+}
+
+// chanTracker is a preprocessor that hands entries back to the test over a channel
+type chanTracker struct {
+	ch chan *entry.Entry
+}
+
+func (t *chanTracker) Process(ents []*entry.Entry) ([]*entry.Entry, error) {
+	for _, e := range ents {
+		t.ch <- e
+	}
+	return ents, nil
+}
+
+func (t *chanTracker) Flush() []*entry.Entry { return nil }
+func (t *chanTracker) Close() error          { return nil }
+
+func newTLSTestHandlerConfig(t *testing.T) (handlerConfig, chan *entry.Entry) {
+	lg = log.New(os.Stderr)
+	mtx.Lock()
+	if connClosers == nil {
+		connClosers = make(map[int]closer)
+	}
+	mtx.Unlock()
+	ch := make(chan *entry.Entry, 16)
+	ctx, cf := context.WithCancel(context.Background())
+	t.Cleanup(cf)
+	hcfg := handlerConfig{
+		name:       `test`,
+		lrt:        rfc6587Reader,
+		wg:         &sync.WaitGroup{},
+		ctx:        ctx,
+		proc:       processors.NewProcessorSet(&nilWriter{}),
+		clientAuth: true,
+	}
+	hcfg.proc.AddProcessor(&chanTracker{ch: ch})
+	return hcfg, ch
+}
+
+func octetFrame(msg string) []byte {
+	return []byte(fmt.Sprintf("%d %s", len(msg), msg))
+}
+
+func waitEntry(t *testing.T, ch chan *entry.Entry) *entry.Entry {
+	select {
+	case ent := <-ch:
+		return ent
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for entry")
+	}
+	return nil
+}
+
+func checkSubject(t *testing.T, ent *entry.Entry) {
+	if v, ok := ent.GetEnumeratedValue(clientSubjectEV); !ok {
+		t.Fatal("missing client subject")
+	} else if s, ok := v.(string); !ok || s != `CN=router1,O=Gravwell` {
+		t.Fatalf("bad client subject %v", v)
+	}
+}
+
+func TestTLSClientAuth(t *testing.T) {
+	pki := newTestPKI(t)
+	hcfg, ch := newTLSTestHandlerConfig(t)
+	l := &listener{
+		baseConfig:     baseConfig{Cert_File: pki.serverCert, Key_File: pki.serverKey},
+		Client_CA_File: pki.caFile,
+	}
+	cfg, err := newTLSConfig(l)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lst, err := tls.Listen(`tcp`, `127.0.0.1:0`, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lst.Close()
+	hcfg.wg.Add(1)
+	go acceptor(lst, addConn(lst), nil, hcfg, TLS)
+
+	//good client
+	conn, err := tls.Dial(`tcp`, lst.Addr().String(), &tls.Config{
+		RootCAs:      pki.caPool,
+		Certificates: []tls.Certificate{pki.client},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := `<13>1 2023-03-01T18:46:11Z router1 app - - - hello world`
+	if _, err = conn.Write(octetFrame(msg)); err != nil {
+		t.Fatal(err)
+	}
+	conn.Close()
+	ent := waitEntry(t, ch)
+	if string(ent.Data) != msg {
+		t.Fatalf("bad data %q", ent.Data)
+	}
+	checkSubject(t, ent)
+
+	//client without a certificate must be rejected
+	if conn, err = tls.Dial(`tcp`, lst.Addr().String(), &tls.Config{RootCAs: pki.caPool}); err == nil {
+		conn.Write(octetFrame(msg))
+		conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		if _, err = conn.Read(make([]byte, 16)); err == nil {
+			t.Fatal("unauthenticated client was not rejected")
+		}
+		conn.Close()
+	}
+	select {
+	case ent = <-ch:
+		t.Fatalf("got entry from unauthenticated client: %q", ent.Data)
+	case <-time.After(250 * time.Millisecond):
+	}
+}
+
+// startTestDTLS starts a DTLS listener on a free local port, it is shut down when the test ends
+func startTestDTLS(t *testing.T, l *listener, hcfg handlerConfig) *net.UDPAddr {
+	uc, err := net.ListenUDP(`udp`, &net.UDPAddr{IP: net.ParseIP(`127.0.0.1`)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := uc.LocalAddr().(*net.UDPAddr)
+	uc.Close()
+	if err = startDTLSListener(l, addr.String(), hcfg); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		mtx.Lock()
+		for _, c := range connClosers {
+			c.Close()
+		}
+		mtx.Unlock()
+	})
+	return addr
+}
+
+func TestDTLSClientAuth(t *testing.T) {
+	pki := newTestPKI(t)
+	hcfg, ch := newTLSTestHandlerConfig(t)
+	l := &listener{
+		baseConfig:     baseConfig{Cert_File: pki.serverCert, Key_File: pki.serverKey},
+		Client_CA_File: pki.caFile,
+	}
+	addr := startTestDTLS(t, l, hcfg)
+
+	ctx, cf := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cf()
+	conn, err := dtls.DialWithContext(ctx, `udp`, addr, &dtls.Config{
+		RootCAs:              pki.caPool,
+		Certificates:         []tls.Certificate{pki.client},
+		ExtendedMasterSecret: dtls.RequireExtendedMasterSecret,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	msgs := []string{
+		`<13>1 2023-03-01T18:46:11Z router1 app - - - first`,
+		`<13>1 2023-03-01T18:46:12Z router1 app - - - second`,
+	}
+	// RFC6012 allows multiple frames in a single record
+	if _, err = conn.Write(append(octetFrame(msgs[0]), octetFrame(msgs[1])...)); err != nil {
+		t.Fatal(err)
+	}
+	for _, msg := range msgs {
+		ent := waitEntry(t, ch)
+		if string(ent.Data) != msg {
+			t.Fatalf("bad data %q != %q", ent.Data, msg)
+		}
+		checkSubject(t, ent)
+	}
+
+	//a client without a certificate cannot complete the handshake
+	ctx2, cf2 := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cf2()
+	if c, err := dtls.DialWithContext(ctx2, `udp`, addr, &dtls.Config{RootCAs: pki.caPool}); err == nil {
+		c.Close()
+		t.Fatal("DTLS client without a certificate was accepted")
+	}
+}
+
+func TestDTLSRecordBoundaries(t *testing.T) {
+	pki := newTestPKI(t)
+	hcfg, ch := newTLSTestHandlerConfig(t)
+	hcfg.lrt = lineReader
+	hcfg.clientAuth = false
+	hcfg.ignoreTimestamps = true
+	l := &listener{baseConfig: baseConfig{Cert_File: pki.serverCert, Key_File: pki.serverKey}}
+	addr := startTestDTLS(t, l, hcfg)
+
+	ctx, cf := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cf()
+	conn, err := dtls.DialWithContext(ctx, `udp`, addr, &dtls.Config{
+		RootCAs:              pki.caPool,
+		ExtendedMasterSecret: dtls.RequireExtendedMasterSecret,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	//each record is a message even without a trailing newline
+	msgs := []string{`first`, `second`}
+	for _, msg := range msgs {
+		if _, err = conn.Write([]byte(msg)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, msg := range msgs {
+		if ent := waitEntry(t, ch); string(ent.Data) != msg {
+			t.Fatalf("bad data %q != %q", ent.Data, msg)
+		}
+	}
+}
+
+func TestTLSListenerSettings(t *testing.T) {
+	l := &listener{baseConfig: baseConfig{Bind_String: `dtls://127.0.0.1:6514`}, Reader_Type: `rfc6587`}
+	if err := checkListenerSettings(l); err == nil {
+		t.Fatal("accepted DTLS without certificates")
+	}
+	l.Cert_File, l.Key_File = `/tmp/cert`, `/tmp/key`
+	if err := checkListenerSettings(l); err != nil {
+		t.Fatal(err)
+	}
+	l = &listener{baseConfig: baseConfig{Bind_String: `tcp://127.0.0.1:601`}, Client_CA_File: `/tmp/ca`}
+	if err := checkListenerSettings(l); err == nil {
+		t.Fatal("accepted Client-CA-File on a plaintext bind")
+	}
+	if err := checkStreamBind(`dtls://127.0.0.1:6514`); err == nil {
+		t.Fatal("accepted DTLS on a stream only listener")
+	}
+}
+
+// failListener fails every Accept without ever being closed
+type failListener struct {
+	sync.Mutex
+	accepts int
+	closed  bool
+}
+
+func (fl *failListener) Accept() (net.Conn, error) {
+	fl.Lock()
+	defer fl.Unlock()
+	fl.accepts++
+	return nil, fmt.Errorf("too many open files")
+}
+
+func (fl *failListener) Close() error {
+	fl.Lock()
+	defer fl.Unlock()
+	fl.closed = true
+	return nil
+}
+
+func (fl *failListener) Addr() net.Addr {
+	return &net.UDPAddr{}
+}
+
+func TestDTLSAcceptFailures(t *testing.T) {
+	lg = log.NewDiscardLogger()
+	fl := &failListener{}
+	var wg sync.WaitGroup
+	wg.Add(1)
+	done := make(chan struct{})
+	go func() {
+		dtlsAcceptor(fl, addConn(fl), &dtls.Config{}, handlerConfig{wg: &wg})
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("acceptor kept spinning on accept failures")
+	}
+	wg.Wait()
+	fl.Lock()
+	defer fl.Unlock()
+	if fl.accepts != 4 || !fl.closed {
+		t.Fatalf("accepted %d times, closed %v", fl.accepts, fl.closed)
+	}
+}
@@ -0,0 +1,114 @@
+/*************************************************************************
+ * Copyright 2026 Gravwell, Inc. All rights reserved.
+ * Contact: <legal@gravwell.io>
+ *
+ * This software may be modified and distributed under the terms of the
+ * BSD 2-clause license. See the LICENSE file for details.
+ **************************************************************************/
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gravwell/gravwell/v3/ingesters/utils"
+)
+
+const (
+	checkpointInterval = time.Second
+	statePerm          = 0640
+)
+
+// checkpoint tracks which inputs and archive members have been fully ingested
+// Keys are an input identity (path, size and modification time) optionally followed
+// by the member path within the input, so a modified input is processed again.
+type checkpoint struct {
+	st    *utils.State
+	done  map[string]bool
+	dirty bool
+	last  time.Time
+	sync  func() error // called before state is persisted so the marked entries are acknowledged
+}
+
+// newCheckpoint loads existing state, an empty path returns a checkpoint that persists nothing
+func newCheckpoint(pth string, sync func() error) (cp *checkpoint, err error) {
+	cp = &checkpoint{
+		done: map[string]bool{},
+		sync: sync,
+	}
+	if pth == `` {
+		return
+	}
+	if cp.st, err = utils.NewState(pth, statePerm); err != nil {
+		return
+	}
+	if err = cp.st.Read(&cp.done); err == utils.ErrNoState {
+		err = nil
+	} else if err != nil {
+		err = fmt.Errorf("failed to read state file %s: %w", pth, err)
+	}
+	return
+}
+
+// inputID builds the identity of an input file
+func inputID(pth string) (string, error) {
+	fi, err := os.Stat(pth)
+	if err != nil {
+		return ``, err
+	}
+	if abs, err := filepath.Abs(pth); err == nil {
+		pth = abs
+	}
+	return fmt.Sprintf("%s:%d:%d", pth, fi.Size(), fi.ModTime().UnixNano()), nil
+}
+
+// memberKey builds the key of a member from the input identity and the member path relative to the input
+func memberKey(id, input string, m member) string {
+	return id + `|` + m.Path[len(input):]
+}
+
+func (c *checkpoint) isDone(key string) bool {
+	return c.done[key]
+}
+
+func (c *checkpoint) mark(key string) error {
+	c.done[key] = true
+	c.dirty = true
+	if time.Since(c.last) < checkpointInterval {
+		return nil
+	}
+	return c.flush()
+}
+
+// flush syncs outstanding entries and writes the state file
+func (c *checkpoint) flush() error {
+	c.last = time.Now()
+	if c.st == nil || !c.dirty {
+		return nil
+	}
+	if c.sync != nil {
+		if err := c.sync(); err != nil {
+			return err
+		}
+	}
+	if err := c.st.Write(c.done); err != nil {
+		return err
+	}
+	c.dirty = false
+	return nil
+}
+
+// complete marks an input as fully ingested and drops its member keys
+func (c *checkpoint) complete(id string) error {
+	pfx := id + `|`
+	for k := range c.done {
+		if strings.HasPrefix(k, pfx) {
+			delete(c.done, k)
+		}
+	}
+	return c.mark(id)
+}
@@ -0,0 +1,274 @@
+/*************************************************************************
+ * Copyright 2026 Gravwell, Inc. All rights reserved.
+ * Contact: <legal@gravwell.io>
+ *
+ * This software may be modified and distributed under the terms of the
+ * BSD 2-clause license. See the LICENSE file for details.
+ **************************************************************************/
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+)
+
+const (
+	formatAuto = `auto`
+	formatJSON = `json`
+	formatCSV  = `csv`
+	formatLine = `line`
+
+	sampleSize   = 64 * 1024
+	initBuffSize = 4 * 1024 * 1024
+	maxBuffSize  = 128 * 1024 * 1024
+
+	maxHeaderField = 64 // header fields longer than this are probably data
+)
+
+var (
+	ErrBinary        = errors.New("binary content")
+	ErrUnknownFormat = errors.New("unknown format")
+)
+
+func checkFormat(f string) (string, error) {
+	switch f = strings.ToLower(strings.TrimSpace(f)); f {
+	case ``:
+		return formatAuto, nil
+	case formatAuto, formatJSON, formatCSV, formatLine:
+		return f, nil
+	}
+	return ``, fmt.Errorf("%w %q", ErrUnknownFormat, f)
+}
+
+// detectFormat inspects the start of a file and picks a record format for it
+func detectFormat(name string, br *bufio.Reader) (string, error) {
+	sample, err := br.Peek(sampleSize)
+	full := err == nil // the sample was truncated, the file is larger than the sample
+	if len(sample) == 0 {
+		return formatLine, nil
+	}
+	if bytes.IndexByte(sample, 0) >= 0 {
+		return ``, ErrBinary
+	}
+	if isJSON(sample, full) {
+		return formatJSON, nil
+	}
+	if strings.EqualFold(path.Ext(name), `.csv`) || looksCSV(sample) {
+		return formatCSV, nil
+	}
+	return formatLine, nil
+}
+
+// isJSON checks that the sample decodes as a sequence of JSON objects or a JSON array
+func isJSON(sample []byte, full bool) bool {
+	trimmed := bytes.TrimSpace(sample)
+	if len(trimmed) == 0 || (trimmed[0] != '{' && trimmed[0] != '[') {
+		return false
+	}
+	dec := json.NewDecoder(bytes.NewReader(trimmed))
+	for {
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err == io.EOF {
+			return true
+		} else if err != nil {
+			// a value cut off by the end of the sample is fine
+			return full && err == io.ErrUnexpectedEOF
+		}
+	}
+}
+
+// looksCSV checks for a header line followed by lines with the same number of fields
+func looksCSV(sample []byte) bool {
+	lines := bytes.Split(sample, []byte("\n"))
+	if len(lines) < 3 {
+		return false
+	}
+	hdr := splitCSVLine(string(dropCR(lines[0])))
+	if len(hdr) < 2 || !isHeader(hdr) {
+		return false
+	}
+	var matched int
+	for _, ln := range lines[1 : len(lines)-1] { // the last line may be truncated
+		if ln = dropCR(ln); len(ln) == 0 {
+			continue
+		}
+		if len(splitCSVLine(string(ln))) != len(hdr) {
+			return false
+		}
+		matched++
+	}
+	return matched >= 2
+}
+
+func isHeader(flds []string) bool {
+	for _, f := range flds {
+		if f = strings.TrimSpace(f); f == `` || len(f) > maxHeaderField {
+			return false
+		} else if _, err := strconv.ParseFloat(f, 64); err == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// splitCSVLine splits a single line on commas outside of quotes
+func splitCSVLine(ln string) (flds []string) {
+	var quoted bool
+	var start int
+	for i := 0; i < len(ln); i++ {
+		switch ln[i] {
+		case '"':
+			quoted = !quoted
+		case ',':
+			if !quoted {
+				flds = append(flds, strings.Trim(ln[start:i], `"`))
+				start = i + 1
+			}
+		}
+	}
+	return append(flds, strings.Trim(ln[start:], `"`))
+}
+
+// readRecords splits a file into records using the given format and hands each to cb
+func readRecords(format string, br *bufio.Reader, cb func([]byte) error) error {
+	switch format {
+	case formatJSON:
+		return readJSON(br, cb)
+	case formatCSV:
+		return readCSV(br, cb)
+	}
+	return readLines(br, cb)
+}
+
+// readJSON emits each element of a top level array or each value of an object stream, compacted
+func readJSON(br *bufio.Reader, cb func([]byte) error) error {
+	if err := skipSpace(br); err != nil {
+		if err == io.EOF {
+			return nil
+		}
+		return err
+	}
+	dec := json.NewDecoder(br)
+	var buf bytes.Buffer
+	emit := func(raw json.RawMessage) error {
+		buf.Reset()
+		if err := json.Compact(&buf, raw); err != nil {
+			return err
+		}
+		return cb(bytes.Clone(buf.Bytes()))
+	}
+	if b, _ := br.Peek(1); len(b) == 1 && b[0] == '[' {
+		if _, err := dec.Token(); err != nil {
+			return err
+		}
+		for dec.More() {
+			var raw json.RawMessage
+			if err := dec.Decode(&raw); err != nil {
+				return err
+			} else if err = emit(raw); err != nil {
+				return err
+			}
+		}
+		_, err := dec.Token()
+		return err
+	}
+	for {
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		} else if err = emit(raw); err != nil {
+			return err
+		}
+	}
+}
+
+func skipSpace(br *bufio.Reader) error {
+	for {
+		b, err := br.Peek(1)
+		if err != nil {
+			return err
+		}
+		switch b[0] {
+		case ' ', '\t', '\r', '\n':
+			br.Discard(1)
+		default:
+			return nil
+		}
+	}
+}
+
+// readCSV emits each CSV record, records may contain quoted newlines and a detected header line is dropped
+func readCSV(br *bufio.Reader, cb func([]byte) error) error {
+	scn := bufio.NewScanner(br)
+	scn.Buffer(make([]byte, initBuffSize), maxBuffSize)
+	scn.Split(csvSplitter)
+	first := true
+	for scn.Scan() {
+		ln := scn.Bytes()
+		if len(ln) == 0 {
+			continue
+		}
+		if first {
+			first = false
+			if isHeader(splitCSVLine(string(ln))) {
+				continue
+			}
+		}
+		if err := cb(bytes.Clone(ln)); err != nil {
+			return err
+		}
+	}
+	return scn.Err()
+}
+
+func readLines(br *bufio.Reader, cb func([]byte) error) error {
+	scn := bufio.NewScanner(br)
+	scn.Buffer(make([]byte, initBuffSize), maxBuffSize)
+	for scn.Scan() {
+		ln := dropCR(scn.Bytes())
+		if len(ln) == 0 {
+			continue
+		}
+		if err := cb(bytes.Clone(ln)); err != nil {
+			return err
+		}
+	}
+	return scn.Err()
+}
+
+// csvSplitter splits on newlines that are not inside a quoted field
+func csvSplitter(data []byte, atEOF bool) (int, []byte, error) {
+	var quoted bool
+	if atEOF && len(data) == 0 {
+		return 0, nil, nil
+	}
+	for i, c := range data {
+		if c == '"' {
+			quoted = !quoted
+		} else if c == '\n' && !quoted {
+			return i + 1, dropCR(data[:i]), nil
+		}
+	}
+	if atEOF {
+		return len(data), dropCR(data), nil
+	}
+	//request more data
+	return 0, nil, nil
+}
+
+func dropCR(data []byte) []byte {
+	if len(data) > 0 && data[len(data)-1] == '\r' {
+		return data[0 : len(data)-1]
+	}
+	return data
+}
@@ -0,0 +1,485 @@
+/*************************************************************************
+ * Copyright 2026 Gravwell, Inc. All rights reserved.
+ * Contact: <legal@gravwell.io>
+ *
+ * This software may be modified and distributed under the terms of the
+ * BSD 2-clause license. See the LICENSE file for details.
+ **************************************************************************/
+
+// archiveFile is a oneshot ingester that walks files, directories and archives.
+// It descends into tar and zip archives and gz/bz2/xz/zstd compression, including
+// nested archives, maps member paths to tags with glob rules, detects JSON, CSV
+// and line oriented content per member, and can checkpoint progress per member.
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"log"
+	"net"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime/debug"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	// Embed tzdata so that we don't rely on potentially broken timezone DBs on the host
+	_ "time/tzdata"
+
+	"github.com/gobwas/glob"
+	"github.com/gravwell/gravwell/v3/ingest"
+	"github.com/gravwell/gravwell/v3/ingest/config"
+	"github.com/gravwell/gravwell/v3/ingest/entry"
+	"github.com/gravwell/gravwell/v3/ingesters/args"
+	"github.com/gravwell/gravwell/v3/ingesters/version"
+	"github.com/gravwell/gravwell/v3/timegrinder"
+
+	gravwelldebug "github.com/gravwell/gravwell/v3/debug"
+)
+
+const (
+	defaultPathEV = `archive_path`
+	batchSize     = 512
+)
+
+var (
+	tso       = flag.String("timestamp-override", "", "Timestamp override")
+	tzo       = flag.String("timezone-override", "", "Timezone override e.g. America/Chicago")
+	inFile    = flag.String("i", "", "Input file, directory, or archive to process, additional inputs may follow the flags")
+	ver       = flag.Bool("version", false, "Print version and exit")
+	utc       = flag.Bool("utc", false, "Assume UTC time")
+	ignoreTS  = flag.Bool("ignore-ts", false, "Ignore timestamp")
+	verbose   = flag.Bool("verbose", false, "Print every entry")
+	fileinfo  = flag.Bool("fileinfo", false, "Print file and archive member names as we process them")
+	status    = flag.Bool("status", false, "Output ingest rate stats as we go")
+	srcOvr    = flag.String("source-override", "", "Override source with address, hash, or integer")
+	fmtFlag   = flag.String("format", formatAuto, "Record format: auto, json, csv, or line")
+	stateFile = flag.String("state-file", "", "Path to a state file used to skip inputs and archive members that were already ingested")
+	pathEV    = flag.String("path-ev", defaultPathEV, "Enumerated value name holding the archive member path, empty disables")
+	maxDepth  = flag.Int("max-depth", defaultMaxDepth, "Maximum archive and compression nesting depth")
+	tmpDir    = flag.String("temp-dir", "", "Directory used to spool large nested zip archives")
+	rules     ruleList
+
+	count      uint64
+	totalBytes uint64
+)
+
+func init() {
+	flag.Var(&rules, "rule", "Tag rule in the form glob=tag, may be repeated, the first matching rule wins")
+}
+
+var (
+	ErrInvalidRule = errors.New("invalid tag rule, expected glob=tag")
+)
+
+// tagRule maps member paths matching a glob to a tag
+// Patterns containing a slash match the full member path, others match the file name.
+type tagRule struct {
+	pattern string
+	tag     string
+	base    bool
+	g       glob.Glob
+}
+
+type ruleList []tagRule
+
+func (rl *ruleList) String() string {
+	if rl == nil {
+		return ``
+	}
+	var s []string
+	for _, r := range *rl {
+		s = append(s, r.pattern+`=`+r.tag)
+	}
+	return strings.Join(s, ",")
+}
+
+func (rl *ruleList) Set(v string) error {
+	r, err := parseRule(v)
+	if err != nil {
+		return err
+	}
+	*rl = append(*rl, r)
+	return nil
+}
+
+func parseRule(v string) (r tagRule, err error) {
+	idx := strings.LastIndex(v, `=`)
+	if idx <= 0 {
+		err = ErrInvalidRule
+		return
+	}
+	r.pattern = strings.TrimSpace(v[:idx])
+	r.tag = strings.TrimSpace(v[idx+1:])
+	if r.pattern == `` || r.tag == `` {
+		err = ErrInvalidRule
+		return
+	} else if err = ingest.CheckTag(r.tag); err != nil {
+		return
+	}
+	r.base = !strings.Contains(r.pattern, `/`)
+	if r.g, err = glob.Compile(r.pattern, '/'); err != nil {
+		err = fmt.Errorf("invalid rule pattern %q: %w", r.pattern, err)
+	}
+	return
+}
+
+// match checks the member path and name, with and without a compression suffix
+func (r tagRule) match(m member) bool {
+	cands := []string{m.Path, m.Name}
+	if r.base {
+		cands = []string{path.Base(m.Name)}
+	}
+	for _, c := range cands {
+		if r.g.Match(c) || r.g.Match(stripCompression(c)) {
+			return true
+		}
+	}
+	return false
+}
+
+func parseFlags() {
+	flag.Parse()
+	if *ver {
+		version.PrintVersion(os.Stdout)
+		ingest.PrintVersion(os.Stdout)
+		os.Exit(0)
+	}
+}
+
+func main() {
+	parseFlags()
+	go gravwelldebug.HandleDebugSignals("archivefile")
+	debug.SetTraceback("all")
+	inputs := flag.Args()
+	if *inFile != `` {
+		inputs = append([]string{*inFile}, inputs...)
+	}
+	if len(inputs) == 0 {
+		log.Fatal("Input path required")
+	}
+	a, err := args.Parse()
+	if err != nil {
+		log.Fatalf("Invalid arguments: %v\n", err)
+	}
+	if len(a.Tags) != 1 {
+		log.Fatal("Archive file oneshot only accepts a single default tag")
+	}
+	format, err := checkFormat(*fmtFlag)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *maxDepth <= 0 {
+		log.Fatalf("Invalid max depth %d\n", *maxDepth)
+	}
+
+	//resolve the timestmap override if there is one
+	if *tso != "" {
+		if err = timegrinder.ValidateFormatOverride(*tso); err != nil {
+			log.Fatalf("Invalid timestamp override: %v\n", err)
+		}
+	}
+
+	var srcOverride net.IP
+	if *srcOvr != `` {
+		if srcOverride, err = config.ParseSource(*srcOvr); err != nil {
+			log.Fatalf("Invalid source override")
+		}
+	}
+
+	//the default tag is first, followed by every tag referenced by a rule
+	tags := a.Tags
+	for _, r := range rules {
+		var have bool
+		for _, t := range tags {
+			have = have || t == r.tag
+		}
+		if !have {
+			tags = append(tags, r.tag)
+		}
+	}
+
+	//fire up a uniform muxer
+	igst, err := ingest.NewUniformIngestMuxer(a.Conns, tags, a.IngestSecret, a.TLSPublicKey, a.TLSPrivateKey, "")
+	if err != nil {
+		log.Fatalf("Failed to create new ingest muxer: %v\n", err)
+	}
+	if err := igst.Start(); err != nil {
+		log.Fatalf("Failed to start ingest muxer: %v\n", err)
+	}
+	if err := igst.WaitForHot(a.Timeout); err != nil {
+		log.Fatalf("Failed to wait for hot connection: %v\n", err)
+	}
+
+	ing := &ingester{
+		format: format,
+		pathEV: *pathEV,
+		rules:  rules,
+		tags:   map[string]entry.EntryTag{},
+		src:    srcOverride,
+		write:  igst.WriteBatch,
+	}
+	for _, t := range tags {
+		if ing.tags[t], err = igst.GetTag(t); err != nil {
+			log.Fatalf("Failed to resolve tag %s: %v\n", t, err)
+		}
+	}
+	ing.defTag = ing.tags[a.Tags[0]]
+	if ing.src == nil {
+		if ing.src, err = igst.SourceIP(); err != nil {
+			log.Fatalf("Failed to get source IP: %v\n", err)
+		}
+	}
+	if !*ignoreTS {
+		if ing.tg, err = newTimegrinder(*tso); err != nil {
+			log.Fatalf("Failed to create timegrinder: %v\n", err)
+		}
+	}
+	if ing.cp, err = newCheckpoint(*stateFile, func() error { return igst.Sync(a.Timeout) }); err != nil {
+		log.Fatal(err)
+	}
+
+	start := time.Now()
+	if err := doIngest(ing, inputs); err != nil {
+		log.Fatalf("Failed to ingest: %v\n", err)
+	}
+	dur := time.Since(start)
+
+	if err = igst.Sync(a.Timeout); err != nil {
+		log.Fatalf("Failed to sync ingest muxer: %v\n", err)
+	}
+	if err = ing.cp.flush(); err != nil {
+		log.Fatalf("Failed to write state file: %v\n", err)
+	}
+	if err := igst.Close(); err != nil {
+		log.Fatalf("Failed to close the ingest muxer: %v\n", err)
+	}
+	fmt.Printf("Completed in %v (%s)\n", dur, ingest.HumanSize(totalBytes))
+	fmt.Printf("Total Count: %s\n", ingest.HumanCount(count))
+	fmt.Printf("Entry Rate: %s\n", ingest.HumanEntryRate(count, dur))
+	fmt.Printf("Ingest Rate: %s\n", ingest.HumanRate(totalBytes, dur))
+}
+
+func newTimegrinder(tso string) (tg *timegrinder.TimeGrinder, err error) {
+	c := timegrinder.Config{
+		EnableLeftMostSeed: true,
+		FormatOverride:     tso,
+	}
+	if tg, err = timegrinder.NewTimeGrinder(c); err != nil {
+		return
+	}
+	if *utc {
+		tg.SetUTC()
+	}
+	if *tzo != `` {
+		err = tg.SetTimezone(*tzo)
+	}
+	return
+}
+
+func doIngest(ing *ingester, inputs []string) (err error) {
+	//if not doing regular updates, just fire it off
+	if !*status {
+		err = ing.ingestInputs(inputs)
+		return
+	}
+
+	errCh := make(chan error, 1)
+	tckr := time.NewTicker(time.Second)
+	defer tckr.Stop()
+	go func(ch chan error) {
+		ch <- ing.ingestInputs(inputs)
+	}(errCh)
+
+	lastts := time.Now()
+	lastcnt := atomic.LoadUint64(&count)
+	lastsz := atomic.LoadUint64(&totalBytes)
+	for {
+		select {
+		case err = <-errCh:
+			fmt.Println("\nDONE")
+			return
+		case <-tckr.C:
+			cnt, sz := atomic.LoadUint64(&count), atomic.LoadUint64(&totalBytes)
+			tdur := time.Since(lastts)
+			fmt.Printf("\r%s %s                                     ",
+				ingest.HumanEntryRate(cnt-lastcnt, tdur),
+				ingest.HumanRate(sz-lastsz, tdur))
+			lastts, lastcnt, lastsz = time.Now(), cnt, sz
+		}
+	}
+}
+
+// ingester turns the plain files found by the walker into entries
+type ingester struct {
+	tg     *timegrinder.TimeGrinder
+	src    net.IP
+	format string
+	pathEV string
+	rules  []tagRule
+	tags   map[string]entry.EntryTag
+	defTag entry.EntryTag
+	cp     *checkpoint
+	write  func([]*entry.Entry) error
+
+	input string // the input currently being walked
+	id    string // checkpoint identity of the current input
+}
+
+// ingestInputs expands directories and walks every input file
+func (ing *ingester) ingestInputs(inputs []string) error {
+	for _, in := range inputs {
+		err := filepath.WalkDir(in, func(pth string, d fs.DirEntry, err error) error {
+			if err != nil {
+				log.Printf("Failed to read %s: %v\n", pth, err)
+				return nil
+			} else if !d.Type().IsRegular() {
+				return nil
+			}
+			return ing.ingestInput(pth)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (ing *ingester) ingestInput(pth string) (err error) {
+	if ing.id, err = inputID(pth); err != nil {
+		log.Printf("Failed to stat %s: %v\n", pth, err)
+		return nil
+	}
+	if ing.cp.isDone(ing.id) {
+		if *fileinfo {
+			log.Println("Skipping completed", pth)
+		}
+		return nil
+	}
+	if *fileinfo {
+		log.Println("Processing", pth)
+	}
+	ing.input = pth
+	w := walker{
+		maxDepth: *maxDepth,
+		tmpDir:   *tmpDir,
+		leaf:     ing.leaf,
+		warn: func(p string, err error) {
+			log.Printf("Failed to ingest %s: %v\n", p, err)
+		},
+	}
+	if err = w.walkFile(pth); err != nil {
+		var fe fatalError
+		if errors.As(err, &fe) {
+			return fe.error
+		}
+		log.Printf("Failed to ingest %s: %v\n", pth, err)
+		return nil
+	} else if w.failed > 0 {
+		// leave the input incomplete so the failed members are retried, the ingested ones are already checkpointed
+		log.Printf("Failed to ingest %d members of %s\n", w.failed, pth)
+		return nil
+	}
+	if err = ing.cp.complete(ing.id); err != nil {
+		err = fmt.Errorf("failed to update state file: %w", err)
+	}
+	return
+}
+
+// tagFor returns the tag of the first matching rule or the default tag
+func (ing *ingester) tagFor(m member) entry.EntryTag {
+	for _, r := range ing.rules {
+		if r.match(m) {
+			return ing.tags[r.tag]
+		}
+	}
+	return ing.defTag
+}
+
+// leaf ingests a single plain file
+func (ing *ingester) leaf(m member, br *bufio.Reader) (err error) {
+	key := memberKey(ing.id, ing.input, m)
+	if ing.cp.isDone(key) {
+		return nil
+	}
+	name := stripCompression(m.Name)
+	format := ing.format
+	if format == formatAuto {
+		if format, err = detectFormat(name, br); err == ErrBinary {
+			if *fileinfo {
+				log.Println("Skipping binary", m.Path)
+			}
+			return ing.markDone(key)
+		} else if err != nil {
+			return err
+		}
+	}
+	if *fileinfo && m.Path != ing.input {
+		log.Printf("Processing %s as %s\n", m.Path, format)
+	}
+
+	tag := ing.tagFor(m)
+	var ev *entry.EnumeratedValue
+	if ing.pathEV != `` {
+		ev = &entry.EnumeratedValue{Name: ing.pathEV, Value: entry.StringEnumData(m.Path)}
+	}
+	blk := make([]*entry.Entry, 0, batchSize)
+	flush := func() error {
+		if len(blk) == 0 {
+			return nil
+		}
+		if err := ing.write(blk); err != nil {
+			return fatalError{err}
+		}
+		blk = make([]*entry.Entry, 0, batchSize)
+		return nil
+	}
+	err = readRecords(format, br, func(b []byte) error {
+		ent := &entry.Entry{
+			TS:   entry.FromStandard(ing.timestamp(b)),
+			Tag:  tag,
+			SRC:  ing.src,
+			Data: b,
+		}
+		if ev != nil {
+			ent.AddEnumeratedValue(*ev)
+		}
+		if *verbose {
+			fmt.Println(ent.TS, ent.Tag, ent.SRC, m.Path, string(ent.Data))
+		}
+		atomic.AddUint64(&count, 1)
+		atomic.AddUint64(&totalBytes, uint64(len(b)))
+		if blk = append(blk, ent); len(blk) >= batchSize {
+			return flush()
+		}
+		return nil
+	})
+	if ferr := flush(); err == nil {
+		err = ferr
+	}
+	if err != nil {
+		return
+	}
+	return ing.markDone(key)
+}
+
+func (ing *ingester) markDone(key string) error {
+	if err := ing.cp.mark(key); err != nil {
+		return fatalError{fmt.Errorf("failed to update state file: %w", err)}
+	}
+	return nil
+}
+
+func (ing *ingester) timestamp(b []byte) time.Time {
+	if ing.tg != nil {
+		if ts, ok, err := ing.tg.Extract(b); err == nil && ok {
+			return ts
+		}
+	}
+	return time.Now()
+}
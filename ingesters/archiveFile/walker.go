@@ -0,0 +1,247 @@
+/*************************************************************************
+ * Copyright 2026 Gravwell, Inc. All rights reserved.
+ * Contact: <legal@gravwell.io>
+ *
+ * This software may be modified and distributed under the terms of the
+ * BSD 2-clause license. See the LICENSE file for details.
+ **************************************************************************/
+
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/gravwell/gravwell/v3/ingesters/utils"
+)
+
+const (
+	memberSep       = `!/` // separates nested archive members in a display path
+	defaultMaxDepth = 8
+	readerSize      = 256 * 1024
+	magicSize       = 512              // enough to see the tar magic at offset 257
+	maxMemorySpool  = 64 * 1024 * 1024 // nested zips larger than this are spooled to a temp file
+)
+
+var (
+	ErrMaxDepth           = errors.New("maximum archive nesting depth exceeded")
+	ErrUnsupportedArchive = errors.New("unsupported archive format")
+
+	compressionSuffixes = []string{`.gz`, `.tgz`, `.bz2`, `.xz`, `.zst`, `.zstd`}
+)
+
+type streamKind int
+
+const (
+	kindPlain streamKind = iota
+	kindCompressed
+	kindTar
+	kindZip
+	kind7z
+	kindRar
+)
+
+// detectKind identifies a stream from its leading bytes
+func detectKind(hdr []byte) streamKind {
+	switch {
+	case bytes.HasPrefix(hdr, []byte{0x1f, 0x8b}),
+		bytes.HasPrefix(hdr, []byte("BZh")),
+		bytes.HasPrefix(hdr, []byte{0xfd, '7', 'z', 'X', 'Z', 0x00}),
+		bytes.HasPrefix(hdr, []byte{0x28, 0xb5, 0x2f, 0xfd}):
+		return kindCompressed
+	case bytes.HasPrefix(hdr, []byte("PK\x03\x04")), bytes.HasPrefix(hdr, []byte("PK\x05\x06")):
+		return kindZip
+	case bytes.HasPrefix(hdr, []byte{'7', 'z', 0xbc, 0xaf, 0x27, 0x1c}):
+		return kind7z
+	case bytes.HasPrefix(hdr, []byte("Rar!\x1a\x07")):
+		return kindRar
+	case len(hdr) >= 262 && string(hdr[257:262]) == `ustar`:
+		return kindTar
+	}
+	return kindPlain
+}
+
+// member is a file found while walking an input
+type member struct {
+	Path string // the input followed by each enclosing archive member, separated by !/
+	Name string // path of the file inside its innermost archive, or the input path
+}
+
+// stripCompression removes a single compression suffix, "logs/app.log.gz" becomes "logs/app.log"
+func stripCompression(name string) string {
+	for _, sfx := range compressionSuffixes {
+		if strings.HasSuffix(strings.ToLower(name), sfx) {
+			if sfx == `.tgz` {
+				return name[:len(name)-len(sfx)] + `.tar`
+			}
+			return name[:len(name)-len(sfx)]
+		}
+	}
+	return name
+}
+
+// fatalError aborts the whole walk, any other error only skips the member that caused it
+type fatalError struct {
+	error
+}
+
+func (f fatalError) Unwrap() error {
+	return f.error
+}
+
+// walker descends through archives and compression layers handing each plain file to leaf
+type walker struct {
+	maxDepth int
+	tmpDir   string
+	leaf     func(member, *bufio.Reader) error
+	warn     func(pth string, err error)
+	failed   int // members that were skipped because of an error
+}
+
+// fail reports a member that could not be ingested
+func (w *walker) fail(pth string, err error) {
+	w.failed++
+	w.warn(pth, err)
+}
+
+// walkFile walks a single input file, zip archives are read in place
+func (w *walker) walkFile(pth string) error {
+	fin, err := os.Open(pth)
+	if err != nil {
+		return err
+	}
+	defer fin.Close()
+	fi, err := fin.Stat()
+	if err != nil {
+		return err
+	}
+	m := member{Path: pth, Name: pth}
+	hdr := make([]byte, magicSize)
+	n, _ := fin.ReadAt(hdr, 0)
+	if detectKind(hdr[:n]) == kindZip {
+		return w.walkZip(m, fin, fi.Size(), 1)
+	}
+	return w.walkStream(m, bufio.NewReaderSize(fin, readerSize), 0)
+}
+
+func (w *walker) walkStream(m member, br *bufio.Reader, depth int) error {
+	if depth > w.maxDepth {
+		return ErrMaxDepth
+	}
+	hdr, _ := br.Peek(magicSize)
+	switch detectKind(hdr) {
+	case kindCompressed:
+		rdr, err := utils.NewCompressedReader(br)
+		if err != nil {
+			return err
+		}
+		defer utils.CloseCompressedReader(rdr)
+		return w.walkStream(m, bufio.NewReaderSize(rdr, readerSize), depth+1)
+	case kindTar:
+		return w.walkTar(m, br, depth+1)
+	case kindZip:
+		return w.spoolZip(m, br, depth+1)
+	case kind7z:
+		return fmt.Errorf("%w 7z", ErrUnsupportedArchive)
+	case kindRar:
+		return fmt.Errorf("%w rar", ErrUnsupportedArchive)
+	}
+	return w.leaf(m, br)
+}
+
+// child walks an archive member, member failures are reported and skipped
+func (w *walker) child(parent member, name string, rdr io.Reader, depth int) error {
+	m := member{
+		Path: parent.Path + memberSep + name,
+		Name: name,
+	}
+	err := w.walkStream(m, bufio.NewReaderSize(rdr, readerSize), depth)
+	var fe fatalError
+	if err != nil && !errors.As(err, &fe) {
+		w.fail(m.Path, err)
+		err = nil
+	}
+	return err
+}
+
+func (w *walker) walkTar(m member, rdr io.Reader, depth int) error {
+	tr := tar.NewReader(rdr)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		if err = w.child(m, path.Clean(hdr.Name), tr, depth); err != nil {
+			return err
+		}
+	}
+}
+
+// spoolZip buffers a zip found inside a stream so it can be read randomly
+func (w *walker) spoolZip(m member, br *bufio.Reader, depth int) error {
+	var buf bytes.Buffer
+	n, err := io.Copy(&buf, io.LimitReader(br, maxMemorySpool+1))
+	if err != nil {
+		return err
+	}
+	if n <= maxMemorySpool {
+		return w.walkZip(m, bytes.NewReader(buf.Bytes()), n, depth)
+	}
+	tf, err := os.CreateTemp(w.tmpDir, `archivefile-*.zip`)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tf.Name())
+	defer tf.Close()
+	if _, err = tf.Write(buf.Bytes()); err != nil {
+		return err
+	}
+	buf = bytes.Buffer{}
+	if _, err = io.Copy(tf, br); err != nil {
+		return err
+	}
+	fi, err := tf.Stat()
+	if err != nil {
+		return err
+	}
+	return w.walkZip(m, tf, fi.Size(), depth)
+}
+
+func (w *walker) walkZip(m member, ra io.ReaderAt, size int64, depth int) error {
+	if depth > w.maxDepth {
+		return ErrMaxDepth
+	}
+	zr, err := zip.NewReader(ra, size)
+	if err != nil {
+		return err
+	}
+	for _, f := range zr.File {
+		if !f.Mode().IsRegular() {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			w.fail(m.Path+memberSep+f.Name, err)
+			continue
+		}
+		err = w.child(m, path.Clean(f.Name), rc, depth)
+		rc.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,385 @@
+/*************************************************************************
+ * Copyright 2026 Gravwell, Inc. All rights reserved.
+ * Contact: <legal@gravwell.io>
+ *
+ * This software may be modified and distributed under the terms of the
+ * BSD 2-clause license. See the LICENSE file for details.
+ **************************************************************************/
+
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gravwell/gravwell/v3/ingest/entry"
+	"github.com/klauspost/compress/zstd"
+	"github.com/ulikunitz/xz"
+)
+
+const (
+	tagDefault entry.EntryTag = iota
+	tagJSON
+	tagCSV
+	tagSyslog
+)
+
+func buildZip(t *testing.T, files map[string]string) []byte {
+	var bb bytes.Buffer
+	zw := zip.NewWriter(&bb)
+	for name, v := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = w.Write([]byte(v)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return bb.Bytes()
+}
+
+func xzBytes(t *testing.T, v string) []byte {
+	var bb bytes.Buffer
+	w, err := xz.NewWriter(&bb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write([]byte(v))
+	if err = w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return bb.Bytes()
+}
+
+func zstdBytes(t *testing.T, v string) []byte {
+	var bb bytes.Buffer
+	w, err := zstd.NewWriter(&bb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write([]byte(v))
+	if err = w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return bb.Bytes()
+}
+
+type tarFile struct {
+	name string
+	data []byte
+}
+
+// writeTarGz builds a gzip compressed tar file in dir
+func writeTarGz(t *testing.T, dir string, files []tarFile) string {
+	pth := filepath.Join(dir, `bundle.tar.gz`)
+	fout, err := os.Create(pth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fout.Close()
+	gz := gzip.NewWriter(fout)
+	tw := tar.NewWriter(gz)
+	tw.WriteHeader(&tar.Header{Name: `logs/`, Typeflag: tar.TypeDir, Mode: 0755})
+	for _, f := range files {
+		hdr := &tar.Header{Name: f.name, Typeflag: tar.TypeReg, Mode: 0644, Size: int64(len(f.data))}
+		if err = tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		} else if _, err = tw.Write(f.data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = tw.Close(); err != nil {
+		t.Fatal(err)
+	} else if err = gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return pth
+}
+
+func testBundle(t *testing.T, dir string) string {
+	return writeTarGz(t, dir, []tarFile{
+		{`logs/app.log`, []byte("2024-01-02T03:04:05Z first\n\n2024-01-02T03:04:06Z second\r\n")},
+		{`logs/nested.zip`, buildZip(t, map[string]string{
+			`events/data.json`: `[{"a": 1}, {"a": 2,
+				"b": "x"}]`,
+		})},
+		{`logs/users.csv`, []byte("name,email,note\nbob,bob@example.com,\"multi\nline\"\nalice,alice@example.com,none\n")},
+		{`logs/syslog.log.xz`, xzBytes(t, "Jan  2 03:04:05 host sshd: one\nJan  2 03:04:06 host sshd: two\n")},
+		{`logs/stream.json.zst`, zstdBytes(t, `{"x":1} {"x":2}`+"\n"+`{"x":3}`)},
+		{`logs/blob.bin`, []byte{0x00, 0x01, 0x02, 0x03}},
+	})
+}
+
+type capture struct {
+	ents []*entry.Entry
+}
+
+func (c *capture) write(b []*entry.Entry) error {
+	c.ents = append(c.ents, b...)
+	return nil
+}
+
+// byPath groups captured entry data by the archive path enumerated value
+func (c *capture) byPath(t *testing.T) map[string][]string {
+	r := map[string][]string{}
+	for _, ent := range c.ents {
+		ev, ok := ent.GetEnumeratedValue(defaultPathEV)
+		if !ok {
+			t.Fatalf("entry %q missing path EV", ent.Data)
+		}
+		r[ev.(string)] = append(r[ev.(string)], string(ent.Data))
+	}
+	return r
+}
+
+func newTestIngester(t *testing.T, c *capture, statePath string) *ingester {
+	ing := &ingester{
+		format: formatAuto,
+		pathEV: defaultPathEV,
+		tags: map[string]entry.EntryTag{
+			`default`: tagDefault,
+			`json`:    tagJSON,
+			`csv`:     tagCSV,
+			`syslog`:  tagSyslog,
+		},
+		defTag: tagDefault,
+		write:  c.write,
+	}
+	for _, v := range []string{`**/*.json=json`, `*.csv=csv`, `syslog.log=syslog`} {
+		r, err := parseRule(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ing.rules = append(ing.rules, r)
+	}
+	var err error
+	if ing.tg, err = newTimegrinder(``); err != nil {
+		t.Fatal(err)
+	}
+	if ing.cp, err = newCheckpoint(statePath, nil); err != nil {
+		t.Fatal(err)
+	}
+	return ing
+}
+
+func TestWalkBundle(t *testing.T) {
+	dir := t.TempDir()
+	pth := testBundle(t, dir)
+	var c capture
+	ing := newTestIngester(t, &c, ``)
+	if err := ing.ingestInputs([]string{dir}); err != nil {
+		t.Fatal(err)
+	}
+	got := c.byPath(t)
+	exp := map[string][]string{
+		pth + `!/logs/app.log`:                      {`2024-01-02T03:04:05Z first`, `2024-01-02T03:04:06Z second`},
+		pth + `!/logs/nested.zip!/events/data.json`: {`{"a":1}`, `{"a":2,"b":"x"}`},
+		pth + `!/logs/users.csv`:                    {`bob,bob@example.com,"multi` + "\n" + `line"`, `alice,alice@example.com,none`},
+		pth + `!/logs/syslog.log.xz`:                {`Jan  2 03:04:05 host sshd: one`, `Jan  2 03:04:06 host sshd: two`},
+		pth + `!/logs/stream.json.zst`:              {`{"x":1}`, `{"x":2}`, `{"x":3}`},
+	}
+	if len(got) != len(exp) {
+		t.Fatalf("got %d members, expected %d: %v", len(got), len(exp), got)
+	}
+	for k, v := range exp {
+		if strings.Join(got[k], "|") != strings.Join(v, "|") {
+			t.Fatalf("%s: got %q expected %q", k, got[k], v)
+		}
+	}
+
+	tags := map[string]entry.EntryTag{}
+	for _, ent := range c.ents {
+		ev, _ := ent.GetEnumeratedValue(defaultPathEV)
+		tags[ev.(string)] = ent.Tag
+		if ent.SRC != nil {
+			t.Fatalf("unexpected source %v", ent.SRC)
+		}
+	}
+	expTags := map[string]entry.EntryTag{
+		pth + `!/logs/app.log`:                      tagDefault,
+		pth + `!/logs/nested.zip!/events/data.json`: tagJSON,
+		pth + `!/logs/users.csv`:                    tagCSV,
+		pth + `!/logs/syslog.log.xz`:                tagSyslog,
+		pth + `!/logs/stream.json.zst`:              tagJSON,
+	}
+	for k, v := range expTags {
+		if tags[k] != v {
+			t.Fatalf("%s: got tag %d expected %d", k, tags[k], v)
+		}
+	}
+	if ts := c.ents[0].TS.StandardTime(); ts.Year() != 2024 || ts.Second() != 5 {
+		t.Fatalf("bad timestamp %v", ts)
+	}
+}
+
+func TestCheckpoint(t *testing.T) {
+	dir := t.TempDir()
+	in := filepath.Join(dir, `in`)
+	if err := os.Mkdir(in, 0755); err != nil {
+		t.Fatal(err)
+	}
+	testBundle(t, in)
+	statePath := filepath.Join(dir, `state`)
+
+	//pretend a previous run finished the first member before stopping
+	var c capture
+	ing := newTestIngester(t, &c, statePath)
+	id, err := inputID(filepath.Join(in, `bundle.tar.gz`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = ing.cp.mark(id + `|!/logs/app.log`); err != nil {
+		t.Fatal(err)
+	} else if err = ing.cp.flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	ing = newTestIngester(t, &c, statePath)
+	if err = ing.ingestInputs([]string{in}); err != nil {
+		t.Fatal(err)
+	} else if err = ing.cp.flush(); err != nil {
+		t.Fatal(err)
+	}
+	if len(c.ents) != 9 {
+		t.Fatalf("resumed ingest produced %d entries", len(c.ents))
+	}
+
+	//a completed input is skipped entirely and only its identity is retained
+	c.ents = nil
+	ing = newTestIngester(t, &c, statePath)
+	if len(ing.cp.done) != 1 || !ing.cp.isDone(id) {
+		t.Fatalf("bad checkpoint state %v", ing.cp.done)
+	}
+	if err = ing.ingestInputs([]string{in}); err != nil {
+		t.Fatal(err)
+	} else if len(c.ents) != 0 {
+		t.Fatalf("completed input produced %d entries", len(c.ents))
+	}
+}
+
+func TestDetectFormat(t *testing.T) {
+	tests := []struct {
+		name   string
+		data   string
+		format string
+	}{
+		{`a.txt`, "hello\nworld\n", formatLine},
+		{`a.txt`, ` [1, 2]`, formatJSON},
+		{`a.txt`, `{"a":1}{"b":`, formatLine}, // truncated but not by the sample size
+		{`a.txt`, "{not json}\n", formatLine},
+		{`a.txt`, "host,port,proto\nx,1,tcp\ny,2,udp\n", formatCSV},
+		{`a.txt`, "1,2,3\n4,5,6\n7,8,9\n", formatLine},
+		{`a.csv`, "1,2,3\n", formatCSV},
+		{`a.txt`, ``, formatLine},
+	}
+	for _, tc := range tests {
+		f, err := detectFormat(tc.name, bufio.NewReader(strings.NewReader(tc.data)))
+		if err != nil {
+			t.Fatal(err)
+		} else if f != tc.format {
+			t.Fatalf("%q: got %s expected %s", tc.data, f, tc.format)
+		}
+	}
+	if _, err := detectFormat(`x`, bufio.NewReader(strings.NewReader("ab\x00cd"))); err != ErrBinary {
+		t.Fatalf("binary content not detected: %v", err)
+	}
+}
+
+func TestRules(t *testing.T) {
+	if _, err := parseRule(`*.log`); err != ErrInvalidRule {
+		t.Fatalf("missing tag accepted: %v", err)
+	} else if _, err = parseRule(`*.log=bad tag`); err == nil {
+		t.Fatal("invalid tag accepted")
+	}
+	r, err := parseRule(`var/log/*.log=syslog`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !r.match(member{Path: `/x.tar!/var/log/messages.log.gz`, Name: `var/log/messages.log.gz`}) {
+		t.Fatal("compressed member did not match")
+	} else if r.match(member{Path: `/x.tar!/var/log/sub/messages.log`, Name: `var/log/sub/messages.log`}) {
+		t.Fatal("single star crossed a separator")
+	}
+	if r, err = parseRule(`*.log=syslog`); err != nil {
+		t.Fatal(err)
+	} else if !r.match(member{Path: `/x.tar!/a/b/c.log`, Name: `a/b/c.log`}) {
+		t.Fatal("base name rule did not match")
+	}
+}
+
+func TestUnsupported(t *testing.T) {
+	var c capture
+	ing := newTestIngester(t, &c, ``)
+	var warned []string
+	w := walker{
+		maxDepth: 2,
+		leaf:     ing.leaf,
+		warn: func(p string, err error) {
+			warned = append(warned, p)
+		},
+	}
+	dir := t.TempDir()
+	//nest gzip layers deeper than the limit and add a 7z member
+	inner := []byte("line\n")
+	for i := 0; i < 3; i++ {
+		var bb bytes.Buffer
+		gz := gzip.NewWriter(&bb)
+		gz.Write(inner)
+		gz.Close()
+		inner = bb.Bytes()
+	}
+	pth := writeTarGz(t, dir, []tarFile{
+		{`deep.gz`, inner},
+		{`a.7z`, []byte{'7', 'z', 0xbc, 0xaf, 0x27, 0x1c, 0, 4}},
+		{`ok.log`, []byte("fine\n")},
+	})
+	ing.input = pth
+	if err := w.walkFile(pth); err != nil {
+		t.Fatal(err)
+	}
+	if len(warned) != 2 || w.failed != 2 || len(c.ents) != 1 {
+		t.Fatalf("warned %v with %d entries", warned, len(c.ents))
+	}
+}
+
+func TestMemberFailure(t *testing.T) {
+	dir := t.TempDir()
+	pth := writeTarGz(t, dir, []tarFile{
+		{`a.7z`, []byte{'7', 'z', 0xbc, 0xaf, 0x27, 0x1c, 0, 4}},
+		{`ok.log`, []byte("fine\n")},
+	})
+	id, err := inputID(pth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var c capture
+	ing := newTestIngester(t, &c, ``)
+	if err = ing.ingestInputs([]string{pth}); err != nil {
+		t.Fatal(err)
+	}
+	//the good member is checkpointed but the input is left incomplete so the failure is retried
+	if len(c.ents) != 1 {
+		t.Fatalf("got %d entries", len(c.ents))
+	} else if ing.cp.isDone(id) {
+		t.Fatal("input with a failed member was completed")
+	} else if !ing.cp.isDone(id + `|!/ok.log`) {
+		t.Fatal("ingested member was not checkpointed")
+	}
+	c.ents = nil
+	if err = ing.ingestInputs([]string{pth}); err != nil {
+		t.Fatal(err)
+	} else if len(c.ents) != 0 || ing.cp.isDone(id) {
+		t.Fatalf("retry produced %d entries, complete %v", len(c.ents), ing.cp.isDone(id))
+	}
+}
@@ -13,6 +13,8 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"strconv"
+	"strings"
 
 	"golang.org/x/sys/unix"
 )
@@ -20,10 +22,21 @@ import (
 const (
 	minBuff      int = 16
 	rechargeSize int = 1024 * 1024
+
+	canMTU   = unix.CAN_MTU
+	canfdMTU = 72 // sizeof(struct canfd_frame)
+
+	// packed CAN FD frames set the top bit of the length byte and carry the FD flags in the next byte
+	fdLenFlag  = 0x80
+	fdLenMask  = 0x7f
+	canfdBRS   = 0x01 // bit rate switch
+	canfdESI   = 0x02 // error state indicator
+	maxFDBytes = 64
 )
 
 var (
 	ErrFailedPacketRead = errors.New("Failed to read complete CAN packet")
+	ErrInvalidFilter    = errors.New("Invalid CAN filter")
 )
 
 type Cansock struct {
@@ -32,28 +45,42 @@ type Cansock struct {
 	buff []byte
 }
 
-// New creates a new CAN device bound to device such as can0 or vcan0
-func New(dev string) (*Cansock, error) {
+// New creates a new CAN device bound to device such as can0 or vcan0.
+// If fd is set CAN FD frames are received in addition to classic frames,
+// filters are applied in the kernel so unmatched frames are never read.
+func New(dev string, fd bool, filters []unix.CanFilter) (*Cansock, error) {
 	//create the socket
-	fd, err := unix.Socket(unix.AF_CAN, unix.SOCK_RAW, unix.CAN_RAW)
+	sfd, err := unix.Socket(unix.AF_CAN, unix.SOCK_RAW, unix.CAN_RAW)
 	if err != nil {
 		return nil, err
 	}
+	if fd {
+		if err = unix.SetsockoptInt(sfd, unix.SOL_CAN_RAW, unix.CAN_RAW_FD_FRAMES, 1); err != nil {
+			unix.Close(sfd)
+			return nil, fmt.Errorf("failed to enable CAN FD frames: %w", err)
+		}
+	}
+	if len(filters) > 0 {
+		if err = unix.SetsockoptCanRawFilter(sfd, unix.SOL_CAN_RAW, unix.CAN_RAW_FILTER, filters); err != nil {
+			unix.Close(sfd)
+			return nil, fmt.Errorf("failed to set CAN filters: %w", err)
+		}
+	}
 	//get the interface for indexing
 	iface, err := net.InterfaceByName(dev)
 	if err != nil {
-		unix.Close(fd)
+		unix.Close(sfd)
 		return nil, err
 	}
 	addr := &unix.SockaddrCAN{Ifindex: iface.Index}
 	//actually bind the socket to the interface
-	if err := unix.Bind(fd, addr); err != nil {
-		unix.Close(fd)
+	if err := unix.Bind(sfd, addr); err != nil {
+		unix.Close(sfd)
 		return nil, err
 	}
 
 	return &Cansock{
-		fd:   fd,
+		fd:   sfd,
 		sock: &unix.SockaddrCAN{Ifindex: iface.Index},
 	}, nil
 }
@@ -71,10 +98,10 @@ func (c *Cansock) Close() error {
 }
 
 func (c *Cansock) Read() ([]byte, error) {
-	if len(c.buff) < minBuff {
+	if len(c.buff) < canfdMTU {
 		c.buff = make([]byte, rechargeSize)
 	}
-	n, err := unix.Read(c.fd, c.buff[:minBuff])
+	n, err := unix.Read(c.fd, c.buff[:canfdMTU])
 	if err != nil {
 		return nil, err
 	}
@@ -88,19 +115,34 @@ func (c *Cansock) Read() ([]byte, error) {
 	return pkt, nil
 }
 
+// packPacket compacts a raw can_frame or canfd_frame into ID, length, and payload.
+// Classic frames are 4 bytes of ID, a length byte, and the data.
+// FD frames are 4 bytes of ID, the length with fdLenFlag set, a flags byte, and the data.
 func packPacket(r []byte) (pkt []byte, err error) {
-	//ensure incoming packet has the correct size
-	if len(r) != minBuff {
+	switch len(r) {
+	case canMTU:
+		pkt = r
+		//rectify the packet based on data length (we basically just chop off the CRC)
+		canlen := r[4] & 0x0f
+		if canlen > 8 {
+			canlen = 8
+		}
+		copy(pkt[5:], pkt[8:8+canlen])
+		pkt = pkt[:5+canlen]
+	case canfdMTU:
+		pkt = r
+		fdlen := r[4]
+		if fdlen > maxFDBytes {
+			err = ErrFailedPacketRead
+			return
+		}
+		pkt[4] = fdLenFlag | fdlen
+		// flags stay in place at offset 5
+		copy(pkt[6:], pkt[8:8+fdlen])
+		pkt = pkt[:6+fdlen]
+	default:
 		err = ErrFailedPacketRead
-		return
-	}
-	pkt = r
-	//rectify the packet based on data length (we basically just chop off the CRC)
-	canlen := r[4] & 0x0f
-	for i := 0; i < int(canlen); i++ {
-		pkt[5+i] = pkt[8+i]
 	}
-	pkt = pkt[:5+canlen]
 	return
 }
 
@@ -108,6 +150,9 @@ type CanPacket struct {
 	ID       uint32
 	Extended bool
 	RTR      bool
+	FD       bool
+	BRS      bool
+	ESI      bool
 	Data     []byte
 }
 
@@ -117,13 +162,25 @@ func ExtractPacket(d []byte) (pkt CanPacket, err error) {
 		err = ErrInvalidPacket
 		return
 	}
-	//pull the length field
-	l := d[4] & 0xf
-	if len(d) < int(5+l) {
-		err = ErrInvalidPacket
-		return
+	if d[4]&fdLenFlag != 0 {
+		l := int(d[4] & fdLenMask)
+		if len(d) < 6+l {
+			err = ErrInvalidPacket
+			return
+		}
+		pkt.FD = true
+		pkt.BRS = d[5]&canfdBRS != 0
+		pkt.ESI = d[5]&canfdESI != 0
+		pkt.Data = d[6 : 6+l]
+	} else {
+		//pull the length field
+		l := int(d[4] & 0xf)
+		if len(d) < 5+l {
+			err = ErrInvalidPacket
+			return
+		}
+		pkt.Data = d[5 : 5+l]
 	}
-	pkt.Data = d[5:]
 	pkt.Extended = (d[3]&0x80 == 0x80)
 	pkt.RTR = (d[3]&0x40 == 0x40)
 	if !pkt.Extended {
@@ -139,5 +196,43 @@ func (cp CanPacket) String() string {
 	if cp.RTR {
 		str += " RTR"
 	}
+	if cp.FD {
+		str += " FD"
+	}
 	return fmt.Sprintf("%s %x", str, cp.Data)
 }
+
+// parseFilter parses a candump style filter: <id>:<mask> matches when received_id & mask == id & mask,
+// <id>~<mask> inverts the match.  Values are hex, IDs wider than 11 bits or written with
+// 8 hex digits select extended frames, otherwise only standard frames match.
+func parseFilter(v string) (f unix.CanFilter, err error) {
+	v = strings.TrimSpace(v)
+	sep := strings.IndexAny(v, ":~")
+	if sep <= 0 || sep == len(v)-1 {
+		err = fmt.Errorf("%w %q, expected <id>:<mask> or <id>~<mask>", ErrInvalidFilter, v)
+		return
+	}
+	ids, masks := strings.TrimPrefix(strings.TrimPrefix(v[:sep], "0x"), "0X"), strings.TrimPrefix(strings.TrimPrefix(v[sep+1:], "0x"), "0X")
+	var id, mask uint64
+	if id, err = strconv.ParseUint(ids, 16, 32); err != nil {
+		err = fmt.Errorf("%w %q, bad ID: %v", ErrInvalidFilter, v, err)
+		return
+	} else if mask, err = strconv.ParseUint(masks, 16, 32); err != nil {
+		err = fmt.Errorf("%w %q, bad mask: %v", ErrInvalidFilter, v, err)
+		return
+	}
+	if id > unix.CAN_EFF_MASK {
+		err = fmt.Errorf("%w %q, ID is wider than 29 bits", ErrInvalidFilter, v)
+		return
+	}
+	f.Id, f.Mask = uint32(id), uint32(mask)
+	if len(ids) == 8 || id > unix.CAN_SFF_MASK {
+		f.Id |= unix.CAN_EFF_FLAG
+	}
+	// always match on the frame format so standard and extended IDs don't alias
+	f.Mask |= unix.CAN_EFF_FLAG
+	if v[sep] == '~' {
+		f.Id |= unix.CAN_INV_FILTER
+	}
+	return
+}
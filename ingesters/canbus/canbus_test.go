@@ -0,0 +1,158 @@
+/*************************************************************************
+ * Copyright 2026 Gravwell, Inc. All rights reserved.
+ * Contact: <legal@gravwell.io>
+ *
+ * This software may be modified and distributed under the terms of the
+ * BSD 2-clause license. See the LICENSE file for details.
+ **************************************************************************/
+
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"net"
+	"testing"
+
+	"golang.org/x/sys/unix"
+)
+
+// rawFrame builds a can_frame or canfd_frame the way the kernel hands it to us
+func rawFrame(id uint32, data []byte, fd bool, flags byte) []byte {
+	sz := canMTU
+	if fd {
+		sz = canfdMTU
+	}
+	r := make([]byte, sz)
+	binary.LittleEndian.PutUint32(r, id)
+	r[4] = byte(len(data))
+	if fd {
+		r[5] = flags
+	}
+	copy(r[8:], data)
+	return r
+}
+
+func TestPackClassic(t *testing.T) {
+	data := []byte{1, 2, 3, 4}
+	pkt, err := packPacket(rawFrame(0x123, data, false, 0))
+	if err != nil {
+		t.Fatal(err)
+	} else if len(pkt) != 5+len(data) {
+		t.Fatalf("bad packed length %d", len(pkt))
+	}
+	cp, err := ExtractPacket(pkt)
+	if err != nil {
+		t.Fatal(err)
+	} else if cp.ID != 0x123 || cp.Extended || cp.RTR || cp.FD || !bytes.Equal(cp.Data, data) {
+		t.Fatalf("bad packet %+v", cp)
+	}
+
+	if pkt, err = packPacket(rawFrame(0x18FEF1FE|unix.CAN_EFF_FLAG, nil, false, 0)); err != nil {
+		t.Fatal(err)
+	} else if cp, err = ExtractPacket(pkt); err != nil {
+		t.Fatal(err)
+	} else if cp.ID != 0x18FEF1FE || !cp.Extended || len(cp.Data) != 0 {
+		t.Fatalf("bad extended packet %+v", cp)
+	}
+}
+
+func TestPackFD(t *testing.T) {
+	data := make([]byte, 48)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	pkt, err := packPacket(rawFrame(0x7FF, data, true, canfdBRS))
+	if err != nil {
+		t.Fatal(err)
+	} else if len(pkt) != 6+len(data) {
+		t.Fatalf("bad packed length %d", len(pkt))
+	}
+	cp, err := ExtractPacket(pkt)
+	if err != nil {
+		t.Fatal(err)
+	} else if cp.ID != 0x7FF || !cp.FD || !cp.BRS || cp.ESI || !bytes.Equal(cp.Data, data) {
+		t.Fatalf("bad FD packet %+v", cp)
+	}
+
+	bad := rawFrame(1, nil, true, 0)
+	bad[4] = 65
+	if _, err = packPacket(bad); err == nil {
+		t.Fatal("accepted oversized FD frame")
+	}
+	if _, err = packPacket(make([]byte, 20)); err == nil {
+		t.Fatal("accepted a short read")
+	}
+	if _, err = ExtractPacket(pkt[:20]); err == nil {
+		t.Fatal("accepted a truncated FD packet")
+	}
+}
+
+func TestParseFilter(t *testing.T) {
+	tests := []struct {
+		v    string
+		id   uint32
+		mask uint32
+	}{
+		{`123:7FF`, 0x123, 0x7FF | unix.CAN_EFF_FLAG},
+		{`0x100:0x700`, 0x100, 0x700 | unix.CAN_EFF_FLAG},
+		{`200~700`, 0x200 | unix.CAN_INV_FILTER, 0x700 | unix.CAN_EFF_FLAG},
+		{`18FEF100:1FFFFF00`, 0x18FEF100 | unix.CAN_EFF_FLAG, 0x1FFFFF00 | unix.CAN_EFF_FLAG},
+		{`00000123:1FFFFFFF`, 0x123 | unix.CAN_EFF_FLAG, 0x1FFFFFFF | unix.CAN_EFF_FLAG},
+		{` 0:0 `, 0, unix.CAN_EFF_FLAG},
+	}
+	for _, tt := range tests {
+		f, err := parseFilter(tt.v)
+		if err != nil {
+			t.Fatalf("%q: %v", tt.v, err)
+		} else if f.Id != tt.id || f.Mask != tt.mask {
+			t.Fatalf("%q: got %x:%x, expected %x:%x", tt.v, f.Id, f.Mask, tt.id, tt.mask)
+		}
+	}
+	for _, v := range []string{``, `123`, `:7FF`, `123:`, `xyz:7FF`, `123:xyz`, `3FFFFFFF:0`} {
+		if _, err := parseFilter(v); !errors.Is(err, ErrInvalidFilter) {
+			t.Fatalf("%q: expected invalid filter, got %v", v, err)
+		}
+	}
+}
+
+// TestVCAN exercises a live socket, it requires a vcan0 interface configured with an MTU of 72
+func TestVCAN(t *testing.T) {
+	if _, err := net.InterfaceByName(`vcan0`); err != nil {
+		t.Skip("vcan0 not available")
+	}
+	f, err := parseFilter(`100:700`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rdr, err := New(`vcan0`, true, []unix.CanFilter{f})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rdr.Close()
+	wtr, err := New(`vcan0`, true, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wtr.Close()
+
+	// the first frame is filtered in the kernel, only the FD frame should arrive
+	if _, err = unix.Write(wtr.fd, rawFrame(0x200, []byte{1}, false, 0)); err != nil {
+		t.Fatal(err)
+	}
+	data := bytes.Repeat([]byte{0xaa}, 12)
+	if _, err = unix.Write(wtr.fd, rawFrame(0x1AB, data, true, canfdBRS)); err != nil {
+		t.Fatal(err)
+	}
+	pkt, err := rdr.Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cp, err := ExtractPacket(pkt)
+	if err != nil {
+		t.Fatal(err)
+	} else if cp.ID != 0x1AB || !cp.FD || !bytes.Equal(cp.Data, data) {
+		t.Fatalf("bad packet %+v", cp)
+	}
+}
@@ -10,6 +10,7 @@ package main
 
 import (
 	"errors"
+	"fmt"
 	"net"
 	"strings"
 	"time"
@@ -17,6 +18,7 @@ import (
 	"github.com/gravwell/gravwell/v3/ingest"
 	"github.com/gravwell/gravwell/v3/ingest/config"
 	"github.com/gravwell/gravwell/v3/ingest/entry"
+	"golang.org/x/sys/unix"
 )
 
 const (
@@ -34,11 +36,36 @@ type cfgType struct {
 		Encrypted_Backend_Target   []string
 		Pipe_Backend_Target        []string
 	}
-	Sniffer map[string]*struct {
-		Interface       string //interface name to bind to
-		Tag_Name        string
-		Source_Override string
+	Sniffer map[string]*snifferConfig
+}
+
+type snifferConfig struct {
+	Interface       string //interface name to bind to
+	Tag_Name        string
+	Source_Override string
+	CAN_FD          bool     // receive CAN FD frames as well as classic frames
+	Filter          []string // candump style <id>:<mask> or <id>~<mask> filters, hex
+	DBC_File        []string // DBC files used to decode signals into enumerated values
+}
+
+// filters returns the kernel socket filters for the sniffer
+func (sc *snifferConfig) filters() (fs []unix.CanFilter, err error) {
+	for _, v := range sc.Filter {
+		var f unix.CanFilter
+		if f, err = parseFilter(v); err != nil {
+			return
+		}
+		fs = append(fs, f)
 	}
+	return
+}
+
+// dbc loads the sniffer's DBC files, nil is returned if there are none
+func (sc *snifferConfig) dbc() (*dbcDatabase, error) {
+	if len(sc.DBC_File) == 0 {
+		return nil, nil
+	}
+	return loadDBC(sc.DBC_File)
 }
 
 func GetConfig(path, overlayPath string) (*cfgType, error) {
@@ -89,6 +116,12 @@ func verifyConfig(c *cfgType) error {
 				return errors.New("Failed to parse Source_Override")
 			}
 		}
+		if _, err := v.filters(); err != nil {
+			return fmt.Errorf("Sniffer %s: %w", k, err)
+		}
+		if _, err := v.dbc(); err != nil {
+			return fmt.Errorf("Sniffer %s failed to load DBC: %w", k, err)
+		}
 	}
 	return nil
 }
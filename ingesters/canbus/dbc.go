@@ -0,0 +1,467 @@
+/*************************************************************************
+ * Copyright 2026 Gravwell, Inc. All rights reserved.
+ * Contact: <legal@gravwell.io>
+ *
+ * This software may be modified and distributed under the terms of the
+ * BSD 2-clause license. See the LICENSE file for details.
+ **************************************************************************/
+
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gravwell/gravwell/v3/ingest/entry"
+	"golang.org/x/sys/unix"
+)
+
+const (
+	valueTypeInteger = 0
+	valueTypeFloat32 = 1
+	valueTypeFloat64 = 2
+
+	messageEVName = `message`
+	unitEVSuffix  = `.unit`
+	labelEVSuffix = `.label`
+)
+
+var (
+	ErrDBCSyntax = errors.New("invalid DBC syntax")
+
+	// SG_ name [M|mN] : start|length@order sign (factor,offset) [min|max] "unit" receivers
+	sgRegex = regexp.MustCompile(`^SG_\s+(\w+)\s*(M|m\d+M?)?\s*:\s*(\d+)\|(\d+)@([01])([+-])\s*\(\s*([^,\s]+)\s*,\s*([^)\s]+)\s*\)\s*\[\s*([^|\s]*)\s*\|\s*([^\]\s]*)\s*\]\s*"((?:[^"\\]|\\.)*)"`)
+	// BO_ id name: dlc transmitter
+	boRegex = regexp.MustCompile(`^BO_\s+(\d+)\s+(\w+)\s*:\s*(\d+)`)
+	// SIG_VALTYPE_ id signal : type;
+	valTypeRegex = regexp.MustCompile(`^SIG_VALTYPE_\s+(\d+)\s+(\w+)\s*:?\s*([012])\s*;`)
+)
+
+type dbcSignal struct {
+	Name         string
+	Start        int
+	Length       int
+	LittleEndian bool
+	Signed       bool
+	Factor       float64
+	Offset       float64
+	Min          float64
+	Max          float64
+	Unit         string
+	Multiplexor  bool
+	Multiplexed  bool
+	MuxValue     uint64
+	ValueType    int
+	Values       map[int64]string
+}
+
+type dbcMessage struct {
+	ID      uint32 // includes CAN_EFF_FLAG for extended frames
+	Name    string
+	Size    int
+	Signals []*dbcSignal
+	mux     *dbcSignal
+}
+
+// dbcDatabase holds the messages from one or more DBC files keyed by CAN ID
+type dbcDatabase struct {
+	msgs map[uint32]*dbcMessage
+}
+
+// loadDBC parses and merges a set of DBC files, a message ID may only be defined once
+func loadDBC(paths []string) (*dbcDatabase, error) {
+	db := &dbcDatabase{msgs: map[uint32]*dbcMessage{}}
+	for _, p := range paths {
+		fin, err := os.Open(p)
+		if err != nil {
+			return nil, err
+		}
+		err = db.parse(fin)
+		fin.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", p, err)
+		}
+	}
+	return db, nil
+}
+
+// dbcID normalizes a DBC message ID, extended IDs carry bit 31 but some tools omit it
+func dbcID(id uint64) uint32 {
+	if id&unix.CAN_EFF_FLAG == 0 && id > unix.CAN_SFF_MASK {
+		id |= unix.CAN_EFF_FLAG
+	}
+	return uint32(id)
+}
+
+// frameID returns the database key for a received packet
+func frameID(pkt CanPacket) uint32 {
+	if pkt.Extended {
+		return pkt.ID | unix.CAN_EFF_FLAG
+	}
+	return pkt.ID
+}
+
+func (db *dbcDatabase) parse(rdr io.Reader) (err error) {
+	var msg *dbcMessage
+	var defined []*dbcMessage
+	type deferred struct {
+		lineno int
+		stmt   string
+	}
+	var later []deferred // VAL_ and SIG_VALTYPE_ reference messages that may not be parsed yet
+
+	sc := bufio.NewScanner(rdr)
+	sc.Buffer(nil, 1024*1024)
+	var lineno int
+	for {
+		var stmt string
+		var start int
+		if stmt, start, err = nextStatement(sc, &lineno); err != nil {
+			return
+		} else if stmt == `` {
+			break
+		}
+		switch firstToken(stmt) {
+		case `BO_`:
+			m := boRegex.FindStringSubmatch(stmt)
+			if m == nil {
+				return fmt.Errorf("line %d: %w: bad message %q", start, ErrDBCSyntax, stmt)
+			}
+			id, _ := strconv.ParseUint(m[1], 10, 32)
+			size, _ := strconv.Atoi(m[3])
+			msg = &dbcMessage{
+				ID:   dbcID(id),
+				Name: m[2],
+				Size: size,
+			}
+			if _, ok := db.msgs[msg.ID]; ok {
+				return fmt.Errorf("line %d: message %s ID %d is already defined", start, msg.Name, id)
+			}
+			db.msgs[msg.ID] = msg
+			defined = append(defined, msg)
+		case `SG_`:
+			if msg == nil {
+				return fmt.Errorf("line %d: %w: signal outside of a message", start, ErrDBCSyntax)
+			}
+			var sig *dbcSignal
+			if sig, err = parseSignal(stmt); err != nil {
+				return fmt.Errorf("line %d: %w", start, err)
+			}
+			if sig.Multiplexor {
+				if msg.mux != nil {
+					return fmt.Errorf("line %d: message %s has multiple multiplexor signals", start, msg.Name)
+				}
+				msg.mux = sig
+			}
+			msg.Signals = append(msg.Signals, sig)
+		case `VAL_`, `SIG_VALTYPE_`:
+			later = append(later, deferred{lineno: start, stmt: stmt})
+			msg = nil
+		default:
+			msg = nil
+		}
+	}
+	for _, d := range later {
+		if strings.HasPrefix(d.stmt, `VAL_`) {
+			err = db.parseValues(d.stmt)
+		} else {
+			err = db.parseValueType(d.stmt)
+		}
+		if err != nil {
+			return fmt.Errorf("line %d: %w", d.lineno, err)
+		}
+	}
+	for _, m := range defined {
+		for _, s := range m.Signals {
+			if s.Multiplexed && m.mux == nil {
+				return fmt.Errorf("message %s signal %s is multiplexed but there is no multiplexor", m.Name, s.Name)
+			}
+		}
+	}
+	return
+}
+
+// nextStatement returns the next logical statement, joining lines while a quoted string is open
+// and while a VAL_ statement is missing its terminating semicolon
+func nextStatement(sc *bufio.Scanner, lineno *int) (stmt string, start int, err error) {
+	var sb strings.Builder
+	for sc.Scan() {
+		*lineno++
+		line := sc.Text()
+		if sb.Len() == 0 {
+			if line = strings.TrimSpace(line); line == `` {
+				continue
+			}
+			start = *lineno
+		} else {
+			sb.WriteByte('\n')
+		}
+		sb.WriteString(line)
+		s := sb.String()
+		if quotesOpen(s) {
+			continue
+		} else if strings.HasPrefix(s, `VAL_ `) && !strings.HasSuffix(strings.TrimSpace(s), `;`) {
+			continue
+		}
+		return s, start, nil
+	}
+	if err = sc.Err(); err == nil && sb.Len() > 0 {
+		err = fmt.Errorf("line %d: %w: unterminated statement", start, ErrDBCSyntax)
+	}
+	return
+}
+
+func quotesOpen(s string) (open bool) {
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			if open {
+				i++
+			}
+		case '"':
+			open = !open
+		}
+	}
+	return
+}
+
+func firstToken(s string) string {
+	if i := strings.IndexAny(s, " \t:"); i > 0 {
+		return s[:i]
+	}
+	return s
+}
+
+func parseSignal(stmt string) (sig *dbcSignal, err error) {
+	m := sgRegex.FindStringSubmatch(stmt)
+	if m == nil {
+		return nil, fmt.Errorf("%w: bad signal %q", ErrDBCSyntax, stmt)
+	}
+	sig = &dbcSignal{
+		Name:         m[1],
+		LittleEndian: m[5] == `1`,
+		Signed:       m[6] == `-`,
+		Unit:         strings.ReplaceAll(m[11], `\"`, `"`),
+	}
+	if mux := m[2]; mux == `M` {
+		sig.Multiplexor = true
+	} else if mux != `` {
+		// mN or mNM for extended multiplexing, only the simple mux value is honored
+		if sig.MuxValue, err = strconv.ParseUint(strings.TrimSuffix(mux[1:], `M`), 10, 64); err != nil {
+			return nil, fmt.Errorf("%w: bad multiplex indicator %q on %s", ErrDBCSyntax, mux, sig.Name)
+		}
+		sig.Multiplexed = true
+	}
+	sig.Start, _ = strconv.Atoi(m[3])
+	sig.Length, _ = strconv.Atoi(m[4])
+	if sig.Length < 1 || sig.Length > 64 {
+		return nil, fmt.Errorf("signal %s has invalid length %d", sig.Name, sig.Length)
+	} else if sig.Start > 511 {
+		return nil, fmt.Errorf("signal %s has invalid start bit %d", sig.Name, sig.Start)
+	}
+	if sig.Factor, err = strconv.ParseFloat(m[7], 64); err != nil {
+		return nil, fmt.Errorf("signal %s bad factor %q", sig.Name, m[7])
+	} else if sig.Offset, err = strconv.ParseFloat(m[8], 64); err != nil {
+		return nil, fmt.Errorf("signal %s bad offset %q", sig.Name, m[8])
+	}
+	// min and max are informational, tolerate empty or odd values
+	sig.Min, _ = strconv.ParseFloat(m[9], 64)
+	sig.Max, _ = strconv.ParseFloat(m[10], 64)
+	return sig, nil
+}
+
+func (db *dbcDatabase) signal(id uint64, name string) (*dbcSignal, error) {
+	msg, ok := db.msgs[dbcID(id)]
+	if !ok {
+		return nil, fmt.Errorf("unknown message ID %d", id)
+	}
+	for _, s := range msg.Signals {
+		if s.Name == name {
+			return s, nil
+		}
+	}
+	return nil, fmt.Errorf("message %s has no signal %s", msg.Name, name)
+}
+
+// parseValues handles VAL_ id signal N "desc" N "desc" ... ;
+func (db *dbcDatabase) parseValues(stmt string) error {
+	toks, err := tokenize(strings.TrimSuffix(strings.TrimSpace(stmt), `;`))
+	if err != nil {
+		return err
+	} else if len(toks) < 3 || len(toks)%2 != 1 {
+		return fmt.Errorf("%w: bad value description %q", ErrDBCSyntax, stmt)
+	}
+	id, err := strconv.ParseUint(toks[1], 10, 32)
+	if err != nil {
+		// VAL_ without a numeric ID refers to an environment variable, not a signal
+		return nil
+	}
+	sig, err := db.signal(id, toks[2])
+	if err != nil {
+		return err
+	}
+	if sig.Values == nil {
+		sig.Values = map[int64]string{}
+	}
+	for i := 3; i+1 < len(toks); i += 2 {
+		v, err := strconv.ParseInt(toks[i], 10, 64)
+		if err != nil {
+			return fmt.Errorf("%w: bad value %q for %s", ErrDBCSyntax, toks[i], sig.Name)
+		}
+		sig.Values[v] = toks[i+1]
+	}
+	return nil
+}
+
+func (db *dbcDatabase) parseValueType(stmt string) error {
+	m := valTypeRegex.FindStringSubmatch(stmt)
+	if m == nil {
+		return fmt.Errorf("%w: bad signal value type %q", ErrDBCSyntax, stmt)
+	}
+	id, _ := strconv.ParseUint(m[1], 10, 32)
+	sig, err := db.signal(id, m[2])
+	if err != nil {
+		return err
+	}
+	sig.ValueType, _ = strconv.Atoi(m[3])
+	if sig.ValueType == valueTypeFloat32 && sig.Length != 32 {
+		return fmt.Errorf("float signal %s must be 32 bits", sig.Name)
+	} else if sig.ValueType == valueTypeFloat64 && sig.Length != 64 {
+		return fmt.Errorf("double signal %s must be 64 bits", sig.Name)
+	}
+	return nil
+}
+
+// tokenize splits on whitespace, keeping quoted strings (without their quotes) as single tokens
+func tokenize(s string) (toks []string, err error) {
+	for {
+		if s = strings.TrimLeft(s, " \t\r\n"); s == `` {
+			return
+		}
+		if s[0] != '"' {
+			i := strings.IndexAny(s, " \t\r\n")
+			if i < 0 {
+				i = len(s)
+			}
+			toks = append(toks, s[:i])
+			s = s[i:]
+			continue
+		}
+		var sb strings.Builder
+		i := 1
+		for ; i < len(s) && s[i] != '"'; i++ {
+			if s[i] == '\\' && i+1 < len(s) {
+				i++
+			}
+			sb.WriteByte(s[i])
+		}
+		if i >= len(s) {
+			return nil, fmt.Errorf("%w: unterminated string", ErrDBCSyntax)
+		}
+		toks = append(toks, sb.String())
+		s = s[i+1:]
+	}
+}
+
+// raw pulls the signal bits out of the payload, ok is false if the payload is too short
+func (s *dbcSignal) raw(data []byte) (v uint64, ok bool) {
+	if s.LittleEndian {
+		for i := 0; i < s.Length; i++ {
+			bit := s.Start + i
+			if bit/8 >= len(data) {
+				return 0, false
+			}
+			v |= uint64((data[bit/8]>>(bit%8))&1) << i
+		}
+		return v, true
+	}
+	// motorola byte order, the start bit is the most significant bit in sawtooth numbering
+	pos := s.Start
+	for i := 0; i < s.Length; i++ {
+		if pos/8 >= len(data) {
+			return 0, false
+		}
+		v = v<<1 | uint64((data[pos/8]>>(pos%8))&1)
+		if pos%8 == 0 {
+			pos += 15
+		} else {
+			pos--
+		}
+	}
+	return v, true
+}
+
+// decode returns the raw integer (used for value descriptions) and the scaled physical value
+func (s *dbcSignal) decode(data []byte) (rv int64, phys float64, ok bool) {
+	var v uint64
+	if v, ok = s.raw(data); !ok {
+		return
+	}
+	switch s.ValueType {
+	case valueTypeFloat32:
+		phys = float64(math.Float32frombits(uint32(v)))
+		rv = int64(phys)
+	case valueTypeFloat64:
+		phys = math.Float64frombits(v)
+		rv = int64(phys)
+	default:
+		if s.Signed && s.Length < 64 && v&(1<<(s.Length-1)) != 0 {
+			rv = int64(v) - int64(1)<<s.Length
+		} else {
+			rv = int64(v)
+		}
+		if s.Signed || s.Length < 64 {
+			phys = float64(rv)
+		} else {
+			phys = float64(v)
+		}
+	}
+	phys = phys*s.Factor + s.Offset
+	return
+}
+
+// Decode converts a packet into enumerated values: the message name, then each signal's physical value
+// along with its unit and value description when the DBC defines them.
+// Multiplexed signals are only emitted when the multiplexor selects them.
+// A frame whose multiplexor cannot be read is not decoded, since there is no way to tell which signals it carries.
+func (db *dbcDatabase) Decode(pkt CanPacket) (evs []entry.EnumeratedValue, ok bool) {
+	if db == nil || pkt.RTR {
+		return
+	}
+	msg, ok := db.msgs[frameID(pkt)]
+	if !ok {
+		return
+	}
+	evs = append(evs, entry.EnumeratedValue{Name: messageEVName, Value: entry.StringEnumData(msg.Name)})
+	var muxValue uint64
+	if msg.mux != nil {
+		var mok bool
+		if muxValue, mok = msg.mux.raw(pkt.Data); !mok {
+			return nil, false
+		}
+	}
+	for _, s := range msg.Signals {
+		if s.Multiplexed && s.MuxValue != muxValue {
+			continue
+		}
+		rv, phys, sok := s.decode(pkt.Data)
+		if !sok {
+			continue
+		}
+		evs = append(evs, entry.EnumeratedValue{Name: s.Name, Value: entry.Float64EnumData(phys)})
+		if s.Unit != `` {
+			evs = append(evs, entry.EnumeratedValue{Name: s.Name + unitEVSuffix, Value: entry.StringEnumData(s.Unit)})
+		}
+		if lbl, lok := s.Values[rv]; lok {
+			evs = append(evs, entry.EnumeratedValue{Name: s.Name + labelEVSuffix, Value: entry.StringEnumData(lbl)})
+		}
+	}
+	return
+}
@@ -0,0 +1,235 @@
+/*************************************************************************
+ * Copyright 2026 Gravwell, Inc. All rights reserved.
+ * Contact: <legal@gravwell.io>
+ *
+ * This software may be modified and distributed under the terms of the
+ * BSD 2-clause license. See the LICENSE file for details.
+ **************************************************************************/
+
+package main
+
+import (
+	"encoding/binary"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gravwell/gravwell/v3/ingest/entry"
+)
+
+const testDBC = `VERSION ""
+
+NS_ :
+	CM_
+	BA_DEF_
+
+BS_:
+
+BU_: ECU Logger
+
+BO_ 291 Powertrain: 8 ECU
+ SG_ EngineSpeed : 0|16@1+ (0.25,0) [0|16383.75] "rpm" Logger
+ SG_ CoolantTemp : 16|8@1+ (1,-40) [-40|215] "degC" Logger
+ SG_ Torque : 24|12@1- (0.5,0) [-1024|1023.5] "Nm" Logger
+ SG_ Gear : 36|4@1+ (1,0) [0|15] "" Logger
+
+BO_ 2566844926 Chassis: 8 ECU
+ SG_ Speed : 7|16@0+ (0.01,0) [0|655.35] "km/h" Logger
+ SG_ Accel : 23|8@0- (0.1,0) [-12.8|12.7] "m/s2" Logger
+
+BO_ 768 Battery: 8 ECU
+ SG_ Page M : 0|8@1+ (1,0) [0|255] "" Logger
+ SG_ Voltage m0 : 8|16@1+ (0.001,0) [0|65.535] "V" Logger
+ SG_ Current m1 : 8|16@1- (0.01,0) [-327.68|327.67] "A" Logger
+
+BO_ 1024 Pressure: 64 ECU
+ SG_ Ratio : 0|32@1- (1,0) [0|0] "" Logger
+ SG_ Tank : 480|32@1+ (1,0) [0|0] "kPa" Logger
+
+CM_ SG_ 291 EngineSpeed "Crankshaft speed,
+this comment spans lines and mentions
+BO_ 999 Fake: 8 ECU which is not a message";
+VAL_ 291 Gear 0 "Neutral" 1 "First" 15 "Reverse \"R\"" ;
+SIG_VALTYPE_ 1024 Ratio : 1;
+`
+
+func testDB(t *testing.T) *dbcDatabase {
+	t.Helper()
+	db := &dbcDatabase{msgs: map[uint32]*dbcMessage{}}
+	if err := db.parse(strings.NewReader(testDBC)); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func evMap(evs []entry.EnumeratedValue) map[string]interface{} {
+	r := map[string]interface{}{}
+	for _, ev := range evs {
+		r[ev.Name] = ev.Value.Interface()
+	}
+	return r
+}
+
+func checkEVs(t *testing.T, evs []entry.EnumeratedValue, want map[string]interface{}) {
+	t.Helper()
+	got := evMap(evs)
+	if len(got) != len(want) {
+		t.Fatalf("got %d values, expected %d: %v", len(got), len(want), got)
+	}
+	for k, wv := range want {
+		gv, ok := got[k]
+		if !ok {
+			t.Fatalf("missing %q: %v", k, got)
+		}
+		if wf, ok := wv.(float64); ok {
+			if gf, ok := gv.(float64); !ok || math.Abs(gf-wf) > 1e-9 {
+				t.Fatalf("%q = %v, expected %v", k, gv, wv)
+			}
+		} else if gv != wv {
+			t.Fatalf("%q = %v, expected %v", k, gv, wv)
+		}
+	}
+}
+
+func TestDBCParse(t *testing.T) {
+	db := testDB(t)
+	if len(db.msgs) != 4 {
+		t.Fatalf("expected 4 messages, got %d", len(db.msgs))
+	}
+	if _, ok := db.msgs[999]; ok {
+		t.Fatal("parsed a message out of a comment")
+	}
+	m, ok := db.msgs[0x18FEF1FE|0x80000000]
+	if !ok || m.Name != `Chassis` {
+		t.Fatalf("extended message missing: %v", db.msgs)
+	}
+	// a DBC that leaves off bit 31 on an extended ID still lands on the extended key
+	db2 := &dbcDatabase{msgs: map[uint32]*dbcMessage{}}
+	if err := db2.parse(strings.NewReader("BO_ 419361278 Chassis: 8 ECU\n")); err != nil {
+		t.Fatal(err)
+	} else if _, ok = db2.msgs[0x18FEF1FE|0x80000000]; !ok {
+		t.Fatalf("extended ID not normalized: %v", db2.msgs)
+	}
+}
+
+func TestDBCDuplicate(t *testing.T) {
+	dir := t.TempDir()
+	a, b := filepath.Join(dir, `a.dbc`), filepath.Join(dir, `b.dbc`)
+	if err := os.WriteFile(a, []byte(testDBC), 0600); err != nil {
+		t.Fatal(err)
+	} else if err = os.WriteFile(b, []byte("BO_ 768 Other: 8 ECU\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadDBC([]string{a}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadDBC([]string{a, b}); err == nil {
+		t.Fatal("accepted a duplicate message ID")
+	}
+}
+
+func TestDBCIntel(t *testing.T) {
+	db := testDB(t)
+	// speed 8000 * 0.25, temp 130 - 40, torque -200 in 12 bits, gear 1
+	pkt := CanPacket{ID: 291, Data: []byte{0x40, 0x1f, 130, 0x38, 0x1f, 0, 0, 0}}
+	evs, ok := db.Decode(pkt)
+	if !ok {
+		t.Fatal("failed to decode")
+	}
+	checkEVs(t, evs, map[string]interface{}{
+		`message`:          `Powertrain`,
+		`EngineSpeed`:      2000.0,
+		`EngineSpeed.unit`: `rpm`,
+		`CoolantTemp`:      90.0,
+		`CoolantTemp.unit`: `degC`,
+		`Torque`:           -100.0,
+		`Torque.unit`:      `Nm`,
+		`Gear`:             1.0,
+		`Gear.label`:       `First`,
+	})
+
+	pkt.Data[4] = 0xff
+	evs, _ = db.Decode(pkt)
+	if lbl := evMap(evs)[`Gear.label`]; lbl != `Reverse "R"` {
+		t.Fatalf("bad escaped label %v", lbl)
+	}
+
+	// a standard frame must not match an extended ID and vice versa
+	if _, ok = db.Decode(CanPacket{ID: 291, Extended: true, Data: pkt.Data}); ok {
+		t.Fatal("extended frame matched a standard ID")
+	}
+	if _, ok = db.Decode(CanPacket{ID: 291, RTR: true}); ok {
+		t.Fatal("decoded a remote request")
+	}
+}
+
+func TestDBCMotorola(t *testing.T) {
+	db := testDB(t)
+	evs, ok := db.Decode(CanPacket{ID: 0x18FEF1FE, Extended: true, Data: []byte{0x12, 0x34, 0xf6, 0, 0, 0, 0, 0}})
+	if !ok {
+		t.Fatal("failed to decode")
+	}
+	checkEVs(t, evs, map[string]interface{}{
+		`message`:    `Chassis`,
+		`Speed`:      46.60,
+		`Speed.unit`: `km/h`,
+		`Accel`:      -1.0,
+		`Accel.unit`: `m/s2`,
+	})
+}
+
+func TestDBCMultiplex(t *testing.T) {
+	db := testDB(t)
+	evs, ok := db.Decode(CanPacket{ID: 768, Data: []byte{0, 0xe0, 0x2e, 0, 0, 0, 0, 0}})
+	if !ok {
+		t.Fatal("failed to decode")
+	}
+	checkEVs(t, evs, map[string]interface{}{
+		`message`:      `Battery`,
+		`Page`:         0.0,
+		`Voltage`:      12.0,
+		`Voltage.unit`: `V`,
+	})
+	if evs, ok = db.Decode(CanPacket{ID: 768, Data: []byte{1, 0x6a, 0xff, 0, 0, 0, 0, 0}}); !ok {
+		t.Fatal("failed to decode")
+	}
+	checkEVs(t, evs, map[string]interface{}{
+		`message`:      `Battery`,
+		`Page`:         1.0,
+		`Current`:      -1.5,
+		`Current.unit`: `A`,
+	})
+
+	// a frame too short to hold the multiplexor is not decoded at all
+	if evs, ok = db.Decode(CanPacket{ID: 768}); ok {
+		t.Fatalf("decoded a frame without a multiplexor: %v", evs)
+	}
+}
+
+func TestDBCFD(t *testing.T) {
+	db := testDB(t)
+	data := make([]byte, 64)
+	binary.LittleEndian.PutUint32(data, math.Float32bits(0.5))
+	binary.LittleEndian.PutUint32(data[60:], 101325)
+	evs, ok := db.Decode(CanPacket{ID: 1024, FD: true, Data: data})
+	if !ok {
+		t.Fatal("failed to decode")
+	}
+	checkEVs(t, evs, map[string]interface{}{
+		`message`:   `Pressure`,
+		`Ratio`:     0.5,
+		`Tank`:      101325.0,
+		`Tank.unit`: `kPa`,
+	})
+
+	// signals past the end of a short payload are skipped
+	if evs, ok = db.Decode(CanPacket{ID: 1024, FD: true, Data: data[:8]}); !ok {
+		t.Fatal("failed to decode")
+	}
+	checkEVs(t, evs, map[string]interface{}{
+		`message`: `Pressure`,
+		`Ratio`:   0.5,
+	})
+}
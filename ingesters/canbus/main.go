@@ -26,6 +26,7 @@ import (
 	"github.com/gravwell/gravwell/v3/ingest/entry"
 	"github.com/gravwell/gravwell/v3/ingesters/utils"
 	"github.com/gravwell/gravwell/v3/ingesters/version"
+	"golang.org/x/sys/unix"
 
 	gravwelldebug "github.com/gravwell/gravwell/v3/debug"
 )
@@ -58,6 +59,9 @@ type sniffer struct {
 	name      string
 	Interface string
 	TagName   string
+	fd        bool
+	filters   []unix.CanFilter
+	dbc       *dbcDatabase
 	c         *Cansock
 	tag       entry.EntryTag
 	src       net.IP
@@ -66,7 +70,7 @@ type sniffer struct {
 	active    bool
 }
 
-func init() {
+func parseFlags() {
 	flag.Parse()
 	if *ver {
 		version.PrintVersion(os.Stdout)
@@ -78,6 +82,7 @@ func init() {
 }
 
 func main() {
+	parseFlags()
 	go gravwelldebug.HandleDebugSignals("canbus")
 	debug.SetTraceback("all")
 	cfg, err := GetConfig(*confLoc, *confdLoc)
@@ -111,7 +116,17 @@ func main() {
 				log.Fatal("Source-Override is invalid")
 			}
 		}
-		c, err := New(v.Interface)
+		filters, err := v.filters()
+		if err != nil {
+			closeSniffers(sniffs)
+			log.Fatal("Invalid filter on ", k, ": ", err)
+		}
+		dbc, err := v.dbc()
+		if err != nil {
+			closeSniffers(sniffs)
+			log.Fatal("Failed to load DBC files for ", k, ": ", err)
+		}
+		c, err := New(v.Interface, v.CAN_FD, filters)
 		if err != nil {
 			log.Fatal("Failed to get new can interface on ", v.Interface, err)
 		}
@@ -120,6 +135,9 @@ func main() {
 			src:       src,
 			Interface: v.Interface,
 			TagName:   v.Tag_Name,
+			fd:        v.CAN_FD,
+			filters:   filters,
+			dbc:       dbc,
 			c:         c,
 			die:       make(chan bool, 1),
 			res:       make(chan results, 1),
@@ -203,7 +221,7 @@ loop:
 			break loop
 		}
 		//sleep over, try to reopen our pcap device
-		if c, err = New(s.Interface); err == nil {
+		if c, err = New(s.Interface, s.fd, s.filters); err == nil {
 			ok = true
 			break
 		}
@@ -264,6 +282,13 @@ mainLoop:
 				Tag:  s.tag,
 				Data: pkt,
 			}
+			if s.dbc != nil {
+				if cp, err := ExtractPacket(pkt); err == nil {
+					if evs, ok := s.dbc.Decode(cp); ok {
+						e.AddEnumeratedValues(evs)
+					}
+				}
+			}
 			if err := igst.WriteEntry(e); err != nil {
 				s.c.Close()
 				fmt.Fprintf(os.Stderr, "Failed to write entry: %v\n", err)
@@ -14,6 +14,7 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"runtime"
 	"sort"
 	"strings"
 	"time"
@@ -44,6 +45,7 @@ type cfgReadType struct {
 	Global       global
 	Attach       attach.AttachConfig
 	Follower     map[string]*follower
+	Journal      map[string]*journalFollower
 	Preprocessor processors.ProcessorConfig
 	TimeFormat   config.CustomTimeFormat
 }
@@ -64,6 +66,10 @@ type follower struct {
 	Timezone_Override         string
 	Regex_Delimiter           string
 	Preprocessor              []string
+	// rotation handling
+	Identity                    string // inode (default) or fingerprint, fingerprint tracks files by their leading bytes
+	Fingerprint_Size            int    // number of leading bytes used for fingerprints
+	Follow_Compressed_Rotations bool   // finish reading rotated files that were compressed before we were done with them
 	// these two must be used together
 	Timestamp_Regex         string
 	Timestamp_Format_String string
@@ -71,6 +77,19 @@ type follower struct {
 	timeFormats config.CustomTimeFormat
 }
 
+// journalFollower reads systemd journal files directly, entries carry the journal fields as enumerated values
+type journalFollower struct {
+	Journal_Directory string // defaults to /var/log/journal
+	Tag_Name          string
+	Unit              []string // only ingest entries from matching _SYSTEMD_UNIT values, globs are allowed
+	Identifier        []string // only ingest entries from matching SYSLOG_IDENTIFIER values, globs are allowed
+	Max_Priority      string   // only ingest entries at this priority or more severe, e.g. warning or 4
+	Field             []string // journal fields to attach as enumerated values, "*" attaches all of them
+	Start_At_Tail     bool     // on first start skip entries that were already in the journal
+	Ignore_Timestamps bool     // use the current time instead of the entry realtime timestamp
+	Preprocessor      []string
+}
+
 type global struct {
 	config.IngestConfig
 	Max_Files_Watched    int
@@ -81,6 +100,7 @@ type cfgType struct {
 	global
 	Attach       attach.AttachConfig
 	Follower     map[string]*follower
+	Journal      map[string]*journalFollower
 	Preprocessor processors.ProcessorConfig
 	TimeFormat   config.CustomTimeFormat
 }
@@ -96,6 +116,7 @@ func GetConfig(path, overlayPath string) (*cfgType, error) {
 		global:       cr.Global,
 		Attach:       cr.Attach,
 		Follower:     cr.Follower,
+		Journal:      cr.Journal,
 		Preprocessor: cr.Preprocessor,
 		TimeFormat:   cr.TimeFormat,
 	}
@@ -116,8 +137,10 @@ func (c *cfgType) Verify() error {
 	} else if c.global.Max_Files_Watched <= 0 {
 		c.global.Max_Files_Watched = defaultMaxWatchedFiles
 	}
-	if len(c.Follower) == 0 {
+	if len(c.Follower) == 0 && len(c.Journal) == 0 {
 		return errors.New("No Followers specified")
+	} else if len(c.Journal) > 0 && runtime.GOOS == `windows` {
+		return errors.New("Journal followers are not supported on Windows")
 	}
 	if err := c.Preprocessor.Validate(); err != nil {
 		return err
@@ -159,6 +182,43 @@ func (c *cfgType) Verify() error {
 		if err := c.Preprocessor.CheckProcessors(v.Preprocessor); err != nil {
 			return fmt.Errorf("Follower %s preprocessor invalid: %v", k, err)
 		}
+		if _, err := v.RotationConfig(); err != nil {
+			return fmt.Errorf("Follower %s rotation config invalid: %v", k, err)
+		}
+	}
+	for k, v := range c.Journal {
+		if _, ok := c.Follower[k]; ok {
+			return fmt.Errorf("Journal %s has the same name as a Follower", k)
+		}
+		if err := v.verify(k); err != nil {
+			return err
+		} else if err = c.Preprocessor.CheckProcessors(v.Preprocessor); err != nil {
+			return fmt.Errorf("Journal %s preprocessor invalid: %v", k, err)
+		}
+	}
+	return nil
+}
+
+func (j *journalFollower) verify(name string) error {
+	if j.Journal_Directory == `` {
+		j.Journal_Directory = filewatch.DefaultJournalDirectory
+	}
+	j.Journal_Directory = filepath.Clean(j.Journal_Directory)
+	if len(j.Tag_Name) == 0 {
+		j.Tag_Name = entry.DefaultTagName
+	}
+	if ingest.CheckTag(j.Tag_Name) != nil {
+		return errors.New("Invalid characters in the Tag-Name for " + name)
+	}
+	if j.Max_Priority != `` {
+		if _, err := filewatch.ParseJournalPriority(j.Max_Priority); err != nil {
+			return fmt.Errorf("Journal %s has an invalid Max-Priority: %v", name, err)
+		}
+	}
+	for _, g := range append(append([]string{}, j.Unit...), j.Identifier...) {
+		if _, err := filepath.Match(g, ``); err != nil {
+			return fmt.Errorf("Journal %s has an invalid Unit or Identifier pattern %q: %v", name, g, err)
+		}
 	}
 	return nil
 }
@@ -175,6 +235,15 @@ func (c *cfgType) Tags() ([]string, error) {
 			tagMp[v.Tag_Name] = true
 		}
 	}
+	for _, v := range c.Journal {
+		if len(v.Tag_Name) == 0 {
+			continue
+		}
+		if _, ok := tagMp[v.Tag_Name]; !ok {
+			tags = append(tags, v.Tag_Name)
+			tagMp[v.Tag_Name] = true
+		}
+	}
 	if len(tags) == 0 {
 		return nil, errors.New("No tags specified")
 	}
@@ -252,6 +321,16 @@ func (f follower) TimezoneOverride() string {
 	return f.Timezone_Override
 }
 
+func (f follower) RotationConfig() (rc filewatch.RotationConfig, err error) {
+	if rc.Identity, err = filewatch.ParseIdentity(f.Identity); err != nil {
+		return
+	}
+	rc.FingerprintSize = int64(f.Fingerprint_Size)
+	rc.FollowCompressed = f.Follow_Compressed_Rotations
+	err = rc.Validate()
+	return
+}
+
 func (g *global) Verify() (err error) {
 	if err = g.IngestConfig.Verify(); err != nil {
 		return
@@ -163,6 +163,9 @@ func main() {
 			Hnd:        lh,
 			Recursive:  val.Recursive,
 		}
+		if c.RotationConfig, err = val.RotationConfig(); err != nil {
+			lg.FatalCode(0, "invalid rotation config", log.KVErr(err))
+		}
 		if rex, ok, err := val.TimestampDelimited(); err != nil {
 			lg.FatalCode(0, "invalid timestamp delimiter", log.KVErr(err))
 		} else if ok {
@@ -180,6 +183,46 @@ func main() {
 				log.KV("filter", val.File_Filter), log.KVErr(err))
 		}
 	}
+	for k, val := range cfg.Journal {
+		pproc, err := cfg.Preprocessor.ProcessorSet(igst, val.Preprocessor)
+		if err != nil {
+			lg.FatalCode(0, "preprocessor construction error", log.KVErr(err))
+		}
+		procs = append(procs, pproc)
+		tag, err := igst.GetTag(val.Tag_Name)
+		if err != nil {
+			lg.Fatal("failed to resolve tag", log.KV("journal", k), log.KV("tag", val.Tag_Name), log.KVErr(err))
+		}
+		jcfg := filewatch.JournalHandlerConfig{
+			TagName:     val.Tag_Name,
+			Tag:         tag,
+			Src:         src,
+			IgnoreTS:    val.Ignore_Timestamps,
+			Units:       val.Unit,
+			Identifiers: val.Identifier,
+			MaxPriority: val.Max_Priority,
+			Fields:      val.Field,
+			Logger:      lg,
+			Ctx:         wtcher.Context(),
+		}
+		if debugOn {
+			jcfg.Debugger = debugout
+		}
+		jh, err := filewatch.NewJournalHandler(jcfg, pproc)
+		if err != nil {
+			lg.Fatal("failed to generate journal handler", log.KV("journal", k), log.KVErr(err))
+		}
+		c := filewatch.JournalConfig{
+			Name:        k,
+			Dir:         val.Journal_Directory,
+			StartAtTail: val.Start_At_Tail,
+			Hnd:         jh,
+		}
+		if err := wtcher.AddJournal(c); err != nil {
+			wtcher.Close()
+			lg.Fatal("failed to add journal", log.KV("journal", k), log.KV("path", val.Journal_Directory), log.KVErr(err))
+		}
+	}
 	qc := utils.GetQuitChannel()
 	if quit, err := wtcher.Catchup(qc); err != nil {
 		lg.Error("failed to catchup file watcher", log.KVErr(err))
@@ -195,7 +238,7 @@ func main() {
 			os.Exit(-1)
 		}
 
-		debugout("Started following %d locations\n", len(cfg.Follower))
+		debugout("Started following %d locations and %d journals\n", len(cfg.Follower), len(cfg.Journal))
 		debugout("Running\n")
 		//listen for signals so we can close gracefully
 		select {
@@ -386,6 +386,10 @@ func (m *mainService) init(ctx context.Context) error {
 			Hnd:        lh,
 			Recursive:  val.Recursive,
 		}
+		if c.RotationConfig, err = val.RotationConfig(); err != nil {
+			errorout("Invalid rotation config: %v\n", err)
+			return err
+		}
 		if rex, ok, err := val.TimestampDelimited(); err != nil {
 			errorout("Invalid timestamp delimiter: %v\n", err)
 			return err
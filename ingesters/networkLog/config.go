@@ -10,6 +10,7 @@ package main
 
 import (
 	"errors"
+	"fmt"
 	"math"
 	"net"
 	"sort"
@@ -18,6 +19,7 @@ import (
 	"github.com/gravwell/gravwell/v3/ingest/attach"
 	"github.com/gravwell/gravwell/v3/ingest/config"
 	"github.com/gravwell/gravwell/v3/ingest/entry"
+	"github.com/gravwell/gravwell/v3/ingesters/utils/flows"
 )
 
 const (
@@ -45,6 +47,7 @@ type snif struct {
 	Snap_Len        int    //max capture length for packets
 	BPF_Filter      string //BPF-syntax expression to filter packets captured
 	Source_Override string //override normal source IP of the interface
+	flows.Config           //optional flow summaries in place of whole packets
 }
 
 type cfgType struct {
@@ -101,14 +104,19 @@ func (c *cfgType) Verify() error {
 		if ingest.CheckTag(v.Tag_Name) != nil {
 			return errors.New("Invalid characters in the \"" + v.Tag_Name + "\"Tag-Name for " + k)
 		}
-		if err := getEnvInt(&v.Snap_Len, defaultSnapLen, envSnapLen); err != nil {
+		//flow mode needs packet payloads to extract protocol metadata
+		defSnapLen := defaultSnapLen
+		if v.Flow_Mode {
+			defSnapLen = maxSnapLen
+		}
+		if err := getEnvInt(&v.Snap_Len, defSnapLen, envSnapLen); err != nil {
 			return err
 		}
 		if v.Snap_Len > maxSnapLen || v.Snap_Len < 0 {
 			return errors.New("Invalid snaplen. Must be < 65535 and > 0")
 		}
 		if v.Snap_Len == 0 {
-			v.Snap_Len = defaultSnapLen
+			v.Snap_Len = defSnapLen
 		}
 		if v.Source_Override != `` {
 			if net.ParseIP(v.Source_Override) == nil {
@@ -118,6 +126,9 @@ func (c *cfgType) Verify() error {
 		if err := config.LoadEnvVar(&v.BPF_Filter, envBPFFilter, defaultBpfFilter); err != nil {
 			return err
 		}
+		if err := v.Config.Verify(); err != nil {
+			return fmt.Errorf("Sniffer %s: %w", k, err)
+		}
 	}
 	return nil
 }
@@ -127,12 +138,14 @@ func (c *cfgType) Tags() ([]string, error) {
 	var tags []string
 	tagMp := make(map[string]bool, 1)
 	for _, v := range c.Sniffer {
-		if len(v.Tag_Name) == 0 {
-			continue
-		}
-		if _, ok := tagMp[v.Tag_Name]; !ok {
-			tags = append(tags, v.Tag_Name)
-			tagMp[v.Tag_Name] = true
+		for _, tag := range []string{v.Tag_Name, v.Packet_Tag_Name} {
+			if len(tag) == 0 {
+				continue
+			}
+			if _, ok := tagMp[tag]; !ok {
+				tags = append(tags, tag)
+				tagMp[tag] = true
+			}
 		}
 	}
 	if len(tags) == 0 {
@@ -27,7 +27,9 @@ import (
 	"github.com/gravwell/gravwell/v3/ingesters/base"
 	"github.com/gravwell/gravwell/v3/ingesters/utils"
 	"github.com/gravwell/gravwell/v3/ingesters/utils/caps"
+	"github.com/gravwell/gravwell/v3/ingesters/utils/flows"
 
+	"github.com/google/gopacket"
 	"github.com/google/gopacket/layers"
 	"github.com/google/gopacket/pcap"
 )
@@ -39,6 +41,7 @@ const (
 	ingesterName                    = "networkLog"
 	appName                         = `networklog`
 	pktTimeout        time.Duration = 500 * time.Millisecond
+	flowExpireTick    time.Duration = time.Second
 )
 
 var (
@@ -62,6 +65,9 @@ type sniffer struct {
 	Interface string
 	TagName   string
 	tag       entry.EntryTag
+	PktTag    string
+	pktTag    entry.EntryTag
+	flowCfg   flows.Config
 	SnapLen   int
 	BPFFilter string
 	handle    *pcap.Handle
@@ -161,6 +167,8 @@ func main() {
 			Promisc:   v.Promisc,
 			Interface: v.Interface,
 			TagName:   v.Tag_Name,
+			PktTag:    v.Packet_Tag_Name,
+			flowCfg:   v.Config,
 			SnapLen:   v.Snap_Len,
 			BPFFilter: v.BPF_Filter,
 			handle:    hnd,
@@ -177,6 +185,13 @@ func main() {
 			lg.Fatal("failed to resolve tag", log.KV("tag", sniffs[i].TagName), log.KVErr(err))
 		}
 		sniffs[i].tag = tag
+		sniffs[i].pktTag = tag
+		if sniffs[i].PktTag != `` {
+			if sniffs[i].pktTag, err = igst.GetTag(sniffs[i].PktTag); err != nil {
+				closeSniffers(sniffs)
+				lg.Fatal("failed to resolve tag", log.KV("tag", sniffs[i].PktTag), log.KVErr(err))
+			}
+		}
 	}
 
 	start := time.Now()
@@ -248,6 +263,7 @@ mainLoop:
 type capPacket struct {
 	ts   entry.Timestamp
 	data []byte
+	wlen int //length on the wire
 }
 
 func packetExtractor(hnd *pcap.Handle, c chan []capPacket) {
@@ -284,6 +300,7 @@ func packetExtractor(hnd *pcap.Handle, c chan []capPacket) {
 		}
 		capPkt.data = data
 		capPkt.ts = entry.FromStandard(ci.Timestamp)
+		capPkt.wlen = ci.Length
 		packets = append(packets, capPkt)
 		packetsSize += len(capPkt.data)
 
@@ -317,7 +334,26 @@ func pcapIngester(igst *ingest.IngestMuxer, s *sniffer) {
 	ch := make(chan []capPacket, 1024)
 	go packetExtractor(s.handle, ch)
 	debugout("Starting sniffer %s on %s with \"%s\"\n", s.name, s.Interface, s.BPFFilter)
-	lg.Info("starting sniffer", log.KV("sniffer", s.name), log.KV("interface", s.Interface), log.KV("bpffilter", s.BPFFilter))
+	lg.Info("starting sniffer", log.KV("sniffer", s.name), log.KV("interface", s.Interface), log.KV("bpffilter", s.BPFFilter), log.KV("flowmode", s.flowCfg.Flow_Mode))
+
+	//in flow mode packets feed the tracker and summaries are written as flows end
+	var tr *flows.Tracker
+	var pending []*flows.Flow
+	var expire <-chan time.Time
+	if s.flowCfg.Flow_Mode {
+		var err error
+		if tr, err = flows.NewTracker(s.flowCfg, flowDecoder(s.handle.LinkType()), func(f *flows.Flow) {
+			pending = append(pending, f)
+		}); err != nil {
+			s.handle.Close()
+			lg.Error("failed to create flow tracker", log.KV("sniffer", s.name), log.KVErr(err))
+			s.res <- results{Error: err}
+			return
+		}
+		tckr := time.NewTicker(flowExpireTick)
+		defer tckr.Stop()
+		expire = tckr.C
+	}
 
 mainLoop:
 	for {
@@ -325,7 +361,39 @@ mainLoop:
 		select {
 		case <-s.die:
 			s.handle.Close()
+			if tr != nil {
+				tr.Flush()
+				if set := s.flowEntries(&pending); len(set) > 0 {
+					if err := igst.WriteBatchContext(exitCtx, set); err != nil {
+						lg.Error("failed to write flows at shutdown", log.KVErr(err))
+					}
+					for i := range set {
+						totalBytes += uint64(len(set[i].Data))
+						count++
+					}
+				}
+			}
 			break mainLoop
+		case <-expire:
+			tr.Expire(time.Now())
+			set := s.flowEntries(&pending)
+			if len(set) == 0 {
+				continue
+			}
+			for i := range set {
+				totalBytes += uint64(len(set[i].Data))
+				count++
+			}
+			if err := igst.WriteBatchContext(exitCtx, set); err != nil {
+				s.handle.Close()
+				lg.Error("failed to handle entry", log.KVErr(err))
+				s.res <- results{
+					Bytes: 0,
+					Count: 0,
+					Error: err,
+				}
+				return
+			}
 		case pkts, ok := <-ch: //get a packet
 			if !ok {
 				//Something bad happened, attempt to restart the pcap
@@ -344,16 +412,28 @@ mainLoop:
 				lg.Info("rebuilt packet source")
 				continue
 			}
-			staticSet := make([]entry.Entry, len(pkts))
-			set := make([]*entry.Entry, len(pkts))
-			for i := range pkts {
-				staticSet[i].TS = pkts[i].ts
-				staticSet[i].Data = pkts[i].data
-				staticSet[i].SRC = s.src
-				staticSet[i].Tag = s.tag
-				set[i] = &staticSet[i]
-				totalBytes += uint64(len(pkts[i].data))
-				count++
+			var set []*entry.Entry
+			if tr != nil {
+				set = s.flowSet(tr, pkts, &pending)
+				if len(set) == 0 {
+					continue
+				}
+				for i := range set {
+					totalBytes += uint64(len(set[i].Data))
+					count++
+				}
+			} else {
+				staticSet := make([]entry.Entry, len(pkts))
+				set = make([]*entry.Entry, len(pkts))
+				for i := range pkts {
+					staticSet[i].TS = pkts[i].ts
+					staticSet[i].Data = pkts[i].data
+					staticSet[i].SRC = s.src
+					staticSet[i].Tag = s.tag
+					set[i] = &staticSet[i]
+					totalBytes += uint64(len(pkts[i].data))
+					count++
+				}
 			}
 			if err := igst.WriteBatchContext(exitCtx, set); err != nil {
 				s.handle.Close()
@@ -375,6 +455,50 @@ mainLoop:
 	}
 }
 
+// flowSet feeds packets to the flow tracker, it returns any packets on a Keep-Packet-Port
+// along with the summaries of flows that ended
+func (s *sniffer) flowSet(tr *flows.Tracker, pkts []capPacket, pending *[]*flows.Flow) (set []*entry.Entry) {
+	for i := range pkts {
+		ci := gopacket.CaptureInfo{
+			Timestamp:     pkts[i].ts.StandardTime(),
+			CaptureLength: len(pkts[i].data),
+			Length:        pkts[i].wlen,
+		}
+		if tr.Add(pkts[i].data, ci) {
+			set = append(set, &entry.Entry{
+				TS:   pkts[i].ts,
+				SRC:  s.src,
+				Tag:  s.pktTag,
+				Data: pkts[i].data,
+			})
+		}
+	}
+	return append(set, s.flowEntries(pending)...)
+}
+
+// flowEntries encodes and clears the pending flow summaries
+func (s *sniffer) flowEntries(pending *[]*flows.Flow) (set []*entry.Entry) {
+	for _, f := range *pending {
+		ent, err := f.Entry(s.tag, s.src)
+		if err != nil {
+			lg.Warn("failed to encode flow", log.KV("sniffer", s.name), log.KVErr(err))
+			continue
+		}
+		set = append(set, ent)
+	}
+	*pending = (*pending)[:0]
+	return
+}
+
+// flowDecoder returns the decoder for packets handed over by packetExtractor,
+// SLL captures are trimmed so they decode as ethernet
+func flowDecoder(lt layers.LinkType) gopacket.Decoder {
+	if lt == layers.LinkTypeLinuxSLL {
+		return layers.LinkTypeEthernet
+	}
+	return lt
+}
+
 // Attempt to find a reasonable IP for a given interface name
 // Returns the first IP it finds.
 func getSourceIP(dev string) (net.IP, error) {
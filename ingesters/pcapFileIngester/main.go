@@ -15,6 +15,8 @@ import (
 	"os"
 	"runtime"
 	"runtime/debug"
+	"strconv"
+	"strings"
 	"time"
 
 	// Embed tzdata so that we don't rely on potentially broken timezone DBs on the host
@@ -24,9 +26,11 @@ import (
 	"github.com/gravwell/gravwell/v3/ingest/entry"
 	"github.com/gravwell/gravwell/v3/ingesters/args"
 	"github.com/gravwell/gravwell/v3/ingesters/utils"
+	"github.com/gravwell/gravwell/v3/ingesters/utils/flows"
 	"github.com/gravwell/gravwell/v3/ingesters/version"
 
 	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
 	pcap "github.com/google/gopacket/pcapgo"
 
 	gravwelldebug "github.com/gravwell/gravwell/v3/debug"
@@ -44,9 +48,18 @@ var (
 	srcOvr     = flag.String("source-override", "", "Override source with address, hash, or integer")
 	ver        = flag.Bool("version", false, "Print the version information and exit")
 
-	pktCount uint64
-	pktSize  uint64
-	simulate bool
+	flowMode     = flag.Bool("flow-mode", false, "Ingest flow summaries instead of packets")
+	flowIdle     = flag.String("flow-idle-timeout", "", "Close flows with no packets for this long (default 1m)")
+	flowActive   = flag.String("flow-active-timeout", "", "Emit interim summaries for flows longer than this (default 30m)")
+	flowMax      = flag.Int("flow-max-flows", 0, "Maximum number of tracked flows")
+	keepPktPorts = flag.String("keep-packet-ports", "", "Comma separated TCP/UDP ports whose packets are ingested in flow mode")
+	flowCfg      flows.Config
+
+	pktCount  uint64 // packets read from the capture
+	entCount  uint64 // entries ingested, flow summaries and kept packets in flow mode
+	pktSize   uint64
+	flowCount uint64
+	simulate  bool
 )
 
 func init() {
@@ -64,6 +77,27 @@ func init() {
 	}
 
 	simulate = *simIngest
+
+	flowCfg = flows.Config{
+		Flow_Mode:           *flowMode,
+		Flow_Idle_Timeout:   *flowIdle,
+		Flow_Active_Timeout: *flowActive,
+		Flow_Max_Flows:      *flowMax,
+	}
+	if *keepPktPorts != `` {
+		for _, v := range strings.Split(*keepPktPorts, ",") {
+			p, err := strconv.Atoi(strings.TrimSpace(v))
+			if err != nil {
+				fmt.Printf("Invalid port %q in -keep-packet-ports\n", v)
+				os.Exit(-1)
+			}
+			flowCfg.Keep_Packet_Port = append(flowCfg.Keep_Packet_Port, p)
+		}
+	}
+	if err := flowCfg.Verify(); err != nil {
+		fmt.Printf("Invalid flow configuration: %v\n", err)
+		os.Exit(-1)
+	}
 }
 
 func main() {
@@ -124,7 +158,9 @@ func main() {
 			select {
 			case err, ok := <-errChan:
 				if !ok {
-					break mainLoop
+					//reader is done, keep draining entries until entChan closes
+					errChan = nil
+					continue
 				}
 				fmt.Fprintf(os.Stderr, "ERROR: %v\n", err)
 				break mainLoop
@@ -133,7 +169,7 @@ func main() {
 					break mainLoop
 				}
 				for i := range blk {
-					pktCount++
+					entCount++
 					pktSize += uint64(len(blk[i].Data))
 				}
 				if err := igst.WriteBatch(blk); err != nil {
@@ -156,7 +192,11 @@ func main() {
 	dur := time.Since(start)
 	fmt.Printf("Completed in %v (%s)\n", dur, ingest.HumanSize(pktSize))
 	fmt.Printf("Total Count: %s\n", ingest.HumanCount(pktCount))
-	fmt.Printf("Entry Rate: %s\n", ingest.HumanEntryRate(pktCount, dur))
+	if flowCfg.Flow_Mode {
+		fmt.Printf("Flow Count: %s\n", ingest.HumanCount(flowCount))
+		fmt.Printf("Entry Count: %s\n", ingest.HumanCount(entCount))
+	}
+	fmt.Printf("Entry Rate: %s\n", ingest.HumanEntryRate(entCount, dur))
 	fmt.Printf("Ingest Rate: %s\n", ingest.HumanRate(pktSize, dur))
 }
 
@@ -180,6 +220,20 @@ func readPackets(hnd *packetHandle, igst *ingest.IngestMuxer, tag entry.EntryTag
 	var ci gopacket.CaptureInfo
 	var blk []*entry.Entry
 
+	var tr *flows.Tracker
+	if flowCfg.Flow_Mode {
+		if tr, err = flows.NewTracker(flowCfg, hnd.LinkType(), func(f *flows.Flow) {
+			if ent, err := f.Entry(tag, src); err == nil {
+				blk = append(blk, ent)
+				lSize += uint64(len(ent.Data))
+				flowCount++
+			}
+		}); err != nil {
+			errChan <- err
+			return
+		}
+	}
+
 	//get packet src
 	for {
 		if dt, ci, err = hnd.ReadPacketData(); err != nil {
@@ -189,6 +243,7 @@ func readPackets(hnd *packetHandle, igst *ingest.IngestMuxer, tag entry.EntryTag
 			break
 		}
 		ts = entry.FromStandard(ci.Timestamp)
+		pktCount++
 		if !first {
 			first = true
 			base = ts
@@ -210,6 +265,18 @@ func readPackets(hnd *packetHandle, igst *ingest.IngestMuxer, tag entry.EntryTag
 				lSize = 0
 			}
 		}
+		if tr != nil {
+			//flows are expired on packet time so the capture is summarized as it would have been live
+			if sec != ts.Sec {
+				tr.Expire(ts.StandardTime())
+			}
+			ci.Timestamp = ts.StandardTime()
+			keep := tr.Add(dt, ci)
+			sec = ts.Sec
+			if !keep {
+				continue
+			}
+		}
 		blk = append(blk, &entry.Entry{
 			TS:   ts,
 			SRC:  src,
@@ -219,6 +286,9 @@ func readPackets(hnd *packetHandle, igst *ingest.IngestMuxer, tag entry.EntryTag
 		lSize += uint64(len(dt))
 		sec = ts.Sec
 	}
+	if tr != nil {
+		tr.Flush()
+	}
 	if len(blk) > 0 {
 		entChan <- blk
 	}
@@ -238,6 +308,15 @@ func simulatePacketRead(hnd *packetHandle) (err error) {
 	//set the bpf filter
 	var dt []byte
 	var ci gopacket.CaptureInfo
+	var tr *flows.Tracker
+	if flowCfg.Flow_Mode {
+		if tr, err = flows.NewTracker(flowCfg, hnd.LinkType(), func(*flows.Flow) {
+			flowCount++
+			entCount++
+		}); err != nil {
+			return
+		}
+	}
 	for {
 		if dt, ci, err = hnd.ReadPacketData(); err != nil {
 			if err == io.EOF {
@@ -246,6 +325,7 @@ func simulatePacketRead(hnd *packetHandle) (err error) {
 			break
 		}
 		ts = entry.FromStandard(ci.Timestamp)
+		pktCount++
 		if !first {
 			first = true
 			base = ts
@@ -259,8 +339,17 @@ func simulatePacketRead(hnd *packetHandle) (err error) {
 		if *tsOverride {
 			ts = ts.Add(diff)
 		}
+		if tr != nil {
+			ci.Timestamp = ts.StandardTime()
+			if !tr.Add(dt, ci) {
+				continue
+			}
+		}
 		pktSize += uint64(len(dt))
-		pktCount++
+		entCount++
+	}
+	if tr != nil {
+		tr.Flush()
 	}
 	fmt.Println("Last packet at", ts.Format(time.RFC3339))
 	return
@@ -312,6 +401,13 @@ func (ph *packetHandle) Close() error {
 	return ph.fi.Close()
 }
 
+func (ph *packetHandle) LinkType() layers.LinkType {
+	if ph.ngMode {
+		return ph.nghnd.LinkType()
+	}
+	return ph.hnd.LinkType()
+}
+
 func (ph *packetHandle) ReadPacketData() (data []byte, ci gopacket.CaptureInfo, err error) {
 	if ph.ngMode {
 		data, ci, err = ph.nghnd.ReadPacketData()
@@ -48,6 +48,7 @@ var (
 	igTs         = flag.Bool("ignore-ts", false, "Ignore the timestamp")
 	custTsRegex  = flag.String("cust-ts-regex", "", "Regular expression for custom timestamp")
 	custTsFormat = flag.String("cust-ts-format", "", "Date format for custom timestamp")
+	custTsStyle  = flag.String("cust-ts-format-style", "", "Pattern style of the custom timestamp format: go, strptime, java, or joda")
 
 	count      uint64
 	totalBytes uint64
@@ -98,9 +99,10 @@ func main() {
 
 	if *custTsRegex != "" || *custTsFormat != "" {
 		custTs = timegrinder.CustomFormat{
-			Name:   "custom",
-			Regex:  *custTsRegex,
-			Format: *custTsFormat,
+			Name:         "custom",
+			Regex:        *custTsRegex,
+			Format:       *custTsFormat,
+			Format_Style: *custTsStyle,
 		}
 		if err = custTs.Validate(); err != nil {
 			log.Fatalf("Invalid custom timestamp formats: %v", err)
@@ -20,6 +20,7 @@ import (
 	"github.com/gravwell/gravwell/v3/ingest/config"
 	"github.com/gravwell/gravwell/v3/ingest/entry"
 	"github.com/gravwell/gravwell/v3/ingest/processors"
+	"github.com/gravwell/gravwell/v3/ingesters/utils/deadletter"
 	"github.com/gravwell/gravwell/v3/sqs_common"
 	"github.com/gravwell/gravwell/v3/timegrinder"
 )
@@ -48,6 +49,7 @@ type bucket struct {
 
 type sqsS3 struct {
 	TimeConfig
+	deadletter.Config
 	Reader           string //defaults to line
 	Tag_Name         string
 	Queue_URL        string
@@ -60,6 +62,8 @@ type sqsS3 struct {
 	Max_Line_Size    int
 	Source_Override  string
 	Attach_Metadata  bool
+
+	Dead_Letter_Queue string // queue URL that exhausted notifications are forwarded to
 }
 
 type global struct {
@@ -209,6 +213,9 @@ func (c *cfgType) Verify() error {
 		if _, err := sqs_common.GetCredentials(v.Credentials_Type, v.ID, v.Secret); err != nil {
 			return err
 		}
+		if err := v.Config.Verify(v.Dead_Letter_Queue != ``); err != nil {
+			return fmt.Errorf("SQS-S3-Listener %s: %w", k, err)
+		}
 	}
 
 	return nil
@@ -229,12 +236,14 @@ func (c *cfgType) Tags() ([]string, error) {
 	}
 
 	for _, v := range c.SQS_S3_Listener {
-		if len(v.Tag_Name) == 0 {
-			continue
-		}
-		if _, ok := tagMp[v.Tag_Name]; !ok {
-			tags = append(tags, v.Tag_Name)
-			tagMp[v.Tag_Name] = true
+		for _, tag := range []string{v.Tag_Name, v.Dead_Letter_Tag} {
+			if len(tag) == 0 {
+				continue
+			}
+			if _, ok := tagMp[tag]; !ok {
+				tags = append(tags, tag)
+				tagMp[tag] = true
+			}
 		}
 	}
 
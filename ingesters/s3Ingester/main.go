@@ -24,6 +24,7 @@ import (
 	"github.com/gravwell/gravwell/v3/ingest/log"
 	"github.com/gravwell/gravwell/v3/ingesters/base"
 	"github.com/gravwell/gravwell/v3/ingesters/utils"
+	"github.com/gravwell/gravwell/v3/ingesters/utils/deadletter"
 )
 
 const (
@@ -155,6 +156,9 @@ func main() {
 
 		if b, err := NewSQSS3Listener(scfg); err != nil {
 			ib.Logger.FatalCode(0, "failed to create SQS S3 Listener", log.KVErr(err))
+		} else if b.dl, err = deadletter.New(v.Config, igst, b.sqs.DeadLetterQueue(v.Dead_Letter_Queue)); err != nil {
+			ib.Logger.FatalCode(0, "failed to create SQS S3 Listener dead letter",
+				log.KV("bucket", k), log.KVErr(err))
 		} else {
 			sqsS3 = append(sqsS3, b)
 		}
@@ -114,6 +114,9 @@ func (s *SQSS3Listener) worker(ctx context.Context, lg *log.Logger, wg *sync.Wai
 				buckets, keys, err = s3Decode(msg)
 				if err != nil {
 					lg.Warn("error decoding message", log.KVErr(err))
+					if s.deadLetter(ctx, lg, m, err) {
+						deleteQueue = append(deleteQueue, m)
+					}
 					continue
 				} else {
 					logSnsKeyDecode(lg, "S3", buckets, keys)
@@ -132,6 +135,7 @@ func (s *SQSS3Listener) worker(ctx context.Context, lg *log.Logger, wg *sync.Wai
 			}
 
 			shouldDelete := true
+			var perrs []error
 			for i, x := range keys {
 				// should we bother with this key?
 				if !s.filter.match(x) {
@@ -155,6 +159,7 @@ func (s *SQSS3Listener) worker(ctx context.Context, lg *log.Logger, wg *sync.Wai
 				sz, s3rtt, rtt, err = ProcessContext(obj, ctx, s.svc, buckets[i], s.rdr, s.TG, s.src, s.Tag, s.Proc, s.MaxLineSize, s.AttachMetadata)
 				if err != nil {
 					shouldDelete = false
+					perrs = append(perrs, fmt.Errorf("bucket %s key %s: %w", buckets[i], x, err))
 					lg.Error("error processing message", log.KV("bucket", buckets[i]), log.KV("key", x), log.KVErr(err))
 				} else {
 					lg.Info("successfully processed message",
@@ -167,7 +172,7 @@ func (s *SQSS3Listener) worker(ctx context.Context, lg *log.Logger, wg *sync.Wai
 				}
 			}
 
-			if shouldDelete {
+			if shouldDelete || s.deadLetter(ctx, lg, m, errors.Join(perrs...)) {
 				deleteQueue = append(deleteQueue, m)
 			}
 		}
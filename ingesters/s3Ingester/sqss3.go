@@ -1,15 +1,18 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"net"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/sqs"
 	"github.com/gravwell/gravwell/v3/ingest/entry"
 	"github.com/gravwell/gravwell/v3/ingest/log"
 	"github.com/gravwell/gravwell/v3/ingest/processors"
+	"github.com/gravwell/gravwell/v3/ingesters/utils/deadletter"
 	"github.com/gravwell/gravwell/v3/sqs_common"
 	"github.com/gravwell/gravwell/v3/timegrinder"
 )
@@ -44,6 +47,7 @@ type SQSS3Listener struct {
 	src     net.IP
 	rdr     reader
 	filter  *matcher
+	dl      *deadletter.DeadLetter
 }
 
 func NewSQSS3Listener(cfg SQSS3Config) (s *SQSS3Listener, err error) {
@@ -113,3 +117,18 @@ func (s SQSS3Config) Log(vals ...interface{}) {
 	}
 	s.Logger.Info(fmt.Sprint(vals...))
 }
+
+// deadLetter hands a notification that could not be handled to the dead letter destinations once it
+// has used up its receives, returning true if it was dead lettered and should be deleted from the queue
+func (s *SQSS3Listener) deadLetter(ctx context.Context, lg *log.Logger, m *sqs.Message, perr error) bool {
+	rc := sqs_common.ReceiveCount(m)
+	if ctx.Err() != nil || !s.dl.Exhausted(rc) {
+		return false
+	}
+	if err := s.dl.Send(ctx, s.sqs.Letter(m, perr)); err != nil {
+		lg.Error("failed to dead letter message", log.KV("name", s.Name), log.KV("receives", rc), log.KVErr(err))
+		return false
+	}
+	lg.Warn("dead lettered message", log.KV("name", s.Name), log.KV("receives", rc), log.KV("reason", perr))
+	return true
+}
@@ -302,6 +302,7 @@ func ProcessContext(obj *s3.Object, ctx context.Context, svc *s3.S3, bucket stri
 		err = fmt.Errorf("failed to create transparent decompression reader: %w", err)
 		return
 	}
+	defer utils.CloseCompressedReader(smartReader)
 
 	switch rdr {
 	case lineReader:
@@ -326,9 +327,11 @@ func processLinesContext(ctx context.Context, rdr io.Reader, maxLineSize int, tg
 		if len(bts) == 0 {
 			continue
 		}
-		ts, ok, _ := tg.Extract(bts)
-		if !ok {
-			ts = time.Now()
+		ts := time.Now()
+		if tg != nil {
+			if t, ok, _ := tg.Extract(bts); ok {
+				ts = t
+			}
 		}
 		ent := entry.Entry{
 			TS:   entry.FromStandard(ts),
@@ -372,9 +375,11 @@ func processCloudtrailContext(ctx context.Context, rdr io.Reader, tg *timegrinde
 		} else {
 			bts = val
 		}
-		ts, ok, _ := tg.Extract(bts)
-		if !ok {
-			ts = time.Now()
+		ts := time.Now()
+		if tg != nil {
+			if t, ok, _ := tg.Extract(bts); ok {
+				ts = t
+			}
 		}
 		ent := entry.Entry{
 			TS:   entry.FromStandard(ts),
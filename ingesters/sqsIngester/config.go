@@ -19,6 +19,7 @@ import (
 	"github.com/gravwell/gravwell/v3/ingest/config"
 	"github.com/gravwell/gravwell/v3/ingest/entry"
 	"github.com/gravwell/gravwell/v3/ingest/processors"
+	"github.com/gravwell/gravwell/v3/ingesters/utils/deadletter"
 	"github.com/gravwell/gravwell/v3/sqs_common"
 )
 
@@ -28,13 +29,17 @@ const (
 
 type queue struct {
 	baseConfig
+	deadletter.Config
 	Tag_Name         string
 	Queue_URL        string
 	Region           string
+	Endpoint         string // optional, for SQS compatible services
 	Credentials_Type string
 	AKID             string
 	Secret           string `json:"-"` // DO NOT send this when marshalling
 	Preprocessor     []string
+
+	Dead_Letter_Queue string // queue URL that exhausted messages are forwarded to
 }
 
 type baseConfig struct {
@@ -126,6 +131,9 @@ func (c *cfgType) Verify() error {
 		if _, err := sqs_common.GetCredentials(v.Credentials_Type, v.AKID, v.Secret); err != nil {
 			return err
 		}
+		if err := v.Config.Verify(v.Dead_Letter_Queue != ``); err != nil {
+			return fmt.Errorf("Queue %s: %w", k, err)
+		}
 	}
 
 	return nil
@@ -136,12 +144,14 @@ func (c *cfgType) Tags() ([]string, error) {
 	tagMp := make(map[string]bool, 1)
 
 	for _, v := range c.Queue {
-		if len(v.Tag_Name) == 0 {
-			continue
-		}
-		if _, ok := tagMp[v.Tag_Name]; !ok {
-			tags = append(tags, v.Tag_Name)
-			tagMp[v.Tag_Name] = true
+		for _, tag := range []string{v.Tag_Name, v.Dead_Letter_Tag} {
+			if len(tag) == 0 {
+				continue
+			}
+			if _, ok := tagMp[tag]; !ok {
+				tags = append(tags, tag)
+				tagMp[tag] = true
+			}
 		}
 	}
 
@@ -26,6 +26,7 @@ import (
 	"github.com/gravwell/gravwell/v3/ingest/processors"
 	"github.com/gravwell/gravwell/v3/ingesters/base"
 	"github.com/gravwell/gravwell/v3/ingesters/utils"
+	"github.com/gravwell/gravwell/v3/ingesters/utils/deadletter"
 	"github.com/gravwell/gravwell/v3/sqs_common"
 
 	"github.com/aws/aws-sdk-go/service/sqs"
@@ -55,6 +56,7 @@ type handlerConfig struct {
 	wg               *sync.WaitGroup
 	done             chan bool
 	proc             *processors.ProcessorSet
+	dl               *deadletter.DeadLetter
 	ctx              context.Context
 }
 
@@ -137,6 +139,7 @@ func main() {
 		s, err := sqs_common.SQSListener(&sqs_common.Config{
 			Queue:       v.Queue_URL,
 			Region:      v.Region,
+			Endpoint:    v.Endpoint,
 			Credentials: c,
 		})
 
@@ -146,6 +149,10 @@ func main() {
 
 		hcfg.SQS = s
 
+		if hcfg.dl, err = deadletter.New(v.Config, igst, s.DeadLetterQueue(v.Dead_Letter_Queue)); err != nil {
+			lg.Fatal("dead letter failure", log.KV("listener", k), log.KVErr(err))
+		}
+
 		if hcfg.proc, err = cfg.Preprocessor.ProcessorSet(igst, v.Preprocessor); err != nil {
 			lg.Fatal("preprocessor failure", log.KVErr(err))
 		}
@@ -239,19 +246,34 @@ func queueRunner(hcfg *handlerConfig) {
 				Data: msg,
 			}
 
-			err := hcfg.proc.ProcessContext(ent, hcfg.ctx)
-			if err != nil {
+			if err := hcfg.proc.ProcessContext(ent, hcfg.ctx); err != nil {
 				lg.Error("failed to ingest entry", log.KVErr(err))
-			} else {
-				err = hcfg.SQS.DeleteMessages([]*sqs.Message{v}, lg)
-				if err != nil {
-					lg.Error("failed to delete message", log.KVErr(err))
+				if !deadLetter(hcfg, v, err) {
+					continue
 				}
 			}
+			if err := hcfg.SQS.DeleteMessages([]*sqs.Message{v}, lg); err != nil {
+				lg.Error("failed to delete message", log.KVErr(err))
+			}
 		}
 	}
 }
 
+// deadLetter forwards a message that failed processing once it has used up its receives,
+// returning true if the message was dead lettered and should be removed from the queue
+func deadLetter(hcfg *handlerConfig, m *sqs.Message, perr error) bool {
+	rc := sqs_common.ReceiveCount(m)
+	if hcfg.ctx.Err() != nil || !hcfg.dl.Exhausted(rc) {
+		return false
+	}
+	if err := hcfg.dl.Send(hcfg.ctx, hcfg.SQS.Letter(m, perr)); err != nil {
+		lg.Error("failed to dead letter message", log.KV("receives", rc), log.KVErr(err))
+		return false
+	}
+	lg.Warn("dead lettered message", log.KV("receives", rc), log.KV("reason", perr))
+	return true
+}
+
 func sleepContext(ctx context.Context, d time.Duration) error {
 	select {
 	case <-ctx.Done():
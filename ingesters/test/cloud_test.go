@@ -0,0 +1,286 @@
+/*************************************************************************
+ * Copyright 2026 Gravwell, Inc. All rights reserved.
+ * Contact: <legal@gravwell.io>
+ *
+ * This software may be modified and distributed under the terms of the
+ * BSD 2-clause license. See the LICENSE file for details.
+ **************************************************************************/
+
+package test
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gravwell/gravwell/v3/ingest"
+	"github.com/gravwell/gravwell/v3/ingesters/test/harness"
+)
+
+const (
+	entryTimeout = 30 * time.Second
+)
+
+var (
+	baseTime = time.Date(2025, time.March, 4, 5, 6, 7, 0, time.UTC)
+)
+
+// integration builds the ingester and spins up an indexer, long running so skipped in short mode
+func integration(t *testing.T, pkg string) (bin string, idx *harness.Indexer) {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("skipping ingester integration test in short mode")
+	}
+	bin = harness.Build(t, pkg)
+	idx = harness.NewIndexer(t)
+	return
+}
+
+func checkEntries(t *testing.T, ents []harness.Entry, tag string, want map[string]time.Time) {
+	t.Helper()
+	if len(ents) != len(want) {
+		t.Fatalf("got %d entries, expected %d: %v", len(ents), len(want), harness.SortedData(ents))
+	}
+	for _, ent := range ents {
+		ts, ok := want[ent.Data]
+		if !ok {
+			t.Fatalf("unexpected entry %q", ent.Data)
+		} else if ent.Tag != tag {
+			t.Fatalf("entry %q has tag %q, expected %q", ent.Data, ent.Tag, tag)
+		} else if !ts.IsZero() && !ent.TS.Equal(ts) {
+			t.Fatalf("entry %q has timestamp %v, expected %v", ent.Data, ent.TS, ts)
+		}
+		delete(want, ent.Data)
+	}
+}
+
+func TestS3(t *testing.T) {
+	bin, idx := integration(t, "ingesters/s3Ingester")
+	s3 := harness.NewFakeS3(t)
+	s3.PutObject("logs", "a.log", []byte("line one\nline two\n"), baseTime)
+	s3.PutObject("logs", "dir/b.log", []byte("line three\n"), baseTime)
+
+	dir := t.TempDir()
+	cfg := harness.GlobalConfig(idx, dir, fmt.Sprintf("State-Store-Location=%s/s3.state", dir))
+	cfg += fmt.Sprintf(`
+[Bucket "test"]
+	Endpoint=%q
+	Bucket-Name=logs
+	S3-Force-Path-Style=true
+	Region=%q
+	ID=%q
+	Secret=%q
+	Tag-Name=s3
+	Ignore-Timestamps=true
+`, s3.URL(), harness.AWSRegion, harness.AWSKeyID, harness.AWSSecret)
+
+	ig := harness.Start(t, bin, dir, cfg, nil)
+	ents, err := idx.WaitForEntries("s3", 3, entryTimeout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkEntries(t, ents, "s3", map[string]time.Time{
+		"line one":   {},
+		"line two":   {},
+		"line three": {},
+	})
+	if err := ig.Stop(); err != nil {
+		t.Fatalf("ingester exited with error: %v", err)
+	}
+
+	// a restart must not pull objects that are already tracked in the state file
+	ig = harness.Start(t, bin, dir, cfg, nil)
+	time.Sleep(3 * time.Second)
+	if err := ig.Stop(); err != nil {
+		t.Fatalf("ingester exited with error: %v", err)
+	}
+	if n := len(idx.TagEntries("s3")); n != 3 {
+		t.Fatalf("restarted ingester reprocessed objects, got %d entries", n)
+	}
+	if n := s3.Gets("logs", "a.log"); n != 1 {
+		t.Fatalf("object fetched %d times, expected 1", n)
+	}
+}
+
+func TestSQS(t *testing.T) {
+	bin, idx := integration(t, "ingesters/sqsIngester")
+	sqs := harness.NewFakeSQS(t)
+	want := map[string]time.Time{}
+	for i := 0; i < 5; i++ {
+		msg := fmt.Sprintf("sqs message %d", i)
+		ts := baseTime.Add(time.Duration(i) * time.Second)
+		sqs.SendMessage("events", msg, ts)
+		want[msg] = ts
+	}
+
+	dir := t.TempDir()
+	cfg := harness.GlobalConfig(idx, dir)
+	cfg += fmt.Sprintf(`
+[Queue "test"]
+	Endpoint=%q
+	Queue-URL=%q
+	Region=%q
+	AKID=%q
+	Secret=%q
+	Tag-Name=sqs
+`, sqs.URL(), sqs.QueueURL("events"), harness.AWSRegion, harness.AWSKeyID, harness.AWSSecret)
+
+	harness.Start(t, bin, dir, cfg, nil)
+	ents, err := idx.WaitForEntries("sqs", len(want), entryTimeout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkEntries(t, ents, "sqs", want)
+	if err := harness.WaitFor(entryTimeout, func() bool { return sqs.Remaining("events") == 0 }); err != nil {
+		t.Fatalf("ingested messages were not deleted, %d remain", sqs.Remaining("events"))
+	}
+}
+
+func TestKinesis(t *testing.T) {
+	bin, idx := integration(t, "ingesters/KinesisIngester")
+	kin := harness.NewFakeKinesis(t)
+
+	// the parent shard has been split, its records must all arrive before any of the child's
+	kin.AddShard("stream", "shardId-000000000000")
+	kin.AddShard("stream", "shardId-000000000001", "shardId-000000000000")
+	want := map[string]time.Time{}
+	var order []string
+	put := func(shard, msg string, ts time.Time) {
+		kin.PutRecord("stream", shard, []byte(msg), ts)
+		want[msg] = ts
+		order = append(order, msg)
+	}
+	for i := 0; i < 3; i++ {
+		put("shardId-000000000000", fmt.Sprintf("parent %d", i), baseTime.Add(time.Duration(i)*time.Second))
+	}
+	kin.CloseShard("stream", "shardId-000000000000")
+	for i := 0; i < 3; i++ {
+		put("shardId-000000000001", fmt.Sprintf("child %d", i), baseTime.Add(time.Minute+time.Duration(i)*time.Second))
+	}
+
+	dir := t.TempDir()
+	cfg := harness.GlobalConfig(idx, dir,
+		fmt.Sprintf("State-Store-Location=%s/kinesis.state", dir),
+		"Credentials-Type=static",
+		fmt.Sprintf("AWS-Access-Key-ID=%q", harness.AWSKeyID),
+		fmt.Sprintf("AWS-Secret-Access-Key=%q", harness.AWSSecret))
+	cfg += fmt.Sprintf(`
+[KinesisStream "test"]
+	Stream-Name=stream
+	Endpoint=%q
+	Region=%q
+	Tag-Name=kinesis
+	Iterator-Type=TRIM_HORIZON
+`, kin.URL(), harness.AWSRegion)
+
+	ig := harness.Start(t, bin, dir, cfg, nil)
+	ents, err := idx.WaitForEntries("kinesis", len(order), entryTimeout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, e := range ents {
+		got = append(got, e.Data)
+	}
+	if !reflect.DeepEqual(got, order) {
+		t.Fatalf("entries out of order:\n%v\nexpected\n%v", got, order)
+	}
+	checkEntries(t, ents, "kinesis", want)
+
+	// lag is reported through the ingester state metadata
+	if _, err := idx.WaitForState(entryTimeout, func(st ingest.IngesterState) bool {
+		return len(st.Metadata) > 0
+	}); err != nil {
+		t.Fatalf("no lag metadata reported: %v", err)
+	}
+	if err := ig.Stop(); err != nil {
+		t.Fatalf("ingester exited with error: %v", err)
+	}
+
+	// restarting resumes from the checkpoint, only new records are ingested
+	kin.PutRecord("stream", "shardId-000000000001", []byte("child 3"), baseTime.Add(2*time.Minute))
+	harness.Start(t, bin, dir, cfg, nil)
+	if ents, err = idx.WaitForEntries("kinesis", len(order)+1, entryTimeout); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(2 * time.Second)
+	if ents = idx.TagEntries("kinesis"); len(ents) != len(order)+1 || ents[len(ents)-1].Data != "child 3" {
+		t.Fatalf("restart did not resume from checkpoint: %v", harness.SortedData(ents))
+	}
+	var resumed bool
+	for _, ir := range kin.IteratorRequests() {
+		if ir.Shard == "shardId-000000000000" && ir.Type != "TRIM_HORIZON" {
+			t.Fatalf("finished parent shard was read again with %s", ir.Type)
+		} else if ir.Shard == "shardId-000000000001" && ir.Type == "AFTER_SEQUENCE_NUMBER" {
+			resumed = true
+		}
+	}
+	if !resumed {
+		t.Fatalf("child shard was not resumed from its sequence number: %v", kin.IteratorRequests())
+	}
+}
+
+func TestPubSub(t *testing.T) {
+	bin, idx := integration(t, "ingesters/GooglePubSubIngester")
+	ps := harness.NewFakePubSub(t, "testproject")
+	if err := ps.CreateTopic("events"); err != nil {
+		t.Fatal(err)
+	} else if err = ps.CreateSubscription("events", "gravwell"); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]time.Time{}
+	for i := 0; i < 5; i++ {
+		msg := fmt.Sprintf("pubsub message %d", i)
+		want[msg] = ps.Publish("events", []byte(msg))
+	}
+
+	dir := t.TempDir()
+	cfg := harness.GlobalConfig(idx, dir, "Project-ID=testproject")
+	cfg += `
+[PubSub "test"]
+	Topic-Name=events
+	Subscription-Name=gravwell
+	Tag-Name=pubsub
+`
+	harness.Start(t, bin, dir, cfg, ps.Env())
+	ents, err := idx.WaitForEntries("pubsub", len(want), entryTimeout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkEntries(t, ents, "pubsub", want)
+	if err := harness.WaitFor(entryTimeout, func() bool { return ps.Acked() == 5 }); err != nil {
+		t.Fatalf("ingested messages were not acknowledged, %d acked", ps.Acked())
+	}
+}
+
+func TestKafka(t *testing.T) {
+	bin, idx := integration(t, "ingesters/kafka_consumer")
+	kafka := harness.NewFakeKafka(t, "events", "gravwell")
+	want := map[string]time.Time{}
+	for i := 0; i < 5; i++ {
+		msg := fmt.Sprintf("kafka message %d", i)
+		kafka.Produce(msg)
+		want[msg] = time.Time{}
+	}
+
+	dir := t.TempDir()
+	cfg := harness.GlobalConfig(idx, dir)
+	cfg += fmt.Sprintf(`
+[Consumer "test"]
+	Leader=%q
+	Topic=events
+	Default-Tag=kafka
+	Tags=*
+`, kafka.Addr())
+
+	harness.Start(t, bin, dir, cfg, nil)
+	ents, err := idx.WaitForEntries("kafka", len(want), entryTimeout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkEntries(t, ents, "kafka", want)
+	if err := harness.WaitFor(entryTimeout, func() bool { return kafka.Commits() > 0 }); err != nil {
+		t.Fatal("consumer group never committed offsets")
+	}
+}
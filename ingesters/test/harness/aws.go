@@ -0,0 +1,470 @@
+/*************************************************************************
+ * Copyright 2026 Gravwell, Inc. All rights reserved.
+ * Contact: <legal@gravwell.io>
+ *
+ * This software may be modified and distributed under the terms of the
+ * BSD 2-clause license. See the LICENSE file for details.
+ **************************************************************************/
+
+package harness
+
+import (
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// AWS style static credentials; the fakes do not check signatures
+const (
+	AWSKeyID  = `AKIDHARNESS`
+	AWSSecret = `harness-secret`
+	AWSRegion = `us-east-1`
+)
+
+// awsError is the error body used by the AWS JSON protocols
+type awsError struct {
+	Type    string `json:"__type"`
+	Message string `json:"message"`
+}
+
+// jsonOperation handles one X-Amz-Target operation, a non-empty error code produces a 400 response
+type jsonOperation func(body []byte) (resp interface{}, code string, err error)
+
+// newAWSJSONServer serves the AWS JSON protocol, dispatching on the X-Amz-Target header
+func newAWSJSONServer(t testing.TB, prefix, contentType string, ops map[string]jsonOperation) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		target := strings.TrimPrefix(r.Header.Get("X-Amz-Target"), prefix+".")
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("X-Amzn-Requestid", strconv.FormatInt(time.Now().UnixNano(), 10))
+		op, ok := ops[target]
+		if !ok {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(awsError{Type: "UnknownOperationException", Message: target})
+			return
+		}
+		resp, code, err := op(body)
+		if code != `` {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(awsError{Type: code, Message: fmt.Sprint(err)})
+			return
+		} else if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(awsError{Type: "InternalFailure", Message: err.Error()})
+			return
+		}
+		json.NewEncoder(w).Encode(resp)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// epoch encodes a time the way the AWS JSON protocol does
+func epoch(t time.Time) float64 {
+	return float64(t.UnixNano()) / 1e9
+}
+
+/******************************************************************
+ * Kinesis
+ ******************************************************************/
+
+type kinesisRecord struct {
+	seq     string
+	data    []byte
+	arrival time.Time
+}
+
+type kinesisShard struct {
+	id      string
+	parents []string
+	closed  bool
+	records []kinesisRecord
+}
+
+// IteratorRequest records a GetShardIterator call
+type IteratorRequest struct {
+	Shard string
+	Type  string
+}
+
+// FakeKinesis implements DescribeStream, GetShardIterator, and GetRecords for in memory streams
+type FakeKinesis struct {
+	srv *httptest.Server
+
+	mtx       sync.Mutex
+	streams   map[string][]*kinesisShard
+	iterators []IteratorRequest
+}
+
+// NewFakeKinesis starts a fake Kinesis endpoint, it is closed when the test ends
+func NewFakeKinesis(t testing.TB) *FakeKinesis {
+	fk := &FakeKinesis{streams: map[string][]*kinesisShard{}}
+	fk.srv = newAWSJSONServer(t, "Kinesis_20131202", "application/x-amz-json-1.1", map[string]jsonOperation{
+		"DescribeStream":   fk.describeStream,
+		"GetShardIterator": fk.getShardIterator,
+		"GetRecords":       fk.getRecords,
+	})
+	return fk
+}
+
+// URL is the endpoint to configure in the ingester
+func (fk *FakeKinesis) URL() string {
+	return fk.srv.URL
+}
+
+// AddShard adds an open shard to a stream, creating the stream if needed.
+// Parents are the shards this one was split or merged from.
+func (fk *FakeKinesis) AddShard(stream, id string, parents ...string) {
+	fk.mtx.Lock()
+	defer fk.mtx.Unlock()
+	fk.streams[stream] = append(fk.streams[stream], &kinesisShard{id: id, parents: parents})
+}
+
+// CloseShard marks a shard closed, as Kinesis does to the parents of a split or merge
+func (fk *FakeKinesis) CloseShard(stream, id string) {
+	fk.mtx.Lock()
+	defer fk.mtx.Unlock()
+	if s := fk.shardLocked(stream, id); s != nil {
+		s.closed = true
+	}
+}
+
+// PutRecord appends a record to a shard and returns its sequence number
+func (fk *FakeKinesis) PutRecord(stream, shard string, data []byte, arrival time.Time) string {
+	fk.mtx.Lock()
+	defer fk.mtx.Unlock()
+	s := fk.shardLocked(stream, shard)
+	if s == nil {
+		panic("unknown shard " + shard)
+	}
+	seq := fmt.Sprintf("%s-%012d", strings.TrimPrefix(s.id, "shardId-"), len(s.records))
+	s.records = append(s.records, kinesisRecord{seq: seq, data: data, arrival: arrival})
+	return seq
+}
+
+// IteratorRequests returns every GetShardIterator call made so far
+func (fk *FakeKinesis) IteratorRequests() []IteratorRequest {
+	fk.mtx.Lock()
+	defer fk.mtx.Unlock()
+	return append([]IteratorRequest(nil), fk.iterators...)
+}
+
+func (fk *FakeKinesis) shardLocked(stream, id string) *kinesisShard {
+	for _, s := range fk.streams[stream] {
+		if s.id == id {
+			return s
+		}
+	}
+	return nil
+}
+
+func (fk *FakeKinesis) describeStream(body []byte) (interface{}, string, error) {
+	var req struct {
+		StreamName string
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		return nil, "SerializationException", err
+	}
+	fk.mtx.Lock()
+	defer fk.mtx.Unlock()
+	shards, ok := fk.streams[req.StreamName]
+	if !ok {
+		return nil, "ResourceNotFoundException", fmt.Errorf("stream %s not found", req.StreamName)
+	}
+	var out []map[string]interface{}
+	for _, s := range shards {
+		snr := map[string]interface{}{"StartingSequenceNumber": "0"}
+		if s.closed {
+			snr["EndingSequenceNumber"] = "99999999999999"
+		}
+		sd := map[string]interface{}{
+			"ShardId":             s.id,
+			"HashKeyRange":        map[string]string{"StartingHashKey": "0", "EndingHashKey": "340282366920938463463374607431768211455"},
+			"SequenceNumberRange": snr,
+		}
+		if len(s.parents) > 0 {
+			sd["ParentShardId"] = s.parents[0]
+		}
+		if len(s.parents) > 1 {
+			sd["AdjacentParentShardId"] = s.parents[1]
+		}
+		out = append(out, sd)
+	}
+	return map[string]interface{}{
+		"StreamDescription": map[string]interface{}{
+			"StreamName":              req.StreamName,
+			"StreamARN":               "arn:aws:kinesis:" + AWSRegion + ":000000000000:stream/" + req.StreamName,
+			"StreamStatus":            "ACTIVE",
+			"Shards":                  out,
+			"HasMoreShards":           false,
+			"RetentionPeriodHours":    24,
+			"StreamCreationTimestamp": epoch(time.Now()),
+			"EnhancedMonitoring":      []interface{}{},
+		},
+	}, ``, nil
+}
+
+// iterators are simply the stream, shard, and position in the shard
+func encodeIterator(stream, shard string, pos int) string {
+	return base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("%s\x00%s\x00%d", stream, shard, pos)))
+}
+
+func decodeIterator(v string) (stream, shard string, pos int, err error) {
+	var b []byte
+	if b, err = base64.StdEncoding.DecodeString(v); err != nil {
+		return
+	}
+	parts := strings.Split(string(b), "\x00")
+	if len(parts) != 3 {
+		err = fmt.Errorf("invalid iterator")
+		return
+	}
+	stream, shard = parts[0], parts[1]
+	pos, err = strconv.Atoi(parts[2])
+	return
+}
+
+func (fk *FakeKinesis) getShardIterator(body []byte) (interface{}, string, error) {
+	var req struct {
+		StreamName             string
+		ShardId                string
+		ShardIteratorType      string
+		StartingSequenceNumber string
+		Timestamp              float64
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		return nil, "SerializationException", err
+	}
+	fk.mtx.Lock()
+	defer fk.mtx.Unlock()
+	fk.iterators = append(fk.iterators, IteratorRequest{Shard: req.ShardId, Type: req.ShardIteratorType})
+	s := fk.shardLocked(req.StreamName, req.ShardId)
+	if s == nil {
+		return nil, "ResourceNotFoundException", fmt.Errorf("shard %s not found", req.ShardId)
+	}
+	var pos int
+	switch req.ShardIteratorType {
+	case "TRIM_HORIZON":
+	case "LATEST":
+		pos = len(s.records)
+	case "AT_SEQUENCE_NUMBER", "AFTER_SEQUENCE_NUMBER":
+		pos = -1
+		for i, r := range s.records {
+			if r.seq == req.StartingSequenceNumber {
+				pos = i
+				break
+			}
+		}
+		if pos < 0 {
+			return nil, "InvalidArgumentException", fmt.Errorf("unknown sequence number %s", req.StartingSequenceNumber)
+		}
+		if req.ShardIteratorType == "AFTER_SEQUENCE_NUMBER" {
+			pos++
+		}
+	case "AT_TIMESTAMP":
+		ts := time.Unix(0, int64(req.Timestamp*1e9))
+		pos = sort.Search(len(s.records), func(i int) bool {
+			return !s.records[i].arrival.Before(ts)
+		})
+	default:
+		return nil, "InvalidArgumentException", fmt.Errorf("invalid iterator type %q", req.ShardIteratorType)
+	}
+	return map[string]string{"ShardIterator": encodeIterator(req.StreamName, req.ShardId, pos)}, ``, nil
+}
+
+func (fk *FakeKinesis) getRecords(body []byte) (interface{}, string, error) {
+	var req struct {
+		ShardIterator string
+		Limit         int
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		return nil, "SerializationException", err
+	}
+	stream, shard, pos, err := decodeIterator(req.ShardIterator)
+	if err != nil {
+		return nil, "InvalidArgumentException", err
+	}
+	fk.mtx.Lock()
+	defer fk.mtx.Unlock()
+	s := fk.shardLocked(stream, shard)
+	if s == nil {
+		return nil, "ResourceNotFoundException", fmt.Errorf("shard %s not found", shard)
+	}
+	end := len(s.records)
+	if req.Limit > 0 && pos+req.Limit < end {
+		end = pos + req.Limit
+	}
+	if pos > end {
+		pos = end
+	}
+	recs := []map[string]interface{}{}
+	for _, r := range s.records[pos:end] {
+		recs = append(recs, map[string]interface{}{
+			"SequenceNumber":              r.seq,
+			"Data":                        base64.StdEncoding.EncodeToString(r.data),
+			"PartitionKey":                "harness",
+			"ApproximateArrivalTimestamp": epoch(r.arrival),
+		})
+	}
+	var behind int64
+	if end < len(s.records) {
+		behind = time.Since(s.records[end].arrival).Milliseconds()
+	}
+	resp := map[string]interface{}{
+		"Records":            recs,
+		"MillisBehindLatest": behind,
+	}
+	// a closed shard that has been read to the end has no next iterator
+	if !s.closed || end < len(s.records) {
+		resp["NextShardIterator"] = encodeIterator(stream, shard, end)
+	}
+	return resp, ``, nil
+}
+
+/******************************************************************
+ * SQS
+ ******************************************************************/
+
+type sqsMessage struct {
+	id       string
+	body     string
+	sent     time.Time
+	receipt  string
+	received bool
+	deleted  bool
+}
+
+// FakeSQS implements ReceiveMessage and DeleteMessageBatch for in memory queues
+type FakeSQS struct {
+	srv *httptest.Server
+
+	mtx    sync.Mutex
+	queues map[string][]*sqsMessage
+	nextID int
+}
+
+// NewFakeSQS starts a fake SQS endpoint, it is closed when the test ends
+func NewFakeSQS(t testing.TB) *FakeSQS {
+	fs := &FakeSQS{queues: map[string][]*sqsMessage{}}
+	fs.srv = newAWSJSONServer(t, "AmazonSQS", "application/x-amz-json-1.0", map[string]jsonOperation{
+		"ReceiveMessage":     fs.receiveMessage,
+		"DeleteMessageBatch": fs.deleteMessageBatch,
+	})
+	return fs
+}
+
+// URL is the endpoint to configure in the ingester
+func (fs *FakeSQS) URL() string {
+	return fs.srv.URL
+}
+
+// QueueURL returns the URL of the named queue, queues are created when first used
+func (fs *FakeSQS) QueueURL(name string) string {
+	return fs.srv.URL + "/000000000000/" + name
+}
+
+// SendMessage enqueues a message on a queue
+func (fs *FakeSQS) SendMessage(queue, body string, sent time.Time) {
+	fs.mtx.Lock()
+	defer fs.mtx.Unlock()
+	fs.nextID++
+	u := fs.QueueURL(queue)
+	fs.queues[u] = append(fs.queues[u], &sqsMessage{
+		id:   fmt.Sprintf("msg-%d", fs.nextID),
+		body: body,
+		sent: sent,
+	})
+}
+
+// Remaining returns the number of messages that have not been deleted
+func (fs *FakeSQS) Remaining(queue string) (n int) {
+	fs.mtx.Lock()
+	defer fs.mtx.Unlock()
+	for _, m := range fs.queues[fs.QueueURL(queue)] {
+		if !m.deleted {
+			n++
+		}
+	}
+	return
+}
+
+func (fs *FakeSQS) receiveMessage(body []byte) (interface{}, string, error) {
+	var req struct {
+		QueueUrl            string
+		MaxNumberOfMessages int
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		return nil, "SerializationException", err
+	}
+	if req.MaxNumberOfMessages <= 0 {
+		req.MaxNumberOfMessages = 1
+	}
+	fs.mtx.Lock()
+	defer fs.mtx.Unlock()
+	msgs := []map[string]interface{}{}
+	for _, m := range fs.queues[req.QueueUrl] {
+		if len(msgs) >= req.MaxNumberOfMessages {
+			break
+		} else if m.deleted || m.received {
+			continue
+		}
+		m.received = true
+		m.receipt = "receipt-" + m.id
+		sum := md5.Sum([]byte(m.body))
+		msgs = append(msgs, map[string]interface{}{
+			"MessageId":     m.id,
+			"ReceiptHandle": m.receipt,
+			"MD5OfBody":     hex.EncodeToString(sum[:]),
+			"Body":          m.body,
+			"Attributes":    map[string]string{"SentTimestamp": strconv.FormatInt(m.sent.UnixMilli(), 10)},
+		})
+	}
+	return map[string]interface{}{"Messages": msgs}, ``, nil
+}
+
+func (fs *FakeSQS) deleteMessageBatch(body []byte) (interface{}, string, error) {
+	var req struct {
+		QueueUrl string
+		Entries  []struct {
+			Id            string
+			ReceiptHandle string
+		}
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		return nil, "SerializationException", err
+	}
+	fs.mtx.Lock()
+	defer fs.mtx.Unlock()
+	ok := []map[string]string{}
+	failed := []map[string]interface{}{}
+	for _, e := range req.Entries {
+		var found bool
+		for _, m := range fs.queues[req.QueueUrl] {
+			if m.receipt == e.ReceiptHandle && m.receipt != `` {
+				m.deleted = true
+				found = true
+			}
+		}
+		if found {
+			ok = append(ok, map[string]string{"Id": e.Id})
+		} else {
+			failed = append(failed, map[string]interface{}{"Id": e.Id, "Code": "ReceiptHandleIsInvalid", "SenderFault": true})
+		}
+	}
+	return map[string]interface{}{"Successful": ok, "Failed": failed}, ``, nil
+}
@@ -0,0 +1,300 @@
+/*************************************************************************
+ * Copyright 2026 Gravwell, Inc. All rights reserved.
+ * Contact: <legal@gravwell.io>
+ *
+ * This software may be modified and distributed under the terms of the
+ * BSD 2-clause license. See the LICENSE file for details.
+ **************************************************************************/
+
+// Package harness provides in-process fakes used to drive the cloud ingesters in integration tests.
+// An Indexer speaks the ingest protocol using ingest.EntryReader, and each of the Fake types
+// emulates just enough of a cloud service for an ingester to run against it.
+package harness
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gravwell/gravwell/v3/ingest"
+	"github.com/gravwell/gravwell/v3/ingest/entry"
+)
+
+const (
+	// IngestSecret is the shared secret ingesters must use to talk to the Indexer
+	IngestSecret = `IntegrationSecret`
+
+	handshakeTimeout = 10 * time.Second
+	pollInterval     = 25 * time.Millisecond
+)
+
+// Entry is an entry received by the Indexer with its tag resolved to a name
+type Entry struct {
+	Tag  string
+	TS   time.Time
+	SRC  net.IP
+	Data string
+}
+
+// Indexer is a minimal in-process indexer, it authenticates ingesters, negotiates tags,
+// and records every entry and ingester state it receives.
+type Indexer struct {
+	t    testing.TB
+	lst  net.Listener
+	auth ingest.AuthHash
+	wg   sync.WaitGroup
+
+	mtx     sync.Mutex
+	tags    map[string]entry.EntryTag
+	names   map[entry.EntryTag]string
+	entries []Entry
+	states  []ingest.IngesterState
+	conns   []net.Conn
+}
+
+// NewIndexer starts an indexer listening on a random loopback port, it is closed when the test ends
+func NewIndexer(t testing.TB) *Indexer {
+	t.Helper()
+	lst, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	auth, err := ingest.GenAuthHash(IngestSecret)
+	if err != nil {
+		t.Fatalf("failed to generate auth hash: %v", err)
+	}
+	idx := &Indexer{
+		t:     t,
+		lst:   lst,
+		auth:  auth,
+		tags:  map[string]entry.EntryTag{},
+		names: map[entry.EntryTag]string{},
+	}
+	idx.wg.Add(1)
+	go idx.acceptRoutine()
+	t.Cleanup(idx.Close)
+	return idx
+}
+
+// Addr is the address to use as a Cleartext-Backend-Target
+func (idx *Indexer) Addr() string {
+	return idx.lst.Addr().String()
+}
+
+// Close stops the listener and drops every connection
+func (idx *Indexer) Close() {
+	idx.lst.Close()
+	idx.mtx.Lock()
+	for _, c := range idx.conns {
+		c.Close()
+	}
+	idx.mtx.Unlock()
+	idx.wg.Wait()
+}
+
+func (idx *Indexer) acceptRoutine() {
+	defer idx.wg.Done()
+	for {
+		conn, err := idx.lst.Accept()
+		if err != nil {
+			return
+		}
+		idx.mtx.Lock()
+		idx.conns = append(idx.conns, conn)
+		idx.mtx.Unlock()
+		idx.wg.Add(1)
+		go idx.connRoutine(conn)
+	}
+}
+
+// GetAndPopulate implements ingest.TagManager so ingesters can negotiate tags on the fly
+func (idx *Indexer) GetAndPopulate(name string) (entry.EntryTag, error) {
+	idx.mtx.Lock()
+	defer idx.mtx.Unlock()
+	return idx.tagLocked(name), nil
+}
+
+func (idx *Indexer) tagLocked(name string) entry.EntryTag {
+	if tg, ok := idx.tags[name]; ok {
+		return tg
+	}
+	tg := entry.EntryTag(len(idx.tags))
+	idx.tags[name] = tg
+	idx.names[tg] = name
+	return tg
+}
+
+// handshake performs the server side of the authentication and tag negotiation
+func (idx *Indexer) handshake(conn net.Conn) error {
+	if err := conn.SetDeadline(time.Now().Add(handshakeTimeout)); err != nil {
+		return err
+	}
+	chal, err := ingest.NewChallenge(idx.auth)
+	if err != nil {
+		return err
+	} else if err = chal.Write(conn); err != nil {
+		return err
+	}
+	var resp ingest.ChallengeResponse
+	if err = resp.Read(conn); err != nil {
+		return err
+	}
+	if err = ingest.VerifyResponse(idx.auth, chal, resp); err != nil {
+		st := ingest.StateResponse{ID: ingest.STATE_NOT_AUTHENTICATED}
+		st.Write(conn)
+		return err
+	}
+	st := ingest.StateResponse{ID: ingest.STATE_AUTHENTICATED}
+	if err = st.Write(conn); err != nil {
+		return err
+	}
+
+	var treq ingest.TagRequest
+	if err = treq.Read(conn); err != nil {
+		return err
+	}
+	tresp := ingest.TagResponse{Tags: map[string]entry.EntryTag{}}
+	idx.mtx.Lock()
+	for _, name := range treq.Tags {
+		tresp.Tags[name] = idx.tagLocked(name)
+	}
+	idx.mtx.Unlock()
+	tresp.Count = uint32(len(tresp.Tags))
+	if err = tresp.Write(conn); err != nil {
+		return err
+	}
+	if err = st.Read(conn); err != nil {
+		return err
+	} else if st.ID != ingest.STATE_HOT {
+		return fmt.Errorf("unexpected state %x", st.ID)
+	}
+	return conn.SetDeadline(time.Time{})
+}
+
+func (idx *Indexer) connRoutine(conn net.Conn) {
+	defer idx.wg.Done()
+	defer conn.Close()
+	if err := idx.handshake(conn); err != nil {
+		idx.t.Logf("ingester handshake failed: %v", err)
+		return
+	}
+	er, err := ingest.NewEntryReaderEx(ingest.EntryReaderWriterConfig{
+		Conn:                  conn,
+		OutstandingEntryCount: ingest.MAX_UNCONFIRMED_COUNT,
+		BufferSize:            ingest.READ_BUFFER_SIZE,
+		Timeout:               time.Minute,
+		TagMan:                idx,
+	})
+	if err != nil {
+		idx.t.Logf("failed to create entry reader: %v", err)
+		return
+	}
+	defer er.Close()
+	er.AddIngesterStateCallback(func(s ingest.IngesterState) {
+		idx.mtx.Lock()
+		idx.states = append(idx.states, s)
+		idx.mtx.Unlock()
+	})
+	if err = er.Start(); err != nil {
+		return
+	} else if err = er.SetupConnection(); err != nil {
+		return
+	} else if err = er.IngestOK(true); err != nil {
+		return
+	} else if err = er.ConfigureStream(); err != nil {
+		return
+	}
+	for {
+		ent, err := er.Read()
+		if err != nil {
+			return
+		}
+		idx.mtx.Lock()
+		idx.entries = append(idx.entries, Entry{
+			Tag:  idx.names[ent.Tag],
+			TS:   ent.TS.StandardTime(),
+			SRC:  ent.SRC,
+			Data: string(ent.Data),
+		})
+		idx.mtx.Unlock()
+	}
+}
+
+// Entries returns a copy of every entry received so far, in arrival order
+func (idx *Indexer) Entries() []Entry {
+	idx.mtx.Lock()
+	defer idx.mtx.Unlock()
+	return append([]Entry(nil), idx.entries...)
+}
+
+// States returns every ingester state message received so far
+func (idx *Indexer) States() []ingest.IngesterState {
+	idx.mtx.Lock()
+	defer idx.mtx.Unlock()
+	return append([]ingest.IngesterState(nil), idx.states...)
+}
+
+// TagEntries returns a copy of the entries received on tag, in arrival order.
+// Ingesters ship their own logs to the gravwell tag, so tests should look at their data tag only.
+func (idx *Indexer) TagEntries(tag string) (r []Entry) {
+	for _, e := range idx.Entries() {
+		if e.Tag == tag {
+			r = append(r, e)
+		}
+	}
+	return
+}
+
+// WaitForEntries waits until at least n entries have arrived on tag
+func (idx *Indexer) WaitForEntries(tag string, n int, timeout time.Duration) ([]Entry, error) {
+	err := WaitFor(timeout, func() bool {
+		return len(idx.TagEntries(tag)) >= n
+	})
+	ents := idx.TagEntries(tag)
+	if err != nil {
+		err = fmt.Errorf("received %d of %d entries on %s: %w", len(ents), n, tag, err)
+	}
+	return ents, err
+}
+
+// WaitForState waits until an ingester state message satisfies fn
+func (idx *Indexer) WaitForState(timeout time.Duration, fn func(ingest.IngesterState) bool) (st ingest.IngesterState, err error) {
+	err = WaitFor(timeout, func() bool {
+		for _, s := range idx.States() {
+			if fn(s) {
+				st = s
+				return true
+			}
+		}
+		return false
+	})
+	return
+}
+
+// SortedData returns the entry payloads sorted, for comparisons where arrival order does not matter
+func SortedData(ents []Entry) (r []string) {
+	for _, e := range ents {
+		r = append(r, e.Data)
+	}
+	sort.Strings(r)
+	return
+}
+
+var ErrTimeout = errors.New("timed out")
+
+// WaitFor polls fn until it returns true or the timeout expires
+func WaitFor(timeout time.Duration, fn func() bool) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		if fn() {
+			return nil
+		} else if time.Now().After(deadline) {
+			return ErrTimeout
+		}
+		time.Sleep(pollInterval)
+	}
+}
@@ -0,0 +1,89 @@
+/*************************************************************************
+ * Copyright 2026 Gravwell, Inc. All rights reserved.
+ * Contact: <legal@gravwell.io>
+ *
+ * This software may be modified and distributed under the terms of the
+ * BSD 2-clause license. See the LICENSE file for details.
+ **************************************************************************/
+
+package harness
+
+import (
+	"testing"
+
+	"github.com/IBM/sarama"
+)
+
+// FakeKafka is a single broker Kafka cluster serving one partition of one topic to a consumer group.
+// Messages must be added before the ingester connects.
+type FakeKafka struct {
+	broker *sarama.MockBroker
+	topic  string
+	group  string
+	fetch  *sarama.MockFetchResponse
+	count  int64
+	t      testing.TB
+}
+
+// NewFakeKafka starts a mock broker that acts as leader and group coordinator for topic partition 0
+func NewFakeKafka(t testing.TB, topic, group string) *FakeKafka {
+	fk := &FakeKafka{
+		broker: sarama.NewMockBroker(t, 0),
+		topic:  topic,
+		group:  group,
+		fetch:  sarama.NewMockFetchResponse(t, 16),
+		t:      t,
+	}
+	t.Cleanup(fk.broker.Close)
+	return fk
+}
+
+// Addr returns the address of the broker
+func (fk *FakeKafka) Addr() string {
+	return fk.broker.Addr()
+}
+
+// Produce adds a message to partition 0 at the next offset
+func (fk *FakeKafka) Produce(msg string) {
+	fk.fetch.SetMessage(fk.topic, 0, fk.count, sarama.StringEncoder(msg))
+	fk.count++
+	fk.fetch.SetHighWaterMark(fk.topic, 0, fk.count)
+	fk.setHandlers()
+}
+
+func (fk *FakeKafka) setHandlers() {
+	t, b := fk.t, fk.broker
+	b.SetHandlerByMap(map[string]sarama.MockResponse{
+		"MetadataRequest": sarama.NewMockMetadataResponse(t).
+			SetBroker(b.Addr(), b.BrokerID()).
+			SetLeader(fk.topic, 0, b.BrokerID()),
+		"OffsetRequest": sarama.NewMockOffsetResponse(t).
+			SetOffset(fk.topic, 0, sarama.OffsetOldest, 0).
+			SetOffset(fk.topic, 0, sarama.OffsetNewest, fk.count),
+		"FindCoordinatorRequest": sarama.NewMockFindCoordinatorResponse(t).
+			SetCoordinator(sarama.CoordinatorGroup, fk.group, b),
+		"HeartbeatRequest": sarama.NewMockHeartbeatResponse(t),
+		"JoinGroupRequest": sarama.NewMockJoinGroupResponse(t).
+			SetGroupProtocol(sarama.RangeBalanceStrategyName),
+		"SyncGroupRequest": sarama.NewMockSyncGroupResponse(t).
+			SetMemberAssignment(&sarama.ConsumerGroupMemberAssignment{
+				Topics: map[string][]int32{fk.topic: {0}},
+			}),
+		"OffsetFetchRequest": sarama.NewMockOffsetFetchResponse(t).
+			SetOffset(fk.group, fk.topic, 0, -1, "", sarama.ErrNoError).
+			SetError(sarama.ErrNoError),
+		"OffsetCommitRequest": sarama.NewMockOffsetCommitResponse(t),
+		"LeaveGroupRequest":   sarama.NewMockLeaveGroupResponse(t),
+		"FetchRequest":        fk.fetch,
+	})
+}
+
+// Commits returns the number of offset commits the consumer group has sent
+func (fk *FakeKafka) Commits() (n int) {
+	for _, rr := range fk.broker.History() {
+		if _, ok := rr.Request.(*sarama.OffsetCommitRequest); ok {
+			n++
+		}
+	}
+	return
+}
@@ -0,0 +1,81 @@
+/*************************************************************************
+ * Copyright 2026 Gravwell, Inc. All rights reserved.
+ * Contact: <legal@gravwell.io>
+ *
+ * This software may be modified and distributed under the terms of the
+ * BSD 2-clause license. See the LICENSE file for details.
+ **************************************************************************/
+
+package harness
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/pubsub/apiv1/pubsubpb"
+	"cloud.google.com/go/pubsub/pstest"
+)
+
+// FakePubSub is an in-process Pub/Sub server, ingesters find it through PUBSUB_EMULATOR_HOST
+type FakePubSub struct {
+	srv     *pstest.Server
+	project string
+}
+
+// NewFakePubSub starts a fake Pub/Sub server for the project, it is closed when the test ends
+func NewFakePubSub(t testing.TB, project string) *FakePubSub {
+	fp := &FakePubSub{
+		srv:     pstest.NewServer(),
+		project: project,
+	}
+	t.Cleanup(func() { fp.srv.Close() })
+	return fp
+}
+
+// Env returns the environment an ingester needs to use the fake
+func (fp *FakePubSub) Env() []string {
+	return []string{"PUBSUB_EMULATOR_HOST=" + fp.srv.Addr}
+}
+
+func (fp *FakePubSub) topicName(topic string) string {
+	return fmt.Sprintf("projects/%s/topics/%s", fp.project, topic)
+}
+
+// CreateTopic creates a topic
+func (fp *FakePubSub) CreateTopic(topic string) error {
+	_, err := fp.srv.GServer.CreateTopic(context.Background(), &pubsubpb.Topic{Name: fp.topicName(topic)})
+	return err
+}
+
+// CreateSubscription creates a subscription on a topic that retains acknowledged messages so it can be seeked
+func (fp *FakePubSub) CreateSubscription(topic, sub string) error {
+	_, err := fp.srv.GServer.CreateSubscription(context.Background(), &pubsubpb.Subscription{
+		Name:                fmt.Sprintf("projects/%s/subscriptions/%s", fp.project, sub),
+		Topic:               fp.topicName(topic),
+		AckDeadlineSeconds:  10,
+		RetainAckedMessages: true,
+	})
+	return err
+}
+
+// Publish publishes a message and returns the publish time assigned by the server.
+// Publish times are always the current time, the fake expires anything older than the retention window.
+func (fp *FakePubSub) Publish(topic string, data []byte) time.Time {
+	id := fp.srv.Publish(fp.topicName(topic), data, nil)
+	if m := fp.srv.Message(id); m != nil {
+		return m.PublishTime
+	}
+	return time.Time{}
+}
+
+// Acked returns the number of messages that have been acknowledged at least once
+func (fp *FakePubSub) Acked() (n int) {
+	for _, m := range fp.srv.Messages() {
+		if m.Acks > 0 {
+			n++
+		}
+	}
+	return
+}
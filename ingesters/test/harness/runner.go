@@ -0,0 +1,156 @@
+/*************************************************************************
+ * Copyright 2026 Gravwell, Inc. All rights reserved.
+ * Contact: <legal@gravwell.io>
+ *
+ * This software may be modified and distributed under the terms of the
+ * BSD 2-clause license. See the LICENSE file for details.
+ **************************************************************************/
+
+package harness
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+)
+
+const (
+	modulePath  = `github.com/gravwell/gravwell/v3/`
+	stopTimeout = 15 * time.Second
+)
+
+var (
+	buildMtx  sync.Mutex
+	buildDir  string
+	buildBins = map[string]string{}
+)
+
+// Build compiles an ingester, e.g. "ingesters/KinesisIngester", once per test binary and returns the path
+func Build(t testing.TB, pkg string) string {
+	t.Helper()
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go toolchain not available")
+	}
+	buildMtx.Lock()
+	defer buildMtx.Unlock()
+	if bin, ok := buildBins[pkg]; ok {
+		return bin
+	}
+	if buildDir == `` {
+		var err error
+		if buildDir, err = os.MkdirTemp("", "ingester-harness"); err != nil {
+			t.Fatalf("failed to create build directory: %v", err)
+		}
+	}
+	bin := filepath.Join(buildDir, filepath.Base(pkg))
+	out, err := exec.Command("go", "build", "-o", bin, modulePath+pkg).CombinedOutput()
+	if err != nil {
+		t.Fatalf("failed to build %s: %v\n%s", pkg, err, out)
+	}
+	buildBins[pkg] = bin
+	return bin
+}
+
+// Ingester is a running ingester process
+type Ingester struct {
+	t      testing.TB
+	cmd    *exec.Cmd
+	dir    string
+	out    bytes.Buffer
+	done   chan struct{}
+	err    error
+	exited bool
+}
+
+// GlobalConfig returns a [Global] section pointing at the indexer with state and logs under dir
+func GlobalConfig(idx *Indexer, dir string, extra ...string) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "[Global]\n")
+	fmt.Fprintf(&sb, "Ingest-Secret=%q\n", IngestSecret)
+	fmt.Fprintf(&sb, "Connection-Timeout=0\n")
+	fmt.Fprintf(&sb, "Cleartext-Backend-Target=%s\n", idx.Addr())
+	fmt.Fprintf(&sb, "Log-Level=INFO\n")
+	fmt.Fprintf(&sb, "Log-File=%s\n", filepath.Join(dir, "ingester.log"))
+	for _, e := range extra {
+		fmt.Fprintf(&sb, "%s\n", e)
+	}
+	return sb.String()
+}
+
+// Start writes the config into dir and runs the ingester against it; the process is
+// stopped when the test ends if it has not been stopped already.
+func Start(t testing.TB, bin, dir, config string, env []string, args ...string) *Ingester {
+	t.Helper()
+	cfgPath := filepath.Join(dir, "ingester.conf")
+	if err := os.WriteFile(cfgPath, []byte(config), 0600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	ig := &Ingester{
+		t:    t,
+		dir:  dir,
+		done: make(chan struct{}),
+	}
+	args = append([]string{"-config-file", cfgPath, "-config-overlays", filepath.Join(dir, "conf.d")}, args...)
+	ig.cmd = exec.Command(bin, args...)
+	ig.cmd.Dir = dir
+	ig.cmd.Env = append(os.Environ(), env...)
+	ig.cmd.Stdout = &ig.out
+	ig.cmd.Stderr = &ig.out
+	if err := ig.cmd.Start(); err != nil {
+		t.Fatalf("failed to start %s: %v", bin, err)
+	}
+	go func() {
+		ig.err = ig.cmd.Wait()
+		close(ig.done)
+	}()
+	t.Cleanup(func() {
+		ig.Stop()
+		if t.Failed() {
+			ig.dumpLogs()
+		}
+	})
+	return ig
+}
+
+// Exited returns true if the process has exited on its own
+func (ig *Ingester) Exited() bool {
+	select {
+	case <-ig.done:
+		return true
+	default:
+	}
+	return false
+}
+
+// Stop sends SIGTERM and waits for the ingester to exit, killing it if it takes too long
+func (ig *Ingester) Stop() error {
+	if ig.exited {
+		return ig.err
+	}
+	ig.exited = true
+	if !ig.Exited() {
+		ig.cmd.Process.Signal(syscall.SIGTERM)
+	}
+	select {
+	case <-ig.done:
+	case <-time.After(stopTimeout):
+		ig.t.Logf("ingester did not exit, killing it")
+		ig.cmd.Process.Kill()
+		<-ig.done
+	}
+	return ig.err
+}
+
+func (ig *Ingester) dumpLogs() {
+	ig.t.Logf("ingester output:\n%s", ig.out.String())
+	if b, err := os.ReadFile(filepath.Join(ig.dir, "ingester.log")); err == nil {
+		ig.t.Logf("ingester log:\n%s", b)
+	}
+}
@@ -0,0 +1,174 @@
+/*************************************************************************
+ * Copyright 2026 Gravwell, Inc. All rights reserved.
+ * Contact: <legal@gravwell.io>
+ *
+ * This software may be modified and distributed under the terms of the
+ * BSD 2-clause license. See the LICENSE file for details.
+ **************************************************************************/
+
+package harness
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type s3Object struct {
+	data     []byte
+	modified time.Time
+}
+
+// FakeS3 implements path style ListObjectsV2 and GetObject for in memory buckets
+type FakeS3 struct {
+	srv *httptest.Server
+
+	mtx     sync.Mutex
+	buckets map[string]map[string]s3Object
+	gets    map[string]int
+}
+
+type s3Contents struct {
+	Key          string
+	LastModified string
+	ETag         string
+	Size         int
+	StorageClass string
+}
+
+type s3ListResult struct {
+	XMLName     xml.Name `xml:"http://s3.amazonaws.com/doc/2006-03-01/ ListBucketResult"`
+	Name        string
+	Prefix      string
+	KeyCount    int
+	MaxKeys     int
+	IsTruncated bool
+	Contents    []s3Contents
+}
+
+type s3Error struct {
+	XMLName xml.Name `xml:"Error"`
+	Code    string
+	Message string
+}
+
+// NewFakeS3 starts a fake S3 endpoint, it is closed when the test ends
+func NewFakeS3(t testing.TB) *FakeS3 {
+	fs := &FakeS3{
+		buckets: map[string]map[string]s3Object{},
+		gets:    map[string]int{},
+	}
+	fs.srv = httptest.NewServer(http.HandlerFunc(fs.handle))
+	t.Cleanup(fs.srv.Close)
+	return fs
+}
+
+// URL is the endpoint to configure in the ingester, buckets must be addressed by path
+func (fs *FakeS3) URL() string {
+	return fs.srv.URL
+}
+
+// PutObject stores an object, creating the bucket if needed
+func (fs *FakeS3) PutObject(bucket, key string, data []byte, modified time.Time) {
+	fs.mtx.Lock()
+	defer fs.mtx.Unlock()
+	if _, ok := fs.buckets[bucket]; !ok {
+		fs.buckets[bucket] = map[string]s3Object{}
+	}
+	fs.buckets[bucket][key] = s3Object{data: data, modified: modified.UTC().Truncate(time.Second)}
+}
+
+// Gets returns the number of times an object has been fetched
+func (fs *FakeS3) Gets(bucket, key string) int {
+	fs.mtx.Lock()
+	defer fs.mtx.Unlock()
+	return fs.gets[bucket+"/"+key]
+}
+
+func etag(b []byte) string {
+	sum := md5.Sum(b)
+	return `"` + hex.EncodeToString(sum[:]) + `"`
+}
+
+func (fs *FakeS3) writeError(w http.ResponseWriter, status int, code, msg string) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	xml.NewEncoder(w).Encode(s3Error{Code: code, Message: msg})
+}
+
+func (fs *FakeS3) handle(w http.ResponseWriter, r *http.Request) {
+	bucket, key, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	fs.mtx.Lock()
+	defer fs.mtx.Unlock()
+	objs, ok := fs.buckets[bucket]
+	if !ok {
+		fs.writeError(w, http.StatusNotFound, "NoSuchBucket", "The specified bucket does not exist")
+		return
+	}
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		fs.writeError(w, http.StatusMethodNotAllowed, "MethodNotAllowed", r.Method)
+		return
+	}
+	if key == `` {
+		fs.list(w, r, bucket, objs)
+		return
+	}
+	obj, ok := objs[key]
+	if !ok {
+		fs.writeError(w, http.StatusNotFound, "NoSuchKey", "The specified key does not exist")
+		return
+	}
+	fs.gets[bucket+"/"+key]++
+	w.Header().Set("Content-Length", strconv.Itoa(len(obj.data)))
+	w.Header().Set("Last-Modified", obj.modified.Format(http.TimeFormat))
+	w.Header().Set("ETag", etag(obj.data))
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.WriteHeader(http.StatusOK)
+	if r.Method == http.MethodGet {
+		w.Write(obj.data)
+	}
+}
+
+func (fs *FakeS3) list(w http.ResponseWriter, r *http.Request, bucket string, objs map[string]s3Object) {
+	prefix := r.URL.Query().Get("prefix")
+	maxKeys := 1000
+	if v, err := strconv.Atoi(r.URL.Query().Get("max-keys")); err == nil && v > 0 {
+		maxKeys = v
+	}
+	var keys []string
+	for k := range objs {
+		if strings.HasPrefix(k, prefix) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	res := s3ListResult{
+		Name:    bucket,
+		Prefix:  prefix,
+		MaxKeys: maxKeys,
+	}
+	// keep things simple and never paginate, callers asking for fewer keys just get them all
+	for _, k := range keys {
+		o := objs[k]
+		res.Contents = append(res.Contents, s3Contents{
+			Key:          k,
+			LastModified: o.modified.Format("2006-01-02T15:04:05.000Z"),
+			ETag:         etag(o.data),
+			Size:         len(o.data),
+			StorageClass: "STANDARD",
+		})
+	}
+	res.KeyCount = len(res.Contents)
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(xml.Header))
+	xml.NewEncoder(w).Encode(res)
+}
@@ -14,6 +14,9 @@ import (
 	"io"
 	"strings"
 	"testing"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/ulikunitz/xz"
 )
 
 func TestNewCompressedReaderGzip(t *testing.T) {
@@ -64,6 +67,62 @@ func TestNewCompressedReaderBzip2(t *testing.T) {
 	}
 }
 
+func TestNewCompressedReaderXZ(t *testing.T) {
+	testData := "Hello, this is test data for xz compression!"
+
+	var buf bytes.Buffer
+	xw, err := xz.NewWriter(&buf)
+	if err != nil {
+		t.Fatalf("Failed to create xz writer: %v", err)
+	}
+	if _, err = xw.Write([]byte(testData)); err != nil {
+		t.Fatalf("Failed to write xz data: %v", err)
+	}
+	if err = xw.Close(); err != nil {
+		t.Fatalf("Failed to close xz writer: %v", err)
+	}
+
+	r, err := NewCompressedReader(&buf)
+	if err != nil {
+		t.Fatalf("NewCompressedReader failed: %v", err)
+	}
+	result, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("Failed to read decompressed data: %v", err)
+	}
+	if string(result) != testData {
+		t.Errorf("Decompressed data mismatch: got %q, want %q", string(result), testData)
+	}
+}
+
+func TestNewCompressedReaderZstd(t *testing.T) {
+	testData := "Hello, this is test data for zstd compression!"
+
+	var buf bytes.Buffer
+	zw, err := zstd.NewWriter(&buf)
+	if err != nil {
+		t.Fatalf("Failed to create zstd writer: %v", err)
+	}
+	if _, err = zw.Write([]byte(testData)); err != nil {
+		t.Fatalf("Failed to write zstd data: %v", err)
+	}
+	if err = zw.Close(); err != nil {
+		t.Fatalf("Failed to close zstd writer: %v", err)
+	}
+
+	r, err := NewCompressedReader(&buf)
+	if err != nil {
+		t.Fatalf("NewCompressedReader failed: %v", err)
+	}
+	result, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("Failed to read decompressed data: %v", err)
+	}
+	if string(result) != testData {
+		t.Errorf("Decompressed data mismatch: got %q, want %q", string(result), testData)
+	}
+}
+
 func TestNewCompressedReaderRaw(t *testing.T) {
 	testData := "Hello, this is uncompressed test data!"
 
@@ -0,0 +1,268 @@
+/*************************************************************************
+ * Copyright 2026 Gravwell, Inc. All rights reserved.
+ * Contact: <legal@gravwell.io>
+ *
+ * This software may be modified and distributed under the terms of the
+ * BSD 2-clause license. See the LICENSE file for details.
+ **************************************************************************/
+
+// Package deadletter implements dead letter handling for queue based ingesters.
+// Messages that repeatedly fail processing are forwarded, along with the reason they failed,
+// to a dead letter queue and/or a local spool directory so they neither stall the queue nor get lost.
+package deadletter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/dchest/safefile"
+	"github.com/gravwell/gravwell/v3/ingest"
+	"github.com/gravwell/gravwell/v3/ingest/entry"
+)
+
+const (
+	// DefaultMaxReceiveCount is used when a dead letter destination is configured without a Max-Receive-Count
+	DefaultMaxReceiveCount = 5
+
+	spoolDirPerms  = 0750
+	spoolFilePerms = 0640
+)
+
+var (
+	ErrNoDestination = errors.New("Max-Receive-Count and Dead-Letter-Tag require a dead letter queue or Dead-Letter-Spool")
+)
+
+// Config is embedded in listener configurations that support dead lettering.
+// The remote destination (a queue, topic, etc.) is specific to each ingester and lives alongside it.
+type Config struct {
+	Max_Receive_Count int    // deliveries of a failing message before it is dead lettered
+	Dead_Letter_Spool string // local directory that dead lettered messages are written to
+	Dead_Letter_Tag   string // tag that receives a summary entry for every dead lettered message
+}
+
+// Verify checks the config, remote indicates that the listener has a remote dead letter destination.
+// If a destination is set without a Max-Receive-Count the default count is applied.
+func (c *Config) Verify(remote bool) error {
+	if c.Max_Receive_Count < 0 {
+		return fmt.Errorf("invalid Max-Receive-Count %d", c.Max_Receive_Count)
+	}
+	if c.Dead_Letter_Tag != `` {
+		if err := ingest.CheckTag(c.Dead_Letter_Tag); err != nil {
+			return fmt.Errorf("invalid Dead-Letter-Tag %q: %w", c.Dead_Letter_Tag, err)
+		}
+	}
+	if !remote && c.Dead_Letter_Spool == `` {
+		if c.Max_Receive_Count > 0 || c.Dead_Letter_Tag != `` {
+			return ErrNoDestination
+		}
+		return nil
+	}
+	if c.Dead_Letter_Spool != `` {
+		if fi, err := os.Stat(c.Dead_Letter_Spool); err == nil && !fi.IsDir() {
+			return fmt.Errorf("Dead-Letter-Spool %q is not a directory", c.Dead_Letter_Spool)
+		}
+	}
+	if c.Max_Receive_Count == 0 {
+		c.Max_Receive_Count = DefaultMaxReceiveCount
+	}
+	return nil
+}
+
+// Enabled returns true if the config has a dead letter destination, it is only valid after Verify
+func (c Config) Enabled() bool {
+	return c.Max_Receive_Count > 0
+}
+
+// Letter is a message that failed processing along with the context of the failure
+type Letter struct {
+	Source     string            `json:"source"` // queue URL, subscription, etc. the message came from
+	ID         string            `json:"id"`
+	Receives   int               `json:"receives"`
+	Error      string            `json:"error"`
+	Time       time.Time         `json:"time"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+	Body       []byte            `json:"body"`
+}
+
+// NewLetter creates a letter stamped with the current time, err may be nil
+func NewLetter(source, id string, receives int, body []byte, err error) Letter {
+	l := Letter{
+		Source:   source,
+		ID:       id,
+		Receives: receives,
+		Time:     time.Now().UTC(),
+		Body:     body,
+	}
+	if err != nil {
+		l.Error = err.Error()
+	}
+	return l
+}
+
+// Summary is the entry sent to the Dead-Letter-Tag, it omits the message body
+type Summary struct {
+	Source       string    `json:"source"`
+	ID           string    `json:"id"`
+	Receives     int       `json:"receives"`
+	Error        string    `json:"error"`
+	Time         time.Time `json:"time"`
+	Size         int       `json:"size"`
+	Destinations []string  `json:"destinations"`
+}
+
+// Forwarder delivers dead lettered messages to a destination
+type Forwarder interface {
+	Forward(context.Context, Letter) error
+	String() string
+}
+
+// Muxer is the subset of the ingest muxer needed to send summary entries
+type Muxer interface {
+	GetTag(string) (entry.EntryTag, error)
+	WriteEntryContext(context.Context, *entry.Entry) error
+}
+
+// DeadLetter routes exhausted messages to the configured destinations, a nil DeadLetter is disabled
+type DeadLetter struct {
+	max    int
+	fwds   []Forwarder
+	igst   Muxer
+	tag    entry.EntryTag
+	hasTag bool
+}
+
+// New creates a DeadLetter from a verified config, remote may be nil.
+// If the config does not enable dead lettering a nil DeadLetter is returned.
+func New(cfg Config, igst Muxer, remote Forwarder) (dl *DeadLetter, err error) {
+	if !cfg.Enabled() {
+		return
+	}
+	dl = &DeadLetter{
+		max:  cfg.Max_Receive_Count,
+		igst: igst,
+	}
+	if remote != nil {
+		dl.fwds = append(dl.fwds, remote)
+	}
+	if cfg.Dead_Letter_Spool != `` {
+		var sp *Spool
+		if sp, err = NewSpool(cfg.Dead_Letter_Spool); err != nil {
+			return nil, err
+		}
+		dl.fwds = append(dl.fwds, sp)
+	}
+	if len(dl.fwds) == 0 {
+		return nil, ErrNoDestination
+	}
+	if cfg.Dead_Letter_Tag != `` {
+		if igst == nil {
+			return nil, errors.New("nil ingest muxer")
+		} else if dl.tag, err = igst.GetTag(cfg.Dead_Letter_Tag); err != nil {
+			return nil, fmt.Errorf("failed to resolve Dead-Letter-Tag %q: %w", cfg.Dead_Letter_Tag, err)
+		}
+		dl.hasTag = true
+	}
+	return
+}
+
+// Exhausted returns true if a message that has been received the given number of times should be dead lettered
+func (dl *DeadLetter) Exhausted(receives int) bool {
+	return dl != nil && receives >= dl.max
+}
+
+// Send forwards the letter to every destination and emits the summary entry.
+// If an error is returned the message must not be acknowledged, it will be retried and may be forwarded again.
+func (dl *DeadLetter) Send(ctx context.Context, l Letter) (err error) {
+	if dl == nil {
+		return errors.New("dead lettering is not enabled")
+	}
+	if l.Time.IsZero() {
+		l.Time = time.Now().UTC()
+	}
+	dsts := make([]string, 0, len(dl.fwds))
+	for _, f := range dl.fwds {
+		if err = f.Forward(ctx, l); err != nil {
+			return fmt.Errorf("failed to dead letter message to %s: %w", f, err)
+		}
+		dsts = append(dsts, f.String())
+	}
+	if !dl.hasTag {
+		return
+	}
+	var b []byte
+	if b, err = json.Marshal(Summary{
+		Source:       l.Source,
+		ID:           l.ID,
+		Receives:     l.Receives,
+		Error:        l.Error,
+		Time:         l.Time,
+		Size:         len(l.Body),
+		Destinations: dsts,
+	}); err != nil {
+		return
+	}
+	return dl.igst.WriteEntryContext(ctx, &entry.Entry{
+		TS:   entry.FromStandard(l.Time),
+		Tag:  dl.tag,
+		Data: b,
+	})
+}
+
+// Spool writes each letter as a JSON file in a directory
+type Spool struct {
+	dir string
+}
+
+// NewSpool creates the spool directory if needed
+func NewSpool(dir string) (*Spool, error) {
+	if err := os.MkdirAll(dir, spoolDirPerms); err != nil {
+		return nil, fmt.Errorf("failed to create Dead-Letter-Spool %q: %w", dir, err)
+	}
+	return &Spool{dir: dir}, nil
+}
+
+// Forward atomically writes the letter into the spool
+func (s *Spool) Forward(ctx context.Context, l Letter) error {
+	b, err := json.Marshal(l)
+	if err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%d-%08x.json", l.Time.UnixNano(), crc32.ChecksumIEEE([]byte(l.Source+l.ID)))
+	return safefile.WriteFile(filepath.Join(s.dir, name), b, spoolFilePerms)
+}
+
+func (s *Spool) String() string {
+	return `spool:` + s.dir
+}
+
+// Counter tracks deliveries for messaging systems that do not report a receive count.
+// Counts are only held in memory, so they restart when the ingester does.
+type Counter struct {
+	sync.Mutex
+	counts map[string]int
+}
+
+// Receive records a delivery of the message and returns how many times it has been seen
+func (c *Counter) Receive(id string) int {
+	c.Lock()
+	defer c.Unlock()
+	if c.counts == nil {
+		c.counts = map[string]int{}
+	}
+	c.counts[id]++
+	return c.counts[id]
+}
+
+// Done forgets a message once it has been acknowledged or dead lettered
+func (c *Counter) Done(id string) {
+	c.Lock()
+	delete(c.counts, id)
+	c.Unlock()
+}
@@ -0,0 +1,208 @@
+/*************************************************************************
+ * Copyright 2026 Gravwell, Inc. All rights reserved.
+ * Contact: <legal@gravwell.io>
+ *
+ * This software may be modified and distributed under the terms of the
+ * BSD 2-clause license. See the LICENSE file for details.
+ **************************************************************************/
+
+package deadletter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/gravwell/gravwell/v3/ingest/entry"
+)
+
+type testMuxer struct {
+	ents []*entry.Entry
+}
+
+func (tm *testMuxer) GetTag(name string) (entry.EntryTag, error) {
+	if name == `deadletter` {
+		return 7, nil
+	}
+	return 0, errors.New("unknown tag")
+}
+
+func (tm *testMuxer) WriteEntryContext(ctx context.Context, ent *entry.Entry) error {
+	tm.ents = append(tm.ents, ent)
+	return nil
+}
+
+type testForwarder struct {
+	letters []Letter
+	err     error
+}
+
+func (tf *testForwarder) Forward(ctx context.Context, l Letter) error {
+	if tf.err != nil {
+		return tf.err
+	}
+	tf.letters = append(tf.letters, l)
+	return nil
+}
+
+func (tf *testForwarder) String() string {
+	return `test`
+}
+
+func TestVerify(t *testing.T) {
+	var c Config
+	if err := c.Verify(false); err != nil || c.Enabled() {
+		t.Fatalf("empty config should be valid and disabled: %v", err)
+	}
+	c = Config{Max_Receive_Count: 3}
+	if err := c.Verify(false); err != ErrNoDestination {
+		t.Fatalf("expected missing destination, got %v", err)
+	}
+	c = Config{Dead_Letter_Tag: `deadletter`}
+	if err := c.Verify(false); err != ErrNoDestination {
+		t.Fatalf("expected missing destination, got %v", err)
+	}
+	c = Config{Max_Receive_Count: -1, Dead_Letter_Spool: t.TempDir()}
+	if err := c.Verify(false); err == nil {
+		t.Fatal("accepted negative receive count")
+	}
+	c = Config{Dead_Letter_Tag: `bad tag!`}
+	if err := c.Verify(true); err == nil {
+		t.Fatal("accepted invalid tag")
+	}
+	c = Config{}
+	if err := c.Verify(true); err != nil {
+		t.Fatal(err)
+	} else if c.Max_Receive_Count != DefaultMaxReceiveCount || !c.Enabled() {
+		t.Fatalf("default receive count not applied: %d", c.Max_Receive_Count)
+	}
+	fpath := filepath.Join(t.TempDir(), `file`)
+	if err := os.WriteFile(fpath, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	c = Config{Dead_Letter_Spool: fpath}
+	if err := c.Verify(false); err == nil {
+		t.Fatal("accepted a file as the spool directory")
+	}
+}
+
+func TestDisabled(t *testing.T) {
+	dl, err := New(Config{}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	} else if dl != nil {
+		t.Fatal("disabled config returned a DeadLetter")
+	}
+	if dl.Exhausted(1000) {
+		t.Fatal("nil DeadLetter exhausted a message")
+	}
+	if err = dl.Send(context.Background(), Letter{}); err == nil {
+		t.Fatal("nil DeadLetter accepted a letter")
+	}
+}
+
+func TestSend(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), `spool`)
+	cfg := Config{
+		Max_Receive_Count: 3,
+		Dead_Letter_Spool: dir,
+		Dead_Letter_Tag:   `deadletter`,
+	}
+	if err := cfg.Verify(true); err != nil {
+		t.Fatal(err)
+	}
+	var tm testMuxer
+	var tf testForwarder
+	dl, err := New(cfg, &tm, &tf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dl.Exhausted(2) || !dl.Exhausted(3) {
+		t.Fatal("bad exhaustion check")
+	}
+
+	l := NewLetter(`queue`, `id1`, 3, []byte("poison"), errors.New("bad message"))
+	l.Attributes = map[string]string{`foo`: `bar`}
+	if err = dl.Send(context.Background(), l); err != nil {
+		t.Fatal(err)
+	}
+
+	// remote forwarder got the letter untouched
+	if len(tf.letters) != 1 || !reflect.DeepEqual(tf.letters[0], l) {
+		t.Fatalf("bad forwarded letters: %+v", tf.letters)
+	}
+
+	// the spool holds the full letter
+	des, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(des) != 1 {
+		t.Fatalf("expected 1 spooled letter, got %d", len(des))
+	}
+	b, err := os.ReadFile(filepath.Join(dir, des[0].Name()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sl Letter
+	if err = json.Unmarshal(b, &sl); err != nil {
+		t.Fatal(err)
+	} else if string(sl.Body) != `poison` || sl.Error != `bad message` || sl.Receives != 3 || sl.Attributes[`foo`] != `bar` {
+		t.Fatalf("bad spooled letter: %+v", sl)
+	}
+
+	// the summary lands on the dead letter tag without the body
+	if len(tm.ents) != 1 {
+		t.Fatalf("expected 1 summary entry, got %d", len(tm.ents))
+	} else if tm.ents[0].Tag != 7 {
+		t.Fatalf("summary has tag %d", tm.ents[0].Tag)
+	}
+	var s Summary
+	if err = json.Unmarshal(tm.ents[0].Data, &s); err != nil {
+		t.Fatal(err)
+	}
+	want := Summary{
+		Source:       `queue`,
+		ID:           `id1`,
+		Receives:     3,
+		Error:        `bad message`,
+		Time:         l.Time,
+		Size:         6,
+		Destinations: []string{`test`, `spool:` + dir},
+	}
+	if !reflect.DeepEqual(s, want) {
+		t.Fatalf("bad summary:\n%+v\n%+v", s, want)
+	}
+}
+
+func TestSendFailure(t *testing.T) {
+	var tm testMuxer
+	tf := testForwarder{err: errors.New("unavailable")}
+	cfg := Config{Dead_Letter_Tag: `deadletter`}
+	if err := cfg.Verify(true); err != nil {
+		t.Fatal(err)
+	}
+	dl, err := New(cfg, &tm, &tf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = dl.Send(context.Background(), NewLetter(`queue`, `id`, 5, nil, nil)); err == nil {
+		t.Fatal("forwarding failure not reported")
+	} else if len(tm.ents) != 0 {
+		t.Fatal("summary sent for a message that was not dead lettered")
+	}
+}
+
+func TestCounter(t *testing.T) {
+	var c Counter
+	if c.Receive(`a`) != 1 || c.Receive(`a`) != 2 || c.Receive(`b`) != 1 {
+		t.Fatal("bad counts")
+	}
+	c.Done(`a`)
+	if c.Receive(`a`) != 1 {
+		t.Fatal("count not reset")
+	}
+}
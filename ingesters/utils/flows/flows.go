@@ -0,0 +1,515 @@
+/*************************************************************************
+ * Copyright 2026 Gravwell, Inc. All rights reserved.
+ * Contact: <legal@gravwell.io>
+ *
+ * This software may be modified and distributed under the terms of the
+ * BSD 2-clause license. See the LICENSE file for details.
+ **************************************************************************/
+
+// Package flows tracks TCP and UDP flows from captured packets and summarizes each flow
+// in a single entry rather than shipping every packet.  Protocol metadata (DNS, HTTP, TLS)
+// seen on the flow is attached to the summary as enumerated values.
+package flows
+
+import (
+	"container/list"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/netip"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"github.com/gravwell/gravwell/v3/ingest"
+	"github.com/gravwell/gravwell/v3/ingest/entry"
+)
+
+const (
+	DefaultIdleTimeout   = time.Minute
+	DefaultActiveTimeout = 30 * time.Minute
+	DefaultMaxFlows      = 65536
+
+	// closed TCP flows stay in the table briefly so trailing ACKs don't spawn new flows
+	closeLinger = 5 * time.Second
+
+	// only the start of each direction of a TCP stream is reassembled for protocol detection
+	maxStreamBuffer = 16 * 1024
+)
+
+// end reasons reported in the flow summary
+const (
+	EndFIN      = `fin`
+	EndRST      = `rst`
+	EndIdle     = `idle`
+	EndActive   = `active`
+	EndEvicted  = `evicted`
+	EndShutdown = `shutdown`
+)
+
+// Config is embedded in capture configurations that support flow mode
+type Config struct {
+	Flow_Mode           bool   // emit flow summaries instead of packets
+	Flow_Idle_Timeout   string // flows with no packets for this long are closed, default 1m
+	Flow_Active_Timeout string // long lived flows emit an interim summary at this interval, default 30m
+	Flow_Max_Flows      int    // maximum tracked flows, the least recently active flow is evicted when full
+	Keep_Packet_Port    []int  // packets to or from these TCP/UDP ports are still ingested whole
+	Packet_Tag_Name     string // tag for kept packets, defaults to the flow tag
+}
+
+// Verify checks the flow settings and applies defaults
+func (c *Config) Verify() (err error) {
+	if _, err = c.idleTimeout(); err != nil {
+		return
+	} else if _, err = c.activeTimeout(); err != nil {
+		return
+	}
+	if c.Flow_Max_Flows < 0 {
+		return fmt.Errorf("Invalid Flow-Max-Flows %d", c.Flow_Max_Flows)
+	} else if c.Flow_Max_Flows == 0 {
+		c.Flow_Max_Flows = DefaultMaxFlows
+	}
+	for _, p := range c.Keep_Packet_Port {
+		if p <= 0 || p > 0xffff {
+			return fmt.Errorf("Invalid Keep-Packet-Port %d", p)
+		}
+	}
+	if c.Packet_Tag_Name != `` {
+		if err = ingest.CheckTag(c.Packet_Tag_Name); err != nil {
+			return fmt.Errorf("Invalid Packet-Tag-Name %q: %w", c.Packet_Tag_Name, err)
+		}
+	}
+	return
+}
+
+func (c *Config) idleTimeout() (time.Duration, error) {
+	return parseTimeout(c.Flow_Idle_Timeout, `Flow-Idle-Timeout`, DefaultIdleTimeout)
+}
+
+func (c *Config) activeTimeout() (time.Duration, error) {
+	return parseTimeout(c.Flow_Active_Timeout, `Flow-Active-Timeout`, DefaultActiveTimeout)
+}
+
+func parseTimeout(v, name string, def time.Duration) (time.Duration, error) {
+	if v == `` {
+		return def, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return 0, fmt.Errorf("Invalid %s %q: %w", name, v, err)
+	} else if d <= 0 {
+		return 0, fmt.Errorf("Invalid %s %q: must be positive", name, v)
+	}
+	return d, nil
+}
+
+// Flow is the summary emitted when a flow ends.
+// Src is the endpoint that initiated the flow.
+type Flow struct {
+	Proto      string    `json:"proto"`
+	Src        net.IP    `json:"src"`
+	SrcPort    uint16    `json:"src_port"`
+	Dst        net.IP    `json:"dst"`
+	DstPort    uint16    `json:"dst_port"`
+	Start      time.Time `json:"start"`
+	End        time.Time `json:"end"`
+	Duration   float64   `json:"duration"` // seconds
+	Packets    uint64    `json:"packets"`
+	Bytes      uint64    `json:"bytes"`
+	SrcPackets uint64    `json:"src_packets"`
+	SrcBytes   uint64    `json:"src_bytes"`
+	DstPackets uint64    `json:"dst_packets"`
+	DstBytes   uint64    `json:"dst_bytes"`
+	TCPFlags   string    `json:"tcp_flags,omitempty"`
+	EndReason  string    `json:"end_reason"`
+
+	// Meta holds protocol metadata extracted from the flow
+	Meta []entry.EnumeratedValue `json:"-"`
+}
+
+// Entry encodes the flow summary as JSON with the protocol metadata attached as enumerated values
+func (f *Flow) Entry(tag entry.EntryTag, src net.IP) (*entry.Entry, error) {
+	b, err := json.Marshal(f)
+	if err != nil {
+		return nil, err
+	}
+	ent := &entry.Entry{
+		TS:   entry.FromStandard(f.Start),
+		SRC:  src,
+		Tag:  tag,
+		Data: b,
+	}
+	if len(f.Meta) > 0 {
+		if err = ent.AddEnumeratedValues(f.Meta); err != nil {
+			return nil, err
+		}
+	}
+	return ent, nil
+}
+
+// Handler receives each flow as it ends, the Flow is not reused by the Tracker
+type Handler func(*Flow)
+
+type flowKey struct {
+	proto  layers.IPProtocol
+	lo, hi netip.AddrPort
+}
+
+type flow struct {
+	Flow
+	key      flowKey
+	src      netip.AddrPort // initiator
+	last     time.Time
+	flags    uint8
+	finSrc   bool
+	finDst   bool
+	closed   bool
+	elem     *list.Element
+	streams  [2]stream // initiator, responder
+	detector detector
+}
+
+// Tracker assembles packets into flows, it is not safe for concurrent use.
+// Timeouts are driven by packet timestamps so offline captures expire flows as they would have live.
+type Tracker struct {
+	decoder gopacket.Decoder
+	idle    time.Duration
+	active  time.Duration
+	linger  time.Duration // closeLinger, capped at the idle timeout
+	max     int
+	keep    map[uint16]bool
+	emit    Handler
+	flows   map[flowKey]*flow
+	lru     *list.List // least recently active flow at the front
+	now     time.Time
+}
+
+// NewTracker creates a tracker for packets of the given link type from a verified config
+func NewTracker(cfg Config, decoder gopacket.Decoder, emit Handler) (t *Tracker, err error) {
+	if emit == nil {
+		return nil, fmt.Errorf("nil flow handler")
+	}
+	t = &Tracker{
+		decoder: decoder,
+		max:     cfg.Flow_Max_Flows,
+		emit:    emit,
+		flows:   map[flowKey]*flow{},
+		lru:     list.New(),
+		keep:    map[uint16]bool{},
+	}
+	if t.max <= 0 {
+		t.max = DefaultMaxFlows
+	}
+	if t.idle, err = cfg.idleTimeout(); err != nil {
+		return nil, err
+	} else if t.active, err = cfg.activeTimeout(); err != nil {
+		return nil, err
+	}
+	if t.linger = closeLinger; t.idle < t.linger {
+		t.linger = t.idle
+	}
+	for _, p := range cfg.Keep_Packet_Port {
+		t.keep[uint16(p)] = true
+	}
+	return
+}
+
+// Len returns the number of flows currently tracked
+func (t *Tracker) Len() int {
+	return len(t.flows)
+}
+
+// Add processes a captured packet, keep is true if the packet matched a Keep-Packet-Port.
+// Packets that are not TCP or UDP over IP are ignored.
+func (t *Tracker) Add(data []byte, ci gopacket.CaptureInfo) (keep bool) {
+	pkt := gopacket.NewPacket(data, t.decoder, gopacket.DecodeOptions{Lazy: true, NoCopy: true})
+	var srcIP, dstIP netip.Addr
+	switch nl := pkt.NetworkLayer().(type) {
+	case *layers.IPv4:
+		srcIP, _ = netip.AddrFromSlice(nl.SrcIP)
+		dstIP, _ = netip.AddrFromSlice(nl.DstIP)
+	case *layers.IPv6:
+		srcIP, _ = netip.AddrFromSlice(nl.SrcIP)
+		dstIP, _ = netip.AddrFromSlice(nl.DstIP)
+	default:
+		return
+	}
+	var tcp *layers.TCP
+	var proto layers.IPProtocol
+	var sport, dport uint16
+	var payload []byte
+	switch tl := pkt.TransportLayer().(type) {
+	case *layers.TCP:
+		tcp = tl
+		proto = layers.IPProtocolTCP
+		sport, dport = uint16(tl.SrcPort), uint16(tl.DstPort)
+		payload = tl.Payload
+	case *layers.UDP:
+		proto = layers.IPProtocolUDP
+		sport, dport = uint16(tl.SrcPort), uint16(tl.DstPort)
+		payload = tl.Payload
+	default:
+		return
+	}
+	keep = t.keep[sport] || t.keep[dport]
+
+	ts := ci.Timestamp
+	if ts.After(t.now) {
+		t.now = ts
+	}
+	size := uint64(ci.Length)
+	if size == 0 {
+		size = uint64(len(data))
+	}
+
+	a, b := netip.AddrPortFrom(srcIP.Unmap(), sport), netip.AddrPortFrom(dstIP.Unmap(), dport)
+	key := flowKey{proto: proto, lo: a, hi: b}
+	if b.Compare(a) < 0 {
+		key.lo, key.hi = b, a
+	}
+
+	f, ok := t.flows[key]
+	if ok && f.closed {
+		// only a new SYN reopens a closed connection, anything else is trailing chatter
+		if tcp == nil || !tcp.SYN || tcp.ACK {
+			return
+		}
+		t.remove(f)
+		ok = false
+	}
+	if !ok {
+		f = t.newFlow(key, a, b, ts, tcp)
+	} else if ts.Sub(f.Start) >= t.active {
+		t.finish(f, EndActive)
+		f.reset(ts)
+	}
+
+	dir := 0
+	if a != f.src {
+		dir = 1
+	}
+	f.Packets++
+	f.Bytes += size
+	if dir == 0 {
+		f.SrcPackets++
+		f.SrcBytes += size
+	} else {
+		f.DstPackets++
+		f.DstBytes += size
+	}
+	if ts.After(f.last) {
+		f.last = ts
+	}
+	t.lru.MoveToBack(f.elem)
+
+	if tcp != nil {
+		f.flags |= tcpFlags(tcp)
+		f.streams[dir].add(tcp.Seq, tcp.SYN, payload)
+		f.detector.stream(dir, f.streams[dir].data(), f.streams[dir].stopped)
+		if f.detector.done() {
+			f.streams[0].release()
+			f.streams[1].release()
+		}
+		if tcp.RST {
+			t.close(f, EndRST)
+		} else if tcp.FIN {
+			if dir == 0 {
+				f.finSrc = true
+			} else {
+				f.finDst = true
+			}
+			if f.finSrc && f.finDst {
+				t.close(f, EndFIN)
+			}
+		}
+	} else if len(payload) > 0 {
+		f.detector.datagram(dir, payload)
+	}
+	return
+}
+
+func (t *Tracker) newFlow(key flowKey, a, b netip.AddrPort, ts time.Time, tcp *layers.TCP) *flow {
+	if len(t.flows) >= t.max {
+		if old := t.lru.Front(); old != nil {
+			of := old.Value.(*flow)
+			if !of.closed {
+				t.finish(of, EndEvicted)
+			}
+			t.remove(of)
+		}
+	}
+	// a SYN-ACK without the SYN means we joined mid handshake, the receiver initiated
+	src, dst := a, b
+	if tcp != nil && tcp.SYN && tcp.ACK {
+		src, dst = b, a
+	}
+	f := &flow{key: key, src: src}
+	f.Proto = protoName(key.proto)
+	f.Src, f.SrcPort = net.IP(src.Addr().AsSlice()), src.Port()
+	f.Dst, f.DstPort = net.IP(dst.Addr().AsSlice()), dst.Port()
+	f.reset(ts)
+	f.detector.init(key.proto, f.SrcPort, f.DstPort)
+	f.elem = t.lru.PushBack(f)
+	t.flows[key] = f
+	return f
+}
+
+// reset clears the counters for a new flow or the next interval of a long lived flow
+func (f *flow) reset(ts time.Time) {
+	f.Start, f.last = ts, ts
+	f.Packets, f.Bytes = 0, 0
+	f.SrcPackets, f.SrcBytes, f.DstPackets, f.DstBytes = 0, 0, 0, 0
+	f.flags = 0
+}
+
+// close ends a TCP connection, it lingers in the table to absorb trailing packets
+func (t *Tracker) close(f *flow, reason string) {
+	t.finish(f, reason)
+	f.closed = true
+	f.streams[0].release()
+	f.streams[1].release()
+}
+
+func (t *Tracker) remove(f *flow) {
+	t.lru.Remove(f.elem)
+	delete(t.flows, f.key)
+}
+
+// finish emits a copy of the flow summary
+func (t *Tracker) finish(f *flow, reason string) {
+	out := f.Flow
+	out.End = f.last
+	out.Duration = f.last.Sub(f.Start).Seconds()
+	out.TCPFlags = flagString(f.flags)
+	out.EndReason = reason
+	out.Meta = f.detector.meta()
+	t.emit(&out)
+}
+
+// Expire closes flows that have been idle since before now-Flow-Idle-Timeout.
+// Live captures should call it periodically with the current time, packet timestamps
+// advance the tracker's notion of now so offline reads only need it at the end of a batch.
+func (t *Tracker) Expire(now time.Time) {
+	if now.Before(t.now) {
+		now = t.now
+	}
+	for e := t.lru.Front(); e != nil; {
+		f := e.Value.(*flow)
+		e = e.Next()
+		age := now.Sub(f.last)
+		if age < t.linger {
+			break // everything behind this was active more recently
+		}
+		if f.closed {
+			t.remove(f)
+		} else if age >= t.idle {
+			t.finish(f, EndIdle)
+			t.remove(f)
+		}
+	}
+}
+
+// Flush emits every open flow, typically at shutdown or the end of a capture file
+func (t *Tracker) Flush() {
+	for e := t.lru.Front(); e != nil; e = e.Next() {
+		if f := e.Value.(*flow); !f.closed {
+			t.finish(f, EndShutdown)
+		}
+	}
+	t.flows = map[flowKey]*flow{}
+	t.lru.Init()
+}
+
+func protoName(p layers.IPProtocol) string {
+	switch p {
+	case layers.IPProtocolTCP:
+		return `tcp`
+	case layers.IPProtocolUDP:
+		return `udp`
+	}
+	return strconv.Itoa(int(p))
+}
+
+const (
+	flagFIN uint8 = 1 << iota
+	flagSYN
+	flagRST
+	flagPSH
+	flagACK
+	flagURG
+	flagECE
+	flagCWR
+)
+
+var flagNames = []string{`FIN`, `SYN`, `RST`, `PSH`, `ACK`, `URG`, `ECE`, `CWR`}
+
+func tcpFlags(tcp *layers.TCP) (f uint8) {
+	for i, set := range []bool{tcp.FIN, tcp.SYN, tcp.RST, tcp.PSH, tcp.ACK, tcp.URG, tcp.ECE, tcp.CWR} {
+		if set {
+			f |= 1 << i
+		}
+	}
+	return
+}
+
+func flagString(f uint8) string {
+	var names []string
+	for i, n := range flagNames {
+		if f&(1<<i) != 0 {
+			names = append(names, n)
+		}
+	}
+	return strings.Join(names, `,`)
+}
+
+// stream buffers the in-order start of one direction of a TCP connection
+type stream struct {
+	buf     []byte
+	next    uint32
+	started bool
+	stopped bool
+}
+
+func (s *stream) add(seq uint32, syn bool, payload []byte) {
+	if s.stopped {
+		return
+	}
+	if syn {
+		s.next, s.started = seq+1, true
+		return
+	}
+	if len(payload) == 0 {
+		return
+	}
+	if !s.started {
+		// joined mid stream, start with whatever we have
+		s.next, s.started = seq, true
+	}
+	off := int32(s.next - seq)
+	switch {
+	case off < 0:
+		// a gap, we won't see the missing bytes so stop buffering
+		s.stopped = true
+		return
+	case int(off) >= len(payload):
+		return // retransmission
+	}
+	payload = payload[off:]
+	if room := maxStreamBuffer - len(s.buf); len(payload) > room {
+		payload = payload[:room]
+		s.stopped = true
+	}
+	s.buf = append(s.buf, payload...)
+	s.next += uint32(len(payload))
+}
+
+func (s *stream) data() []byte {
+	return s.buf
+}
+
+func (s *stream) release() {
+	s.buf = nil
+	s.stopped = true
+}
@@ -0,0 +1,402 @@
+/*************************************************************************
+ * Copyright 2026 Gravwell, Inc. All rights reserved.
+ * Contact: <legal@gravwell.io>
+ *
+ * This software may be modified and distributed under the terms of the
+ * BSD 2-clause license. See the LICENSE file for details.
+ **************************************************************************/
+
+package flows
+
+import (
+	"crypto/md5"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"github.com/gravwell/gravwell/v3/ingest/entry"
+)
+
+var (
+	baseTime   = time.Date(2025, time.March, 4, 5, 6, 7, 0, time.UTC)
+	clientIP   = net.ParseIP("10.0.0.1").To4()
+	serverIP   = net.ParseIP("192.168.1.1").To4()
+	clientMAC  = net.HardwareAddr{0, 1, 2, 3, 4, 5}
+	serverMAC  = net.HardwareAddr{0, 1, 2, 3, 4, 6}
+	testConfig = Config{Flow_Mode: true}
+)
+
+type collector struct {
+	t     *testing.T
+	tr    *Tracker
+	flows []*Flow
+	ts    time.Time
+}
+
+func newCollector(t *testing.T, cfg Config) *collector {
+	t.Helper()
+	if err := cfg.Verify(); err != nil {
+		t.Fatal(err)
+	}
+	c := &collector{t: t, ts: baseTime}
+	var err error
+	if c.tr, err = NewTracker(cfg, layers.LinkTypeEthernet, func(f *Flow) { c.flows = append(c.flows, f) }); err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func (c *collector) add(data []byte) bool {
+	c.ts = c.ts.Add(10 * time.Millisecond)
+	return c.tr.Add(data, gopacket.CaptureInfo{Timestamp: c.ts, CaptureLength: len(data), Length: len(data)})
+}
+
+func (c *collector) serialize(fromClient bool, l ...gopacket.SerializableLayer) []byte {
+	c.t.Helper()
+	eth := &layers.Ethernet{SrcMAC: clientMAC, DstMAC: serverMAC, EthernetType: layers.EthernetTypeIPv4}
+	if !fromClient {
+		eth.SrcMAC, eth.DstMAC = serverMAC, clientMAC
+	}
+	buf := gopacket.NewSerializeBuffer()
+	if err := gopacket.SerializeLayers(buf, gopacket.SerializeOptions{FixLengths: true, ComputeChecksums: true}, append([]gopacket.SerializableLayer{eth}, l...)...); err != nil {
+		c.t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func (c *collector) ip(fromClient bool, proto layers.IPProtocol) *layers.IPv4 {
+	ip := &layers.IPv4{Version: 4, TTL: 64, Protocol: proto, SrcIP: clientIP, DstIP: serverIP}
+	if !fromClient {
+		ip.SrcIP, ip.DstIP = serverIP, clientIP
+	}
+	return ip
+}
+
+// tcp builds a segment between the client on port 40000 and the server on port dport
+func (c *collector) tcp(fromClient bool, dport uint16, seq uint32, flags string, payload []byte) []byte {
+	ip := c.ip(fromClient, layers.IPProtocolTCP)
+	tcp := &layers.TCP{SrcPort: 40000, DstPort: layers.TCPPort(dport), Seq: seq, Window: 1024}
+	if !fromClient {
+		tcp.SrcPort, tcp.DstPort = tcp.DstPort, tcp.SrcPort
+	}
+	for _, f := range flags {
+		switch f {
+		case 'S':
+			tcp.SYN = true
+		case 'A':
+			tcp.ACK = true
+		case 'F':
+			tcp.FIN = true
+		case 'R':
+			tcp.RST = true
+		case 'P':
+			tcp.PSH = true
+		}
+	}
+	tcp.SetNetworkLayerForChecksum(ip)
+	return c.serialize(fromClient, ip, tcp, gopacket.Payload(payload))
+}
+
+func (c *collector) udp(fromClient bool, dport uint16, payload []byte) []byte {
+	ip := c.ip(fromClient, layers.IPProtocolUDP)
+	udp := &layers.UDP{SrcPort: 40000, DstPort: layers.UDPPort(dport)}
+	if !fromClient {
+		udp.SrcPort, udp.DstPort = udp.DstPort, udp.SrcPort
+	}
+	udp.SetNetworkLayerForChecksum(ip)
+	return c.serialize(fromClient, ip, udp, gopacket.Payload(payload))
+}
+
+func metaMap(evs []entry.EnumeratedValue) map[string]string {
+	r := map[string]string{}
+	for _, ev := range evs {
+		r[ev.Name] = ev.Value.String()
+	}
+	return r
+}
+
+func checkMeta(t *testing.T, f *Flow, want map[string]string) {
+	t.Helper()
+	got := metaMap(f.Meta)
+	if len(got) != len(want) {
+		t.Fatalf("got metadata %v, expected %v", got, want)
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Fatalf("%s = %q, expected %q", k, got[k], v)
+		}
+	}
+}
+
+func TestHTTPFlow(t *testing.T) {
+	c := newCollector(t, testConfig)
+	req := []byte("GET /index.html HTTP/1.1\r\nHost: example.com\r\nUser-Agent: test/1.0\r\n\r\n")
+	resp := []byte("HTTP/1.1 404 Not Found\r\nContent-Length: 0\r\n\r\n")
+	c.add(c.tcp(true, 80, 100, "S", nil))
+	c.add(c.tcp(false, 80, 500, "SA", nil))
+	c.add(c.tcp(true, 80, 101, "A", nil))
+	c.add(c.tcp(true, 80, 101, "PA", req[:20]))
+	c.add(c.tcp(true, 80, 101, "PA", req[:20])) // retransmission
+	c.add(c.tcp(true, 80, 121, "PA", req[20:]))
+	c.add(c.tcp(false, 80, 501, "PA", resp))
+	c.add(c.tcp(true, 80, uint32(101+len(req)), "FA", nil))
+	if len(c.flows) != 0 {
+		t.Fatal("flow emitted before both sides closed")
+	}
+	c.add(c.tcp(false, 80, uint32(501+len(resp)), "FA", nil))
+	c.add(c.tcp(true, 80, uint32(102+len(req)), "A", nil)) // trailing ack belongs to the closed flow
+	if len(c.flows) != 1 {
+		t.Fatalf("expected 1 flow, got %d", len(c.flows))
+	}
+	f := c.flows[0]
+	if f.Proto != `tcp` || !f.Src.Equal(clientIP) || f.SrcPort != 40000 || !f.Dst.Equal(serverIP) || f.DstPort != 80 {
+		t.Fatalf("bad 5-tuple %+v", f)
+	} else if f.Packets != 9 || f.SrcPackets != 6 || f.DstPackets != 3 {
+		t.Fatalf("bad packet counts %+v", f)
+	} else if f.Bytes != f.SrcBytes+f.DstBytes || f.Bytes == 0 {
+		t.Fatalf("bad byte counts %+v", f)
+	} else if f.TCPFlags != `FIN,SYN,PSH,ACK` || f.EndReason != EndFIN {
+		t.Fatalf("bad flags or reason %+v", f)
+	} else if f.Duration != f.End.Sub(f.Start).Seconds() || f.Duration <= 0 {
+		t.Fatalf("bad duration %+v", f)
+	}
+	checkMeta(t, f, map[string]string{
+		`http.method`:     `GET`,
+		`http.uri`:        `/index.html`,
+		`http.version`:    `HTTP/1.1`,
+		`http.host`:       `example.com`,
+		`http.user_agent`: `test/1.0`,
+		`http.status`:     `404`,
+	})
+
+	// the closed flow lingers and is then dropped without another summary
+	c.tr.Expire(c.ts.Add(time.Hour))
+	if c.tr.Len() != 0 || len(c.flows) != 1 {
+		t.Fatalf("closed flow not cleaned up: %d tracked, %d emitted", c.tr.Len(), len(c.flows))
+	}
+}
+
+func TestRSTAndReopen(t *testing.T) {
+	c := newCollector(t, testConfig)
+	c.add(c.tcp(true, 22, 1, "S", nil))
+	c.add(c.tcp(false, 22, 1, "R", nil))
+	// the same 5-tuple is reused right away
+	c.add(c.tcp(true, 22, 1000, "S", nil))
+	c.tr.Flush()
+	if len(c.flows) != 2 {
+		t.Fatalf("expected 2 flows, got %d", len(c.flows))
+	} else if c.flows[0].EndReason != EndRST || c.flows[1].EndReason != EndShutdown {
+		t.Fatalf("bad end reasons %s %s", c.flows[0].EndReason, c.flows[1].EndReason)
+	} else if c.flows[1].Packets != 1 {
+		t.Fatalf("reopened flow has %d packets", c.flows[1].Packets)
+	}
+}
+
+func clientHello() []byte {
+	u16 := func(v int) []byte { return binary.BigEndian.AppendUint16(nil, uint16(v)) }
+	ext := func(typ int, body []byte) []byte { return append(append(u16(typ), u16(len(body))...), body...) }
+
+	name := []byte(`www.example.com`)
+	sni := append(append(u16(len(name)+3), 0), append(u16(len(name)), name...)...)
+	groups := append(u16(6), 0x2a, 0x2a, 0x00, 0x1d, 0x00, 0x17)
+	alpn := []byte{2, 'h', '2', 8, 'h', 't', 't', 'p', '/', '1', '.', '1'}
+	alpn = append(u16(len(alpn)), alpn...)
+	var exts []byte
+	exts = append(exts, ext(0x0a0a, nil)...)
+	exts = append(exts, ext(0, sni)...)
+	exts = append(exts, ext(10, groups)...)
+	exts = append(exts, ext(11, []byte{1, 0})...)
+	exts = append(exts, ext(16, alpn)...)
+
+	body := u16(0x0303)
+	body = append(body, make([]byte, 32)...)                      // random
+	body = append(body, 0)                                        // session id
+	body = append(body, 0, 6, 0x0a, 0x0a, 0x13, 0x01, 0xc0, 0x2f) // ciphers
+	body = append(body, 1, 0)                                     // compression
+	body = append(body, u16(len(exts))...)
+	body = append(body, exts...)
+
+	hs := append([]byte{1, 0, byte(len(body) >> 8), byte(len(body))}, body...)
+	return append(append([]byte{0x16, 0x03, 0x01}, u16(len(hs))...), hs...)
+}
+
+func TestTLSFlow(t *testing.T) {
+	c := newCollector(t, testConfig)
+	ch := clientHello()
+	// capture starts mid connection, the hello spans two segments
+	c.add(c.tcp(true, 443, 5000, "PA", ch[:30]))
+	c.add(c.tcp(true, 443, 5030, "PA", ch[30:]))
+	c.add(c.tcp(false, 443, 9000, "PA", []byte{0x16, 0x03, 0x03, 0, 0}))
+	c.tr.Expire(c.ts.Add(30 * time.Second))
+	if len(c.flows) != 0 {
+		t.Fatal("flow expired early")
+	}
+	c.tr.Expire(c.ts.Add(DefaultIdleTimeout))
+	if len(c.flows) != 1 {
+		t.Fatalf("expected 1 flow, got %d", len(c.flows))
+	}
+	f := c.flows[0]
+	if f.EndReason != EndIdle || f.DstPort != 443 {
+		t.Fatalf("bad flow %+v", f)
+	}
+	ja3 := `771,4865-49199,0-10-11-16,29-23,0`
+	sum := md5.Sum([]byte(ja3))
+	checkMeta(t, f, map[string]string{
+		`tls.sni`:        `www.example.com`,
+		`tls.alpn`:       `h2,http/1.1`,
+		`tls.ja3`:        hex.EncodeToString(sum[:]),
+		`tls.ja3_string`: ja3,
+	})
+}
+
+func TestDNSFlow(t *testing.T) {
+	c := newCollector(t, Config{Keep_Packet_Port: []int{53}})
+	q := &layers.DNS{
+		ID:        7,
+		RD:        true,
+		Questions: []layers.DNSQuestion{{Name: []byte(`example.com`), Type: layers.DNSTypeA, Class: layers.DNSClassIN}},
+	}
+	r := *q
+	r.QR = true
+	r.Answers = []layers.DNSResourceRecord{
+		{Name: []byte(`example.com`), Type: layers.DNSTypeCNAME, Class: layers.DNSClassIN, TTL: 60, CNAME: []byte(`edge.example.net`)},
+		{Name: []byte(`edge.example.net`), Type: layers.DNSTypeA, Class: layers.DNSClassIN, TTL: 60, IP: net.ParseIP("93.184.216.34").To4()},
+	}
+	encode := func(d *layers.DNS) []byte {
+		buf := gopacket.NewSerializeBuffer()
+		if err := d.SerializeTo(buf, gopacket.SerializeOptions{FixLengths: true}); err != nil {
+			t.Fatal(err)
+		}
+		return buf.Bytes()
+	}
+	if !c.add(c.udp(true, 53, encode(q))) || !c.add(c.udp(false, 53, encode(&r))) {
+		t.Fatal("DNS packets were not kept")
+	}
+	if c.add(c.udp(true, 123, []byte{0})) {
+		t.Fatal("NTP packet was kept")
+	}
+	c.tr.Flush()
+	if len(c.flows) != 2 {
+		t.Fatalf("expected 2 flows, got %d", len(c.flows))
+	}
+	var f *Flow
+	for _, fl := range c.flows {
+		if fl.DstPort == 53 {
+			f = fl
+		}
+	}
+	if f == nil || f.Proto != `udp` || f.Packets != 2 || f.TCPFlags != `` || f.EndReason != EndShutdown {
+		t.Fatalf("bad DNS flow %+v", f)
+	}
+	checkMeta(t, f, map[string]string{
+		`dns.query`:  `example.com`,
+		`dns.type`:   `A`,
+		`dns.rcode`:  `No Error`,
+		`dns.answer`: `edge.example.net,93.184.216.34`,
+	})
+}
+
+func TestNonIP(t *testing.T) {
+	c := newCollector(t, testConfig)
+	arp := &layers.ARP{
+		AddrType:          layers.LinkTypeEthernet,
+		Protocol:          layers.EthernetTypeIPv4,
+		HwAddressSize:     6,
+		ProtAddressSize:   4,
+		Operation:         layers.ARPRequest,
+		SourceHwAddress:   clientMAC,
+		SourceProtAddress: clientIP,
+		DstHwAddress:      make([]byte, 6),
+		DstProtAddress:    serverIP,
+	}
+	eth := &layers.Ethernet{SrcMAC: clientMAC, DstMAC: layers.EthernetBroadcast, EthernetType: layers.EthernetTypeARP}
+	buf := gopacket.NewSerializeBuffer()
+	if err := gopacket.SerializeLayers(buf, gopacket.SerializeOptions{FixLengths: true}, eth, arp); err != nil {
+		t.Fatal(err)
+	}
+	c.add(buf.Bytes())
+	c.add([]byte{1, 2, 3})
+	if c.tr.Len() != 0 {
+		t.Fatal("non IP packet created a flow")
+	}
+}
+
+func TestActiveTimeoutAndEviction(t *testing.T) {
+	c := newCollector(t, Config{Flow_Active_Timeout: `1s`, Flow_Max_Flows: 1})
+	for i := 0; i < 150; i++ {
+		c.add(c.udp(true, 514, []byte(`syslog`)))
+	}
+	if len(c.flows) != 1 || c.flows[0].EndReason != EndActive || c.flows[0].Packets != 100 {
+		t.Fatalf("bad interim flow %+v", c.flows)
+	}
+	// a second flow evicts the first
+	c.add(c.udp(true, 123, []byte{0}))
+	if len(c.flows) != 2 || c.flows[1].EndReason != EndEvicted || c.flows[1].Packets != 50 || c.tr.Len() != 1 {
+		t.Fatalf("bad evicted flow %+v", c.flows)
+	}
+}
+
+func TestShortIdleTimeout(t *testing.T) {
+	// idle timeouts shorter than the close linger must still be honored
+	c := newCollector(t, Config{Flow_Idle_Timeout: `2s`})
+	c.add(c.udp(true, 514, []byte(`syslog`)))
+	c.tr.Expire(c.ts.Add(time.Second))
+	if len(c.flows) != 0 {
+		t.Fatalf("flow expired early %+v", c.flows)
+	}
+	c.tr.Expire(c.ts.Add(2 * time.Second))
+	if len(c.flows) != 1 || c.flows[0].EndReason != EndIdle || c.tr.Len() != 0 {
+		t.Fatalf("flow not idled out %+v", c.flows)
+	}
+}
+
+func TestConfig(t *testing.T) {
+	for _, c := range []Config{
+		{Flow_Idle_Timeout: `bad`},
+		{Flow_Active_Timeout: `-1s`},
+		{Flow_Max_Flows: -1},
+		{Keep_Packet_Port: []int{70000}},
+		{Packet_Tag_Name: `bad tag`},
+	} {
+		if err := c.Verify(); err == nil {
+			t.Fatalf("accepted bad config %+v", c)
+		}
+	}
+	c := Config{Flow_Idle_Timeout: `10s`}
+	if err := c.Verify(); err != nil {
+		t.Fatal(err)
+	} else if c.Flow_Max_Flows != DefaultMaxFlows {
+		t.Fatal("default max flows not applied")
+	}
+}
+
+func TestEntry(t *testing.T) {
+	f := Flow{
+		Proto:   `udp`,
+		Src:     clientIP,
+		Dst:     serverIP,
+		Start:   baseTime,
+		Packets: 1,
+		Meta:    []entry.EnumeratedValue{{Name: `dns.query`, Value: entry.StringEnumData(`example.com`)}},
+	}
+	ent, err := f.Entry(3, serverIP)
+	if err != nil {
+		t.Fatal(err)
+	} else if ent.Tag != 3 || !ent.SRC.Equal(serverIP) || !ent.TS.StandardTime().Equal(baseTime) {
+		t.Fatalf("bad entry %+v", ent)
+	}
+	var got Flow
+	if err = json.Unmarshal(ent.Data, &got); err != nil {
+		t.Fatal(err)
+	} else if !got.Src.Equal(clientIP) || got.Proto != `udp` || got.Packets != 1 {
+		t.Fatalf("bad flow json %s", ent.Data)
+	}
+	if evs := ent.EnumeratedValues(); len(evs) != 1 || evs[0].Name != `dns.query` {
+		t.Fatalf("bad enumerated values %v", evs)
+	}
+}
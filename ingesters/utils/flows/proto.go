@@ -0,0 +1,394 @@
+/*************************************************************************
+ * Copyright 2026 Gravwell, Inc. All rights reserved.
+ * Contact: <legal@gravwell.io>
+ *
+ * This software may be modified and distributed under the terms of the
+ * BSD 2-clause license. See the LICENSE file for details.
+ **************************************************************************/
+
+package flows
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/binary"
+	"encoding/hex"
+	"strconv"
+	"strings"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"github.com/gravwell/gravwell/v3/ingest/entry"
+)
+
+const (
+	maxDNSAnswers = 16
+)
+
+const (
+	parseMore = iota // need more data
+	parseNo          // not this protocol
+	parseOK
+)
+
+var httpMethods = []string{`GET`, `POST`, `PUT`, `DELETE`, `HEAD`, `OPTIONS`, `PATCH`, `CONNECT`, `TRACE`}
+
+// detector extracts protocol metadata from the start of a flow
+type detector struct {
+	dns bool
+
+	reqDone  bool // initiator side identified or given up on
+	respDone bool // responder side identified or given up on
+	isHTTP   bool
+
+	dnsQuery   string
+	dnsType    string
+	dnsRcode   string
+	dnsAnswers []string
+
+	httpMethod  string
+	httpURI     string
+	httpVersion string
+	httpHost    string
+	httpUA      string
+	httpStatus  int
+
+	tlsSNI  string
+	tlsJA3  string
+	tlsALPN string
+}
+
+func (d *detector) init(proto layers.IPProtocol, sport, dport uint16) {
+	if proto == layers.IPProtocolUDP {
+		d.dns = isDNSPort(sport) || isDNSPort(dport)
+		d.reqDone, d.respDone = true, true
+	}
+}
+
+func isDNSPort(p uint16) bool {
+	return p == 53 || p == 5353
+}
+
+func (d *detector) done() bool {
+	return d.reqDone && d.respDone
+}
+
+// stream inspects the reassembled start of one direction of a TCP connection
+// final indicates that no more data will be buffered for the direction.
+func (d *detector) stream(dir int, b []byte, final bool) {
+	if len(b) == 0 {
+		return
+	}
+	if dir == 0 {
+		if d.reqDone {
+			return
+		}
+		if r := d.httpRequest(b, final); r != parseNo {
+			d.reqDone = r == parseOK
+			return
+		}
+		r := d.clientHello(b)
+		d.reqDone = r != parseMore || final
+		if d.reqDone {
+			// TLS and unknown protocols carry nothing we parse on the response side
+			d.respDone = d.respDone || !d.isHTTP
+		}
+		return
+	}
+	if d.respDone {
+		return
+	}
+	if !bytes.HasPrefix(b, []byte(`HTTP/`)) {
+		if len(b) >= 5 || !bytes.HasPrefix([]byte(`HTTP/`), b) {
+			d.respDone = true
+		}
+		return
+	}
+	eol := bytes.Index(b, []byte("\r\n"))
+	if eol < 0 && !final {
+		return
+	}
+	d.respDone = true
+	if eol < 0 {
+		eol = len(b)
+	}
+	if flds := strings.Fields(string(b[:eol])); len(flds) >= 2 {
+		if code, err := strconv.Atoi(flds[1]); err == nil {
+			d.httpStatus = code
+			d.isHTTP = true
+		}
+	}
+}
+
+func (d *detector) httpRequest(b []byte, final bool) int {
+	sp := bytes.IndexByte(b, ' ')
+	if sp < 0 {
+		if len(b) < 8 {
+			for _, m := range httpMethods {
+				if strings.HasPrefix(m, string(b)) {
+					return parseMore
+				}
+			}
+		}
+		return parseNo
+	}
+	var ok bool
+	for _, m := range httpMethods {
+		if string(b[:sp]) == m {
+			ok = true
+			break
+		}
+	}
+	if !ok {
+		return parseNo
+	}
+	hdrEnd := bytes.Index(b, []byte("\r\n\r\n"))
+	if hdrEnd < 0 {
+		if !final {
+			return parseMore
+		}
+		hdrEnd = len(b)
+	}
+	lines := strings.Split(string(b[:hdrEnd]), "\r\n")
+	flds := strings.Fields(lines[0])
+	if len(flds) != 3 || !strings.HasPrefix(flds[2], `HTTP/`) {
+		return parseNo
+	}
+	d.isHTTP = true
+	d.httpMethod, d.httpURI, d.httpVersion = flds[0], flds[1], flds[2]
+	for _, l := range lines[1:] {
+		k, v, ok := strings.Cut(l, `:`)
+		if !ok {
+			continue
+		}
+		switch strings.ToLower(strings.TrimSpace(k)) {
+		case `host`:
+			d.httpHost = strings.TrimSpace(v)
+		case `user-agent`:
+			d.httpUA = strings.TrimSpace(v)
+		}
+	}
+	return parseOK
+}
+
+// clientHello finds a TLS ClientHello, which may span several records, and extracts the SNI and JA3
+func (d *detector) clientHello(b []byte) int {
+	var hs []byte
+	for {
+		if len(b) < 5 {
+			return parseMore
+		} else if b[0] != 0x16 || b[1] != 0x03 {
+			return parseNo
+		}
+		rl := int(binary.BigEndian.Uint16(b[3:5]))
+		if len(b) < 5+rl {
+			return parseMore
+		}
+		hs = append(hs, b[5:5+rl]...)
+		b = b[5+rl:]
+		if len(hs) < 4 {
+			continue
+		} else if hs[0] != 1 {
+			return parseNo
+		}
+		hl := int(hs[1])<<16 | int(hs[2])<<8 | int(hs[3])
+		if len(hs) >= 4+hl {
+			if !d.parseClientHello(hs[4 : 4+hl]) {
+				return parseNo
+			}
+			return parseOK
+		}
+	}
+}
+
+// greaseValue identifies the RFC 8701 GREASE values that JA3 ignores
+func greaseValue(v uint16) bool {
+	return v&0x0f0f == 0x0a0a && v>>8 == v&0xff
+}
+
+type hsReader struct {
+	b   []byte
+	bad bool
+}
+
+func (r *hsReader) next(n int) []byte {
+	if r.bad || len(r.b) < n {
+		r.bad = true
+		return nil
+	}
+	v := r.b[:n]
+	r.b = r.b[n:]
+	return v
+}
+
+func (r *hsReader) u8() int {
+	if v := r.next(1); v != nil {
+		return int(v[0])
+	}
+	return 0
+}
+
+func (r *hsReader) u16() int {
+	if v := r.next(2); v != nil {
+		return int(binary.BigEndian.Uint16(v))
+	}
+	return 0
+}
+
+func u16List(b []byte) (vals []string) {
+	for i := 0; i+1 < len(b); i += 2 {
+		if v := binary.BigEndian.Uint16(b[i:]); !greaseValue(v) {
+			vals = append(vals, strconv.Itoa(int(v)))
+		}
+	}
+	return
+}
+
+func (d *detector) parseClientHello(b []byte) bool {
+	r := hsReader{b: b}
+	version := r.u16()
+	r.next(32)     // random
+	r.next(r.u8()) // session id
+	ciphers := u16List(r.next(r.u16()))
+	r.next(r.u8()) // compression methods
+	if r.bad {
+		return false
+	}
+	var exts, groups, points []string
+	if len(r.b) >= 2 {
+		er := hsReader{b: r.next(r.u16())}
+		for len(er.b) > 0 && !er.bad {
+			typ := uint16(er.u16())
+			body := er.next(er.u16())
+			if er.bad {
+				break
+			}
+			if !greaseValue(typ) {
+				exts = append(exts, strconv.Itoa(int(typ)))
+			}
+			switch typ {
+			case 0: // server_name
+				sr := hsReader{b: body}
+				sr.u16()
+				for len(sr.b) > 0 && !sr.bad {
+					nt := sr.u8()
+					name := sr.next(sr.u16())
+					if nt == 0 && !sr.bad {
+						d.tlsSNI = string(name)
+						break
+					}
+				}
+			case 10: // supported_groups
+				gr := hsReader{b: body}
+				groups = u16List(gr.next(gr.u16()))
+			case 11: // ec_point_formats
+				pr := hsReader{b: body}
+				for _, p := range pr.next(pr.u8()) {
+					points = append(points, strconv.Itoa(int(p)))
+				}
+			case 16: // application_layer_protocol_negotiation
+				ar := hsReader{b: body}
+				ar = hsReader{b: ar.next(ar.u16())}
+				var protos []string
+				for len(ar.b) > 0 && !ar.bad {
+					if p := ar.next(ar.u8()); !ar.bad {
+						protos = append(protos, string(p))
+					}
+				}
+				d.tlsALPN = strings.Join(protos, `,`)
+			}
+		}
+	}
+	d.tlsJA3 = strings.Join([]string{
+		strconv.Itoa(version),
+		strings.Join(ciphers, `-`),
+		strings.Join(exts, `-`),
+		strings.Join(groups, `-`),
+		strings.Join(points, `-`),
+	}, `,`)
+	return true
+}
+
+// datagram inspects a UDP payload
+func (d *detector) datagram(dir int, b []byte) {
+	if !d.dns {
+		return
+	}
+	var dns layers.DNS
+	if err := dns.DecodeFromBytes(b, gopacket.NilDecodeFeedback); err != nil {
+		return
+	}
+	if d.dnsQuery == `` && len(dns.Questions) > 0 {
+		d.dnsQuery = string(dns.Questions[0].Name)
+		d.dnsType = dns.Questions[0].Type.String()
+	}
+	if !dns.QR {
+		return
+	}
+	d.dnsRcode = dns.ResponseCode.String()
+	for _, a := range dns.Answers {
+		var v string
+		switch a.Type {
+		case layers.DNSTypeA, layers.DNSTypeAAAA:
+			v = a.IP.String()
+		case layers.DNSTypeCNAME:
+			v = string(a.CNAME)
+		case layers.DNSTypePTR:
+			v = string(a.PTR)
+		case layers.DNSTypeNS:
+			v = string(a.NS)
+		case layers.DNSTypeMX:
+			v = string(a.MX.Name)
+		case layers.DNSTypeTXT:
+			var txts []string
+			for _, t := range a.TXTs {
+				txts = append(txts, string(t))
+			}
+			v = strings.Join(txts, ` `)
+		default:
+			continue
+		}
+		if len(d.dnsAnswers) >= maxDNSAnswers {
+			break
+		}
+		var dup bool
+		for _, ex := range d.dnsAnswers {
+			if ex == v {
+				dup = true
+				break
+			}
+		}
+		if !dup {
+			d.dnsAnswers = append(d.dnsAnswers, v)
+		}
+	}
+}
+
+// meta returns the extracted metadata as enumerated values
+func (d *detector) meta() (evs []entry.EnumeratedValue) {
+	add := func(name, v string) {
+		if v != `` {
+			evs = append(evs, entry.EnumeratedValue{Name: name, Value: entry.StringEnumData(v)})
+		}
+	}
+	add(`dns.query`, d.dnsQuery)
+	add(`dns.type`, d.dnsType)
+	add(`dns.rcode`, d.dnsRcode)
+	add(`dns.answer`, strings.Join(d.dnsAnswers, `,`))
+	add(`http.method`, d.httpMethod)
+	add(`http.uri`, d.httpURI)
+	add(`http.version`, d.httpVersion)
+	add(`http.host`, d.httpHost)
+	add(`http.user_agent`, d.httpUA)
+	if d.httpStatus != 0 {
+		evs = append(evs, entry.EnumeratedValue{Name: `http.status`, Value: entry.IntEnumData(d.httpStatus)})
+	}
+	add(`tls.sni`, d.tlsSNI)
+	add(`tls.alpn`, d.tlsALPN)
+	if d.tlsJA3 != `` {
+		sum := md5.Sum([]byte(d.tlsJA3))
+		add(`tls.ja3`, hex.EncodeToString(sum[:]))
+		add(`tls.ja3_string`, d.tlsJA3)
+	}
+	return
+}
@@ -29,6 +29,8 @@ import (
 	"github.com/gravwell/gravwell/v3/ingest/entry"
 	"github.com/gravwell/gravwell/v3/ingest/processors"
 	"github.com/gravwell/gravwell/v3/timegrinder"
+	"github.com/klauspost/compress/zstd"
+	"github.com/ulikunitz/xz"
 )
 
 const (
@@ -46,6 +48,11 @@ const (
 	bzip2Magic2 = 'Z'
 )
 
+var (
+	xzMagic   = []byte{0xfd, '7', 'z', 'X', 'Z', 0x00}
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+)
+
 var (
 	errInvalidColumns = errors.New("invalid csv import columns")
 	csvCols           = []string{`Timestamp`, `Source`, `Tag`, `Data`}
@@ -54,8 +61,9 @@ var (
 )
 
 // NewCompressedReader wraps an io.Reader in a bufio.Reader and automatically
-// detects and handles gzip, bzip2, or raw data streams. It returns an io.Reader
+// detects and handles gzip, bzip2, xz, zstd, or raw data streams. It returns an io.Reader
 // that transparently decompresses the data if compression is detected.
+// Callers should release the reader with CloseCompressedReader.
 func NewCompressedReader(r io.Reader) (io.Reader, error) {
 	if r == nil {
 		return nil, errors.New("nil reader")
@@ -63,8 +71,8 @@ func NewCompressedReader(r io.Reader) (io.Reader, error) {
 
 	br := bufio.NewReader(r)
 
-	// Peek at the first 2 bytes to detect compression format
-	header, err := br.Peek(2)
+	// Peek at the first bytes to detect compression format
+	header, err := br.Peek(len(xzMagic))
 	if err != nil && err != io.EOF {
 		return nil, errPeekFailed
 	}
@@ -84,10 +92,29 @@ func NewCompressedReader(r io.Reader) (io.Reader, error) {
 		return bzip2.NewReader(br), nil
 	}
 
+	if bytes.HasPrefix(header, xzMagic) {
+		return xz.NewReader(br)
+	}
+
+	if bytes.HasPrefix(header, zstdMagic) {
+		return zstd.NewReader(br, zstd.WithDecoderConcurrency(1))
+	}
+
 	// No compression detected, return the buffered reader
 	return br, nil
 }
 
+// CloseCompressedReader releases any decompressor returned by NewCompressedReader,
+// the zstd decoder holds a goroutine until it is closed. The underlying reader is not closed.
+func CloseCompressedReader(r io.Reader) {
+	switch c := r.(type) {
+	case io.Closer:
+		c.Close()
+	case interface{ Close() }:
+		c.Close()
+	}
+}
+
 type TagHandler interface {
 	OverrideTags(entry.EntryTag)
 	GetTag(string) (entry.EntryTag, error)
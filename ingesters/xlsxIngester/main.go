@@ -10,13 +10,13 @@ package main
 
 import (
 	"bytes"
-	"encoding/csv"
 	"flag"
 	"fmt"
 	"log"
 	"net"
 	"os"
 	"runtime/debug"
+	"strings"
 	"time"
 
 	// Embed tzdata so that we don't rely on potentially broken timezone DBs on the host
@@ -29,8 +29,6 @@ import (
 	"github.com/gravwell/gravwell/v3/ingesters/version"
 	"github.com/gravwell/gravwell/v3/timegrinder"
 
-	"github.com/tealeg/xlsx"
-
 	gravwelldebug "github.com/gravwell/gravwell/v3/debug"
 )
 
@@ -42,7 +40,7 @@ const (
 var (
 	tso       = flag.String("timestamp-override", "", "Timestamp override")
 	tzo       = flag.String("timezone-override", "", "Timezone override e.g. America/Chicago")
-	inFile    = flag.String("i", "", "Input XLSX file to process")
+	inFile    = flag.String("i", "", "Input XLSX, ODS, or CSV file to process")
 	ver       = flag.Bool("version", false, "Print version and exit")
 	utc       = flag.Bool("utc", false, "Assume UTC time")
 	ignoreTS  = flag.Bool("ignore-ts", false, "Ignore timestamp")
@@ -53,6 +51,11 @@ var (
 	status    = flag.Bool("status", false, "Output ingest rate stats as we go")
 	srcOvr    = flag.String("source-override", "", "Override source with address, hash, or integer")
 	skipFirst = flag.Bool("skip-first", false, "Skip first entry of each sheet")
+	inFormat  = flag.String("input-format", inputAuto, "Input format: auto, xlsx, csv, or ods, auto selects by file extension")
+	sheets    = flag.String("sheets", "", "Comma separated list of sheet names or globs to ingest, empty ingests every sheet")
+	exSheets  = flag.String("exclude-sheets", "", "Comma separated list of sheet names or globs to skip")
+	headerRow = flag.Int("header-row", 0, "Row number holding column names, rows are emitted as JSON objects keyed by the names, 0 emits CSV")
+	tsColumn  = flag.String("ts-column", "", "Column holding the entry timestamp, by header name, column letter, or 1 based column number")
 
 	nlBytes          = []byte("\n")
 	count            uint64
@@ -66,7 +69,7 @@ var (
 	start            time.Time
 )
 
-func init() {
+func parseFlags() {
 	flag.Parse()
 	if *ver {
 		version.PrintVersion(os.Stdout)
@@ -86,6 +89,7 @@ func init() {
 }
 
 func main() {
+	parseFlags()
 	go gravwelldebug.HandleDebugSignals("xlsx")
 	debug.SetTraceback("all")
 	if *inFile == "" {
@@ -112,10 +116,29 @@ func main() {
 		}
 	}
 
-	var fin *xlsx.File
-	if fin, err = xlsx.OpenFile(*inFile); err != nil {
+	ss, err := newSheetSelector(*sheets, *exSheets)
+	if err != nil {
+		log.Fatal(err)
+	}
+	enc, err := newRowEncoder(*headerRow, *skipFirst, *tsColumn)
+	if err != nil {
+		log.Fatalf("Invalid timestamp column %q: %v\n", *tsColumn, err)
+	}
+	if *tsColumn != `` && noTg {
+		log.Fatal("Timestamp column and ignore timestamps are mutually exclusive")
+	}
+	loc := time.UTC
+	if *tzo != `` {
+		if loc, err = time.LoadLocation(*tzo); err != nil {
+			log.Fatalf("Invalid timezone override: %v\n", err)
+		}
+	}
+
+	fin, err := openTable(*inFile, strings.ToLower(*inFormat), loc)
+	if err != nil {
 		log.Fatalf("Failed to open %s: %v\n", *inFile, err)
 	}
+	defer fin.Close()
 
 	//fire up a uniform muxer
 	igst, err := ingest.NewUniformIngestMuxer(a.Conns, a.Tags, a.IngestSecret, a.TLSPublicKey, a.TLSPrivateKey, "")
@@ -134,7 +157,7 @@ func main() {
 	}
 
 	//go ingest the file
-	if err := doIngest(fin, igst, tag, *tso); err != nil {
+	if err := doIngest(fin, ss, enc, igst, tag, *tso); err != nil {
 		log.Fatalf("Failed to ingest file: %v\n", err)
 	}
 
@@ -150,10 +173,10 @@ func main() {
 	fmt.Printf("Ingest Rate: %s\n", ingest.HumanRate(totalBytes, dur))
 }
 
-func doIngest(fin *xlsx.File, igst *ingest.IngestMuxer, tag entry.EntryTag, tso string) (err error) {
+func doIngest(fin table, ss sheetSelector, enc *rowEncoder, igst *ingest.IngestMuxer, tag entry.EntryTag, tso string) (err error) {
 	//if not doing regular updates, just fire it off
 	if !*status {
-		err = ingestFile(fin, igst, tag, tso)
+		err = ingestFile(fin, ss, enc, igst, tag, tso)
 		return
 	}
 
@@ -161,7 +184,7 @@ func doIngest(fin *xlsx.File, igst *ingest.IngestMuxer, tag entry.EntryTag, tso
 	tckr := time.NewTicker(time.Second)
 	defer tckr.Stop()
 	go func(ch chan error) {
-		ch <- ingestFile(fin, igst, tag, tso)
+		ch <- ingestFile(fin, ss, enc, igst, tag, tso)
 	}(errCh)
 
 loop:
@@ -185,9 +208,7 @@ loop:
 	return
 }
 
-func ingestFile(fin *xlsx.File, igst *ingest.IngestMuxer, tag entry.EntryTag, tso string) error {
-	var ts time.Time
-	var ok bool
+func ingestFile(fin table, ss sheetSelector, enc *rowEncoder, igst *ingest.IngestMuxer, tag entry.EntryTag, tso string) error {
 	var tg *timegrinder.TimeGrinder
 	var err error
 	var blk []*entry.Entry
@@ -225,27 +246,21 @@ func ingestFile(fin *xlsx.File, igst *ingest.IngestMuxer, tag entry.EntryTag, ts
 	}
 	start = time.Now()
 
-	lineCh := make(chan []byte, 128)
-	go xlsxCsvReader(fin, lineCh)
-
-	for bts := range lineCh {
-		if bts = bytes.TrimSuffix(bts, nlBytes); len(bts) == 0 {
-			continue
+	err = fin.walk(ss, func(sheet string, rowNum int, cells []cell) error {
+		bts, tsCell, err := enc.encode(sheet, rowNum, cells)
+		if err != nil {
+			log.Printf("Failed to encode sheet %s row %d: %v\n", sheet, rowNum, err)
+			return nil
+		} else if len(bts) == 0 {
+			return nil
 		}
 		if ignorePrefixFlag {
 			if bytes.HasPrefix(bts, ignorePrefix) {
-				continue
+				return nil
 			}
 		}
-		if noTg {
-			ts = time.Now()
-		} else if ts, ok, err = tg.Extract(bts); err != nil {
-			return err
-		} else if !ok {
-			ts = time.Now()
-		}
 		ent := &entry.Entry{
-			TS:   entry.FromStandard(ts),
+			TS:   entry.FromStandard(rowTimestamp(tg, bts, tsCell)),
 			Tag:  tag,
 			SRC:  src,
 			Data: bts,
@@ -268,74 +283,31 @@ func ingestFile(fin *xlsx.File, igst *ingest.IngestMuxer, tag entry.EntryTag, ts
 		}
 		count++
 		totalBytes += uint64(len(ent.Data))
-	}
-	if len(blk) > 0 {
+		return nil
+	})
+	if err == nil && len(blk) > 0 {
 		err = igst.WriteBatch(blk)
 	}
 	dur = time.Since(start)
 	return err
 }
 
-func xlsxCsvReader(f *xlsx.File, out chan []byte) {
-	defer close(out)
-	for _, sheet := range f.Sheets {
-		skip := *skipFirst
-		for _, row := range sheet.Rows {
-			if skip {
-				skip = false
-				continue
-			}
-			if bts, err := encodeLine(row); err != nil {
-				log.Printf("Failed to encode %v: %v\n", row, err)
-				return
-			} else {
-				out <- bts
-			}
-		}
-	}
-}
-
-func encodeLine(row *xlsx.Row) (b []byte, err error) {
-	if row == nil {
-		return
-	}
-	records := make([]string, len(row.Cells))
-	if len(records) == 0 {
-		return
-	}
-	var hit bool
-	for i, c := range row.Cells {
-		if c == nil {
-			continue
-		}
-		var val string
-		if c.IsTime() {
-			var ts time.Time
-			if ts, err = c.GetTime(false); err != nil {
-				return
-			} else {
-				val = ts.UTC().Format(time.RFC3339Nano)
-			}
-		} else {
-			val = trimQuotes(c.String())
-		}
-		if s := trimQuotes(val); len(s) > 0 {
-			hit = true
-			records[i] = s
+// rowTimestamp prefers the timestamp column, falling back to extracting a timestamp from the whole row
+func rowTimestamp(tg *timegrinder.TimeGrinder, bts []byte, tsCell *cell) time.Time {
+	if tg == nil {
+		return time.Now()
+	}
+	if tsCell != nil {
+		if tsCell.kind == cellTime {
+			return tsCell.t
+		} else if ts, ok, err := tg.Extract([]byte(tsCell.str)); err == nil && ok {
+			return ts
 		}
 	}
-	if !hit {
-		return
-	}
-	bb := bytes.NewBuffer(nil)
-	wtr := csv.NewWriter(bb)
-	if err = wtr.Write(records); err == nil {
-		wtr.Flush()
-		if err = wtr.Error(); err == nil {
-			b = bb.Bytes()
-		}
+	if ts, ok, err := tg.Extract(bts); err == nil && ok {
+		return ts
 	}
-	return
+	return time.Now()
 }
 
 func dropCR(data []byte) []byte {
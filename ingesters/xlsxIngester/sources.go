@@ -0,0 +1,386 @@
+/*************************************************************************
+ * Copyright 2026 Gravwell, Inc. All rights reserved.
+ * Contact: <legal@gravwell.io>
+ *
+ * This software may be modified and distributed under the terms of the
+ * BSD 2-clause license. See the LICENSE file for details.
+ **************************************************************************/
+
+package main
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tealeg/xlsx"
+)
+
+const (
+	inputAuto = `auto`
+	inputXLSX = `xlsx`
+	inputCSV  = `csv`
+	inputODS  = `ods`
+
+	odsContent = `content.xml`
+	maxColumns = 16384 // the widest sheet supported by Excel and LibreOffice
+)
+
+var (
+	ErrUnknownInput = errors.New("unknown input format")
+	ErrNoODSContent = errors.New("ODS file is missing content.xml")
+	ErrTooManyCells = errors.New("row exceeds the maximum column count")
+
+	isoLayouts = []string{
+		time.RFC3339Nano,
+		`2006-01-02T15:04:05.999999999`,
+		`2006-01-02T15:04:05`,
+		`2006-01-02`,
+	}
+)
+
+// table is a spreadsheet or delimited file that can be walked row by row
+type table interface {
+	walk(ss sheetSelector, fn rowHandler) error
+	Close() error
+}
+
+// openTable opens a file as the given input format, auto selects the format by file extension.
+// Naive dates and times found in the file are interpreted in loc.
+func openTable(pth, format string, loc *time.Location) (table, error) {
+	if format == inputAuto || format == `` {
+		switch strings.ToLower(filepath.Ext(pth)) {
+		case `.csv`:
+			format = inputCSV
+		case `.ods`:
+			format = inputODS
+		default:
+			format = inputXLSX
+		}
+	}
+	switch format {
+	case inputXLSX:
+		f, err := xlsx.OpenFile(pth)
+		if err != nil {
+			return nil, err
+		}
+		return &xlsxTable{f: f, loc: loc}, nil
+	case inputCSV:
+		fin, err := os.Open(pth)
+		if err != nil {
+			return nil, err
+		}
+		return &csvTable{fin: fin, name: strings.TrimSuffix(filepath.Base(pth), filepath.Ext(pth))}, nil
+	case inputODS:
+		zr, err := zip.OpenReader(pth)
+		if err != nil {
+			return nil, err
+		}
+		return &odsTable{zr: zr, loc: loc}, nil
+	}
+	return nil, fmt.Errorf("%w %q", ErrUnknownInput, format)
+}
+
+func parseISOTime(v string, loc *time.Location) (t time.Time, ok bool) {
+	for _, l := range isoLayouts {
+		var err error
+		if t, err = time.ParseInLocation(l, v, loc); err == nil {
+			return t, true
+		}
+	}
+	return
+}
+
+// inLocation reinterprets the wall clock of a naive spreadsheet time in loc
+func inLocation(t time.Time, loc *time.Location) time.Time {
+	if loc == nil {
+		return t
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), loc)
+}
+
+type xlsxTable struct {
+	f   *xlsx.File
+	loc *time.Location
+}
+
+func (xt *xlsxTable) walk(ss sheetSelector, fn rowHandler) error {
+	for _, sheet := range xt.f.Sheets {
+		if !ss.want(sheet.Name) {
+			continue
+		}
+		for i, row := range sheet.Rows {
+			if row == nil || len(row.Cells) == 0 {
+				continue
+			}
+			cells := make([]cell, len(row.Cells))
+			for j, c := range row.Cells {
+				var err error
+				if cells[j], err = xt.cell(c); err != nil {
+					return fmt.Errorf("sheet %s row %d: %w", sheet.Name, i+1, err)
+				}
+			}
+			if err := fn(sheet.Name, i+1, cells); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (xt *xlsxTable) cell(c *xlsx.Cell) (cell, error) {
+	if c == nil {
+		return cell{}, nil
+	}
+	str := trimQuotes(c.String())
+	switch c.Type() {
+	case xlsx.CellTypeBool:
+		return cell{kind: cellBool, b: c.Bool(), str: str}, nil
+	case xlsx.CellTypeNumeric:
+		if c.Value == `` {
+			return cell{}, nil
+		} else if c.IsTime() {
+			ts, err := c.GetTime(xt.f.Date1904)
+			if err != nil {
+				return cell{}, err
+			}
+			// serial dates are floating point days, round off the conversion noise
+			return timeCell(inLocation(ts.Round(time.Millisecond), xt.loc)), nil
+		}
+		if f, err := c.Float(); err == nil {
+			return cell{kind: cellNumber, num: f, str: str}, nil
+		}
+	case xlsx.CellTypeDate:
+		if ts, ok := parseISOTime(c.Value, xt.loc); ok {
+			return timeCell(ts), nil
+		}
+	}
+	return stringCell(str), nil
+}
+
+func (xt *xlsxTable) Close() error {
+	return nil
+}
+
+// csvTable walks a CSV file as a single sheet named after the file
+type csvTable struct {
+	fin  *os.File
+	name string
+}
+
+func (ct *csvTable) walk(ss sheetSelector, fn rowHandler) error {
+	if !ss.want(ct.name) {
+		return nil
+	}
+	rdr := csv.NewReader(bufio.NewReader(ct.fin))
+	rdr.FieldsPerRecord = -1
+	rdr.LazyQuotes = true
+	for rowNum := 1; ; rowNum++ {
+		rec, err := rdr.Read()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		cells := make([]cell, len(rec))
+		for i, v := range rec {
+			cells[i] = inferCell(v)
+		}
+		if err = fn(ct.name, rowNum, cells); err != nil {
+			return err
+		}
+	}
+}
+
+func (ct *csvTable) Close() error {
+	return ct.fin.Close()
+}
+
+// odsTable walks the tables of an OpenDocument spreadsheet
+type odsTable struct {
+	zr  *zip.ReadCloser
+	loc *time.Location
+}
+
+func (ot *odsTable) Close() error {
+	return ot.zr.Close()
+}
+
+func (ot *odsTable) walk(ss sheetSelector, fn rowHandler) error {
+	for _, f := range ot.zr.File {
+		if f.Name != odsContent {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return err
+		}
+		defer rc.Close()
+		return walkODS(rc, ot.loc, ss, fn)
+	}
+	return ErrNoODSContent
+}
+
+// odsAttr finds an attribute by local name, attributes in the office namespace are preferred
+func odsAttr(se xml.StartElement, name string) (v string, ok bool) {
+	for _, a := range se.Attr {
+		if a.Name.Local == name {
+			if v, ok = a.Value, true; strings.HasSuffix(a.Name.Space, `:office:1.0`) {
+				return
+			}
+		}
+	}
+	return
+}
+
+func odsRepeat(se xml.StartElement, name string) int {
+	if v, ok := odsAttr(se, name); ok {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			return n
+		}
+	}
+	return 1
+}
+
+// odsCell accumulates the state of the cell being decoded
+type odsCell struct {
+	start  xml.StartElement
+	repeat int
+	text   strings.Builder
+	paras  int
+}
+
+func (oc *odsCell) value(loc *time.Location) cell {
+	txt := oc.text.String()
+	typ, _ := odsAttr(oc.start, `value-type`)
+	switch typ {
+	case `float`, `percentage`, `currency`:
+		v, _ := odsAttr(oc.start, `value`)
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			if txt == `` {
+				txt = v
+			}
+			return cell{kind: cellNumber, num: f, str: txt}
+		}
+	case `date`:
+		v, _ := odsAttr(oc.start, `date-value`)
+		if ts, ok := parseISOTime(v, loc); ok {
+			return timeCell(ts)
+		}
+	case `boolean`:
+		v, _ := odsAttr(oc.start, `boolean-value`)
+		if b, err := strconv.ParseBool(v); err == nil {
+			if txt == `` {
+				txt = strings.ToUpper(v)
+			}
+			return cell{kind: cellBool, b: b, str: txt}
+		}
+	}
+	return stringCell(txt)
+}
+
+func walkODS(rdr io.Reader, loc *time.Location, ss sheetSelector, fn rowHandler) error {
+	dec := xml.NewDecoder(rdr)
+	var sheet string
+	var rowNum, rowRepeat, pendingEmpty int
+	var cells []cell
+	var cur *odsCell
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case `table`:
+				if sheet, _ = odsAttr(t, `name`); !ss.want(sheet) {
+					if err = dec.Skip(); err != nil {
+						return err
+					}
+					continue
+				}
+				rowNum = 0
+			case `table-row`:
+				rowRepeat = odsRepeat(t, `number-rows-repeated`)
+				cells = cells[:0]
+				pendingEmpty = 0
+			case `table-cell`, `covered-table-cell`:
+				cur = &odsCell{start: t.Copy(), repeat: odsRepeat(t, `number-columns-repeated`)}
+			case `p`, `h`:
+				if cur != nil {
+					if cur.paras > 0 {
+						cur.text.WriteByte('\n')
+					}
+					cur.paras++
+				}
+			case `s`:
+				if cur != nil {
+					cur.text.WriteString(strings.Repeat(` `, odsRepeat(t, `c`)))
+				}
+			case `tab`:
+				if cur != nil {
+					cur.text.WriteByte('\t')
+				}
+			case `line-break`:
+				if cur != nil {
+					cur.text.WriteByte('\n')
+				}
+			case `annotation`:
+				// comments are not cell content
+				if err = dec.Skip(); err != nil {
+					return err
+				}
+			}
+		case xml.CharData:
+			if cur != nil && cur.paras > 0 {
+				cur.text.Write(t)
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case `table-cell`, `covered-table-cell`:
+				if cur == nil {
+					continue
+				}
+				c := cur.value(loc)
+				if c.kind == cellEmpty {
+					// trailing empty cells are often repeated to the sheet width, only keep them if content follows
+					pendingEmpty += cur.repeat
+				} else {
+					if len(cells)+pendingEmpty+cur.repeat > maxColumns {
+						return fmt.Errorf("sheet %s row %d: %w", sheet, rowNum+1, ErrTooManyCells)
+					}
+					for ; pendingEmpty > 0; pendingEmpty-- {
+						cells = append(cells, cell{})
+					}
+					for i := 0; i < cur.repeat; i++ {
+						cells = append(cells, c)
+					}
+				}
+				cur = nil
+			case `table-row`:
+				if len(cells) == 0 {
+					rowNum += rowRepeat
+					continue
+				}
+				for i := 0; i < rowRepeat; i++ {
+					rowNum++
+					if err = fn(sheet, rowNum, append([]cell(nil), cells...)); err != nil {
+						return err
+					}
+				}
+			}
+		}
+	}
+}
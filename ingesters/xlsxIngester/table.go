@@ -0,0 +1,312 @@
+/*************************************************************************
+ * Copyright 2026 Gravwell, Inc. All rights reserved.
+ * Contact: <legal@gravwell.io>
+ *
+ * This software may be modified and distributed under the terms of the
+ * BSD 2-clause license. See the LICENSE file for details.
+ **************************************************************************/
+
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gobwas/glob"
+	"github.com/tealeg/xlsx"
+)
+
+var (
+	ErrInvalidColumn = errors.New("invalid column, expected a header name, column letter, or 1 based column number")
+)
+
+type cellKind int
+
+const (
+	cellEmpty cellKind = iota
+	cellString
+	cellNumber
+	cellBool
+	cellTime
+)
+
+// cell is a typed spreadsheet cell, str holds the value as it is displayed
+type cell struct {
+	kind cellKind
+	str  string
+	num  float64
+	b    bool
+	t    time.Time
+}
+
+func stringCell(s string) cell {
+	if s == `` {
+		return cell{}
+	}
+	return cell{kind: cellString, str: s}
+}
+
+func timeCell(t time.Time) cell {
+	return cell{kind: cellTime, t: t, str: t.UTC().Format(time.RFC3339Nano)}
+}
+
+// inferCell types a text value from a CSV file, values with leading zeros stay strings so identifiers survive
+func inferCell(s string) cell {
+	switch {
+	case s == ``:
+		return cell{}
+	case strings.EqualFold(s, `true`):
+		return cell{kind: cellBool, b: true, str: s}
+	case strings.EqualFold(s, `false`):
+		return cell{kind: cellBool, str: s}
+	}
+	if len(s) > 1 && s[0] == '0' && s[1] != '.' {
+		return stringCell(s)
+	}
+	if f, err := strconv.ParseFloat(s, 64); err == nil && !math.IsInf(f, 0) && !math.IsNaN(f) {
+		return cell{kind: cellNumber, num: f, str: s}
+	}
+	return stringCell(s)
+}
+
+// rowHandler receives each row of a sheet, rowNum is 1 based and rows with no content may be omitted
+type rowHandler func(sheet string, rowNum int, cells []cell) error
+
+// sheetSelector decides which sheets are ingested from include and exclude glob lists
+type sheetSelector struct {
+	include []glob.Glob
+	exclude []glob.Glob
+}
+
+func newSheetSelector(include, exclude string) (ss sheetSelector, err error) {
+	if ss.include, err = compileGlobs(include); err != nil {
+		return
+	}
+	ss.exclude, err = compileGlobs(exclude)
+	return
+}
+
+func compileGlobs(v string) (r []glob.Glob, err error) {
+	for _, p := range strings.Split(v, `,`) {
+		if p = strings.TrimSpace(p); p == `` {
+			continue
+		}
+		var g glob.Glob
+		if g, err = glob.Compile(p); err != nil {
+			err = fmt.Errorf("invalid sheet pattern %q: %w", p, err)
+			return
+		}
+		r = append(r, g)
+	}
+	return
+}
+
+func (ss sheetSelector) want(name string) bool {
+	for _, g := range ss.exclude {
+		if g.Match(name) {
+			return false
+		}
+	}
+	if len(ss.include) == 0 {
+		return true
+	}
+	for _, g := range ss.include {
+		if g.Match(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// rowEncoder turns rows into entry payloads
+// Without a header row each row is encoded as a CSV line, with one it is encoded
+// as a JSON object keyed by the header names with natively typed values.
+type rowEncoder struct {
+	headerRow int    // 1 based header row number, zero disables JSON output
+	skipFirst bool   // drop the first row of each sheet when there is no header
+	tsColumn  string // timestamp column specification
+
+	started bool
+	sheet   string
+	header  []string
+	tsIdx   int
+}
+
+func newRowEncoder(headerRow int, skipFirst bool, tsColumn string) (*rowEncoder, error) {
+	if headerRow < 0 {
+		return nil, fmt.Errorf("invalid header row %d", headerRow)
+	}
+	re := &rowEncoder{
+		headerRow: headerRow,
+		skipFirst: skipFirst,
+		tsColumn:  strings.TrimSpace(tsColumn),
+		tsIdx:     -1,
+	}
+	if re.tsColumn != `` && re.headerRow == 0 {
+		if _, err := columnIndex(re.tsColumn); err != nil {
+			return nil, err
+		}
+	}
+	return re, nil
+}
+
+// columnIndex resolves a column letter or 1 based column number to a 0 based index
+func columnIndex(v string) (int, error) {
+	if n, err := strconv.Atoi(v); err == nil {
+		if n <= 0 {
+			return -1, ErrInvalidColumn
+		}
+		return n - 1, nil
+	}
+	if len(v) == 0 || len(v) > 3 {
+		return -1, ErrInvalidColumn
+	}
+	for _, r := range v {
+		if (r < 'A' || r > 'Z') && (r < 'a' || r > 'z') {
+			return -1, ErrInvalidColumn
+		}
+	}
+	return xlsx.ColLettersToIndex(strings.ToUpper(v)), nil
+}
+
+func (re *rowEncoder) reset(sheet string) {
+	re.started = true
+	re.sheet = sheet
+	re.header = nil
+	re.tsIdx = -1
+	if re.tsColumn != `` && re.headerRow == 0 {
+		re.tsIdx, _ = columnIndex(re.tsColumn)
+	}
+}
+
+// setHeader builds unique column names, unnamed columns use their column letters
+func (re *rowEncoder) setHeader(cells []cell) {
+	re.header = make([]string, len(cells))
+	seen := map[string]int{}
+	for i, c := range cells {
+		name := strings.TrimSpace(c.str)
+		if name == `` {
+			name = xlsx.ColIndexToLetters(i)
+		}
+		if n := seen[name]; n > 0 {
+			seen[name] = n + 1
+			name = fmt.Sprintf("%s_%d", name, n+1)
+		} else {
+			seen[name] = 1
+		}
+		re.header[i] = name
+	}
+	if re.tsColumn == `` {
+		return
+	}
+	re.tsIdx = -1
+	for i, name := range re.header {
+		if strings.EqualFold(name, re.tsColumn) {
+			re.tsIdx = i
+			return
+		}
+	}
+	re.tsIdx, _ = columnIndex(re.tsColumn)
+}
+
+func (re *rowEncoder) columnName(i int) string {
+	if i < len(re.header) {
+		return re.header[i]
+	}
+	return xlsx.ColIndexToLetters(i)
+}
+
+// encode returns the payload for a row and its timestamp cell, a nil payload means the row is skipped
+func (re *rowEncoder) encode(sheet string, rowNum int, cells []cell) (b []byte, ts *cell, err error) {
+	if !re.started || sheet != re.sheet {
+		re.reset(sheet)
+	}
+	if re.headerRow > 0 {
+		if rowNum < re.headerRow {
+			return
+		} else if rowNum == re.headerRow {
+			re.setHeader(cells)
+			return
+		}
+	} else if re.skipFirst && rowNum == 1 {
+		return
+	}
+	var hit bool
+	for _, c := range cells {
+		if c.kind != cellEmpty {
+			hit = true
+			break
+		}
+	}
+	if !hit {
+		return
+	}
+	if re.tsIdx >= 0 && re.tsIdx < len(cells) && cells[re.tsIdx].kind != cellEmpty {
+		ts = &cells[re.tsIdx]
+	}
+	if re.headerRow > 0 {
+		b, err = re.encodeJSON(cells)
+	} else {
+		b, err = encodeCSV(cells)
+	}
+	return
+}
+
+// encodeJSON writes an object with keys in column order, empty cells are omitted
+func (re *rowEncoder) encodeJSON(cells []cell) ([]byte, error) {
+	bb := bytes.NewBuffer(nil)
+	bb.WriteByte('{')
+	var n int
+	for i, c := range cells {
+		if c.kind == cellEmpty {
+			continue
+		}
+		if n > 0 {
+			bb.WriteByte(',')
+		}
+		n++
+		k, err := json.Marshal(re.columnName(i))
+		if err != nil {
+			return nil, err
+		}
+		bb.Write(k)
+		bb.WriteByte(':')
+		switch c.kind {
+		case cellNumber:
+			bb.WriteString(strconv.FormatFloat(c.num, 'f', -1, 64))
+		case cellBool:
+			bb.WriteString(strconv.FormatBool(c.b))
+		default:
+			v, err := json.Marshal(c.str)
+			if err != nil {
+				return nil, err
+			}
+			bb.Write(v)
+		}
+	}
+	bb.WriteByte('}')
+	return bb.Bytes(), nil
+}
+
+func encodeCSV(cells []cell) (b []byte, err error) {
+	records := make([]string, len(cells))
+	for i, c := range cells {
+		records[i] = c.str
+	}
+	bb := bytes.NewBuffer(nil)
+	wtr := csv.NewWriter(bb)
+	if err = wtr.Write(records); err == nil {
+		wtr.Flush()
+		if err = wtr.Error(); err == nil {
+			b = bytes.TrimSuffix(bb.Bytes(), nlBytes)
+		}
+	}
+	return
+}
@@ -0,0 +1,278 @@
+/*************************************************************************
+ * Copyright 2026 Gravwell, Inc. All rights reserved.
+ * Contact: <legal@gravwell.io>
+ *
+ * This software may be modified and distributed under the terms of the
+ * BSD 2-clause license. See the LICENSE file for details.
+ **************************************************************************/
+
+package main
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tealeg/xlsx"
+)
+
+type testRow struct {
+	data string
+	ts   *cell
+}
+
+// collect walks a table through an encoder and returns the emitted rows
+func collect(t *testing.T, tbl table, ss sheetSelector, enc *rowEncoder) (rows []testRow) {
+	t.Helper()
+	err := tbl.walk(ss, func(sheet string, rowNum int, cells []cell) error {
+		b, ts, err := enc.encode(sheet, rowNum, cells)
+		if err != nil {
+			return err
+		} else if b != nil {
+			rows = append(rows, testRow{data: string(b), ts: ts})
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return
+}
+
+func writeXLSX(t *testing.T, dir string) string {
+	f := xlsx.NewFile()
+	events, err := f.AddSheet(`Events`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := events.AddRow()
+	for _, h := range []string{`when`, `host`, `bytes`, `ok`, ``, `host`} {
+		r.AddCell().SetString(h)
+	}
+	r = events.AddRow()
+	r.AddCell().SetDateTime(time.Date(2024, 3, 4, 5, 6, 7, 0, time.UTC))
+	r.AddCell().SetString(`web "1"`)
+	r.AddCell().SetFloat(1234.5)
+	r.AddCell().SetBool(true)
+	r.AddCell().SetString(`x`)
+	r.AddCell().SetString(`dup`)
+	events.AddRow() // empty rows are skipped
+	r = events.AddRow()
+	r.AddCell()
+	r.AddCell().SetString(`db`)
+	r.AddCell().SetInt(7)
+	r.AddCell().SetBool(false)
+
+	notes, err := f.AddSheet(`Notes`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	notes.AddRow().AddCell().SetString(`ignored`)
+	pth := filepath.Join(dir, `test.xlsx`)
+	if err = f.Save(pth); err != nil {
+		t.Fatal(err)
+	}
+	return pth
+}
+
+func TestXLSXJSON(t *testing.T) {
+	pth := writeXLSX(t, t.TempDir())
+	tbl, err := openTable(pth, inputAuto, time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tbl.Close()
+	ss, err := newSheetSelector(`Ev*`, ``)
+	if err != nil {
+		t.Fatal(err)
+	}
+	enc, err := newRowEncoder(1, false, `WHEN`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows := collect(t, tbl, ss, enc)
+	exp := []string{
+		`{"when":"2024-03-04T05:06:07Z","host":"web \"1\"","bytes":1234.5,"ok":true,"E":"x","host_2":"dup"}`,
+		`{"host":"db","bytes":7,"ok":false}`,
+	}
+	if len(rows) != len(exp) {
+		t.Fatalf("got %d rows: %v", len(rows), rows)
+	}
+	for i := range exp {
+		if rows[i].data != exp[i] {
+			t.Fatalf("row %d: got %s expected %s", i, rows[i].data, exp[i])
+		}
+	}
+	if rows[0].ts == nil || rows[0].ts.kind != cellTime || !rows[0].ts.t.Equal(time.Date(2024, 3, 4, 5, 6, 7, 0, time.UTC)) {
+		t.Fatalf("bad timestamp cell %+v", rows[0].ts)
+	} else if rows[1].ts != nil {
+		t.Fatalf("empty timestamp cell returned %+v", rows[1].ts)
+	}
+}
+
+func TestXLSXCSV(t *testing.T) {
+	pth := writeXLSX(t, t.TempDir())
+	tbl, err := openTable(pth, inputXLSX, time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tbl.Close()
+	ss, err := newSheetSelector(``, `Events`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	enc, err := newRowEncoder(0, false, ``)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rows := collect(t, tbl, ss, enc); len(rows) != 1 || rows[0].data != `ignored` {
+		t.Fatalf("bad rows %v", rows)
+	}
+
+	ss, _ = newSheetSelector(``, ``)
+	enc, _ = newRowEncoder(0, true, `a`)
+	rows := collect(t, tbl, ss, enc)
+	if len(rows) != 2 { // the first row of both sheets is skipped
+		t.Fatalf("got %d rows: %v", len(rows), rows)
+	}
+	if !strings.HasPrefix(rows[0].data, `2024-03-04T05:06:07Z,"web ""1""",`) {
+		t.Fatalf("bad CSV row %s", rows[0].data)
+	} else if rows[0].ts == nil || rows[0].ts.kind != cellTime {
+		t.Fatalf("bad timestamp cell %+v", rows[0].ts)
+	}
+}
+
+func TestCSVInput(t *testing.T) {
+	pth := filepath.Join(t.TempDir(), `logins.csv`)
+	data := "user,zip,count,admin,when\nbob,01234,3,TRUE,2024-01-02 03:04:05\n\"alice, a\",55555,1.5,false,\n"
+	if err := os.WriteFile(pth, []byte(data), 0640); err != nil {
+		t.Fatal(err)
+	}
+	tbl, err := openTable(pth, inputAuto, time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tbl.Close()
+	ss, _ := newSheetSelector(`logins`, ``)
+	enc, err := newRowEncoder(1, false, `5`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows := collect(t, tbl, ss, enc)
+	exp := []string{
+		`{"user":"bob","zip":"01234","count":3,"admin":true,"when":"2024-01-02 03:04:05"}`,
+		`{"user":"alice, a","zip":55555,"count":1.5,"admin":false}`,
+	}
+	if len(rows) != len(exp) {
+		t.Fatalf("got %d rows: %v", len(rows), rows)
+	}
+	for i := range exp {
+		if rows[i].data != exp[i] {
+			t.Fatalf("row %d: got %s expected %s", i, rows[i].data, exp[i])
+		}
+	}
+	if rows[0].ts == nil || rows[0].ts.str != `2024-01-02 03:04:05` {
+		t.Fatalf("bad timestamp cell %+v", rows[0].ts)
+	}
+}
+
+const testODSContent = `<?xml version="1.0" encoding="UTF-8"?>
+<office:document-content xmlns:office="urn:oasis:names:tc:opendocument:xmlns:office:1.0"
+	xmlns:table="urn:oasis:names:tc:opendocument:xmlns:table:1.0"
+	xmlns:text="urn:oasis:names:tc:opendocument:xmlns:text:1.0"
+	xmlns:calcext="urn:org:documentfoundation:names:experimental:calc:xmlns:calcext:1.0">
+<office:body><office:spreadsheet>
+<table:table table:name="Skip"><table:table-row><table:table-cell office:value-type="string"><text:p>nope</text:p></table:table-cell></table:table-row></table:table>
+<table:table table:name="Data">
+	<table:table-row table:number-rows-repeated="2"><table:table-cell table:number-columns-repeated="1024"/></table:table-row>
+	<table:table-row>
+		<table:table-cell office:value-type="string" calcext:value-type="string"><text:p>time</text:p></table:table-cell>
+		<table:table-cell office:value-type="string"><text:p>msg</text:p></table:table-cell>
+		<table:table-cell office:value-type="string"><text:p>n</text:p></table:table-cell>
+		<table:table-cell office:value-type="string"><text:p>flag</text:p></table:table-cell>
+		<table:table-cell table:number-columns-repeated="1020"/>
+	</table:table-row>
+	<table:table-row>
+		<table:table-cell office:value-type="date" office:date-value="2024-05-06T07:08:09"><text:p>05/06/24 07:08</text:p></table:table-cell>
+		<table:table-cell office:value-type="string"><text:p>two<text:s text:c="2"/>spaces</text:p><text:p>line</text:p><office:annotation><text:p>comment</text:p></office:annotation></table:table-cell>
+		<table:table-cell office:value-type="percentage" office:value="0.25"><text:p>25%</text:p></table:table-cell>
+		<table:table-cell office:value-type="boolean" office:boolean-value="true"><text:p>TRUE</text:p></table:table-cell>
+		<table:table-cell table:number-columns-repeated="1020"/>
+	</table:table-row>
+	<table:table-row table:number-rows-repeated="2">
+		<table:table-cell table:number-columns-repeated="2"/>
+		<table:table-cell office:value-type="float" office:value="3"><text:p>3</text:p></table:table-cell>
+	</table:table-row>
+	<table:table-row table:number-rows-repeated="1048000"><table:table-cell table:number-columns-repeated="1024"/></table:table-row>
+</table:table>
+</office:spreadsheet></office:body></office:document-content>`
+
+func TestODSInput(t *testing.T) {
+	pth := filepath.Join(t.TempDir(), `test.ods`)
+	fout, err := os.Create(pth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := zip.NewWriter(fout)
+	w, err := zw.Create(odsContent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write([]byte(testODSContent))
+	if err = zw.Close(); err != nil {
+		t.Fatal(err)
+	} else if err = fout.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	loc, err := time.LoadLocation(`America/Chicago`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tbl, err := openTable(pth, inputAuto, loc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tbl.Close()
+	ss, _ := newSheetSelector(``, `Skip`)
+	enc, _ := newRowEncoder(3, false, `time`)
+	rows := collect(t, tbl, ss, enc)
+	exp := []string{
+		`{"time":"2024-05-06T12:08:09Z","msg":"two  spaces\nline","n":0.25,"flag":true}`,
+		`{"n":3}`,
+		`{"n":3}`,
+	}
+	if len(rows) != len(exp) {
+		t.Fatalf("got %d rows: %v", len(rows), rows)
+	}
+	for i := range exp {
+		if rows[i].data != exp[i] {
+			t.Fatalf("row %d: got %s expected %s", i, rows[i].data, exp[i])
+		}
+	}
+	if rows[0].ts == nil || !rows[0].ts.t.Equal(time.Date(2024, 5, 6, 7, 8, 9, 0, loc)) {
+		t.Fatalf("bad timestamp cell %+v", rows[0].ts)
+	}
+}
+
+func TestColumns(t *testing.T) {
+	tests := map[string]int{`1`: 0, `A`: 0, `c`: 2, `AA`: 26, `12`: 11}
+	for v, exp := range tests {
+		if idx, err := columnIndex(v); err != nil || idx != exp {
+			t.Fatalf("%s: got %d %v expected %d", v, idx, err, exp)
+		}
+	}
+	for _, v := range []string{``, `0`, `-1`, `A1`, `ABCD`} {
+		if _, err := columnIndex(v); err == nil {
+			t.Fatalf("%q accepted", v)
+		}
+	}
+	if _, err := newRowEncoder(0, false, `name`); err == nil {
+		t.Fatal("named timestamp column accepted without a header row")
+	} else if _, err = newRowEncoder(-1, false, ``); err == nil {
+		t.Fatal("negative header row accepted")
+	}
+}
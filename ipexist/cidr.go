@@ -0,0 +1,339 @@
+/*************************************************************************
+ * Copyright 2026 Gravwell, Inc. All rights reserved.
+ * Contact: <legal@gravwell.io>
+ *
+ * This software may be modified and distributed under the terms of the
+ * BSD 2-clause license. See the LICENSE file for details.
+ **************************************************************************/
+
+package ipexist
+
+import (
+	"encoding/binary"
+	"math/bits"
+	"net"
+)
+
+const (
+	fullBitmap uint16 = 0xFFFF // offset marker for a /16 that is entirely set
+)
+
+// AddCIDR adds every address in an IPv4 network, whole /16 blocks are marked as full without allocating a bitmap
+func (ipbm *IpBitMap) AddCIDR(n *net.IPNet) (err error) {
+	var start, end uint32
+	if start, end, err = v4Range(n); err != nil {
+		return
+	}
+	return ipbm.rangeOp(start, end, true)
+}
+
+// RemoveCIDR removes every address in an IPv4 network
+func (ipbm *IpBitMap) RemoveCIDR(n *net.IPNet) (err error) {
+	var start, end uint32
+	if start, end, err = v4Range(n); err != nil {
+		return
+	}
+	return ipbm.rangeOp(start, end, false)
+}
+
+func v4Range(n *net.IPNet) (start, end uint32, err error) {
+	if n == nil {
+		err = ErrInvalidCIDR
+		return
+	}
+	ip := n.IP.To4()
+	ones, bits := n.Mask.Size()
+	if ip == nil || bits != 32 {
+		err = ErrInvalidIPv4
+		return
+	}
+	mask := uint32(0xffffffff) << (32 - ones)
+	if ones == 0 {
+		mask = 0
+	}
+	start = binary.BigEndian.Uint32(ip) & mask
+	end = start | ^mask
+	return
+}
+
+func (ipbm *IpBitMap) rangeOp(start, end uint32, set bool) (err error) {
+	for upper := start >> 16; upper <= end>>16; upper++ {
+		if upper == 0xffff {
+			break // we do not support broadcast
+		}
+		lo, hi := uint16(0), uint16(0xffff)
+		if upper == start>>16 {
+			lo = uint16(start)
+		}
+		if upper == end>>16 {
+			hi = uint16(end)
+		}
+		if lo == 0 && hi == 0xffff {
+			if set {
+				ipbm.fillBlock(uint16(upper))
+			} else {
+				ipbm.clearBlock(uint16(upper))
+			}
+			continue
+		}
+		off := ipbm.bitmapOffsets[upper]
+		if (set && off == fullBitmap) || (!set && off == 0) {
+			continue
+		}
+		var b *slash16bitmap
+		if b, err = ipbm.materialize(uint16(upper)); err != nil {
+			return
+		}
+		if set {
+			b.setRange(lo, hi)
+		} else {
+			b.clearRange(lo, hi)
+		}
+	}
+	return
+}
+
+// block returns the bitmap for a /16, a nil bitmap with full set means every address is present
+func (ipbm *IpBitMap) block(upper uint16) (b *slash16bitmap, full bool) {
+	switch off := ipbm.bitmapOffsets[upper]; {
+	case off == fullBitmap:
+		full = true
+	case off > 0 && off <= ipbm.maxOffset:
+		b = &ipbm.bitmaps[off-1]
+	}
+	return
+}
+
+// materialize returns a writable bitmap for a /16, allocating one if needed.
+// A block that was marked as full gets a bitmap with every bit set.
+// The returned pointer is invalidated by the next allocation on a memory mapped set.
+func (ipbm *IpBitMap) materialize(upper uint16) (b *slash16bitmap, err error) {
+	off := ipbm.bitmapOffsets[upper]
+	if off == 0 || off == fullBitmap {
+		var noff uint16
+		if noff, err = ipbm.addNewBitmap(); err != nil {
+			return
+		}
+		ipbm.bitmapOffsets[upper] = noff
+		b = &ipbm.bitmaps[noff-1]
+		*b = slash16bitmap{}
+		if off == fullBitmap {
+			b.setRange(0, 0xffff)
+		}
+		return
+	} else if off > ipbm.maxOffset {
+		err = ErrInvalidBaseOffset
+		return
+	}
+	b = &ipbm.bitmaps[off-1]
+	return
+}
+
+// fillBlock sets an entire /16, existing bitmaps are filled so that they are not orphaned
+func (ipbm *IpBitMap) fillBlock(upper uint16) {
+	if b, full := ipbm.block(upper); b != nil {
+		b.setRange(0, 0xffff)
+	} else if !full {
+		ipbm.bitmapOffsets[upper] = fullBitmap
+	}
+}
+
+func (ipbm *IpBitMap) clearBlock(upper uint16) {
+	if b, full := ipbm.block(upper); b != nil {
+		*b = slash16bitmap{}
+	} else if full {
+		ipbm.bitmapOffsets[upper] = 0
+	}
+}
+
+// Union adds every address in o to the set
+func (ipbm *IpBitMap) Union(o *IpBitMap) (err error) {
+	for upper := 0; upper < 0xffff; upper++ {
+		ob, ofull := o.block(uint16(upper))
+		if ofull {
+			ipbm.fillBlock(uint16(upper))
+		} else if ob != nil && !ob.empty() {
+			if _, full := ipbm.block(uint16(upper)); full {
+				continue
+			}
+			var b *slash16bitmap
+			if b, err = ipbm.materialize(uint16(upper)); err != nil {
+				return
+			}
+			for i := range b {
+				b[i] |= ob[i]
+			}
+		}
+	}
+	return
+}
+
+// Intersect removes every address that is not also in o
+func (ipbm *IpBitMap) Intersect(o *IpBitMap) (err error) {
+	for upper := 0; upper < 0xffff; upper++ {
+		b, full := ipbm.block(uint16(upper))
+		if b == nil && !full {
+			continue
+		}
+		ob, ofull := o.block(uint16(upper))
+		if ofull {
+			continue
+		} else if ob == nil {
+			ipbm.clearBlock(uint16(upper))
+			continue
+		}
+		if b, err = ipbm.materialize(uint16(upper)); err != nil {
+			return
+		}
+		for i := range b {
+			b[i] &= ob[i]
+		}
+	}
+	return
+}
+
+// Difference removes every address that is in o
+func (ipbm *IpBitMap) Difference(o *IpBitMap) (err error) {
+	for upper := 0; upper < 0xffff; upper++ {
+		ob, ofull := o.block(uint16(upper))
+		if ofull {
+			ipbm.clearBlock(uint16(upper))
+			continue
+		} else if ob == nil {
+			continue
+		}
+		if b, full := ipbm.block(uint16(upper)); b == nil && !full {
+			continue
+		}
+		var b *slash16bitmap
+		if b, err = ipbm.materialize(uint16(upper)); err != nil {
+			return
+		}
+		for i := range b {
+			b[i] &^= ob[i]
+		}
+	}
+	return
+}
+
+// Walk calls fn with the smallest set of networks that covers the set in ascending order.
+// Walking stops at the first error returned by fn.
+func (ipbm *IpBitMap) Walk(fn func(*net.IPNet) error) (err error) {
+	var start, end uint64
+	var open bool
+	emit := func(s, e uint64) error {
+		if !open {
+			start, end, open = s, e, true
+			return nil
+		} else if s == end+1 {
+			end = e
+			return nil
+		}
+		err := walkV4Range(start, end, fn)
+		start, end = s, e
+		return err
+	}
+	for upper := uint64(0); upper < 0xffff; upper++ {
+		base := upper << 16
+		b, full := ipbm.block(uint16(upper))
+		if full {
+			if err = emit(base, base|0xffff); err != nil {
+				return
+			}
+			continue
+		} else if b == nil {
+			continue
+		}
+		for _, r := range b.runs() {
+			if err = emit(base|uint64(r[0]), base|uint64(r[1])); err != nil {
+				return
+			}
+		}
+	}
+	if open {
+		err = walkV4Range(start, end, fn)
+	}
+	return
+}
+
+// walkV4Range splits an inclusive range into the largest aligned networks
+func walkV4Range(start, end uint64, fn func(*net.IPNet) error) error {
+	for start <= end {
+		host := bits.TrailingZeros64(start | 1<<32)
+		for host > 0 && start+(1<<host)-1 > end {
+			host--
+		}
+		ip := make(net.IP, 4)
+		binary.BigEndian.PutUint32(ip, uint32(start))
+		if err := fn(&net.IPNet{IP: ip, Mask: net.CIDRMask(32-host, 32)}); err != nil {
+			return err
+		}
+		start += 1 << host
+	}
+	return nil
+}
+
+func (b *slash16bitmap) setRange(lo, hi uint16) {
+	for w := lo >> 6; w <= hi>>6; w++ {
+		b[w] |= wordMask(w, lo, hi)
+	}
+}
+
+func (b *slash16bitmap) clearRange(lo, hi uint16) {
+	for w := lo >> 6; w <= hi>>6; w++ {
+		b[w] &^= wordMask(w, lo, hi)
+	}
+}
+
+// wordMask returns the bits of word w that fall within the inclusive range lo to hi
+func wordMask(w, lo, hi uint16) (m uint64) {
+	m = 0xffffffffffffffff
+	if w == lo>>6 {
+		m &= m << (lo & 0x3f)
+	}
+	if w == hi>>6 {
+		m &= 0xffffffffffffffff >> (63 - (hi & 0x3f))
+	}
+	return
+}
+
+func (b *slash16bitmap) empty() bool {
+	for _, v := range b {
+		if v != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// runs returns the inclusive ranges of set bits in ascending order
+func (b *slash16bitmap) runs() (r [][2]uint16) {
+	start := -1
+	for w, v := range b {
+		base := w << 6
+		for pos := 0; pos < 64; {
+			if start < 0 {
+				// find the next set bit
+				x := v >> pos
+				if x == 0 {
+					break
+				}
+				pos += bits.TrailingZeros64(x)
+				start = base + pos
+			} else {
+				// find the next clear bit
+				x := ^v >> pos
+				if x == 0 {
+					break
+				}
+				pos += bits.TrailingZeros64(x)
+				r = append(r, [2]uint16{uint16(start), uint16(base + pos - 1)})
+				start = -1
+			}
+		}
+	}
+	if start >= 0 {
+		r = append(r, [2]uint16{uint16(start), 0xffff})
+	}
+	return
+}
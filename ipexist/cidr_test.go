@@ -0,0 +1,174 @@
+/*************************************************************************
+ * Copyright 2026 Gravwell, Inc. All rights reserved.
+ * Contact: <legal@gravwell.io>
+ *
+ * This software may be modified and distributed under the terms of the
+ * BSD 2-clause license. See the LICENSE file for details.
+ **************************************************************************/
+
+package ipexist
+
+import (
+	"bytes"
+	"net"
+	"reflect"
+	"testing"
+)
+
+type cidrSet interface {
+	AddCIDR(*net.IPNet) error
+	RemoveCIDR(*net.IPNet) error
+	IPExists(net.IP) (bool, error)
+	Walk(func(*net.IPNet) error) error
+}
+
+func mustCIDR(t *testing.T, s string) *net.IPNet {
+	t.Helper()
+	_, n, err := net.ParseCIDR(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
+
+func addCIDRs(t *testing.T, s cidrSet, cidrs ...string) {
+	t.Helper()
+	for _, c := range cidrs {
+		if err := s.AddCIDR(mustCIDR(t, c)); err != nil {
+			t.Fatal(c, err)
+		}
+	}
+}
+
+func walkStrings(t *testing.T, s cidrSet) (r []string) {
+	t.Helper()
+	if err := s.Walk(func(n *net.IPNet) error {
+		r = append(r, n.String())
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	return
+}
+
+func checkExists(t *testing.T, s cidrSet, exp map[string]bool) {
+	t.Helper()
+	for ip, want := range exp {
+		if ok, err := s.IPExists(net.ParseIP(ip)); err != nil {
+			t.Fatal(ip, err)
+		} else if ok != want {
+			t.Fatalf("%s exists %v, expected %v", ip, ok, want)
+		}
+	}
+}
+
+func TestCIDR(t *testing.T) {
+	bm := NewIPBitMap()
+	addCIDRs(t, bm, `10.0.0.0/8`, `192.168.1.0/30`)
+	if err := bm.RemoveCIDR(mustCIDR(t, `10.1.2.0/24`)); err != nil {
+		t.Fatal(err)
+	}
+	// removing addresses that are not present must not add them
+	if err := bm.RemoveIP(net.ParseIP(`192.168.1.9`)); err != nil {
+		t.Fatal(err)
+	}
+	checkExists(t, bm, map[string]bool{
+		`10.200.1.1`:  true,
+		`10.1.2.5`:    false,
+		`10.1.3.0`:    true,
+		`10.1.1.255`:  true,
+		`11.0.0.0`:    false,
+		`192.168.1.3`: true,
+		`192.168.1.4`: false,
+		`192.168.1.9`: false,
+	})
+	if err := bm.AddCIDR(mustCIDR(t, `2001:db8::/32`)); err != ErrInvalidIPv4 {
+		t.Fatal("IPv6 network was not rejected", err)
+	}
+	exp := []string{`10.0.0.0/16`, `10.1.0.0/23`, `10.1.3.0/24`, `10.1.4.0/22`, `10.1.8.0/21`,
+		`10.1.16.0/20`, `10.1.32.0/19`, `10.1.64.0/18`, `10.1.128.0/17`, `10.2.0.0/15`, `10.4.0.0/14`,
+		`10.8.0.0/13`, `10.16.0.0/12`, `10.32.0.0/11`, `10.64.0.0/10`, `10.128.0.0/9`, `192.168.1.0/30`}
+	if got := walkStrings(t, bm); !reflect.DeepEqual(got, exp) {
+		t.Fatalf("bad walk:\n%v\n%v", got, exp)
+	}
+
+	// full blocks and partial bitmaps must survive an encode/decode round trip, including into a memory map
+	bb := bytes.NewBuffer(nil)
+	if err := bm.Encode(bb); err != nil {
+		t.Fatal(err)
+	}
+	mmn, err := getTempFileName()
+	if err != nil {
+		t.Fatal(err)
+	}
+	nbm, err := LoadIPBitMapMemoryMapped(bb, mmn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer nbm.Close()
+	if got := walkStrings(t, nbm); !reflect.DeepEqual(got, exp) {
+		t.Fatalf("bad walk after decode:\n%v\n%v", got, exp)
+	}
+	// punch a hole in a full /16 on the memory mapped copy
+	if err = nbm.RemoveCIDR(mustCIDR(t, `10.9.0.0/17`)); err != nil {
+		t.Fatal(err)
+	}
+	checkExists(t, nbm, map[string]bool{`10.9.1.1`: false, `10.9.200.1`: true, `10.10.0.0`: true})
+	// single addresses can be removed from a full /16 as well
+	if err = nbm.RemoveIP(net.ParseIP(`10.11.0.5`)); err != nil {
+		t.Fatal(err)
+	}
+	checkExists(t, nbm, map[string]bool{`10.11.0.4`: true, `10.11.0.5`: false, `10.11.0.6`: true})
+}
+
+func TestRemoveIPFullBlock(t *testing.T) {
+	bm := NewIPBitMap()
+	addCIDRs(t, bm, `10.0.0.0/16`)
+	if err := bm.RemoveIP(net.ParseIP(`10.0.0.5`)); err != nil {
+		t.Fatal(err)
+	}
+	checkExists(t, bm, map[string]bool{
+		`10.0.0.4`:     true,
+		`10.0.0.5`:     false,
+		`10.0.0.6`:     true,
+		`10.0.255.255`: true,
+	})
+	exp := []string{`10.0.0.0/30`, `10.0.0.4/32`, `10.0.0.6/31`, `10.0.0.8/29`, `10.0.0.16/28`, `10.0.0.32/27`,
+		`10.0.0.64/26`, `10.0.0.128/25`, `10.0.1.0/24`, `10.0.2.0/23`, `10.0.4.0/22`, `10.0.8.0/21`,
+		`10.0.16.0/20`, `10.0.32.0/19`, `10.0.64.0/18`, `10.0.128.0/17`}
+	if got := walkStrings(t, bm); !reflect.DeepEqual(got, exp) {
+		t.Fatalf("bad walk:\n%v\n%v", got, exp)
+	}
+}
+
+func TestCIDRSetOps(t *testing.T) {
+	mk := func(cidrs ...string) *IpBitMap {
+		bm := NewIPBitMap()
+		addCIDRs(t, bm, cidrs...)
+		return bm
+	}
+	a := func() *IpBitMap { return mk(`10.0.0.0/15`, `1.2.3.0/24`) }
+	b := mk(`10.1.0.0/16`, `1.2.3.128/25`, `8.8.8.8/32`)
+
+	u := a()
+	if err := u.Union(b); err != nil {
+		t.Fatal(err)
+	}
+	if got, exp := walkStrings(t, u), []string{`1.2.3.0/24`, `8.8.8.8/32`, `10.0.0.0/15`}; !reflect.DeepEqual(got, exp) {
+		t.Fatalf("bad union %v", got)
+	}
+	i := a()
+	if err := i.Intersect(b); err != nil {
+		t.Fatal(err)
+	}
+	if got, exp := walkStrings(t, i), []string{`1.2.3.128/25`, `10.1.0.0/16`}; !reflect.DeepEqual(got, exp) {
+		t.Fatalf("bad intersect %v", got)
+	}
+	d := a()
+	if err := d.Difference(b); err != nil {
+		t.Fatal(err)
+	}
+	if got, exp := walkStrings(t, d), []string{`1.2.3.0/25`, `10.0.0.0/16`}; !reflect.DeepEqual(got, exp) {
+		t.Fatalf("bad difference %v", got)
+	}
+}
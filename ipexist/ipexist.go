@@ -9,7 +9,7 @@
 //lint:file-ignore SA1019 suggestion isn't the same and we can't actually use it, ignore.
 
 // Package ipexist is a very high performance bitfield existence checker for IPv4 addresses
-// IPv6 is not supported to to the huge address space
+// IPv6 addresses are handled by the IPv6PrefixSet, which stores ranges rather than bitmaps due to the huge address space
 package ipexist
 
 import (
@@ -33,6 +33,7 @@ var (
 	ErrInvalidIPv4       = errors.New("Invalid IPv4 Address")
 	ErrInvalidBaseOffset = errors.New("Invalid IPv4 base offset, potential corruption")
 	ErrNotMmapBacked     = errors.New("IPBitMap is not backed by a memory map")
+	ErrInvalidCIDR       = errors.New("Invalid CIDR")
 )
 
 var (
@@ -150,11 +151,15 @@ func (ipbm *IpBitMap) RemoveIP(ip net.IP) (err error) {
 	off := ipbm.bitmapOffsets[upper]
 
 	// we're cool if it doesn't exist
-	if off == 0 || off == 0xffff || off > ipbm.maxOffset {
+	if off == 0 || (off != fullBitmap && off > ipbm.maxOffset) {
 		return
 	}
-
-	ipbm.bitmaps[off-1].clear(lower)
+	// a full /16 needs a real bitmap before a single address can be removed
+	var b *slash16bitmap
+	if b, err = ipbm.materialize(upper); err != nil {
+		return
+	}
+	b.clear(lower)
 	return
 }
 
@@ -357,8 +362,8 @@ func (b *slash16bitmap) clear(v uint16) {
 	//get the bit offset into the field
 	boff := uint64(1 << (v & 0x3f))
 
-	//set the bit
-	b[v>>6] ^= boff
+	//clear the bit
+	b[v>>6] &^= boff
 }
 
 func (b *slash16bitmap) isset(v uint16) bool {
@@ -381,15 +386,19 @@ func writeAll(w io.Writer, b []byte) (err error) {
 }
 
 func checkHeader(r io.Reader) (err error) {
+	return checkHeaderValue(r, compV1Header)
+}
+
+func checkHeaderValue(r io.Reader, hdr []byte) (err error) {
 	var n int
-	x := make([]byte, len(compV1Header))
+	x := make([]byte, len(hdr))
 	if n, err = r.Read(x); err != nil {
 		return
 	} else if n != len(x) {
 		return errors.New("failed header read")
 	}
 	for i := range x {
-		if x[i] != compV1Header[i] {
+		if x[i] != hdr[i] {
 			err = errors.New("Bad header")
 			break
 		}
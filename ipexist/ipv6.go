@@ -0,0 +1,490 @@
+/*************************************************************************
+ * Copyright 2026 Gravwell, Inc. All rights reserved.
+ * Contact: <legal@gravwell.io>
+ *
+ * This software may be modified and distributed under the terms of the
+ * BSD 2-clause license. See the LICENSE file for details.
+ **************************************************************************/
+
+//lint:file-ignore SA1019 suggestion isn't the same and we can't actually use it, ignore.
+
+package ipexist
+
+import (
+	"bufio"
+	"compress/flate"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math/bits"
+	"net"
+	"reflect"
+	"sort"
+	"unsafe"
+)
+
+const (
+	v6rangeSize int64 = 32
+	maxV6Ranges       = 1 << 32
+)
+
+var (
+	ErrInvalidIPv6 = errors.New("Invalid IPv6 Address")
+
+	compV6Header = []byte{0x49, 0x50, 0x76, 0x36, 0x46, 0x4c, 0x54, 0x31} //IPv6FLT1
+)
+
+// u128 is an IPv6 address as a 128 bit integer
+type u128 struct {
+	hi, lo uint64
+}
+
+// v6range is an inclusive range of addresses
+type v6range struct {
+	lo, hi u128
+}
+
+// IPv6PrefixSet is an existence checker for IPv6 addresses and networks.
+// The set is stored as a sorted list of disjoint address ranges, so large networks
+// cost the same as single addresses and lookups are a binary search.
+// Additions are buffered and merged on the next lookup, removal, or Compact, the set is not
+// safe for concurrent use while there are pending additions.
+type IPv6PrefixSet struct {
+	ranges     []v6range
+	pending    []v6range
+	mmapBacked bool
+	mm         mmapBacker
+}
+
+func NewIPv6PrefixSet() *IPv6PrefixSet {
+	return &IPv6PrefixSet{}
+}
+
+func LoadIPv6PrefixSet(r io.Reader) (*IPv6PrefixSet, error) {
+	x := NewIPv6PrefixSet()
+	if err := x.Decode(r); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+func NewIPv6PrefixSetMemoryMapped(p string) (ps *IPv6PrefixSet, err error) {
+	ps = &IPv6PrefixSet{}
+	if ps.mm, err = newMmapBacker(p); err != nil {
+		ps = nil
+		return
+	}
+	ps.mmapBacked = true
+	return
+}
+
+func LoadIPv6PrefixSetMemoryMapped(r io.Reader, p string) (*IPv6PrefixSet, error) {
+	x, err := NewIPv6PrefixSetMemoryMapped(p)
+	if err != nil {
+		return nil, err
+	}
+	if err = x.Decode(r); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+func (ps *IPv6PrefixSet) Close() (err error) {
+	ps.ranges = nil
+	ps.pending = nil
+	if ps.mmapBacked {
+		err = ps.mm.Close()
+		ps.mmapBacked = false
+	}
+	return
+}
+
+// Count returns the number of disjoint ranges in the set
+func (ps *IPv6PrefixSet) Count() (int, error) {
+	if err := ps.Compact(); err != nil {
+		return 0, err
+	}
+	return len(ps.ranges), nil
+}
+
+func (ps *IPv6PrefixSet) AddIP(ip net.IP) (err error) {
+	var v u128
+	if v, err = v6Addr(ip); err != nil {
+		return
+	}
+	ps.pending = append(ps.pending, v6range{lo: v, hi: v})
+	return
+}
+
+func (ps *IPv6PrefixSet) RemoveIP(ip net.IP) (err error) {
+	var v u128
+	if v, err = v6Addr(ip); err != nil {
+		return
+	}
+	return ps.remove(v6range{lo: v, hi: v})
+}
+
+func (ps *IPv6PrefixSet) AddCIDR(n *net.IPNet) (err error) {
+	var r v6range
+	if r, err = v6Range(n); err != nil {
+		return
+	}
+	ps.pending = append(ps.pending, r)
+	return
+}
+
+func (ps *IPv6PrefixSet) RemoveCIDR(n *net.IPNet) (err error) {
+	var r v6range
+	if r, err = v6Range(n); err != nil {
+		return
+	}
+	return ps.remove(r)
+}
+
+func (ps *IPv6PrefixSet) remove(r v6range) (err error) {
+	if err = ps.Compact(); err != nil {
+		return
+	}
+	return ps.setRanges(v6Difference(ps.ranges, []v6range{r}))
+}
+
+func (ps *IPv6PrefixSet) IPExists(ip net.IP) (ok bool, err error) {
+	var v u128
+	if v, err = v6Addr(ip); err != nil {
+		return
+	} else if err = ps.Compact(); err != nil {
+		return
+	}
+	i := sort.Search(len(ps.ranges), func(i int) bool {
+		return ps.ranges[i].hi.cmp(v) >= 0
+	})
+	ok = i < len(ps.ranges) && ps.ranges[i].lo.cmp(v) <= 0
+	return
+}
+
+// Compact merges pending additions into the set
+func (ps *IPv6PrefixSet) Compact() error {
+	if len(ps.pending) == 0 {
+		return nil
+	}
+	add := v6Normalize(ps.pending)
+	ps.pending = nil
+	return ps.setRanges(v6Union(ps.ranges, add))
+}
+
+// Union adds every address in o to the set
+func (ps *IPv6PrefixSet) Union(o *IPv6PrefixSet) (err error) {
+	if err = ps.Compact(); err != nil {
+		return
+	} else if err = o.Compact(); err != nil {
+		return
+	}
+	return ps.setRanges(v6Union(ps.ranges, o.ranges))
+}
+
+// Intersect removes every address that is not also in o
+func (ps *IPv6PrefixSet) Intersect(o *IPv6PrefixSet) (err error) {
+	if err = ps.Compact(); err != nil {
+		return
+	} else if err = o.Compact(); err != nil {
+		return
+	}
+	return ps.setRanges(v6Intersect(ps.ranges, o.ranges))
+}
+
+// Difference removes every address that is in o
+func (ps *IPv6PrefixSet) Difference(o *IPv6PrefixSet) (err error) {
+	if err = ps.Compact(); err != nil {
+		return
+	} else if err = o.Compact(); err != nil {
+		return
+	}
+	return ps.setRanges(v6Difference(ps.ranges, o.ranges))
+}
+
+// Walk calls fn with the smallest set of networks that covers the set in ascending order.
+// Walking stops at the first error returned by fn.
+func (ps *IPv6PrefixSet) Walk(fn func(*net.IPNet) error) (err error) {
+	if err = ps.Compact(); err != nil {
+		return
+	}
+	for _, r := range ps.ranges {
+		if err = walkV6Range(r, fn); err != nil {
+			return
+		}
+	}
+	return
+}
+
+func walkV6Range(r v6range, fn func(*net.IPNet) error) error {
+	start := r.lo
+	for {
+		host := start.trailingZeros()
+		for host > 0 && start.add(u128{}.setBit(host).sub(u128{lo: 1})).cmp(r.hi) > 0 {
+			host--
+		}
+		if err := fn(&net.IPNet{IP: start.ip(), Mask: net.CIDRMask(128-host, 128)}); err != nil {
+			return err
+		}
+		if host == 128 {
+			return nil // the entire address space
+		}
+		last := start.add(u128{}.setBit(host).sub(u128{lo: 1}))
+		if last.cmp(r.hi) >= 0 {
+			return nil
+		}
+		start = last.add(u128{lo: 1})
+	}
+}
+
+// setRanges replaces the contents of the set, copying into the memory map if the set is backed by one
+func (ps *IPv6PrefixSet) setRanges(rs []v6range) (err error) {
+	if !ps.mmapBacked {
+		ps.ranges = rs
+		return
+	}
+	if len(rs) == 0 {
+		ps.ranges = nil
+		return ps.mm.fm.SetSize(0)
+	}
+	if err = ps.mm.fm.SetSize(int64(len(rs)) * v6rangeSize); err != nil {
+		return
+	}
+	//ok, now use reflection to setup our slice
+	hdr := (*reflect.SliceHeader)(unsafe.Pointer(&ps.ranges))
+	hdr.Len = len(rs)
+	hdr.Cap = len(rs)
+	hdr.Data = uintptr(unsafe.Pointer(&ps.mm.fm.Buff[0]))
+	copy(ps.ranges, rs)
+	return
+}
+
+func (ps *IPv6PrefixSet) Encode(w io.Writer) (err error) {
+	var fw *flate.Writer
+	if err = ps.Compact(); err != nil {
+		return
+	}
+	//write the header
+	if err = writeAll(w, compV6Header); err != nil {
+		return
+	}
+	//write the range count
+	x := make([]byte, 8)
+	binary.LittleEndian.PutUint64(x, uint64(len(ps.ranges)))
+	if err = writeAll(w, x); err != nil {
+		return
+	}
+	//get a new flate writer
+	if fw, err = flate.NewWriter(w, flateLevel); err != nil {
+		return
+	}
+	buff := make([]byte, v6rangeSize)
+	for _, r := range ps.ranges {
+		binary.BigEndian.PutUint64(buff[0:], r.lo.hi)
+		binary.BigEndian.PutUint64(buff[8:], r.lo.lo)
+		binary.BigEndian.PutUint64(buff[16:], r.hi.hi)
+		binary.BigEndian.PutUint64(buff[24:], r.hi.lo)
+		if err = writeAll(fw, buff); err != nil {
+			return
+		}
+	}
+	return fw.Flush()
+}
+
+func (ps *IPv6PrefixSet) Decode(r io.Reader) (err error) {
+	var cnt uint64
+	if err = checkHeaderValue(r, compV6Header); err != nil {
+		return
+	}
+	//get the range count
+	if cnt, err = readUint64(r); err != nil {
+		return
+	} else if cnt > maxV6Ranges {
+		return errors.New("file is corrupt")
+	}
+	fr := bufio.NewReader(flate.NewReader(r))
+	var rs []v6range
+	buff := make([]byte, v6rangeSize)
+	for i := uint64(0); i < cnt; i++ {
+		if _, err = io.ReadFull(fr, buff); err != nil {
+			return
+		}
+		rg := v6range{
+			lo: u128{hi: binary.BigEndian.Uint64(buff[0:]), lo: binary.BigEndian.Uint64(buff[8:])},
+			hi: u128{hi: binary.BigEndian.Uint64(buff[16:]), lo: binary.BigEndian.Uint64(buff[24:])},
+		}
+		//ranges must be ordered and disjoint
+		if rg.lo.cmp(rg.hi) > 0 || (len(rs) > 0 && rs[len(rs)-1].hi.cmp(rg.lo) >= 0) {
+			return errors.New("ranges are corrupt")
+		}
+		rs = append(rs, rg)
+	}
+	ps.pending = nil
+	return ps.setRanges(rs)
+}
+
+func v6Addr(ip net.IP) (v u128, err error) {
+	if len(ip) != net.IPv6len || ip.To4() != nil {
+		err = ErrInvalidIPv6
+		return
+	}
+	v.hi = binary.BigEndian.Uint64(ip[0:8])
+	v.lo = binary.BigEndian.Uint64(ip[8:16])
+	return
+}
+
+func v6Range(n *net.IPNet) (r v6range, err error) {
+	if n == nil {
+		err = ErrInvalidCIDR
+		return
+	}
+	ones, bits := n.Mask.Size()
+	if bits != 128 {
+		err = ErrInvalidIPv6
+		return
+	} else if r.lo, err = v6Addr(n.IP); err != nil {
+		return
+	}
+	var mask u128
+	for i := 0; i < ones; i++ {
+		mask = mask.setBit(127 - i)
+	}
+	r.lo = u128{hi: r.lo.hi & mask.hi, lo: r.lo.lo & mask.lo}
+	r.hi = u128{hi: r.lo.hi | ^mask.hi, lo: r.lo.lo | ^mask.lo}
+	return
+}
+
+// v6Normalize sorts ranges and merges any that overlap or touch
+func v6Normalize(rs []v6range) []v6range {
+	sort.Slice(rs, func(i, j int) bool {
+		return rs[i].lo.cmp(rs[j].lo) < 0
+	})
+	out := rs[:0]
+	for _, r := range rs {
+		out = v6Append(out, r)
+	}
+	return out
+}
+
+// v6Append adds a range that starts at or after the start of the last range, merging them if they overlap or touch
+func v6Append(rs []v6range, r v6range) []v6range {
+	if l := len(rs) - 1; l >= 0 && (rs[l].hi.isMax() || rs[l].hi.add(u128{lo: 1}).cmp(r.lo) >= 0) {
+		if r.hi.cmp(rs[l].hi) > 0 {
+			rs[l].hi = r.hi
+		}
+		return rs
+	}
+	return append(rs, r)
+}
+
+func v6Union(a, b []v6range) (out []v6range) {
+	out = make([]v6range, 0, len(a)+len(b))
+	for len(a) > 0 || len(b) > 0 {
+		if len(b) == 0 || (len(a) > 0 && a[0].lo.cmp(b[0].lo) <= 0) {
+			out = v6Append(out, a[0])
+			a = a[1:]
+		} else {
+			out = v6Append(out, b[0])
+			b = b[1:]
+		}
+	}
+	return
+}
+
+func v6Intersect(a, b []v6range) (out []v6range) {
+	for len(a) > 0 && len(b) > 0 {
+		r := v6range{lo: a[0].lo, hi: a[0].hi}
+		if b[0].lo.cmp(r.lo) > 0 {
+			r.lo = b[0].lo
+		}
+		if b[0].hi.cmp(r.hi) < 0 {
+			r.hi = b[0].hi
+		}
+		if r.lo.cmp(r.hi) <= 0 {
+			out = append(out, r)
+		}
+		if a[0].hi.cmp(b[0].hi) < 0 {
+			a = a[1:]
+		} else {
+			b = b[1:]
+		}
+	}
+	return
+}
+
+func v6Difference(a, b []v6range) (out []v6range) {
+	for _, r := range a {
+		for len(b) > 0 && b[0].hi.cmp(r.lo) < 0 {
+			b = b[1:] // entirely before this range
+		}
+		for i := 0; i < len(b) && b[i].lo.cmp(r.hi) <= 0; i++ {
+			if b[i].lo.cmp(r.lo) > 0 {
+				out = append(out, v6range{lo: r.lo, hi: b[i].lo.sub(u128{lo: 1})})
+			}
+			if b[i].hi.cmp(r.hi) >= 0 {
+				r.lo, r.hi = u128{lo: 1}, u128{} // fully consumed
+				break
+			}
+			r.lo = b[i].hi.add(u128{lo: 1})
+		}
+		if r.lo.cmp(r.hi) <= 0 {
+			out = append(out, r)
+		}
+	}
+	return
+}
+
+func (v u128) cmp(o u128) int {
+	switch {
+	case v.hi < o.hi:
+		return -1
+	case v.hi > o.hi:
+		return 1
+	case v.lo < o.lo:
+		return -1
+	case v.lo > o.lo:
+		return 1
+	}
+	return 0
+}
+
+func (v u128) add(o u128) (r u128) {
+	var carry uint64
+	r.lo, carry = bits.Add64(v.lo, o.lo, 0)
+	r.hi, _ = bits.Add64(v.hi, o.hi, carry)
+	return
+}
+
+func (v u128) sub(o u128) (r u128) {
+	var borrow uint64
+	r.lo, borrow = bits.Sub64(v.lo, o.lo, 0)
+	r.hi, _ = bits.Sub64(v.hi, o.hi, borrow)
+	return
+}
+
+func (v u128) setBit(n int) u128 {
+	if n >= 64 {
+		v.hi |= 1 << (n - 64)
+	} else if n >= 0 {
+		v.lo |= 1 << n
+	}
+	return v
+}
+
+func (v u128) trailingZeros() int {
+	if v.lo != 0 {
+		return bits.TrailingZeros64(v.lo)
+	}
+	return 64 + bits.TrailingZeros64(v.hi)
+}
+
+func (v u128) isMax() bool {
+	return v.hi == 0xffffffffffffffff && v.lo == 0xffffffffffffffff
+}
+
+func (v u128) ip() net.IP {
+	ip := make(net.IP, net.IPv6len)
+	binary.BigEndian.PutUint64(ip[0:8], v.hi)
+	binary.BigEndian.PutUint64(ip[8:16], v.lo)
+	return ip
+}
@@ -0,0 +1,121 @@
+/*************************************************************************
+ * Copyright 2026 Gravwell, Inc. All rights reserved.
+ * Contact: <legal@gravwell.io>
+ *
+ * This software may be modified and distributed under the terms of the
+ * BSD 2-clause license. See the LICENSE file for details.
+ **************************************************************************/
+
+package ipexist
+
+import (
+	"bytes"
+	"net"
+	"reflect"
+	"testing"
+)
+
+func TestIPv6PrefixSet(t *testing.T) {
+	ps := NewIPv6PrefixSet()
+	addCIDRs(t, ps, `2001:db8::/32`, `2001:db9::/32`, `fe80::/64`, `2001:db8:1::/48`)
+	if err := ps.AddIP(net.ParseIP(`::1`)); err != nil {
+		t.Fatal(err)
+	}
+	if err := ps.RemoveCIDR(mustCIDR(t, `2001:db8:ffff::/48`)); err != nil {
+		t.Fatal(err)
+	}
+	if err := ps.AddIP(net.ParseIP(`1.2.3.4`)); err != ErrInvalidIPv6 {
+		t.Fatal("IPv4 address was not rejected", err)
+	}
+	checkExists(t, ps, map[string]bool{
+		`2001:db8::1`:      true,
+		`2001:db9:ffff::1`: true,
+		`2001:dba::`:       false,
+		`2001:db8:ffff::1`: false,
+		`2001:db8:fffe::1`: true,
+		`fe80::1234`:       true,
+		`fe80:0:0:1::`:     false,
+		`::1`:              true,
+		`::2`:              false,
+	})
+	if cnt, err := ps.Count(); err != nil {
+		t.Fatal(err)
+	} else if cnt != 4 {
+		t.Fatalf("bad range count %d", cnt)
+	}
+	exp := []string{`::1/128`, `2001:db8::/33`, `2001:db8:8000::/34`, `2001:db8:c000::/35`, `2001:db8:e000::/36`,
+		`2001:db8:f000::/37`, `2001:db8:f800::/38`, `2001:db8:fc00::/39`, `2001:db8:fe00::/40`, `2001:db8:ff00::/41`,
+		`2001:db8:ff80::/42`, `2001:db8:ffc0::/43`, `2001:db8:ffe0::/44`, `2001:db8:fff0::/45`, `2001:db8:fff8::/46`,
+		`2001:db8:fffc::/47`, `2001:db8:fffe::/48`, `2001:db9::/32`, `fe80::/64`}
+	if got := walkStrings(t, ps); !reflect.DeepEqual(got, exp) {
+		t.Fatalf("bad walk:\n%v\n%v", got, exp)
+	}
+
+	bb := bytes.NewBuffer(nil)
+	if err := ps.Encode(bb); err != nil {
+		t.Fatal(err)
+	}
+	mmn, err := getTempFileName()
+	if err != nil {
+		t.Fatal(err)
+	}
+	nps, err := LoadIPv6PrefixSetMemoryMapped(bb, mmn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer nps.Close()
+	if got := walkStrings(t, nps); !reflect.DeepEqual(got, exp) {
+		t.Fatalf("bad walk after decode:\n%v\n%v", got, exp)
+	}
+	// modifications must land in the memory map
+	addCIDRs(t, nps, `2001:db8:ffff::/48`)
+	checkExists(t, nps, map[string]bool{`2001:db8:ffff::1`: true, `2001:dba::`: false})
+	if cnt, _ := nps.Count(); cnt != 3 {
+		t.Fatalf("bad range count after merge %d", cnt)
+	}
+
+	if _, err = LoadIPv6PrefixSet(bytes.NewReader([]byte(`IPv4FLT1`))); err == nil {
+		t.Fatal("IPv4 encoding was accepted")
+	}
+}
+
+func TestIPv6PrefixSetOps(t *testing.T) {
+	mk := func(cidrs ...string) *IPv6PrefixSet {
+		ps := NewIPv6PrefixSet()
+		addCIDRs(t, ps, cidrs...)
+		return ps
+	}
+	a := func() *IPv6PrefixSet { return mk(`2001:db8::/31`, `fd00::/8`) }
+	b := mk(`2001:db9::/32`, `fd00::/9`, `::1/128`)
+
+	u := a()
+	if err := u.Union(b); err != nil {
+		t.Fatal(err)
+	}
+	if got, exp := walkStrings(t, u), []string{`::1/128`, `2001:db8::/31`, `fd00::/8`}; !reflect.DeepEqual(got, exp) {
+		t.Fatalf("bad union %v", got)
+	}
+	i := a()
+	if err := i.Intersect(b); err != nil {
+		t.Fatal(err)
+	}
+	if got, exp := walkStrings(t, i), []string{`2001:db9::/32`, `fd00::/9`}; !reflect.DeepEqual(got, exp) {
+		t.Fatalf("bad intersect %v", got)
+	}
+	d := a()
+	if err := d.Difference(b); err != nil {
+		t.Fatal(err)
+	}
+	if got, exp := walkStrings(t, d), []string{`2001:db8::/32`, `fd80::/9`}; !reflect.DeepEqual(got, exp) {
+		t.Fatalf("bad difference %v", got)
+	}
+
+	all := mk(`::/0`)
+	if got := walkStrings(t, all); !reflect.DeepEqual(got, []string{`::/0`}) {
+		t.Fatalf("bad walk of everything %v", got)
+	}
+	if err := all.Difference(a()); err != nil {
+		t.Fatal(err)
+	}
+	checkExists(t, all, map[string]bool{`::`: true, `2001:db9::1`: false, `ffff::1`: true})
+}
@@ -24,8 +24,9 @@ import (
 )
 
 var (
-	fIn  = flag.String("i", "", "Input file")
-	fOut = flag.String("o", "output.ipe", "Output file")
+	fIn   = flag.String("i", "", "Input file, one IPv4 or IPv6 address or CIDR per line")
+	fOut  = flag.String("o", "output.ipe", "Output file for IPv4 addresses")
+	fOut6 = flag.String("o6", "output6.ipe", "Output file for IPv6 addresses, only written if the input contains IPv6")
 )
 
 func init() {
@@ -36,8 +37,10 @@ func init() {
 	} else if *fOut == `` {
 		flag.PrintDefaults()
 		log.Fatalf("Missing output file, specify something for -o\n")
-	} else if *fIn == *fOut {
+	} else if *fIn == *fOut || *fIn == *fOut6 {
 		log.Fatalf("Input and Output files cannot be the same\n")
+	} else if *fOut == *fOut6 {
+		log.Fatalf("IPv4 and IPv6 output files cannot be the same\n")
 	}
 }
 
@@ -47,36 +50,62 @@ func main() {
 		log.Fatalf("Failed to open %s: %v\n", *fIn, err)
 	}
 	defer fin.Close()
-	fout, err := os.Create(*fOut)
-	if err != nil {
-		log.Fatalf("Failed to create %s: %v\n", *fOut, err)
-	}
-	defer fout.Close()
-
 	ipb := ipexist.NewIPBitMap()
+	ips := ipexist.NewIPv6PrefixSet()
 
 	r := bufio.NewReader(fin)
-	var cnt int
+	var cnt, cnt6 int
 	for {
 		s, err := r.ReadString('\n')
-		if err != nil {
-			if err == io.EOF {
-				err = nil
-			}
+		if err != nil && (err != io.EOF || len(s) == 0) {
 			break
 		}
 		s = strings.TrimSpace(strings.Trim(s, "\n\r\"'"))
-		if ip := net.ParseIP(s); ip != nil {
-			if ip = ip.To4(); ip != nil {
-				if err := ipb.AddIP(ip); err != nil {
-					log.Fatalf("Failed to add %s: %v\n", ip, err)
+		if s == `` || strings.HasPrefix(s, "#") {
+			continue
+		}
+		var aerr error
+		if !strings.Contains(s, "/") {
+			if ip := net.ParseIP(s); ip != nil {
+				if ip4 := ip.To4(); ip4 != nil {
+					aerr = ipb.AddIP(ip4)
+					cnt++
+				} else {
+					aerr = ips.AddIP(ip)
+					cnt6++
 				}
+			}
+		} else if _, n, perr := net.ParseCIDR(s); perr == nil {
+			if len(n.IP) == net.IPv4len {
+				aerr = ipb.AddCIDR(n)
 				cnt++
+			} else {
+				aerr = ips.AddCIDR(n)
+				cnt6++
 			}
 		}
+		if aerr != nil {
+			log.Fatalf("Failed to add %s: %v\n", s, aerr)
+		}
 	}
-	if err = ipb.Encode(fout); err != nil {
+	writeSet(*fOut, ipb)
+	if cnt6 > 0 {
+		writeSet(*fOut6, ips)
+	}
+	log.Printf("Processed %d IPv4 and %d IPv6 addresses and networks\n", cnt, cnt6)
+}
+
+type encoder interface {
+	Encode(io.Writer) error
+}
+
+func writeSet(pth string, e encoder) {
+	fout, err := os.Create(pth)
+	if err != nil {
+		log.Fatalf("Failed to create %s: %v\n", pth, err)
+	}
+	defer fout.Close()
+	if err = e.Encode(fout); err != nil {
 		log.Fatalf("Failied to encode output file: %v\n", err)
 	}
-	log.Printf("Processed %d IPs\n", cnt)
 }
@@ -0,0 +1,372 @@
+/*************************************************************************
+ * Copyright 2026 Gravwell, Inc. All rights reserved.
+ * Contact: <legal@gravwell.io>
+ *
+ * This software may be modified and distributed under the terms of the
+ * BSD 2-clause license. See the LICENSE file for details.
+ **************************************************************************/
+
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/gravwell/gravwell/v3/client/types"
+	"github.com/gravwell/gravwell/v3/client/types/kits"
+	"github.com/gravwell/gravwell/v3/ingesters/utils"
+)
+
+const (
+	maxDiffValueLen = 120  // longer single line values are summarized rather than printed
+	maxDiffLines    = 2000 // multi-line values longer than this are not line diffed
+)
+
+// kitContents is one side of a diff, items are decoded into generic JSON values
+// keyed by "<type> <name>" so that packed kits and unpacked trees compare equally.
+type kitContents struct {
+	mf    kits.Manifest
+	items map[string]interface{}
+}
+
+func itemKey(name string, tp kits.ItemType) string {
+	return tp.String() + " " + name
+}
+
+// decodeItem turns an encoded kit item into a generic value; licenses and anything
+// else that is not JSON is treated as a string.
+func decodeItem(bts []byte) (v interface{}) {
+	if err := json.Unmarshal(bts, &v); err != nil {
+		v = string(bts)
+	}
+	return
+}
+
+// loadKitContents reads either a kit file or an unpacked kit directory
+func loadKitContents(pth string) (kc kitContents, err error) {
+	var fi os.FileInfo
+	if fi, err = os.Stat(pth); err != nil {
+		return
+	}
+	kc.items = map[string]interface{}{}
+	if fi.IsDir() {
+		var mb []byte
+		if mb, err = os.ReadFile(filepath.Join(pth, kits.ManifestName)); err != nil {
+			return
+		} else if err = json.Unmarshal(mb, &kc.mf); err != nil {
+			err = fmt.Errorf("Couldn't parse MANIFEST: %v", err)
+			return
+		}
+		for _, itm := range kc.mf.Items {
+			var bts []byte
+			if bts, err = readKitItem(pth, itm); err != nil {
+				return
+			}
+			kc.items[itemKey(itm.Name, itm.Type)] = decodeItem(bts)
+		}
+		return
+	}
+
+	var fin utils.ReadResetCloser
+	if fin, err = utils.OpenFileReader(pth); err != nil {
+		return
+	}
+	defer fin.Close()
+	var rdr *kits.Reader
+	if rdr, err = kits.NewReader(fin, nil); err != nil {
+		return
+	} else if err = rdr.Verify(); err != nil {
+		return
+	} else if kc.mf, err = rdr.Manifest(); err != nil {
+		return
+	}
+	err = rdr.Process(func(name string, tp kits.ItemType, hash [sha256.Size]byte, r io.Reader) error {
+		bts, err := io.ReadAll(r)
+		if err != nil {
+			return err
+		}
+		kc.items[itemKey(name, tp)] = decodeItem(bts)
+		return nil
+	})
+	return
+}
+
+// flatten walks a decoded JSON value and produces a map of field paths to leaf values
+func flatten(prefix string, v interface{}, out map[string]interface{}) {
+	switch x := v.(type) {
+	case map[string]interface{}:
+		if len(x) == 0 {
+			out[prefix] = x
+		}
+		for k, vv := range x {
+			p := k
+			if prefix != `` {
+				p = prefix + `.` + k
+			}
+			flatten(p, vv, out)
+		}
+	case []interface{}:
+		if len(x) == 0 {
+			out[prefix] = x
+		}
+		for i, vv := range x {
+			flatten(fmt.Sprintf("%s[%d]", prefix, i), vv, out)
+		}
+	default:
+		out[prefix] = x
+	}
+}
+
+func sortedKeys(m map[string]interface{}) (r []string) {
+	for k := range m {
+		r = append(r, k)
+	}
+	sort.Strings(r)
+	return
+}
+
+func fmtValue(v interface{}) string {
+	if v == nil {
+		return `null`
+	}
+	var s string
+	if str, ok := v.(string); ok {
+		s = fmt.Sprintf("%q", str)
+	} else if b, err := json.Marshal(v); err == nil {
+		s = string(b)
+	} else {
+		s = fmt.Sprintf("%v", v)
+	}
+	if len(s) > maxDiffValueLen {
+		s = fmt.Sprintf("%s... (%d bytes)", s[:maxDiffValueLen], len(s))
+	}
+	return s
+}
+
+// diffFields produces a field level diff of two decoded items
+func diffFields(oldv, newv interface{}) (lines []string) {
+	of, nf := map[string]interface{}{}, map[string]interface{}{}
+	flatten(``, oldv, of)
+	flatten(``, newv, nf)
+	keys := map[string]interface{}{}
+	for k := range of {
+		keys[k] = nil
+	}
+	for k := range nf {
+		keys[k] = nil
+	}
+	for _, k := range sortedKeys(keys) {
+		name := k
+		if name == `` {
+			name = `(value)`
+		}
+		ov, ook := of[k]
+		nv, nok := nf[k]
+		switch {
+		case !ook:
+			lines = append(lines, fmt.Sprintf("%s: added %s", name, fmtValue(nv)))
+		case !nok:
+			lines = append(lines, fmt.Sprintf("%s: removed %s", name, fmtValue(ov)))
+		default:
+			ob, _ := json.Marshal(ov)
+			nb, _ := json.Marshal(nv)
+			if string(ob) == string(nb) {
+				continue
+			}
+			ostr, ook := ov.(string)
+			nstr, nok := nv.(string)
+			if ook && nok && (strings.Contains(ostr, "\n") || strings.Contains(nstr, "\n")) {
+				lines = append(lines, name+":")
+				for _, l := range diffLines(ostr, nstr) {
+					lines = append(lines, "  "+l)
+				}
+			} else {
+				lines = append(lines, fmt.Sprintf("%s: %s -> %s", name, fmtValue(ov), fmtValue(nv)))
+			}
+		}
+	}
+	return
+}
+
+// diffLines is a simple LCS line diff used for queries, scripts, and macro expansions
+func diffLines(a, b string) (r []string) {
+	al, bl := strings.Split(a, "\n"), strings.Split(b, "\n")
+	if len(al) > maxDiffLines || len(bl) > maxDiffLines {
+		return []string{fmt.Sprintf("changed (%d lines -> %d lines)", len(al), len(bl))}
+	}
+	lcs := make([][]int, len(al)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(bl)+1)
+	}
+	for i := len(al) - 1; i >= 0; i-- {
+		for j := len(bl) - 1; j >= 0; j-- {
+			if al[i] == bl[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+	var i, j int
+	for i < len(al) && j < len(bl) {
+		if al[i] == bl[j] {
+			i++
+			j++
+		} else if lcs[i+1][j] >= lcs[i][j+1] {
+			r = append(r, "- "+al[i])
+			i++
+		} else {
+			r = append(r, "+ "+bl[j])
+			j++
+		}
+	}
+	for ; i < len(al); i++ {
+		r = append(r, "- "+al[i])
+	}
+	for ; j < len(bl); j++ {
+		r = append(r, "+ "+bl[j])
+	}
+	return
+}
+
+// diffManifests summarizes changes to the kit itself rather than its items
+func diffManifests(o, n kits.Manifest) (lines []string) {
+	str := func(name string, ov, nv interface{}) {
+		if fmt.Sprint(ov) != fmt.Sprint(nv) {
+			lines = append(lines, fmt.Sprintf("%s: %v -> %v", name, fmtValue(ov), fmtValue(nv)))
+		}
+	}
+	str(`ID`, o.ID, n.ID)
+	str(`Name`, o.Name, n.Name)
+	str(`Description`, o.Desc, n.Desc)
+	if o.Version != n.Version {
+		lines = append(lines, fmt.Sprintf("Version: %d -> %d", o.Version, n.Version))
+		if n.Version < o.Version {
+			lines = append(lines, "  WARNING: version went backwards")
+		}
+	}
+	if o.MinVersion.Compare(n.MinVersion) != 0 {
+		lines = append(lines, fmt.Sprintf("MinVersion: %v -> %v", o.MinVersion, n.MinVersion))
+	}
+	if o.MaxVersion.Compare(n.MaxVersion) != 0 {
+		lines = append(lines, fmt.Sprintf("MaxVersion: %v -> %v", o.MaxVersion, n.MaxVersion))
+	}
+	str(`Icon`, o.Icon, n.Icon)
+	str(`Cover`, o.Cover, n.Cover)
+	str(`Banner`, o.Banner, n.Banner)
+
+	od, nd := map[string]uint{}, map[string]uint{}
+	for _, d := range o.Dependencies {
+		od[d.ID] = d.MinVersion
+	}
+	for _, d := range n.Dependencies {
+		nd[d.ID] = d.MinVersion
+	}
+	for _, d := range n.Dependencies {
+		if ov, ok := od[d.ID]; !ok {
+			lines = append(lines, fmt.Sprintf("Dependency added: %v >= %v", d.ID, d.MinVersion))
+		} else if ov != d.MinVersion {
+			lines = append(lines, fmt.Sprintf("Dependency changed: %v >= %v -> %v >= %v", d.ID, ov, d.ID, d.MinVersion))
+		}
+	}
+	for _, d := range o.Dependencies {
+		if _, ok := nd[d.ID]; !ok {
+			lines = append(lines, fmt.Sprintf("Dependency removed: %v >= %v", d.ID, d.MinVersion))
+		}
+	}
+
+	oc, nc := map[string]types.KitConfigMacro{}, map[string]types.KitConfigMacro{}
+	for _, m := range o.ConfigMacros {
+		oc[m.MacroName] = m
+	}
+	for _, m := range n.ConfigMacros {
+		nc[m.MacroName] = m
+	}
+	for _, m := range n.ConfigMacros {
+		if om, ok := oc[m.MacroName]; !ok {
+			lines = append(lines, fmt.Sprintf("Config macro added: %v (default %q)", m.MacroName, m.DefaultValue))
+		} else if om.DefaultValue != m.DefaultValue || om.Type != m.Type || om.Description != m.Description {
+			lines = append(lines, fmt.Sprintf("Config macro changed: %v", m.MacroName))
+		}
+	}
+	for _, m := range o.ConfigMacros {
+		if _, ok := nc[m.MacroName]; !ok {
+			lines = append(lines, fmt.Sprintf("Config macro removed: %v", m.MacroName))
+		}
+	}
+	return
+}
+
+// the "diff" command compares two kits, each of which may be a kit file or an unpacked directory
+func diffKit(args []string) {
+	if len(args) != 2 {
+		fmt.Printf("Usage: kitctl diff <old kit or directory> <new kit or directory>\n")
+		return
+	}
+	oldKit, err := loadKitContents(args[0])
+	if err != nil {
+		log.Fatalf("Could not load %v: %v", args[0], err)
+	}
+	newKit, err := loadKitContents(args[1])
+	if err != nil {
+		log.Fatalf("Could not load %v: %v", args[1], err)
+	}
+
+	if writeKitDiff(os.Stdout, oldKit, newKit) {
+		os.Exit(1)
+	}
+}
+
+// writeKitDiff prints the manifest and item changes between two kits, returning true if anything changed
+func writeKitDiff(w io.Writer, oldKit, newKit kitContents) (changed bool) {
+	if lines := diffManifests(oldKit.mf, newKit.mf); len(lines) > 0 {
+		changed = true
+		fmt.Fprintln(w, "Manifest:")
+		for _, l := range lines {
+			fmt.Fprintf(w, "	%s\n", l)
+		}
+	}
+
+	var added, removed, modified int
+	all := map[string]interface{}{}
+	for k := range oldKit.items {
+		all[k] = nil
+	}
+	for k := range newKit.items {
+		all[k] = nil
+	}
+	for _, k := range sortedKeys(all) {
+		ov, ook := oldKit.items[k]
+		nv, nok := newKit.items[k]
+		switch {
+		case !ook:
+			added++
+			fmt.Fprintf(w, "+ %s\n", k)
+		case !nok:
+			removed++
+			fmt.Fprintf(w, "- %s\n", k)
+		default:
+			if lines := diffFields(ov, nv); len(lines) > 0 {
+				modified++
+				fmt.Fprintf(w, "~ %s\n", k)
+				for _, l := range lines {
+					fmt.Fprintf(w, "	%s\n", l)
+				}
+			}
+		}
+	}
+	if added+removed+modified > 0 {
+		changed = true
+	}
+	fmt.Fprintf(w, "%d added, %d removed, %d changed\n", added, removed, modified)
+	return
+}
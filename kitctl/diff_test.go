@@ -0,0 +1,100 @@
+/*************************************************************************
+ * Copyright 2026 Gravwell, Inc. All rights reserved.
+ * Contact: <legal@gravwell.io>
+ *
+ * This software may be modified and distributed under the terms of the
+ * BSD 2-clause license. See the LICENSE file for details.
+ **************************************************************************/
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/gravwell/gravwell/v3/client/types"
+	"github.com/gravwell/gravwell/v3/client/types/kits"
+)
+
+// load writes out the manifest and reads the kit back the way the diff command does
+func (k *testKit) load() kitContents {
+	k.t.Helper()
+	mb, err := json.Marshal(k.mf)
+	if err != nil {
+		k.t.Fatal(err)
+	} else if err = os.WriteFile(filepath.Join(k.dir, kits.ManifestName), mb, 0644); err != nil {
+		k.t.Fatal(err)
+	}
+	kc, err := loadKitContents(k.dir)
+	if err != nil {
+		k.t.Fatal(err)
+	}
+	return kc
+}
+
+func TestKitDiff(t *testing.T) {
+	ok := newTestKit(t)
+	ok.macro(`FOO`, "tag=foo\n| count")
+	ok.macro(`BAR`, `tag=bar`)
+	ok.macro(`SAME`, `tag=same`)
+	ok.add(kits.License, `lic`, writeLicense(ok.dir, `lic`, []byte(`MIT`)))
+
+	nk := newTestKit(t)
+	nk.mf.Version = 2
+	nk.mf.Dependencies = []types.KitDependency{{ID: `io.gravwell.other`, MinVersion: 3}}
+	nk.macro(`FOO`, "tag=foo\n| stats count\n| table")
+	nk.macro(`BAZ`, `tag=baz`)
+	nk.macro(`SAME`, `tag=same`)
+	nk.add(kits.License, `lic`, writeLicense(nk.dir, `lic`, []byte(`BSD`)))
+
+	var out bytes.Buffer
+	if !writeKitDiff(&out, ok.load(), nk.load()) {
+		t.Fatal("changes not reported")
+	}
+	exp := "Manifest:\n" +
+		"\tVersion: 1 -> 2\n" +
+		"\tDependency added: io.gravwell.other >= 3\n" +
+		"~ license lic\n" +
+		"\t(value): \"MIT\" -> \"BSD\"\n" +
+		"- macro BAR\n" +
+		"+ macro BAZ\n" +
+		"~ macro FOO\n" +
+		"\tExpansion:\n" +
+		"\t  - | count\n" +
+		"\t  + | stats count\n" +
+		"\t  + | table\n" +
+		"1 added, 1 removed, 2 changed\n"
+	if out.String() != exp {
+		t.Fatalf("bad diff output:\n%s\nexpected:\n%s", out.String(), exp)
+	}
+
+	//identical kits
+	out.Reset()
+	if writeKitDiff(&out, ok.load(), ok.load()) {
+		t.Fatal("identical kits reported as changed")
+	} else if out.String() != "0 added, 0 removed, 0 changed\n" {
+		t.Fatalf("bad diff output for identical kits: %q", out.String())
+	}
+}
+
+func TestDiffFields(t *testing.T) {
+	var oldv, newv interface{}
+	json.Unmarshal([]byte(`{"Name":"a","Labels":["x"],"Data":{"keep":1,"drop":true}}`), &oldv)
+	json.Unmarshal([]byte(`{"Name":"b","Labels":["x","y"],"Data":{"keep":1,"add":"z"}}`), &newv)
+	exp := []string{
+		`Data.add: added "z"`,
+		`Data.drop: removed true`,
+		`Labels[1]: added "y"`,
+		`Name: "a" -> "b"`,
+	}
+	if got := diffFields(oldv, newv); !reflect.DeepEqual(got, exp) {
+		t.Fatalf("got %q, expected %q", got, exp)
+	}
+	if got := diffFields(oldv, oldv); len(got) != 0 {
+		t.Fatalf("identical values differ: %q", got)
+	}
+}
@@ -0,0 +1,468 @@
+/*************************************************************************
+ * Copyright 2026 Gravwell, Inc. All rights reserved.
+ * Contact: <legal@gravwell.io>
+ *
+ * This software may be modified and distributed under the terms of the
+ * BSD 2-clause license. See the LICENSE file for details.
+ **************************************************************************/
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/gravwell/gravwell/v3/client/types"
+	"github.com/gravwell/gravwell/v3/client/types/kits"
+)
+
+const (
+	lintWarn  = `WARNING`
+	lintError = `ERROR`
+)
+
+var (
+	// macros are referenced in queries as $NAME
+	macroRefRegex = regexp.MustCompile(`\$([A-Z][A-Z0-9_-]*)`)
+
+	// the first Gravwell versions that can install a given feature
+	verAlerts            = types.CanonicalVersion{Major: 5, Minor: 4}
+	verFlows             = types.CanonicalVersion{Major: 4, Minor: 2}
+	verTemplateVariables = types.CanonicalVersion{Major: 5}
+	verSearchReference   = types.CanonicalVersion{Major: 5}
+)
+
+type lintIssue struct {
+	level string
+	item  string
+	msg   string
+}
+
+// lintRef is a reference from one kit item to another by GUID
+type lintRef struct {
+	owner string
+	kind  string // what we expect to find, e.g. "template"
+	id    string
+}
+
+// linter accumulates everything it learns about the kit while walking the items,
+// references are only resolved once every item has been read.
+type linter struct {
+	wd     string
+	mf     kits.Manifest
+	issues []lintIssue
+
+	guids    map[string][]string // guid -> items carrying it
+	kinds    map[string]string   // guid -> kind of item
+	macros   map[string]bool     // regular macros defined in the kit
+	used     map[string]bool     // macros referenced anywhere in the kit
+	queries  map[string][]string // item -> queries and expansions
+	refs     []lintRef
+	features map[string]types.CanonicalVersion // feature description -> minimum version
+}
+
+func newLinter(wd string, mf kits.Manifest) *linter {
+	return &linter{
+		wd:       wd,
+		mf:       mf,
+		guids:    map[string][]string{},
+		kinds:    map[string]string{},
+		macros:   map[string]bool{},
+		used:     map[string]bool{},
+		queries:  map[string][]string{},
+		features: map[string]types.CanonicalVersion{},
+	}
+}
+
+func (l *linter) add(level, item, format string, args ...interface{}) {
+	l.issues = append(l.issues, lintIssue{level: level, item: item, msg: fmt.Sprintf(format, args...)})
+}
+
+func (l *linter) errorf(item, format string, args ...interface{}) {
+	l.add(lintError, item, format, args...)
+}
+
+func (l *linter) warnf(item, format string, args ...interface{}) {
+	l.add(lintWarn, item, format, args...)
+}
+
+// missing reports a reference that cannot be resolved within the kit. If the kit has
+// dependencies the object may be provided by one of them, so it is only a warning.
+func (l *linter) missing(item, format string, args ...interface{}) {
+	if len(l.mf.Dependencies) > 0 {
+		l.warnf(item, format+" (it may be provided by a dependency)", args...)
+	} else {
+		l.errorf(item, format, args...)
+	}
+}
+
+func (l *linter) guid(item, kind, id string) {
+	if id == `` || id == uuid.Nil.String() {
+		return
+	}
+	id = strings.ToLower(id)
+	l.guids[id] = append(l.guids[id], item)
+	l.kinds[id] = kind
+}
+
+func (l *linter) query(item string, q ...string) {
+	for _, v := range q {
+		if v != `` {
+			l.queries[item] = append(l.queries[item], v)
+		}
+	}
+}
+
+func (l *linter) ref(item, kind, id string) {
+	if id == `` || id == uuid.Nil.String() {
+		return
+	}
+	l.refs = append(l.refs, lintRef{owner: item, kind: kind, id: strings.ToLower(id)})
+}
+
+func (l *linter) feature(name string, v types.CanonicalVersion) {
+	if cur, ok := l.features[name]; !ok || cur.Compare(v) > 0 {
+		l.features[name] = v
+	}
+}
+
+func (l *linter) run() {
+	l.lintManifest()
+	seen := map[string]bool{}
+	for _, itm := range l.mf.Items {
+		key := itm.Type.String() + " " + itm.Name
+		if seen[key] {
+			l.errorf(key, "listed in the manifest more than once")
+			continue
+		}
+		seen[key] = true
+		l.lintItem(key, itm)
+	}
+	l.lintGUIDs()
+	l.lintRefs()
+	l.lintMacros()
+	l.lintVersion()
+}
+
+func (l *linter) lintManifest() {
+	if l.mf.ID == `` {
+		l.errorf(`MANIFEST`, "missing kit ID")
+	}
+	if l.mf.Name == `` {
+		l.errorf(`MANIFEST`, "missing kit name")
+	}
+	if l.mf.Version == 0 {
+		l.errorf(`MANIFEST`, "kit version must be greater than zero")
+	}
+	if l.mf.MaxVersion.Enabled() && l.mf.MaxVersion.Compare(l.mf.MinVersion) > 0 {
+		l.errorf(`MANIFEST`, "MaxVersion %v is lower than MinVersion %v", l.mf.MaxVersion, l.mf.MinVersion)
+	}
+	for _, d := range l.mf.Dependencies {
+		if d.ID == l.mf.ID {
+			l.errorf(`MANIFEST`, "kit depends on itself")
+		}
+	}
+	cm := map[string]bool{}
+	for _, m := range l.mf.ConfigMacros {
+		if err := types.CheckMacroName(m.MacroName); err != nil {
+			l.errorf(`MANIFEST`, "config macro %q: %v", m.MacroName, err)
+		}
+		if cm[m.MacroName] {
+			l.errorf(`MANIFEST`, "config macro %v is defined more than once", m.MacroName)
+		}
+		cm[m.MacroName] = true
+		if t := strings.ToUpper(m.Type); t != `TAG` && t != `OTHER` {
+			l.errorf(`MANIFEST`, "config macro %v has invalid type %q", m.MacroName, m.Type)
+		}
+		l.query(`config macro `+m.MacroName, m.DefaultValue)
+	}
+	// the icon, cover, and banner must be files in the kit
+	images := map[string]string{`icon`: l.mf.Icon, `cover`: l.mf.Cover, `banner`: l.mf.Banner}
+	for k, v := range images {
+		if v != `` {
+			l.ref(`MANIFEST `+k, `file`, v)
+		}
+	}
+}
+
+func (l *linter) lintItem(key string, itm kits.Item) {
+	var err error
+	switch itm.Type {
+	case kits.Resource:
+		var x kits.PackedResource
+		if x, err = readResource(l.wd, itm.Name); err == nil {
+			x.ResourceName = itm.Name
+			err = x.Validate()
+		}
+	case kits.Macro:
+		var x kits.PackedMacro
+		if x, err = readMacro(l.wd, itm.Name); err == nil {
+			if err = x.Validate(); err == nil {
+				if x.Name != itm.Name {
+					l.warnf(key, "macro name %q does not match the manifest", x.Name)
+				}
+				l.macros[x.Name] = true
+				l.query(key, x.Expansion)
+			}
+		}
+	case kits.ScheduledSearch:
+		var x kits.PackedScheduledSearch
+		if x, err = readScheduledSearch(l.wd, itm.Name); err == nil {
+			if err = x.Validate(); err == nil {
+				l.guid(key, `scheduled search`, x.GUID.String())
+				l.query(key, x.SearchString, x.Flow)
+				l.ref(key, `search library`, x.SearchReference.String())
+				if x.TypeName() == types.ScheduledTypeFlow {
+					l.feature(`flows`, verFlows)
+					if x.Flow != `` && !json.Valid([]byte(x.Flow)) {
+						l.errorf(key, "flow is not valid JSON")
+					}
+				}
+				if x.SearchReference != uuid.Nil {
+					l.feature(`scheduled searches referencing the search library`, verSearchReference)
+				}
+			}
+		}
+	case kits.Dashboard:
+		var x kits.PackedDashboard
+		if x, err = readDashboard(l.wd, itm.Name); err == nil {
+			if err = x.Validate(); err == nil {
+				l.guid(key, `dashboard`, x.UUID)
+				err = l.lintDashboard(key, x)
+			}
+		}
+	case kits.Template:
+		var x types.PackedUserTemplate
+		if x, err = readTemplate(l.wd, itm.Name); err == nil {
+			l.guid(key, `template`, x.UUID)
+			l.lintTemplate(key, x)
+		}
+	case kits.Pivot:
+		var x types.PackedPivot
+		// the pivot data is a raw object, so it is already valid JSON if the read succeeds
+		if err = genericRead(l.wd, itm, &x); err == nil {
+			l.guid(key, `pivot`, x.UUID)
+		}
+	case kits.Extractor:
+		var x types.AXDefinition
+		if x, err = readExtractor(l.wd, itm.Name); err == nil {
+			l.guid(key, `extractor`, x.UUID.String())
+			err = x.Validate()
+		}
+	case kits.File:
+		var x types.UserFile
+		if x, err = readUserFile(l.wd, itm.Name); err == nil {
+			l.guid(key, `file`, x.GUID.String())
+			if len(x.Contents) == 0 {
+				l.warnf(key, "file is empty")
+			}
+		}
+	case kits.SearchLibrary:
+		var x types.WireSearchLibrary
+		if x, err = readSearchLibrary(l.wd, itm.Name); err == nil {
+			l.guid(key, `search library`, x.GUID.String())
+			l.query(key, x.Query)
+			if x.Query == `` {
+				l.errorf(key, "search library entry has no query")
+			}
+		}
+	case kits.Playbook:
+		var x types.Playbook
+		if x, err = readPlaybook(l.wd, itm.Name); err == nil {
+			l.guid(key, `playbook`, x.GUID.String())
+			if len(x.Metadata) > 0 && !json.Valid(x.Metadata) {
+				l.errorf(key, "playbook metadata is not valid JSON")
+			}
+			if len(x.Body) == 0 {
+				l.warnf(key, "playbook has an empty body")
+			}
+		}
+	case kits.Alert:
+		var x types.AlertDefinition
+		if err = genericRead(l.wd, itm, &x); err == nil {
+			l.guid(key, `alert`, x.GUID.String())
+			l.feature(`alerts`, verAlerts)
+			for _, d := range x.Dispatchers {
+				l.ref(key, `scheduled search`, d.ID)
+			}
+			for _, c := range x.Consumers {
+				l.ref(key, `scheduled search`, c.ID)
+			}
+		}
+	case kits.License:
+		var x []byte
+		if x, err = readLicense(l.wd, itm.Name); err == nil && len(x) == 0 {
+			l.errorf(key, "license is empty")
+		}
+	default:
+		l.errorf(key, "unknown item type %v", itm.Type)
+		return
+	}
+	if err != nil {
+		l.errorf(key, "%v", err)
+	}
+}
+
+// lintDashboard pulls the searches out of the dashboard, which is otherwise
+// entirely the domain of the GUI, so we only look at the fields we care about.
+func (l *linter) lintDashboard(key string, x kits.PackedDashboard) error {
+	var data struct {
+		Searches []struct {
+			Alias     string
+			Query     string
+			Reference *struct {
+				ID   string
+				Type string
+			}
+		}
+	}
+	if err := json.Unmarshal(x.Data, &data); err != nil {
+		return fmt.Errorf("invalid dashboard data: %w", err)
+	}
+	for _, s := range data.Searches {
+		l.query(key, s.Query)
+		if s.Reference == nil {
+			continue
+		}
+		switch s.Reference.Type {
+		case `template`:
+			l.ref(key, `template`, s.Reference.ID)
+		case `savedQuery`:
+			l.ref(key, `search library`, s.Reference.ID)
+		case `scheduledSearch`:
+			l.ref(key, `scheduled search`, s.Reference.ID)
+		}
+	}
+	return nil
+}
+
+func (l *linter) lintTemplate(key string, x types.PackedUserTemplate) {
+	l.query(key, x.Data.Query)
+	if x.Name == `` {
+		l.errorf(key, "template has no name")
+	}
+	if x.Data.Query == `` {
+		l.errorf(key, "template has no query")
+	}
+	if len(x.Data.Variables) > 1 {
+		l.feature(`templates with multiple variables`, verTemplateVariables)
+	}
+	names := map[string]bool{}
+	for _, v := range x.Data.Variables {
+		if v.Name == `` {
+			l.errorf(key, "template variable has no name")
+			continue
+		} else if names[v.Name] {
+			l.errorf(key, "template variable %v is defined more than once", v.Name)
+		}
+		names[v.Name] = true
+		if !strings.Contains(x.Data.Query, v.Name) {
+			l.warnf(key, "template variable %v is not used in the query", v.Name)
+		}
+	}
+}
+
+func (l *linter) lintGUIDs() {
+	for id, owners := range l.guids {
+		if len(owners) > 1 {
+			l.errorf(strings.Join(owners, ", "), "duplicate GUID %v", id)
+		}
+	}
+}
+
+func (l *linter) lintRefs() {
+	for _, r := range l.refs {
+		if kind, ok := l.kinds[r.id]; !ok {
+			l.missing(r.owner, "references %v %v which is not in the kit", r.kind, r.id)
+		} else if kind != r.kind {
+			l.errorf(r.owner, "references %v %v but that GUID belongs to a %v", r.kind, r.id, kind)
+		}
+	}
+}
+
+func (l *linter) lintMacros() {
+	configMacros := map[string]bool{}
+	for _, m := range l.mf.ConfigMacros {
+		configMacros[m.MacroName] = true
+		if l.macros[m.MacroName] {
+			l.errorf(`MANIFEST`, "config macro %v conflicts with a regular macro", m.MacroName)
+		}
+	}
+	for item, qs := range l.queries {
+		for _, q := range qs {
+			for _, m := range macroRefRegex.FindAllStringSubmatch(q, -1) {
+				name := m[1]
+				l.used[name] = true
+				if !l.macros[name] && !configMacros[name] {
+					l.missing(item, "uses undefined macro %v", name)
+				}
+			}
+		}
+	}
+	for _, m := range l.mf.ConfigMacros {
+		if !l.used[m.MacroName] {
+			l.warnf(`MANIFEST`, "config macro %v is never referenced", m.MacroName)
+		}
+	}
+}
+
+func (l *linter) lintVersion() {
+	for name, v := range l.features {
+		if !l.mf.MinVersion.Enabled() || l.mf.MinVersion.Compare(v) > 0 {
+			l.errorf(`MANIFEST`, "kit uses %v which require Gravwell %v, but MinVersion is %v", name, v, l.mf.MinVersion)
+		}
+	}
+}
+
+// sorted returns the issues with errors first, then ordered by item and message
+// so that output is stable between runs.
+func (l *linter) sorted() []lintIssue {
+	r := append([]lintIssue(nil), l.issues...)
+	sort.SliceStable(r, func(i, j int) bool {
+		if r[i].level != r[j].level {
+			return r[i].level == lintError
+		} else if r[i].item != r[j].item {
+			return r[i].item < r[j].item
+		}
+		return r[i].msg < r[j].msg
+	})
+	return r
+}
+
+func (l *linter) count(level string) (n int) {
+	for _, i := range l.issues {
+		if i.level == level {
+			n++
+		}
+	}
+	return
+}
+
+// the "lint" command checks the unpacked kit in the current directory for problems
+// that would otherwise only show up once the kit is installed.
+func lintKit(args []string) {
+	wd, err := os.Getwd()
+	if err != nil {
+		log.Fatalf("Couldn't figure out working directory: %v", err)
+	}
+	mf, err := readManifest()
+	if err != nil {
+		log.Fatal(err)
+	}
+	l := newLinter(wd, mf)
+	l.run()
+	for _, i := range l.sorted() {
+		fmt.Printf("%v: %v: %v\n", i.level, i.item, i.msg)
+	}
+	errs, warns := l.count(lintError), l.count(lintWarn)
+	fmt.Printf("%d errors, %d warnings\n", errs, warns)
+	if errs > 0 || (*fStrict && warns > 0) {
+		os.Exit(1)
+	}
+}
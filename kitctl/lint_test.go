@@ -0,0 +1,353 @@
+/*************************************************************************
+ * Copyright 2026 Gravwell, Inc. All rights reserved.
+ * Contact: <legal@gravwell.io>
+ *
+ * This software may be modified and distributed under the terms of the
+ * BSD 2-clause license. See the LICENSE file for details.
+ **************************************************************************/
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/gravwell/gravwell/v3/client/types"
+	"github.com/gravwell/gravwell/v3/client/types/kits"
+)
+
+// testKit is an unpacked kit in a temporary directory
+type testKit struct {
+	t   *testing.T
+	dir string
+	mf  kits.Manifest
+}
+
+func newTestKit(t *testing.T) *testKit {
+	return &testKit{
+		t:   t,
+		dir: t.TempDir(),
+		mf: kits.Manifest{
+			ID:         `io.gravwell.test`,
+			Name:       `test`,
+			Version:    1,
+			MinVersion: types.CanonicalVersion{Major: 5, Minor: 4},
+		},
+	}
+}
+
+func (k *testKit) add(tp kits.ItemType, name string, err error) {
+	k.t.Helper()
+	if err != nil {
+		k.t.Fatal(err)
+	}
+	k.mf.Items = append(k.mf.Items, kits.Item{Name: name, Type: tp})
+}
+
+func (k *testKit) macro(name, expansion string) {
+	k.add(kits.Macro, name, writeMacro(k.dir, kits.PackedMacro{Name: name, Expansion: expansion}))
+}
+
+func (k *testKit) template(name string, x types.PackedUserTemplate) {
+	k.add(kits.Template, name, writeTemplate(k.dir, name, x))
+}
+
+func (k *testKit) file(name string, id uuid.UUID, contents string) {
+	k.add(kits.File, name, writeUserFile(k.dir, name, types.UserFile{GUID: id, Name: name, Contents: []byte(contents)}))
+}
+
+func (k *testKit) search(name string, id uuid.UUID, query string) {
+	var x types.WireSearchLibrary
+	x.Name, x.GUID, x.Query = name, id, query
+	k.add(kits.SearchLibrary, name, writeSearchLibrary(k.dir, name, x))
+}
+
+func (k *testKit) scheduled(name string, x kits.PackedScheduledSearch) {
+	x.Name, x.Schedule, x.Duration = name, `* * * * *`, -3600
+	k.add(kits.ScheduledSearch, name, writeScheduledSearch(k.dir, name, x))
+}
+
+func (k *testKit) dashboard(name, data string) {
+	x := kits.PackedDashboard{UUID: uuid.NewString(), Name: name, Data: types.RawObject(data)}
+	k.add(kits.Dashboard, name, writeDashboard(k.dir, name, x))
+}
+
+func (k *testKit) lint() []lintIssue {
+	l := newLinter(k.dir, k.mf)
+	l.run()
+	return l.issues
+}
+
+func TestLintRules(t *testing.T) {
+	ida, idb := uuid.New(), uuid.New()
+	tmpl := func(query string, vars ...string) types.PackedUserTemplate {
+		x := types.PackedUserTemplate{UUID: ida.String(), Name: `tmpl`, Data: types.TemplateContents{Query: query}}
+		for _, v := range vars {
+			x.Data.Variables = append(x.Data.Variables, types.TemplateVariable{Name: v})
+		}
+		return x
+	}
+	configMacro := func(k *testKit, name, tp string) {
+		k.mf.ConfigMacros = append(k.mf.ConfigMacros, types.KitConfigMacro{MacroName: name, Type: tp, DefaultValue: `default`})
+	}
+	tests := []struct {
+		name  string
+		level string
+		msg   string
+		pass  func(k *testKit)
+		fail  func(k *testKit)
+	}{
+		{
+			name: `duplicate item`, level: lintError, msg: `listed in the manifest more than once`,
+			pass: func(k *testKit) { k.macro(`FOO`, `tag=foo`) },
+			fail: func(k *testKit) { k.macro(`FOO`, `tag=foo`); k.mf.Items = append(k.mf.Items, k.mf.Items[0]) },
+		},
+		{
+			name: `kit ID`, level: lintError, msg: `missing kit ID`,
+			pass: func(k *testKit) {},
+			fail: func(k *testKit) { k.mf.ID = `` },
+		},
+		{
+			name: `kit name`, level: lintError, msg: `missing kit name`,
+			pass: func(k *testKit) {},
+			fail: func(k *testKit) { k.mf.Name = `` },
+		},
+		{
+			name: `kit version`, level: lintError, msg: `kit version must be greater than zero`,
+			pass: func(k *testKit) {},
+			fail: func(k *testKit) { k.mf.Version = 0 },
+		},
+		{
+			name: `version range`, level: lintError, msg: `is lower than MinVersion`,
+			pass: func(k *testKit) { k.mf.MaxVersion = types.CanonicalVersion{Major: 5, Minor: 4} },
+			fail: func(k *testKit) { k.mf.MaxVersion = types.CanonicalVersion{Major: 5, Minor: 3} },
+		},
+		{
+			name: `self dependency`, level: lintError, msg: `kit depends on itself`,
+			pass: func(k *testKit) { k.mf.Dependencies = []types.KitDependency{{ID: `io.gravwell.other`}} },
+			fail: func(k *testKit) { k.mf.Dependencies = []types.KitDependency{{ID: k.mf.ID}} },
+		},
+		{
+			name: `config macro name`, level: lintError, msg: `config macro "bad name"`,
+			pass: func(k *testKit) { configMacro(k, `GOOD_NAME`, `TAG`) },
+			fail: func(k *testKit) { configMacro(k, `bad name`, `TAG`) },
+		},
+		{
+			name: `config macro duplicate`, level: lintError, msg: `config macro CM is defined more than once`,
+			pass: func(k *testKit) { configMacro(k, `CM`, `TAG`); configMacro(k, `CM2`, `TAG`) },
+			fail: func(k *testKit) { configMacro(k, `CM`, `TAG`); configMacro(k, `CM`, `TAG`) },
+		},
+		{
+			name: `config macro type`, level: lintError, msg: `has invalid type`,
+			pass: func(k *testKit) { configMacro(k, `CM`, `other`) },
+			fail: func(k *testKit) { configMacro(k, `CM`, `number`) },
+		},
+		{
+			name: `config macro conflict`, level: lintError, msg: `conflicts with a regular macro`,
+			pass: func(k *testKit) { configMacro(k, `CM`, `TAG`); k.macro(`FOO`, `tag=$CM`) },
+			fail: func(k *testKit) { configMacro(k, `FOO`, `TAG`); k.macro(`FOO`, `tag=$FOO`) },
+		},
+		{
+			name: `config macro unused`, level: lintWarn, msg: `config macro CM is never referenced`,
+			pass: func(k *testKit) { configMacro(k, `CM`, `TAG`); k.macro(`FOO`, `tag=$CM`) },
+			fail: func(k *testKit) { configMacro(k, `CM`, `TAG`) },
+		},
+		{
+			name: `manifest image`, level: lintError, msg: `references file`,
+			pass: func(k *testKit) { k.file(`icon`, ida, `png`); k.mf.Icon = ida.String() },
+			fail: func(k *testKit) { k.mf.Icon = ida.String() },
+		},
+		{
+			name: `macro name`, level: lintWarn, msg: `does not match the manifest`,
+			pass: func(k *testKit) { k.macro(`FOO`, `tag=foo`) },
+			fail: func(k *testKit) {
+				k.macro(`FOO`, `tag=foo`)
+				if err := genericWrite(k.dir, kits.Macro, `FOO`, kits.PackedMacro{Name: `BAR`}); err != nil {
+					k.t.Fatal(err)
+				}
+			},
+		},
+		{
+			name: `invalid item`, level: lintError, msg: `Missing macro expansion`,
+			pass: func(k *testKit) { k.macro(`FOO`, `tag=foo`) },
+			fail: func(k *testKit) { k.macro(`FOO`, ``) },
+		},
+		{
+			name: `unknown item type`, level: lintError, msg: `unknown item type`,
+			pass: func(k *testKit) { k.macro(`FOO`, `tag=foo`) },
+			fail: func(k *testKit) { k.mf.Items = append(k.mf.Items, kits.Item{Name: `x`, Type: kits.ItemType(99)}) },
+		},
+		{
+			name: `undefined macro`, level: lintError, msg: `uses undefined macro BAR`,
+			pass: func(k *testKit) { k.macro(`BAR`, `tag=bar`); k.macro(`FOO`, `$BAR | count`) },
+			fail: func(k *testKit) { k.macro(`FOO`, `$BAR | count`) },
+		},
+		{
+			name: `undefined macro with dependencies`, level: lintWarn, msg: `uses undefined macro BAR (it may be provided by a dependency)`,
+			pass: func(k *testKit) { k.mf.Dependencies = []types.KitDependency{{ID: `io.gravwell.other`}} },
+			fail: func(k *testKit) {
+				k.mf.Dependencies = []types.KitDependency{{ID: `io.gravwell.other`}}
+				k.macro(`FOO`, `$BAR | count`)
+			},
+		},
+		{
+			name: `duplicate GUID`, level: lintError, msg: `duplicate GUID`,
+			pass: func(k *testKit) { k.file(`a`, ida, `a`); k.file(`b`, idb, `b`) },
+			fail: func(k *testKit) { k.file(`a`, ida, `a`); k.file(`b`, ida, `b`) },
+		},
+		{
+			name: `missing reference`, level: lintError, msg: `references search library`,
+			pass: func(k *testKit) {
+				k.search(`lib`, ida, `tag=foo`)
+				k.scheduled(`sched`, kits.PackedScheduledSearch{SearchReference: ida})
+			},
+			fail: func(k *testKit) { k.scheduled(`sched`, kits.PackedScheduledSearch{SearchReference: ida}) },
+		},
+		{
+			name: `reference kind`, level: lintError, msg: `but that GUID belongs to a file`,
+			pass: func(k *testKit) {
+				k.template(`tmpl`, tmpl(`tag=foo`))
+				k.dashboard(`dash`, `{"searches":[{"reference":{"id":"`+ida.String()+`","type":"template"}}]}`)
+			},
+			fail: func(k *testKit) {
+				k.file(`tmpl`, ida, `not a template`)
+				k.dashboard(`dash`, `{"searches":[{"reference":{"id":"`+ida.String()+`","type":"template"}}]}`)
+			},
+		},
+		{
+			name: `dashboard data`, level: lintError, msg: `invalid dashboard data`,
+			pass: func(k *testKit) { k.dashboard(`dash`, `{"searches":[{"query":"tag=foo"}]}`) },
+			fail: func(k *testKit) { k.dashboard(`dash`, `[1, 2]`) },
+		},
+		{
+			name: `template name`, level: lintError, msg: `template has no name`,
+			pass: func(k *testKit) { k.template(`tmpl`, tmpl(`tag=foo`)) },
+			fail: func(k *testKit) { x := tmpl(`tag=foo`); x.Name = ``; k.template(`tmpl`, x) },
+		},
+		{
+			name: `template query`, level: lintError, msg: `template has no query`,
+			pass: func(k *testKit) { k.template(`tmpl`, tmpl(`tag=foo`)) },
+			fail: func(k *testKit) { k.template(`tmpl`, tmpl(``)) },
+		},
+		{
+			name: `template variable name`, level: lintError, msg: `template variable has no name`,
+			pass: func(k *testKit) { k.template(`tmpl`, tmpl(`tag=%%VAR%%`, `%%VAR%%`)) },
+			fail: func(k *testKit) { k.template(`tmpl`, tmpl(`tag=foo`, ``)) },
+		},
+		{
+			name: `template variable duplicate`, level: lintError, msg: `template variable %%VAR%% is defined more than once`,
+			pass: func(k *testKit) { k.template(`tmpl`, tmpl(`tag=%%VAR%% %%VAR2%%`, `%%VAR%%`, `%%VAR2%%`)) },
+			fail: func(k *testKit) { k.template(`tmpl`, tmpl(`tag=%%VAR%%`, `%%VAR%%`, `%%VAR%%`)) },
+		},
+		{
+			name: `template variable unused`, level: lintWarn, msg: `is not used in the query`,
+			pass: func(k *testKit) { k.template(`tmpl`, tmpl(`tag=%%VAR%%`, `%%VAR%%`)) },
+			fail: func(k *testKit) { k.template(`tmpl`, tmpl(`tag=foo`, `%%VAR%%`)) },
+		},
+		{
+			name: `flow JSON`, level: lintError, msg: `flow is not valid JSON`,
+			pass: func(k *testKit) {
+				k.scheduled(`flow`, kits.PackedScheduledSearch{ScheduledType: types.ScheduledTypeFlow, Flow: `{}`})
+			},
+			fail: func(k *testKit) {
+				k.scheduled(`flow`, kits.PackedScheduledSearch{ScheduledType: types.ScheduledTypeFlow, Flow: `{`})
+			},
+		},
+		{
+			name: `unreadable item`, level: lintError, msg: `unexpected end of JSON input`,
+			pass: func(k *testKit) {
+				k.add(kits.Pivot, `pivot`, genericWrite(k.dir, kits.Pivot, `pivot`, types.PackedPivot{UUID: ida.String(), Data: types.RawObject(`{}`)}))
+			},
+			fail: func(k *testKit) {
+				k.add(kits.Pivot, `pivot`, genericWrite(k.dir, kits.Pivot, `pivot`, types.PackedPivot{UUID: ida.String()}))
+				pth := filepath.Join(k.dir, kits.Pivot.Ext(), `pivot.meta`)
+				if err := os.WriteFile(pth, []byte(`{"Data": {`), 0644); err != nil {
+					k.t.Fatal(err)
+				}
+			},
+		},
+		{
+			name: `empty file`, level: lintWarn, msg: `file is empty`,
+			pass: func(k *testKit) { k.file(`a`, ida, `a`) },
+			fail: func(k *testKit) { k.file(`a`, ida, ``) },
+		},
+		{
+			name: `search library query`, level: lintError, msg: `search library entry has no query`,
+			pass: func(k *testKit) { k.search(`lib`, ida, `tag=foo`) },
+			fail: func(k *testKit) { k.search(`lib`, ida, ``) },
+		},
+		{
+			name: `playbook metadata`, level: lintError, msg: `playbook metadata is not valid JSON`,
+			pass: func(k *testKit) {
+				k.add(kits.Playbook, `pb`, writePlaybook(k.dir, `pb`, types.Playbook{GUID: ida, Body: []byte(`#`), Metadata: []byte(`{}`)}))
+			},
+			fail: func(k *testKit) {
+				k.add(kits.Playbook, `pb`, writePlaybook(k.dir, `pb`, types.Playbook{GUID: ida, Body: []byte(`#`), Metadata: []byte(`{`)}))
+			},
+		},
+		{
+			name: `playbook body`, level: lintWarn, msg: `playbook has an empty body`,
+			pass: func(k *testKit) {
+				k.add(kits.Playbook, `pb`, writePlaybook(k.dir, `pb`, types.Playbook{GUID: ida, Body: []byte(`#`)}))
+			},
+			fail: func(k *testKit) {
+				k.add(kits.Playbook, `pb`, writePlaybook(k.dir, `pb`, types.Playbook{GUID: ida}))
+			},
+		},
+		{
+			name: `license`, level: lintError, msg: `license is empty`,
+			pass: func(k *testKit) { k.add(kits.License, `lic`, writeLicense(k.dir, `lic`, []byte(`MIT`))) },
+			fail: func(k *testKit) { k.add(kits.License, `lic`, writeLicense(k.dir, `lic`, nil)) },
+		},
+		{
+			name: `feature version`, level: lintError, msg: `kit uses alerts which require Gravwell 5.4.0`,
+			pass: func(k *testKit) {
+				k.add(kits.Alert, `alert`, genericWrite(k.dir, kits.Alert, `alert`, types.AlertDefinition{GUID: ida}))
+			},
+			fail: func(k *testKit) {
+				k.mf.MinVersion = types.CanonicalVersion{Major: 5, Minor: 3}
+				k.add(kits.Alert, `alert`, genericWrite(k.dir, kits.Alert, `alert`, types.AlertDefinition{GUID: ida}))
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			k := newTestKit(t)
+			tc.pass(k)
+			for _, i := range k.lint() {
+				if strings.Contains(i.msg, tc.msg) {
+					t.Fatalf("passing kit flagged: %v: %v: %v", i.level, i.item, i.msg)
+				}
+			}
+			k = newTestKit(t)
+			tc.fail(k)
+			issues := k.lint()
+			for _, i := range issues {
+				if i.level == tc.level && strings.Contains(i.msg, tc.msg) {
+					return
+				}
+			}
+			t.Fatalf("expected %v %q, got %+v", tc.level, tc.msg, issues)
+		})
+	}
+}
+
+func TestLintSorted(t *testing.T) {
+	k := newTestKit(t)
+	k.mf.Name = ``
+	k.template(`tmpl`, types.PackedUserTemplate{Name: `tmpl`, Data: types.TemplateContents{
+		Query:     `tag=foo`,
+		Variables: []types.TemplateVariable{{Name: `%%VAR%%`}},
+	}})
+	l := newLinter(k.dir, k.mf)
+	l.run()
+	if l.count(lintError) != 1 || l.count(lintWarn) != 1 {
+		t.Fatalf("bad counts: %+v", l.issues)
+	}
+	if s := l.sorted(); s[0].level != lintError || s[1].level != lintWarn {
+		t.Fatalf("errors not sorted first: %+v", s)
+	}
+}
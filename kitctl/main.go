@@ -37,6 +37,10 @@ var (
 
 	fDefaultValue = flag.String("default-value", "", "Default value")
 	fMacroType    = flag.String("macro-type", "", "Config macro type ('tag' or 'other')")
+
+	fKey         = flag.String("key", "", "Private key used to sign kits (pack and sign commands)")
+	fTrustedKeys = flag.String("trusted-keys", "", "Directory of trusted public keys (verify command)")
+	fStrict      = flag.Bool("strict", false, "Treat lint warnings as errors")
 )
 
 func main() {
@@ -79,6 +83,21 @@ func main() {
 	case "configmacro":
 		// Manage config macros
 		configMacro(args[1:])
+	case "lint":
+		// Check the kit in the current directory for problems
+		lintKit(args[1:])
+	case "diff":
+		// Compare two kits
+		diffKit(args[1:])
+	case "keygen":
+		// Generate a kit signing key pair
+		keygen(args[1:])
+	case "sign":
+		// Sign an existing kit file
+		signKit(args[1:])
+	case "verify":
+		// Check a kit file's signature against the trusted keys
+		verifyKit(args[1:])
 	default:
 		log.Fatalf("Invalid command %v. Try kitctl help", args[0])
 	}
@@ -93,6 +112,7 @@ func help(args []string) {
 	fmt.Println("	import <input file>: include the contents of another kit into the already-unpacked kit in the current directory")
 	fmt.Println("	info: prints information about the kit in the current directory")
 	fmt.Println("	init: starts a new kit from scratch in the current directory")
+	fmt.Println("	lint: checks the kit in the current directory for broken references and invalid items")
 	fmt.Println("	dep list: list the current kit's dependencies")
 	fmt.Println("	dep add: add another dependency to the current kit")
 	fmt.Println("	dep del: delete a dependency from the current kit")
@@ -100,6 +120,10 @@ func help(args []string) {
 	fmt.Println("	configmacro show: show info about a particular config macro")
 	fmt.Println("	configmacro add: add a new config macro to the kit")
 	fmt.Println("	configmacro del: delete a config macro from the kit")
+	fmt.Println("	diff <old> <new>: show the differences between two kit files or unpacked kit directories")
+	fmt.Println("	keygen <name>: generate a kit signing key pair, <name>.key and <name>.pub")
+	fmt.Println("	sign <input file> [output file]: sign a kit file with the key given by -key")
+	fmt.Println("	verify <input file>: check that a kit file is signed by a key in the -trusted-keys directory")
 	fmt.Println("")
 	fmt.Println("Flags:")
 	flag.PrintDefaults()
@@ -360,6 +384,8 @@ func packKit(args []string) {
 		fmt.Printf("Usage: kitctl pack <outfile>\n")
 		return
 	}
+	// Load the signing key up front so we don't leave a half built kit behind
+	key := signingKey()
 
 	mf, err := readManifest()
 	if err != nil {
@@ -385,120 +411,14 @@ func packKit(args []string) {
 		log.Fatalf("Could not get builder: %v", err)
 	}
 
-	marshallAdd := func(itm kits.Item, obj interface{}) error {
-		bts, err := json.Marshal(obj)
+	// Walk each kit item in the manifest and add it
+	for _, itm := range mf.Items {
+		bts, err := readKitItem(wd, itm)
 		if err != nil {
-			return fmt.Errorf("Could not marshal %v %v: %v", itm.Type.String(), itm.Name, err)
+			log.Fatal(err)
 		}
 		if err := bldr.Add(itm.Name, itm.Type, bts); err != nil {
-			return fmt.Errorf("Couldn't add %v %v: %v", itm.Type.String(), itm.Name, err)
-		}
-		return nil
-	}
-
-	// Walk each kit item in the manifest and add it
-	for _, itm := range mf.Items {
-		switch itm.Type {
-		// Some types have special "packed" versions
-		case kits.Resource:
-			pr, err := readResource(wd, itm.Name)
-			if err != nil {
-				log.Fatalf("Could not read resource %v: %v", itm.Name, err)
-			}
-			if err := marshallAdd(itm, pr); err != nil {
-				log.Fatal(err)
-			}
-		case kits.Macro:
-			pm, err := readMacro(wd, itm.Name)
-			if err != nil {
-				log.Fatalf("Could not read macro %v: %v", itm.Name, err)
-			}
-			if err := marshallAdd(itm, pm); err != nil {
-				log.Fatal(err)
-			}
-		case kits.ScheduledSearch:
-			x, err := readScheduledSearch(wd, itm.Name)
-			if err != nil {
-				log.Fatalf("Could not read scheduled search %v: %v", itm.Name, err)
-			}
-			if err := marshallAdd(itm, x); err != nil {
-				log.Fatal(err)
-			}
-		case kits.Dashboard:
-			x, err := readDashboard(wd, itm.Name)
-			if err != nil {
-				log.Fatalf("Could not read dashboard %v: %v", itm.Name, err)
-			}
-			if err := marshallAdd(itm, x); err != nil {
-				log.Fatal(err)
-			}
-		case kits.Template:
-			var x types.PackedUserTemplate
-			if x, err = readTemplate(wd, itm.Name); err != nil {
-				log.Fatalf("Could not read %v %v: %v", itm.Type.String(), itm.Name, err)
-			}
-			if err := marshallAdd(itm, x); err != nil {
-				log.Fatal(err)
-			}
-		case kits.Pivot:
-			var x types.PackedPivot
-			if err := genericRead(wd, itm, &x); err != nil {
-				log.Fatalf("Could not read %v %v: %v", itm.Type.String(), itm.Name, err)
-			}
-			if err := marshallAdd(itm, x); err != nil {
-				log.Fatal(err)
-			}
-		// Other types just ship as-is
-		case kits.Extractor:
-			var x types.AXDefinition
-			if x, err = readExtractor(wd, itm.Name); err != nil {
-				log.Fatalf("Could not read %v %v: %v", itm.Type.String(), itm.Name, err)
-			}
-			if err := marshallAdd(itm, x); err != nil {
-				log.Fatal(err)
-			}
-		case kits.File:
-			var x types.UserFile
-			if x, err = readUserFile(wd, itm.Name); err != nil {
-				log.Fatalf("Could not read %v %v: %v", itm.Type.String(), itm.Name, err)
-			}
-			if err := marshallAdd(itm, x); err != nil {
-				log.Fatal(err)
-			}
-		case kits.SearchLibrary:
-			var x types.WireSearchLibrary
-			if x, err = readSearchLibrary(wd, itm.Name); err != nil {
-				log.Fatalf("Could not read %v %v: %v", itm.Type.String(), itm.Name, err)
-			}
-			if err := marshallAdd(itm, x); err != nil {
-				log.Fatal(err)
-			}
-		case kits.Playbook:
-			var x types.Playbook
-			if x, err = readPlaybook(wd, itm.Name); err != nil {
-				log.Fatalf("Could not read %v %v: %v", itm.Type.String(), itm.Name, err)
-			}
-			if err := marshallAdd(itm, x); err != nil {
-				log.Fatal(err)
-			}
-		case kits.Alert:
-			var x types.AlertDefinition
-			if err = genericRead(wd, itm, &x); err != nil {
-				log.Fatalf("Could not read %v %v: %v", itm.Type.String(), itm.Name, err)
-			}
-			if err := marshallAdd(itm, x); err != nil {
-				log.Fatal(err)
-			}
-		case kits.License:
-			x, err := readLicense(wd, itm.Name)
-			if err != nil {
-				log.Fatalf("Could not read license %v: %v", itm.Name, err)
-			}
-			if err := bldr.Add(itm.Name, itm.Type, x); err != nil {
-				log.Fatalf("Couldn't add %v %v: %v", itm.Type.String(), itm.Name, err)
-			}
-		default:
-			log.Fatalf("Error parsing item %v, unknown item type %v", itm.Name, itm.Type)
+			log.Fatalf("Couldn't add %v %v: %v", itm.Type.String(), itm.Name, err)
 		}
 	}
 
@@ -519,7 +439,20 @@ func packKit(args []string) {
 		}
 	}
 
-	if err = bldr.WriteManifest(nil); err != nil {
+	// Sign the manifest if we were handed a key
+	var sig []byte
+	if key != nil {
+		mf := bldr.Manifest()
+		mb, err := mf.Marshal()
+		if err != nil {
+			log.Fatalf("Could not encode manifest for signing: %v", err)
+		}
+		if sig, err = kits.SignManifest(key, mb); err != nil {
+			log.Fatalf("Could not sign manifest: %v", err)
+		}
+	}
+
+	if err = bldr.WriteManifest(sig); err != nil {
 		log.Fatalf("Could not write manifest: %v", err)
 	} else if err = bldr.Close(); err != nil {
 		log.Fatalf("Could not close builder: %v", err)
@@ -556,3 +556,58 @@ func genericRead(dir string, itm kits.Item, obj interface{}) (err error) {
 	}
 	return
 }
+
+/**************************************************************************
+ * Kit Items
+ **************************************************************************/
+
+// readKitItem reads the item from the unpacked kit and returns it encoded
+// exactly as it is stored in a packed kit.
+func readKitItem(wd string, itm kits.Item) (bts []byte, err error) {
+	var obj interface{}
+	switch itm.Type {
+	// Some types have special "packed" versions
+	case kits.Resource:
+		obj, err = readResource(wd, itm.Name)
+	case kits.Macro:
+		obj, err = readMacro(wd, itm.Name)
+	case kits.ScheduledSearch:
+		obj, err = readScheduledSearch(wd, itm.Name)
+	case kits.Dashboard:
+		obj, err = readDashboard(wd, itm.Name)
+	case kits.Template:
+		obj, err = readTemplate(wd, itm.Name)
+	case kits.Pivot:
+		var x types.PackedPivot
+		err = genericRead(wd, itm, &x)
+		obj = x
+	// Other types just ship as-is
+	case kits.Extractor:
+		obj, err = readExtractor(wd, itm.Name)
+	case kits.File:
+		obj, err = readUserFile(wd, itm.Name)
+	case kits.SearchLibrary:
+		obj, err = readSearchLibrary(wd, itm.Name)
+	case kits.Playbook:
+		obj, err = readPlaybook(wd, itm.Name)
+	case kits.Alert:
+		var x types.AlertDefinition
+		err = genericRead(wd, itm, &x)
+		obj = x
+	case kits.License:
+		// licenses are not JSON
+		if bts, err = readLicense(wd, itm.Name); err != nil {
+			err = fmt.Errorf("Could not read license %v: %v", itm.Name, err)
+		}
+		return
+	default:
+		err = fmt.Errorf("Error parsing item %v, unknown item type %v", itm.Name, itm.Type)
+		return
+	}
+	if err != nil {
+		err = fmt.Errorf("Could not read %v %v: %v", itm.Type.String(), itm.Name, err)
+	} else if bts, err = json.Marshal(obj); err != nil {
+		err = fmt.Errorf("Could not marshal %v %v: %v", itm.Type.String(), itm.Name, err)
+	}
+	return
+}
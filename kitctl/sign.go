@@ -0,0 +1,164 @@
+/*************************************************************************
+ * Copyright 2026 Gravwell, Inc. All rights reserved.
+ * Contact: <legal@gravwell.io>
+ *
+ * This software may be modified and distributed under the terms of the
+ * BSD 2-clause license. See the LICENSE file for details.
+ **************************************************************************/
+
+package main
+
+import (
+	"crypto/ed25519"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/gravwell/gravwell/v3/client/types/kits"
+	"github.com/gravwell/gravwell/v3/ingesters/utils"
+)
+
+// the "keygen" command creates a new ed25519 signing key pair
+func keygen(args []string) {
+	if len(args) != 1 {
+		fmt.Printf("Usage: kitctl keygen <key name>\n")
+		return
+	}
+	privPath := args[0] + kits.PrivateKeyExt
+	pubPath := args[0] + kits.PublicKeyExt
+	for _, p := range []string{privPath, pubPath} {
+		if _, err := os.Stat(p); !os.IsNotExist(err) {
+			log.Fatalf("%v already exists, aborting", p)
+		}
+	}
+	pub, priv, err := kits.GenerateSigningKey()
+	if err != nil {
+		log.Fatalf("Failed to generate key: %v", err)
+	}
+	pb, err := kits.EncodePublicKey(pub)
+	if err != nil {
+		log.Fatalf("Failed to encode public key: %v", err)
+	}
+	vb, err := kits.EncodePrivateKey(priv)
+	if err != nil {
+		log.Fatalf("Failed to encode private key: %v", err)
+	}
+	if err = os.WriteFile(privPath, vb, 0600); err != nil {
+		log.Fatalf("Failed to write private key: %v", err)
+	}
+	if err = os.WriteFile(pubPath, pb, 0644); err != nil {
+		log.Fatalf("Failed to write public key: %v", err)
+	}
+	fmt.Printf("Private key written to %v, keep it safe\n", privPath)
+	fmt.Printf("Public key written to %v, copy it into the trusted keys directory\n", pubPath)
+}
+
+// signingKey loads the private key specified by the -key flag, if any
+func signingKey() ed25519.PrivateKey {
+	if *fKey == `` {
+		return nil
+	}
+	key, err := kits.LoadPrivateKey(*fKey)
+	if err != nil {
+		log.Fatalf("Could not load signing key: %v", err)
+	}
+	return key
+}
+
+// the "sign" command signs an existing kit file, in place unless an output file is given
+func signKit(args []string) {
+	if len(args) != 1 && len(args) != 2 {
+		fmt.Printf("Usage: kitctl -key <private key> sign <kitfile> [output file]\n")
+		return
+	}
+	key := signingKey()
+	if key == nil {
+		log.Fatalf("Must specify the signing key with the -key flag")
+	}
+	out := args[0]
+	if len(args) == 2 {
+		out = args[1]
+	}
+
+	// make sure we are signing something sane
+	fi, err := utils.OpenFileReader(args[0])
+	if err != nil {
+		log.Fatalf("Could not open file %v: %v", args[0], err)
+	}
+	defer fi.Close()
+	rdr, err := kits.NewReader(fi, nil)
+	if err != nil {
+		log.Fatalf("Could not get reader for kit file: %v", err)
+	}
+	if err = rdr.Verify(); err != nil {
+		log.Fatalf("Could not verify kit: %v", err)
+	}
+	if err = fi.Reset(); err != nil {
+		log.Fatalf("Could not reset kit file: %v", err)
+	}
+
+	// write to a temporary file next to the output so that signing in place is safe
+	fout, err := os.CreateTemp(filepath.Dir(out), filepath.Base(out))
+	if err != nil {
+		log.Fatalf("Could not create output file: %v", err)
+	}
+	tmp := fout.Name()
+	if err = kits.SignKit(fi, fout, key); err != nil {
+		fout.Close()
+		os.Remove(tmp)
+		log.Fatalf("Could not sign kit: %v", err)
+	}
+	if err = fout.Close(); err != nil {
+		os.Remove(tmp)
+		log.Fatalf("Could not write signed kit: %v", err)
+	}
+	if err = os.Chmod(tmp, 0644); err != nil {
+		os.Remove(tmp)
+		log.Fatalf("Could not set permissions on signed kit: %v", err)
+	}
+	if err = os.Rename(tmp, out); err != nil {
+		os.Remove(tmp)
+		log.Fatalf("Could not write signed kit: %v", err)
+	}
+}
+
+// the "verify" command checks that a kit file was signed by one of the trusted keys
+func verifyKit(args []string) {
+	if len(args) != 1 {
+		fmt.Printf("Usage: kitctl -trusted-keys <directory> verify <kitfile>\n")
+		return
+	}
+	if *fTrustedKeys == `` {
+		log.Fatalf("Must specify the trusted keys directory with the -trusted-keys flag")
+	}
+	keys, err := kits.LoadTrustedKeys(*fTrustedKeys)
+	if err != nil {
+		log.Fatalf("Could not load trusted keys: %v", err)
+	} else if len(keys) == 0 {
+		log.Fatalf("No public keys found in %v", *fTrustedKeys)
+	}
+
+	fi, err := utils.OpenFileReader(args[0])
+	if err != nil {
+		log.Fatalf("Could not open file %v: %v", args[0], err)
+	}
+	defer fi.Close()
+	rdr, err := kits.NewReader(fi, kits.NewEd25519Verifier(keys))
+	if err != nil {
+		log.Fatalf("Could not get reader for kit file: %v", err)
+	}
+	if err = rdr.Verify(); err != nil {
+		log.Fatalf("Could not verify kit: %v", err)
+	}
+	if signed, err := rdr.Signed(); err != nil {
+		log.Fatalf("Kit signature check failed: %v", err)
+	} else if !signed {
+		log.Fatalf("Kit is not signed")
+	}
+	mf, err := rdr.Manifest()
+	if err != nil {
+		log.Fatalf("Failed to read manifest: %v", err)
+	}
+	fmt.Printf("Kit %v version %v is signed by a trusted key\n", mf.ID, mf.Version)
+}
@@ -11,8 +11,10 @@
 package sqs_common
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
@@ -21,11 +23,18 @@ import (
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/sqs"
 	"github.com/gravwell/gravwell/v3/ingest/log"
+	"github.com/gravwell/gravwell/v3/ingesters/utils/deadletter"
+)
+
+const (
+	sentTimestampAttr = "SentTimestamp"
+	receiveCountAttr  = "ApproximateReceiveCount"
 )
 
 type Config struct {
 	Queue       string
 	Region      string
+	Endpoint    string // optional, for SQS compatible services
 	Credentials *credentials.Credentials
 }
 
@@ -43,10 +52,14 @@ func SQSListener(c *Config) (*SQS, error) {
 		conf: c,
 	}
 
-	s.sess, err = session.NewSession(&aws.Config{
+	cfg := &aws.Config{
 		Region:      aws.String(c.Region),
 		Credentials: c.Credentials,
-	})
+	}
+	if c.Endpoint != `` {
+		cfg.Endpoint = aws.String(c.Endpoint)
+	}
+	s.sess, err = session.NewSession(cfg)
 	if err != nil {
 		return nil, err
 	}
@@ -58,12 +71,9 @@ func SQSListener(c *Config) (*SQS, error) {
 
 // GetMessages returns one or more messages from the queue on this SQS object.
 func (s *SQS) GetMessages() ([]*sqs.Message, error) {
-	// aws uses string pointers, so we have to declare it on the
-	// stack in order to take it's reference... why aws, why......
-	an := "SentTimestamp"
 	var maxMessages int64 = 10
 	req := &sqs.ReceiveMessageInput{
-		AttributeNames:      []*string{&an},
+		AttributeNames:      []*string{aws.String(sentTimestampAttr), aws.String(receiveCountAttr)},
 		MaxNumberOfMessages: &maxMessages,
 	}
 
@@ -110,6 +120,89 @@ func (s *SQS) DeleteMessages(m []*sqs.Message, lg *log.Logger) error {
 	return err
 }
 
+// ReceiveCount returns the number of times SQS has delivered the message, 0 if unknown
+func ReceiveCount(m *sqs.Message) int {
+	if m == nil {
+		return 0
+	}
+	v, ok := m.Attributes[receiveCountAttr]
+	if !ok || v == nil {
+		return 0
+	}
+	n, err := strconv.Atoi(*v)
+	if err != nil {
+		return 0
+	}
+	return n
+}
+
+// Letter packages a message from this queue that failed processing for dead lettering
+func (s *SQS) Letter(m *sqs.Message, err error) deadletter.Letter {
+	var id string
+	var body []byte
+	if m.MessageId != nil {
+		id = *m.MessageId
+	}
+	if m.Body != nil {
+		body = []byte(*m.Body)
+	}
+	l := deadletter.NewLetter(s.conf.Queue, id, ReceiveCount(m), body, err)
+	if len(m.Attributes) > 0 {
+		l.Attributes = make(map[string]string, len(m.Attributes))
+		for k, v := range m.Attributes {
+			if v != nil {
+				l.Attributes[k] = *v
+			}
+		}
+	}
+	return l
+}
+
+// DeadLetterQueue returns a forwarder that sends dead lettered messages to the queue at url,
+// the original body is sent as is and the failure context is attached as message attributes.
+// A nil Forwarder is returned if url is empty.
+func (s *SQS) DeadLetterQueue(url string) deadletter.Forwarder {
+	if url == `` {
+		return nil
+	}
+	return &deadLetterQueue{svc: s.svc, url: url}
+}
+
+type deadLetterQueue struct {
+	svc *sqs.SQS
+	url string
+}
+
+func (d *deadLetterQueue) Forward(ctx context.Context, l deadletter.Letter) error {
+	attrs := map[string]*sqs.MessageAttributeValue{
+		"Gravwell-Source": {
+			DataType:    aws.String("String"),
+			StringValue: aws.String(l.Source),
+		},
+		"Gravwell-Receive-Count": {
+			DataType:    aws.String("Number"),
+			StringValue: aws.String(strconv.Itoa(l.Receives)),
+		},
+	}
+	// SQS rejects empty attribute values
+	if l.Error != `` {
+		attrs["Gravwell-Error"] = &sqs.MessageAttributeValue{
+			DataType:    aws.String("String"),
+			StringValue: aws.String(l.Error),
+		}
+	}
+	_, err := d.svc.SendMessageWithContext(ctx, &sqs.SendMessageInput{
+		QueueUrl:          aws.String(d.url),
+		MessageBody:       aws.String(string(l.Body)),
+		MessageAttributes: attrs,
+	})
+	return err
+}
+
+func (d *deadLetterQueue) String() string {
+	return d.url
+}
+
 func GetCredentials(t, akid, secret string) (*credentials.Credentials, error) {
 	var c *credentials.Credentials
 
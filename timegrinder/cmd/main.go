@@ -11,7 +11,6 @@ import (
 	"flag"
 	"fmt"
 	"log"
-	"os"
 	"regexp"
 
 	// Embed tzdata so that we don't rely on potentially broken timezone DBs on the host
@@ -24,6 +23,7 @@ var (
 	cName   = flag.String("custom-format-name", "", "Name for a custom format")
 	cRegex  = flag.String("custom-format-regex", "", "Extraction regular expression for custom format")
 	cFormat = flag.String("custom-format", "", "Parse format for custom format")
+	cStyle  = flag.String("custom-format-style", "", "Pattern style of the custom format: go, strptime, java, or joda")
 )
 
 func main() {
@@ -31,7 +31,8 @@ func main() {
 	var cust timegrinder.CustomFormat
 	flag.Parse()
 	if *cFormat != `` {
-		if *cRegex == `` {
+		// strptime and java patterns can build their own extraction regex
+		if *cRegex == `` && (*cStyle == `` || *cStyle == timegrinder.FormatStyleGo) {
 			log.Fatalf("missing custom-format-regex for %s", *cFormat)
 		} else if *cName == `` {
 			log.Fatalf("missing custom-format-name for %s", *cFormat)
@@ -39,10 +40,15 @@ func main() {
 			log.Fatalf("Failed to parse regex %q %v", *cRegex, err)
 		}
 		cust = timegrinder.CustomFormat{
-			Name:   *cName,
-			Regex:  *cRegex,
-			Format: *cFormat,
+			Name:         *cName,
+			Regex:        *cRegex,
+			Format:       *cFormat,
+			Format_Style: *cStyle,
 		}
+		if err := cust.Validate(); err != nil {
+			log.Fatalf("Invalid custom format: %v", err)
+		}
+		custActive = true
 	}
 
 	cfg := timegrinder.Config{
@@ -60,10 +66,10 @@ func main() {
 		}
 	}
 
-	if len(os.Args) == 0 {
+	if flag.NArg() == 0 {
 		log.Fatal("not values to test")
 	}
-	for _, arg := range os.Args[1:] {
+	for _, arg := range flag.Args() {
 		ts, offset, name, err := tg.DebugExtract([]byte(arg))
 		if err != nil {
 			fmt.Printf("Extraction error %q - %v\n", arg, err)
@@ -34,6 +34,10 @@ type CustomFormat struct {
 	Regex  string
 	Format string
 
+	// optional pattern dialect of Format, strptime and java patterns are translated and the Regex
+	// may be omitted as one is generated from the pattern
+	Format_Style string
+
 	// optional pre-extraction system that can go get the meat of a timestamp before actually trying to handle the timestamp
 	Extraction_Regex string
 
@@ -41,6 +45,7 @@ type CustomFormat struct {
 	yearMissing bool // indicates that the extraction doesn't set the year
 
 	pre preExtractor
+	pat *timePattern // translated Format, nil for Go layouts
 }
 
 // Validate will check that the custom format is well formed and usable
@@ -55,21 +60,25 @@ func (cf *CustomFormat) Validate() (err error) {
 	} else if cf.Format == `` {
 		return ErrMissingFormat
 	}
+	if cf.pat, err = newTimePattern(cf.Format_Style, cf.Format); err != nil {
+		return
+	}
 
-	if cf.Regex != `` {
+	if cf.regex() != `` {
 		if _, ok := tg.GetProcessor(cf.Format); ok {
 			err = fmt.Errorf("Cannot specify a format name (%v) and a regex", cf.Format)
 			return
 		}
 		//check that the regex compiles
 		var rx *regexp.Regexp
-		if rx, err = regexp.Compile(cf.Regex); err != nil {
+		if rx, err = regexp.Compile(cf.regex()); err != nil {
 			return
 		}
 
 		//check that we can produce and consume a timestamp using the format
 		var t time.Time
-		if t, err = time.Parse(cf.Format, time.Now().Format(cf.Format)); err != nil {
+		sample := cf.format(time.Now())
+		if t, err = cf.parse(sample, time.UTC); err != nil {
 			err = fmt.Errorf("Invalid time format: %w", err)
 			return
 		} else if t.IsZero() || t.Equal(zeroTime) {
@@ -83,7 +92,7 @@ func (cf *CustomFormat) Validate() (err error) {
 			cf.yearMissing = true
 		}
 		// Try to match it
-		if _, _, ok := match(rx, nil, []byte(time.Now().Format(cf.Format))); !ok {
+		if _, _, ok := match(rx, nil, []byte(sample)); !ok {
 			err = ErrRegexFormatMismatch
 			return
 		}
@@ -111,9 +120,39 @@ func (cf *CustomFormat) Validate() (err error) {
 }
 
 func (cf CustomFormat) ExtractionRegex() string {
+	return cf.regex()
+}
+
+// regex returns the extraction regex, translated formats generate one if it was omitted
+func (cf CustomFormat) regex() string {
+	if cf.Regex == `` && cf.pat != nil {
+		return cf.pat.rx
+	}
 	return cf.Regex
 }
 
+// layout returns the Go layout used for extractions, empty if the format has no Go equivalent
+func (cf CustomFormat) layout() string {
+	if cf.pat != nil {
+		return cf.pat.layout
+	}
+	return cf.Format
+}
+
+func (cf CustomFormat) format(t time.Time) string {
+	if cf.pat != nil {
+		return cf.pat.format(t)
+	}
+	return t.Format(cf.Format)
+}
+
+func (cf CustomFormat) parse(v string, loc *time.Location) (time.Time, error) {
+	if cf.pat != nil {
+		return cf.pat.parse(v, loc)
+	}
+	return time.ParseInLocation(cf.Format, v, loc)
+}
+
 type customProcessor struct {
 	CustomFormat
 	rx     *regexp.Regexp
@@ -124,11 +163,11 @@ func NewCustomProcessor(cf CustomFormat) (p Processor, err error) {
 	if err = cf.Validate(); err != nil {
 		return
 	}
-	if cf.Regex != `` {
+	if cf.regex() != `` {
 		cp := &customProcessor{
 			CustomFormat: cf,
 		}
-		if cp.rx, err = regexp.Compile(cf.Regex); err != nil {
+		if cp.rx, err = regexp.Compile(cf.regex()); err != nil {
 			return
 		}
 		p = cp
@@ -151,7 +190,7 @@ func NewCustomProcessor(cf CustomFormat) (p Processor, err error) {
 }
 
 func (cp *customProcessor) ToString(t time.Time) string {
-	return t.Format(cp.CustomFormat.Format)
+	return cp.CustomFormat.format(t)
 }
 
 func (cp *customProcessor) Match(d []byte) (int, int, bool) {
@@ -159,7 +198,12 @@ func (cp *customProcessor) Match(d []byte) (int, int, bool) {
 }
 
 func (cp *customProcessor) Extract(d []byte, loc *time.Location) (t time.Time, ok bool, offset int) {
-	if t, ok, offset = extract(cp.rx, nil, d, cp.CustomFormat.Format, loc, cp.window); ok && cp.dateMissing {
+	if l := cp.layout(); l != `` {
+		t, ok, offset = extract(cp.rx, nil, d, l, loc, cp.window)
+	} else {
+		t, ok, offset = cp.pat.extract(cp.rx, d, loc, cp.window)
+	}
+	if ok && cp.dateMissing {
 		t = addDate(t)
 	}
 	//check if we need to set the year
@@ -169,11 +213,19 @@ func (cp *customProcessor) Extract(d []byte, loc *time.Location) (t time.Time, o
 	return
 }
 
+func (cp *customProcessor) YearMissing() bool {
+	return cp.yearMissing
+}
+
 func (cp *customProcessor) Name() string {
 	return cp.CustomFormat.Name
 }
 
+// Format returns the Go layout of the format, translated formats with no Go equivalent return the original pattern
 func (cp *customProcessor) Format() string {
+	if l := cp.layout(); l != `` {
+		return l
+	}
 	return cp.CustomFormat.Format
 }
 
@@ -258,3 +310,11 @@ func (pep preExtractProcessor) Extract(d []byte, loc *time.Location) (t time.Tim
 func (pep preExtractProcessor) Name() string {
 	return pep.name
 }
+
+func (pep preExtractProcessor) YearMissing() bool {
+	return yearMissing(pep.Processor)
+}
+
+func (pep preExtractProcessor) SetZoneAbbreviations(zones map[string]*time.Location) {
+	setZoneAbbreviations(pep.Processor, zones)
+}
@@ -0,0 +1,505 @@
+/*************************************************************************
+ * Copyright 2026 Gravwell, Inc. All rights reserved.
+ * Contact: <legal@gravwell.io>
+ *
+ * This software may be modified and distributed under the terms of the
+ * BSD 2-clause license. See the LICENSE file for details.
+ **************************************************************************/
+
+package timegrinder
+
+import (
+	"errors"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+var (
+	ErrNoTimestampFound = errors.New("Could not find a time of day in any sample")
+	ErrNoFormatFound    = errors.New("Could not build a custom format that extracts the samples")
+
+	// a numeric date where the day and month could be swapped, year first dates are never swapped
+	numericDateRegex = regexp.MustCompile(`(?:^|[^\d])(\d{1,2})[/.\-](\d{1,2})[/.\-](\d{2,4})(?:[^\d]|$)`)
+	timeOfDayRegex   = regexp.MustCompile(`\d{1,2}:\d{2}(?::\d{2})?`)
+	fracRegex        = regexp.MustCompile(`^[.,]\d+`)
+	meridiemRegex    = regexp.MustCompile(`^\s?[AaPp][Mm]\b`)
+	zoneRegex        = regexp.MustCompile(`^(Z\b|\s?[+\-]\d{2}:\d{2}|\s?[+\-]\d{4}|\s(?:UTC|GMT|[ECMP][SD]T|AK[SD]T|HST|BST|IST|W?[CE]S?T|[CEW]EST|MSK|JST|KST|A[CEW][SD]T|NZ[SD]T|SGT|HKT)\b)`)
+	trailYearRegex   = regexp.MustCompile(`^\s\d{4}\b`)
+)
+
+// Candidate is a processor that matched a timestamp during discovery
+type Candidate struct {
+	Name  string    // processor name
+	Start int       // offset of the timestamp in the data
+	End   int       // end of the timestamp in the data
+	TS    time.Time // the extracted timestamp
+
+	// Alternate is set when the timestamp is a numeric date that reads just as well with the
+	// day and month swapped, e.g. 02/03/2024 is February 3rd in the US and March 2nd in the UK.
+	Alternate time.Time
+}
+
+// Ambiguous reports whether the day and month of the candidate could be swapped
+func (c Candidate) Ambiguous() bool {
+	return !c.Alternate.IsZero()
+}
+
+// Candidates runs every processor against the data and returns each one that extracts a timestamp,
+// ordered by offset.  Unlike Extract this does not stop at the first hit, so it shows every
+// format that could claim the data.
+func (tg *TimeGrinder) Candidates(data []byte) (cs []Candidate) {
+	for _, p := range tg.procs {
+		start, end, ok := p.Match(data)
+		if !ok || start < 0 || end > len(data) || start >= end {
+			continue
+		}
+		ts, ok, _ := tg.extract(p, data[start:end])
+		if !ok {
+			continue
+		}
+		c := Candidate{
+			Name:  p.Name(),
+			Start: start,
+			End:   end,
+			TS:    ts,
+		}
+		c.Alternate = swappedDate(data[start:end], ts)
+		cs = append(cs, c)
+	}
+	sort.SliceStable(cs, func(i, j int) bool {
+		return cs[i].Start < cs[j].Start
+	})
+	return
+}
+
+// swappedDate returns the timestamp with the day and month swapped if the text contains a numeric
+// date that would also be valid read the other way around
+func swappedDate(v []byte, ts time.Time) (alt time.Time) {
+	m := numericDateRegex.FindSubmatch(v)
+	if len(m) != 4 {
+		return
+	}
+	a, _ := strconv.Atoi(string(m[1]))
+	b, _ := strconv.Atoi(string(m[2]))
+	if a == b || a < 1 || b < 1 || a > 12 || b > 12 {
+		return
+	}
+	// make sure the numbers are actually the date we extracted
+	if (int(ts.Month()) == a && ts.Day() == b) || (int(ts.Month()) == b && ts.Day() == a) {
+		alt = time.Date(ts.Year(), time.Month(ts.Day()), int(ts.Month()), ts.Hour(), ts.Minute(), ts.Second(), ts.Nanosecond(), ts.Location())
+	}
+	return
+}
+
+// SynthesizeFormat builds a CustomFormat that extracts timestamps from the samples.
+// Candidate formats are built around the first time of day in each sample and the one that
+// extracts the most samples is returned, the returned format always passes Validate.
+// Numeric dates that do not say which number is the day are read month first unless a sample
+// shows otherwise.
+func SynthesizeFormat(name string, samples []string) (cf CustomFormat, err error) {
+	var cands []CustomFormat
+	var found bool
+	seen := map[string]bool{}
+	for _, s := range samples {
+		layouts, ok := synthesizeLayouts(s)
+		found = found || ok
+		for _, l := range layouts {
+			if key := l[0] + "\x00" + l[1]; !seen[key] {
+				seen[key] = true
+				cands = append(cands, CustomFormat{Name: name, Format: l[0], Regex: l[1]})
+			}
+		}
+	}
+	if !found {
+		err = ErrNoTimestampFound
+		return
+	}
+	best := -1
+	for _, c := range cands {
+		if c.Validate() != nil {
+			continue
+		}
+		if score := synthesizedScore(c, samples); score > best {
+			cf, best = c, score
+		}
+	}
+	if best <= 0 {
+		err = ErrNoFormatFound
+	}
+	return
+}
+
+// synthesizedScore counts how many samples the format extracts a timestamp from
+func synthesizedScore(cf CustomFormat, samples []string) (score int) {
+	p, err := NewCustomProcessor(cf)
+	if err != nil {
+		return
+	}
+	for _, s := range samples {
+		if _, ok, _ := p.Extract([]byte(s), time.UTC); ok {
+			score++
+		}
+	}
+	return
+}
+
+type synthToken struct {
+	v    string
+	kind int
+}
+
+const (
+	tokDigits = iota
+	tokAlpha
+	tokSpace
+	tokOther
+)
+
+func tokenize(s string) (toks []synthToken) {
+	kindOf := func(r rune) int {
+		switch {
+		case r >= '0' && r <= '9':
+			return tokDigits
+		case r < unicode.MaxASCII && unicode.IsLetter(r):
+			return tokAlpha
+		case unicode.IsSpace(r):
+			return tokSpace
+		}
+		return tokOther
+	}
+	for _, r := range s {
+		k := kindOf(r)
+		if l := len(toks) - 1; l >= 0 && k != tokOther && toks[l].kind == k {
+			toks[l].v += string(r)
+		} else {
+			toks = append(toks, synthToken{v: string(r), kind: k})
+		}
+	}
+	return
+}
+
+var (
+	monthNames   = map[string]bool{`jan`: true, `feb`: true, `mar`: true, `apr`: true, `may`: true, `jun`: true, `jul`: true, `aug`: true, `sep`: true, `oct`: true, `nov`: true, `dec`: true}
+	weekdayNames = map[string]bool{`mon`: true, `tue`: true, `wed`: true, `thu`: true, `fri`: true, `sat`: true, `sun`: true}
+)
+
+func isMonthName(s string) (layout string, ok bool) {
+	ls := strings.ToLower(s)
+	if len(ls) == 3 {
+		ok, layout = monthNames[ls], `Jan`
+	} else if len(ls) > 3 {
+		for _, m := range []time.Month{time.January, time.February, time.March, time.April, time.May, time.June,
+			time.July, time.August, time.September, time.October, time.November, time.December} {
+			if ls == strings.ToLower(m.String()) {
+				ok, layout = true, `January`
+			}
+		}
+	}
+	return
+}
+
+func isWeekdayName(s string) (layout string, ok bool) {
+	ls := strings.ToLower(s)
+	if len(ls) == 3 {
+		ok, layout = weekdayNames[ls], `Mon`
+	} else if len(ls) > 3 && weekdayNames[ls[:3]] {
+		for d := time.Sunday; d <= time.Saturday; d++ {
+			if ls == strings.ToLower(d.String()) {
+				ok, layout = true, `Monday`
+			}
+		}
+	}
+	return
+}
+
+// synthesizeLayouts returns layout and regex pairs for the timestamp around the first time of day in s.
+// Ambiguous numeric dates produce a pair for each reading, month first.
+func synthesizeLayouts(s string) (r [][2]string, ok bool) {
+	idx := timeOfDayRegex.FindStringIndex(s)
+	if idx == nil {
+		return
+	}
+	ok = true
+	dates := synthesizeDate(s[:idx[0]])
+	if len(dates) == 0 {
+		dates = [][2]string{{``, ``}}
+	}
+	// the year can also trail the time, as in the Unix format
+	timeLayout, timeRx := synthesizeTime(s, idx, !strings.Contains(dates[0][0], `06`))
+	for _, d := range dates {
+		r = append(r, [2]string{d[0] + timeLayout, d[1] + timeRx})
+	}
+	return
+}
+
+// synthesizeTime builds the time of day along with anything that trails it such as
+// fractional seconds, AM/PM, a timezone, and a year
+func synthesizeTime(s string, idx []int, wantYear bool) (layout, rx string) {
+	parts := strings.Split(s[idx[0]:idx[1]], `:`)
+	rest := s[idx[1]:]
+	var meridiem string
+	var mlen int
+	frac := fracRegex.FindString(rest)
+	if m := meridiemRegex.FindString(rest[len(frac):]); m != `` {
+		meridiem, mlen = m, len(m)
+	}
+	hourLayout := `15`
+	if meridiem != `` {
+		if hourLayout = `3`; len(parts[0]) == 2 {
+			hourLayout = `03`
+		}
+	}
+	layout = hourLayout + `:04`
+	rx = `\d{1,2}:\d{2}`
+	if len(parts) == 3 {
+		layout += `:05`
+		rx += `:\d{2}`
+		// Go accepts fractional seconds after the seconds without them being in the layout
+		rx += `(?:[.,]\d+)?`
+	}
+	rest = rest[len(frac):]
+	if meridiem != `` {
+		sp := strings.TrimRight(meridiem, "AaPpMm")
+		if strings.ToUpper(meridiem[len(sp):]) == meridiem[len(sp):] {
+			layout += sp + `PM`
+		} else {
+			layout += sp + `pm`
+		}
+		rx += regexp.QuoteMeta(sp) + `[AaPp][Mm]`
+		rest = rest[mlen:]
+	}
+	if z := zoneRegex.FindString(rest); z != `` {
+		switch {
+		case z == `Z`:
+			layout += `Z07:00`
+			rx += `(?:Z|[+\-]\d{2}:\d{2})`
+		case unicode.IsLetter(rune(z[len(z)-1])):
+			layout += ` MST`
+			rx += `\s[A-Z]{2,5}`
+		case strings.Contains(z, `:`):
+			sp := strings.TrimRight(z, "+-0123456789:")
+			layout += sp + `-07:00`
+			rx += regexp.QuoteMeta(sp) + `[+\-]\d{2}:\d{2}`
+		default:
+			sp := strings.TrimRight(z, "+-0123456789")
+			layout += sp + `-0700`
+			rx += regexp.QuoteMeta(sp) + `[+\-]\d{4}`
+		}
+		rest = rest[len(z):]
+	}
+	if wantYear && trailYearRegex.MatchString(rest) {
+		layout += ` 2006`
+		rx += `\s\d{4}`
+	}
+	return
+}
+
+// synthesizeDate walks backwards from the time of day collecting the date, the returned
+// layouts and regular expressions include the separator between the date and the time
+func synthesizeDate(s string) (r [][2]string) {
+	toks := tokenize(s)
+	// walk back over tokens that can be part of a date
+	start := len(toks)
+	var nums, names int
+	var year bool
+walk:
+	for i := len(toks) - 1; i >= 0; i-- {
+		t := toks[i]
+		switch t.kind {
+		case tokDigits:
+			if len(t.v) > 4 || nums == 3 {
+				break walk
+			} else if len(t.v) == 4 {
+				// only plausible years, and only one of them
+				if y, _ := strconv.Atoi(t.v); year || y < 1900 || y > 2100 {
+					break walk
+				}
+				year = true
+			} else if len(t.v) == 3 {
+				break walk
+			}
+			nums++
+			start = i
+			continue
+		case tokAlpha:
+			if _, ok := isMonthName(t.v); ok && names < 2 {
+				names++
+				start = i
+				continue
+			} else if _, ok := isWeekdayName(t.v); ok && names < 2 {
+				names++
+				start = i
+				continue
+			} else if t.v == `T` && i == len(toks)-1 {
+				start = i
+				continue
+			}
+		case tokSpace:
+			if len(t.v) <= 2 {
+				continue
+			}
+		case tokOther:
+			if strings.Contains(`/-.,`, t.v) {
+				continue
+			}
+		}
+		break walk
+	}
+	// the date has to start with a name or a number
+	for start < len(toks) && (toks[start].kind == tokSpace || toks[start].kind == tokOther) {
+		start++
+	}
+	toks = toks[start:]
+	if len(toks) == 0 || (nums == 0 && names == 0) {
+		return
+	}
+
+	var numIdx []int
+	var hasMonthName bool
+	for i, t := range toks {
+		if t.kind == tokDigits {
+			numIdx = append(numIdx, i)
+		} else if _, ok := isMonthName(t.v); ok && t.kind == tokAlpha {
+			hasMonthName = true
+		}
+	}
+	orders := dateOrders(toks, numIdx, hasMonthName)
+	for _, roles := range orders {
+		var layout, rx strings.Builder
+		for i, t := range toks {
+			switch t.kind {
+			case tokDigits:
+				l, x := numericLayout(roles[i], t.v, i > 0 && toks[i-1].kind == tokSpace && hasMonthName)
+				if l == `_2` {
+					// the underscore day eats the padding space, so normalize the separator
+					trimTrailingSpace(&layout, &rx)
+					layout.WriteString(` `)
+					rx.WriteString(`\s+`)
+				}
+				layout.WriteString(l)
+				rx.WriteString(x)
+			case tokAlpha:
+				if l, ok := isMonthName(t.v); ok {
+					layout.WriteString(l)
+					rx.WriteString(`[A-Za-z]{3,9}`)
+				} else if l, ok := isWeekdayName(t.v); ok {
+					layout.WriteString(l)
+					rx.WriteString(`[A-Za-z]{3,9}`)
+				} else {
+					layout.WriteString(t.v)
+					rx.WriteString(regexp.QuoteMeta(t.v))
+				}
+			default:
+				layout.WriteString(t.v)
+				rx.WriteString(regexp.QuoteMeta(t.v))
+			}
+		}
+		r = append(r, [2]string{layout.String(), rx.String()})
+	}
+	return
+}
+
+func trimTrailingSpace(layout, rx *strings.Builder) {
+	l := strings.TrimRight(layout.String(), " \t")
+	x := rx.String()
+	for strings.HasSuffix(x, ` `) || strings.HasSuffix(x, "\t") {
+		x = x[:len(x)-1]
+	}
+	layout.Reset()
+	layout.WriteString(l)
+	rx.Reset()
+	rx.WriteString(x)
+}
+
+const (
+	roleNone = iota
+	roleDay
+	roleMonth
+	roleYear
+	roleShortYear
+)
+
+// dateOrders assigns day, month, and year roles to the numbers in a date.
+// Ambiguous numeric dates return both readings, month first.
+func dateOrders(toks []synthToken, numIdx []int, hasMonthName bool) (orders []map[int]int) {
+	roles := map[int]int{}
+	var small []int
+	for _, i := range numIdx {
+		if len(toks[i].v) == 4 {
+			roles[i] = roleYear
+		} else {
+			small = append(small, i)
+		}
+	}
+	hasYear := len(small) < len(numIdx)
+	if hasMonthName {
+		for n, i := range small {
+			if n == 0 {
+				roles[i] = roleDay
+			} else if n == 1 && !hasYear {
+				roles[i] = roleShortYear
+			}
+		}
+		return []map[int]int{roles}
+	}
+	if len(small) == 3 && !hasYear {
+		roles[small[2]] = roleShortYear
+		small = small[:2]
+	}
+	if len(small) != 2 {
+		return []map[int]int{roles}
+	}
+	a, _ := strconv.Atoi(toks[small[0]].v)
+	b, _ := strconv.Atoi(toks[small[1]].v)
+	yearFirst := len(numIdx) > 0 && roles[numIdx[0]] == roleYear
+	monthFirst := copyRoles(roles)
+	monthFirst[small[0]], monthFirst[small[1]] = roleMonth, roleDay
+	dayFirst := copyRoles(roles)
+	dayFirst[small[0]], dayFirst[small[1]] = roleDay, roleMonth
+	switch {
+	case yearFirst || b > 12:
+		return []map[int]int{monthFirst}
+	case a > 12:
+		return []map[int]int{dayFirst}
+	case small[1] > 0 && toks[small[1]-1].v == `.`:
+		// dotted dates are almost always day first
+		return []map[int]int{dayFirst, monthFirst}
+	}
+	return []map[int]int{monthFirst, dayFirst}
+}
+
+func copyRoles(m map[int]int) map[int]int {
+	r := make(map[int]int, len(m))
+	for k, v := range m {
+		r[k] = v
+	}
+	return r
+}
+
+// numericLayout returns the layout element and regular expression for a number in a date
+func numericLayout(role int, v string, afterSpace bool) (layout, rx string) {
+	padded := len(v) == 2
+	switch role {
+	case roleYear:
+		return `2006`, `\d{4}`
+	case roleShortYear:
+		return `06`, `\d{2}`
+	case roleMonth:
+		if padded {
+			return `01`, `\d{2}`
+		}
+		return `1`, `\d{1,2}`
+	case roleDay:
+		if afterSpace {
+			return `_2`, `\d{1,2}`
+		} else if padded {
+			return `02`, `\d{2}`
+		}
+		return `2`, `\d{1,2}`
+	}
+	return v, regexp.QuoteMeta(v)
+}
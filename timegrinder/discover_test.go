@@ -0,0 +1,128 @@
+/*************************************************************************
+ * Copyright 2026 Gravwell, Inc. All rights reserved.
+ * Contact: <legal@gravwell.io>
+ *
+ * This software may be modified and distributed under the terms of the
+ * BSD 2-clause license. See the LICENSE file for details.
+ **************************************************************************/
+
+package timegrinder
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCandidates(t *testing.T) {
+	tg, err := New(Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cs := tg.Candidates([]byte(`id 7 at 2024-03-05T10:11:12Z`))
+	if len(cs) == 0 {
+		t.Fatal("no candidates")
+	}
+	exp := time.Date(2024, 3, 5, 10, 11, 12, 0, time.UTC)
+	for i, c := range cs {
+		if c.Start != 8 {
+			t.Fatalf("candidate %d %s bad start %d", i, c.Name, c.Start)
+		} else if !c.TS.Equal(exp) {
+			t.Fatalf("candidate %d %s bad timestamp %v", i, c.Name, c.TS)
+		} else if c.Ambiguous() {
+			t.Fatalf("candidate %d %s is ambiguous", i, c.Name)
+		}
+	}
+
+	// 02/03 could be February 3rd or March 2nd
+	cs = tg.Candidates([]byte(`02/03/2024 10:11:12,123 started`))
+	var ambiguous bool
+	for _, c := range cs {
+		if c.Ambiguous() {
+			ambiguous = true
+			if c.Alternate.Month() != time.Month(c.TS.Day()) || c.Alternate.Day() != int(c.TS.Month()) {
+				t.Fatalf("bad alternate for %s: %v %v", c.Name, c.TS, c.Alternate)
+			}
+		}
+	}
+	if !ambiguous {
+		t.Fatalf("no ambiguous candidates in %+v", cs)
+	}
+
+	// 25/12 can only be read one way
+	if cs = tg.Candidates([]byte(`25/12/2024 10:11:12,123 started`)); len(cs) == 0 {
+		t.Fatal("no candidates")
+	}
+	for _, c := range cs {
+		if c.Ambiguous() {
+			t.Fatalf("%s should not be ambiguous", c.Name)
+		}
+	}
+
+	if cs = tg.Candidates([]byte(`nothing to see here`)); len(cs) != 0 {
+		t.Fatalf("unexpected candidates %+v", cs)
+	}
+}
+
+func TestSynthesizeFormat(t *testing.T) {
+	tests := []struct {
+		samples []string
+		format  string
+		ts      time.Time
+	}{
+		{
+			samples: []string{`host=a when=2024/03/15 10:11:12.123 msg=x`},
+			format:  `2006/01/02 15:04:05`,
+			ts:      time.Date(2024, 3, 15, 10, 11, 12, 123000000, time.UTC),
+		},
+		{
+			samples: []string{`[25.12.2024 08:00:01] started`},
+			format:  `02.01.2006 15:04:05`,
+			ts:      time.Date(2024, 12, 25, 8, 0, 1, 0, time.UTC),
+		},
+		{
+			samples: []string{`02/03/2024 4:05:06 PM done`},
+			format:  `01/02/2006 3:04:05 PM`,
+			ts:      time.Date(2024, 2, 3, 16, 5, 6, 0, time.UTC),
+		},
+		{
+			// the second sample shows the day comes first
+			samples: []string{`02/03/2024 4:05:06 PM done`, `25/03/2024 4:05:06 PM done`},
+			format:  `02/01/2006 3:04:05 PM`,
+			ts:      time.Date(2024, 3, 2, 16, 5, 6, 0, time.UTC),
+		},
+		{
+			samples: []string{`Sun, 12 Mar 2023 10:11:12 +0000 GET`},
+			format:  `Mon, _2 Jan 2006 15:04:05 -0700`,
+			ts:      time.Date(2023, 3, 12, 10, 11, 12, 0, time.UTC),
+		},
+		{
+			samples: []string{`ts:2024-03-05T10:11:12Z`},
+			format:  `2006-01-02T15:04:05Z07:00`,
+			ts:      time.Date(2024, 3, 5, 10, 11, 12, 0, time.UTC),
+		},
+	}
+	for i, tt := range tests {
+		cf, err := SynthesizeFormat(`test`, tt.samples)
+		if err != nil {
+			t.Fatalf("test %d: %v", i, err)
+		} else if cf.Format != tt.format {
+			t.Fatalf("test %d: format %q != %q", i, cf.Format, tt.format)
+		} else if err = cf.Validate(); err != nil {
+			t.Fatalf("test %d: %v", i, err)
+		}
+		p, err := NewCustomProcessor(cf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ts, ok, _ := p.Extract([]byte(tt.samples[0]), time.UTC)
+		if !ok {
+			t.Fatalf("test %d: failed to extract from %q", i, tt.samples[0])
+		} else if !ts.Equal(tt.ts) {
+			t.Fatalf("test %d: %v != %v", i, ts, tt.ts)
+		}
+	}
+
+	if _, err := SynthesizeFormat(`test`, []string{`no time here`}); err != ErrNoTimestampFound {
+		t.Fatalf("expected %v, got %v", ErrNoTimestampFound, err)
+	}
+}
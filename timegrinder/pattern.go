@@ -0,0 +1,938 @@
+/*************************************************************************
+ * Copyright 2026 Gravwell, Inc. All rights reserved.
+ * Contact: <legal@gravwell.io>
+ *
+ * This software may be modified and distributed under the terms of the
+ * BSD 2-clause license. See the LICENSE file for details.
+ **************************************************************************/
+
+package timegrinder
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	FormatStyleGo       = `go`       // Go reference time layouts, the default
+	FormatStyleStrptime = `strptime` // C/Python strptime directives, e.g. %Y-%m-%d %H:%M:%S.%f
+	FormatStyleJava     = `java`     // java.time DateTimeFormatter patterns, e.g. yyyy-MM-dd'T'HH:mm:ss.SSSZ
+	FormatStyleJoda     = `joda`     // Joda-Time patterns, mostly the same as java
+)
+
+var (
+	ErrUnknownFormatStyle   = errors.New("Unknown time format style")
+	ErrUnsupportedDirective = errors.New("Unsupported time format directive")
+)
+
+type fieldKind int
+
+const (
+	fieldLiteral fieldKind = iota
+	fieldYear
+	fieldYear2
+	fieldISOYear
+	fieldMonth
+	fieldMonthAbbr
+	fieldMonthName
+	fieldDay
+	fieldYearDay
+	fieldHour
+	fieldHour12
+	fieldMinute
+	fieldSecond
+	fieldFrac
+	fieldAMPM
+	fieldWeekdayAbbr
+	fieldWeekdayName
+	fieldWeekdaySun // numeric day of the week, Sunday is 0
+	fieldWeekdayMon // numeric day of the week, Monday is 1 and Sunday is 7
+	fieldWeekSun    // week of the year, weeks start on Sunday and days before the first Sunday are in week 0
+	fieldWeekMon    // week of the year, weeks start on Monday and days before the first Monday are in week 0
+	fieldWeekISO    // ISO 8601 week of the week based year
+	fieldEpoch
+	fieldZoneOffset
+	fieldZoneName
+)
+
+// patternField is a single directive or run of literal text in a translated pattern
+type patternField struct {
+	kind  fieldKind
+	pad   byte   // '0' or ' ' for padded numeric fields, 0 if unpadded; fractions with no pad are variable width
+	width int    // digits in a padded numeric field or fraction
+	lit   string // literal text, or the Go layout of a zone offset
+}
+
+func num(kind fieldKind, pad byte, width int) patternField {
+	return patternField{kind: kind, pad: pad, width: width}
+}
+
+// timePattern is a strptime or Java style pattern translated into something timegrinder can extract
+type timePattern struct {
+	style  string
+	fields []patternField
+	layout string         // equivalent Go layout, empty if the pattern has no Go equivalent
+	rx     string         // regular expression that matches a formatted timestamp
+	crx    *regexp.Regexp // anchored regex with a capture group per directive
+}
+
+// newTimePattern translates a format in the given style, a nil pattern means the format is already a Go layout
+func newTimePattern(style, format string) (tp *timePattern, err error) {
+	var fields []patternField
+	switch style = strings.ToLower(strings.TrimSpace(style)); style {
+	case ``, FormatStyleGo:
+		return
+	case FormatStyleStrptime:
+		fields, err = parseStrptime(format)
+	case FormatStyleJava, FormatStyleJoda:
+		fields, err = parseJava(format, style == FormatStyleJoda)
+	default:
+		err = fmt.Errorf("%w %q", ErrUnknownFormatStyle, style)
+	}
+	if err != nil {
+		return
+	}
+	if err = checkYearDay(fields); err != nil {
+		err = fmt.Errorf("%w in %s format", err, style)
+		return
+	}
+	tp = &timePattern{
+		style:  style,
+		fields: fields,
+	}
+	rx := new(strings.Builder)
+	crx := new(strings.Builder)
+	crx.WriteString(`^`)
+	for _, f := range fields {
+		r := f.regex()
+		rx.WriteString(r)
+		if f.kind == fieldLiteral {
+			crx.WriteString(r)
+		} else {
+			crx.WriteString(`(` + r + `)`)
+		}
+	}
+	crx.WriteString(`$`)
+	tp.rx = rx.String()
+	if tp.crx, err = regexp.Compile(crx.String()); err != nil {
+		return
+	}
+	if l, ok := tp.goLayout(); ok && tp.equivalent(l) {
+		tp.layout = l
+	}
+	return
+}
+
+// checkYearDay rejects a day of the year with no year, the date depends on whether the year is a leap year
+// and the year is not known until after the timestamp is parsed
+func checkYearDay(fields []patternField) error {
+	var yday, year bool
+	for _, f := range fields {
+		switch f.kind {
+		case fieldYearDay:
+			yday = true
+		case fieldYear, fieldYear2:
+			year = true
+		}
+	}
+	if yday && !year {
+		return fmt.Errorf("%w: day of the year without a year", ErrUnsupportedDirective)
+	}
+	return nil
+}
+
+// goLayout builds a Go layout from the fields, ok is false if a field has no Go equivalent
+func (tp *timePattern) goLayout() (layout string, ok bool) {
+	var sb strings.Builder
+	for _, f := range tp.fields {
+		var l string
+		switch f.kind {
+		case fieldLiteral, fieldZoneOffset:
+			l = f.lit
+		case fieldFrac:
+			// Go fractions must directly follow a period or comma
+			if s := sb.String(); len(s) == 0 || (s[len(s)-1] != '.' && s[len(s)-1] != ',') {
+				return
+			} else if f.pad == 0 {
+				l = `999999999`
+			} else {
+				l = strings.Repeat(`0`, f.width)
+			}
+		default:
+			if l = f.layout(); l == `` {
+				return
+			}
+		}
+		sb.WriteString(l)
+	}
+	layout, ok = sb.String(), true
+	return
+}
+
+// equivalent checks that the Go layout reads formatted timestamps the same way the pattern does.
+// Literal text can look like a Go layout element, so we don't trust a layout until it is checked.
+func (tp *timePattern) equivalent(layout string) bool {
+	for _, ref := range []time.Time{
+		time.Date(2009, time.November, 10, 23, 14, 15, 123456789, time.UTC),
+		time.Date(1999, time.January, 5, 5, 6, 7, 987654321, time.UTC),
+	} {
+		v := tp.format(ref)
+		a, err := time.ParseInLocation(layout, v, time.UTC)
+		if err != nil {
+			return false
+		}
+		b, err := tp.parseFields(v, time.UTC)
+		if err != nil || !a.Equal(b) {
+			return false
+		}
+	}
+	return true
+}
+
+func (tp *timePattern) format(t time.Time) string {
+	var sb strings.Builder
+	for _, f := range tp.fields {
+		sb.WriteString(f.format(t))
+	}
+	return sb.String()
+}
+
+func (tp *timePattern) parse(v string, loc *time.Location) (time.Time, error) {
+	if tp.layout != `` {
+		return time.ParseInLocation(tp.layout, v, loc)
+	}
+	return tp.parseFields(v, loc)
+}
+
+// extract is the pattern equivalent of extract for patterns with no Go layout
+func (tp *timePattern) extract(rx *regexp.Regexp, d []byte, loc *time.Location, window TimestampWindow) (t time.Time, ok bool, off int) {
+	var err error
+	off = -1
+	for len(d) > 0 {
+		idxs := rx.FindIndex(d)
+		if len(idxs) != 2 {
+			return
+		}
+		if t, err = tp.parseFields(string(d[idxs[0]:idxs[1]]), loc); err != nil {
+			return
+		}
+		if !t.IsZero() && (window.Valid(t) || (t.Year() == 0 && window.Valid(tweakYear(t)))) {
+			ok = true
+			off = idxs[0]
+			return
+		}
+		d = d[idxs[1]:]
+	}
+	return
+}
+
+func (tp *timePattern) parseFields(v string, loc *time.Location) (t time.Time, err error) {
+	m := tp.crx.FindStringSubmatch(v)
+	if m == nil {
+		err = fmt.Errorf("%q does not match the %s format", v, tp.style)
+		return
+	}
+	var pv patternValues
+	idx := 1
+	for _, f := range tp.fields {
+		if f.kind == fieldLiteral {
+			continue
+		}
+		if err = f.parse(m[idx], &pv); err != nil {
+			return
+		}
+		idx++
+	}
+	return pv.time(loc)
+}
+
+// layout returns the Go layout element for a field, empty if there is none.
+// AM/PM has none because Go layouts only match one case of it.
+func (f patternField) layout() string {
+	switch f.kind {
+	case fieldYear:
+		return `2006`
+	case fieldYear2:
+		return `06`
+	case fieldMonth:
+		return pick(f.pad, `01`, ``, `1`)
+	case fieldMonthAbbr:
+		return `Jan`
+	case fieldMonthName:
+		return `January`
+	case fieldDay:
+		return pick(f.pad, `02`, `_2`, `2`)
+	case fieldYearDay:
+		return pick(f.pad, `002`, `__2`, ``)
+	case fieldHour:
+		return pick(f.pad, `15`, ``, `15`)
+	case fieldHour12:
+		return pick(f.pad, `03`, ``, `3`)
+	case fieldMinute:
+		return pick(f.pad, `04`, ``, `4`)
+	case fieldSecond:
+		return pick(f.pad, `05`, ``, `5`)
+	case fieldWeekdayAbbr:
+		return `Mon`
+	case fieldWeekdayName:
+		return `Monday`
+	case fieldZoneName:
+		return `MST`
+	}
+	return ``
+}
+
+func pick(pad byte, zero, space, none string) string {
+	switch pad {
+	case '0':
+		return zero
+	case ' ':
+		return space
+	}
+	return none
+}
+
+func (f patternField) regex() string {
+	switch f.kind {
+	case fieldLiteral:
+		return regexp.QuoteMeta(f.lit)
+	case fieldYear, fieldISOYear:
+		return `\d{4}`
+	case fieldYear2:
+		return `\d{2}`
+	case fieldMonthAbbr, fieldWeekdayAbbr:
+		return `[A-Za-z]{3}`
+	case fieldMonthName:
+		return `[A-Za-z]{3,9}`
+	case fieldWeekdayName:
+		return `[A-Za-z]{6,9}`
+	case fieldFrac:
+		if f.pad == 0 {
+			return `\d{1,9}`
+		}
+		return `\d{` + strconv.Itoa(f.width) + `}`
+	case fieldAMPM:
+		return `[AaPp][Mm]`
+	case fieldEpoch:
+		return `\d+`
+	case fieldZoneName:
+		return `[A-Z]{3,5}`
+	case fieldZoneOffset:
+		r := `[+\-]\d{2}`
+		if strings.Contains(f.lit, `:`) {
+			r += `:\d{2}`
+		} else if strings.HasSuffix(f.lit, `0700`) {
+			r += `\d{2}`
+		}
+		if f.lit[0] == 'Z' {
+			r = `(?:Z|` + r + `)`
+		}
+		return r
+	}
+	// everything else is numeric
+	switch f.pad {
+	case '0':
+		return `\d{` + strconv.Itoa(f.width) + `}`
+	case ' ':
+		return `[ \d]{` + strconv.Itoa(f.width-1) + `}\d`
+	}
+	return `\d{1,` + strconv.Itoa(f.width) + `}`
+}
+
+func (f patternField) format(t time.Time) string {
+	switch f.kind {
+	case fieldLiteral:
+		return f.lit
+	case fieldYear:
+		return fmt.Sprintf("%04d", t.Year())
+	case fieldYear2:
+		return fmt.Sprintf("%02d", t.Year()%100)
+	case fieldISOYear:
+		y, _ := t.ISOWeek()
+		return fmt.Sprintf("%04d", y)
+	case fieldMonth:
+		return f.formatNum(int(t.Month()))
+	case fieldMonthAbbr:
+		return t.Month().String()[:3]
+	case fieldMonthName:
+		return t.Month().String()
+	case fieldDay:
+		return f.formatNum(t.Day())
+	case fieldYearDay:
+		return f.formatNum(t.YearDay())
+	case fieldHour:
+		return f.formatNum(t.Hour())
+	case fieldHour12:
+		h := t.Hour() % 12
+		if h == 0 {
+			h = 12
+		}
+		return f.formatNum(h)
+	case fieldMinute:
+		return f.formatNum(t.Minute())
+	case fieldSecond:
+		return f.formatNum(t.Second())
+	case fieldFrac:
+		w := f.width
+		if w == 0 {
+			w = 9
+		}
+		return fmt.Sprintf("%0*d", w, t.Nanosecond()/pow10(9-w))
+	case fieldAMPM:
+		if t.Hour() < 12 {
+			return `AM`
+		}
+		return `PM`
+	case fieldWeekdayAbbr:
+		return t.Weekday().String()[:3]
+	case fieldWeekdayName:
+		return t.Weekday().String()
+	case fieldWeekdaySun:
+		return strconv.Itoa(int(t.Weekday()))
+	case fieldWeekdayMon:
+		if t.Weekday() == time.Sunday {
+			return `7`
+		}
+		return strconv.Itoa(int(t.Weekday()))
+	case fieldWeekSun:
+		return f.formatNum((t.YearDay() + 6 - int(t.Weekday())) / 7)
+	case fieldWeekMon:
+		return f.formatNum((t.YearDay() + 6 - (int(t.Weekday())+6)%7) / 7)
+	case fieldWeekISO:
+		_, w := t.ISOWeek()
+		return f.formatNum(w)
+	case fieldEpoch:
+		return strconv.FormatInt(t.Unix(), 10)
+	case fieldZoneOffset:
+		return t.Format(f.lit)
+	case fieldZoneName:
+		return t.Format(`MST`)
+	}
+	return ``
+}
+
+func (f patternField) formatNum(v int) string {
+	switch f.pad {
+	case '0':
+		return fmt.Sprintf("%0*d", f.width, v)
+	case ' ':
+		return fmt.Sprintf("%*d", f.width, v)
+	}
+	return strconv.Itoa(v)
+}
+
+func pow10(n int) (r int) {
+	for r = 1; n > 0; n-- {
+		r *= 10
+	}
+	return
+}
+
+// patternValues holds the values parsed out of a timestamp before they are turned into a time
+type patternValues struct {
+	set                    uint32 // bitmask of the field kinds that were parsed
+	year, month, day, yday int
+	hour, min, sec, nsec   int
+	pm                     bool
+	wday                   time.Weekday
+	week, isoYear, offset  int
+	weekKind               fieldKind
+	epoch                  int64
+	zone                   string
+}
+
+func (pv *patternValues) has(kinds ...fieldKind) bool {
+	for _, k := range kinds {
+		if pv.set&(1<<k) != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func (f patternField) parse(v string, pv *patternValues) (err error) {
+	var n int
+	switch f.kind {
+	case fieldMonthAbbr, fieldMonthName:
+		if n = lookupName(v, f.kind == fieldMonthAbbr, 12, func(i int) string { return time.Month(i + 1).String() }); n < 0 {
+			return fmt.Errorf("Invalid month %q", v)
+		}
+		pv.month = n + 1
+	case fieldWeekdayAbbr, fieldWeekdayName:
+		if n = lookupName(v, f.kind == fieldWeekdayAbbr, 7, func(i int) string { return time.Weekday(i).String() }); n < 0 {
+			return fmt.Errorf("Invalid day of the week %q", v)
+		}
+		pv.wday = time.Weekday(n)
+	case fieldAMPM:
+		pv.pm = strings.EqualFold(v, `PM`)
+	case fieldFrac:
+		if len(v) > 9 {
+			return fmt.Errorf("Invalid fractional second %q", v)
+		} else if n, err = strconv.Atoi(v); err != nil {
+			return
+		}
+		pv.nsec = n * pow10(9-len(v))
+	case fieldEpoch:
+		if pv.epoch, err = strconv.ParseInt(v, 10, 64); err != nil {
+			return
+		}
+	case fieldZoneOffset:
+		var t time.Time
+		if t, err = time.Parse(f.lit, v); err != nil {
+			return
+		}
+		_, pv.offset = t.Zone()
+	case fieldZoneName:
+		pv.zone = v
+	default:
+		if n, err = strconv.Atoi(strings.TrimLeft(v, ` `)); err != nil {
+			return
+		}
+		switch f.kind {
+		case fieldYear:
+			pv.year = n
+		case fieldYear2:
+			// same pivot as the time package
+			if pv.year = n + 2000; n >= 69 {
+				pv.year = n + 1900
+			}
+		case fieldISOYear:
+			pv.isoYear = n
+		case fieldMonth:
+			pv.month = n
+		case fieldDay:
+			pv.day = n
+		case fieldYearDay:
+			pv.yday = n
+		case fieldHour, fieldHour12:
+			pv.hour = n
+		case fieldMinute:
+			pv.min = n
+		case fieldSecond:
+			pv.sec = n
+		case fieldWeekdaySun:
+			if n > 6 {
+				return fmt.Errorf("Invalid day of the week %d", n)
+			}
+			pv.wday = time.Weekday(n)
+		case fieldWeekdayMon:
+			if n < 1 || n > 7 {
+				return fmt.Errorf("Invalid day of the week %d", n)
+			}
+			pv.wday = time.Weekday(n % 7)
+		case fieldWeekSun, fieldWeekMon, fieldWeekISO:
+			pv.week, pv.weekKind = n, f.kind
+		}
+	}
+	pv.set |= 1 << f.kind
+	return
+}
+
+// lookupName returns the index of a case insensitive month or weekday name, or -1
+func lookupName(v string, abbr bool, count int, name func(int) string) int {
+	for i := 0; i < count; i++ {
+		n := name(i)
+		if abbr {
+			n = n[:3]
+		}
+		if strings.EqualFold(v, n) {
+			return i
+		}
+	}
+	return -1
+}
+
+// time assembles the parsed values, missing fields default the same way the time package does
+func (pv *patternValues) time(loc *time.Location) (t time.Time, err error) {
+	if pv.has(fieldEpoch) {
+		t = time.Unix(pv.epoch, int64(pv.nsec)).In(loc)
+		return
+	}
+	year, month, day := pv.year, 1, 1
+	if pv.has(fieldMonth, fieldMonthAbbr, fieldMonthName) {
+		month = pv.month
+	}
+	if pv.has(fieldDay) {
+		day = pv.day
+	}
+	hour := pv.hour
+	if pv.has(fieldHour12) {
+		if hour < 1 || hour > 12 {
+			return t, fmt.Errorf("Invalid hour %d", hour)
+		} else if pv.pm && hour < 12 {
+			hour += 12
+		} else if !pv.pm && hour == 12 {
+			hour = 0
+		}
+	}
+	if month < 1 || month > 12 {
+		return t, fmt.Errorf("Invalid month %d", month)
+	} else if hour > 23 || pv.min > 59 || pv.sec > 59 {
+		return t, fmt.Errorf("Invalid time of day %02d:%02d:%02d", hour, pv.min, pv.sec)
+	} else if day < 1 || day > time.Date(year, time.Month(month)+1, 0, 0, 0, 0, 0, time.UTC).Day() {
+		return t, fmt.Errorf("Invalid day %d for month %d", day, month)
+	}
+
+	// week and day of year fields only decide the date when there is no day of the month
+	switch {
+	case pv.has(fieldDay):
+	case pv.has(fieldWeekSun, fieldWeekMon, fieldWeekISO):
+		year, month, day = pv.weekDate(year)
+	case pv.has(fieldYearDay):
+		if pv.yday < 1 || pv.yday > time.Date(year, 12, 31, 0, 0, 0, 0, time.UTC).YearDay() {
+			return t, fmt.Errorf("Invalid day of the year %d", pv.yday)
+		}
+		var m time.Month
+		year, m, day = time.Date(year, 1, pv.yday, 0, 0, 0, 0, time.UTC).Date()
+		month = int(m)
+	}
+
+	switch {
+	case pv.has(fieldZoneOffset):
+		t = time.Date(year, time.Month(month), day, hour, pv.min, pv.sec, pv.nsec, time.UTC).Add(-time.Duration(pv.offset) * time.Second)
+		// use the local zone if it has the same offset, otherwise make one up
+		if _, off := t.In(loc).Zone(); off == pv.offset {
+			t = t.In(loc)
+		} else {
+			t = t.In(time.FixedZone(``, pv.offset))
+		}
+	case pv.has(fieldZoneName):
+		// let the time package resolve the abbreviation
+		v := fmt.Sprintf("%04d-%02d-%02d %02d:%02d:%02d %s", year, month, day, hour, pv.min, pv.sec, pv.zone)
+		if t, err = time.ParseInLocation(`2006-01-02 15:04:05 MST`, v, loc); err == nil {
+			t = t.Add(time.Duration(pv.nsec))
+		}
+	default:
+		t = time.Date(year, time.Month(month), day, hour, pv.min, pv.sec, pv.nsec, loc)
+	}
+	return
+}
+
+// weekDate resolves a week of the year to a date, missing days of the week default to the first day of the week
+func (pv *patternValues) weekDate(year int) (int, int, int) {
+	wday := pv.wday
+	if !pv.has(fieldWeekdaySun, fieldWeekdayMon, fieldWeekdayAbbr, fieldWeekdayName) && pv.weekKind != fieldWeekSun {
+		wday = time.Monday
+	}
+	jan1 := time.Date(year, 1, 1, 0, 0, 0, 0, time.UTC)
+	var d int
+	switch pv.weekKind {
+	case fieldWeekSun:
+		first := (7 - int(jan1.Weekday())) % 7
+		d = first + 7*(pv.week-1) + int(wday)
+	case fieldWeekMon:
+		first := (8 - int(jan1.Weekday())) % 7
+		d = first + 7*(pv.week-1) + (int(wday)+6)%7
+	case fieldWeekISO:
+		if pv.has(fieldISOYear) {
+			jan1 = time.Date(pv.isoYear, 1, 1, 0, 0, 0, 0, time.UTC)
+		}
+		// the first ISO week is the one with January 4th in it
+		jan4 := jan1.AddDate(0, 0, 3)
+		first := 3 - (int(jan4.Weekday())+6)%7
+		d = first + 7*(pv.week-1) + (int(wday)+6)%7
+	}
+	y, m, dd := jan1.AddDate(0, 0, d).Date()
+	return y, int(m), dd
+}
+
+func appendLiteral(fields []patternField, s string) []patternField {
+	if l := len(fields); l > 0 && fields[l-1].kind == fieldLiteral {
+		fields[l-1].lit += s
+		return fields
+	}
+	return append(fields, patternField{kind: fieldLiteral, lit: s})
+}
+
+// parseStrptime translates strptime directives.  Numeric directives take the GNU - flag to drop
+// padding and _ flag to pad with spaces.
+func parseStrptime(format string) (fields []patternField, err error) {
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			fields = appendLiteral(fields, format[i:i+1])
+			continue
+		}
+		start := i
+		if i++; i >= len(format) {
+			return nil, fmt.Errorf("%w: trailing %% in strptime format", ErrUnsupportedDirective)
+		}
+		pad := byte('0')
+		switch format[i] {
+		case '-':
+			pad = 0
+			i++
+		case '_':
+			pad = ' '
+			i++
+		}
+		if i < len(format) && format[i] == ':' {
+			i++ // only valid for %:z
+		}
+		if i >= len(format) {
+			return nil, fmt.Errorf("%w: incomplete directive %q in strptime format", ErrUnsupportedDirective, format[start:])
+		}
+		var expand string
+		var f patternField
+		switch dir := format[start : i+1]; format[i] {
+		case 'Y':
+			f = num(fieldYear, '0', 4)
+		case 'y':
+			f = num(fieldYear2, '0', 2)
+		case 'G':
+			f = num(fieldISOYear, '0', 4)
+		case 'm':
+			f = num(fieldMonth, pad, 2)
+		case 'b', 'h':
+			f.kind = fieldMonthAbbr
+		case 'B':
+			f.kind = fieldMonthName
+		case 'd':
+			f = num(fieldDay, pad, 2)
+		case 'e':
+			if pad == '0' {
+				pad = ' '
+			}
+			f = num(fieldDay, pad, 2)
+		case 'j':
+			f = num(fieldYearDay, pad, 3)
+		case 'H':
+			f = num(fieldHour, pad, 2)
+		case 'k':
+			if pad == '0' {
+				pad = ' '
+			}
+			f = num(fieldHour, pad, 2)
+		case 'I':
+			f = num(fieldHour12, pad, 2)
+		case 'l':
+			if pad == '0' {
+				pad = ' '
+			}
+			f = num(fieldHour12, pad, 2)
+		case 'M':
+			f = num(fieldMinute, pad, 2)
+		case 'S':
+			f = num(fieldSecond, pad, 2)
+		case 'f':
+			f = num(fieldFrac, 0, 6)
+		case 'p':
+			f.kind = fieldAMPM
+		case 'a':
+			f.kind = fieldWeekdayAbbr
+		case 'A':
+			f.kind = fieldWeekdayName
+		case 'w':
+			f = num(fieldWeekdaySun, 0, 1)
+		case 'u':
+			f = num(fieldWeekdayMon, 0, 1)
+		case 'U':
+			f = num(fieldWeekSun, pad, 2)
+		case 'W':
+			f = num(fieldWeekMon, pad, 2)
+		case 'V':
+			f = num(fieldWeekISO, pad, 2)
+		case 's':
+			f.kind = fieldEpoch
+		case 'z':
+			f = patternField{kind: fieldZoneOffset, lit: `-0700`}
+			if strings.HasSuffix(dir, `:z`) {
+				f.lit = `-07:00`
+			}
+		case 'Z':
+			f.kind = fieldZoneName
+		case 'T':
+			expand = `%H:%M:%S`
+		case 'R':
+			expand = `%H:%M`
+		case 'r':
+			expand = `%I:%M:%S %p`
+		case 'D':
+			expand = `%m/%d/%y`
+		case 'F':
+			expand = `%Y-%m-%d`
+		case 'n':
+			f = patternField{kind: fieldLiteral, lit: "\n"}
+		case 't':
+			f = patternField{kind: fieldLiteral, lit: "\t"}
+		case '%':
+			f = patternField{kind: fieldLiteral, lit: `%`}
+		default:
+			return nil, fmt.Errorf("%w %q in strptime format", ErrUnsupportedDirective, dir)
+		}
+		if strings.Contains(format[start:i], `:`) && format[i] != 'z' {
+			return nil, fmt.Errorf("%w %q in strptime format", ErrUnsupportedDirective, format[start:i+1])
+		}
+		if expand != `` {
+			var sub []patternField
+			if sub, err = parseStrptime(expand); err != nil {
+				return
+			}
+			for _, sf := range sub {
+				if sf.kind == fieldLiteral {
+					fields = appendLiteral(fields, sf.lit)
+				} else {
+					fields = append(fields, sf)
+				}
+			}
+		} else if f.kind == fieldLiteral {
+			fields = appendLiteral(fields, f.lit)
+		} else {
+			fields = append(fields, f)
+		}
+	}
+	return
+}
+
+// parseJava translates java.time DateTimeFormatter or Joda-Time patterns.  Letters are pattern
+// letters, text in single quotes is literal and two single quotes in a row are a literal quote.
+func parseJava(format string, joda bool) (fields []patternField, err error) {
+	style := FormatStyleJava
+	if joda {
+		style = FormatStyleJoda
+	}
+	for i := 0; i < len(format); {
+		c := format[i]
+		switch {
+		case c == '\'':
+			if i+1 < len(format) && format[i+1] == '\'' {
+				fields = appendLiteral(fields, `'`)
+				i += 2
+				continue
+			}
+			var lit strings.Builder
+			j := i + 1
+			for ; ; j++ {
+				if j >= len(format) {
+					return nil, fmt.Errorf("%w: unterminated quote in %s format", ErrUnsupportedDirective, style)
+				} else if format[j] == '\'' {
+					if j+1 < len(format) && format[j+1] == '\'' {
+						lit.WriteByte('\'')
+						j++
+						continue
+					}
+					break
+				}
+				lit.WriteByte(format[j])
+			}
+			if lit.Len() > 0 {
+				fields = appendLiteral(fields, lit.String())
+			}
+			i = j + 1
+		case (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z'):
+			n := 1
+			for i+n < len(format) && format[i+n] == c {
+				n++
+			}
+			var f patternField
+			if f, err = javaField(c, n, joda); err != nil {
+				return nil, fmt.Errorf("%w %q in %s format", err, format[i:i+n], style)
+			}
+			fields = append(fields, f)
+			i += n
+		case !joda && (c == '[' || c == ']' || c == '{' || c == '}' || c == '#'):
+			return nil, fmt.Errorf("%w %q in %s format, optional sections are not supported", ErrUnsupportedDirective, string(c), style)
+		default:
+			fields = appendLiteral(fields, format[i:i+1])
+			i++
+		}
+	}
+	return
+}
+
+func javaField(c byte, n int, joda bool) (f patternField, err error) {
+	// numeric fields are unpadded with a single letter and zero padded to width otherwise
+	numeric := func(kind fieldKind, width int) (patternField, error) {
+		if n == 1 {
+			return num(kind, 0, width), nil
+		} else if n == width {
+			return num(kind, '0', width), nil
+		}
+		return f, ErrUnsupportedDirective
+	}
+	switch {
+	case c == 'y' || (c == 'u' && !joda) || (c == 'Y' && joda):
+		if n == 2 {
+			return num(fieldYear2, '0', 2), nil
+		} else if n <= 4 {
+			return num(fieldYear, '0', 4), nil
+		}
+	case (c == 'Y' && !joda) || (c == 'x' && joda):
+		if n != 2 && n <= 4 {
+			return num(fieldISOYear, '0', 4), nil
+		}
+	case c == 'M' || (c == 'L' && !joda):
+		switch n {
+		case 1, 2:
+			return numeric(fieldMonth, 2)
+		case 3:
+			return patternField{kind: fieldMonthAbbr}, nil
+		case 4:
+			return patternField{kind: fieldMonthName}, nil
+		}
+	case c == 'd':
+		return numeric(fieldDay, 2)
+	case c == 'D':
+		return numeric(fieldYearDay, 3)
+	case c == 'w':
+		return numeric(fieldWeekISO, 2)
+	case c == 'E' || ((c == 'e' || c == 'c') && !joda && n >= 3):
+		if n <= 3 {
+			return patternField{kind: fieldWeekdayAbbr}, nil
+		} else if n == 4 {
+			return patternField{kind: fieldWeekdayName}, nil
+		}
+	case c == 'e' || (c == 'c' && !joda):
+		if n == 1 {
+			return num(fieldWeekdayMon, 0, 1), nil
+		}
+	case c == 'a':
+		return patternField{kind: fieldAMPM}, nil
+	case c == 'H':
+		return numeric(fieldHour, 2)
+	case c == 'h':
+		return numeric(fieldHour12, 2)
+	case c == 'm':
+		return numeric(fieldMinute, 2)
+	case c == 's':
+		return numeric(fieldSecond, 2)
+	case c == 'S':
+		if n <= 9 {
+			return num(fieldFrac, '0', n), nil
+		}
+	case c == 'z':
+		if n <= 3 {
+			return patternField{kind: fieldZoneName}, nil
+		}
+	case c == 'Z':
+		f.kind = fieldZoneOffset
+		switch {
+		case n == 1, n == 3, n == 2 && !joda:
+			f.lit = `-0700`
+			return
+		case n == 5, n == 2 && joda:
+			f.lit = `-07:00`
+			return
+		}
+	case c == 'X' || (c == 'x' && !joda):
+		f.kind = fieldZoneOffset
+		switch n {
+		case 1:
+			f.lit = `-07`
+		case 2, 4:
+			f.lit = `-0700`
+		case 3, 5:
+			f.lit = `-07:00`
+		default:
+			return f, ErrUnsupportedDirective
+		}
+		if c == 'X' {
+			f.lit = `Z` + f.lit[1:]
+		}
+		return
+	}
+	return f, ErrUnsupportedDirective
+}
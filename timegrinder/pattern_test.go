@@ -0,0 +1,137 @@
+/*************************************************************************
+ * Copyright 2026 Gravwell, Inc. All rights reserved.
+ * Contact: <legal@gravwell.io>
+ *
+ * This software may be modified and distributed under the terms of the
+ * BSD 2-clause license. See the LICENSE file for details.
+ **************************************************************************/
+
+package timegrinder
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPatternTranslation(t *testing.T) {
+	tests := []struct {
+		style  string
+		format string
+		layout string // expected Go layout, empty if there should not be one
+		data   string
+		ts     time.Time
+	}{
+		{FormatStyleStrptime, `%Y-%m-%d %H:%M:%S.%f`, `2006-01-02 15:04:05.999999999`,
+			`x 2024-03-05 10:11:12.1234 y`, time.Date(2024, 3, 5, 10, 11, 12, 123400000, time.UTC)},
+		{FormatStyleStrptime, `%d/%b/%Y:%T %z`, `02/Jan/2006:15:04:05 -0700`,
+			`[05/Mar/2024:10:11:12 +0000]`, time.Date(2024, 3, 5, 10, 11, 12, 0, time.UTC)},
+		{FormatStyleStrptime, `%a %b %e %I:%M:%S %p %Y`, ``,
+			`Tue Mar  5 04:11:12 PM 2024`, time.Date(2024, 3, 5, 16, 11, 12, 0, time.UTC)},
+		{FormatStyleStrptime, `%Y.%j %H:%M`, `2006.002 15:04`,
+			`2024.065 10:11`, time.Date(2024, 3, 5, 10, 11, 0, 0, time.UTC)},
+		{FormatStyleStrptime, `ts=%s.%f`, ``,
+			`ts=1709633472.5 msg`, time.Unix(1709633472, 500000000).UTC()},
+		{FormatStyleStrptime, `%Y week %U %w %H:%M`, ``,
+			`2024 week 09 2 10:11`, time.Date(2024, 3, 5, 10, 11, 0, 0, time.UTC)},
+		{FormatStyleStrptime, `%Y-W%W-%u`, ``,
+			`2024-W10-2`, time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC)},
+		{FormatStyleStrptime, `%G-W%V-%u %k:%M`, ``,
+			`2020-W53-5  9:00`, time.Date(2021, 1, 1, 9, 0, 0, 0, time.UTC)},
+		{FormatStyleStrptime, `%-m/%-d/%y %H%M%S%f`, ``,
+			`3/5/24 101112123`, time.Date(2024, 3, 5, 10, 11, 12, 123000000, time.UTC)},
+		{FormatStyleJava, `yyyy-MM-dd'T'HH:mm:ss.SSSZ`, `2006-01-02T15:04:05.000-0700`,
+			`2024-03-05T10:11:12.345-0500`, time.Date(2024, 3, 5, 15, 11, 12, 345000000, time.UTC)},
+		{FormatStyleJava, `yyyy-MM-dd'T'HH:mm:ss.SSSXXX`, `2006-01-02T15:04:05.000Z07:00`,
+			`2024-03-05T10:11:12.345Z`, time.Date(2024, 3, 5, 10, 11, 12, 345000000, time.UTC)},
+		{FormatStyleJava, `EEE, d MMM yyyy h:mm a`, ``,
+			`Tue, 5 Mar 2024 4:11 PM`, time.Date(2024, 3, 5, 16, 11, 0, 0, time.UTC)},
+		{FormatStyleStrptime, `%Y-%m-%d %I:%M:%S %p`, ``,
+			`2024-03-05 10:11:12 pm`, time.Date(2024, 3, 5, 22, 11, 12, 0, time.UTC)},
+		{FormatStyleJava, `yyyy-MM-dd h:mm:ss a`, ``,
+			`2024-03-05 12:11:12 am`, time.Date(2024, 3, 5, 0, 11, 12, 0, time.UTC)},
+		{FormatStyleJava, `dd.MM.yy HH:mm:ss,SSS`, `02.01.06 15:04:05,000`,
+			`05.03.24 10:11:12,345`, time.Date(2024, 3, 5, 10, 11, 12, 345000000, time.UTC)},
+		{FormatStyleJava, `YYYY'-W'ww'-'e`, ``,
+			`2024-W10-2`, time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC)},
+		{FormatStyleJava, `''yyyyMMddHHmmssSSS''`, ``,
+			`'20240305101112345'`, time.Date(2024, 3, 5, 10, 11, 12, 345000000, time.UTC)},
+		{FormatStyleJoda, `yyyy-MM-dd HH:mm:ssZZ`, `2006-01-02 15:04:05-07:00`,
+			`2024-03-05 10:11:12+01:00`, time.Date(2024, 3, 5, 9, 11, 12, 0, time.UTC)},
+	}
+	for i, tt := range tests {
+		cf := CustomFormat{
+			Name:         `test`,
+			Format:       tt.format,
+			Format_Style: tt.style,
+		}
+		p, err := NewCustomProcessor(cf)
+		if err != nil {
+			t.Fatalf("test %d %q: %v", i, tt.format, err)
+		} else if l := p.(*customProcessor).layout(); l != tt.layout {
+			t.Fatalf("test %d %q: bad layout %q != %q", i, tt.format, l, tt.layout)
+		}
+		ts, ok, _ := p.Extract([]byte(tt.data), time.UTC)
+		if !ok {
+			t.Fatalf("test %d %q: failed to extract from %q", i, tt.format, tt.data)
+		} else if !ts.Equal(tt.ts) {
+			t.Fatalf("test %d %q: %v != %v", i, tt.format, ts, tt.ts)
+		}
+		// formatting and extracting must round trip
+		if ts2, ok, _ := p.Extract([]byte(p.ToString(tt.ts)), time.UTC); !ok || !ts2.Equal(ts) {
+			t.Fatalf("test %d %q: round trip of %q failed: %v", i, tt.format, p.ToString(tt.ts), ts2)
+		}
+	}
+}
+
+func TestPatternYearMissing(t *testing.T) {
+	cf := CustomFormat{
+		Name:         `test`,
+		Format:       `%b %d %H:%M:%S`,
+		Format_Style: FormatStyleStrptime,
+	}
+	if err := cf.Validate(); err != nil {
+		t.Fatal(err)
+	} else if !cf.yearMissing || cf.dateMissing {
+		t.Fatalf("bad missing flags %v %v", cf.yearMissing, cf.dateMissing)
+	}
+	cf.Format = `%H:%M:%S`
+	if err := cf.Validate(); err != nil {
+		t.Fatal(err)
+	} else if !cf.dateMissing {
+		t.Fatal("date should be missing")
+	}
+}
+
+func TestPatternErrors(t *testing.T) {
+	tests := []struct {
+		style  string
+		format string
+		err    error
+	}{
+		{`python`, `%Y`, ErrUnknownFormatStyle},
+		{FormatStyleStrptime, `%Y-%m-%d %c`, ErrUnsupportedDirective},
+		{FormatStyleStrptime, `%Y-%m-%d %`, ErrUnsupportedDirective},
+		{FormatStyleStrptime, `%:H`, ErrUnsupportedDirective},
+		{FormatStyleJava, `yyyy-MM-dd G`, ErrUnsupportedDirective},
+		{FormatStyleJava, `yyyy-MM-dd'T`, ErrUnsupportedDirective},
+		{FormatStyleJava, `yyyy-MM-dd[ HH:mm]`, ErrUnsupportedDirective},
+		{FormatStyleJava, `yyyyy`, ErrUnsupportedDirective},
+		{FormatStyleJava, `kk:mm`, ErrUnsupportedDirective},
+		// the day of the year can't be resolved without knowing if it is a leap year
+		{FormatStyleStrptime, `%j %H:%M:%S`, ErrUnsupportedDirective},
+		{FormatStyleJava, `DDD HH:mm:ss`, ErrUnsupportedDirective},
+	}
+	for i, tt := range tests {
+		cf := CustomFormat{Name: `test`, Format: tt.format, Format_Style: tt.style}
+		if err := cf.Validate(); !errors.Is(err, tt.err) {
+			t.Fatalf("test %d %q: expected %v, got %v", i, tt.format, tt.err, err)
+		}
+	}
+
+	// a user supplied regex must still match the format
+	cf := CustomFormat{Name: `test`, Format: `%Y-%m-%d`, Format_Style: FormatStyleStrptime, Regex: `\d{2}:\d{2}`}
+	if err := cf.Validate(); err != ErrRegexFormatMismatch {
+		t.Fatalf("expected %v, got %v", ErrRegexFormatMismatch, err)
+	}
+}
@@ -294,14 +294,14 @@ func NewAnsiCProcessor() *processor {
 	}
 }
 
-func NewUnixProcessor() *processor {
-	return &processor{
+func NewUnixProcessor() *zoneProcessor {
+	return newZoneProcessor(processor{
 		rxp:    regexp.MustCompile(UnixRegex),
 		rxstr:  UnixRegex,
 		format: UnixFormat,
 		name:   Unix.String(),
 		min:    len(UnixFormat) - 2, //for shorter timezones
-	}
+	})
 }
 
 func NewRubyProcessor() *processor {
@@ -314,14 +314,14 @@ func NewRubyProcessor() *processor {
 	}
 }
 
-func NewRFC822Processor() *processor {
-	return &processor{
+func NewRFC822Processor() *zoneProcessor {
+	return newZoneProcessor(processor{
 		rxp:    regexp.MustCompile(RFC822Regex),
 		rxstr:  RFC822Regex,
 		format: RFC822Format,
 		name:   RFC822.String(),
 		min:    len(RFC822Format) - 2, //for shorter timezones
-	}
+	})
 }
 
 func NewRFC822ZProcessor() *processor {
@@ -344,14 +344,14 @@ func NewRFC850Processor() *processor {
 	}
 }
 
-func NewRFC1123Processor() *processor {
-	return &processor{
+func NewRFC1123Processor() *zoneProcessor {
+	return newZoneProcessor(processor{
 		rxp:    regexp.MustCompile(RFC1123Regex),
 		rxstr:  RFC1123Regex,
 		format: RFC1123Format,
 		name:   RFC1123.String(),
 		min:    len(RFC1123Format) - 2, //for shorter timezones
-	}
+	})
 }
 
 func NewRFC1123ZProcessor() *processor {
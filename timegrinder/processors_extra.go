@@ -549,18 +549,92 @@ func (up unixSecondsProcessor) Match(d []byte) (start, end int, ok bool) {
 	return
 }
 
+const (
+	// maxInferredFutureDelta is how far after the reference time an inferred year may put a timestamp
+	maxInferredFutureDelta = 25 * time.Hour
+	// maxInferredYears bounds how far back we look for a year, leap days can need up to 8 years
+	maxInferredYears = 8
+)
+
 // tweakYear tries to figure out an appropriate year for the timestamp
 // if the current year is zero.
 func tweakYear(t time.Time) time.Time {
 	if t.Year() != 0 {
 		return t
 	}
-	now := time.Now()
-	year := now.Year()
-	// If setting the current year puts us too far in the future,
-	// more than 25 hours, we'll set the previous year instead.
-	if t.AddDate(year, 0, 0).Sub(now) > (25 * time.Hour) {
-		return t.AddDate(year-1, 0, 0)
+	return inferYear(t, time.Now())
+}
+
+// inferYear replaces the year of t with the year that puts it nearest to ref without
+// landing more than maxInferredFutureDelta after it.
+// This handles rollover in both directions, a December timestamp read on January 1st lands in the
+// previous year and a January 1st timestamp read late on December 31st lands in the next year.
+// February 29th lands in the nearest leap year.
+func inferYear(t, ref time.Time) time.Time {
+	for y := ref.Year() + 1; y > ref.Year()-maxInferredYears; y-- {
+		c := time.Date(y, t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+		if c.Day() != t.Day() {
+			continue // the date does not exist in this year
+		} else if c.Sub(ref) <= maxInferredFutureDelta {
+			return c
+		}
 	}
-	return t.AddDate(year, 0, 0)
+	return t
+}
+
+// yearlessProcessor is implemented by processors whose format does not contain a year
+type yearlessProcessor interface {
+	YearMissing() bool
+}
+
+func yearMissing(p Processor) bool {
+	yp, ok := p.(yearlessProcessor)
+	return ok && yp.YearMissing()
+}
+
+func (sp syslogProcessor) YearMissing() bool {
+	return true
+}
+
+// zoneAbbreviationSetter is implemented by processors whose format contains a timezone abbreviation
+type zoneAbbreviationSetter interface {
+	SetZoneAbbreviations(map[string]*time.Location)
+}
+
+func setZoneAbbreviations(p Processor, zones map[string]*time.Location) {
+	if zs, ok := p.(zoneAbbreviationSetter); ok {
+		zs.SetZoneAbbreviations(zones)
+	}
+}
+
+// zoneProcessor handles formats with timezone abbreviations such as "CST".
+// Abbreviations are ambiguous and the time package only understands the ones used by the
+// location passed to Extract, so abbreviations can be mapped to a specific location.
+type zoneProcessor struct {
+	processor
+	zones map[string]*time.Location
+}
+
+func newZoneProcessor(p processor) *zoneProcessor {
+	return &zoneProcessor{processor: p}
+}
+
+// SetZoneAbbreviations sets the abbreviation to location map, the map must not be modified after it is set
+func (zp *zoneProcessor) SetZoneAbbreviations(zones map[string]*time.Location) {
+	zp.zones = zones
+}
+
+func (zp *zoneProcessor) Extract(d []byte, loc *time.Location) (t time.Time, ok bool, offset int) {
+	if t, ok, offset = zp.processor.Extract(d, loc); !ok || len(zp.zones) == 0 {
+		return
+	}
+	abbr, _ := t.Zone()
+	if zl, found := zp.zones[abbr]; found {
+		//the wall clock is right, the offset may not be
+		t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), zl)
+		if !zp.window.Valid(t) {
+			return time.Time{}, false, -1
+		}
+	}
+	return
 }
@@ -44,6 +44,8 @@ type TimeGrinder struct {
 	seed     bool
 	override Processor
 	loc      *time.Location
+	ref      time.Time
+	zones    map[string]*time.Location
 }
 
 // Config defines a few configuration options when instantiating a new TimeGrinder.
@@ -56,6 +58,9 @@ type Config struct {
 	FormatOverride string
 	// TSWindow sets maximum deltas into the past & future for timestamp parsing. Any timestamp extracted which falls outside those deltas from the current time will be considered invalid and skipped.
 	TSWindow TimestampWindow
+	// ZoneAbbreviations maps timezone abbreviations (e.g. "CST") to location names (e.g. "America/Chicago").
+	// Abbreviations are ambiguous, so this decides which zone the Unix, RFC822, and RFC1123 formats mean.
+	ZoneAbbreviations map[string]string
 }
 
 func Extract(b []byte) (t time.Time, ok bool, err error) {
@@ -198,6 +203,11 @@ func New(c Config) (tg *TimeGrinder, err error) {
 		loc:    time.UTC,
 		seed:   c.EnableLeftMostSeed,
 	}
+	for abbr, name := range c.ZoneAbbreviations {
+		if err = tg.SetZoneAbbreviation(abbr, name); err != nil {
+			return
+		}
+	}
 	if c.FormatOverride != `` {
 		err = tg.SetFormatOverride(c.FormatOverride)
 	}
@@ -237,6 +247,42 @@ func (tg *TimeGrinder) SetTimezone(f string) error {
 	return nil
 }
 
+// SetReferenceTime sets the time used to pick the year for formats that do not contain one, such as Syslog.
+// The year is chosen so that the timestamp is nearest the reference without being more than a day after it.
+// Setting a zero time uses the current time, which is the default.
+func (tg *TimeGrinder) SetReferenceTime(t time.Time) {
+	tg.ref = t
+}
+
+func (tg *TimeGrinder) referenceTime() time.Time {
+	if tg.ref.IsZero() {
+		return time.Now()
+	}
+	return tg.ref
+}
+
+// SetZoneAbbreviation maps a timezone abbreviation to a location for formats that use abbreviations
+func (tg *TimeGrinder) SetZoneAbbreviation(abbr, name string) error {
+	if abbr == `` {
+		return errors.New("Missing timezone abbreviation")
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return err
+	}
+	//processors hold the map, so replace it rather than modifying it
+	zones := make(map[string]*time.Location, len(tg.zones)+1)
+	for k, v := range tg.zones {
+		zones[k] = v
+	}
+	zones[abbr] = loc
+	tg.zones = zones
+	for i := range tg.procs {
+		setZoneAbbreviations(tg.procs[i], zones)
+	}
+	return nil
+}
+
 func (tg *TimeGrinder) OverrideProcessor() (Processor, error) {
 	if tg.override != nil {
 		return tg.override, nil
@@ -258,6 +304,9 @@ func (tg *TimeGrinder) AddProcessor(p Processor) (idx int, err error) {
 	}
 	// make sure the cutoff is set
 	p.SetWindow(tg.TSWindow)
+	if tg.zones != nil {
+		setZoneAbbreviations(p, tg.zones)
+	}
 	tg.procs = append([]Processor{p}, tg.procs...)
 	tg.count++
 	idx = 0
@@ -279,6 +328,19 @@ func (tg *TimeGrinder) Reset() {
 	tg.curr = 0
 }
 
+// extract runs a processor and infers the year for formats that do not contain one
+func (tg *TimeGrinder) extract(p Processor, data []byte) (t time.Time, ok bool, offset int) {
+	if t, ok, offset = p.Extract(data, tg.loc); offset >= 0 {
+		if t.Year() == 0 || (!tg.ref.IsZero() && yearMissing(p)) {
+			// the processor checked the window against the current year, check it again with the inferred one
+			if t = inferYear(t, tg.referenceTime()); !tg.TSWindow.Valid(t) {
+				t, ok, offset = time.Time{}, false, -1
+			}
+		}
+	}
+	return
+}
+
 func (tg *TimeGrinder) setSeed(data []byte) (hit bool) {
 	var offset int
 	var leftmost int
@@ -287,7 +349,7 @@ func (tg *TimeGrinder) setSeed(data []byte) (hit bool) {
 
 	//go until we get a hit
 	for i < len(tg.procs) {
-		if _, ok, leftmost = tg.procs[i].Extract(data, tg.loc); ok {
+		if _, ok, leftmost = tg.extract(tg.procs[i], data); ok {
 			tg.curr = i
 			hit = true
 			break
@@ -296,7 +358,7 @@ func (tg *TimeGrinder) setSeed(data []byte) (hit bool) {
 	}
 	//search for something even more left
 	for i < len(tg.procs) {
-		if _, ok, offset = tg.procs[i].Extract(data, tg.loc); ok {
+		if _, ok, offset = tg.extract(tg.procs[i], data); ok {
 			if offset < leftmost {
 				leftmost = offset
 				tg.curr = i
@@ -315,7 +377,7 @@ func (tg *TimeGrinder) Extract(data []byte) (t time.Time, ok bool, err error) {
 	var c int
 
 	if tg.override != nil {
-		if t, ok, _ = tg.override.Extract(data, tg.loc); ok {
+		if t, ok, _ = tg.extract(tg.override, data); ok {
 			return
 		}
 	}
@@ -328,7 +390,7 @@ func (tg *TimeGrinder) Extract(data []byte) (t time.Time, ok bool, err error) {
 
 	i = tg.curr
 	for c = 0; c < tg.count; c++ {
-		t, ok, _ = tg.procs[i].Extract(data, tg.loc)
+		t, ok, _ = tg.extract(tg.procs[i], data)
 		if ok {
 			tg.curr = i
 			return
@@ -384,7 +446,7 @@ func (tg *TimeGrinder) DebugExtract(data []byte) (t time.Time, offset int, name
 	var c int
 
 	if tg.override != nil {
-		if t, _, offset = tg.override.Extract(data, tg.loc); offset < 0 {
+		if t, _, offset = tg.extract(tg.override, data); offset < 0 {
 			return
 		}
 		name = tg.override.Name()
@@ -399,7 +461,7 @@ func (tg *TimeGrinder) DebugExtract(data []byte) (t time.Time, offset int, name
 
 	i = tg.curr
 	for c = 0; c < tg.count; c++ {
-		t, _, offset = tg.procs[i].Extract(data, tg.loc)
+		t, _, offset = tg.extract(tg.procs[i], data)
 		if offset >= 0 {
 			tg.curr = i
 			name = tg.procs[i].Name()
@@ -423,7 +485,7 @@ func (tg *TimeGrinder) DebugMatch(data []byte) (ts time.Time, name string, start
 
 	if tg.override != nil {
 		if start, end, ok = tg.override.Match(data); ok {
-			if ts, ok, _ = tg.override.Extract(data[start:end], tg.loc); ok {
+			if ts, ok, _ = tg.extract(tg.override, data[start:end]); ok {
 				name = tg.override.Name()
 			}
 		}
@@ -439,7 +501,7 @@ func (tg *TimeGrinder) DebugMatch(data []byte) (ts time.Time, name string, start
 	i = tg.curr
 	for c = 0; c < tg.count; c++ {
 		if start, end, ok = tg.procs[i].Match(data); ok {
-			if ts, ok, _ = tg.procs[i].Extract(data[start:end], tg.loc); ok {
+			if ts, ok, _ = tg.extract(tg.procs[i], data[start:end]); ok {
 				name = tg.procs[i].Name()
 				tg.curr = i
 				return //hit
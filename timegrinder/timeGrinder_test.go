@@ -190,6 +190,109 @@ func TestYearGuess(t *testing.T) {
 	tester(custom.Name, custom.Format, tg)
 }
 
+func TestInferYear(t *testing.T) {
+	mk := func(s string) time.Time {
+		ts, err := time.Parse(`2006-01-02 15:04:05`, s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ts
+	}
+	tests := []struct {
+		ts, ref, exp string
+	}{
+		{`0000-12-31 23:55:00`, `2027-01-01 00:10:00`, `2026-12-31 23:55:00`}, // read just after new year
+		{`0000-01-01 00:00:10`, `2026-12-31 23:59:30`, `2027-01-01 00:00:10`}, // sender is slightly ahead
+		{`0000-06-01 12:00:00`, `2026-06-01 11:00:00`, `2026-06-01 12:00:00`},
+		{`0000-06-02 13:00:00`, `2026-06-01 11:00:00`, `2025-06-02 13:00:00`}, // too far ahead
+		{`0000-02-29 08:00:00`, `2027-03-15 00:00:00`, `2024-02-29 08:00:00`}, // nearest leap year
+	}
+	for _, tt := range tests {
+		if got := inferYear(mk(tt.ts), mk(tt.ref)); !got.Equal(mk(tt.exp)) {
+			t.Fatalf("%s with reference %s: got %v, expected %s", tt.ts, tt.ref, got, tt.exp)
+		}
+	}
+}
+
+func TestReferenceTime(t *testing.T) {
+	tg, err := New(Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	custom := CustomFormat{
+		Name:   `yearless`,
+		Regex:  `\d{2}/\d{2}@\d{2}:\d{2}:\d{2}`,
+		Format: `01/02@15:04:05`,
+	}
+	if p, err := NewCustomProcessor(custom); err != nil {
+		t.Fatal(err)
+	} else if _, err = tg.AddProcessor(p); err != nil {
+		t.Fatal(err)
+	}
+	tg.SetReferenceTime(time.Date(2023, time.January, 1, 0, 5, 0, 0, time.UTC))
+	for _, v := range []string{`Dec 31 23:59:00 host sshd: test`, `12/31@23:59:00 test`} {
+		ts, ok, err := tg.Extract([]byte(v))
+		if err != nil || !ok {
+			t.Fatalf("failed to extract %q: %v", v, err)
+		} else if exp := time.Date(2022, time.December, 31, 23, 59, 0, 0, time.UTC); !ts.Equal(exp) {
+			t.Fatalf("bad year from %q: %v", v, ts)
+		}
+	}
+	// clearing the reference goes back to the current time
+	tg.SetReferenceTime(time.Time{})
+	if ts, ok, _ := tg.Extract([]byte(time.Now().UTC().Format(SyslogFormat))); !ok || ts.Year() < 2025 {
+		t.Fatalf("bad extraction without a reference: %v", ts)
+	}
+}
+
+func TestReferenceTimeWindow(t *testing.T) {
+	tg, err := New(Config{TSWindow: TimestampWindow{MaxPastDelta: 30 * 24 * time.Hour}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tg.SetTimezone("UTC")
+	v := []byte(time.Now().UTC().Add(-time.Hour).Format(SyslogFormat) + ` host sshd: test`)
+	if _, ok, _ := tg.Extract(v); !ok {
+		t.Fatal("failed to extract a recent timestamp")
+	}
+	// an inferred year outside the window is rejected even though the current year is inside it
+	tg.SetReferenceTime(time.Now().AddDate(-3, 0, 0))
+	if ts, ok, _ := tg.Extract(v); ok {
+		t.Fatalf("extracted %v outside the window", ts)
+	}
+	if _, off, _, _ := tg.DebugExtract(v); off != -1 {
+		t.Fatalf("debug extract hit at %d outside the window", off)
+	}
+}
+
+func TestZoneAbbreviations(t *testing.T) {
+	tg, err := New(Config{ZoneAbbreviations: map[string]string{`CST`: `Asia/Shanghai`}})
+	if err != nil {
+		t.Fatal(err)
+	} else if err = tg.SetZoneAbbreviation(`IST`, `Asia/Kolkata`); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		v   string
+		exp time.Time
+	}{
+		{`Mon Jan  2 15:04:05 CST 2006`, time.Date(2006, time.January, 2, 7, 4, 5, 0, time.UTC)},
+		{`02 Jan 06 15:04 IST`, time.Date(2006, time.January, 2, 9, 34, 0, 0, time.UTC)},
+		{`02 Jan 2006 15:04:05 CST`, time.Date(2006, time.January, 2, 7, 4, 5, 0, time.UTC)},
+		{`02 Jan 2006 15:04:05 EST`, time.Date(2006, time.January, 2, 15, 4, 5, 0, time.UTC)}, // unmapped
+	}
+	for _, tt := range tests {
+		if ts, ok, err := tg.Extract([]byte(tt.v)); err != nil || !ok {
+			t.Fatalf("failed to extract %q: %v", tt.v, err)
+		} else if !ts.Equal(tt.exp) {
+			t.Fatalf("%q: got %v, expected %v", tt.v, ts.UTC(), tt.exp)
+		}
+	}
+	if _, err = New(Config{ZoneAbbreviations: map[string]string{`XST`: `Nowhere/Special`}}); err == nil {
+		t.Fatal("invalid location was accepted")
+	}
+}
+
 func TestTooManyDigitsUnix(t *testing.T) {
 	tg, err := New(cfg)
 	if err != nil {
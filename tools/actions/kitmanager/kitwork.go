@@ -9,6 +9,7 @@
 package main
 
 import (
+	"crypto/ed25519"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -21,6 +22,7 @@ import (
 	"github.com/gravwell/gravwell/v3/client"
 	"github.com/gravwell/gravwell/v3/client/types"
 	"github.com/gravwell/gravwell/v3/client/types/kits"
+	"github.com/gravwell/gravwell/v3/ingesters/utils"
 )
 
 // pullKit reaches out to the remote Gravwell instance and performs a kit build using the existing kit build request
@@ -210,6 +212,36 @@ func unpackKitFile(pth, targetDir string) (err error) {
 	return
 }
 
+// verifyKitSignature checks that the kit file at pth is intact and signed by one of the
+// public keys in the trusted keys directory.
+func verifyKitSignature(pth, trustedDir string) (err error) {
+	var keys []ed25519.PublicKey
+	if keys, err = kits.LoadTrustedKeys(trustedDir); err != nil {
+		err = fmt.Errorf("failed to load trusted keys: %w", err)
+		return
+	} else if len(keys) == 0 {
+		err = fmt.Errorf("no trusted keys found in %s", trustedDir)
+		return
+	}
+	var fin utils.ReadResetCloser
+	if fin, err = utils.OpenFileReader(pth); err != nil {
+		return
+	}
+	defer fin.Close()
+	var rdr *kits.Reader
+	if rdr, err = kits.NewReader(fin, kits.NewEd25519Verifier(keys)); err != nil {
+		return
+	} else if err = rdr.Verify(); err != nil {
+		err = fmt.Errorf("kit failed verification: %w", err)
+		return
+	}
+	var signed bool
+	if signed, err = rdr.Signed(); err == nil && !signed {
+		err = kits.ErrMissingSignature
+	}
+	return
+}
+
 func getSearchLibraryItems(cli *client.Client, label string, orig types.KitBuildRequest, kbr *types.KitBuildRequest) (err error) {
 	var items []types.WireSearchLibrary
 	if items, err = cli.ListSearchLibrary(); err != nil {
@@ -396,6 +428,49 @@ func pushKit(cli *client.Client, force bool) (err error) {
 		return
 	}
 
+	fmt.Printf("Deploying kit %s version %v\n", mf.ID, mf.Version)
+	// pack and check the kit before touching the server so that a bad signature
+	// can never leave us having deleted the existing kit
+	// create a temp file for the kit
+	var fout *os.File
+	if fout, err = os.CreateTemp(os.TempDir(), mf.ID); err != nil {
+		err = fmt.Errorf("failed to create temp file for kit pack: %w", err)
+		return
+	}
+	pth := fout.Name()   // get the file name for the temp file
+	defer os.Remove(pth) // clean up the temp file when done
+	if err = fout.Close(); err != nil {
+		err = fmt.Errorf("failed to close temp kit pack file: %w", err)
+		return
+	}
+
+	// call the kitctl pack command, signing the kit if we have a key
+	var stdoutStderr []byte
+	args := []string{"pack", pth}
+	if kitSigningKey != `` {
+		var keyPath string
+		if keyPath, err = filepath.Abs(kitSigningKey); err != nil {
+			err = fmt.Errorf("failed to resolve signing key path: %w", err)
+			return
+		}
+		args = append([]string{"-key", keyPath}, args...)
+	}
+	cmd := exec.Command(kitCtl, args...)
+	cmd.Dir = kitDir // set working directory to kit dir
+	if stdoutStderr, err = cmd.CombinedOutput(); err != nil {
+		err = fmt.Errorf("failed to pack kit file %s: %v\nCommand Output: %s", pth, err, stdoutStderr)
+		return
+	}
+
+	// refuse to deploy anything that is not signed by a trusted key
+	if kitTrustedKeys != `` {
+		if err = verifyKitSignature(pth, kitTrustedKeys); err != nil {
+			err = fmt.Errorf("refusing to deploy kit %s: %w", mf.ID, err)
+			return
+		}
+		fmt.Printf("Kit %s is signed by a trusted key\n", mf.ID)
+	}
+
 	// go get the list of kits from the remote server to check if we have an existing kit the same ID and a version that
 	// will allow us to deploy this kit, if we have a kit with the same ID and a version that is greater than or equal
 	// to the version in the manifest and force is not true, then we need to error out because we don't want to accidentally
@@ -426,29 +501,6 @@ func pushKit(cli *client.Client, force bool) (err error) {
 		}
 	}
 
-	fmt.Printf("Deploying kit %s version %v\n", mf.ID, mf.Version)
-	// create a temp file for the kit
-	var fout *os.File
-	if fout, err = os.CreateTemp(os.TempDir(), mf.ID); err != nil {
-		err = fmt.Errorf("failed to create temp file for kit pack: %w", err)
-		return
-	}
-	pth := fout.Name()   // get the file name for the temp file
-	defer os.Remove(pth) // clean up the temp file when done
-	if err = fout.Close(); err != nil {
-		err = fmt.Errorf("failed to close temp kit pack file: %w", err)
-		return
-	}
-
-	// call the kitctl pack command
-	var stdoutStderr []byte
-	cmd := exec.Command(kitCtl, "pack", pth)
-	cmd.Dir = kitDir // set working directory to kit dir
-	if stdoutStderr, err = cmd.CombinedOutput(); err != nil {
-		err = fmt.Errorf("failed to pack kit file %s: %v\nCommand Output: %s", pth, err, stdoutStderr)
-		return
-	}
-
 	// push the kit to the server
 	var state types.KitState
 	if state, err = cli.UploadKit(pth); err != nil {
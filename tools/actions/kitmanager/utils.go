@@ -37,6 +37,8 @@ const (
 	envKitLabels       = `GRAVWELL_KIT_LABELS`
 	envKitForceInstall = `GRAVWELL_KIT_FORCE_INSTALL`
 	envKitCtl          = `GRAVWELL_KITCTL`
+	envKitSigningKey   = `GRAVWELL_KIT_SIGNING_KEY`
+	envKitTrustedKeys  = `GRAVWELL_KIT_TRUSTED_KEYS`
 
 	commandsStr = `Available Commands:
   list         List available kits
@@ -57,6 +59,8 @@ var (
 	kitLabels       = os.Getenv(envKitLabels)
 	kitCtl          = os.Getenv(envKitCtl)
 	kitForceInstall = getBoolFromString(os.Getenv(envKitForceInstall))
+	kitSigningKey   = os.Getenv(envKitSigningKey)
+	kitTrustedKeys  = os.Getenv(envKitTrustedKeys)
 
 	fHost            = flag.String("host", "", "URL of Gravwell system")
 	fToken           = flag.String("token", "", "Authentication token for Gravwell system")
@@ -70,6 +74,8 @@ var (
 	fKitLabels       = flag.String("kit-labels", "", "Comma separated list of labels to deploy the kit to")
 	fKitForceInstall = flag.Bool("kit-force-install", false, "Force kit install on push even if existing kit has same or newer version")
 	fIgnoreCert      = flag.Bool("ignore-cert", false, "Ignore TLS certificate errors")
+	fKitSigningKey   = flag.String("kit-signing-key", "", "Private key used to sign the kit on push")
+	fKitTrustedKeys  = flag.String("kit-trusted-keys", "", "Directory of trusted public keys, kits not signed by one of them are refused on push")
 )
 
 // initVars just ensures that the hostUrl, authToken, and kitId variables are set from environment variables
@@ -100,6 +106,12 @@ func initVars(cmd string) (err error) {
 	if *fKitWriteGroups != "" {
 		kitWriteGroups = *fKitWriteGroups
 	}
+	if *fKitSigningKey != "" {
+		kitSigningKey = *fKitSigningKey
+	}
+	if *fKitTrustedKeys != "" {
+		kitTrustedKeys = *fKitTrustedKeys
+	}
 
 	// do some dumb loops to determine if the boolean flags are set
 	flag.Visit(func(f *flag.Flag) {
@@ -36,9 +36,9 @@ var (
 
 func main() {
 	flag.Parse()
-	var pc processors.PluginConfig
-	var p *processors.Plugin
+	var p processors.Processor
 	var rdr utils.ReimportReader
+	var ptype string
 	var vc *config.VariableConfig
 	var err error
 	if *configPath == `` {
@@ -48,13 +48,10 @@ func main() {
 	if config_data, err := os.ReadFile(*configPath); err != nil {
 		fmt.Printf("Failed to load plugin config file %q: %v\n", *configPath, err)
 		os.Exit(1)
-	} else if vc, err = loadPluginConfig(config_data); err != nil {
+	} else if ptype, vc, err = loadPluginConfig(config_data); err != nil {
 		fmt.Printf("Failed to load plugin config: %v\n", err)
 		os.Exit(1)
-	} else if pc, err = processors.PluginLoadConfig(vc); err != nil {
-		fmt.Printf("Failed to PluginLoadConfig: %v\n", err)
-		os.Exit(1)
-	} else if p, err = processors.NewPluginProcessor(pc, &testTagHandler{}); err != nil {
+	} else if p, err = newProcessor(ptype, vc); err != nil {
 		fmt.Printf("Failed to create plugin: %v\n", err)
 		os.Exit(1)
 	}
@@ -138,7 +135,30 @@ func popEnts(rdr utils.ReimportReader) (ents []*entry.Entry, err error) {
 	return
 }
 
-func loadPluginConfig(cnt []byte) (r *config.VariableConfig, err error) {
+// newProcessor builds either a scriggo plugin or a wasm program from the configuration
+func newProcessor(ptype string, vc *config.VariableConfig) (p processors.Processor, err error) {
+	switch ptype {
+	case processors.PluginProcessor:
+		var pc processors.PluginConfig
+		if pc, err = processors.PluginLoadConfig(vc); err != nil {
+			err = fmt.Errorf("PluginLoadConfig failed: %w", err)
+		} else {
+			p, err = processors.NewPluginProcessor(pc, &testTagHandler{})
+		}
+	case processors.WasmProcessor:
+		var wc processors.WasmConfig
+		if wc, err = processors.WasmLoadConfig(vc); err != nil {
+			err = fmt.Errorf("WasmLoadConfig failed: %w", err)
+		} else {
+			p, err = processors.NewWasmProcessor(wc, &testTagHandler{})
+		}
+	default:
+		err = fmt.Errorf("Configuration stanza is of the wrong type: %q is not plugin or wasm", ptype)
+	}
+	return
+}
+
+func loadPluginConfig(cnt []byte) (ptype string, r *config.VariableConfig, err error) {
 	var cfg testConfig
 	//load it up and make sure there is exactly one preprocessor config defined
 	if err = config.LoadConfigBytes(&cfg, cnt); err != nil {
@@ -147,15 +167,14 @@ func loadPluginConfig(cnt []byte) (r *config.VariableConfig, err error) {
 		err = fmt.Errorf("plugin config does not contain exactly one plugin configuration: count %d", len(cfg.Preprocessor))
 		return
 	}
-	//grab the preprocessor and check that the defined preprocessor is of type plugin
-	ptype, vc, ok := cfg.pop()
-	if !ok || vc == nil {
+	//grab the preprocessor, the caller checks that it is of type plugin or wasm
+	var vc *config.VariableConfig
+	var ok bool
+	if ptype, vc, ok = cfg.pop(); !ok || vc == nil {
 		err = fmt.Errorf("failed to pull plugin configuration")
 		return
-	} else if ptype != "plugin" {
-		err = fmt.Errorf("Configuration stanza is of the wrong type: %q != plugin", ptype)
-		return
 	}
+	ptype = strings.TrimSpace(strings.ToLower(ptype))
 	r = vc
 	return
 }
@@ -0,0 +1,61 @@
+/*************************************************************************
+ * Copyright 2026 Gravwell, Inc. All rights reserved.
+ * Contact: <legal@gravwell.io>
+ *
+ * This software may be modified and distributed under the terms of the
+ * BSD 2-clause license. See the LICENSE file for details.
+ **************************************************************************/
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/gravwell/gravwell/v3/timegrinder"
+)
+
+// discoverFormats prints every candidate for each sample and synthesizes a custom format
+// from the samples that no processor could handle
+func discoverFormats(tg *timegrinder.TimeGrinder, samples []string) {
+	var unmatched []string
+	for _, s := range samples {
+		cs := tg.Candidates([]byte(s))
+		if len(cs) == 0 {
+			outputNoMatch(s)
+			unmatched = append(unmatched, s)
+			continue
+		}
+		fmt.Println(s)
+		for _, c := range cs {
+			fmt.Printf("\t%s%-24s%s %d-%d\t%s%v%s\n", Yellow, c.Name, Reset, c.Start, c.End, Blue, c.TS, Reset)
+			if c.Ambiguous() {
+				fmt.Printf("\t%s%-24s%s day and month may be swapped: %v\n", Red, ``, Reset, c.Alternate)
+			}
+		}
+		if conflicting(cs) {
+			fmt.Printf("\t%sCandidates disagree, consider a custom format or format override%s\n", Red, Reset)
+		}
+	}
+	if len(unmatched) == 0 {
+		return
+	}
+	cf, err := timegrinder.SynthesizeFormat(*discoverName, unmatched)
+	if err != nil {
+		fmt.Printf("\n%sFailed to synthesize a custom format: %v%s\n", Red, err, Reset)
+		return
+	}
+	fmt.Printf("\nSynthesized custom format for %d unmatched samples:\n\n", len(unmatched))
+	fmt.Printf("[TimeFormat %q]\n", cf.Name)
+	fmt.Printf("\tFormat=%q\n", cf.Format)
+	fmt.Printf("\tRegex=`%s`\n", cf.Regex)
+}
+
+// conflicting reports whether the candidates extracted more than one distinct timestamp
+func conflicting(cs []timegrinder.Candidate) bool {
+	for _, c := range cs[1:] {
+		if !c.TS.Equal(cs[0].TS) {
+			return true
+		}
+	}
+	return false
+}
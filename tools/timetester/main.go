@@ -26,6 +26,8 @@ var (
 	lms            = flag.Bool("enable-left-most-seed", false, "Activate EnableLeftMostSeed config option")
 	fo             = flag.String("format-override", "", "Enable FormatOverride config option")
 	metrics        = flag.Bool("metrics", false, "Output metrics about captures")
+	discover       = flag.Bool("discover", false, "Report every processor that matches each sample and synthesize a custom format when none do")
+	discoverName   = flag.String("discover-name", "discovered", "Name of the synthesized custom time format")
 )
 
 type customFormats struct {
@@ -55,6 +57,7 @@ func main() {
 				Name:             k,
 				Regex:            v.Regex,
 				Format:           v.Format,
+				Format_Style:     v.Format_Style,
 				Extraction_Regex: v.Extraction_Regex,
 			}
 			if cp, err := timegrinder.NewCustomProcessor(cf); err != nil {
@@ -69,6 +72,10 @@ func main() {
 			log.Fatalf("Failed to set timestamp format override to %q: %v\n", *fo, err)
 		}
 	}
+	if *discover {
+		discoverFormats(tg, flag.Args())
+		return
+	}
 	for _, arg := range flag.Args() {
 		if ts, name, start, end, ok := tg.DebugMatch([]byte(arg)); !ok {
 			outputNoMatch(arg)